@@ -1,15 +1,35 @@
 package main
 
 import (
+	"context"
 	"healthcare/cmd/healthcare/docs"
+	"healthcare/controllers/appointments"
+	"healthcare/controllers/calendar"
 	"healthcare/controllers/dashboard"
+	"healthcare/controllers/diagnoses"
 	"healthcare/controllers/doctors"
+	"healthcare/controllers/documents"
+	"healthcare/controllers/drugs"
+	"healthcare/controllers/events"
+	"healthcare/controllers/images"
+	"healthcare/controllers/jobs"
+	"healthcare/controllers/labs"
+	"healthcare/controllers/locale"
 	"healthcare/controllers/medications"
+	"healthcare/controllers/notifications"
+	"healthcare/controllers/paging"
 	"healthcare/controllers/patients"
+	"healthcare/controllers/queue"
 	"healthcare/controllers/therapyschedules"
 	"healthcare/controllers/ums"
 	"healthcare/controllers/visits"
+	"healthcare/controllers/vitals"
 	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/i18n"
+	"healthcare/utils/migrations"
+	"healthcare/utils/scan"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -56,16 +76,93 @@ func (a Application) AddRoutes(
 	rg *gin.RouterGroup,
 ) {
 
+	catalogue := locale.LoadCatalogue(wsParams)
+	rg.Use(locale.Middleware(catalogue), paging.Middleware())
 	api := rg.Group("api/v1")
 	ums.AddumsRoutes(model, wsParams, rg, api, false)
 	doctors.AdddoctorsRoutes(model, wsParams, roleMap, api, false)
 
 	// Add new healthcare routes
 	patients.AddPatientsRoutes(model, wsParams, roleMap, api, false)
-	visits.SetupRoutes(api)
-	medications.SetupRoutes(api)
-	therapyschedules.SetupRoutes(api)
-	dashboard.SetupRoutes(api)
+	drugChecker := drugs.Checker{Core: model, Reference: drugs.LoadReference(wsParams)}
+	drugs.AddDrugsRoutes(model, wsParams, roleMap, api, drugChecker, false)
+	broker := events.LoadBroker(wsParams)
+	events.AddEventsRoutes(model, wsParams, roleMap, api, broker)
+	visits.SetupRoutes(model, wsParams, api, broker, false)
+	store := images.LoadStorage(wsParams)
+	scanner := images.LoadScanner(wsParams)
+	signer := images.LoadSigner(wsParams)
+	images.AddImagesRoutes(model, wsParams, roleMap, api, store, scanner, signer)
+	documents.AddDocumentsRoutes(model, wsParams, roleMap, api, store, scanner, signer)
+	vitals.AddVitalsRoutes(model, wsParams, roleMap, api, false)
+	medications.SetupRoutes(model, wsParams, api, drugChecker, false)
+	therapies := &therapyschedules.Tracker{Core: model, Location: appointments.LoadLocation(wsParams)}
+	therapyschedules.SetupRoutes(model, wsParams, api, therapies, false)
+	calendar.AddCalendarRoutes(model, wsParams, roleMap, api, therapies, false)
+	diagnoses.AddDiagnosesRoutes(model, wsParams, roleMap, api, false)
+	labs.AddLabsRoutes(model, wsParams, roleMap, api, false)
+	appointments.AddAppointmentsRoutes(model, wsParams, roleMap, api, false)
+	queue.AddQueueRoutes(model, wsParams, roleMap, api, broker, false)
+	scheduler := notifications.LoadScheduler(model, wsParams)
+	scheduler.Therapies = therapies
+	notifications.AddNotificationsRoutes(model, wsParams, roleMap, api, scheduler, false)
+	runner := jobs.NewRunner(model, wsParams)
+	if wsParams.Specific.Notifications.Enabled {
+		runner.Register(jobs.Job{
+			Name: "notifications",
+			Spec: "@every " + scheduler.Interval.String(),
+			Run:  scheduler.RunOnce,
+		})
+	}
+	runner.Register(jobs.Job{
+		Name: "documents-purge",
+		Spec: "@daily",
+		Run: func(ctx context.Context) error {
+			purged, err := documents.PurgeExpired(ctx, model, store)
+			if purged > 0 {
+				log.Printf("documents: %d expired documents purged", purged)
+			}
+			return err
+		},
+	})
+	if _, unscanned := scanner.(scan.None); !unscanned {
+		runner.Register(jobs.Job{
+			Name: "uploads-rescan",
+			Spec: "@every 15m",
+			Run: func(ctx context.Context) error {
+				imagesRescanned, err := images.Rescan(ctx, model, store, scanner)
+				if err != nil {
+					return err
+				}
+				documentsRescanned, err := documents.Rescan(ctx, model, store, scanner)
+				if imagesRescanned+documentsRescanned > 0 {
+					log.Printf("uploads: %d images and %d documents rescanned", imagesRescanned, documentsRescanned)
+				}
+				return err
+			},
+		})
+	}
+	runner.Register(jobs.Job{
+		Name: "therapy-status",
+		Spec: "5 0 * * *",
+		Run: func(ctx context.Context) error {
+			ended, err := therapies.UpdateStatuses(ctx)
+			if ended > 0 {
+				log.Printf("therapy schedules: %d completed or expired", ended)
+			}
+			return err
+		},
+	})
+	jobs.AddJobsRoutes(model, wsParams, roleMap, api, runner, false)
+	// the leases of jobs still running when the process stops expire and another replica picks them up
+	if wsParams.Specific.Jobs.Enabled {
+		go runner.Start(context.Background())
+	}
+	dashboard.SetupRoutes(model, wsParams, api, catalogue, dashboard.Providers{
+		Diagnoses: diagnoses.Stats{Core: model},
+		Therapies: therapies,
+		Visits:    visits.Stats{Core: model, Location: appointments.LoadLocation(wsParams)},
+	}, false)
 	if wsParams.Network[""].Port == wsParams.Network[""].StaticPort && len(wsParams.Specific.StaticBaseUrl) > 0 && len(wsParams.Network[""].StaticPath) > 0 {
 		rg.Static("/"+wsParams.Specific.StaticBaseUrl, wsParams.Network[""].StaticPath)
 
@@ -74,12 +171,12 @@ func (a Application) AddRoutes(
 			c.Redirect(301, "/"+wsParams.Specific.StaticBaseUrl)
 		})
 
-		a.engine.NoRoute(func(c *gin.Context) {
+		a.engine.NoRoute(locale.Middleware(catalogue), func(c *gin.Context) {
 			// Catch-all route for React app
 			if strings.HasPrefix(c.Request.RequestURI, "/"+wsParams.Specific.StaticBaseUrl) {
 				c.File(wsParams.Network[""].StaticPath + "/index.html")
 			} else {
-				c.JSON(http.StatusNotFound, gin.H{"code": "PAGE_NOT_FOUND", "message": "404 page not found"})
+				locale.Error(c, http.StatusNotFound, i18n.PageNotFound, "404 page not found")
 			}
 		})
 	}
@@ -111,7 +208,32 @@ func (a Application) GetKeys() [][]byte {
 }
 
 func (a Application) InitParams(wsParams *libParams.ApplicationParams[models.ApplicationParams]) {
-
+	// statements and query maps are picked by the dialect when the routes are added
+	sqlDialect, err := dialect.FromParams(wsParams, a.Name())
+	if err != nil {
+		log.Fatalln("error in db.healthcare.dbType", err)
+	}
+	dialect.Use(sqlDialect)
+	// replicas starting together wait on the advisory lock of the migrations and find the schema migrated
+	if wsParams.Specific.Migrations.RunOnStartup {
+		ctx := context.Background()
+		dsn := wsParams.Specific.Migrations.Address()
+		if dialect.FromDSN(dsn) != sqlDialect {
+			log.Fatalf("migrations dsn is a %s database but db.healthcare.dbType is %s", dialect.FromDSN(dsn), sqlDialect)
+		}
+		db, err := migrations.Open(ctx, dsn)
+		if err != nil {
+			log.Fatalln("error in migrations.Open", err)
+		}
+		defer db.Close()
+		runner, err := migrations.New(db, sqlDialect)
+		if err != nil {
+			log.Fatalln("error in loading migrations", err)
+		}
+		if _, err := runner.Up(ctx, 0); err != nil {
+			log.Fatalln("error in migrations", err)
+		}
+	}
 }
 
 // @title           سامانه سرویس های Health Care
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/migrations"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `usage: migrate [flags] command
+
+commands:
+  up [version]       apply pending migrations, up to version when given
+  down [steps]       revert the last steps applied migrations, 1 when not given
+  status             list migrations and when they were applied
+  baseline version   record migrations up to version as applied without running them,
+                     for databases created by the former scripts
+
+flags:
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	paramFile := flag.String("p", "param.yaml", "Application Params")
+	dsn := flag.String("dsn", "", "Connection string, specific.migrations.dsn or DATABASE_URL when empty; oracle:// urls migrate an Oracle database")
+	flag.Usage = usage
+
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if len(*dsn) == 0 {
+		wsParams, err := libParams.Load[models.ApplicationParams](*paramFile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		*dsn = wsParams.Specific.Migrations.Address()
+	}
+
+	ctx := context.Background()
+	db, err := migrations.Open(ctx, *dsn)
+	if err != nil {
+		log.Fatalln("error in Open", err)
+	}
+	defer db.Close()
+	runner, err := migrations.New(db, dialect.FromDSN(*dsn))
+	if err != nil {
+		log.Fatalln("error in loading migrations", err)
+	}
+
+	switch command := flag.Arg(0); command {
+	case "up":
+		applied, err := runner.Up(ctx, argument(1, 0))
+		if err != nil {
+			log.Fatalln("error in up", err)
+		}
+		log.Printf("%d migrations applied", applied)
+	case "down":
+		reverted, err := runner.Down(ctx, int(argument(1, 1)))
+		if err != nil {
+			log.Fatalln("error in down", err)
+		}
+		log.Printf("%d migrations reverted", reverted)
+	case "baseline":
+		if flag.NArg() < 2 {
+			log.Fatalln("baseline needs the version the schema is at")
+		}
+		recorded, err := runner.Baseline(ctx, argument(1, 0))
+		if err != nil {
+			log.Fatalln("error in baseline", err)
+		}
+		log.Printf("%d migrations recorded as applied", recorded)
+	case "status":
+		states, err := runner.Status(ctx)
+		if err != nil {
+			log.Fatalln("error in status", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+		for _, s := range states {
+			appliedAt, note := "pending", ""
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case s.Unknown:
+				note = "unknown to this binary"
+			case s.Modified:
+				note = "modified after it was applied"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+		}
+		w.Flush()
+	default:
+		log.Fatalf("unknown command %q", command)
+	}
+}
+
+// argument returns the positive number at position i of the command line or def when it is missing
+func argument(i int, def int64) int64 {
+	if flag.NArg() <= i {
+		return def
+	}
+	n, err := strconv.ParseInt(flag.Arg(i), 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s: a positive number is expected", flag.Arg(i))
+	}
+	return n
+}
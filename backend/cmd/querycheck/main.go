@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"healthcare/controllers/appointments"
+	"healthcare/controllers/calendar"
+	"healthcare/controllers/diagnoses"
+	"healthcare/controllers/doctors"
+	"healthcare/controllers/documents"
+	"healthcare/controllers/drugs"
+	"healthcare/controllers/images"
+	"healthcare/controllers/jobs"
+	"healthcare/controllers/labs"
+	"healthcare/controllers/notifications"
+	"healthcare/controllers/patients"
+	"healthcare/controllers/queue"
+	"healthcare/controllers/therapyschedules"
+	"healthcare/controllers/visits"
+	"healthcare/controllers/vitals"
+	"healthcare/utils/dialect"
+	"healthcare/utils/migrations"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// parse compiles a statement on Oracle without running it, DBMS_SQL parses queries, DML and PL/SQL
+// blocks against the schema and leaves the binds unbound
+const parse = `
+DECLARE
+  c INTEGER := DBMS_SQL.OPEN_CURSOR;
+BEGIN
+  DBMS_SQL.PARSE(c, :1, DBMS_SQL.NATIVE);
+  DBMS_SQL.CLOSE_CURSOR(c);
+EXCEPTION
+  WHEN OTHERS THEN
+    DBMS_SQL.CLOSE_CURSOR(c);
+    RAISE;
+END;`
+
+func main() {
+	dsn := flag.String("dsn", os.Getenv("DATABASE_URL"), "Connection string of a scratch database, DATABASE_URL when empty; oracle:// urls check the Oracle variants")
+	roundTrip := flag.Bool("roundtrip", false, "Revert every migration and apply them again before checking, drops all data of the schema")
+	flag.Parse()
+	if len(*dsn) == 0 {
+		log.Fatalln("a connection string is needed, see -dsn")
+	}
+
+	ctx := context.Background()
+	d := dialect.FromDSN(*dsn)
+	db, err := migrations.Open(ctx, *dsn)
+	if err != nil {
+		log.Fatalln("error in Open", err)
+	}
+	defer db.Close()
+	if err := migrate(ctx, db, d, *roundTrip); err != nil {
+		log.Fatalln("error in migrating", err)
+	}
+
+	statements := collect(d)
+	names := make([]string, 0, len(statements))
+	for name := range statements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failed := 0
+	for _, name := range names {
+		if err := check(ctx, db, d, statements[name]); err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", name, err)
+		}
+	}
+	log.Printf("%d %s statements checked, %d failed", len(names), d, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// migrate applies the embedded migrations, with roundTrip it reverts them all and applies them again
+// so the down scripts run too
+func migrate(ctx context.Context, db *sql.DB, d dialect.Dialect, roundTrip bool) error {
+	runner, err := migrations.New(db, d)
+	if err != nil {
+		return err
+	}
+	applied, err := runner.Up(ctx, 0)
+	if err != nil {
+		return err
+	}
+	log.Printf("%d migrations applied", applied)
+	if !roundTrip {
+		return nil
+	}
+	all, err := migrations.Embedded(d)
+	if err != nil {
+		return err
+	}
+	reverted, err := runner.Down(ctx, len(all))
+	if err != nil {
+		return err
+	}
+	if applied, err = runner.Up(ctx, 0); err != nil {
+		return err
+	}
+	log.Printf("%d migrations reverted and %d applied again", reverted, applied)
+	return nil
+}
+
+// collect returns the statements of the handlers in d by package and name. The ums statements are
+// left out, they query the users of the simulator schema which the migrations do not create
+func collect(d dialect.Dialect) map[string]string {
+	packages := map[string]map[string]dialect.Query{
+		"appointments":     appointments.Statements,
+		"calendar":         calendar.Statements,
+		"diagnoses":        diagnoses.Statements,
+		"doctors":          doctors.Statements,
+		"documents":        documents.Statements,
+		"drugs":            drugs.Statements,
+		"images":           images.Statements,
+		"jobs":             jobs.Statements,
+		"labs":             labs.Statements,
+		"notifications":    notifications.Statements,
+		"patients":         patients.Statements,
+		"queue":            queue.Statements,
+		"therapyschedules": therapyschedules.Statements,
+		"visits":           visits.Statements,
+		"vitals":           vitals.Statements,
+	}
+	statements := map[string]string{}
+	for pkg, queries := range packages {
+		for name, q := range queries {
+			statements[pkg+"."+name] = q.In(d)
+		}
+	}
+	for name, q := range patients.QueryMaps[d] {
+		statements["patients.QueryMaps."+name] = q.Query
+	}
+	for name, q := range doctors.QueryMaps[d] {
+		statements["doctors.QueryMaps."+name] = q.Command
+	}
+	return statements
+}
+
+// check prepares a statement in a transaction that is rolled back. PostgreSQL plans it with the types
+// of its binds, Oracle compiles it through DBMS_SQL
+func check(ctx context.Context, db *sql.DB, d dialect.Dialect, query string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if d == dialect.Oracle {
+		_, err = tx.ExecContext(ctx, parse, strings.TrimSpace(query))
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, positional(query))
+	if err != nil {
+		return err
+	}
+	return stmt.Close()
+}
+
+// positional turns the :1 binds of a statement into the $1 of lib/pq as requestCore does for
+// PostgreSQL, quoted literals and :: casts are kept
+func positional(query string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			c = '$'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestPositional(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM visits WHERE id = :1", "SELECT * FROM visits WHERE id = $1"},
+		{"VALUES (:1, :12, :1)", "VALUES ($1, $12, $1)"},
+		{"SELECT :1::date", "SELECT $1::date"},
+		{"SELECT ':1', 'a::b' WHERE x = :2", "SELECT ':1', 'a::b' WHERE x = $2"},
+		{"SELECT 'it''s :1' || :1", "SELECT 'it''s :1' || $1"},
+		{"SELECT a:b", "SELECT a:b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := positional(tt.query); got != tt.want {
+				t.Errorf("positional(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+-- the migration runner serializes replicas with DBMS_LOCK, which is not granted to app users
+ALTER SESSION SET CONTAINER = FREEPDB1;
+GRANT EXECUTE ON SYS.DBMS_LOCK TO healthcare;
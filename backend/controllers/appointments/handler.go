@@ -0,0 +1,662 @@
+package appointments
+
+import (
+	"encoding/json"
+	"fmt"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/i18n"
+	"healthcare/utils/jalali"
+	"healthcare/utils/slots"
+	"healthcare/utils/storage"
+	"healthcare/utils/validation"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+	"github.com/hmmftg/requestCore/webFramework"
+)
+
+// MaxSlotDays limits the range of a slot search
+const MaxSlotDays = 31
+
+type appointmentsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Location  *time.Location
+}
+
+func getDoctor(core requestCore.RequestCoreInterface, doctorID string) error {
+	if doctorID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_DOCTOR_ID", "doctor id is required")
+	}
+	rows, err := libQuery.GetQuery[models.DoctorsRow](doctorByID.SQL(), core.GetDB(), doctorID)
+	if err != nil {
+		return libError.New(http.StatusInternalServerError, "ERROR_GET_DOCTOR", err.Error())
+	}
+	if len(rows) == 0 {
+		return libError.NewWithDescription(http.StatusNotFound, "DOCTOR_NOT_FOUND", "doctor %s not found", doctorID)
+	}
+	return nil
+}
+
+func toHours(rows []models.WorkingHoursRow) []slots.Hours {
+	hours := make([]slots.Hours, 0, len(rows))
+	for _, row := range rows {
+		start, _ := slots.ParseClock(string(row.StartTime))
+		end, _ := slots.ParseClock(string(row.EndTime))
+		hours = append(hours, slots.Hours{Weekday: time.Weekday(row.Weekday), Start: start, End: end, SlotMinutes: row.SlotMinutes})
+	}
+	return hours
+}
+
+func toException(date time.Time, startTime, endTime string, slotMinutes int, kind string) (slots.Exception, error) {
+	e := slots.Exception{Date: date, Kind: kind, SlotMinutes: slotMinutes}
+	var err error
+	if startTime != "" || endTime != "" {
+		if e.Start, err = slots.ParseClock(startTime); err != nil {
+			return e, err
+		}
+		if e.End, err = slots.ParseClock(endTime); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// freeSlots returns the free slots of a doctor in the days [from, to) of loc
+func freeSlots(core requestCore.RequestCoreInterface, loc *time.Location, doctorID string, from, to time.Time) ([]slots.Interval, error) {
+	hourRows, err := libQuery.GetQuery[models.WorkingHoursRow](workingHours.SQL(), core.GetDB(), doctorID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_WORKING_HOURS", err.Error())
+	}
+	exceptionRows, err := libQuery.GetQuery[models.DoctorExceptionRow](doctorExceptions.SQL(), core.GetDB(), doctorID,
+		from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_EXCEPTIONS", err.Error())
+	}
+	busyRows, err := libQuery.GetQuery[models.BusyRow](busySlots.SQL(), core.GetDB(), doctorID, from, to)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_BOOKINGS", err.Error())
+	}
+
+	exceptions := make([]slots.Exception, 0, len(exceptionRows))
+	for _, row := range exceptionRows {
+		e, err := toException(row.Date, string(row.StartTime), string(row.EndTime), row.SlotMinutes, row.Kind)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "INVALID_EXCEPTION", err.Error())
+		}
+		exceptions = append(exceptions, e)
+	}
+	busy := make([]slots.Interval, 0, len(busyRows))
+	for _, row := range busyRows {
+		busy = append(busy, slots.Interval{Start: row.Start, End: row.End})
+	}
+	return slots.Generate(loc, from, to, toHours(hourRows), exceptions, busy, time.Now()), nil
+}
+
+// day returns midnight of the date in loc, dates are parsed as UTC midnight
+func day(date time.Time, loc *time.Location) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// dayRange reads the from and to days of a request in the calendar it selects as days of loc, from
+// defaults to today and a missing to is the zero time
+func dayRange(w webFramework.WebFramework, calendar, from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	calendar, err := jalali.FromRequest(w, calendar)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start, err := jalali.ParseDay(calendar, from, "from", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := jalali.ParseDay(calendar, to, "to", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if start.IsZero() {
+		start = day(time.Now().In(loc), loc)
+	}
+	return start, end, nil
+}
+
+// isDoubleBooking reports whether err is a violation of the exclusion constraint on visit slots
+func isDoubleBooking(err error) bool {
+	return dialect.Is(err, dialect.ExclusionViolation, "visits_no_double_booking")
+}
+
+type hoursHandler struct {
+	Name string
+}
+
+// returns handler title
+func (h hoursHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.NoBinding
+	if h.Name == "hours-put" {
+		body = libRequest.JSON
+	}
+	return handlers.HandlerParameters{
+		Title:          "appointments",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/doctors/:id/hours",
+	}
+}
+
+// runs after validating request
+func (h hoursHandler) Initializer(req handlers.HandlerRequest[models.WorkingHoursRequest, *models.WorkingHoursResponse]) error {
+	req.Request.DoctorID = req.W.Parser.GetUrlParam("id")
+	if h.Name != "hours-put" {
+		return nil
+	}
+	fields, err := validation.Check(req.Request)
+	if err != nil || len(fields) > 0 {
+		return validation.Fail(fields, err)
+	}
+	hours := make([]slots.Hours, 0, len(req.Request.Hours))
+	for i, entry := range req.Request.Hours {
+		start, err := slots.ParseClock(entry.StartTime)
+		if err != nil {
+			fields = append(fields, scheduleField(fmt.Sprintf("hours[%d].start_time", i), err))
+		}
+		end, err := slots.ParseClock(entry.EndTime)
+		if err != nil {
+			fields = append(fields, scheduleField(fmt.Sprintf("hours[%d].end_time", i), err))
+		}
+		req.Request.Hours[i].StartTime, req.Request.Hours[i].EndTime = start.String(), end.String()
+		hours = append(hours, slots.Hours{Weekday: time.Weekday(entry.Weekday), Start: start, End: end, SlotMinutes: entry.SlotMinutes})
+	}
+	if len(fields) == 0 {
+		if err := slots.ValidateHours(hours); err != nil {
+			fields = append(fields, scheduleField("hours", err))
+		}
+	}
+	return validation.Fail(fields, nil)
+}
+
+// scheduleField is the error of a rule of utils/slots, clocks, ranges and overlaps are checked
+// there so they can not be tags
+func scheduleField(field string, err error) validation.FieldError {
+	return validation.FieldError{Field: field, Code: i18n.ValidationInvalid, Rule: "schedule", Description: field + ": " + err.Error()}
+}
+
+// Handler is the main method that handles request and returns the response
+func (h hoursHandler) Handler(req handlers.HandlerRequest[models.WorkingHoursRequest, *models.WorkingHoursResponse]) (*models.WorkingHoursResponse, error) {
+	if err := getDoctor(req.Core, req.Request.DoctorID); err != nil {
+		return nil, err
+	}
+	req.Response = &models.WorkingHoursResponse{Result: libQuery.DmlResult{Success: true}}
+	switch h.Name {
+	case "hours-get":
+
+	case "hours-put":
+		hours, err := json.Marshal(req.Request.Hours)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		result, err := req.Core.GetDB().InsertRow(replaceHours.SQL(), req.Request.DoctorID, string(hours))
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	rows, err := libQuery.GetQuery[models.WorkingHoursRow](workingHours.SQL(), req.Core.GetDB(), req.Request.DoctorID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_WORKING_HOURS", err.Error())
+	}
+	req.Response.Hours = rows
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h hoursHandler) Simulation(req handlers.HandlerRequest[models.WorkingHoursRequest, *models.WorkingHoursResponse]) (*models.WorkingHoursResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h hoursHandler) Finalizer(req handlers.HandlerRequest[models.WorkingHoursRequest, *models.WorkingHoursResponse]) {
+}
+
+type exceptionsHandler struct {
+	Name     string
+	Location *time.Location
+}
+
+// returns handler title
+func (h exceptionsHandler) Parameters() handlers.HandlerParameters {
+	body, path := libRequest.Query, "/doctors/:id/exceptions"
+	switch h.Name {
+	case "exceptions-post":
+		body = libRequest.JSON
+	case "exceptions-delete":
+		body, path = libRequest.NoBinding, "/doctors/:id/exceptions/:exceptionId"
+	}
+	return handlers.HandlerParameters{
+		Title:          "appointments",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           path,
+	}
+}
+
+// runs after validating request
+func (h exceptionsHandler) Initializer(req handlers.HandlerRequest[models.DoctorExceptionRequest, *models.DoctorExceptionResponse]) error {
+	req.Request.DoctorID = req.W.Parser.GetUrlParam("id")
+	switch h.Name {
+	case "exceptions-get":
+		from, to, err := dayRange(req.W, req.Request.Calendar, req.Request.From, req.Request.To, h.Location)
+		if err != nil {
+			return err
+		}
+		if to.IsZero() {
+			to = from.AddDate(1, 0, 0)
+		}
+		if to.Before(from) {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_DATE_RANGE", "from must not be after to")
+		}
+		// the days are gregorian from here on and the to day is exclusive
+		req.Request.From, req.Request.To = from.Format(time.DateOnly), to.AddDate(0, 0, 1).Format(time.DateOnly)
+
+	case "exceptions-post":
+		req.Request.Kind = strings.ToLower(strings.TrimSpace(req.Request.Kind))
+		if req.Request.Kind != slots.Extra {
+			req.Request.SlotMinutes = 0
+		}
+		calendar, err := jalali.FromRequest(req.W, req.Request.Calendar)
+		if err != nil {
+			return err
+		}
+		if calendar != jalali.Gregorian {
+			// the day is checked and stored in the gregorian calendar
+			date, err := jalali.ParseDay(calendar, req.Request.Date, "date", h.Location)
+			if err != nil {
+				return err
+			}
+			if !date.IsZero() {
+				req.Request.Date = date.Format(time.DateOnly)
+			}
+		}
+		fields, err := validation.Check(req.Request)
+		if err != nil || len(fields) > 0 {
+			return validation.Fail(fields, err)
+		}
+		date, _ := time.Parse(time.DateOnly, req.Request.Date)
+		e, err := toException(date, req.Request.StartTime, req.Request.EndTime, req.Request.SlotMinutes, req.Request.Kind)
+		if err == nil {
+			err = slots.ValidateException(e)
+		}
+		if err != nil {
+			return validation.Fail([]validation.FieldError{scheduleField("start_time", err)}, nil)
+		}
+		if req.Request.StartTime != "" {
+			req.Request.StartTime, req.Request.EndTime = e.Start.String(), e.End.String()
+		}
+
+	case "exceptions-delete":
+		req.Request.ExceptionID = req.W.Parser.GetUrlParam("exceptionId")
+		if req.Request.ExceptionID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_EXCEPTION_ID", "exception id is required")
+		}
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h exceptionsHandler) Handler(req handlers.HandlerRequest[models.DoctorExceptionRequest, *models.DoctorExceptionResponse]) (*models.DoctorExceptionResponse, error) {
+	if err := getDoctor(req.Core, req.Request.DoctorID); err != nil {
+		return nil, err
+	}
+	switch h.Name {
+	case "exceptions-get":
+		rows, err := libQuery.GetQuery[models.DoctorExceptionRow](doctorExceptions.SQL(), req.Core.GetDB(), req.Request.DoctorID,
+			req.Request.From, req.Request.To)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_EXCEPTIONS", err.Error())
+		}
+		req.Response = &models.DoctorExceptionResponse{
+			Result:     libQuery.DmlResult{Success: true},
+			Exceptions: rows,
+		}
+		return req.Response, nil
+
+	case "exceptions-post":
+		result, err := req.Core.GetDB().InsertRow(insertException.SQL(), req.Request.DoctorID, req.Request.Date,
+			req.Request.StartTime, req.Request.EndTime, req.Request.SlotMinutes, req.Request.Kind, strings.TrimSpace(req.Request.Reason))
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response = &models.DoctorExceptionResponse{Result: libQuery.GetDmlResult(result, nil)}
+		return req.Response, nil
+
+	case "exceptions-delete":
+		result, err := req.Core.GetDB().InsertRow(deleteException.SQL(), req.Request.DoctorID, req.Request.ExceptionID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "EXCEPTION_NOT_FOUND", "exception %s not found", req.Request.ExceptionID)
+		}
+		req.Response = &models.DoctorExceptionResponse{Result: libQuery.GetDmlResult(result, nil)}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h exceptionsHandler) Simulation(req handlers.HandlerRequest[models.DoctorExceptionRequest, *models.DoctorExceptionResponse]) (*models.DoctorExceptionResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h exceptionsHandler) Finalizer(req handlers.HandlerRequest[models.DoctorExceptionRequest, *models.DoctorExceptionResponse]) {
+}
+
+type slotsHandler struct {
+	Location *time.Location
+}
+
+// returns handler title
+func (h slotsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "appointments",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/doctors/:id/slots",
+	}
+}
+
+// runs after validating request
+func (h slotsHandler) Initializer(req handlers.HandlerRequest[models.SlotsRequest, *models.SlotsResponse]) error {
+	return nil
+}
+
+// slotRange returns the days [from, to) to search the slots of a request in, the to day of a request
+// is inclusive and defaults to a week from its from day
+func slotRange(w webFramework.WebFramework, request *models.SlotsRequest, loc *time.Location) (time.Time, time.Time, error) {
+	from, to, err := dayRange(w, request.Calendar, request.From, request.To, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, 0, 6)
+	}
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, libError.NewWithDescription(http.StatusBadRequest, "INVALID_DATE_RANGE", "from must not be after to")
+	}
+	if to.After(from.AddDate(0, 0, MaxSlotDays)) {
+		return time.Time{}, time.Time{}, libError.NewWithDescription(http.StatusBadRequest, "INVALID_DATE_RANGE", "at most %d days can be searched", MaxSlotDays)
+	}
+	return from, to, nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h slotsHandler) Handler(req handlers.HandlerRequest[models.SlotsRequest, *models.SlotsResponse]) (*models.SlotsResponse, error) {
+	doctorID := req.W.Parser.GetUrlParam("id")
+	if err := getDoctor(req.Core, doctorID); err != nil {
+		return nil, err
+	}
+	from, to, err := slotRange(req.W, req.Request, h.Location)
+	if err != nil {
+		return nil, err
+	}
+	free, err := freeSlots(req.Core, h.Location, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	req.Response = &models.SlotsResponse{
+		DoctorID: doctorID,
+		Timezone: h.Location.String(),
+		Slots:    make([]models.Slot, 0, len(free)),
+	}
+	for _, slot := range free {
+		req.Response.Slots = append(req.Response.Slots, models.Slot{Start: slot.Start, End: slot.End})
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h slotsHandler) Simulation(req handlers.HandlerRequest[models.SlotsRequest, *models.SlotsResponse]) (*models.SlotsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h slotsHandler) Finalizer(req handlers.HandlerRequest[models.SlotsRequest, *models.SlotsResponse]) {
+}
+
+type bookingHandler struct {
+	Location *time.Location
+}
+
+// returns handler title
+func (h bookingHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "appointments",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/doctors/:id/bookings",
+	}
+}
+
+// runs after validating request
+func (h bookingHandler) Initializer(req handlers.HandlerRequest[models.BookingRequest, *models.BookingResponse]) error {
+	req.Request.DoctorID = req.W.Parser.GetUrlParam("id")
+	if err := validation.Error(req.Request); err != nil {
+		return err
+	}
+	if req.Request.VisitType == "" {
+		req.Request.VisitType = "general"
+	}
+	return nil
+}
+
+// Handler books a free slot as a scheduled visit, two bookings racing for the same slot are
+// decided by the exclusion constraint on the visit slots
+func (h bookingHandler) Handler(req handlers.HandlerRequest[models.BookingRequest, *models.BookingResponse]) (*models.BookingResponse, error) {
+	if err := getDoctor(req.Core, req.Request.DoctorID); err != nil {
+		return nil, err
+	}
+	patients, err := libQuery.GetQuery[models.PatientIdentityRow](patientByID.SQL(), req.Core.GetDB(), req.Request.PatientID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_PATIENT", err.Error())
+	}
+	if len(patients) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "PATIENT_NOT_FOUND", "patient %s not found", req.Request.PatientID)
+	}
+
+	start := req.Request.Start.In(h.Location)
+	from := day(start, h.Location)
+	free, err := freeSlots(req.Core, h.Location, req.Request.DoctorID, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	slot, ok := slots.Find(free, start)
+	if !ok {
+		return nil, libError.NewWithDescription(http.StatusConflict, "SLOT_NOT_AVAILABLE", "%s is not a free slot of doctor %s", start.Format(time.RFC3339), req.Request.DoctorID)
+	}
+
+	visitID := storage.NewID()
+	result, err := req.Core.GetDB().InsertRow(insertBooking.SQL(), visitID, req.Request.PatientID, req.Request.DoctorID,
+		req.Request.VisitType, slot.Start, strings.TrimSpace(req.Request.ChiefComplaint), strings.TrimSpace(req.Request.Notes), slot.End)
+	if err != nil {
+		if isDoubleBooking(err) {
+			return nil, libError.NewWithDescription(http.StatusConflict, "SLOT_TAKEN", "%s was booked by someone else", start.Format(time.RFC3339))
+		}
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+	}
+	req.Response = &models.BookingResponse{
+		Result:  libQuery.GetDmlResult(result, nil),
+		VisitID: visitID,
+		Slot:    models.Slot{Start: slot.Start, End: slot.End},
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h bookingHandler) Simulation(req handlers.HandlerRequest[models.BookingRequest, *models.BookingResponse]) (*models.BookingResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h bookingHandler) Finalizer(req handlers.HandlerRequest[models.BookingRequest, *models.BookingResponse]) {
+}
+
+// hoursGetHandler godoc
+// @Summary Get weekly working hours of a doctor
+// @Description Get the weekly working hours of a doctor, weekday 0 is Sunday and times are in the clinic time zone
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Router /doctors/:id/hours [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.WorkingHoursResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) hoursGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.WorkingHoursRequest, *models.WorkingHoursResponse, hoursHandler](env.Interface, hoursHandler{Name: "hours-get"}, simulation)
+}
+
+// hoursPutHandler godoc
+// @Summary Replace weekly working hours of a doctor
+// @Description Replace the whole week of working hours, each range is split into slots of slot_minutes, an empty list removes all hours
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param hours body models.WorkingHoursRequest true "Working hours"
+// @Router /doctors/:id/hours [put]
+// @Security OAuth2Password
+// @Success 200 {object} models.WorkingHoursResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) hoursPutHandler(simulation bool) any {
+	return handlers.BaseHandler[models.WorkingHoursRequest, *models.WorkingHoursResponse, hoursHandler](env.Interface, hoursHandler{Name: "hours-put"}, simulation)
+}
+
+// exceptionsGetHandler godoc
+// @Summary Get schedule exceptions of a doctor
+// @Description Get leave, holidays and extra hours of a doctor, defaults to the next year
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /doctors/:id/exceptions [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.DoctorExceptionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) exceptionsGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DoctorExceptionRequest, *models.DoctorExceptionResponse, exceptionsHandler](env.Interface, exceptionsHandler{Name: "exceptions-get", Location: env.Location}, simulation)
+}
+
+// exceptionsPostHandler godoc
+// @Summary Add a schedule exception for a doctor
+// @Description Add leave or a holiday, for the whole day or a time range, or extra hours on a date
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param X-Calendar header string false "Calendar of date" Enums(gregorian, jalali)
+// @Param exception body models.DoctorExceptionRequest true "Exception"
+// @Router /doctors/:id/exceptions [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.DoctorExceptionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) exceptionsPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DoctorExceptionRequest, *models.DoctorExceptionResponse, exceptionsHandler](env.Interface, exceptionsHandler{Name: "exceptions-post", Location: env.Location}, simulation)
+}
+
+// exceptionsDeleteHandler godoc
+// @Summary Delete a schedule exception of a doctor
+// @Description Delete leave, a holiday or extra hours, bookings made meanwhile are not affected
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param exceptionId path string true "Exception ID"
+// @Router /doctors/:id/exceptions/:exceptionId [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.DoctorExceptionResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) exceptionsDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DoctorExceptionRequest, *models.DoctorExceptionResponse, exceptionsHandler](env.Interface, exceptionsHandler{Name: "exceptions-delete", Location: env.Location}, simulation)
+}
+
+// slotsGetHandler godoc
+// @Summary Get free slots of a doctor
+// @Description Get the free slots of a doctor from the working hours and exceptions, without booked and past slots, defaults to the next seven days
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /doctors/:id/slots [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.SlotsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) slotsGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.SlotsRequest, *models.SlotsResponse, slotsHandler](env.Interface, slotsHandler{Location: env.Location}, simulation)
+}
+
+// bookingPostHandler godoc
+// @Summary Book a slot of a doctor
+// @Description Book a free slot as a scheduled visit, a slot booked by someone else meanwhile is rejected with 409
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Doctor ID"
+// @Param booking body models.BookingRequest true "Booking"
+// @Router /doctors/:id/bookings [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env appointmentsEnv) bookingPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.BookingRequest, *models.BookingResponse, bookingHandler](env.Interface, bookingHandler{Location: env.Location}, simulation)
+}
@@ -0,0 +1,199 @@
+package appointments
+
+import "healthcare/utils/dialect"
+
+var (
+	doctorByID = dialect.Query{
+		Postgres: `--sql
+			SELECT d.id, COALESCE(pr.full_name, '') AS name
+			  FROM public.doctors d
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE d.id = :1
+		`,
+		Oracle: `--sql
+			SELECT d.id, pr.full_name AS name
+			  FROM doctors d
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE d.id = :1
+		`,
+	}
+	patientByID = dialect.Query{
+		Postgres: `--sql
+			SELECT p.id, p.patient_id AS patient_number, COALESCE(p.full_name, '') AS full_name
+			  FROM public.patients p
+			 WHERE p.id = :1
+		`,
+		Oracle: `--sql
+			SELECT p.id, p.patient_id AS patient_number, p.full_name
+			  FROM patients p
+			 WHERE p.id = :1
+		`,
+	}
+	// Oracle keeps the times of working hours and exceptions as HH24:MI text
+	workingHours = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				h.id,
+				h.doctor_id,
+				h.weekday,
+				to_char(h.start_time, 'HH24:MI') AS start_time,
+				to_char(h.end_time, 'HH24:MI') AS end_time,
+				h.slot_minutes
+			  FROM public.doctor_working_hours h
+			 WHERE h.doctor_id = :1
+			 ORDER BY h.weekday, h.start_time
+		`,
+		Oracle: `--sql
+			SELECT h.id, h.doctor_id, h.weekday, h.start_time, h.end_time, h.slot_minutes
+			  FROM doctor_working_hours h
+			 WHERE h.doctor_id = :1
+			 ORDER BY h.weekday, h.start_time
+		`,
+	}
+	// the week is replaced in one statement so readers never see it half written
+	replaceHours = dialect.Query{
+		Postgres: `--sql
+			WITH removed AS (
+				DELETE FROM public.doctor_working_hours WHERE doctor_id = :1
+			)
+			INSERT INTO public.doctor_working_hours (doctor_id, weekday, start_time, end_time, slot_minutes)
+			SELECT :1, h.weekday, h.start_time::TIME, h.end_time::TIME, h.slot_minutes
+			  FROM jsonb_to_recordset(:2::JSONB) AS h(weekday INT, start_time TEXT, end_time TEXT, slot_minutes INT)
+		`,
+		Oracle: `--sql
+			BEGIN
+				DELETE FROM doctor_working_hours WHERE doctor_id = :1;
+				INSERT INTO doctor_working_hours (doctor_id, weekday, start_time, end_time, slot_minutes)
+				SELECT :1, h.weekday, h.start_time, h.end_time, h.slot_minutes
+				  FROM JSON_TABLE(:2, '$[*]' COLUMNS (
+					weekday NUMBER PATH '$.weekday',
+					start_time VARCHAR2(5) PATH '$.start_time',
+					end_time VARCHAR2(5) PATH '$.end_time',
+					slot_minutes NUMBER PATH '$.slot_minutes'
+				  )) h;
+			END;
+		`,
+	}
+	doctorExceptions = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				e.id,
+				e.doctor_id,
+				e.exception_date,
+				COALESCE(to_char(e.start_time, 'HH24:MI'), '') AS start_time,
+				COALESCE(to_char(e.end_time, 'HH24:MI'), '') AS end_time,
+				COALESCE(e.slot_minutes, 0) AS slot_minutes,
+				e.kind,
+				COALESCE(e.reason, '') AS reason
+			  FROM public.doctor_exceptions e
+			 WHERE e.doctor_id = :1
+			   AND e.exception_date >= :2
+			   AND e.exception_date < :3
+			 ORDER BY e.exception_date, e.start_time NULLS FIRST
+		`,
+		Oracle: `--sql
+			SELECT
+				e.id,
+				e.doctor_id,
+				e.exception_date,
+				e.start_time,
+				e.end_time,
+				COALESCE(e.slot_minutes, 0) AS slot_minutes,
+				e.kind,
+				e.reason
+			  FROM doctor_exceptions e
+			 WHERE e.doctor_id = :1
+			   AND e.exception_date >= TO_DATE(:2, 'YYYY-MM-DD')
+			   AND e.exception_date < TO_DATE(:3, 'YYYY-MM-DD')
+			 ORDER BY e.exception_date, e.start_time NULLS FIRST
+		`,
+	}
+	insertException = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.doctor_exceptions (doctor_id, exception_date, start_time, end_time, slot_minutes, kind, reason)
+			VALUES (:1, :2::DATE, NULLIF(:3, '')::TIME, NULLIF(:4, '')::TIME, NULLIF(:5, 0), :6, :7)
+		`,
+		Oracle: `--sql
+			INSERT INTO doctor_exceptions (doctor_id, exception_date, start_time, end_time, slot_minutes, kind, reason)
+			VALUES (:1, TO_DATE(:2, 'YYYY-MM-DD'), :3, :4, NULLIF(:5, 0), :6, :7)
+		`,
+	}
+	deleteException = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.doctor_exceptions WHERE doctor_id = :1 AND id = :2
+		`,
+		Oracle: `--sql
+			DELETE FROM doctor_exceptions WHERE doctor_id = :1 AND id = :2
+		`,
+	}
+	// Oracle has no range types, a booked slot is kept as its start and end
+	busySlots = dialect.Query{
+		Postgres: `--sql
+			SELECT lower(v.slot) AS slot_start, upper(v.slot) AS slot_end
+			  FROM public.visits v
+			 WHERE v.doctor_id = :1
+			   AND v.slot && tstzrange(:2, :3)
+			   AND v.status NOT IN ('cancelled', 'no_show')
+		`,
+		Oracle: `--sql
+			SELECT v.slot_start, v.slot_end
+			  FROM visits v
+			 WHERE v.doctor_id = :1
+			   AND v.slot_end > :2
+			   AND v.slot_start < :3
+			   AND v.status NOT IN ('cancelled', 'no_show')
+		`,
+	}
+	// insertBooking fails with visits_no_double_booking when the slot overlaps an active visit of the
+	// doctor. Oracle has no exclusion constraints, bookings of a doctor lock the doctor row and check
+	// the overlap themselves, and the visits_no_double_booking_trg trigger checks every other write
+	insertBooking = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.visits
+				(id, patient_id, doctor_id, visit_type, visit_date, status, chief_complaint, notes, slot)
+			VALUES (:1, :2, :3, :4, :5, 'scheduled', NULLIF(:6, ''), NULLIF(:7, ''), tstzrange(:5, :8, '[)'))
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_id visits.id%TYPE := :1;
+				v_patient visits.patient_id%TYPE := :2;
+				v_doctor visits.doctor_id%TYPE := :3;
+				v_type visits.visit_type%TYPE := :4;
+				v_start visits.slot_start%TYPE := :5;
+				v_complaint visits.chief_complaint%TYPE := :6;
+				v_notes visits.notes%TYPE := :7;
+				v_end visits.slot_end%TYPE := :8;
+				v_locked doctors.id%TYPE;
+			BEGIN
+				SELECT id INTO v_locked FROM doctors WHERE id = v_doctor FOR UPDATE;
+				INSERT INTO visits
+					(id, patient_id, doctor_id, visit_type, visit_date, status, chief_complaint, notes, slot_start, slot_end)
+				SELECT v_id, v_patient, v_doctor, v_type, v_start, 'scheduled', v_complaint, v_notes, v_start, v_end
+				  FROM dual
+				 WHERE NOT EXISTS (
+					SELECT 1 FROM visits v
+					 WHERE v.doctor_id = v_doctor
+					   AND v.slot_start < v_end
+					   AND v.slot_end > v_start
+					   AND v.status NOT IN ('cancelled', 'no_show'));
+				IF SQL%ROWCOUNT = 0 THEN
+					RAISE_APPLICATION_ERROR(-20001, 'visits_no_double_booking: the slot overlaps another visit');
+				END IF;
+			END;
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"busySlots":        busySlots,
+	"deleteException":  deleteException,
+	"doctorByID":       doctorByID,
+	"doctorExceptions": doctorExceptions,
+	"insertBooking":    insertBooking,
+	"insertException":  insertException,
+	"patientByID":      patientByID,
+	"replaceHours":     replaceHours,
+	"workingHours":     workingHours,
+}
@@ -0,0 +1,45 @@
+package appointments
+
+import (
+	"healthcare/models"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// LoadLocation loads the clinic time zone configured in params, the local time zone is used when none is configured
+func LoadLocation(wsParams *libParams.ApplicationParams[models.ApplicationParams]) *time.Location {
+	if len(wsParams.Specific.Timezone) == 0 {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(wsParams.Specific.Timezone)
+	if err != nil {
+		log.Fatalln("error loading time zone", err)
+	}
+	return loc
+}
+
+func AddAppointmentsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	simulation bool,
+) {
+	env := &appointmentsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Location:  LoadLocation(wsParams),
+	}
+	rg.GET("/doctors/:id/hours", libGin.Gin(env.hoursGetHandler(simulation)))
+	rg.PUT("/doctors/:id/hours", libGin.Gin(env.hoursPutHandler(simulation)))
+	rg.GET("/doctors/:id/exceptions", libGin.Gin(env.exceptionsGetHandler(simulation)))
+	rg.POST("/doctors/:id/exceptions", libGin.Gin(env.exceptionsPostHandler(simulation)))
+	rg.DELETE("/doctors/:id/exceptions/:exceptionId", libGin.Gin(env.exceptionsDeleteHandler(simulation)))
+	rg.GET("/doctors/:id/slots", libGin.Gin(env.slotsGetHandler(simulation)))
+	rg.POST("/doctors/:id/bookings", libGin.Gin(env.bookingPostHandler(simulation)))
+}
@@ -0,0 +1,206 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"healthcare/controllers/locale"
+	"healthcare/controllers/therapyschedules"
+	"healthcare/controllers/visits"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/ical"
+	"healthcare/utils/therapy"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Defaults of the feed
+const (
+	DefaultRefresh      = time.Hour
+	DefaultPastDays     = 30
+	DefaultFutureDays   = 180
+	DefaultVisitMinutes = 30
+)
+
+// ProductID identifies the feeds to calendar apps
+const ProductID = "-//healthcare//calendar//EN"
+
+// uidDomain makes the event UIDs globally unique
+const uidDomain = "healthcare"
+
+// NewToken returns a random feed token and the hash it is stored as
+func NewToken() (token, hash string) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token)
+}
+
+// HashToken returns the hash a feed token is stored as
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// owner returns the doctor and patient records of a user
+func owner(core requestCore.RequestCoreInterface, userID string) (*models.CalendarOwnerRow, error) {
+	rows, err := libQuery.GetQuery[models.CalendarOwnerRow](calendarOwner.SQL(), core.GetDB(), userID)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// Feed serves the iCalendar feeds of visits and therapy sessions, the secret token in the url
+// stands in for authentication since calendar apps cannot send one
+type Feed struct {
+	Core       requestCore.RequestCoreInterface
+	Therapies  *therapyschedules.Tracker
+	Location   *time.Location
+	Refresh    time.Duration
+	PastDays   int
+	FutureDays int
+}
+
+// Serve writes the feed of the token in the url
+func (f *Feed) Serve(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	feeds, err := libQuery.GetQuery[models.CalendarFeedRow](feedByHash.SQL(), f.Core.GetDB(), HashToken(token))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if len(feeds) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.FeedNotFound, "calendar feed not found")
+		return
+	}
+	user, err := owner(f.Core, feeds[0].UserID)
+	if err != nil || user == nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, fmt.Sprint("loading calendar owner: ", err))
+		return
+	}
+
+	now := time.Now().In(f.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, f.Location)
+	from, to := today.AddDate(0, 0, -f.PastDays), today.AddDate(0, 0, f.FutureDays+1)
+	events, err := f.events(user, from, to)
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if _, err := f.Core.GetDB().InsertRow(touchFeed.SQL(), feeds[0].UserID); err != nil {
+		log.Println("calendar: recording fetch", err)
+	}
+
+	name := "Clinic"
+	if user.Name != "" {
+		name = "Clinic - " + string(user.Name)
+	}
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	cal := ical.Calendar{ProductID: ProductID, Name: name, TimeZone: f.Location.String(), Refresh: f.Refresh}
+	if err := ical.Write(c.Writer, cal, events); err != nil {
+		log.Println("calendar: writing feed", err)
+	}
+}
+
+// events returns the visits and therapy sessions of the user in [from, to), a user who is a doctor
+// sees the visits and therapies they run and a patient the ones they attend
+func (f *Feed) events(user *models.CalendarOwnerRow, from, to time.Time) ([]ical.Event, error) {
+	rows, err := visits.CalendarVisits(f.Core, string(user.DoctorID), string(user.PatientID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	var events []ical.Event
+	for _, visit := range rows {
+		events = append(events, f.visitEvent(user, visit))
+	}
+	therapies, err := f.Therapies.CalendarSessions(string(user.DoctorID), string(user.PatientID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range therapies {
+		for _, session := range entry.Sessions {
+			events = append(events, f.sessionEvent(user, &entry.Schedule, session))
+		}
+	}
+	return events, nil
+}
+
+// visitEvent returns the event of a visit, a visit that is cancelled or missed is marked cancelled
+func (f *Feed) visitEvent(user *models.CalendarOwnerRow, visit models.CalendarVisitRow) ical.Event {
+	summary := "Visit"
+	if user.DoctorID != "" && visit.DoctorID == string(user.DoctorID) {
+		summary = "Visit: " + string(visit.PatientName)
+	} else if visit.DoctorName != "" {
+		summary = "Visit with " + string(visit.DoctorName)
+	}
+	end := visit.SlotEnd
+	if !end.After(visit.VisitDate) {
+		end = visit.VisitDate.Add(DefaultVisitMinutes * time.Minute)
+	}
+	status := ical.Confirmed
+	if visit.Status == "cancelled" || visit.Status == "no_show" {
+		status = ical.Cancelled
+	}
+	return ical.Event{
+		UID:          fmt.Sprintf("visit-%s@%s", visit.ID, uidDomain),
+		Start:        visit.VisitDate,
+		End:          end,
+		Summary:      summary,
+		Description:  visit.VisitType,
+		Status:       status,
+		Sequence:     visit.UpdatedAt.Unix(),
+		LastModified: visit.UpdatedAt,
+	}
+}
+
+// sessionEvent returns the all-day event of a therapy session, its UID keeps the day the rule put it
+// on so a session moved off a holiday updates the same event. Sessions after a schedule ended are
+// marked cancelled
+func (f *Feed) sessionEvent(user *models.CalendarOwnerRow, schedule *models.TherapyScheduleRow, session therapy.Session) ical.Event {
+	summary := schedule.TherapyType
+	if user.DoctorID != "" && schedule.DoctorID == string(user.DoctorID) {
+		summary += ": " + string(schedule.PatientName)
+	} else if schedule.DoctorName != "" {
+		summary += " with " + string(schedule.DoctorName)
+	}
+	original := session.Date
+	if session.Original != nil {
+		original = *session.Original
+	}
+	status := ical.Confirmed
+	if schedule.Status != therapy.Active {
+		ended := schedule.EndedAt.In(f.Location)
+		if !session.Date.Before(time.Date(ended.Year(), ended.Month(), ended.Day(), 0, 0, 0, 0, time.UTC)) {
+			status = ical.Cancelled
+		}
+	}
+	description := string(schedule.Instructions)
+	if schedule.Duration > 0 {
+		description = strings.TrimSpace(fmt.Sprintf("%d minutes\n%s", schedule.Duration, description))
+	}
+	return ical.Event{
+		UID:          fmt.Sprintf("therapy-%s-%s@%s", schedule.ID, original.Format("20060102"), uidDomain),
+		Start:        session.Date,
+		AllDay:       true,
+		Summary:      summary,
+		Description:  description,
+		Status:       status,
+		Sequence:     schedule.UpdatedAt.Unix(),
+		LastModified: schedule.UpdatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"net/http"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type calendarEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	// FeedURL is the url of the feeds without the token
+	FeedURL string
+}
+
+type tokenHandler struct {
+	Name    string
+	FeedURL string
+}
+
+// returns handler title
+func (h tokenHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "calendar",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/calendar/token",
+	}
+}
+
+// runs after validating request
+func (h tokenHandler) Initializer(req handlers.HandlerRequest[models.CalendarTokenRequest, *models.CalendarTokenResponse]) error {
+	user, err := ums.CurrentUser(req.W, req.Core)
+	if err != nil {
+		return err
+	}
+	req.Request.UserID = user.UserId
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h tokenHandler) Handler(req handlers.HandlerRequest[models.CalendarTokenRequest, *models.CalendarTokenResponse]) (*models.CalendarTokenResponse, error) {
+	req.Response = &models.CalendarTokenResponse{Result: libQuery.DmlResult{Success: true}}
+	switch h.Name {
+	case "calendar-token-get":
+		feeds, err := libQuery.GetQuery[models.CalendarFeedRow](feedByUser.SQL(), req.Core.GetDB(), req.Request.UserID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_CALENDAR_FEED", err.Error())
+		}
+		if len(feeds) > 0 {
+			req.Response.Exists = true
+			req.Response.CreatedAt = feeds[0].CreatedAt
+			req.Response.LastFetchedAt = feeds[0].LastFetchedAt
+		}
+
+	case "calendar-token-post":
+		user, err := owner(req.Core, req.Request.UserID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_CALENDAR_OWNER", err.Error())
+		}
+		if user == nil || (user.DoctorID == "" && user.PatientID == "") {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "NO_CALENDAR", "user %s is neither a doctor nor a patient", req.Request.UserID)
+		}
+		token, hash := NewToken()
+		result, err := req.Core.GetDB().InsertRow(upsertFeed.SQL(), req.Request.UserID, hash)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+		req.Response.Exists = true
+		req.Response.Token = token
+		req.Response.URL = h.FeedURL + token + ".ics"
+
+	case "calendar-token-delete":
+		result, err := req.Core.GetDB().InsertRow(deleteFeed.SQL(), req.Request.UserID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "CALENDAR_FEED_NOT_FOUND", "user %s has no calendar feed", req.Request.UserID)
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h tokenHandler) Simulation(req handlers.HandlerRequest[models.CalendarTokenRequest, *models.CalendarTokenResponse]) (*models.CalendarTokenResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h tokenHandler) Finalizer(req handlers.HandlerRequest[models.CalendarTokenRequest, *models.CalendarTokenResponse]) {
+}
+
+// calendarTokenGetHandler godoc
+// @Summary Get the calendar feed of the current user
+// @Description Tell whether the current user has a calendar feed and when it was last fetched, the token itself is only shown when it is generated
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /calendar/token [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.CalendarTokenResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env calendarEnv) calendarTokenGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.CalendarTokenRequest, *models.CalendarTokenResponse, tokenHandler](env.Interface, tokenHandler{Name: "calendar-token-get"}, simulation)
+}
+
+// calendarTokenPostHandler godoc
+// @Summary Generate the calendar feed token of the current user
+// @Description Generate a secret feed url of the visits and therapy sessions of the current user for calendar apps, generating it again revokes the previous url
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /calendar/token [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.CalendarTokenResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env calendarEnv) calendarTokenPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.CalendarTokenRequest, *models.CalendarTokenResponse, tokenHandler](env.Interface,
+		tokenHandler{Name: "calendar-token-post", FeedURL: env.FeedURL}, simulation)
+}
+
+// calendarTokenDeleteHandler godoc
+// @Summary Revoke the calendar feed of the current user
+// @Description Revoke the feed url of the current user, calendar apps subscribed to it stop receiving updates
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /calendar/token [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.CalendarTokenResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env calendarEnv) calendarTokenDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.CalendarTokenRequest, *models.CalendarTokenResponse, tokenHandler](env.Interface, tokenHandler{Name: "calendar-token-delete"}, simulation)
+}
@@ -0,0 +1,81 @@
+package calendar
+
+import "healthcare/utils/dialect"
+
+var (
+	// calendarOwner finds the doctor and patient records of a user, either may be empty
+	calendarOwner = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				COALESCE((SELECT d.id::TEXT FROM public.doctors d WHERE d.profile_id::TEXT = :1 LIMIT 1), '') AS doctor_id,
+				COALESCE((SELECT p.id::TEXT FROM public.patients p WHERE p.profile_id::TEXT = :1 LIMIT 1), '') AS patient_id,
+				COALESCE((SELECT pr.full_name FROM public.profiles pr WHERE pr.id::TEXT = :1), '') AS name
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS user_id FROM dual)
+			SELECT
+				(SELECT d.id FROM doctors d WHERE d.profile_id = a.user_id FETCH FIRST 1 ROWS ONLY) AS doctor_id,
+				(SELECT p.id FROM patients p WHERE p.profile_id = a.user_id FETCH FIRST 1 ROWS ONLY) AS patient_id,
+				(SELECT pr.full_name FROM profiles pr WHERE pr.id = a.user_id) AS name
+			  FROM a
+		`,
+	}
+	// regenerating the token replaces the old one
+	upsertFeed = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.calendar_feeds (user_id, token_hash)
+			VALUES (:1, :2)
+			ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW(), last_fetched_at = NULL
+		`,
+		Oracle: `--sql
+			MERGE INTO calendar_feeds f
+			USING (SELECT :1 AS user_id, :2 AS token_hash FROM dual) a
+			   ON (f.user_id = a.user_id)
+			 WHEN MATCHED THEN UPDATE SET f.token_hash = a.token_hash, f.created_at = SYSTIMESTAMP, f.last_fetched_at = NULL
+			 WHEN NOT MATCHED THEN INSERT (user_id, token_hash) VALUES (a.user_id, a.token_hash)
+		`,
+	}
+	feedColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT f.user_id, f.created_at, COALESCE(f.last_fetched_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS last_fetched_at
+			  FROM public.calendar_feeds f
+		`,
+		Oracle: `--sql
+			SELECT f.user_id, f.created_at, COALESCE(f.last_fetched_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS last_fetched_at
+			  FROM calendar_feeds f
+		`,
+	}
+	feedByUser = feedColumns.Append(`
+		 WHERE f.user_id = :1
+	`)
+	feedByHash = feedColumns.Append(`
+		 WHERE f.token_hash = :1
+	`)
+	touchFeed = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.calendar_feeds SET last_fetched_at = NOW() WHERE user_id = :1
+		`,
+		Oracle: `--sql
+			UPDATE calendar_feeds SET last_fetched_at = SYSTIMESTAMP WHERE user_id = :1
+		`,
+	}
+	deleteFeed = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.calendar_feeds WHERE user_id = :1
+		`,
+		Oracle: `--sql
+			DELETE FROM calendar_feeds WHERE user_id = :1
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"calendarOwner": calendarOwner,
+	"deleteFeed":    deleteFeed,
+	"feedByHash":    feedByHash,
+	"feedByUser":    feedByUser,
+	"touchFeed":     touchFeed,
+	"upsertFeed":    upsertFeed,
+}
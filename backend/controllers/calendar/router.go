@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"healthcare/controllers/appointments"
+	"healthcare/controllers/therapyschedules"
+	"healthcare/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// AddCalendarRoutes sets up the calendar feed tokens of the current user and the feeds themselves
+func AddCalendarRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	therapies *therapyschedules.Tracker,
+	simulation bool,
+) {
+	params := wsParams.Specific.Calendar
+	env := &calendarEnv{
+		Interface: model,
+		Params:    wsParams,
+		FeedURL:   strings.TrimSuffix(params.PublicURL, "/") + rg.BasePath() + "/calendar/feed/",
+	}
+	feed := &Feed{
+		Core:       model,
+		Therapies:  therapies,
+		Location:   appointments.LoadLocation(wsParams),
+		Refresh:    time.Duration(params.RefreshMinutes) * time.Minute,
+		PastDays:   params.PastDays,
+		FutureDays: params.FutureDays,
+	}
+	if feed.Refresh <= 0 {
+		feed.Refresh = DefaultRefresh
+	}
+	if feed.PastDays <= 0 {
+		feed.PastDays = DefaultPastDays
+	}
+	if feed.FutureDays <= 0 {
+		feed.FutureDays = DefaultFutureDays
+	}
+
+	rg.GET("/calendar/token", libGin.Gin(env.calendarTokenGetHandler(simulation)))
+	rg.POST("/calendar/token", libGin.Gin(env.calendarTokenPostHandler(simulation)))
+	rg.DELETE("/calendar/token", libGin.Gin(env.calendarTokenDeleteHandler(simulation)))
+	rg.GET("/calendar/feed/:token", feed.Serve) // token-authenticated feed for calendar apps
+}
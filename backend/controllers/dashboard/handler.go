@@ -2,71 +2,185 @@ package dashboard
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
-	"charity-log/backend/models"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/jalali"
+	"healthcare/utils/validation"
 
-	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libRequest"
+	"github.com/hmmftg/requestCore/webFramework"
 )
 
-// DashboardStatsHandler handles GET requests for dashboard statistics
-func DashboardStatsHandler(c *gin.Context) {
-	// Get query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	doctorID := c.Query("doctor_id")
+// TopDiagnosesProvider aggregates coded diagnoses of visits in [start, end), doctorID is optional
+type TopDiagnosesProvider interface {
+	TopDiagnoses(start, end time.Time, doctorID string, limit int) ([]models.DiagnosisStats, error)
+}
 
-	// Parse dates
-	var startDate, endDate time.Time
-	var err error
+// ActiveTherapiesProvider counts the therapy schedules that are still active, doctorID is optional
+type ActiveTherapiesProvider interface {
+	ActiveTherapies(doctorID string) (int, error)
+}
 
-	if startDateStr != "" {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
-			return
-		}
-	} else {
-		startDate = time.Now().AddDate(0, -1, 0) // Default to 1 month ago
+// VisitsPerDayProvider counts the visits of each day in [start, end), doctorID is optional
+type VisitsPerDayProvider interface {
+	VisitsPerDay(start, end time.Time, doctorID string) ([]models.DailyVisitCount, error)
+}
+
+// Providers are the sources of the dashboard statistics, a missing one leaves its mock data. The
+// totals, visit types, patient statistics and visit statistics are still the sample data of the
+// baseline until they are read from the database, so they are not paged in a query
+type Providers struct {
+	Diagnoses TopDiagnosesProvider
+	Therapies ActiveTherapiesProvider
+	Visits    VisitsPerDayProvider
+}
+
+type dashboardEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Catalogue *i18n.Catalogue
+	Providers Providers
+	Location  *time.Location
+}
+
+// dateRange reads the start and end dates of a request in the calendar it selects with the calendar
+// query parameter or the X-Calendar header as days of the clinic time zone loc, they default to the
+// last month
+func dateRange(w webFramework.WebFramework, request *models.DashboardStatsRequest, loc *time.Location) (string, time.Time, time.Time, error) {
+	calendar, err := jalali.FromRequest(w, request.Calendar)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	startDate, err := jalali.ParseDay(calendar, request.StartDate, "start_date", loc)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	endDate, err := jalali.ParseDay(calendar, request.EndDate, "end_date", loc)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
 	}
+	if endDate.IsZero() {
+		y, m, d := time.Now().In(loc).Date()
+		endDate = time.Date(y, m, d, 0, 0, 0, 0, loc) // Default to today
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, -1, 0) // Default to 1 month ago
+	}
+	if endDate.Before(startDate) {
+		return "", time.Time{}, time.Time{}, libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidDateRange, "end_date is before start_date")
+	}
+	return calendar, startDate, endDate, nil
+}
 
-	if endDateStr != "" {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
-			return
-		}
-	} else {
-		endDate = time.Now() // Default to now
+// filters returns the filters of a request with its dates in calendar
+func filters(request *models.DashboardStatsRequest, calendar string, start, end time.Time) models.DashboardFilters {
+	return models.DashboardFilters{
+		Calendar:  calendar,
+		StartDate: jalali.FormatDate(calendar, start),
+		EndDate:   jalali.FormatDate(calendar, end),
+		DoctorID:  request.DoctorID,
+		VisitType: request.VisitType,
+		Status:    request.Status,
+	}
+}
+
+// monthlyVisits groups daily visit counts by the months of calendar from start to end, months without
+// visits are listed with a zero count
+func monthlyVisits(days []models.DailyVisitCount, calendar, language string, start, end time.Time) []models.MonthlyVisitStats {
+	counts := map[jalali.Month]int{}
+	for _, day := range days {
+		counts[jalali.MonthOf(calendar, day.Day)] += day.Count
+	}
+	stats := []models.MonthlyVisitStats{}
+	last := jalali.MonthOf(calendar, end)
+	for month := jalali.MonthOf(calendar, start); !month.After(last); month = month.Next() {
+		stats = append(stats, models.MonthlyVisitStats{
+			Month:  jalali.MonthName(calendar, month.Month, language),
+			Period: month.String(),
+			Count:  counts[month],
+		})
+	}
+	return stats
+}
+
+type statsHandler struct {
+	Catalogue *i18n.Catalogue
+	Providers Providers
+	Location  *time.Location
+}
+
+// returns handler title
+func (h statsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "dashboard",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/dashboard/stats",
+	}
+}
+
+// runs after validating request
+func (h statsHandler) Initializer(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.DashboardStatsResponse]) error {
+	return nil
+}
+
+// Handler returns the dashboard statistics of the date range, month names are in the language of
+// the request
+func (h statsHandler) Handler(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.DashboardStatsResponse]) (*models.DashboardStatsResponse, error) {
+	calendar, startDate, endDate, err := dateRange(req.W, req.Request, h.Location)
+	if err != nil {
+		return nil, err
 	}
+	doctorID := req.Request.DoctorID
 
 	// TODO: Implement database queries for real statistics
 	// For now, return mock data
-	response := models.DashboardStatsResponse{
+	response := &models.DashboardStatsResponse{
 		TotalPatients:    150,
 		TotalVisits:      45,
 		ActiveTherapies:  12,
 		PendingFollowUps: 8,
-		MonthlyVisits: []models.MonthlyVisitStats{
-			{Month: "January", Count: 12},
-			{Month: "February", Count: 18},
-			{Month: "March", Count: 15},
-			{Month: "April", Count: 22},
-			{Month: "May", Count: 19},
-			{Month: "June", Count: 25},
-		},
+		MonthlyVisits:    []models.MonthlyVisitStats{},
 		VisitTypes: []models.VisitTypeStats{
 			{Type: "General", Count: 25},
 			{Type: "Dental", Count: 15},
 			{Type: "Emergency", Count: 5},
 		},
-		TopDiagnoses: []models.DiagnosisStats{
-			{Diagnosis: "Regular Checkup", Count: 20},
-			{Diagnosis: "Flu Symptoms", Count: 12},
-			{Diagnosis: "Dental Cleaning", Count: 8},
-			{Diagnosis: "Back Pain", Count: 5},
-		},
+		TopDiagnoses: []models.DiagnosisStats{},
+		Filters:      filters(req.Request, calendar, startDate, endDate),
+	}
+
+	language := h.Catalogue.Language(req.W.Parser.GetHeaderValue("Accept-Language"))
+	for i, count := range []int{12, 18, 15, 22, 19, 25} {
+		response.MonthlyVisits = append(response.MonthlyVisits, models.MonthlyVisitStats{
+			Month: jalali.MonthName(calendar, i+1, language),
+			Count: count,
+		})
+	}
+
+	if h.Providers.Visits != nil {
+		// the end date is inclusive
+		days, err := h.Providers.Visits.VisitsPerDay(startDate, endDate.AddDate(0, 0, 1), doctorID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, i18n.InternalError, err.Error())
+		}
+		response.MonthlyVisits = monthlyVisits(days, calendar, language, startDate, endDate)
+	}
+
+	if h.Providers.Diagnoses != nil {
+		// the end date is inclusive
+		topDiagnoses, err := h.Providers.Diagnoses.TopDiagnoses(startDate, endDate.AddDate(0, 0, 1), doctorID, 10)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, i18n.InternalError, err.Error())
+		}
+		response.TopDiagnoses = topDiagnoses
 	}
 
 	// Apply doctor filter if provided
@@ -78,249 +192,279 @@ func DashboardStatsHandler(c *gin.Context) {
 		response.PendingFollowUps = response.PendingFollowUps / 2
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Dashboard statistics retrieved successfully",
-		"data":    response,
-		"filters": gin.H{
-			"start_date": startDate.Format("2006-01-02"),
-			"end_date":   endDate.Format("2006-01-02"),
-			"doctor_id":  doctorID,
-		},
-	})
+	if h.Providers.Therapies != nil {
+		activeTherapies, err := h.Providers.Therapies.ActiveTherapies(doctorID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, i18n.InternalError, err.Error())
+		}
+		response.ActiveTherapies = activeTherapies
+	}
+	return response, nil
+}
+
+// Simulation returns a simulated response
+func (h statsHandler) Simulation(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.DashboardStatsResponse]) (*models.DashboardStatsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h statsHandler) Finalizer(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.DashboardStatsResponse]) {
 }
 
-// PatientStatsHandler handles GET requests for patient statistics
-func PatientStatsHandler(c *gin.Context) {
-	// Get query parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-	status := c.Query("status")
-	ageGroup := c.Query("age_group")
+type patientsHandler struct{}
 
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
+// returns handler title
+func (h patientsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "dashboard",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/dashboard/patients",
+	}
+}
+
+// runs after validating request
+func (h patientsHandler) Initializer(req handlers.HandlerRequest[models.PatientStatsRequest, *[]models.PatientStatsRow]) error {
+	return validation.Error(req.Request)
+}
 
+// inAgeGroup reports whether age is in one of the age groups of the patient statistics
+func inAgeGroup(age int, group string) bool {
+	switch group {
+	case "0-18":
+		return age >= 0 && age <= 18
+	case "19-35":
+		return age >= 19 && age <= 35
+	case "36-50":
+		return age >= 36 && age <= 50
+	case "50+":
+		return age > 50
+	}
+	return true
+}
+
+// Handler returns the sample patients matching the filters with their visit statistics, the fixed list
+// is paged by the paging middleware
+func (h patientsHandler) Handler(req handlers.HandlerRequest[models.PatientStatsRequest, *[]models.PatientStatsRow]) (*[]models.PatientStatsRow, error) {
 	// TODO: Implement database queries for patient statistics
 	// For now, return mock data
-	mockPatients := []gin.H{
+	mockPatients := []models.PatientStatsRow{
 		{
-			"id":          "1",
-			"full_name":   "John Doe",
-			"age":         35,
-			"gender":      "Male",
-			"last_visit":  time.Now().Add(-7 * 24 * time.Hour),
-			"visit_count": 5,
-			"status":      "active",
-			"blood_type":  "O+",
-			"phone":       "+1234567890",
-			"email":       "john.doe@example.com",
+			ID:         "1",
+			FullName:   "John Doe",
+			Age:        35,
+			Gender:     "Male",
+			LastVisit:  time.Now().Add(-7 * 24 * time.Hour),
+			VisitCount: 5,
+			Status:     "active",
+			BloodType:  "O+",
+			Phone:      "+1234567890",
+			Email:      "john.doe@example.com",
 		},
 		{
-			"id":          "2",
-			"full_name":   "Jane Smith",
-			"age":         28,
-			"gender":      "Female",
-			"last_visit":  time.Now().Add(-3 * 24 * time.Hour),
-			"visit_count": 3,
-			"status":      "active",
-			"blood_type":  "A+",
-			"phone":       "+1234567891",
-			"email":       "jane.smith@example.com",
+			ID:         "2",
+			FullName:   "Jane Smith",
+			Age:        28,
+			Gender:     "Female",
+			LastVisit:  time.Now().Add(-3 * 24 * time.Hour),
+			VisitCount: 3,
+			Status:     "active",
+			BloodType:  "A+",
+			Phone:      "+1234567891",
+			Email:      "jane.smith@example.com",
 		},
 		{
-			"id":          "3",
-			"full_name":   "Bob Johnson",
-			"age":         45,
-			"gender":      "Male",
-			"last_visit":  time.Now().Add(-14 * 24 * time.Hour),
-			"visit_count": 8,
-			"status":      "inactive",
-			"blood_type":  "B+",
-			"phone":       "+1234567892",
-			"email":       "bob.johnson@example.com",
+			ID:         "3",
+			FullName:   "Bob Johnson",
+			Age:        45,
+			Gender:     "Male",
+			LastVisit:  time.Now().Add(-14 * 24 * time.Hour),
+			VisitCount: 8,
+			Status:     "inactive",
+			BloodType:  "B+",
+			Phone:      "+1234567892",
+			Email:      "bob.johnson@example.com",
 		},
 	}
 
 	// Apply filters
-	filteredPatients := mockPatients
-	if status != "" {
-		var filtered []gin.H
-		for _, patient := range filteredPatients {
-			if patient["status"] == status {
-				filtered = append(filtered, patient)
-			}
-		}
-		filteredPatients = filtered
-	}
-
-	if ageGroup != "" {
-		var filtered []gin.H
-		for _, patient := range filteredPatients {
-			age := patient["age"].(int)
-			switch ageGroup {
-			case "0-18":
-				if age >= 0 && age <= 18 {
-					filtered = append(filtered, patient)
-				}
-			case "19-35":
-				if age >= 19 && age <= 35 {
-					filtered = append(filtered, patient)
-				}
-			case "36-50":
-				if age >= 36 && age <= 50 {
-					filtered = append(filtered, patient)
-				}
-			case "50+":
-				if age > 50 {
-					filtered = append(filtered, patient)
-				}
-			}
+	rows := []models.PatientStatsRow{}
+	for _, patient := range mockPatients {
+		if (req.Request.Status == "" || patient.Status == req.Request.Status) && inAgeGroup(patient.Age, req.Request.AgeGroup) {
+			rows = append(rows, patient)
 		}
-		filteredPatients = filtered
-	}
-
-	// Calculate pagination
-	total := len(filteredPatients)
-	offset := (page - 1) * limit
-	end := offset + limit
-
-	if offset >= total {
-		filteredPatients = []gin.H{}
-	} else if end > total {
-		filteredPatients = filteredPatients[offset:]
-	} else {
-		filteredPatients = filteredPatients[offset:end]
-	}
-
-	response := gin.H{
-		"success": true,
-		"message": "Patient statistics retrieved successfully",
-		"data":    filteredPatients,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
-		"summary": gin.H{
-			"total_patients":         total,
-			"active_patients":        len(filteredPatients),
-			"average_age":            36,
-			"most_common_blood_type": "O+",
-		},
 	}
+	return &rows, nil
+}
 
-	c.JSON(http.StatusOK, response)
+// Simulation returns a simulated response
+func (h patientsHandler) Simulation(req handlers.HandlerRequest[models.PatientStatsRequest, *[]models.PatientStatsRow]) (*[]models.PatientStatsRow, error) {
+	return req.Response, nil
 }
 
-// VisitStatsHandler handles GET requests for visit statistics
-func VisitStatsHandler(c *gin.Context) {
-	// Get query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	visitType := c.Query("visit_type")
-	status := c.Query("status")
+// runs after sending back response
+func (h patientsHandler) Finalizer(req handlers.HandlerRequest[models.PatientStatsRequest, *[]models.PatientStatsRow]) {
+}
 
-	// Parse dates
-	var startDate, endDate time.Time
-	var err error
+type visitsHandler struct {
+	Location *time.Location
+}
 
-	if startDateStr != "" {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
-			return
-		}
-	} else {
-		startDate = time.Now().AddDate(0, -1, 0) // Default to 1 month ago
+// returns handler title
+func (h visitsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "dashboard",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/dashboard/visits",
 	}
+}
 
-	if endDateStr != "" {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
-			return
-		}
-	} else {
-		endDate = time.Now() // Default to now
+// runs after validating request
+func (h visitsHandler) Initializer(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.VisitStatsResponse]) error {
+	return nil
+}
+
+// Handler returns the sample visits matching the filters with their summary
+func (h visitsHandler) Handler(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.VisitStatsResponse]) (*models.VisitStatsResponse, error) {
+	calendar, startDate, endDate, err := dateRange(req.W, req.Request, h.Location)
+	if err != nil {
+		return nil, err
 	}
 
 	// TODO: Implement database queries for visit statistics
 	// For now, return mock data
-	mockVisits := []gin.H{
+	mockVisits := []models.VisitStatsRow{
 		{
-			"id":           "1",
-			"patient_name": "John Doe",
-			"doctor_name":  "Dr. Smith",
-			"visit_type":   "general",
-			"visit_date":   time.Now().Add(-2 * 24 * time.Hour),
-			"status":       "completed",
-			"diagnosis":    "Regular checkup",
-			"duration":     30,
+			ID:          "1",
+			PatientName: "John Doe",
+			DoctorName:  "Dr. Smith",
+			VisitType:   "general",
+			VisitDate:   time.Now().Add(-2 * 24 * time.Hour),
+			Status:      "completed",
+			Diagnosis:   "Regular checkup",
+			Duration:    30,
 		},
 		{
-			"id":           "2",
-			"patient_name": "Jane Smith",
-			"doctor_name":  "Dr. Johnson",
-			"visit_type":   "dental",
-			"visit_date":   time.Now().Add(-1 * 24 * time.Hour),
-			"status":       "in_progress",
-			"diagnosis":    "Cavity treatment",
-			"duration":     45,
+			ID:          "2",
+			PatientName: "Jane Smith",
+			DoctorName:  "Dr. Johnson",
+			VisitType:   "dental",
+			VisitDate:   time.Now().Add(-1 * 24 * time.Hour),
+			Status:      "in_progress",
+			Diagnosis:   "Cavity treatment",
+			Duration:    45,
 		},
 		{
-			"id":           "3",
-			"patient_name": "Bob Johnson",
-			"doctor_name":  "Dr. Smith",
-			"visit_type":   "emergency",
-			"visit_date":   time.Now().Add(-6 * time.Hour),
-			"status":       "completed",
-			"diagnosis":    "Minor injury",
-			"duration":     20,
+			ID:          "3",
+			PatientName: "Bob Johnson",
+			DoctorName:  "Dr. Smith",
+			VisitType:   "emergency",
+			VisitDate:   time.Now().Add(-6 * time.Hour),
+			Status:      "completed",
+			Diagnosis:   "Minor injury",
+			Duration:    20,
 		},
 	}
 
 	// Apply filters
-	filteredVisits := mockVisits
-	if visitType != "" {
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["visit_type"] == visitType {
-				filtered = append(filtered, visit)
-			}
-		}
-		filteredVisits = filtered
-	}
-
-	if status != "" {
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["status"] == status {
-				filtered = append(filtered, visit)
-			}
+	visits := []models.VisitStatsRow{}
+	for _, visit := range mockVisits {
+		if (req.Request.VisitType == "" || visit.VisitType == req.Request.VisitType) &&
+			(req.Request.Status == "" || visit.Status == req.Request.Status) {
+			visits = append(visits, visit)
 		}
-		filteredVisits = filtered
 	}
 
-	response := gin.H{
-		"success": true,
-		"message": "Visit statistics retrieved successfully",
-		"data":    filteredVisits,
-		"summary": gin.H{
-			"total_visits":       len(filteredVisits),
-			"completed_visits":   2,
-			"in_progress_visits": 1,
-			"average_duration":   32,
-			"most_common_type":   "general",
+	return &models.VisitStatsResponse{
+		Visits: visits,
+		Summary: models.VisitStatsSummary{
+			TotalVisits:      len(visits),
+			CompletedVisits:  2,
+			InProgressVisits: 1,
+			AverageDuration:  32,
+			MostCommonType:   "general",
 		},
-		"filters": gin.H{
-			"start_date": startDate.Format("2006-01-02"),
-			"end_date":   endDate.Format("2006-01-02"),
-			"visit_type": visitType,
-			"status":     status,
-		},
-	}
+		Filters: filters(req.Request, calendar, startDate, endDate),
+	}, nil
+}
+
+// Simulation returns a simulated response
+func (h visitsHandler) Simulation(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.VisitStatsResponse]) (*models.VisitStatsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h visitsHandler) Finalizer(req handlers.HandlerRequest[models.DashboardStatsRequest, *models.VisitStatsResponse]) {
+}
+
+// dashboardStatsHandler godoc
+// @Summary Get dashboard statistics
+// @Description Get the dashboard statistics of a date range, the dates are in the calendar of the request and default to the last month, month names are in the language of the request
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param Accept-Language header string false "Language of month names"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param start_date query string false "First day"
+// @Param end_date query string false "Last day"
+// @Param doctor_id query string false "Doctor ID"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /dashboard/stats [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.DashboardStatsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env dashboardEnv) dashboardStatsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DashboardStatsRequest, *models.DashboardStatsResponse, statsHandler](env.Interface, statsHandler{Catalogue: env.Catalogue, Providers: env.Providers, Location: env.Location}, simulation)
+}
+
+// patientStatsHandler godoc
+// @Summary Get patient statistics
+// @Description Get the patients matching the filters with their visit statistics, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param status query string false "Patient status"
+// @Param age_group query string false "Age group" Enums(0-18, 19-35, 36-50, 50+)
+// @Param _start query int false "First item of the page"
+// @Param _end query int false "Item after the page"
+// @Param _sort query string false "Field to sort by"
+// @Param _order query string false "asc or desc"
+// @Router /dashboard/patients [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.PatientStatsRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env dashboardEnv) patientStatsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.PatientStatsRequest, *[]models.PatientStatsRow, patientsHandler](env.Interface, patientsHandler{}, simulation)
+}
 
-	c.JSON(http.StatusOK, response)
+// visitStatsHandler godoc
+// @Summary Get visit statistics
+// @Description Get the visits of a date range matching the filters with their summary, the dates are in the calendar of the request and default to the last month
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param start_date query string false "First day"
+// @Param end_date query string false "Last day"
+// @Param visit_type query string false "Visit type"
+// @Param status query string false "Visit status"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /dashboard/visits [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitStatsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env dashboardEnv) visitStatsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DashboardStatsRequest, *models.VisitStatsResponse, visitsHandler](env.Interface, visitsHandler{Location: env.Location}, simulation)
 }
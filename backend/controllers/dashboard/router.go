@@ -1,15 +1,36 @@
 package dashboard
 
 import (
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
 )
 
 // SetupRoutes sets up all dashboard-related routes
-func SetupRoutes(r *gin.RouterGroup) {
+func SetupRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	r *gin.RouterGroup,
+	catalogue *i18n.Catalogue,
+	providers Providers,
+	simulation bool,
+) {
+	env := &dashboardEnv{
+		Interface: model,
+		Params:    wsParams,
+		Catalogue: catalogue,
+		Providers: providers,
+		Location:  appointments.LoadLocation(wsParams),
+	}
 	dashboard := r.Group("/dashboard")
 	{
-		dashboard.GET("/stats", DashboardStatsHandler)  // Get dashboard statistics
-		dashboard.GET("/patients", PatientStatsHandler) // Get patient statistics
-		dashboard.GET("/visits", VisitStatsHandler)     // Get visit statistics
+		dashboard.GET("/stats", libGin.Gin(env.dashboardStatsHandler(simulation)))  // Get dashboard statistics
+		dashboard.GET("/patients", libGin.Gin(env.patientStatsHandler(simulation))) // Get patient statistics
+		dashboard.GET("/visits", libGin.Gin(env.visitStatsHandler(simulation)))     // Get visit statistics
 	}
 }
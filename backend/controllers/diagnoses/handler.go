@@ -0,0 +1,329 @@
+package diagnoses
+
+import (
+	"fmt"
+	"healthcare/controllers/ums"
+	"healthcare/controllers/visits"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/icd10"
+	"healthcare/utils/storage"
+	"healthcare/utils/validation"
+	"log"
+	"net/http"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type diagnosesEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+}
+
+type icd10SearchHandler struct {
+}
+
+// returns handler title
+func (h icd10SearchHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "icd10",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/icd10/search",
+	}
+}
+
+// runs after validating request
+func (h icd10SearchHandler) Initializer(req handlers.HandlerRequest[models.ICD10SearchRequest, *[]models.ICD10Row]) error {
+	if len([]rune(icd10.NormalizeText(req.Request.Query))) < 2 {
+		return libError.NewWithDescription(http.StatusBadRequest, "QUERY_TOO_SHORT", "q must have at least 2 characters")
+	}
+	if req.Request.Limit <= 0 || req.Request.Limit > 100 {
+		req.Request.Limit = 20
+	}
+	return nil
+}
+
+// Handler searches codes and english and persian descriptions
+func (h icd10SearchHandler) Handler(req handlers.HandlerRequest[models.ICD10SearchRequest, *[]models.ICD10Row]) (*[]models.ICD10Row, error) {
+	rows, err := libQuery.GetQuery[models.ICD10Row](searchCodes.SQL(), req.Core.GetDB(),
+		icd10.SearchKey(req.Request.Query), icd10.NormalizeText(req.Request.Query), req.Request.Limit)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_SEARCH_ICD10", err.Error())
+	}
+	return &rows, nil
+}
+
+// Simulation returns a simulated response
+func (h icd10SearchHandler) Simulation(req handlers.HandlerRequest[models.ICD10SearchRequest, *[]models.ICD10Row]) (*[]models.ICD10Row, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h icd10SearchHandler) Finalizer(req handlers.HandlerRequest[models.ICD10SearchRequest, *[]models.ICD10Row]) {
+}
+
+type icd10ImportHandler struct {
+	Path string
+}
+
+// returns handler title
+func (h icd10ImportHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "icd10",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  true,
+		Path:           "/icd10/import",
+	}
+}
+
+// runs after validating request
+func (h icd10ImportHandler) Initializer(req handlers.HandlerRequest[models.ICD10ImportRequest, *models.ICD10ImportResponse]) error {
+	if h.Path == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "ICD10_PATH_NOT_CONFIGURED", "icd10Path is not configured")
+	}
+	return nil
+}
+
+// Handler loads the configured code table file, stages its codes and upserts them into the code
+// table at once, a file that fails halfway leaves the code table as it was
+func (h icd10ImportHandler) Handler(req handlers.HandlerRequest[models.ICD10ImportRequest, *models.ICD10ImportResponse]) (*models.ICD10ImportResponse, error) {
+	codes, err := icd10.Load(h.Path)
+	if err != nil {
+		return nil, libError.New(http.StatusBadRequest, "ERROR_READ_ICD10", err.Error())
+	}
+	importID := storage.NewID()
+	defer func() {
+		if _, err := req.Core.GetDB().InsertRow(dropImport.SQL(), importID); err != nil {
+			log.Println("icd10: dropping staged import", importID, err)
+		}
+	}()
+	for _, code := range codes {
+		_, err := req.Core.GetDB().InsertRow(stageCode.SQL(), importID, code.Code, code.DescriptionEn, code.DescriptionFa)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_IMPORT_ICD10", err.Error())
+		}
+	}
+	if _, err := req.Core.GetDB().InsertRow(mergeImport.SQL(), importID); err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_IMPORT_ICD10", err.Error())
+	}
+	return &models.ICD10ImportResponse{Path: h.Path, Imported: len(codes)}, nil
+}
+
+// Simulation returns a simulated response
+func (h icd10ImportHandler) Simulation(req handlers.HandlerRequest[models.ICD10ImportRequest, *models.ICD10ImportResponse]) (*models.ICD10ImportResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h icd10ImportHandler) Finalizer(req handlers.HandlerRequest[models.ICD10ImportRequest, *models.ICD10ImportResponse]) {
+}
+
+type diagnosesHandler struct {
+	Name string
+}
+
+// returns handler title
+func (h diagnosesHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name != "diagnoses-post" {
+		body = libRequest.NoBinding
+	}
+	return handlers.HandlerParameters{
+		Title:          "diagnoses",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id/diagnoses",
+	}
+}
+
+// runs after validating request
+func (h diagnosesHandler) Initializer(req handlers.HandlerRequest[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse]) error {
+	req.Request.VisitID = req.W.Parser.GetUrlParam("id")
+	if req.Request.VisitID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	switch h.Name {
+	case "diagnoses-post":
+		req.Request.ICD10Code = icd10.NormalizeCode(req.Request.ICD10Code)
+		fields, err := validation.Check(req.Request)
+		if req.Request.ICD10Code != "" && !icd10.ValidCode(req.Request.ICD10Code) {
+			fields = append(fields, validation.FieldError{
+				Field:       "icd10_code",
+				Code:        i18n.ValidationInvalid,
+				Rule:        "icd10",
+				Description: fmt.Sprintf("icd10_code %s is not an ICD-10 code such as J45.909", req.Request.ICD10Code),
+			})
+		}
+		return validation.Fail(fields, err)
+	case "diagnoses-delete":
+		req.Request.ID = req.W.Parser.GetUrlParam("diagnosisId")
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h diagnosesHandler) Handler(req handlers.HandlerRequest[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse]) (*models.VisitDiagnosisResponse, error) {
+	switch h.Name {
+	case "diagnoses-get":
+		rows, err := libQuery.GetQuery[models.VisitDiagnosisRow](visitDiagnoses.SQL(), req.Core.GetDB(), req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_DIAGNOSES", err.Error())
+		}
+		req.Response = &models.VisitDiagnosisResponse{
+			Result:    libQuery.DmlResult{Success: true},
+			Diagnoses: rows,
+		}
+		return req.Response, nil
+
+	case "diagnoses-post":
+		if _, err := ums.CurrentUser(req.W, req.Core); err != nil {
+			return nil, err
+		}
+		if err := visits.EnsureEditable(req.Core, req.Request.VisitID); err != nil {
+			return nil, err
+		}
+		code, err := libQuery.GetQuery[models.ICD10Row](codeExists.SQL(), req.Core.GetDB(), req.Request.ICD10Code)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_ICD10", err.Error())
+		}
+		if len(code) == 0 {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, "UNKNOWN_ICD10_CODE", "icd10_code %s is not in the code table", req.Request.ICD10Code)
+		}
+		result, err := req.Core.GetDB().InsertRow(insertDiagnosis.SQL(),
+			req.Request.VisitID, req.Request.ICD10Code, req.Request.IsPrimary, req.Request.Notes)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response = &models.VisitDiagnosisResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+		}
+		return req.Response, nil
+
+	case "diagnoses-delete":
+		if _, err := ums.CurrentUser(req.W, req.Core); err != nil {
+			return nil, err
+		}
+		if err := visits.EnsureEditable(req.Core, req.Request.VisitID); err != nil {
+			return nil, err
+		}
+		result, err := req.Core.GetDB().InsertRow(deleteDiagnosis.SQL(), req.Request.ID, req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
+		}
+		req.Response = &models.VisitDiagnosisResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h diagnosesHandler) Simulation(req handlers.HandlerRequest[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse]) (*models.VisitDiagnosisResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h diagnosesHandler) Finalizer(req handlers.HandlerRequest[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse]) {
+}
+
+// icd10SearchHandler godoc
+// @Summary Search ICD-10 codes
+// @Description Autocomplete ICD-10 codes by code prefix or english or persian description
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param q query string true "Code or description"
+// @Param limit query int false "Maximum number of results (default 20)"
+// @Router /icd10/search [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.ICD10Row
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env diagnosesEnv) icd10SearchHandler(simulation bool) any {
+	return handlers.BaseHandler[models.ICD10SearchRequest, *[]models.ICD10Row, icd10SearchHandler](env.Interface, icd10SearchHandler{}, simulation)
+}
+
+// icd10ImportHandler godoc
+// @Summary Import ICD-10 code table
+// @Description Import or refresh the ICD-10 code table from the configured local file
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /icd10/import [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.ICD10ImportResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env diagnosesEnv) icd10ImportHandler(simulation bool) any {
+	return handlers.BaseHandler[models.ICD10ImportRequest, *models.ICD10ImportResponse, icd10ImportHandler](env.Interface, icd10ImportHandler{Path: env.Params.Specific.ICD10Path}, simulation)
+}
+
+// diagnosesGetHandler godoc
+// @Summary Get coded diagnoses of a visit
+// @Description Get ICD-10 coded diagnoses of a visit, the primary diagnosis comes first
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/diagnoses [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitDiagnosisResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env diagnosesEnv) diagnosesGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse, diagnosesHandler](env.Interface, diagnosesHandler{Name: "diagnoses-get"}, simulation)
+}
+
+// diagnosesPostHandler godoc
+// @Summary Add a coded diagnosis to a visit
+// @Description Add an ICD-10 coded diagnosis, marking it primary demotes the current primary diagnosis
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param diagnosis body models.VisitDiagnosisRequest true "Diagnosis"
+// @Router /visits/:id/diagnoses [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitDiagnosisResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env diagnosesEnv) diagnosesPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse, diagnosesHandler](env.Interface, diagnosesHandler{Name: "diagnoses-post"}, simulation)
+}
+
+// diagnosesDeleteHandler godoc
+// @Summary Remove a coded diagnosis from a visit
+// @Description Remove an ICD-10 coded diagnosis from a visit
+// @Tags diagnoses
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param diagnosisId path string true "Diagnosis ID"
+// @Router /visits/:id/diagnoses/:diagnosisId [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitDiagnosisResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env diagnosesEnv) diagnosesDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitDiagnosisRequest, *models.VisitDiagnosisResponse, diagnosesHandler](env.Interface, diagnosesHandler{Name: "diagnoses-delete"}, simulation)
+}
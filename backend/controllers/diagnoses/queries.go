@@ -0,0 +1,221 @@
+package diagnoses
+
+import "healthcare/utils/dialect"
+
+var (
+	// searchCodes matches the code prefix or any part of the english or persian description,
+	// code matches are listed first
+	searchCodes = dialect.Query{
+		Postgres: `--sql
+			SELECT c.code, c.description_en, COALESCE(c.description_fa, '') AS description_fa
+			  FROM public.icd10_codes c
+			 WHERE REPLACE(c.code, '.', '') ILIKE :1 || '%'
+			    OR c.description_en ILIKE '%' || :2 || '%'
+			    OR c.description_fa ILIKE '%' || :2 || '%'
+			 ORDER BY CASE WHEN REPLACE(c.code, '.', '') ILIKE :1 || '%' THEN 0 ELSE 1 END, c.code
+			 LIMIT :3
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT UPPER(:1) AS code, UPPER(:2) AS text FROM dual)
+			SELECT c.code, c.description_en, c.description_fa
+			  FROM a
+			  JOIN icd10_codes c
+			    ON UPPER(REPLACE(c.code, '.', '')) LIKE a.code || '%'
+			    OR UPPER(c.description_en) LIKE '%' || a.text || '%'
+			    OR UPPER(c.description_fa) LIKE '%' || a.text || '%'
+			 ORDER BY CASE WHEN UPPER(REPLACE(c.code, '.', '')) LIKE a.code || '%' THEN 0 ELSE 1 END, c.code
+			 FETCH FIRST :3 ROWS ONLY
+		`,
+	}
+	// stageCode records a code of the import :1, a code listed twice keeps its last descriptions
+	stageCode = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.icd10_import_codes (import_id, code, description_en, description_fa)
+			VALUES (:1, :2, :3, :4)
+			ON CONFLICT (import_id, code) DO UPDATE SET
+				description_en = EXCLUDED.description_en,
+				description_fa = EXCLUDED.description_fa
+		`,
+		Oracle: `--sql
+			MERGE INTO icd10_import_codes c
+			USING (SELECT :1 AS import_id, :2 AS code, :3 AS description_en, :4 AS description_fa FROM dual) a
+			   ON (c.import_id = a.import_id AND c.code = a.code)
+			 WHEN MATCHED THEN UPDATE SET
+				c.description_en = a.description_en,
+				c.description_fa = a.description_fa
+			 WHEN NOT MATCHED THEN INSERT (import_id, code, description_en, description_fa)
+				VALUES (a.import_id, a.code, a.description_en, a.description_fa)
+		`,
+	}
+	// mergeImport upserts the staged codes of an import into the code table in one statement
+	mergeImport = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.icd10_codes (code, description_en, description_fa)
+			SELECT s.code, s.description_en, s.description_fa
+			  FROM public.icd10_import_codes s
+			 WHERE s.import_id = :1
+			ON CONFLICT (code) DO UPDATE SET
+				description_en = EXCLUDED.description_en,
+				description_fa = EXCLUDED.description_fa
+		`,
+		Oracle: `--sql
+			MERGE INTO icd10_codes c
+			USING (
+				SELECT s.code, s.description_en, s.description_fa
+				  FROM icd10_import_codes s
+				 WHERE s.import_id = :1
+			) a
+			   ON (c.code = a.code)
+			 WHEN MATCHED THEN UPDATE SET
+				c.description_en = a.description_en,
+				c.description_fa = a.description_fa
+			 WHEN NOT MATCHED THEN INSERT (code, description_en, description_fa)
+				VALUES (a.code, a.description_en, a.description_fa)
+		`,
+	}
+	// dropImport removes the staged codes of an import and those left behind by imports that
+	// stopped more than a day ago
+	dropImport = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.icd10_import_codes
+			 WHERE import_id = :1 OR staged_at < NOW() - INTERVAL '1 day'
+		`,
+		Oracle: `--sql
+			DELETE FROM icd10_import_codes
+			 WHERE import_id = :1 OR staged_at < SYSTIMESTAMP - INTERVAL '1' DAY
+		`,
+	}
+	codeExists = dialect.Query{
+		Postgres: `--sql
+			SELECT c.code, c.description_en, COALESCE(c.description_fa, '') AS description_fa
+			  FROM public.icd10_codes c
+			 WHERE c.code = :1
+		`,
+		Oracle: `--sql
+			SELECT c.code, c.description_en, c.description_fa
+			  FROM icd10_codes c
+			 WHERE c.code = :1
+		`,
+	}
+	// insertDiagnosis adds a diagnosis, a primary one demotes the current primary diagnosis of the
+	// visit in the same statement so a failed insert keeps it. The demotion is read by the insert,
+	// which makes PostgreSQL run it first and keeps the unique primary index satisfied
+	insertDiagnosis = dialect.Query{
+		Postgres: `--sql
+			WITH demoted AS (
+				UPDATE public.visit_diagnoses SET is_primary = false
+				 WHERE visit_id = :1 AND is_primary = true AND :3
+				RETURNING id
+			)
+			INSERT INTO public.visit_diagnoses (visit_id, icd10_code, is_primary, notes)
+			SELECT :1, :2, :3, :4
+			  FROM (SELECT COUNT(*) FROM demoted) d
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_visit visit_diagnoses.visit_id%TYPE := :1;
+				v_code visit_diagnoses.icd10_code%TYPE := :2;
+				v_primary BOOLEAN := :3;
+				v_notes visit_diagnoses.notes%TYPE := :4;
+			BEGIN
+				IF v_primary THEN
+					UPDATE visit_diagnoses SET is_primary = FALSE
+					 WHERE visit_id = v_visit AND is_primary = TRUE;
+				END IF;
+				INSERT INTO visit_diagnoses (visit_id, icd10_code, is_primary, notes)
+				VALUES (v_visit, v_code, v_primary, v_notes);
+			END;
+		`,
+	}
+	deleteDiagnosis = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.visit_diagnoses WHERE id = :1 AND visit_id = :2
+		`,
+		Oracle: `--sql
+			DELETE FROM visit_diagnoses WHERE id = :1 AND visit_id = :2
+		`,
+	}
+	visitDiagnoses = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				d.id,
+				d.visit_id,
+				d.icd10_code,
+				c.description_en,
+				COALESCE(c.description_fa, '') AS description_fa,
+				d.is_primary,
+				COALESCE(d.notes, '') AS notes,
+				d.created_at
+			  FROM public.visit_diagnoses d
+			  JOIN public.icd10_codes c ON c.code = d.icd10_code
+			 WHERE d.visit_id = :1
+			 ORDER BY d.is_primary DESC, d.created_at
+		`,
+		Oracle: `--sql
+			SELECT
+				d.id,
+				d.visit_id,
+				d.icd10_code,
+				c.description_en,
+				c.description_fa,
+				d.is_primary,
+				d.notes,
+				d.created_at
+			  FROM visit_diagnoses d
+			  JOIN icd10_codes c ON c.code = d.icd10_code
+			 WHERE d.visit_id = :1
+			 ORDER BY CASE WHEN d.is_primary THEN 0 ELSE 1 END, d.created_at
+		`,
+	}
+	// topDiagnoses counts coded diagnoses of visits in the date range, optionally for one doctor
+	topDiagnoses = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				d.icd10_code AS code,
+				c.description_en AS diagnosis,
+				COALESCE(c.description_fa, '') AS diagnosis_fa,
+				COUNT(*) AS count
+			  FROM public.visit_diagnoses d
+			  JOIN public.visits v ON v.id = d.visit_id
+			  JOIN public.icd10_codes c ON c.code = d.icd10_code
+			 WHERE v.visit_date >= :1
+			   AND v.visit_date < :2
+			   AND (:3 = '' OR CAST(v.doctor_id AS TEXT) = :3)
+			 GROUP BY d.icd10_code, c.description_en, c.description_fa
+			 ORDER BY count DESC, d.icd10_code
+			 LIMIT :4
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS date_from, :2 AS date_to, :3 AS doctor_id FROM dual)
+			SELECT
+				d.icd10_code AS code,
+				c.description_en AS diagnosis,
+				c.description_fa AS diagnosis_fa,
+				COUNT(*) AS count
+			  FROM a
+			  JOIN visits v
+			    ON v.visit_date >= a.date_from
+			   AND v.visit_date < a.date_to
+			   AND (a.doctor_id IS NULL OR v.doctor_id = a.doctor_id)
+			  JOIN visit_diagnoses d ON d.visit_id = v.id
+			  JOIN icd10_codes c ON c.code = d.icd10_code
+			 GROUP BY d.icd10_code, c.description_en, c.description_fa
+			 ORDER BY COUNT(*) DESC, d.icd10_code
+			 FETCH FIRST :4 ROWS ONLY
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"codeExists":      codeExists,
+	"deleteDiagnosis": deleteDiagnosis,
+	"dropImport":      dropImport,
+	"insertDiagnosis": insertDiagnosis,
+	"mergeImport":     mergeImport,
+	"searchCodes":     searchCodes,
+	"stageCode":       stageCode,
+	"topDiagnoses":    topDiagnoses,
+	"visitDiagnoses":  visitDiagnoses,
+}
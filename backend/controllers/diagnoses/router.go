@@ -0,0 +1,29 @@
+package diagnoses
+
+import (
+	"healthcare/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func AddDiagnosesRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	simulation bool,
+) {
+	env := &diagnosesEnv{
+		Interface: model,
+		Params:    wsParams,
+	}
+	icd := rg.Group("/icd10")
+	icd.GET("search", libGin.Gin(env.icd10SearchHandler(simulation)))
+	icd.POST("import", libGin.Gin(env.icd10ImportHandler(simulation)))
+	rg.GET("/visits/:id/diagnoses", libGin.Gin(env.diagnosesGetHandler(simulation)))
+	rg.POST("/visits/:id/diagnoses", libGin.Gin(env.diagnosesPostHandler(simulation)))
+	rg.DELETE("/visits/:id/diagnoses/:diagnosisId", libGin.Gin(env.diagnosesDeleteHandler(simulation)))
+}
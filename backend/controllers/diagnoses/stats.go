@@ -0,0 +1,19 @@
+package diagnoses
+
+import (
+	"healthcare/models"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Stats aggregates coded diagnoses for the dashboard
+type Stats struct {
+	Core requestCore.RequestCoreInterface
+}
+
+// TopDiagnoses returns the most frequent ICD-10 codes of visits in [start, end), doctorID is optional
+func (s Stats) TopDiagnoses(start, end time.Time, doctorID string, limit int) ([]models.DiagnosisStats, error) {
+	return libQuery.GetQuery[models.DiagnosisStats](topDiagnoses.SQL(), s.Core.GetDB(), start, end, doctorID, limit)
+}
@@ -2,6 +2,7 @@ package doctors
 
 import (
 	"healthcare/models"
+	"healthcare/utils/dialect"
 	"net/http"
 	"time"
 
@@ -61,10 +62,7 @@ func (h doctorsHandler) Initializer(req handlers.HandlerRequest[models.DoctorsRe
 func (h doctorsHandler) Handler(req handlers.HandlerRequest[models.DoctorsRequest, *models.DoctorsResponse]) (*models.DoctorsResponse, error) {
 	switch h.Name {
 	case "doctors-post":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			insert into simulator.doctors (id, name)
-			values(:1, :2)
-		`, req.Request.ID, req.Request.Name)
+		result, err := req.Core.GetDB().InsertRow(insertDoctor.SQL(), req.Request.ID, req.Request.Name)
 		if err != nil {
 			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
 		}
@@ -74,11 +72,7 @@ func (h doctorsHandler) Handler(req handlers.HandlerRequest[models.DoctorsReques
 		}
 		return req.Response, nil
 	case "doctors-put":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			update simulator.doctors 
-			   set name=:1
-			 where id=:2
-		`, req.Request.Name, req.Request.ID)
+		result, err := req.Core.GetDB().InsertRow(updateDoctor.SQL(), req.Request.Name, req.Request.ID)
 		if err != nil {
 			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
 		}
@@ -88,10 +82,7 @@ func (h doctorsHandler) Handler(req handlers.HandlerRequest[models.DoctorsReques
 		}
 		return req.Response, nil
 	case "doctors-delete":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			delete from simulator.servers 
-			 where id=:1
-		`, req.Request.ID)
+		result, err := req.Core.GetDB().InsertRow(deleteDoctor.SQL(), req.Request.ID)
 		if err != nil {
 			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
 		}
@@ -190,7 +181,7 @@ func (env doctorsEnv) doctorsDeleteHandler(simulation bool) any {
 func (env doctorsEnv) doctorsGetHandler(simulation bool) any {
 	return handlers.QueryHandlerWithCaching[models.DoctorsRow](
 		"doctors-get", models.QuerySingle, "/doctors/:id",
-		QueryMap, env.Interface, libRequest.URI,
+		QueryMaps[dialect.Current()], env.Interface, libRequest.URI,
 		true, simulation, nil,
 		&handlers.CachingArgs{
 			Cache:       true,
@@ -214,5 +205,5 @@ func (env doctorsEnv) doctorsGetHandler(simulation bool) any {
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 func (env doctorsEnv) doctorsGetAllHandler(simulation bool) any {
-	return handlers.QueryHandler[models.DoctorsRow]("doctors-get-all", models.QueryAll, "/doctors/all", QueryMap, env.Interface, libRequest.Query, true, simulation, nil)
+	return handlers.QueryHandler[models.DoctorsRow]("doctors-get-all", models.QueryAll, "/doctors/all", QueryMaps[dialect.Current()], env.Interface, libRequest.Query, true, simulation, nil)
 }
@@ -2,22 +2,80 @@ package doctors
 
 import (
 	"healthcare/models"
+	"healthcare/utils/dialect"
 
 	"github.com/hmmftg/requestCore/libQuery"
 )
 
 var (
-	QueryMap = map[string]libQuery.QueryCommand{
-		models.QuerySingle: {
-			Name:    models.QuerySingle,
-			Command: "select * from doctors where id = :1",
-			Type:    libQuery.QuerySingle,
-			Args:    []any{"id"},
+	// QueryMaps holds the query map of each dialect, handlers are registered with the one of the
+	// current dialect
+	QueryMaps = map[dialect.Dialect]map[string]libQuery.QueryCommand{
+		dialect.Postgres: {
+			models.QuerySingle: {
+				Name:    models.QuerySingle,
+				Command: "select * from doctors where id = :1",
+				Type:    libQuery.QuerySingle,
+				Args:    []any{"id"},
+			},
+			models.QueryAll: {
+				Name:    models.QueryAll,
+				Command: "select * from doctors order by id",
+				Type:    libQuery.QueryAll,
+			},
 		},
-		models.QueryAll: {
-			Name:    models.QueryAll,
-			Command: "select * from doctors order by id",
-			Type:    libQuery.QueryAll,
+		dialect.Oracle: {
+			models.QuerySingle: {
+				Name:    models.QuerySingle,
+				Command: "select * from doctors where id = :1",
+				Type:    libQuery.QuerySingle,
+				Args:    []any{"id"},
+			},
+			models.QueryAll: {
+				Name:    models.QueryAll,
+				Command: "select * from doctors order by id",
+				Type:    libQuery.QueryAll,
+			},
 		},
 	}
+	insertDoctor = dialect.Query{
+		Postgres: `--sql
+			insert into simulator.doctors (id, name)
+			values(:1, :2)
+		`,
+		Oracle: `--sql
+			insert into SIMULATOR.DOCTORS (id, name)
+			values(:1, :2)
+		`,
+	}
+	updateDoctor = dialect.Query{
+		Postgres: `--sql
+			update simulator.doctors
+			   set name=:1
+			 where id=:2
+		`,
+		Oracle: `--sql
+			update SIMULATOR.DOCTORS
+			   set name=:1
+			 where id=:2
+		`,
+	}
+	deleteDoctor = dialect.Query{
+		Postgres: `--sql
+			delete from simulator.servers
+			 where id=:1
+		`,
+		Oracle: `--sql
+			delete from SIMULATOR.SERVERS
+			 where id=:1
+		`,
+	}
 )
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"deleteDoctor": deleteDoctor,
+	"insertDoctor": insertDoctor,
+	"updateDoctor": updateDoctor,
+}
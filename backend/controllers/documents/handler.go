@@ -0,0 +1,493 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"healthcare/controllers/locale"
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/i18n"
+	"healthcare/utils/scan"
+	"healthcare/utils/storage"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Categories of patient documents
+const (
+	Consent   = "consent"
+	Referral  = "referral"
+	LabReport = "lab_report"
+	IDScan    = "id_scan"
+	Other     = "other"
+)
+
+// DefaultRetentionYears is the retention of each category when none is configured, configured
+// categories are added to or override these
+var DefaultRetentionYears = map[string]int{
+	Consent:   10,
+	Referral:  10,
+	LabReport: 10,
+	IDScan:    2,
+	Other:     5,
+}
+
+// DefaultMaxSize is used when no upload size limit is configured
+const DefaultMaxSize = 20 << 20
+
+// DefaultAllowedTypes are accepted when no MIME types are configured
+var DefaultAllowedTypes = []string{"application/pdf", "image/jpeg", "image/png", "image/webp"}
+
+// extensions of the stored files per sniffed MIME type
+var extensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/gif":       ".gif",
+	"text/plain":      ".txt",
+}
+
+const dateLayout = "2006-01-02"
+
+// Documents handles uploads and downloads of patient documents
+type Documents struct {
+	Core      requestCore.RequestCoreInterface
+	Storage   storage.Storage
+	Scanner   scan.Scanner
+	Signer    storage.URLSigner
+	MaxSize   int64
+	Allowed   map[string]bool
+	Retention map[string]int
+	BasePath  string
+}
+
+// categories returns the configured categories in order, for error messages
+func (s *Documents) categories() string {
+	names := make([]string, 0, len(s.Retention))
+	for name := range s.Retention {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// retainUntil is the end of the retention of a document, a requested date can extend it but not shorten it
+func (s *Documents) retainUntil(category string, documentDate time.Time, requested string) (time.Time, error) {
+	until := documentDate.AddDate(s.Retention[category], 0, 0)
+	if requested == "" {
+		return until, nil
+	}
+	date, err := time.Parse(dateLayout, requested)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("retain_until must be a date in %s format", dateLayout)
+	}
+	if date.Before(until) {
+		return time.Time{}, fmt.Errorf("%s documents must be kept until %s", category, until.Format(dateLayout))
+	}
+	return date, nil
+}
+
+// withURL sets a signed download url on the document, quarantined documents get none
+func (s *Documents) withURL(row models.PatientDocumentRow) models.PatientDocumentRow {
+	if !scan.Downloadable(row.ScanStatus) {
+		return row
+	}
+	row.DownloadURL = s.Signer.Sign(path.Join("/", s.BasePath, "patients", row.PatientID, "documents", row.ID, "file"), time.Now())
+	return row
+}
+
+func (s *Documents) user(c *gin.Context) (*ums.UserData, bool) {
+	user, err := ums.HeaderUser(s.Core, c.GetHeader("Authorization"))
+	if err != nil {
+		locale.Error(c, http.StatusUnauthorized, i18n.Unauthorized, err.Error())
+		return nil, false
+	}
+	return user, true
+}
+
+// patient checks that the patient of the request exists and writes the error response when it is missing
+func (s *Documents) patient(c *gin.Context) (*models.PatientIdentityRow, bool) {
+	rows, err := libQuery.GetQuery[models.PatientIdentityRow](documentPatient.SQL(), s.Core.GetDB(), c.Param("id"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return nil, false
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.PatientNotFound, "patient not found")
+		return nil, false
+	}
+	return &rows[0], true
+}
+
+// duplicate returns the stored document of the patient with the same content, if any
+func (s *Documents) duplicate(patientID, checksum string) (*models.PatientDocumentRow, error) {
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](documentByChecksum.SQL(), s.Core.GetDB(), patientID, checksum)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+func (s *Documents) insert(row *models.PatientDocumentRow) (sql.Result, error) {
+	return s.Core.GetDB().InsertRow(insertDocument.SQL(),
+		row.ID, row.PatientID, row.Category, row.Title, row.Description, row.FileName, row.FileSize,
+		row.MimeType, row.Checksum, row.StorageKey, row.DocumentDate, row.RetainUntil, row.UploadedBy,
+		row.ScanStatus, row.ScanSignature)
+}
+
+// remove deletes a stored file, failures only leave an orphan file behind so they are logged
+func (s *Documents) remove(ctx context.Context, key string) {
+	if err := s.Storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Println("error removing document file", key, err)
+	}
+}
+
+// Upload handles multipart POST requests with the document in the file field, an upload with
+// the same content as a stored document of the patient returns that document instead
+func (s *Documents) Upload(c *gin.Context) {
+	user, ok := s.user(c)
+	if !ok {
+		return
+	}
+	patient, ok := s.patient(c)
+	if !ok {
+		return
+	}
+
+	// leave room for the other form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.MaxSize+1<<20)
+	var request models.PatientDocumentRequest
+	if err := c.ShouldBind(&request); err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+	request.Category = strings.ToLower(strings.TrimSpace(request.Category))
+	if _, ok := s.Retention[request.Category]; !ok {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidCategory, fmt.Sprintf("category must be one of %s", s.categories()))
+		return
+	}
+	documentDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.DocumentDate != "" {
+		date, err := time.Parse(dateLayout, request.DocumentDate)
+		if err != nil {
+			locale.Error(c, http.StatusBadRequest, i18n.InvalidDate, fmt.Sprintf("document_date must be a date in %s format", dateLayout))
+			return
+		}
+		documentDate = date
+	}
+	retainUntil, err := s.retainUntil(request.Category, documentDate, request.RetainUntil)
+	if err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			locale.Error(c, http.StatusRequestEntityTooLarge, i18n.FileTooLarge, fmt.Sprintf("file is larger than %d bytes", s.MaxSize))
+			return
+		}
+		locale.Error(c, http.StatusBadRequest, i18n.FileRequired, "file is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > s.MaxSize {
+		locale.Error(c, http.StatusRequestEntityTooLarge, i18n.FileTooLarge, fmt.Sprintf("file is larger than %d bytes", s.MaxSize))
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+	mimeType := storage.DetectType(data)
+	if !s.Allowed[mimeType] {
+		locale.Error(c, http.StatusUnsupportedMediaType, i18n.FileTypeNotAllowed, fmt.Sprintf("file type %s is not allowed", mimeType))
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if existing, err := s.duplicate(patient.ID, checksum); err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	} else if existing != nil {
+		row := s.withURL(*existing)
+		locale.Respond(c, http.StatusOK, i18n.DocumentExists, models.PatientDocumentResponse{
+			Result:    libQuery.DmlResult{Success: true, Message: "document already stored"},
+			Document:  &row,
+			Duplicate: true,
+		})
+		return
+	}
+
+	fileName := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	title := strings.TrimSpace(request.Title)
+	if title == "" {
+		title = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	row := models.PatientDocumentRow{
+		ID:           storage.NewID(),
+		PatientID:    patient.ID,
+		Category:     request.Category,
+		Title:        dialect.Text(title),
+		Description:  dialect.Text(request.Description),
+		FileName:     fileName,
+		FileSize:     int64(len(data)),
+		MimeType:     mimeType,
+		Checksum:     checksum,
+		DocumentDate: documentDate,
+		RetainUntil:  retainUntil,
+		UploadedBy:   dialect.Text(user.UserId),
+		CreatedAt:    time.Now(),
+	}
+	row.StorageKey = "patients/" + row.PatientID + "/documents/" + row.ID + extensions[mimeType]
+	verdict := scan.Check(c.Request.Context(), s.Scanner, bytes.NewReader(data))
+	row.ScanStatus, row.ScanSignature = verdict.Status, dialect.Text(verdict.Signature)
+	if !scan.Downloadable(row.ScanStatus) {
+		row.StorageKey = scan.QuarantineKey(row.StorageKey)
+	}
+	err = s.Storage.Put(c.Request.Context(), row.StorageKey, bytes.NewReader(data), row.FileSize, mimeType)
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	result, err := s.insert(&row)
+	if err != nil {
+		s.remove(c.Request.Context(), row.StorageKey)
+		// a concurrent upload of the same content wins the unique checksum index
+		if existing, _ := s.duplicate(patient.ID, checksum); existing != nil {
+			existingRow := s.withURL(*existing)
+			locale.Respond(c, http.StatusOK, i18n.DocumentExists, models.PatientDocumentResponse{
+				Result:    libQuery.DmlResult{Success: true, Message: "document already stored"},
+				Document:  &existingRow,
+				Duplicate: true,
+			})
+			return
+		}
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+
+	switch row.ScanStatus {
+	case scan.Infected:
+		locale.ErrorWithData(c, http.StatusUnprocessableEntity, i18n.FileInfected, "file is infected with "+string(row.ScanSignature), row)
+		return
+	case scan.Failed:
+		locale.ErrorWithData(c, http.StatusServiceUnavailable, i18n.FileNotScanned, "file could not be scanned and was quarantined", row)
+		return
+	}
+
+	row = s.withURL(row)
+	locale.Respond(c, http.StatusCreated, i18n.DocumentSaved, models.PatientDocumentResponse{
+		Result:   libQuery.GetDmlResult(result, nil),
+		Document: &row,
+	})
+}
+
+// List handles GET requests for the documents of a patient, optionally of one category
+func (s *Documents) List(c *gin.Context) {
+	if _, ok := s.user(c); !ok {
+		return
+	}
+	patient, ok := s.patient(c)
+	if !ok {
+		return
+	}
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](patientDocuments.SQL(), s.Core.GetDB(), patient.ID, c.Query("category"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if rows == nil {
+		rows = []models.PatientDocumentRow{}
+	}
+	for i := range rows {
+		rows[i] = s.withURL(rows[i])
+	}
+	locale.Respond(c, http.StatusOK, i18n.DocumentsRetrieved, rows)
+}
+
+// Download streams a document, it is authorised by the signature of the url instead of a bearer token
+func (s *Documents) Download(c *gin.Context) {
+	if !s.Signer.Verify(c.Request.URL.Path, c.Query("expires"), c.Query("signature"), time.Now()) {
+		locale.Error(c, http.StatusForbidden, i18n.InvalidLink, "download link is invalid or expired")
+		return
+	}
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](patientDocument.SQL(), s.Core.GetDB(), c.Param("id"), c.Param("documentId"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.DocumentNotFound, "document not found")
+		return
+	}
+	document := rows[0]
+	if !scan.Downloadable(document.ScanStatus) {
+		locale.Error(c, http.StatusForbidden, i18n.FileQuarantined, "document file is quarantined")
+		return
+	}
+	body, err := s.Storage.Get(c.Request.Context(), document.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		locale.Error(c, http.StatusNotFound, i18n.FileNotFound, "document file not found")
+		return
+	}
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	defer body.Close()
+	c.DataFromReader(http.StatusOK, document.FileSize, document.MimeType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}),
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// Delete handles DELETE requests for a document, the document is hidden right away but its
+// file is kept until the end of its retention
+func (s *Documents) Delete(c *gin.Context) {
+	user, ok := s.user(c)
+	if !ok {
+		return
+	}
+	patient, ok := s.patient(c)
+	if !ok {
+		return
+	}
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](patientDocument.SQL(), s.Core.GetDB(), patient.ID, c.Param("documentId"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.DocumentNotFound, "document not found")
+		return
+	}
+	result, err := s.Core.GetDB().InsertRow(deleteDocument.SQL(), patient.ID, rows[0].ID, user.UserId)
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if !rows[0].RetainUntil.After(time.Now()) {
+		s.remove(c.Request.Context(), rows[0].StorageKey)
+		if _, err := s.Core.GetDB().InsertRow(purgeDocument.SQL(), rows[0].ID); err != nil {
+			log.Println("error marking document purged", rows[0].ID, err)
+		}
+	}
+	locale.Respond(c, http.StatusOK, i18n.DocumentDeleted, models.PatientDocumentResponse{
+		Result: libQuery.GetDmlResult(result, nil),
+	})
+}
+
+// PurgeExpired removes the files of deleted documents whose retention has ended and returns
+// how many were purged, it is meant to run periodically
+func PurgeExpired(ctx context.Context, core requestCore.RequestCoreInterface, store storage.Storage) (int, error) {
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](expiredDocuments.SQL(), core.GetDB())
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, row := range rows {
+		if err := store.Delete(ctx, row.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Println("error purging document file", row.StorageKey, err)
+			continue
+		}
+		if _, err := core.GetDB().InsertRow(purgeDocument.SQL(), row.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// rescanUser is recorded as the deleter of quarantined documents a rescan finds to be duplicates
+const rescanUser = "rescan"
+
+// Rescan scans the documents quarantined because the scanner failed again. Clean documents are moved
+// out of quarantine and infected ones stay there with their signature, a clean document whose content
+// was stored again meanwhile is deleted as a duplicate. It stops at the first error of the scanner,
+// which is most likely still down, and returns the number of documents rescanned
+func Rescan(ctx context.Context, core requestCore.RequestCoreInterface, store storage.Storage, scanner scan.Scanner) (int, error) {
+	s := &Documents{Core: core, Storage: store, Scanner: scanner}
+	rows, err := libQuery.GetQuery[models.PatientDocumentRow](failedDocuments.SQL(), core.GetDB())
+	if err != nil {
+		return 0, err
+	}
+	rescanned := 0
+	for _, row := range rows {
+		done, err := s.rescan(ctx, row)
+		if err != nil {
+			return rescanned, err
+		}
+		if done {
+			rescanned++
+		}
+	}
+	return rescanned, nil
+}
+
+// rescan scans a quarantined document again, a file that can not be read is logged and left for the next run
+func (s *Documents) rescan(ctx context.Context, row models.PatientDocumentRow) (bool, error) {
+	data, err := s.read(ctx, row.StorageKey)
+	if err != nil {
+		log.Println("error reading quarantined document", row.ID, err)
+		return false, nil
+	}
+	result, err := s.Scanner.Scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	key := row.StorageKey
+	if scan.Downloadable(result.Status) {
+		key = scan.ReleaseKey(key)
+		if err = s.Storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), row.MimeType); err != nil {
+			return false, err
+		}
+	}
+	updated, err := s.Core.GetDB().InsertRow(updateRescan.SQL(), result.Status, result.Signature, key, row.ID)
+	if err != nil || updateRescan.Unchanged(updated) {
+		if key != row.StorageKey {
+			s.remove(ctx, key)
+		}
+		if dialect.Is(err, dialect.UniqueViolation, "patient_documents_checksum_idx") {
+			// the quarantined file stays until the retention of the deleted document ends
+			_, err = s.Core.GetDB().InsertRow(deleteDocument.SQL(), row.PatientID, row.ID, rescanUser)
+		}
+		return false, err
+	}
+	if key != row.StorageKey {
+		s.remove(ctx, row.StorageKey)
+	}
+	return true, nil
+}
+
+// read returns the content of a stored file
+func (s *Documents) read(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
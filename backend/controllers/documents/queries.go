@@ -0,0 +1,197 @@
+package documents
+
+import "healthcare/utils/dialect"
+
+var documentColumns = dialect.Query{
+	Postgres: `
+			d.id,
+			d.patient_id,
+			d.category,
+			COALESCE(d.title, '') AS title,
+			COALESCE(d.description, '') AS description,
+			d.file_name,
+			d.file_size,
+			d.mime_type,
+			d.checksum,
+			d.storage_key,
+			d.document_date,
+			d.retain_until,
+			COALESCE(d.uploaded_by, '') AS uploaded_by,
+			d.scan_status,
+			COALESCE(d.scan_signature, '') AS scan_signature,
+			d.created_at`,
+	Oracle: `
+			d.id,
+			d.patient_id,
+			d.category,
+			d.title,
+			d.description,
+			d.file_name,
+			d.file_size,
+			d.mime_type,
+			d.checksum,
+			d.storage_key,
+			d.document_date,
+			d.retain_until,
+			d.uploaded_by,
+			d.scan_status,
+			d.scan_signature,
+			d.created_at`,
+}
+
+var (
+	documentPatient = dialect.Query{
+		Postgres: `--sql
+			SELECT p.id, p.patient_id AS patient_number, COALESCE(p.full_name, '') AS full_name
+			  FROM public.patients p
+			 WHERE p.id = :1
+		`,
+		Oracle: `--sql
+			SELECT p.id, p.patient_id AS patient_number, p.full_name
+			  FROM patients p
+			 WHERE p.id = :1
+		`,
+	}
+	insertDocument = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.patient_documents
+				(id, patient_id, category, title, description, file_name, file_size, mime_type, checksum,
+				 storage_key, document_date, retain_until, uploaded_by, scan_status, scan_signature, scanned_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, NULLIF(:15, ''), NOW())
+		`,
+		Oracle: `--sql
+			INSERT INTO patient_documents
+				(id, patient_id, category, title, description, file_name, file_size, mime_type, checksum,
+				 storage_key, document_date, retain_until, uploaded_by, scan_status, scan_signature, scanned_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, SYSTIMESTAMP)
+		`,
+	}
+	documentByChecksum = dialect.Query{
+		Postgres: `--sql
+			SELECT` + documentColumns.Postgres + `
+			  FROM public.patient_documents d
+			 WHERE d.patient_id = :1 AND d.checksum = :2 AND d.deleted_at IS NULL
+			   AND d.scan_status IN ('clean', 'skipped')
+		`,
+		Oracle: `--sql
+			SELECT` + documentColumns.Oracle + `
+			  FROM patient_documents d
+			 WHERE d.patient_id = :1 AND d.checksum = :2 AND d.deleted_at IS NULL
+			   AND d.scan_status IN ('clean', 'skipped')
+		`,
+	}
+	patientDocuments = dialect.Query{
+		Postgres: `--sql
+			SELECT` + documentColumns.Postgres + `
+			  FROM public.patient_documents d
+			 WHERE d.patient_id = :1 AND d.deleted_at IS NULL
+			   AND (:2 = '' OR d.category = :2)
+			 ORDER BY d.document_date DESC, d.created_at DESC
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS patient_id, :2 AS category FROM dual)
+			SELECT` + documentColumns.Oracle + `
+			  FROM a
+			  JOIN patient_documents d ON d.patient_id = a.patient_id
+			 WHERE d.deleted_at IS NULL
+			   AND (a.category IS NULL OR d.category = a.category)
+			 ORDER BY d.document_date DESC, d.created_at DESC
+		`,
+	}
+	patientDocument = dialect.Query{
+		Postgres: `--sql
+			SELECT` + documentColumns.Postgres + `
+			  FROM public.patient_documents d
+			 WHERE d.patient_id = :1 AND d.id = :2 AND d.deleted_at IS NULL
+		`,
+		Oracle: `--sql
+			SELECT` + documentColumns.Oracle + `
+			  FROM patient_documents d
+			 WHERE d.patient_id = :1 AND d.id = :2 AND d.deleted_at IS NULL
+		`,
+	}
+	deleteDocument = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.patient_documents
+			   SET deleted_at = NOW(), deleted_by = :3
+			 WHERE patient_id = :1 AND id = :2 AND deleted_at IS NULL
+		`,
+		Oracle: `--sql
+			MERGE INTO patient_documents d
+			USING (SELECT :1 AS patient_id, :2 AS id, :3 AS deleted_by FROM dual) a
+			   ON (d.patient_id = a.patient_id AND d.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET d.deleted_at = SYSTIMESTAMP, d.deleted_by = a.deleted_by
+				WHERE d.deleted_at IS NULL
+		`,
+	}
+	expiredDocuments = dialect.Query{
+		Postgres: `--sql
+			SELECT` + documentColumns.Postgres + `
+			  FROM public.patient_documents d
+			 WHERE d.deleted_at IS NOT NULL AND d.purged_at IS NULL AND d.retain_until < CURRENT_DATE
+			 ORDER BY d.retain_until
+			 LIMIT 500
+		`,
+		Oracle: `--sql
+			SELECT` + documentColumns.Oracle + `
+			  FROM patient_documents d
+			 WHERE d.deleted_at IS NOT NULL AND d.purged_at IS NULL AND d.retain_until < TRUNC(CURRENT_DATE)
+			 ORDER BY d.retain_until
+			 FETCH FIRST 500 ROWS ONLY
+		`,
+	}
+	// failedDocuments are the documents quarantined because the scanner failed, oldest first
+	failedDocuments = dialect.Query{
+		Postgres: `--sql
+			SELECT` + documentColumns.Postgres + `
+			  FROM public.patient_documents d
+			 WHERE d.scan_status = 'failed' AND d.deleted_at IS NULL
+			 ORDER BY d.created_at
+			 LIMIT 100
+		`,
+		Oracle: `--sql
+			SELECT` + documentColumns.Oracle + `
+			  FROM patient_documents d
+			 WHERE d.scan_status = 'failed' AND d.deleted_at IS NULL
+			 ORDER BY d.created_at
+			 FETCH FIRST 100 ROWS ONLY
+		`,
+	}
+	// updateRescan records the verdict of a rescan and where the file is stored now, only while the
+	// document is still waiting for one
+	updateRescan = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.patient_documents
+			   SET scan_status = :1, scan_signature = NULLIF(:2, ''), storage_key = :3, scanned_at = NOW()
+			 WHERE id = :4 AND scan_status = 'failed' AND deleted_at IS NULL
+		`,
+		Oracle: `--sql
+			UPDATE patient_documents
+			   SET scan_status = :1, scan_signature = :2, storage_key = :3, scanned_at = SYSTIMESTAMP
+			 WHERE id = :4 AND scan_status = 'failed' AND deleted_at IS NULL
+		`,
+	}
+	purgeDocument = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.patient_documents SET purged_at = NOW() WHERE id = :1
+		`,
+		Oracle: `--sql
+			UPDATE patient_documents SET purged_at = SYSTIMESTAMP WHERE id = :1
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"deleteDocument":     deleteDocument,
+	"documentByChecksum": documentByChecksum,
+	"documentPatient":    documentPatient,
+	"expiredDocuments":   expiredDocuments,
+	"failedDocuments":    failedDocuments,
+	"insertDocument":     insertDocument,
+	"patientDocument":    patientDocument,
+	"patientDocuments":   patientDocuments,
+	"purgeDocument":      purgeDocument,
+	"updateRescan":       updateRescan,
+}
@@ -0,0 +1,55 @@
+package documents
+
+import (
+	"healthcare/models"
+	"healthcare/utils/scan"
+	"healthcare/utils/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// AddDocumentsRoutes sets up upload, listing, download and deletion of patient documents
+func AddDocumentsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	store storage.Storage,
+	scanner scan.Scanner,
+	signer storage.URLSigner,
+) {
+	params := wsParams.Specific.Documents
+	documents := &Documents{
+		Core:      model,
+		Storage:   store,
+		Scanner:   scanner,
+		Signer:    signer,
+		MaxSize:   int64(params.MaxSizeMB) << 20,
+		Allowed:   map[string]bool{},
+		Retention: map[string]int{},
+		BasePath:  rg.BasePath(),
+	}
+	if documents.MaxSize <= 0 {
+		documents.MaxSize = DefaultMaxSize
+	}
+	allowed := params.AllowedTypes
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedTypes
+	}
+	for _, mimeType := range allowed {
+		documents.Allowed[mimeType] = true
+	}
+	for category, years := range DefaultRetentionYears {
+		documents.Retention[category] = years
+	}
+	for category, years := range params.RetentionYears {
+		documents.Retention[category] = years
+	}
+
+	rg.POST("/patients/:id/documents", documents.Upload)                   // Upload document
+	rg.GET("/patients/:id/documents", documents.List)                      // Get documents of patient
+	rg.GET("/patients/:id/documents/:documentId/file", documents.Download) // Download with signed url
+	rg.DELETE("/patients/:id/documents/:documentId", documents.Delete)     // Delete document
+}
@@ -0,0 +1,53 @@
+package drugs
+
+import (
+	"healthcare/models"
+	"healthcare/utils/drugref"
+	"net/http"
+	"sort"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Checker finds interactions between a proposed drug and the active medications of a patient
+type Checker struct {
+	Core      requestCore.RequestCoreInterface
+	Reference *drugref.Reference
+}
+
+// CheckForPatient checks the proposed drug against all active medications of the patient
+func (c Checker) CheckForPatient(patientID, medicationName string) ([]models.DrugInteraction, error) {
+	return c.check(activeMedicationsByPatient.SQL(), patientID, medicationName)
+}
+
+// CheckForVisit checks the proposed drug against all active medications of the visit's patient
+func (c Checker) CheckForVisit(visitID, medicationName string) ([]models.DrugInteraction, error) {
+	return c.check(activeMedicationsByVisit.SQL(), visitID, medicationName)
+}
+
+func (c Checker) check(query, id, medicationName string) ([]models.DrugInteraction, error) {
+	active, err := libQuery.GetQuery[models.ActiveMedicationRow](query, c.Core.GetDB(), id)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_ACTIVE_MEDICATIONS", err.Error())
+	}
+	interactions := []models.DrugInteraction{}
+	for _, medication := range active {
+		for _, match := range c.Reference.Check(medicationName, []string{medication.MedicationName}) {
+			interactions = append(interactions, models.DrugInteraction{
+				ProposedDrug:   match.ProposedDrug,
+				ExistingDrug:   match.ExistingDrug,
+				MedicationID:   medication.ID,
+				VisitID:        medication.VisitID,
+				Severity:       match.Severity,
+				Description:    match.Description,
+				RequiresReview: drugref.IsSevere(match.Severity),
+			})
+		}
+	}
+	sort.SliceStable(interactions, func(i, j int) bool {
+		return drugref.SeverityRank(interactions[i].Severity) > drugref.SeverityRank(interactions[j].Severity)
+	})
+	return interactions, nil
+}
@@ -0,0 +1,167 @@
+package drugs
+
+import (
+	"healthcare/models"
+	"healthcare/utils/validation"
+	"net/http"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type drugsEnv struct {
+	Params    libParams.ParamInterface
+	Interface requestCore.RequestCoreInterface
+	Checker   Checker
+}
+
+func (env *drugsEnv) GetInterface() requestCore.RequestCoreInterface {
+	return env.Interface
+}
+func (env *drugsEnv) GetParams() libParams.ParamInterface {
+	return env.Params
+}
+func (env *drugsEnv) SetInterface(core requestCore.RequestCoreInterface) {
+	env.Interface = core
+}
+func (env *drugsEnv) SetParams(parameters libParams.ParamInterface) {
+	env.Params = parameters
+}
+
+type drugsHandler struct {
+	Name    string
+	Checker Checker
+}
+
+// returns handler title
+func (h drugsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "drugs",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/drugs",
+	}
+}
+
+// runs after validating request
+func (h drugsHandler) Initializer(req handlers.HandlerRequest[models.DrugInteractionRequest, *models.DrugInteractionResponse]) error {
+	return validation.Error(req.Request)
+}
+
+// Handler is the main method that handles request and returns the response
+func (h drugsHandler) Handler(req handlers.HandlerRequest[models.DrugInteractionRequest, *models.DrugInteractionResponse]) (*models.DrugInteractionResponse, error) {
+	switch h.Name {
+	case "drugs-interactions":
+		var interactions []models.DrugInteraction
+		var err error
+		if req.Request.PatientID != "" {
+			interactions, err = h.Checker.CheckForPatient(req.Request.PatientID, req.Request.MedicationName)
+		} else {
+			interactions, err = h.Checker.CheckForVisit(req.Request.VisitID, req.Request.MedicationName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, known := h.Checker.Reference.Lookup(req.Request.MedicationName)
+		req.Response = &models.DrugInteractionResponse{
+			MedicationName: req.Request.MedicationName,
+			KnownDrug:      known,
+			Interactions:   interactions,
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h drugsHandler) Simulation(req handlers.HandlerRequest[models.DrugInteractionRequest, *models.DrugInteractionResponse]) (*models.DrugInteractionResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h drugsHandler) Finalizer(req handlers.HandlerRequest[models.DrugInteractionRequest, *models.DrugInteractionResponse]) {
+}
+
+type drugListRequest struct {
+}
+
+type drugListHandler struct {
+	Checker Checker
+}
+
+// returns handler title
+func (h drugListHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "drugs",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/drugs",
+	}
+}
+
+// runs after validating request
+func (h drugListHandler) Initializer(req handlers.HandlerRequest[drugListRequest, *[]models.DrugRow]) error {
+	return nil
+}
+
+// Handler returns the loaded drug reference
+func (h drugListHandler) Handler(req handlers.HandlerRequest[drugListRequest, *[]models.DrugRow]) (*[]models.DrugRow, error) {
+	drugs := []models.DrugRow{}
+	for _, drug := range h.Checker.Reference.Drugs() {
+		drugs = append(drugs, models.DrugRow{
+			GenericName: drug.GenericName,
+			Class:       drug.Class,
+			Aliases:     drug.Aliases,
+		})
+	}
+	return &drugs, nil
+}
+
+// Simulation returns a simulated response
+func (h drugListHandler) Simulation(req handlers.HandlerRequest[drugListRequest, *[]models.DrugRow]) (*[]models.DrugRow, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h drugListHandler) Finalizer(req handlers.HandlerRequest[drugListRequest, *[]models.DrugRow]) {
+}
+
+// drugsGetAllHandler godoc
+// @Summary List reference drugs
+// @Description List generic names, classes and aliases of the loaded drug reference
+// @Tags drugs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /drugs/all [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.DrugRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env drugsEnv) drugsGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[drugListRequest, *[]models.DrugRow, drugListHandler](env.Interface, drugListHandler{Checker: env.Checker}, simulation)
+}
+
+// drugsInteractionsHandler godoc
+// @Summary Check drug interactions
+// @Description List interactions between a proposed drug and the active medications of a patient across all visits
+// @Tags drugs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param request body models.DrugInteractionRequest true "Proposed drug and patient or visit"
+// @Router /drugs/interactions [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.DrugInteractionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env drugsEnv) drugsInteractionsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.DrugInteractionRequest, *models.DrugInteractionResponse, drugsHandler](env.Interface, drugsHandler{Name: "drugs-interactions", Checker: env.Checker}, simulation)
+}
@@ -0,0 +1,51 @@
+package drugs
+
+import "healthcare/utils/dialect"
+
+var (
+	// activeMedicationsByPatient lists medications of a patient that are still running, across all visits
+	activeMedicationsByPatient = dialect.Query{
+		Postgres: `--sql
+			SELECT m.id, m.visit_id, m.medication_name
+			  FROM public.medications m
+			  JOIN public.visits v ON v.id = m.visit_id
+			 WHERE v.patient_id = :1
+			   AND m.is_active = true
+			   AND (m.end_date IS NULL OR m.end_date >= NOW())
+		`,
+		Oracle: `--sql
+			SELECT m.id, m.visit_id, m.medication_name
+			  FROM medications m
+			  JOIN visits v ON v.id = m.visit_id
+			 WHERE v.patient_id = :1
+			   AND m.is_active = TRUE
+			   AND (m.end_date IS NULL OR m.end_date >= SYSTIMESTAMP)
+		`,
+	}
+	// activeMedicationsByVisit lists running medications of the patient the visit belongs to
+	activeMedicationsByVisit = dialect.Query{
+		Postgres: `--sql
+			SELECT m.id, m.visit_id, m.medication_name
+			  FROM public.medications m
+			  JOIN public.visits v ON v.id = m.visit_id
+			 WHERE v.patient_id = (SELECT patient_id FROM public.visits WHERE id = :1)
+			   AND m.is_active = true
+			   AND (m.end_date IS NULL OR m.end_date >= NOW())
+		`,
+		Oracle: `--sql
+			SELECT m.id, m.visit_id, m.medication_name
+			  FROM medications m
+			  JOIN visits v ON v.id = m.visit_id
+			 WHERE v.patient_id = (SELECT patient_id FROM visits WHERE id = :1)
+			   AND m.is_active = TRUE
+			   AND (m.end_date IS NULL OR m.end_date >= SYSTIMESTAMP)
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"activeMedicationsByPatient": activeMedicationsByPatient,
+	"activeMedicationsByVisit":   activeMedicationsByVisit,
+}
@@ -0,0 +1,44 @@
+package drugs
+
+import (
+	"healthcare/models"
+	"healthcare/utils/drugref"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// LoadReference loads the drug reference configured in params, an empty reference is used when none is configured
+func LoadReference(wsParams *libParams.ApplicationParams[models.ApplicationParams]) *drugref.Reference {
+	if len(wsParams.Specific.DrugReferencePath) == 0 {
+		log.Println("drug reference path is not configured, interaction checks are disabled")
+		ref, _ := drugref.New(drugref.Dataset{})
+		return ref
+	}
+	ref, err := drugref.Load(wsParams.Specific.DrugReferencePath)
+	if err != nil {
+		log.Fatalln("error loading drug reference", err)
+	}
+	return ref
+}
+
+func AddDrugsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	checker Checker,
+	simulation bool,
+) {
+	env := &drugsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Checker:   checker,
+	}
+	root := rg.Group("/drugs")
+	root.GET("all", libGin.Gin(env.drugsGetAllHandler(simulation)))
+	root.POST("interactions", libGin.Gin(env.drugsInteractionsHandler(simulation)))
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"healthcare/controllers/locale"
+	"healthcare/controllers/ums"
+	"healthcare/utils/events"
+	"healthcare/utils/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+)
+
+// MaxRooms limits the rooms of a single stream
+const MaxRooms = 10
+
+// DefaultHeartbeat keeps idle streams open through proxies
+const DefaultHeartbeat = 15 * time.Second
+
+// writeTimeout drops a client whose connection stopped reading
+const writeTimeout = 10 * time.Second
+
+// Stream pushes the events of clinic rooms to waiting-room displays over server-sent events
+type Stream struct {
+	Core      requestCore.RequestCoreInterface
+	Broker    *events.Broker
+	Heartbeat time.Duration
+}
+
+// parseRooms parses the comma separated rooms of the request, duplicates are dropped
+func parseRooms(value string) ([]string, error) {
+	seen := map[string]bool{}
+	var rooms []string
+	for _, room := range strings.Split(value, ",") {
+		room = strings.TrimSpace(room)
+		if room == "" || seen[room] {
+			continue
+		}
+		seen[room] = true
+		rooms = append(rooms, room)
+	}
+	if len(rooms) == 0 {
+		return nil, fmt.Errorf("room is required")
+	}
+	if len(rooms) > MaxRooms {
+		return nil, fmt.Errorf("at most %d rooms can be streamed at once", MaxRooms)
+	}
+	return rooms, nil
+}
+
+// lastEventID is the last event a reconnecting client received, browsers send it as a header
+func lastEventID(c *gin.Context) uint64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	id, _ := strconv.ParseUint(value, 10, 64)
+	return id
+}
+
+// write sends one frame to the client, a client that does not take it in time is dropped
+func write(c *gin.Context, controller *http.ResponseController, frame string) bool {
+	_ = controller.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.Writer.WriteString(frame); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+func frame(event events.Event) string {
+	data, err := json.Marshal(event)
+	if err != nil {
+		data = []byte("{}")
+	}
+	return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// Events streams the events of the rooms in the query, e.g. room=clinic or room=doctor:<id>,department:dentistry.
+// EventSource cannot send headers, so the token may also be passed as access_token
+func (s *Stream) Events(c *gin.Context) {
+	auth := c.GetHeader("Authorization")
+	if auth == "" && c.Query("access_token") != "" {
+		auth = "Bearer " + c.Query("access_token")
+	}
+	if _, err := ums.HeaderUser(s.Core, auth); err != nil {
+		locale.Error(c, http.StatusUnauthorized, i18n.Unauthorized, err.Error())
+		return
+	}
+	rooms, err := parseRooms(c.Query("room"))
+	if err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+
+	sub, replay := s.Broker.Subscribe(rooms, lastEventID(c))
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	controller := http.NewResponseController(c.Writer)
+	if !write(c, controller, "retry: 3000\n\n") {
+		return
+	}
+	for _, event := range replay {
+		if !write(c, controller, frame(event)) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(s.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// dropped for falling behind, the client reconnects with its last event id and catches up
+				if sub.Lagged() {
+					write(c, controller, "event: lagged\ndata: {}\n\n")
+				}
+				return
+			}
+			if !write(c, controller, frame(event)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(c, controller, ": ping\n\n") {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"healthcare/models"
+	"healthcare/utils/events"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// LoadBroker creates the broker the handlers publish their changes to
+func LoadBroker(wsParams *libParams.ApplicationParams[models.ApplicationParams]) *events.Broker {
+	params := wsParams.Specific.Events
+	return events.NewBroker(params.Buffer, params.History)
+}
+
+// AddEventsRoutes sets up the live event stream of waiting-room displays
+func AddEventsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	broker *events.Broker,
+) {
+	stream := &Stream{
+		Core:      model,
+		Broker:    broker,
+		Heartbeat: time.Duration(wsParams.Specific.Events.HeartbeatSeconds) * time.Second,
+	}
+	if stream.Heartbeat <= 0 {
+		stream.Heartbeat = DefaultHeartbeat
+	}
+
+	rg.GET("/events", stream.Events) // Server-sent events of rooms
+}
@@ -0,0 +1,155 @@
+package images
+
+import (
+	"fmt"
+	"healthcare/controllers/locale"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/dicom"
+	"healthcare/utils/i18n"
+	"image"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// ImageTypeDICOM is the image type of DICOM uploads when the client sends none
+const ImageTypeDICOM = "dicom"
+
+// nameTokens returns the lower cased words of a name in order, so "Doe^John" and "john doe" compare equal
+func nameTokens(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// matchPatient checks that a DICOM file belongs to the patient of the visit, the patient id
+// is compared when the file has one and the name otherwise
+func matchPatient(file *dicom.File, patient *models.PatientIdentityRow) error {
+	if id := strings.TrimSpace(file.PatientID); id != "" {
+		if !strings.EqualFold(id, patient.PatientNumber) {
+			return fmt.Errorf("DICOM patient id %s does not match patient %s", id, patient.PatientNumber)
+		}
+		return nil
+	}
+	name := dicom.PersonName(file.PatientName)
+	if name == "" {
+		return fmt.Errorf("DICOM file has neither patient id nor patient name")
+	}
+	if nameTokens(name) != nameTokens(string(patient.FullName)) {
+		return fmt.Errorf("DICOM patient name %s does not match patient %s", name, patient.PatientNumber)
+	}
+	return nil
+}
+
+// rejection is why the content of an upload is refused, with the status and code of the response
+type rejection struct {
+	status int
+	code   string
+	detail string
+}
+
+// readDICOM parses a DICOM upload, validates it against the patient of the visit and fills the
+// study fields of row, the returned frame is nil when the pixel data can not be rendered
+func (s *Images) readDICOM(c *gin.Context, visit *models.VisitStateRow, row *models.VisitImageRow, data []byte) (*dicom.File, image.Image, bool) {
+	file, frame, rejected := s.inspectDICOM(visit, row, data)
+	if rejected != nil {
+		locale.Error(c, rejected.status, rejected.code, rejected.detail)
+		return nil, nil, false
+	}
+	return file, frame, true
+}
+
+// inspectDICOM is readDICOM without a request, rescans of quarantined uploads run it too
+func (s *Images) inspectDICOM(visit *models.VisitStateRow, row *models.VisitImageRow, data []byte) (*dicom.File, image.Image, *rejection) {
+	file, err := dicom.Parse(data)
+	if err != nil {
+		return nil, nil, &rejection{http.StatusBadRequest, i18n.InvalidImage, "invalid DICOM file: " + err.Error()}
+	}
+	patients, err := libQuery.GetQuery[models.PatientIdentityRow](patientIdentity.SQL(), s.Core.GetDB(), visit.PatientID)
+	if err != nil {
+		return nil, nil, &rejection{http.StatusInternalServerError, i18n.InternalError, err.Error()}
+	}
+	if len(patients) == 0 {
+		return nil, nil, &rejection{http.StatusNotFound, i18n.PatientNotFound, "patient not found"}
+	}
+	if err = matchPatient(file, &patients[0]); err != nil {
+		return nil, nil, &rejection{http.StatusUnprocessableEntity, i18n.PatientMismatch, err.Error()}
+	}
+
+	row.Modality = dialect.Text(file.Modality)
+	row.StudyUID = dialect.Text(file.StudyInstanceUID)
+	if row.ImageType == "" {
+		row.ImageType = ImageTypeDICOM
+	}
+	if row.Description == "" {
+		row.Description = dialect.Text(file.StudyDescription)
+	}
+	// compressed transfer syntaxes other than baseline JPEG are stored without renditions
+	frame, err := file.Frame()
+	if err != nil {
+		log.Println("no preview for DICOM image", row.ID, err)
+		return file, nil, nil
+	}
+	return file, frame, nil
+}
+
+// indexStudy adds an uploaded instance to the studies of the patient, the image is already
+// stored so a failure is only logged
+func (s *Images) indexStudy(visit *models.VisitStateRow, file *dicom.File) {
+	var studyDate any
+	if !file.StudyDate.IsZero() {
+		studyDate = file.StudyDate
+	}
+	_, err := s.Core.GetDB().InsertRow(upsertStudy.SQL(), visit.PatientID, file.StudyInstanceUID,
+		file.Modality, studyDate, file.StudyDescription, visit.ID)
+	if err != nil {
+		log.Println("error indexing imaging study", file.StudyInstanceUID, err)
+	}
+}
+
+// unindexStudy removes a deleted instance from the studies of the patient
+func (s *Images) unindexStudy(visit *models.VisitStateRow, studyUID string) {
+	if _, err := s.Core.GetDB().InsertRow(releaseStudy.SQL(), visit.PatientID, studyUID); err != nil {
+		log.Println("error updating imaging study", studyUID, err)
+		return
+	}
+	if _, err := s.Core.GetDB().InsertRow(dropEmptyStudies.SQL(), visit.PatientID); err != nil {
+		log.Println("error removing empty imaging studies", err)
+	}
+}
+
+// Studies handles GET requests for the imaging studies of a patient with their images
+func (s *Images) Studies(c *gin.Context) {
+	if _, ok := s.user(c); !ok {
+		return
+	}
+	studies, err := libQuery.GetQuery[models.ImagingStudyRow](patientStudies.SQL(), s.Core.GetDB(), c.Param("id"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if studies == nil {
+		studies = []models.ImagingStudyRow{}
+	}
+	images, err := libQuery.GetQuery[models.VisitImageRow](patientStudyImages.SQL(), s.Core.GetDB(), c.Param("id"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	byStudy := map[string][]models.VisitImageRow{}
+	for _, row := range images {
+		byStudy[string(row.StudyUID)] = append(byStudy[string(row.StudyUID)], s.withURL(row))
+	}
+	for i := range studies {
+		studies[i].Images = byStudy[string(studies[i].StudyUID)]
+		if studies[i].Images == nil {
+			studies[i].Images = []models.VisitImageRow{}
+		}
+	}
+	locale.Respond(c, http.StatusOK, i18n.StudiesRetrieved, studies)
+}
@@ -0,0 +1,539 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"healthcare/controllers/locale"
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/dicom"
+	"healthcare/utils/i18n"
+	"healthcare/utils/imaging"
+	"healthcare/utils/scan"
+	"healthcare/utils/storage"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// DefaultMaxSize is used when no upload size limit is configured
+const DefaultMaxSize = 20 << 20
+
+// DefaultAllowedTypes are accepted when no MIME types are configured
+var DefaultAllowedTypes = []string{"image/jpeg", "image/png", "image/webp", dicom.MimeType}
+
+// extensions of the stored files per sniffed MIME type
+var extensions = map[string]string{
+	"image/jpeg":   ".jpg",
+	"image/png":    ".png",
+	"image/webp":   ".webp",
+	"image/gif":    ".gif",
+	"image/bmp":    ".bmp",
+	dicom.MimeType: ".dcm",
+}
+
+// Images handles uploads and downloads of visit images
+type Images struct {
+	Core     requestCore.RequestCoreInterface
+	Storage  storage.Storage
+	Scanner  scan.Scanner
+	Signer   storage.URLSigner
+	MaxSize  int64
+	Allowed  map[string]bool
+	BasePath string
+}
+
+func (s *Images) fileURL(row *models.VisitImageRow, variant string) string {
+	return s.Signer.Sign(path.Join("/", s.BasePath, "visits", row.VisitID, "images", row.ID, variant), time.Now())
+}
+
+// withURL replaces the stored url of uploaded files with signed download urls,
+// rows created with a client supplied url keep it and quarantined files get none
+func (s *Images) withURL(row models.VisitImageRow) models.VisitImageRow {
+	if !scan.Downloadable(row.ScanStatus) {
+		row.ImageURL = ""
+		return row
+	}
+	if row.StorageKey != "" {
+		row.ImageURL = dialect.Text(s.fileURL(&row, "file"))
+	}
+	if row.ThumbnailKey != "" {
+		row.ThumbnailURL = s.fileURL(&row, imaging.Thumbnail.Name)
+	}
+	if row.PreviewKey != "" {
+		row.PreviewURL = s.fileURL(&row, imaging.Preview.Name)
+	}
+	return row
+}
+
+// visit loads the visit of the request and writes the error response when it is missing
+func (s *Images) visit(c *gin.Context) (*models.VisitStateRow, bool) {
+	rows, err := libQuery.GetQuery[models.VisitStateRow](imageVisit.SQL(), s.Core.GetDB(), c.Param("id"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return nil, false
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.VisitNotFound, "visit not found")
+		return nil, false
+	}
+	return &rows[0], true
+}
+
+func (s *Images) user(c *gin.Context) (*ums.UserData, bool) {
+	user, err := ums.HeaderUser(s.Core, c.GetHeader("Authorization"))
+	if err != nil {
+		locale.Error(c, http.StatusUnauthorized, i18n.Unauthorized, err.Error())
+		return nil, false
+	}
+	return user, true
+}
+
+// storedFile is a file of an image that is written to storage
+type storedFile struct {
+	key      string
+	data     []byte
+	mimeType string
+}
+
+// prepare strips metadata of the original and, for JPEG and PNG, renders upright thumbnail
+// and preview renditions, the keys of the files are set on row. frame is the already decoded
+// image of formats that imaging does not read, e.g. a DICOM frame, renditions are made from it when set
+func prepare(row *models.VisitImageRow, data []byte, frame image.Image) ([]storedFile, error) {
+	data, err := imaging.Strip(string(row.MimeType), data)
+	if err != nil {
+		return nil, err
+	}
+	base := "visits/" + row.VisitID + "/" + row.ID
+	row.StorageKey = dialect.Text(base + extensions[string(row.MimeType)])
+	row.FileSize = int64(len(data))
+	files := []storedFile{{key: string(row.StorageKey), data: data, mimeType: string(row.MimeType)}}
+	img := frame
+	if img == nil {
+		if !imaging.Supported(string(row.MimeType)) {
+			return files, nil
+		}
+		if img, err = imaging.Decode(data); err != nil {
+			return nil, err
+		}
+	}
+	// the thumbnail is scaled from the preview, which is much cheaper than the original
+	scaled := imaging.Resize(img, imaging.Preview.MaxSide)
+	for _, rendition := range []imaging.Rendition{imaging.Preview, imaging.Thumbnail} {
+		rendered, err := imaging.Render(scaled, rendition)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, storedFile{key: base + "_" + rendition.Name + ".jpg", data: rendered, mimeType: "image/jpeg"})
+	}
+	row.PreviewKey, row.ThumbnailKey = dialect.Text(files[1].key), dialect.Text(files[2].key)
+	return files, nil
+}
+
+// save writes the files to storage, nothing is left behind when one of them fails
+func (s *Images) save(ctx context.Context, files []storedFile) error {
+	for i, file := range files {
+		err := s.Storage.Put(ctx, file.key, bytes.NewReader(file.data), int64(len(file.data)), file.mimeType)
+		if err != nil {
+			for _, stored := range files[:i] {
+				s.remove(ctx, stored.key)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes a stored file, failures only leave an orphan file behind so they are logged
+func (s *Images) remove(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.Storage.Delete(ctx, key); err != nil {
+		log.Println("error removing image file", key, err)
+	}
+}
+
+// Upload handles multipart POST requests with the image in the file field
+func (s *Images) Upload(c *gin.Context) {
+	user, ok := s.user(c)
+	if !ok {
+		return
+	}
+	visit, ok := s.visit(c)
+	if !ok {
+		return
+	}
+	if visit.IsLocked {
+		locale.Error(c, http.StatusConflict, i18n.VisitLocked, "visit is locked, use an addendum instead")
+		return
+	}
+
+	// leave room for the other form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.MaxSize+1<<20)
+	var request models.VisitImageRequest
+	if err := c.ShouldBind(&request); err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			locale.Error(c, http.StatusRequestEntityTooLarge, i18n.FileTooLarge, fmt.Sprintf("file is larger than %d bytes", s.MaxSize))
+			return
+		}
+		locale.Error(c, http.StatusBadRequest, i18n.FileRequired, "file is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > s.MaxSize {
+		locale.Error(c, http.StatusRequestEntityTooLarge, i18n.FileTooLarge, fmt.Sprintf("file is larger than %d bytes", s.MaxSize))
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+		return
+	}
+	mimeType := storage.DetectType(data)
+	if dicom.IsDICOM(data) {
+		mimeType = dicom.MimeType
+	}
+	if !s.Allowed[mimeType] {
+		locale.Error(c, http.StatusUnsupportedMediaType, i18n.FileTypeNotAllowed, fmt.Sprintf("file type %s is not allowed", mimeType))
+		return
+	}
+
+	row := models.VisitImageRow{
+		ID:          storage.NewID(),
+		VisitID:     visit.ID,
+		Description: dialect.Text(request.Description),
+		ImageType:   dialect.Text(request.ImageType),
+		FileName:    dialect.Text(filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))),
+		MimeType:    dialect.Text(mimeType),
+		UploadedBy:  dialect.Text(user.UserId),
+		CreatedAt:   time.Now(),
+	}
+	row.ImageURL = dialect.Text(path.Join("/", s.BasePath, "visits", row.VisitID, "images", row.ID, "file"))
+	verdict := scan.Check(c.Request.Context(), s.Scanner, bytes.NewReader(data))
+	row.ScanStatus, row.ScanSignature = verdict.Status, dialect.Text(verdict.Signature)
+	if !scan.Downloadable(row.ScanStatus) {
+		s.quarantine(c, row, data)
+		return
+	}
+	var study *dicom.File
+	var frame image.Image
+	if mimeType == dicom.MimeType {
+		if study, frame, ok = s.readDICOM(c, visit, &row, data); !ok {
+			return
+		}
+	}
+	files, err := prepare(&row, data, frame)
+	if err != nil {
+		locale.Error(c, http.StatusBadRequest, i18n.InvalidImage, "invalid image: "+err.Error())
+		return
+	}
+	if err = s.save(c.Request.Context(), files); err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	result, err := s.insert(&row)
+	if err != nil {
+		s.remove(c.Request.Context(), string(row.StorageKey))
+		s.remove(c.Request.Context(), string(row.ThumbnailKey))
+		s.remove(c.Request.Context(), string(row.PreviewKey))
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+
+	if study != nil {
+		s.indexStudy(visit, study)
+	}
+
+	row = s.withURL(row)
+	locale.Respond(c, http.StatusCreated, i18n.ImageSaved, models.VisitImageResponse{
+		Result: libQuery.GetDmlResult(result, nil),
+		Image:  &row,
+	})
+}
+
+func (s *Images) insert(row *models.VisitImageRow) (sql.Result, error) {
+	return s.Core.GetDB().InsertRow(insertImage.SQL(),
+		row.ID, row.VisitID, row.ImageURL, row.Description, row.ImageType,
+		row.FileName, row.FileSize, row.MimeType, row.StorageKey,
+		row.ThumbnailKey, row.PreviewKey, row.UploadedBy, row.Modality, row.StudyUID,
+		row.ScanStatus, row.ScanSignature)
+}
+
+// quarantine keeps a file that did not pass the scan apart from served files, unchanged and
+// without renditions, and records it so it can be reviewed
+func (s *Images) quarantine(c *gin.Context, row models.VisitImageRow, data []byte) {
+	row.StorageKey = dialect.Text(scan.QuarantineKey("visits/" + row.VisitID + "/" + row.ID + extensions[string(row.MimeType)]))
+	row.FileSize = int64(len(data))
+	err := s.Storage.Put(c.Request.Context(), string(row.StorageKey), bytes.NewReader(data), row.FileSize, string(row.MimeType))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if _, err = s.insert(&row); err != nil {
+		s.remove(c.Request.Context(), string(row.StorageKey))
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	row = s.withURL(row)
+	if row.ScanStatus == scan.Infected {
+		locale.ErrorWithData(c, http.StatusUnprocessableEntity, i18n.FileInfected, "file is infected with "+string(row.ScanSignature), row)
+		return
+	}
+	locale.ErrorWithData(c, http.StatusServiceUnavailable, i18n.FileNotScanned, "file could not be scanned and was quarantined", row)
+}
+
+// Rescan scans the images quarantined because the scanner failed again. Clean images are prepared
+// as on upload and moved out of quarantine, infected ones stay there with their signature and clean
+// ones the upload would have refused are removed. It stops at the first error of the scanner, which
+// is most likely still down, and returns the number of images rescanned
+func Rescan(ctx context.Context, core requestCore.RequestCoreInterface, store storage.Storage, scanner scan.Scanner) (int, error) {
+	s := &Images{Core: core, Storage: store, Scanner: scanner}
+	rows, err := libQuery.GetQuery[models.VisitImageRow](failedImages.SQL(), core.GetDB())
+	if err != nil {
+		return 0, err
+	}
+	rescanned := 0
+	for _, row := range rows {
+		done, err := s.rescan(ctx, row)
+		if err != nil {
+			return rescanned, err
+		}
+		if done {
+			rescanned++
+		}
+	}
+	return rescanned, nil
+}
+
+// rescan scans a quarantined image again, a file that can not be read is logged and left for the next run
+func (s *Images) rescan(ctx context.Context, row models.VisitImageRow) (bool, error) {
+	quarantined := string(row.StorageKey)
+	data, err := s.read(ctx, quarantined)
+	if err != nil {
+		log.Println("error reading quarantined image", row.ID, err)
+		return false, nil
+	}
+	result, err := s.Scanner.Scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	row.ScanStatus, row.ScanSignature = result.Status, dialect.Text(result.Signature)
+	if !scan.Downloadable(row.ScanStatus) {
+		return s.updateRescan(&row)
+	}
+
+	visits, err := libQuery.GetQuery[models.VisitStateRow](imageVisit.SQL(), s.Core.GetDB(), row.VisitID)
+	if err != nil || len(visits) == 0 {
+		return false, err
+	}
+	visit := &visits[0]
+	var study *dicom.File
+	var frame image.Image
+	if row.MimeType == dicom.MimeType {
+		var rejected *rejection
+		if study, frame, rejected = s.inspectDICOM(visit, &row, data); rejected != nil {
+			if rejected.status == http.StatusInternalServerError {
+				return false, errors.New(rejected.detail)
+			}
+			return false, s.discard(ctx, row, quarantined, rejected.detail)
+		}
+	}
+	files, err := prepare(&row, data, frame)
+	if err != nil {
+		return false, s.discard(ctx, row, quarantined, "invalid image: "+err.Error())
+	}
+	if err = s.save(ctx, files); err != nil {
+		return false, err
+	}
+	done, err := s.updateRescan(&row)
+	if !done {
+		for _, file := range files {
+			s.remove(ctx, file.key)
+		}
+		return false, err
+	}
+	s.remove(ctx, quarantined)
+	if study != nil {
+		s.indexStudy(visit, study)
+	}
+	return true, nil
+}
+
+// read returns the content of a stored file
+func (s *Images) read(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// updateRescan stores the verdict of a rescan, it reports false when the image was deleted meanwhile
+func (s *Images) updateRescan(row *models.VisitImageRow) (bool, error) {
+	result, err := s.Core.GetDB().InsertRow(updateRescan.SQL(),
+		row.ScanStatus, row.ScanSignature, row.StorageKey, row.ThumbnailKey, row.PreviewKey,
+		row.FileSize, row.ImageType, row.Description, row.Modality, row.StudyUID, row.ID)
+	if err != nil {
+		return false, err
+	}
+	return !updateRescan.Unchanged(result), nil
+}
+
+// discard removes a quarantined image that passed the rescan but would have been refused on upload,
+// the uploader was only told it could not be scanned
+func (s *Images) discard(ctx context.Context, row models.VisitImageRow, quarantined, reason string) error {
+	log.Println("removing quarantined image", row.ID, "of visit", row.VisitID, reason)
+	if _, err := s.Core.GetDB().InsertRow(deleteImage.SQL(), row.VisitID, row.ID); err != nil {
+		return err
+	}
+	s.remove(ctx, quarantined)
+	return nil
+}
+
+// List handles GET requests for the images of a visit
+func (s *Images) List(c *gin.Context) {
+	if _, ok := s.user(c); !ok {
+		return
+	}
+	if _, ok := s.visit(c); !ok {
+		return
+	}
+	rows, err := libQuery.GetQuery[models.VisitImageRow](visitImages.SQL(), s.Core.GetDB(), c.Param("id"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if rows == nil {
+		rows = []models.VisitImageRow{}
+	}
+	for i := range rows {
+		rows[i] = s.withURL(rows[i])
+	}
+	locale.Respond(c, http.StatusOK, i18n.ImagesRetrieved, rows)
+}
+
+// Download serves the original file
+func (s *Images) Download(c *gin.Context) {
+	s.serve(c, "file")
+}
+
+// Thumbnail serves the thumbnail rendition
+func (s *Images) Thumbnail(c *gin.Context) {
+	s.serve(c, imaging.Thumbnail.Name)
+}
+
+// Preview serves the web-sized preview rendition
+func (s *Images) Preview(c *gin.Context) {
+	s.serve(c, imaging.Preview.Name)
+}
+
+// serve streams a stored file, it is authorised by the signature of the url instead of a bearer token
+func (s *Images) serve(c *gin.Context, variant string) {
+	if !s.Signer.Verify(c.Request.URL.Path, c.Query("expires"), c.Query("signature"), time.Now()) {
+		locale.Error(c, http.StatusForbidden, i18n.InvalidLink, "download link is invalid or expired")
+		return
+	}
+	rows, err := libQuery.GetQuery[models.VisitImageRow](visitImage.SQL(), s.Core.GetDB(), c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.ImageNotFound, "image not found")
+		return
+	}
+	image := rows[0]
+	if !scan.Downloadable(image.ScanStatus) {
+		locale.Error(c, http.StatusForbidden, i18n.FileQuarantined, "image file is quarantined")
+		return
+	}
+	key, size, mimeType, fileName := string(image.StorageKey), image.FileSize, string(image.MimeType), string(image.FileName)
+	switch variant {
+	case imaging.Thumbnail.Name:
+		key, size, mimeType = string(image.ThumbnailKey), -1, "image/jpeg"
+	case imaging.Preview.Name:
+		key, size, mimeType = string(image.PreviewKey), -1, "image/jpeg"
+	}
+	if variant != "file" {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "_" + variant + ".jpg"
+	}
+	if key == "" {
+		locale.Error(c, http.StatusNotFound, i18n.FileNotFound, "image file not found")
+		return
+	}
+	body, err := s.Storage.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		locale.Error(c, http.StatusNotFound, i18n.FileNotFound, "image file not found")
+		return
+	}
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	defer body.Close()
+	c.DataFromReader(http.StatusOK, size, mimeType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": fileName}),
+		"Cache-Control":          "private, max-age=300",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// Delete handles DELETE requests for an image of an unlocked visit
+func (s *Images) Delete(c *gin.Context) {
+	if _, ok := s.user(c); !ok {
+		return
+	}
+	visit, ok := s.visit(c)
+	if !ok {
+		return
+	}
+	if visit.IsLocked {
+		locale.Error(c, http.StatusConflict, i18n.VisitLocked, "visit is locked, use an addendum instead")
+		return
+	}
+	rows, err := libQuery.GetQuery[models.VisitImageRow](visitImage.SQL(), s.Core.GetDB(), visit.ID, c.Param("imageId"))
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		locale.Error(c, http.StatusNotFound, i18n.ImageNotFound, "image not found")
+		return
+	}
+	result, err := s.Core.GetDB().InsertRow(deleteImage.SQL(), visit.ID, rows[0].ID)
+	if err != nil {
+		locale.Error(c, http.StatusInternalServerError, i18n.InternalError, err.Error())
+		return
+	}
+	s.remove(c.Request.Context(), string(rows[0].StorageKey))
+	s.remove(c.Request.Context(), string(rows[0].ThumbnailKey))
+	s.remove(c.Request.Context(), string(rows[0].PreviewKey))
+	if rows[0].StudyUID != "" {
+		s.unindexStudy(visit, string(rows[0].StudyUID))
+	}
+	locale.Respond(c, http.StatusOK, i18n.ImageDeleted, models.VisitImageResponse{
+		Result: libQuery.GetDmlResult(result, nil),
+	})
+}
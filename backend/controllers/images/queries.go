@@ -0,0 +1,324 @@
+package images
+
+import "healthcare/utils/dialect"
+
+// oracleImageColumns are the columns of visit images in the Oracle variants, which have no empty
+// strings to coalesce to
+const oracleImageColumns = `
+				i.id,
+				i.visit_id,
+				i.image_url,
+				i.description,
+				i.image_type,
+				i.file_name,
+				COALESCE(i.file_size, 0) AS file_size,
+				i.mime_type,
+				i.storage_key,
+				i.thumbnail_key,
+				i.preview_key,
+				i.uploaded_by,
+				i.modality,
+				i.study_uid,
+				i.scan_status,
+				i.scan_signature,
+				i.created_at`
+
+var (
+	imageVisit = dialect.Query{
+		Postgres: `--sql
+			SELECT v.id, v.patient_id, v.doctor_id, v.status, v.locked_at IS NOT NULL AS is_locked
+			  FROM public.visits v
+			 WHERE v.id = :1
+		`,
+		Oracle: `--sql
+			SELECT v.id, v.patient_id, v.doctor_id, v.status,
+				CASE WHEN v.locked_at IS NOT NULL THEN 1 ELSE 0 END AS is_locked
+			  FROM visits v
+			 WHERE v.id = :1
+		`,
+	}
+	insertImage = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.visit_images
+				(id, visit_id, image_url, description, image_type, file_name, file_size, mime_type, storage_key,
+				 thumbnail_key, preview_key, uploaded_by, modality, study_uid, scan_status, scan_signature, scanned_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, NULLIF(:13, ''), NULLIF(:14, ''), :15, NULLIF(:16, ''), NOW())
+		`,
+		Oracle: `--sql
+			INSERT INTO visit_images
+				(id, visit_id, image_url, description, image_type, file_name, file_size, mime_type, storage_key,
+				 thumbnail_key, preview_key, uploaded_by, modality, study_uid, scan_status, scan_signature, scanned_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16, SYSTIMESTAMP)
+		`,
+	}
+	visitImages = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				i.id,
+				i.visit_id,
+				COALESCE(i.image_url, '') AS image_url,
+				COALESCE(i.description, '') AS description,
+				COALESCE(i.image_type, '') AS image_type,
+				COALESCE(i.file_name, '') AS file_name,
+				COALESCE(i.file_size, 0) AS file_size,
+				COALESCE(i.mime_type, '') AS mime_type,
+				COALESCE(i.storage_key, '') AS storage_key,
+				COALESCE(i.thumbnail_key, '') AS thumbnail_key,
+				COALESCE(i.preview_key, '') AS preview_key,
+				COALESCE(i.uploaded_by, '') AS uploaded_by,
+				COALESCE(i.modality, '') AS modality,
+				COALESCE(i.study_uid, '') AS study_uid,
+				i.scan_status,
+				COALESCE(i.scan_signature, '') AS scan_signature,
+				i.created_at
+			  FROM public.visit_images i
+			 WHERE i.visit_id = :1
+			 ORDER BY i.created_at
+		`,
+		Oracle: `--sql
+			SELECT` + oracleImageColumns + `
+			  FROM visit_images i
+			 WHERE i.visit_id = :1
+			 ORDER BY i.created_at
+		`,
+	}
+	visitImage = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				i.id,
+				i.visit_id,
+				COALESCE(i.image_url, '') AS image_url,
+				COALESCE(i.description, '') AS description,
+				COALESCE(i.image_type, '') AS image_type,
+				COALESCE(i.file_name, '') AS file_name,
+				COALESCE(i.file_size, 0) AS file_size,
+				COALESCE(i.mime_type, '') AS mime_type,
+				COALESCE(i.storage_key, '') AS storage_key,
+				COALESCE(i.thumbnail_key, '') AS thumbnail_key,
+				COALESCE(i.preview_key, '') AS preview_key,
+				COALESCE(i.uploaded_by, '') AS uploaded_by,
+				COALESCE(i.modality, '') AS modality,
+				COALESCE(i.study_uid, '') AS study_uid,
+				i.scan_status,
+				COALESCE(i.scan_signature, '') AS scan_signature,
+				i.created_at
+			  FROM public.visit_images i
+			 WHERE i.visit_id = :1 AND i.id = :2
+		`,
+		Oracle: `--sql
+			SELECT` + oracleImageColumns + `
+			  FROM visit_images i
+			 WHERE i.visit_id = :1 AND i.id = :2
+		`,
+	}
+	patientIdentity = dialect.Query{
+		Postgres: `--sql
+			SELECT p.id, p.patient_id AS patient_number, COALESCE(p.full_name, pr.full_name, '') AS full_name
+			  FROM public.patients p
+			  LEFT JOIN public.profiles pr ON pr.id = p.profile_id
+			 WHERE p.id = :1
+		`,
+		Oracle: `--sql
+			SELECT p.id, p.patient_id AS patient_number, COALESCE(p.full_name, pr.full_name) AS full_name
+			  FROM patients p
+			  LEFT JOIN profiles pr ON pr.id = p.profile_id
+			 WHERE p.id = :1
+		`,
+	}
+	upsertStudy = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.imaging_studies
+				(patient_id, study_uid, modality, study_date, description, instance_count, first_visit_id, last_visit_id)
+			VALUES (:1, :2, :3, :4, :5, 1, :6, :6)
+			ON CONFLICT (patient_id, study_uid) DO UPDATE SET
+				instance_count = imaging_studies.instance_count + 1,
+				modality = COALESCE(NULLIF(EXCLUDED.modality, ''), imaging_studies.modality),
+				study_date = COALESCE(EXCLUDED.study_date, imaging_studies.study_date),
+				description = COALESCE(NULLIF(EXCLUDED.description, ''), imaging_studies.description),
+				last_visit_id = EXCLUDED.last_visit_id,
+				updated_at = NOW()
+		`,
+		Oracle: `--sql
+			MERGE INTO imaging_studies s
+			USING (
+				SELECT :1 AS patient_id, :2 AS study_uid, :3 AS modality, CAST(:4 AS DATE) AS study_date,
+				       :5 AS description, :6 AS visit_id
+				  FROM dual
+			) a
+			   ON (s.patient_id = a.patient_id AND s.study_uid = a.study_uid)
+			 WHEN MATCHED THEN UPDATE SET
+				s.instance_count = s.instance_count + 1,
+				s.modality = COALESCE(a.modality, s.modality),
+				s.study_date = COALESCE(a.study_date, s.study_date),
+				s.description = COALESCE(a.description, s.description),
+				s.last_visit_id = a.visit_id,
+				s.updated_at = SYSTIMESTAMP
+			 WHEN NOT MATCHED THEN INSERT
+				(patient_id, study_uid, modality, study_date, description, instance_count, first_visit_id, last_visit_id)
+				VALUES (a.patient_id, a.study_uid, a.modality, a.study_date, a.description, 1, a.visit_id, a.visit_id)
+		`,
+	}
+	releaseStudy = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.imaging_studies
+			   SET instance_count = instance_count - 1, updated_at = NOW()
+			 WHERE patient_id = :1 AND study_uid = :2
+		`,
+		Oracle: `--sql
+			UPDATE imaging_studies
+			   SET instance_count = instance_count - 1, updated_at = SYSTIMESTAMP
+			 WHERE patient_id = :1 AND study_uid = :2
+		`,
+	}
+	dropEmptyStudies = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.imaging_studies WHERE patient_id = :1 AND instance_count <= 0
+		`,
+		Oracle: `--sql
+			DELETE FROM imaging_studies WHERE patient_id = :1 AND instance_count <= 0
+		`,
+	}
+	patientStudies = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				s.id,
+				s.patient_id,
+				s.study_uid,
+				COALESCE(s.modality, '') AS modality,
+				COALESCE(s.study_date, DATE '0001-01-01') AS study_date,
+				COALESCE(s.description, '') AS description,
+				s.instance_count,
+				COALESCE(s.last_visit_id::TEXT, '') AS last_visit_id,
+				s.updated_at
+			  FROM public.imaging_studies s
+			 WHERE s.patient_id = :1
+			 ORDER BY s.study_date DESC NULLS LAST, s.updated_at DESC
+		`,
+		Oracle: `--sql
+			SELECT
+				s.id,
+				s.patient_id,
+				s.study_uid,
+				s.modality,
+				COALESCE(s.study_date, DATE '0001-01-01') AS study_date,
+				s.description,
+				s.instance_count,
+				s.last_visit_id,
+				s.updated_at
+			  FROM imaging_studies s
+			 WHERE s.patient_id = :1
+			 ORDER BY s.study_date DESC NULLS LAST, s.updated_at DESC
+		`,
+	}
+	patientStudyImages = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				i.id,
+				i.visit_id,
+				COALESCE(i.image_url, '') AS image_url,
+				COALESCE(i.description, '') AS description,
+				COALESCE(i.image_type, '') AS image_type,
+				COALESCE(i.file_name, '') AS file_name,
+				COALESCE(i.file_size, 0) AS file_size,
+				COALESCE(i.mime_type, '') AS mime_type,
+				COALESCE(i.storage_key, '') AS storage_key,
+				COALESCE(i.thumbnail_key, '') AS thumbnail_key,
+				COALESCE(i.preview_key, '') AS preview_key,
+				COALESCE(i.uploaded_by, '') AS uploaded_by,
+				COALESCE(i.modality, '') AS modality,
+				COALESCE(i.study_uid, '') AS study_uid,
+				i.scan_status,
+				COALESCE(i.scan_signature, '') AS scan_signature,
+				i.created_at
+			  FROM public.visit_images i
+			  JOIN public.visits v ON v.id = i.visit_id
+			 WHERE v.patient_id = :1 AND i.study_uid IS NOT NULL
+			 ORDER BY i.created_at
+		`,
+		Oracle: `--sql
+			SELECT` + oracleImageColumns + `
+			  FROM visit_images i
+			  JOIN visits v ON v.id = i.visit_id
+			 WHERE v.patient_id = :1 AND i.study_uid IS NOT NULL
+			 ORDER BY i.created_at
+		`,
+	}
+	// failedImages are the images quarantined because the scanner failed, oldest first
+	failedImages = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				i.id,
+				i.visit_id,
+				COALESCE(i.image_url, '') AS image_url,
+				COALESCE(i.description, '') AS description,
+				COALESCE(i.image_type, '') AS image_type,
+				COALESCE(i.file_name, '') AS file_name,
+				COALESCE(i.file_size, 0) AS file_size,
+				COALESCE(i.mime_type, '') AS mime_type,
+				COALESCE(i.storage_key, '') AS storage_key,
+				COALESCE(i.thumbnail_key, '') AS thumbnail_key,
+				COALESCE(i.preview_key, '') AS preview_key,
+				COALESCE(i.uploaded_by, '') AS uploaded_by,
+				COALESCE(i.modality, '') AS modality,
+				COALESCE(i.study_uid, '') AS study_uid,
+				i.scan_status,
+				COALESCE(i.scan_signature, '') AS scan_signature,
+				i.created_at
+			  FROM public.visit_images i
+			 WHERE i.scan_status = 'failed'
+			 ORDER BY i.created_at
+			 LIMIT 100
+		`,
+		Oracle: `--sql
+			SELECT` + oracleImageColumns + `
+			  FROM visit_images i
+			 WHERE i.scan_status = 'failed'
+			 ORDER BY i.created_at
+			 FETCH FIRST 100 ROWS ONLY
+		`,
+	}
+	// updateRescan records the verdict of a rescan and the files of an image it released, only while
+	// the image is still waiting for one
+	updateRescan = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.visit_images
+			   SET scan_status = :1, scan_signature = NULLIF(:2, ''), storage_key = :3, thumbnail_key = :4,
+			       preview_key = :5, file_size = :6, image_type = :7, description = :8,
+			       modality = NULLIF(:9, ''), study_uid = NULLIF(:10, ''), scanned_at = NOW()
+			 WHERE id = :11 AND scan_status = 'failed'
+		`,
+		Oracle: `--sql
+			UPDATE visit_images
+			   SET scan_status = :1, scan_signature = :2, storage_key = :3, thumbnail_key = :4,
+			       preview_key = :5, file_size = :6, image_type = :7, description = :8,
+			       modality = :9, study_uid = :10, scanned_at = SYSTIMESTAMP
+			 WHERE id = :11 AND scan_status = 'failed'
+		`,
+	}
+	deleteImage = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.visit_images WHERE visit_id = :1 AND id = :2
+		`,
+		Oracle: `--sql
+			DELETE FROM visit_images WHERE visit_id = :1 AND id = :2
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"deleteImage":        deleteImage,
+	"dropEmptyStudies":   dropEmptyStudies,
+	"failedImages":       failedImages,
+	"imageVisit":         imageVisit,
+	"insertImage":        insertImage,
+	"patientIdentity":    patientIdentity,
+	"patientStudies":     patientStudies,
+	"patientStudyImages": patientStudyImages,
+	"releaseStudy":       releaseStudy,
+	"updateRescan":       updateRescan,
+	"upsertStudy":        upsertStudy,
+	"visitImage":         visitImage,
+	"visitImages":        visitImages,
+}
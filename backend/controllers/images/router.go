@@ -0,0 +1,92 @@
+package images
+
+import (
+	"context"
+	"healthcare/models"
+	"healthcare/utils/scan"
+	"healthcare/utils/storage"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// LoadStorage builds the file storage configured in params
+func LoadStorage(wsParams *libParams.ApplicationParams[models.ApplicationParams]) storage.Storage {
+	store, err := storage.New(wsParams.Specific.Storage)
+	if err != nil {
+		log.Fatalln("error initializing file storage", err)
+	}
+	return store
+}
+
+// LoadSigner builds the signer of download URLs, startup fails without a configured secret
+func LoadSigner(wsParams *libParams.ApplicationParams[models.ApplicationParams]) storage.URLSigner {
+	config := wsParams.Specific.Storage
+	signer, err := storage.NewURLSigner(config.URLSecret, time.Duration(config.URLTTLMinutes)*time.Minute)
+	if err != nil {
+		log.Fatalln("error initializing download url signer", err)
+	}
+	return signer
+}
+
+// LoadScanner builds the upload scanner configured in params, an unreachable clamd is only
+// reported since uploads are quarantined while it is down and the uploads-rescan job scans them
+// once it is back
+func LoadScanner(wsParams *libParams.ApplicationParams[models.ApplicationParams]) scan.Scanner {
+	scanner, err := scan.New(wsParams.Specific.Scanner)
+	if err != nil {
+		log.Fatalln("error initializing upload scanner", err)
+	}
+	switch scanner := scanner.(type) {
+	case scan.None:
+		log.Println("upload scanner is not configured, uploads are stored unscanned")
+	case *scan.Clamd:
+		if err := scanner.Ping(context.Background()); err != nil {
+			log.Println("clamd is not reachable, uploads are quarantined and rescanned once it is", err)
+		}
+	}
+	return scanner
+}
+
+// AddImagesRoutes sets up upload, listing, download and deletion of visit images and the
+// imaging studies index of patients
+func AddImagesRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	store storage.Storage,
+	scanner scan.Scanner,
+	signer storage.URLSigner,
+) {
+	images := &Images{
+		Core:     model,
+		Storage:  store,
+		Scanner:  scanner,
+		Signer:   signer,
+		MaxSize:  int64(wsParams.Specific.Images.MaxSizeMB) << 20,
+		Allowed:  map[string]bool{},
+		BasePath: rg.BasePath(),
+	}
+	if images.MaxSize <= 0 {
+		images.MaxSize = DefaultMaxSize
+	}
+	allowed := wsParams.Specific.Images.AllowedTypes
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedTypes
+	}
+	for _, mimeType := range allowed {
+		images.Allowed[mimeType] = true
+	}
+
+	rg.POST("/visits/:id/images", images.Upload)                      // Upload image
+	rg.GET("/visits/:id/images", images.List)                         // Get images of visit
+	rg.GET("/visits/:id/images/:imageId/file", images.Download)       // Download with signed url
+	rg.GET("/visits/:id/images/:imageId/thumbnail", images.Thumbnail) // Thumbnail with signed url
+	rg.GET("/visits/:id/images/:imageId/preview", images.Preview)     // Preview with signed url
+	rg.DELETE("/visits/:id/images/:imageId", images.Delete)           // Delete image
+	rg.GET("/patients/:id/imaging-studies", images.Studies)           // DICOM studies of patient
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"healthcare/models"
+	"net/http"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type jobsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Runner    *Runner
+}
+
+type jobsHandler struct {
+	Name   string
+	Runner *Runner
+}
+
+// returns handler title
+func (h jobsHandler) Parameters() handlers.HandlerParameters {
+	path := "/jobs"
+	switch h.Name {
+	case "jobs-runs":
+		path = "/jobs/:name/runs"
+	case "jobs-trigger":
+		path = "/jobs/:name/trigger"
+	}
+	return handlers.HandlerParameters{
+		Title:          "jobs",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           path,
+	}
+}
+
+// runs after validating request
+func (h jobsHandler) Initializer(req handlers.HandlerRequest[models.JobRequest, *models.JobResponse]) error {
+	if h.Name == "jobs-list" {
+		return nil
+	}
+	req.Request.Name = req.W.Parser.GetUrlParam("name")
+	if _, ok := h.Runner.Lookup(req.Request.Name); !ok {
+		return libError.NewWithDescription(http.StatusNotFound, "JOB_NOT_FOUND", "job %s is not registered", req.Request.Name)
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h jobsHandler) Handler(req handlers.HandlerRequest[models.JobRequest, *models.JobResponse]) (*models.JobResponse, error) {
+	req.Response = &models.JobResponse{Result: libQuery.DmlResult{Success: true}}
+	switch h.Name {
+	case "jobs-list":
+		rows, err := libQuery.GetQuery[models.JobRow](jobList.SQL(), req.Core.GetDB())
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_JOBS", err.Error())
+		}
+		req.Response.Jobs = rows
+
+	case "jobs-runs":
+		rows, err := libQuery.GetQuery[models.JobRunRow](jobRuns.SQL(), req.Core.GetDB(), req.Request.Name)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_JOB_RUNS", err.Error())
+		}
+		req.Response.Runs = rows
+
+	case "jobs-trigger":
+		result, err := req.Core.GetDB().InsertRow(triggerJob.SQL(), req.Request.Name)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "JOB_NOT_FOUND", "job %s has not been stored by the runner yet", req.Request.Name)
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h jobsHandler) Simulation(req handlers.HandlerRequest[models.JobRequest, *models.JobResponse]) (*models.JobResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h jobsHandler) Finalizer(req handlers.HandlerRequest[models.JobRequest, *models.JobResponse]) {
+}
+
+// jobsListHandler godoc
+// @Summary Get background jobs
+// @Description Get every background job with its schedule, next run, lease and the outcome of its last run
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /jobs [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.JobResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env jobsEnv) jobsListHandler(simulation bool) any {
+	return handlers.BaseHandler[models.JobRequest, *models.JobResponse, jobsHandler](env.Interface, jobsHandler{Name: "jobs-list", Runner: env.Runner}, simulation)
+}
+
+// jobRunsHandler godoc
+// @Summary Get runs of a background job
+// @Description Get the latest 50 runs of a job with their trigger, replica and error
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param name path string true "Job name"
+// @Router /jobs/:name/runs [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.JobResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env jobsEnv) jobRunsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.JobRequest, *models.JobResponse, jobsHandler](env.Interface, jobsHandler{Name: "jobs-runs", Runner: env.Runner}, simulation)
+}
+
+// jobTriggerHandler godoc
+// @Summary Trigger a background job
+// @Description Make a job due now, the next replica to poll runs it outside its schedule
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param name path string true "Job name"
+// @Router /jobs/:name/trigger [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.JobResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env jobsEnv) jobTriggerHandler(simulation bool) any {
+	return handlers.BaseHandler[models.JobRequest, *models.JobResponse, jobsHandler](env.Interface, jobsHandler{Name: "jobs-trigger", Runner: env.Runner}, simulation)
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import "healthcare/utils/dialect"
+
+var (
+	// a changed schedule restarts the job from its new next run
+	upsertJob = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.jobs (name, schedule, next_run_at)
+			VALUES (:1, :2, :3)
+			ON CONFLICT (name) DO UPDATE SET
+				schedule = EXCLUDED.schedule,
+				next_run_at = CASE WHEN public.jobs.schedule <> EXCLUDED.schedule THEN EXCLUDED.next_run_at ELSE public.jobs.next_run_at END,
+				updated_at = NOW()
+		`,
+		Oracle: `--sql
+			MERGE INTO jobs j
+			USING (SELECT :1 AS name, :2 AS schedule, :3 AS next_run_at FROM dual) a
+			   ON (j.name = a.name)
+			 WHEN MATCHED THEN UPDATE SET
+				j.schedule = a.schedule,
+				j.next_run_at = CASE WHEN j.schedule <> a.schedule THEN a.next_run_at ELSE j.next_run_at END,
+				j.updated_at = SYSTIMESTAMP
+			 WHEN NOT MATCHED THEN INSERT (name, schedule, next_run_at) VALUES (a.name, a.schedule, a.next_run_at)
+		`,
+	}
+	// acquireLease succeeds for one replica only, an expired lease belongs to a replica that stopped
+	acquireLease = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.jobs SET lease_owner = :2, lease_until = NOW() + make_interval(secs => :3), updated_at = NOW()
+			 WHERE name = :1
+			   AND enabled
+			   AND next_run_at <= NOW()
+			   AND (lease_until IS NULL OR lease_until < NOW())
+		`,
+		Oracle: `--sql
+			MERGE INTO jobs j
+			USING (SELECT :1 AS name, :2 AS owner, NUMTODSINTERVAL(:3, 'SECOND') AS lease FROM dual) a
+			   ON (j.name = a.name)
+			 WHEN MATCHED THEN UPDATE SET j.lease_owner = a.owner, j.lease_until = SYSTIMESTAMP + a.lease, j.updated_at = SYSTIMESTAMP
+				WHERE j.enabled
+				  AND j.next_run_at <= SYSTIMESTAMP
+				  AND (j.lease_until IS NULL OR j.lease_until < SYSTIMESTAMP)
+		`,
+	}
+	renewLease = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.jobs SET lease_until = NOW() + make_interval(secs => :3)
+			 WHERE name = :1 AND lease_owner = :2
+		`,
+		Oracle: `--sql
+			MERGE INTO jobs j
+			USING (SELECT :1 AS name, :2 AS owner, NUMTODSINTERVAL(:3, 'SECOND') AS lease FROM dual) a
+			   ON (j.name = a.name AND j.lease_owner = a.owner)
+			 WHEN MATCHED THEN UPDATE SET j.lease_until = SYSTIMESTAMP + a.lease
+		`,
+	}
+	jobColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				j.name,
+				j.schedule,
+				j.enabled,
+				j.next_run_at,
+				j.attempt,
+				j.triggered,
+				COALESCE(j.lease_owner, '') AS lease_owner,
+				COALESCE(j.lease_until, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS lease_until,
+				COALESCE(j.last_run_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS last_run_at,
+				COALESCE(j.last_status, '') AS last_status,
+				COALESCE(j.last_error, '') AS last_error
+			  FROM public.jobs j
+		`,
+		Oracle: `--sql
+			SELECT
+				j.name,
+				j.schedule,
+				j.enabled,
+				j.next_run_at,
+				j.attempt,
+				j.triggered,
+				j.lease_owner,
+				COALESCE(j.lease_until, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS lease_until,
+				COALESCE(j.last_run_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS last_run_at,
+				j.last_status,
+				j.last_error
+			  FROM jobs j
+		`,
+	}
+	jobByName = jobColumns.Append(`
+		 WHERE j.name = :1
+	`)
+	jobList = jobColumns.Append(`
+		 ORDER BY j.name
+	`)
+	insertRun = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.job_runs (id, job_name, attempt, trigger, owner, status)
+			VALUES (:1, :2, :3, :4, :5, 'running')
+		`,
+		Oracle: `--sql
+			INSERT INTO job_runs (id, job_name, attempt, "TRIGGER", owner, status)
+			VALUES (:1, :2, :3, :4, :5, 'running')
+		`,
+	}
+	finishRun = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.job_runs SET status = :2, error = NULLIF(:3, ''), finished_at = NOW()
+			 WHERE id = :1
+		`,
+		Oracle: `--sql
+			MERGE INTO job_runs r
+			USING (SELECT :1 AS id, :2 AS status, :3 AS error FROM dual) a
+			   ON (r.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET r.status = a.status, r.error = a.error, r.finished_at = SYSTIMESTAMP
+		`,
+	}
+	// releaseJob only applies while the replica still holds the lease
+	releaseJob = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.jobs SET
+				next_run_at = :3,
+				attempt = :4,
+				last_run_at = NOW(),
+				last_status = :5,
+				last_error = NULLIF(:6, ''),
+				triggered = FALSE,
+				lease_owner = NULL,
+				lease_until = NULL,
+				updated_at = NOW()
+			 WHERE name = :1 AND lease_owner = :2
+		`,
+		Oracle: `--sql
+			MERGE INTO jobs j
+			USING (
+				SELECT :1 AS name, :2 AS owner, :3 AS next_run_at, :4 AS attempt, :5 AS last_status, :6 AS last_error
+				  FROM dual
+			) a
+			   ON (j.name = a.name)
+			 WHEN MATCHED THEN UPDATE SET
+				j.next_run_at = a.next_run_at,
+				j.attempt = a.attempt,
+				j.last_run_at = SYSTIMESTAMP,
+				j.last_status = a.last_status,
+				j.last_error = a.last_error,
+				j.triggered = FALSE,
+				j.lease_owner = NULL,
+				j.lease_until = NULL,
+				j.updated_at = SYSTIMESTAMP
+				WHERE j.lease_owner = a.owner
+		`,
+	}
+	triggerJob = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.jobs SET next_run_at = NOW(), triggered = TRUE, updated_at = NOW()
+			 WHERE name = :1
+		`,
+		Oracle: `--sql
+			UPDATE jobs SET next_run_at = SYSTIMESTAMP, triggered = TRUE, updated_at = SYSTIMESTAMP
+			 WHERE name = :1
+		`,
+	}
+	jobRuns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				r.id,
+				r.job_name,
+				r.attempt,
+				r.trigger,
+				r.owner,
+				r.status,
+				COALESCE(r.error, '') AS error,
+				r.started_at,
+				COALESCE(r.finished_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS finished_at
+			  FROM public.job_runs r
+			 WHERE r.job_name = :1
+			 ORDER BY r.started_at DESC
+			 LIMIT 50
+		`,
+		Oracle: `--sql
+			SELECT
+				r.id,
+				r.job_name,
+				r.attempt,
+				r."TRIGGER",
+				r.owner,
+				r.status,
+				r.error,
+				r.started_at,
+				COALESCE(r.finished_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS finished_at
+			  FROM job_runs r
+			 WHERE r.job_name = :1
+			 ORDER BY r.started_at DESC
+			 FETCH FIRST 50 ROWS ONLY
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"acquireLease": acquireLease,
+	"finishRun":    finishRun,
+	"insertRun":    insertRun,
+	"jobByName":    jobByName,
+	"jobList":      jobList,
+	"jobRuns":      jobRuns,
+	"releaseJob":   releaseJob,
+	"renewLease":   renewLease,
+	"triggerJob":   triggerJob,
+	"upsertJob":    upsertJob,
+}
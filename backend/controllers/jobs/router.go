@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"healthcare/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func AddJobsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	runner *Runner,
+	simulation bool,
+) {
+	env := &jobsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Runner:    runner,
+	}
+	rg.GET("/jobs", libGin.Gin(env.jobsListHandler(simulation)))
+	rg.GET("/jobs/:name/runs", libGin.Gin(env.jobRunsHandler(simulation)))
+	rg.POST("/jobs/:name/trigger", libGin.Gin(env.jobTriggerHandler(simulation)))
+}
@@ -0,0 +1,298 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/jobs"
+	"healthcare/utils/storage"
+	"log"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Defaults of the runner
+const (
+	DefaultTick        = 15 * time.Second
+	DefaultLease       = time.Minute
+	DefaultMaxAttempts = 3
+	DefaultRetryBase   = time.Minute
+	DefaultTimeout     = 30 * time.Minute
+)
+
+// Triggers of a run
+const (
+	Scheduled = "schedule"
+	Manual    = "manual"
+	Retry     = "retry"
+)
+
+// Statuses of a run
+const (
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+)
+
+// Job is a named task run on a schedule, Run must return when ctx is done
+type Job struct {
+	Name    string
+	Spec    string
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+
+	schedule jobs.Schedule
+}
+
+// Runner runs the registered jobs on their schedules, every replica runs one and a lease in the
+// database makes sure a job runs on one replica at a time
+type Runner struct {
+	Core        requestCore.RequestCoreInterface
+	Location    *time.Location
+	Owner       string
+	Tick        time.Duration
+	Lease       time.Duration
+	MaxAttempts int
+	RetryBase   time.Duration
+	Schedules   map[string]string
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// NewRunner creates the runner configured in params, jobs are added with Register
+func NewRunner(core requestCore.RequestCoreInterface, wsParams *libParams.ApplicationParams[models.ApplicationParams]) *Runner {
+	params := wsParams.Specific.Jobs
+	host, _ := os.Hostname()
+	r := &Runner{
+		Core:        core,
+		Location:    appointments.LoadLocation(wsParams),
+		Owner:       fmt.Sprintf("%s-%s", host, storage.NewID()[:8]),
+		Tick:        time.Duration(params.TickSeconds) * time.Second,
+		Lease:       time.Duration(params.LeaseSeconds) * time.Second,
+		MaxAttempts: params.MaxAttempts,
+		RetryBase:   time.Duration(params.RetryBaseSeconds) * time.Second,
+		Schedules:   params.Schedules,
+		jobs:        map[string]*Job{},
+		running:     map[string]bool{},
+	}
+	if r.Tick <= 0 {
+		r.Tick = DefaultTick
+	}
+	if r.Lease <= 0 {
+		r.Lease = DefaultLease
+	}
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultMaxAttempts
+	}
+	if r.RetryBase <= 0 {
+		r.RetryBase = DefaultRetryBase
+	}
+	return r
+}
+
+// Register adds a job, a schedule configured for its name replaces Spec
+func (r *Runner) Register(job Job) {
+	if spec, ok := r.Schedules[job.Name]; ok && spec != "" {
+		job.Spec = spec
+	}
+	schedule, err := jobs.Parse(job.Spec, r.Location)
+	if err != nil {
+		log.Fatalln("error parsing schedule of job", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+	job.schedule = schedule
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.Name] = &job
+}
+
+// Lookup returns the registered job name
+func (r *Runner) Lookup(name string) (*Job, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[name]
+	return job, ok
+}
+
+// Names returns the names of the registered jobs in order
+func (r *Runner) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start stores the registered jobs and runs them until ctx is done, then waits for the running ones
+func (r *Runner) Start(ctx context.Context) {
+	now := time.Now()
+	for _, name := range r.Names() {
+		job, _ := r.Lookup(name)
+		if _, err := r.Core.GetDB().InsertRow(upsertJob.SQL(), job.Name, job.Spec, job.schedule.Next(now)); err != nil {
+			log.Println("jobs: storing", job.Name, err)
+		}
+	}
+	ticker := time.NewTicker(r.Tick)
+	defer ticker.Stop()
+	for {
+		r.poll(ctx)
+		select {
+		case <-ctx.Done():
+			r.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll starts every due job whose lease this replica acquires
+func (r *Runner) poll(ctx context.Context) {
+	for _, name := range r.Names() {
+		if ctx.Err() != nil {
+			return
+		}
+		r.mu.Lock()
+		busy := r.running[name]
+		r.mu.Unlock()
+		if busy {
+			continue
+		}
+		result, err := r.Core.GetDB().InsertRow(acquireLease.SQL(), name, r.Owner, r.Lease.Seconds())
+		if err != nil {
+			log.Println("jobs: acquiring", name, err)
+			continue
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			continue
+		}
+		state, err := libQuery.GetQuery[models.JobRow](jobByName.SQL(), r.Core.GetDB(), name)
+		if err != nil || len(state) == 0 {
+			log.Println("jobs: reading", name, err)
+			continue
+		}
+		job, _ := r.Lookup(name)
+		r.mu.Lock()
+		r.running[name] = true
+		r.mu.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer func() {
+				r.mu.Lock()
+				delete(r.running, name)
+				r.mu.Unlock()
+			}()
+			r.run(ctx, job, state[0])
+		}()
+	}
+}
+
+// run runs a job holding its lease, a failed run is retried after a back-off until it runs out
+// of attempts and then waits for its next scheduled run
+func (r *Runner) run(ctx context.Context, job *Job, state models.JobRow) {
+	trigger := Scheduled
+	switch {
+	case state.Triggered:
+		trigger = Manual
+	case state.Attempt > 0:
+		trigger = Retry
+	}
+	attempt := state.Attempt + 1
+	runID := storage.NewID()
+	if _, err := r.Core.GetDB().InsertRow(insertRun.SQL(), runID, job.Name, attempt, trigger, r.Owner); err != nil {
+		log.Println("jobs: recording run of", job.Name, err)
+	}
+
+	leaseCtx, lose := context.WithCancelCause(ctx)
+	runCtx, cancel := context.WithTimeout(leaseCtx, job.Timeout)
+	done := make(chan struct{})
+	go r.renew(runCtx, job.Name, done, lose)
+	err := r.call(runCtx, job)
+	close(done)
+	if cause := context.Cause(leaseCtx); errors.Is(cause, ErrLeaseLost) {
+		err = cause
+	}
+	cancel()
+	lose(nil)
+
+	now := time.Now()
+	status, message, next, failures := Succeeded, "", job.schedule.Next(now), 0
+	if err != nil {
+		status, message = Failed, err.Error()
+		log.Printf("jobs: %s failed on attempt %d: %v", job.Name, attempt, err)
+		if attempt < r.MaxAttempts {
+			next, failures = now.Add(jobs.Backoff(attempt, r.RetryBase)), attempt
+		}
+	}
+	if _, err := r.Core.GetDB().InsertRow(finishRun.SQL(), runID, status, message); err != nil {
+		log.Println("jobs: recording run of", job.Name, err)
+	}
+	if _, err := r.Core.GetDB().InsertRow(releaseJob.SQL(), job.Name, r.Owner, next, failures, status, message); err != nil {
+		log.Println("jobs: releasing", job.Name, err)
+	}
+}
+
+// call runs the job, a panic fails the run instead of the process
+func (r *Runner) call(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+			log.Printf("jobs: %s panicked: %v\n%s", job.Name, p, debug.Stack())
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// ErrLeaseLost fails a run whose lease was taken over or could not be renewed before it expired,
+// another replica may run the job by then
+var ErrLeaseLost = errors.New("lease of the job was lost")
+
+// renew extends the lease while the job runs so other replicas leave it alone. When the lease is
+// gone, or renewing keeps failing until it has expired, the run is cancelled with ErrLeaseLost
+func (r *Runner) renew(ctx context.Context, name string, done <-chan struct{}, lose context.CancelCauseFunc) {
+	ticker := time.NewTicker(r.Lease / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := r.Core.GetDB().InsertRow(renewLease.SQL(), name, r.Owner, r.Lease.Seconds())
+			if err == nil && renewLease.Unchanged(result) {
+				log.Println("jobs: lease of", name, "is held by another replica, cancelling the run")
+				lose(ErrLeaseLost)
+				return
+			}
+			if err == nil {
+				renewed = time.Now()
+				continue
+			}
+			log.Println("jobs: renewing lease of", name, err)
+			if time.Since(renewed) >= r.Lease {
+				log.Println("jobs: lease of", name, "expired, cancelling the run")
+				lose(fmt.Errorf("%w: %v", ErrLeaseLost, err))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,642 @@
+package labs
+
+import (
+	"fmt"
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/jalali"
+	"healthcare/utils/labs"
+	"healthcare/utils/storage"
+	"healthcare/utils/validation"
+	"healthcare/utils/visitflow"
+	"healthcare/utils/vitals"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+	"github.com/hmmftg/requestCore/webFramework"
+)
+
+type labsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Catalog   *labs.Catalog
+	Location  *time.Location
+}
+
+func getPatient(core requestCore.RequestCoreInterface, query, id string) (*models.LabPatientRow, error) {
+	rows, err := libQuery.GetQuery[models.LabPatientRow](query, core.GetDB(), id)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_ORDER", err.Error())
+	}
+	if len(rows) == 0 {
+		if query == orderPatient.SQL() {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "LAB_ORDER_NOT_FOUND", "lab order %s not found", id)
+		}
+		return nil, libError.NewWithDescription(http.StatusNotFound, "VISIT_NOT_FOUND", "visit %s not found", id)
+	}
+	return &rows[0], nil
+}
+
+// withResults attaches the results to their orders
+func withResults(orders []models.LabOrderRow, results []models.LabResultRow) []models.LabOrderRow {
+	byOrder := map[string][]models.LabResultRow{}
+	for _, result := range results {
+		byOrder[result.OrderID] = append(byOrder[result.OrderID], result)
+	}
+	for i := range orders {
+		orders[i].Results = byOrder[orders[i].ID]
+		if orders[i].Results == nil {
+			orders[i].Results = []models.LabResultRow{}
+		}
+	}
+	return orders
+}
+
+func getOrder(core requestCore.RequestCoreInterface, orderID string) (*models.LabOrderRow, error) {
+	orders, err := libQuery.GetQuery[models.LabOrderRow](orderByID.SQL(), core.GetDB(), orderID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_ORDER", err.Error())
+	}
+	if len(orders) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "LAB_ORDER_NOT_FOUND", "lab order %s not found", orderID)
+	}
+	results, err := libQuery.GetQuery[models.LabResultRow](resultsByOrder.SQL(), core.GetDB(), orderID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_RESULTS", err.Error())
+	}
+	return &withResults(orders, results)[0], nil
+}
+
+type panelsHandler struct {
+	Catalog *labs.Catalog
+}
+
+// returns handler title
+func (h panelsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "labs",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/labs/panels",
+	}
+}
+
+// runs after validating request
+func (h panelsHandler) Initializer(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabPanelsResponse]) error {
+	return nil
+}
+
+// Handler returns the orderable panels
+func (h panelsHandler) Handler(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabPanelsResponse]) (*models.LabPanelsResponse, error) {
+	req.Response = &models.LabPanelsResponse{Panels: h.Catalog.Panels()}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h panelsHandler) Simulation(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabPanelsResponse]) (*models.LabPanelsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h panelsHandler) Finalizer(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabPanelsResponse]) {
+}
+
+type ordersHandler struct {
+	Name    string
+	Catalog *labs.Catalog
+}
+
+// returns handler title
+func (h ordersHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.NoBinding
+	path := "/lab-orders/:id"
+	switch h.Name {
+	case "orders-post":
+		body, path = libRequest.JSON, "/visits/:id/lab-orders"
+	case "orders-visit":
+		path = "/visits/:id/lab-orders"
+	case "orders-cancel":
+		path = "/lab-orders/:id/cancel"
+	}
+	return handlers.HandlerParameters{
+		Title:          "labs",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           path,
+	}
+}
+
+// runs after validating request
+func (h ordersHandler) Initializer(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabOrderResponse]) error {
+	id := req.W.Parser.GetUrlParam("id")
+	switch h.Name {
+	case "orders-post", "orders-visit":
+		req.Request.VisitID = id
+		if id == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+		}
+	default:
+		req.Request.OrderID = id
+		if id == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_LAB_ORDER_ID", "lab order id is required")
+		}
+	}
+	if h.Name == "orders-post" {
+		fields, err := validation.Check(req.Request)
+		if _, ok := h.Catalog.Panel(req.Request.PanelCode); !ok && req.Request.PanelCode != "" {
+			fields = append(fields, panelField(h.Catalog))
+		}
+		return validation.Fail(fields, err)
+	}
+	return nil
+}
+
+// panelField is the error of a panel code missing from the catalog, the panels are configured so
+// their codes can not be a tag
+func panelField(catalog *labs.Catalog) validation.FieldError {
+	codes := []string{}
+	for _, panel := range catalog.Panels() {
+		codes = append(codes, panel.Code)
+	}
+	sort.Strings(codes)
+	return validation.FieldError{
+		Field:       "panel_code",
+		Code:        i18n.ValidationOneOf,
+		Rule:        "oneof",
+		Param:       strings.Join(codes, " "),
+		Description: fmt.Sprintf("panel_code must be one of %s", strings.Join(codes, ", ")),
+	}
+}
+
+// Handler is the main method that handles request and returns the response
+func (h ordersHandler) Handler(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabOrderResponse]) (*models.LabOrderResponse, error) {
+	switch h.Name {
+	case "orders-post":
+		patient, err := getPatient(req.Core, visitPatient.SQL(), req.Request.VisitID)
+		if err != nil {
+			return nil, err
+		}
+		if patient.VisitStatus == visitflow.Cancelled || patient.VisitStatus == visitflow.NoShow {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_NOT_ACTIVE", "labs can not be ordered for a %s visit", patient.VisitStatus)
+		}
+		user, err := ums.CurrentUser(req.W, req.Core)
+		if err != nil {
+			return nil, err
+		}
+		panel, _ := h.Catalog.Panel(req.Request.PanelCode)
+		id := storage.NewID()
+		result, err := req.Core.GetDB().InsertRow(insertOrder.SQL(), id, patient.VisitID, patient.PatientID,
+			panel.Code, panel.Name, labs.Ordered, strings.TrimSpace(req.Request.Notes), user.UserId, user.UserName)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		order, err := getOrder(req.Core, id)
+		if err != nil {
+			return nil, err
+		}
+		req.Response = &models.LabOrderResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+			Order:  order,
+		}
+		return req.Response, nil
+
+	case "orders-visit":
+		if _, err := getPatient(req.Core, visitPatient.SQL(), req.Request.VisitID); err != nil {
+			return nil, err
+		}
+		orders, err := libQuery.GetQuery[models.LabOrderRow](ordersByVisit.SQL(), req.Core.GetDB(), req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_ORDERS", err.Error())
+		}
+		results, err := libQuery.GetQuery[models.LabResultRow](resultsByVisit.SQL(), req.Core.GetDB(), req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_RESULTS", err.Error())
+		}
+		req.Response = &models.LabOrderResponse{
+			Result: libQuery.DmlResult{Success: true},
+			Orders: withResults(orders, results),
+		}
+		return req.Response, nil
+
+	case "orders-get":
+		order, err := getOrder(req.Core, req.Request.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		req.Response = &models.LabOrderResponse{
+			Result: libQuery.DmlResult{Success: true},
+			Order:  order,
+		}
+		return req.Response, nil
+
+	case "orders-cancel":
+		order, err := getOrder(req.Core, req.Request.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if order.Status != labs.Ordered {
+			return nil, libError.NewWithDescription(http.StatusConflict, "LAB_ORDER_NOT_CANCELLABLE", "lab order %s is %s, only orders without results can be cancelled", order.ID, order.Status)
+		}
+		result, err := req.Core.GetDB().InsertRow(cancelOrder.SQL(), order.ID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, "LAB_ORDER_CHANGED", "lab order %s changed while cancelling", order.ID)
+		}
+		order.Status = labs.Cancelled
+		req.Response = &models.LabOrderResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+			Order:  order,
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h ordersHandler) Simulation(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabOrderResponse]) (*models.LabOrderResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h ordersHandler) Finalizer(req handlers.HandlerRequest[models.LabOrderRequest, *models.LabOrderResponse]) {
+}
+
+type resultsHandler struct {
+	Catalog *labs.Catalog
+}
+
+// returns handler title
+func (h resultsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "labs",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/lab-orders/:id/results",
+	}
+}
+
+// runs after validating request
+func (h resultsHandler) Initializer(req handlers.HandlerRequest[models.LabResultsRequest, *models.LabResultsResponse]) error {
+	req.Request.OrderID = req.W.Parser.GetUrlParam("id")
+	if req.Request.OrderID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_LAB_ORDER_ID", "lab order id is required")
+	}
+	if err := validation.Error(req.Request); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i := range req.Request.Results {
+		entry := &req.Request.Results[i]
+		entry.AnalyteCode = strings.ToLower(strings.TrimSpace(entry.AnalyteCode))
+		if seen[entry.AnalyteCode] {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_LAB_RESULTS", "analyte %s is sent twice", entry.AnalyteCode)
+		}
+		seen[entry.AnalyteCode] = true
+	}
+	if req.Request.CollectedAt.IsZero() {
+		req.Request.CollectedAt = time.Now()
+	}
+	return nil
+}
+
+// Handler validates the values against the panel of the order, flags them by the reference
+// range of the patient and moves the order to partial or resulted
+func (h resultsHandler) Handler(req handlers.HandlerRequest[models.LabResultsRequest, *models.LabResultsResponse]) (*models.LabResultsResponse, error) {
+	order, err := getPatient(req.Core, orderPatient.SQL(), req.Request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.OrderStatus == labs.Cancelled {
+		return nil, libError.NewWithDescription(http.StatusConflict, "LAB_ORDER_CANCELLED", "lab order %s is cancelled", order.OrderID)
+	}
+	panel, ok := h.Catalog.Panel(order.PanelCode)
+	if !ok {
+		return nil, libError.NewWithDescription(http.StatusConflict, "INVALID_LAB_PANEL", "panel %s of lab order %s is no longer in the catalog", order.PanelCode, order.OrderID)
+	}
+	user, err := ums.CurrentUser(req.W, req.Core)
+	if err != nil {
+		return nil, err
+	}
+
+	// every value is checked before any is stored
+	age := vitals.Age(order.DateOfBirth, req.Request.CollectedAt)
+	analytes := make([]labs.Analyte, len(req.Request.Results))
+	evaluated := make([]labs.Result, len(req.Request.Results))
+	for i, entry := range req.Request.Results {
+		analyte, ok := panel.Analyte(entry.AnalyteCode)
+		if !ok {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, "INVALID_ANALYTE", "analyte %q is not part of panel %s", entry.AnalyteCode, panel.Code)
+		}
+		if entry.Unit != "" && !strings.EqualFold(entry.Unit, analyte.Unit) {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, "INVALID_UNIT", "%s must be reported in %q, not %q", analyte.Code, analyte.Unit, entry.Unit)
+		}
+		result, err := analyte.Evaluate(entry.Value, string(order.Gender), age)
+		if err != nil {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, "INVALID_LAB_VALUE", "%s", err.Error())
+		}
+		analytes[i], evaluated[i] = analyte, result
+	}
+
+	req.Response = &models.LabResultsResponse{AbnormalFlags: map[string]string{}}
+	var result libQuery.DmlResult
+	for i, entry := range req.Request.Results {
+		analyte, value := analytes[i], evaluated[i]
+		inserted, err := req.Core.GetDB().InsertRow(upsertResult.SQL(),
+			order.OrderID, order.VisitID, order.PatientID, analyte.Code, analyte.Name, analyte.Type,
+			value.Value, value.Numeric, analyte.Unit, value.Reference.Low, value.Reference.High, value.RefText,
+			value.Flag, strings.TrimSpace(entry.Comment), user.UserId)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		result = libQuery.GetDmlResult(inserted, nil)
+		if value.Flag != "" {
+			req.Response.AbnormalFlags[analyte.Code] = value.Flag
+		}
+		if labs.IsCritical(value.Flag) {
+			req.Response.Critical = append(req.Response.Critical, analyte.Code)
+		}
+	}
+	sort.Strings(req.Response.Critical)
+
+	results, err := libQuery.GetQuery[models.LabResultRow](resultsByOrder.SQL(), req.Core.GetDB(), order.OrderID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_RESULTS", err.Error())
+	}
+	status := labs.Partial
+	if len(results) >= len(panel.Analytes) {
+		status = labs.Resulted
+	}
+	if _, err = req.Core.GetDB().InsertRow(updateOrderStatus.SQL(), order.OrderID, status, req.Request.CollectedAt); err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+	}
+	req.Response.Result = result
+	req.Response.Status = status
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h resultsHandler) Simulation(req handlers.HandlerRequest[models.LabResultsRequest, *models.LabResultsResponse]) (*models.LabResultsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h resultsHandler) Finalizer(req handlers.HandlerRequest[models.LabResultsRequest, *models.LabResultsResponse]) {
+}
+
+type cumulativeHandler struct {
+	Location *time.Location
+}
+
+// returns handler title
+func (h cumulativeHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "labs",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/patients/:id/labs",
+	}
+}
+
+// runs after validating request
+func (h cumulativeHandler) Initializer(req handlers.HandlerRequest[models.LabCumulativeRequest, *models.LabCumulativeResponse]) error {
+	req.Request.Analyte = strings.ToLower(strings.TrimSpace(req.Request.Analyte))
+	return nil
+}
+
+// resultRange returns the instants [from, to) of the results of a request, its days are in the
+// calendar it selects and in the clinic time zone loc, to is inclusive and defaults to now and from
+// defaults to five years before to
+func resultRange(w webFramework.WebFramework, request *models.LabCumulativeRequest, loc *time.Location) (time.Time, time.Time, error) {
+	calendar, err := jalali.FromRequest(w, request.Calendar)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := jalali.ParseDay(calendar, request.From, "from", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := jalali.ParseDay(calendar, request.To, "to", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	} else {
+		// the end date is inclusive
+		to = to.AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(-5, 0, 0)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, libError.NewWithDescription(http.StatusBadRequest, "INVALID_DATE_RANGE", "from must be before to")
+	}
+	return from, to, nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h cumulativeHandler) Handler(req handlers.HandlerRequest[models.LabCumulativeRequest, *models.LabCumulativeResponse]) (*models.LabCumulativeResponse, error) {
+	patientID := req.W.Parser.GetUrlParam("id")
+	from, to, err := resultRange(req.W, req.Request, h.Location)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := libQuery.GetQuery[models.LabResultRow](resultsByPatient.SQL(), req.Core.GetDB(),
+		patientID, from, to, req.Request.Analyte)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_LAB_RESULTS", err.Error())
+	}
+	req.Response = &models.LabCumulativeResponse{
+		PatientID: patientID,
+		Analytes:  buildSeries(rows),
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h cumulativeHandler) Simulation(req handlers.HandlerRequest[models.LabCumulativeRequest, *models.LabCumulativeResponse]) (*models.LabCumulativeResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h cumulativeHandler) Finalizer(req handlers.HandlerRequest[models.LabCumulativeRequest, *models.LabCumulativeResponse]) {
+}
+
+// buildSeries groups results per analyte in the order the analytes were first resulted, rows are
+// sorted oldest first so the name, unit and range of each series are those of its latest result
+func buildSeries(rows []models.LabResultRow) []models.LabSeries {
+	series := []models.LabSeries{}
+	index := map[string]int{}
+	for _, row := range rows {
+		i, ok := index[row.AnalyteCode]
+		if !ok {
+			i = len(series)
+			index[row.AnalyteCode] = i
+			series = append(series, models.LabSeries{Code: row.AnalyteCode, Points: []models.LabPoint{}})
+		}
+		series[i].Name, series[i].Unit, series[i].RefText = row.AnalyteName, string(row.Unit), string(row.RefText)
+		point := models.LabPoint{
+			OrderID:    row.OrderID,
+			VisitID:    row.VisitID,
+			ResultedAt: row.ResultedAt,
+			Value:      row.Value,
+			Flag:       string(row.Flag),
+		}
+		if row.ValueType == labs.Numeric {
+			value := row.NumericValue
+			point.Numeric = &value
+		}
+		series[i].Points = append(series[i].Points, point)
+	}
+	return series
+}
+
+// labPanelsHandler godoc
+// @Summary Get orderable lab panels
+// @Description Get the lab panels with their analytes, units and reference ranges
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Router /labs/panels [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabPanelsResponse
+// @Failure 401 {object} response.ErrorResponse
+func (env labsEnv) labPanelsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabOrderRequest, *models.LabPanelsResponse, panelsHandler](env.Interface, panelsHandler{Catalog: env.Catalog}, simulation)
+}
+
+// labOrderPostHandler godoc
+// @Summary Order a lab panel for a visit
+// @Description Order a lab panel of the catalog for a visit, the orderer is taken from the bearer token
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param order body models.LabOrderRequest true "Lab order"
+// @Router /visits/:id/lab-orders [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabOrderResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labOrderPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabOrderRequest, *models.LabOrderResponse, ordersHandler](env.Interface, ordersHandler{Name: "orders-post", Catalog: env.Catalog}, simulation)
+}
+
+// labOrdersVisitHandler godoc
+// @Summary Get lab orders of a visit
+// @Description Get the lab orders of a visit with their results
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/lab-orders [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabOrderResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labOrdersVisitHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabOrderRequest, *models.LabOrderResponse, ordersHandler](env.Interface, ordersHandler{Name: "orders-visit", Catalog: env.Catalog}, simulation)
+}
+
+// labOrderGetHandler godoc
+// @Summary Get a lab order
+// @Description Get a lab order with its results
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Lab order ID"
+// @Router /lab-orders/:id [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabOrderResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labOrderGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabOrderRequest, *models.LabOrderResponse, ordersHandler](env.Interface, ordersHandler{Name: "orders-get", Catalog: env.Catalog}, simulation)
+}
+
+// labOrderCancelHandler godoc
+// @Summary Cancel a lab order
+// @Description Cancel a lab order that has no results yet
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Lab order ID"
+// @Router /lab-orders/:id/cancel [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabOrderResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labOrderCancelHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabOrderRequest, *models.LabOrderResponse, ordersHandler](env.Interface, ordersHandler{Name: "orders-cancel", Catalog: env.Catalog}, simulation)
+}
+
+// labResultsPostHandler godoc
+// @Summary Enter results of a lab order
+// @Description Enter typed analyte values, units are checked against the panel and values are flagged by the reference range of the patient's sex and age, a repeated analyte amends its result
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Lab order ID"
+// @Param results body models.LabResultsRequest true "Lab results"
+// @Router /lab-orders/:id/results [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabResultsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labResultsPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabResultsRequest, *models.LabResultsResponse, resultsHandler](env.Interface, resultsHandler{Catalog: env.Catalog}, simulation)
+}
+
+// labCumulativeHandler godoc
+// @Summary Get cumulative lab results of a patient
+// @Description Get lab results of a patient across visits grouped per analyte for comparing values over time, defaults to the last five years
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Patient ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Param analyte query string false "Analyte code"
+// @Router /patients/:id/labs [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.LabCumulativeResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env labsEnv) labCumulativeHandler(simulation bool) any {
+	return handlers.BaseHandler[models.LabCumulativeRequest, *models.LabCumulativeResponse, cumulativeHandler](env.Interface, cumulativeHandler{Location: env.Location}, simulation)
+}
@@ -0,0 +1,295 @@
+package labs
+
+import "healthcare/utils/dialect"
+
+var (
+	visitPatient = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				'' AS order_id,
+				'' AS panel_code,
+				'' AS order_status,
+				v.id AS visit_id,
+				v.patient_id,
+				v.status AS visit_status,
+				COALESCE(p.gender, '') AS gender,
+				COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM public.visits v
+			  JOIN public.patients p ON p.id = v.patient_id
+			 WHERE v.id = :1
+		`,
+		Oracle: `--sql
+			SELECT
+				CAST(NULL AS VARCHAR2(36)) AS order_id,
+				CAST(NULL AS VARCHAR2(64)) AS panel_code,
+				CAST(NULL AS VARCHAR2(32)) AS order_status,
+				v.id AS visit_id,
+				v.patient_id,
+				v.status AS visit_status,
+				p.gender,
+				COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM visits v
+			  JOIN patients p ON p.id = v.patient_id
+			 WHERE v.id = :1
+		`,
+	}
+	orderPatient = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				o.id AS order_id,
+				o.panel_code,
+				o.status AS order_status,
+				o.visit_id,
+				o.patient_id,
+				v.status AS visit_status,
+				COALESCE(p.gender, '') AS gender,
+				COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM public.lab_orders o
+			  JOIN public.visits v ON v.id = o.visit_id
+			  JOIN public.patients p ON p.id = o.patient_id
+			 WHERE o.id = :1
+		`,
+		Oracle: `--sql
+			SELECT
+				o.id AS order_id,
+				o.panel_code,
+				o.status AS order_status,
+				o.visit_id,
+				o.patient_id,
+				v.status AS visit_status,
+				p.gender,
+				COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM lab_orders o
+			  JOIN visits v ON v.id = o.visit_id
+			  JOIN patients p ON p.id = o.patient_id
+			 WHERE o.id = :1
+		`,
+	}
+	insertOrder = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.lab_orders
+				(id, visit_id, patient_id, panel_code, panel_name, status, notes, ordered_by, ordered_by_name)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)
+		`,
+		Oracle: `--sql
+			INSERT INTO lab_orders
+				(id, visit_id, patient_id, panel_code, panel_name, status, notes, ordered_by, ordered_by_name)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)
+		`,
+	}
+	selectOrders = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				o.id,
+				o.visit_id,
+				o.patient_id,
+				o.panel_code,
+				o.panel_name,
+				o.status,
+				COALESCE(o.notes, '') AS notes,
+				o.ordered_by,
+				COALESCE(o.ordered_by_name, '') AS ordered_by_name,
+				o.ordered_at,
+				COALESCE(o.collected_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS collected_at,
+				COALESCE(o.resulted_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS resulted_at
+			  FROM public.lab_orders o
+		`,
+		Oracle: `--sql
+			SELECT
+				o.id,
+				o.visit_id,
+				o.patient_id,
+				o.panel_code,
+				o.panel_name,
+				o.status,
+				o.notes,
+				o.ordered_by,
+				o.ordered_by_name,
+				o.ordered_at,
+				COALESCE(o.collected_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS collected_at,
+				COALESCE(o.resulted_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS resulted_at
+			  FROM lab_orders o
+		`,
+	}
+	ordersByVisit = selectOrders.Append(`
+		 WHERE o.visit_id = :1
+		 ORDER BY o.ordered_at
+	`)
+	orderByID = selectOrders.Append(`
+		 WHERE o.id = :1
+	`)
+	cancelOrder = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.lab_orders
+			   SET status = 'cancelled', updated_at = NOW()
+			 WHERE id = :1 AND status = 'ordered'
+		`,
+		Oracle: `--sql
+			UPDATE lab_orders
+			   SET status = 'cancelled', updated_at = SYSTIMESTAMP
+			 WHERE id = :1 AND status = 'ordered'
+		`,
+	}
+	updateOrderStatus = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.lab_orders
+			   SET status = :2,
+			       collected_at = COALESCE(collected_at, :3),
+			       resulted_at = CASE WHEN :2 = 'resulted' THEN NOW() ELSE resulted_at END,
+			       updated_at = NOW()
+			 WHERE id = :1 AND status <> 'cancelled'
+		`,
+		Oracle: `--sql
+			MERGE INTO lab_orders o
+			USING (SELECT :1 AS id, :2 AS status, :3 AS collected_at FROM dual) a
+			   ON (o.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				o.status = a.status,
+				o.collected_at = COALESCE(o.collected_at, a.collected_at),
+				o.resulted_at = CASE WHEN a.status = 'resulted' THEN SYSTIMESTAMP ELSE o.resulted_at END,
+				o.updated_at = SYSTIMESTAMP
+				WHERE o.status <> 'cancelled'
+		`,
+	}
+	upsertResult = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.lab_results (
+				order_id, visit_id, patient_id, analyte_code, analyte_name, value_type, value_text,
+				numeric_value, unit, ref_low, ref_high, ref_text, flag, comment, entered_by
+			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15)
+			ON CONFLICT (order_id, analyte_code) DO UPDATE SET
+				value_text = EXCLUDED.value_text,
+				numeric_value = EXCLUDED.numeric_value,
+				unit = EXCLUDED.unit,
+				ref_low = EXCLUDED.ref_low,
+				ref_high = EXCLUDED.ref_high,
+				ref_text = EXCLUDED.ref_text,
+				flag = EXCLUDED.flag,
+				comment = EXCLUDED.comment,
+				entered_by = EXCLUDED.entered_by,
+				resulted_at = NOW(),
+				amended = TRUE
+		`,
+		Oracle: `--sql
+			MERGE INTO lab_results r
+			USING (
+				SELECT :1 AS order_id, :2 AS visit_id, :3 AS patient_id, :4 AS analyte_code, :5 AS analyte_name,
+				       :6 AS value_type, :7 AS value_text, :8 AS numeric_value, :9 AS unit, :10 AS ref_low,
+				       :11 AS ref_high, :12 AS ref_text, :13 AS flag, :14 AS "COMMENT", :15 AS entered_by
+				  FROM dual
+			) a
+			   ON (r.order_id = a.order_id AND r.analyte_code = a.analyte_code)
+			 WHEN MATCHED THEN UPDATE SET
+				r.value_text = a.value_text,
+				r.numeric_value = a.numeric_value,
+				r.unit = a.unit,
+				r.ref_low = a.ref_low,
+				r.ref_high = a.ref_high,
+				r.ref_text = a.ref_text,
+				r.flag = a.flag,
+				r."COMMENT" = a."COMMENT",
+				r.entered_by = a.entered_by,
+				r.resulted_at = SYSTIMESTAMP,
+				r.amended = TRUE
+			 WHEN NOT MATCHED THEN INSERT (
+				order_id, visit_id, patient_id, analyte_code, analyte_name, value_type, value_text,
+				numeric_value, unit, ref_low, ref_high, ref_text, flag, "COMMENT", entered_by
+			) VALUES (
+				a.order_id, a.visit_id, a.patient_id, a.analyte_code, a.analyte_name, a.value_type, a.value_text,
+				a.numeric_value, a.unit, a.ref_low, a.ref_high, a.ref_text, a.flag, a."COMMENT", a.entered_by
+			)
+		`,
+	}
+	selectResults = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				r.id,
+				r.order_id,
+				r.visit_id,
+				r.patient_id,
+				r.analyte_code,
+				r.analyte_name,
+				r.value_type,
+				r.value_text AS value,
+				COALESCE(r.numeric_value, 0) AS numeric_value,
+				COALESCE(r.unit, '') AS unit,
+				COALESCE(r.ref_low, 0) AS ref_low,
+				COALESCE(r.ref_high, 0) AS ref_high,
+				COALESCE(r.ref_text, '') AS ref_text,
+				COALESCE(r.flag, '') AS flag,
+				COALESCE(r.comment, '') AS comment,
+				r.amended,
+				COALESCE(r.entered_by, '') AS entered_by,
+				r.resulted_at
+			  FROM public.lab_results r
+		`,
+		Oracle: `--sql
+			SELECT
+				r.id,
+				r.order_id,
+				r.visit_id,
+				r.patient_id,
+				r.analyte_code,
+				r.analyte_name,
+				r.value_type,
+				r.value_text AS value,
+				COALESCE(r.numeric_value, 0) AS numeric_value,
+				r.unit,
+				COALESCE(r.ref_low, 0) AS ref_low,
+				COALESCE(r.ref_high, 0) AS ref_high,
+				r.ref_text,
+				r.flag,
+				r."COMMENT" AS "COMMENT",
+				r.amended,
+				r.entered_by,
+				r.resulted_at
+			  FROM lab_results r
+		`,
+	}
+	resultsByOrder = selectResults.Append(`
+		 WHERE r.order_id = :1
+		 ORDER BY r.resulted_at, r.analyte_code
+	`)
+	resultsByVisit = selectResults.Append(`
+		 WHERE r.visit_id = :1
+		 ORDER BY r.resulted_at, r.analyte_code
+	`)
+	resultsByPatient = dialect.Query{
+		Postgres: selectResults.Postgres + `
+			  JOIN public.lab_orders o ON o.id = r.order_id
+			 WHERE r.patient_id = :1
+			   AND r.resulted_at >= :2
+			   AND r.resulted_at < :3
+			   AND (:4 = '' OR r.analyte_code = :4)
+			   AND o.status <> 'cancelled'
+			 ORDER BY r.resulted_at, r.analyte_code
+		`,
+		Oracle: `
+			WITH a AS (SELECT :1 AS patient_id, :2 AS date_from, :3 AS date_to, :4 AS analyte_code FROM dual)` +
+			selectResults.Oracle + `
+			  JOIN lab_orders o ON o.id = r.order_id
+			  JOIN a ON a.patient_id = r.patient_id
+			 WHERE r.resulted_at >= a.date_from
+			   AND r.resulted_at < a.date_to
+			   AND (a.analyte_code IS NULL OR r.analyte_code = a.analyte_code)
+			   AND o.status <> 'cancelled'
+			 ORDER BY r.resulted_at, r.analyte_code
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"cancelOrder":       cancelOrder,
+	"insertOrder":       insertOrder,
+	"orderByID":         orderByID,
+	"orderPatient":      orderPatient,
+	"ordersByVisit":     ordersByVisit,
+	"resultsByOrder":    resultsByOrder,
+	"resultsByPatient":  resultsByPatient,
+	"resultsByVisit":    resultsByVisit,
+	"updateOrderStatus": updateOrderStatus,
+	"upsertResult":      upsertResult,
+	"visitPatient":      visitPatient,
+}
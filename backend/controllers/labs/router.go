@@ -0,0 +1,51 @@
+package labs
+
+import (
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/labs"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// LoadCatalog loads the lab catalog configured in params, the default panels are used when none is configured
+func LoadCatalog(wsParams *libParams.ApplicationParams[models.ApplicationParams]) *labs.Catalog {
+	if len(wsParams.Specific.LabCatalogPath) == 0 {
+		catalog, err := labs.New(nil)
+		if err != nil {
+			log.Fatalln("error loading default lab catalog", err)
+		}
+		return catalog
+	}
+	catalog, err := labs.Load(wsParams.Specific.LabCatalogPath)
+	if err != nil {
+		log.Fatalln("error loading lab catalog", err)
+	}
+	return catalog
+}
+
+func AddLabsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	simulation bool,
+) {
+	env := &labsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Catalog:   LoadCatalog(wsParams),
+		Location:  appointments.LoadLocation(wsParams),
+	}
+	rg.GET("/labs/panels", libGin.Gin(env.labPanelsHandler(simulation)))
+	rg.POST("/visits/:id/lab-orders", libGin.Gin(env.labOrderPostHandler(simulation)))
+	rg.GET("/visits/:id/lab-orders", libGin.Gin(env.labOrdersVisitHandler(simulation)))
+	rg.GET("/lab-orders/:id", libGin.Gin(env.labOrderGetHandler(simulation)))
+	rg.POST("/lab-orders/:id/cancel", libGin.Gin(env.labOrderCancelHandler(simulation)))
+	rg.POST("/lab-orders/:id/results", libGin.Gin(env.labResultsPostHandler(simulation)))
+	rg.GET("/patients/:id/labs", libGin.Gin(env.labCumulativeHandler(simulation)))
+}
@@ -0,0 +1,279 @@
+package locale
+
+import (
+	"bytes"
+	"encoding/json"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/validation"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+// contextKey holds the Localizer of a request in the gin context
+const contextKey = "healthcare.locale"
+
+// ErrorData is one error of a response, Detail is the untranslated cause when it adds to Description
+// and Field is the request field of validation errors
+type ErrorData struct {
+	Code        string `json:"code"`
+	Field       string `json:"field,omitempty"`
+	Description any    `json:"description"`
+	Detail      any    `json:"detail,omitempty"`
+}
+
+// Response is the envelope of the responses of the gin handlers, it is the one requestCore handlers
+// respond with: lists are the result as a whole and paged by the paging middleware, or a paging.Page
+// already cut in their query
+type Response struct {
+	Status      int         `json:"status"`
+	Description string      `json:"description"`
+	Result      any         `json:"result,omitempty"`
+	Errors      []ErrorData `json:"errors,omitempty"`
+}
+
+// Localizer describes codes in the language of a request
+type Localizer struct {
+	Catalogue *i18n.Catalogue
+	Language  string
+}
+
+// fallback describes codes of requests that did not pass the middleware with the built-in catalogue
+var fallback = func() Localizer {
+	catalogue, err := i18n.New(i18n.File{}, i18n.Persian)
+	if err != nil {
+		log.Fatalln("error loading the built-in catalogue", err)
+	}
+	return Localizer{Catalogue: catalogue, Language: catalogue.Fallback()}
+}()
+
+// LoadCatalogue loads the catalogue configured in params
+func LoadCatalogue(wsParams *libParams.ApplicationParams[models.ApplicationParams]) *i18n.Catalogue {
+	params := wsParams.Specific.Locale
+	catalogue, err := i18n.Load(params.CatalogPath, params.DefaultLanguage)
+	if err != nil {
+		log.Fatalln("error loading locale catalogue", err)
+	}
+	return catalogue
+}
+
+// From returns the Localizer of the request
+func From(c *gin.Context) Localizer {
+	if value, ok := c.Get(contextKey); ok {
+		if l, ok := value.(Localizer); ok {
+			return l
+		}
+	}
+	return fallback
+}
+
+// Error describes code in the language of the request
+func (l Localizer) Error(code string) (string, bool) {
+	return l.Catalogue.Error(code, l.Language)
+}
+
+// Message describes code in the language of the request
+func (l Localizer) Message(code string) string {
+	text, ok := l.Catalogue.Message(code, l.Language)
+	if !ok {
+		return code
+	}
+	return text
+}
+
+// Message describes a message code in the language of the request
+func Message(c *gin.Context, code string) string {
+	return From(c).Message(code)
+}
+
+// Respond writes a successful response of result, code is the message describing it
+func Respond(c *gin.Context, status int, code string, result any) {
+	c.JSON(status, Response{Status: status, Description: Message(c, code), Result: result})
+}
+
+// Error writes an error response of code, detail is the cause in English and is kept next to the
+// description when they differ
+func Error(c *gin.Context, status int, code, detail string) {
+	ErrorWithData(c, status, code, detail, nil)
+}
+
+// ErrorWithData writes an error response of code along with data as its result
+func ErrorWithData(c *gin.Context, status int, code, detail string, data any) {
+	text, ok := From(c).Error(code)
+	if !ok {
+		text = detail
+	}
+	e := ErrorData{Code: code, Description: text}
+	if detail != "" && detail != text {
+		e.Detail = detail
+	}
+	c.JSON(status, Response{Status: status, Description: text, Result: data, Errors: []ErrorData{e}})
+}
+
+// Fields writes a bad request response with an error per field that broke a rule
+func Fields(c *gin.Context, fields []validation.FieldError) {
+	l := From(c)
+	text, _ := l.Error(i18n.InvalidRequest)
+	errs := make([]ErrorData, len(fields))
+	for i, f := range fields {
+		errs[i] = ErrorData{Code: f.Code, Field: f.Field, Description: f.Description}
+		if description, ok := l.Error(f.Code); ok {
+			errs[i].Description, errs[i].Detail = description, f.Description
+		}
+	}
+	c.JSON(http.StatusBadRequest, Response{Status: http.StatusBadRequest, Description: text, Errors: errs})
+}
+
+// Validate checks the validate tags of v, skipping the fields in skip, and writes the response of the
+// fields that broke a rule, it reports whether v is valid
+func Validate(c *gin.Context, v any, skip ...string) bool {
+	fields, err := validation.Check(v, skip...)
+	if err != nil {
+		Error(c, http.StatusInternalServerError, "INVALID_VALIDATION", err.Error())
+		return false
+	}
+	if fields != nil {
+		Fields(c, fields)
+		return false
+	}
+	return true
+}
+
+// Middleware picks the language of each request from Accept-Language and describes the codes of
+// the JSON error responses of every handler in it, the requestCore ones included
+func Middleware(catalogue *i18n.Catalogue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l := Localizer{Catalogue: catalogue, Language: catalogue.Language(c.GetHeader("Accept-Language"))}
+		c.Set(contextKey, l)
+		c.Header("Content-Language", l.Language)
+		w := &writer{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		w.flush(l)
+	}
+}
+
+// writer holds back the body of error responses so their codes can be described, other responses
+// such as event streams and downloads pass straight through
+type writer struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *writer) held() bool {
+	return w.status >= http.StatusBadRequest
+}
+
+func (w *writer) WriteHeader(code int) {
+	w.status = code
+	if !w.held() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *writer) WriteHeaderNow() {
+	if !w.held() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	if w.held() {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	if w.held() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *writer) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *writer) Size() int {
+	if w.held() {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *writer) Written() bool {
+	return w.held() || w.ResponseWriter.Written()
+}
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush writes the held error response with its codes described
+func (w *writer) flush(l Localizer) {
+	if !w.held() {
+		return
+	}
+	body := w.body.Bytes()
+	if strings.Contains(w.Header().Get("Content-Type"), "json") {
+		body = localize(body, l)
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// localize replaces the description of every object with a known code, the replaced description is
+// kept as its detail
+func localize(body []byte, l Localizer) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	if !describe(value, l) {
+		return body
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return body
+	}
+	return out.Bytes()
+}
+
+func describe(value any, l Localizer) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		if code, ok := v["code"].(string); ok {
+			if text, ok := l.Error(code); ok && v["description"] != text {
+				if d := v["description"]; d != nil && d != "" && v["detail"] == nil {
+					v["detail"] = d
+				}
+				v["description"] = text
+				changed = true
+			}
+		}
+		for _, item := range v {
+			changed = describe(item, l) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = describe(item, l) || changed
+		}
+	}
+	return changed
+}
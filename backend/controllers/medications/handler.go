@@ -2,230 +2,330 @@ package medications
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
-	"charity-log/backend/models"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/validation"
 
-	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
 	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
 )
 
-// MedicationPostHandler handles POST requests for creating new medications
-func MedicationPostHandler(c *gin.Context) {
-	var medication models.MedicationRequest
-	if err := c.ShouldBindJSON(&medication); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// InteractionChecker lists interactions between a proposed drug and the active medications
+// of the patient the visit belongs to
+type InteractionChecker interface {
+	CheckForVisit(visitID, medicationName string) ([]models.DrugInteraction, error)
+}
 
-	// Validate required fields
-	if medication.VisitID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "visit_id is required"})
-		return
-	}
-	if medication.MedicationName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "medication_name is required"})
-		return
-	}
-	if medication.Dosage == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dosage is required"})
-		return
-	}
+type medicationsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Checker   InteractionChecker
+}
+
+type medicationsHandler struct {
+	Name    string
+	Checker InteractionChecker
+}
 
-	// Set default values
-	if medication.StartDate.IsZero() {
-		medication.StartDate = time.Now()
+// returns handler title
+func (h medicationsHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name == "medications-delete" {
+		body = libRequest.NoBinding
 	}
-	if !medication.IsActive {
-		medication.IsActive = true
+	return handlers.HandlerParameters{
+		Title:          "medications",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/medications",
 	}
+}
 
-	// TODO: Implement database insertion
-	// For now, return a mock response
-	response := models.MedicationResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Medication created successfully",
-		},
+// runs after validating request
+func (h medicationsHandler) Initializer(req handlers.HandlerRequest[models.MedicationRequest, *models.MedicationResponse]) error {
+	switch h.Name {
+	case "medications-post":
+		// Set default values
+		if req.Request.StartDate.IsZero() {
+			req.Request.StartDate = time.Now()
+		}
+		if err := validation.Error(req.Request); err != nil {
+			return err
+		}
+		if !req.Request.IsActive {
+			req.Request.IsActive = true
+		}
+	case "medications-put", "medications-delete":
+		req.Request.ID = req.W.Parser.GetUrlParam("id")
+		if req.Request.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidMedicationID, "medication id is required")
+		}
+		if h.Name == "medications-put" {
+			// the visit of a medication does not change
+			return validation.Error(req.Request, "visit_id")
+		}
 	}
-
-	c.JSON(http.StatusCreated, response)
+	return nil
 }
 
-// MedicationPutHandler handles PUT requests for updating medications
-func MedicationPutHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
-		return
-	}
+// Handler lists the interactions of a new medication with the patient's active medications
+// along with the result
+func (h medicationsHandler) Handler(req handlers.HandlerRequest[models.MedicationRequest, *models.MedicationResponse]) (*models.MedicationResponse, error) {
+	switch h.Name {
+	case "medications-post":
+		var interactions []models.DrugInteraction
+		if h.Checker != nil {
+			var err error
+			interactions, err = h.Checker.CheckForVisit(req.Request.VisitID, req.Request.MedicationName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// TODO: Implement database insertion
+		// For now, return a mock response
+		req.Response = &models.MedicationResponse{
+			Result: libQuery.DmlResult{
+				Success: true,
+				Message: "Medication created successfully",
+			},
+			Interactions: interactions,
+		}
+		return req.Response, nil
 
-	var medication models.MedicationRequest
-	if err := c.ShouldBindJSON(&medication); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	case "medications-put":
+		// TODO: Implement database update
+		// For now, return a mock response
+		req.Response = &models.MedicationResponse{
+			Result: libQuery.DmlResult{
+				Success: true,
+				Message: "Medication updated successfully",
+			},
+		}
+		return req.Response, nil
 
-	// TODO: Implement database update
-	// For now, return a mock response
-	response := models.MedicationResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Medication updated successfully",
-		},
+	case "medications-delete":
+		// TODO: Implement database deletion
+		// For now, return a mock response
+		req.Response = &models.MedicationResponse{
+			Result: libQuery.DmlResult{
+				Success: true,
+				Message: "Medication deleted successfully",
+			},
+		}
+		return req.Response, nil
 	}
-
-	c.JSON(http.StatusOK, response)
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
 }
 
-// MedicationDeleteHandler handles DELETE requests for medications
-func MedicationDeleteHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
-		return
-	}
+// Simulation returns a simulated response
+func (h medicationsHandler) Simulation(req handlers.HandlerRequest[models.MedicationRequest, *models.MedicationResponse]) (*models.MedicationResponse, error) {
+	return req.Response, nil
+}
 
-	// TODO: Implement database deletion
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Medication deleted successfully",
-		"id":      idStr,
-	}
+// runs after sending back response
+func (h medicationsHandler) Finalizer(req handlers.HandlerRequest[models.MedicationRequest, *models.MedicationResponse]) {
+}
 
-	c.JSON(http.StatusOK, response)
+type medicationsQueryHandler struct {
+	Name string
 }
 
-// MedicationGetHandler handles GET requests for a specific medication
-func MedicationGetHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
-		return
+// returns handler title
+func (h medicationsQueryHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "medications",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/medications",
 	}
+}
 
-	// TODO: Implement database retrieval
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Medication retrieved successfully",
-		"data": gin.H{
-			"id":                idStr,
-			"visit_id":          "1",
-			"medication_name":   "Paracetamol",
-			"dosage":            "500mg",
-			"frequency":         "3 times daily",
-			"duration":          "7 days",
-			"instructions":      "Take with food",
-			"start_date":        time.Now(),
-			"end_date":          time.Now().Add(7 * 24 * time.Hour),
-			"is_active":         true,
-			"side_effects":      "May cause drowsiness",
-			"contraindications": "Not for children under 12",
-			"created_at":        time.Now().Add(-24 * time.Hour),
-			"updated_at":        time.Now(),
-		},
+// runs after validating request
+func (h medicationsQueryHandler) Initializer(req handlers.HandlerRequest[models.MedicationFilter, *[]models.MedicationRow]) error {
+	if h.Name == "medications-get" {
+		req.Request.ID = req.W.Parser.GetUrlParam("id")
+		if req.Request.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidMedicationID, "medication id is required")
+		}
 	}
-
-	c.JSON(http.StatusOK, response)
+	return validation.Error(req.Request)
 }
 
-// MedicationGetAllHandler handles GET requests for all medications
-func MedicationGetAllHandler(c *gin.Context) {
-	// Get query parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-	visitID := c.Query("visit_id")
-	isActive := c.Query("is_active")
-
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	// TODO: Implement database retrieval with filters
-	// For now, return mock data
-	mockMedications := []gin.H{
+// mockMedications are returned until medications are read from the database
+func mockMedications() []models.MedicationRow {
+	return []models.MedicationRow{
 		{
-			"id":                "1",
-			"visit_id":          "1",
-			"medication_name":   "Paracetamol",
-			"dosage":            "500mg",
-			"frequency":         "3 times daily",
-			"duration":          "7 days",
-			"instructions":      "Take with food",
-			"start_date":        time.Now(),
-			"end_date":          time.Now().Add(7 * 24 * time.Hour),
-			"is_active":         true,
-			"side_effects":      "May cause drowsiness",
-			"contraindications": "Not for children under 12",
-			"created_at":        time.Now().Add(-24 * time.Hour),
-			"updated_at":        time.Now(),
+			ID:                "1",
+			VisitID:           "1",
+			MedicationName:    "Paracetamol",
+			Dosage:            "500mg",
+			Frequency:         "3 times daily",
+			Duration:          "7 days",
+			Instructions:      "Take with food",
+			StartDate:         time.Now(),
+			EndDate:           time.Now().Add(7 * 24 * time.Hour),
+			IsActive:          true,
+			SideEffects:       "May cause drowsiness",
+			Contraindications: "Not for children under 12",
+			CreatedAt:         time.Now().Add(-24 * time.Hour),
+			UpdatedAt:         time.Now(),
 		},
 		{
-			"id":                "2",
-			"visit_id":          "2",
-			"medication_name":   "Ibuprofen",
-			"dosage":            "400mg",
-			"frequency":         "2 times daily",
-			"duration":          "5 days",
-			"instructions":      "Take after meals",
-			"start_date":        time.Now().Add(-2 * time.Hour),
-			"end_date":          time.Now().Add(5 * 24 * time.Hour),
-			"is_active":         true,
-			"side_effects":      "May cause stomach upset",
-			"contraindications": "Not for patients with ulcers",
-			"created_at":        time.Now().Add(-2 * time.Hour),
-			"updated_at":        time.Now().Add(-1 * time.Hour),
+			ID:                "2",
+			VisitID:           "2",
+			MedicationName:    "Ibuprofen",
+			Dosage:            "400mg",
+			Frequency:         "2 times daily",
+			Duration:          "5 days",
+			Instructions:      "Take after meals",
+			StartDate:         time.Now().Add(-2 * time.Hour),
+			EndDate:           time.Now().Add(5 * 24 * time.Hour),
+			IsActive:          true,
+			SideEffects:       "May cause stomach upset",
+			Contraindications: "Not for patients with ulcers",
+			CreatedAt:         time.Now().Add(-2 * time.Hour),
+			UpdatedAt:         time.Now().Add(-1 * time.Hour),
 		},
 	}
+}
 
-	// Apply basic filtering
-	filteredMedications := mockMedications
-	if visitID != "" {
-		var filtered []gin.H
-		for _, medication := range filteredMedications {
-			if medication["visit_id"] == visitID {
-				filtered = append(filtered, medication)
-			}
-		}
-		filteredMedications = filtered
-	}
+// Handler returns the medication, or the medications matching the filters, the list is paged by the
+// paging middleware
+func (h medicationsQueryHandler) Handler(req handlers.HandlerRequest[models.MedicationFilter, *[]models.MedicationRow]) (*[]models.MedicationRow, error) {
+	switch h.Name {
+	case "medications-get":
+		// TODO: Implement database retrieval
+		// For now, return a mock response
+		medication := mockMedications()[0]
+		medication.ID = req.Request.ID
+		rows := []models.MedicationRow{medication}
+		return &rows, nil
 
-	if isActive != "" {
-		var filtered []gin.H
-		for _, medication := range filteredMedications {
-			if medication["is_active"] == (isActive == "true") {
-				filtered = append(filtered, medication)
+	case "medications-get-all":
+		// TODO: Implement database retrieval with filters
+		// For now, return mock data
+		filter := req.Request
+		rows := []models.MedicationRow{}
+		for _, medication := range mockMedications() {
+			if (filter.VisitID == "" || medication.VisitID == filter.VisitID) &&
+				(filter.IsActive == "" || medication.IsActive == (filter.IsActive == "true")) {
+				rows = append(rows, medication)
 			}
 		}
-		filteredMedications = filtered
+		return &rows, nil
 	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
 
-	// Calculate pagination
-	total := len(filteredMedications)
-	offset := (page - 1) * limit
-	end := offset + limit
-
-	if offset >= total {
-		filteredMedications = []gin.H{}
-	} else if end > total {
-		filteredMedications = filteredMedications[offset:]
-	} else {
-		filteredMedications = filteredMedications[offset:end]
-	}
+// Simulation returns a simulated response
+func (h medicationsQueryHandler) Simulation(req handlers.HandlerRequest[models.MedicationFilter, *[]models.MedicationRow]) (*[]models.MedicationRow, error) {
+	return req.Response, nil
+}
 
-	response := gin.H{
-		"success": true,
-		"message": "Medications retrieved successfully",
-		"data":    filteredMedications,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
-	}
+// runs after sending back response
+func (h medicationsQueryHandler) Finalizer(req handlers.HandlerRequest[models.MedicationFilter, *[]models.MedicationRow]) {
+}
+
+// medicationPostHandler godoc
+// @Summary Create a new medication
+// @Description Prescribe a medication in a visit, interactions with the patient's active medications are listed in the response
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param medication body models.MedicationRequest true "Medication"
+// @Router /medications [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.MedicationResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env medicationsEnv) medicationPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.MedicationRequest, *models.MedicationResponse, medicationsHandler](env.Interface, medicationsHandler{Name: "medications-post", Checker: env.Checker}, simulation)
+}
+
+// medicationPutHandler godoc
+// @Summary Update a medication
+// @Description Update a medication, its visit does not change
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Medication ID"
+// @Param medication body models.MedicationRequest true "Medication"
+// @Router /medications/:id [put]
+// @Security OAuth2Password
+// @Success 200 {object} models.MedicationResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env medicationsEnv) medicationPutHandler(simulation bool) any {
+	return handlers.BaseHandler[models.MedicationRequest, *models.MedicationResponse, medicationsHandler](env.Interface, medicationsHandler{Name: "medications-put"}, simulation)
+}
+
+// medicationDeleteHandler godoc
+// @Summary Delete a medication
+// @Description Delete a medication record
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Medication ID"
+// @Router /medications/:id [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.MedicationResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env medicationsEnv) medicationDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.MedicationRequest, *models.MedicationResponse, medicationsHandler](env.Interface, medicationsHandler{Name: "medications-delete"}, simulation)
+}
+
+// medicationGetHandler godoc
+// @Summary Get a medication by ID
+// @Description Get a single medication record by ID
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Medication ID"
+// @Router /medications/:id [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.MedicationRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env medicationsEnv) medicationGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.MedicationFilter, *[]models.MedicationRow, medicationsQueryHandler](env.Interface, medicationsQueryHandler{Name: "medications-get"}, simulation)
+}
 
-	c.JSON(http.StatusOK, response)
+// medicationGetAllHandler godoc
+// @Summary Get all medications
+// @Description Get the medications matching the filters, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param visit_id query string false "Visit ID"
+// @Param is_active query bool false "Active medications only"
+// @Param _start query int false "First item of the page"
+// @Param _end query int false "Item after the page"
+// @Param _sort query string false "Field to sort by"
+// @Param _order query string false "asc or desc"
+// @Router /medications/all [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.MedicationRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env medicationsEnv) medicationGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[models.MedicationFilter, *[]models.MedicationRow, medicationsQueryHandler](env.Interface, medicationsQueryHandler{Name: "medications-get-all"}, simulation)
 }
@@ -1,17 +1,34 @@
 package medications
 
 import (
+	"healthcare/models"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
 )
 
 // SetupRoutes sets up all medication-related routes
-func SetupRoutes(r *gin.RouterGroup) {
+func SetupRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	r *gin.RouterGroup,
+	checker InteractionChecker,
+	simulation bool,
+) {
+	env := &medicationsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Checker:   checker,
+	}
 	medications := r.Group("/medications")
 	{
-		medications.POST("", MedicationPostHandler)         // Create new medication
-		medications.GET("", MedicationGetAllHandler)        // Get all medications with filters
-		medications.GET("/:id", MedicationGetHandler)       // Get specific medication
-		medications.PUT("/:id", MedicationPutHandler)       // Update medication
-		medications.DELETE("/:id", MedicationDeleteHandler) // Delete medication
+		medications.POST("", libGin.Gin(env.medicationPostHandler(simulation)))         // Create new medication
+		medications.GET("/all", libGin.Gin(env.medicationGetAllHandler(simulation)))    // Get all medications with filters
+		medications.GET("", libGin.Gin(env.medicationGetAllHandler(simulation)))        // Get all medications, kept for older clients
+		medications.GET("/:id", libGin.Gin(env.medicationGetHandler(simulation)))       // Get specific medication
+		medications.PUT("/:id", libGin.Gin(env.medicationPutHandler(simulation)))       // Update medication
+		medications.DELETE("/:id", libGin.Gin(env.medicationDeleteHandler(simulation))) // Delete medication
 	}
 }
@@ -0,0 +1,162 @@
+package notifications
+
+import (
+	"healthcare/models"
+	"healthcare/utils/notify"
+	"net/http"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type notificationsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Scheduler *Scheduler
+}
+
+var statuses = map[string]bool{
+	notify.Pending: true, notify.Sending: true, notify.Sent: true, notify.Failed: true, notify.Cancelled: true,
+}
+
+type notificationsHandler struct {
+	Name        string
+	MaxAttempts int
+}
+
+// returns handler title
+func (h notificationsHandler) Parameters() handlers.HandlerParameters {
+	body, path := libRequest.Query, "/notifications"
+	switch h.Name {
+	case "notifications-attempts":
+		body, path = libRequest.NoBinding, "/notifications/:id/attempts"
+	case "notifications-retry":
+		body, path = libRequest.NoBinding, "/notifications/:id/retry"
+	}
+	return handlers.HandlerParameters{
+		Title:          "notifications",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           path,
+	}
+}
+
+// runs after validating request
+func (h notificationsHandler) Initializer(req handlers.HandlerRequest[models.NotificationRequest, *models.NotificationResponse]) error {
+	if h.Name == "notifications-list" {
+		if req.Request.Status != "" && !statuses[req.Request.Status] {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_STATUS", "unknown notification status %s", req.Request.Status)
+		}
+		return nil
+	}
+	req.Request.NotificationID = req.W.Parser.GetUrlParam("id")
+	if req.Request.NotificationID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_NOTIFICATION_ID", "notification id is required")
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h notificationsHandler) Handler(req handlers.HandlerRequest[models.NotificationRequest, *models.NotificationResponse]) (*models.NotificationResponse, error) {
+	req.Response = &models.NotificationResponse{Result: libQuery.DmlResult{Success: true}}
+	switch h.Name {
+	case "notifications-list":
+		rows, err := libQuery.GetQuery[models.NotificationRow](notificationList.SQL(), req.Core.GetDB(),
+			req.Request.Status, req.Request.Kind, req.Request.PatientID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_NOTIFICATIONS", err.Error())
+		}
+		req.Response.Notifications = rows
+
+	case "notifications-attempts":
+		rows, err := libQuery.GetQuery[models.NotificationAttemptRow](notificationAttempts.SQL(), req.Core.GetDB(), req.Request.NotificationID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_ATTEMPTS", err.Error())
+		}
+		req.Response.Attempts = rows
+
+	case "notifications-retry":
+		result, err := req.Core.GetDB().InsertRow(retryNotification.SQL(), req.Request.NotificationID, h.MaxAttempts)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, "NOTIFICATION_NOT_FAILED", "notification %s is not a failed notification", req.Request.NotificationID)
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h notificationsHandler) Simulation(req handlers.HandlerRequest[models.NotificationRequest, *models.NotificationResponse]) (*models.NotificationResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h notificationsHandler) Finalizer(req handlers.HandlerRequest[models.NotificationRequest, *models.NotificationResponse]) {
+}
+
+// notificationsListHandler godoc
+// @Summary Get notifications
+// @Description Get the latest reminders with their delivery status and attempts
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param status query string false "Status" Enums(pending, sending, sent, failed, cancelled)
+// @Param kind query string false "Kind" Enums(appointment, follow_up, therapy)
+// @Param patient_id query string false "Patient ID"
+// @Router /notifications [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.NotificationResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env notificationsEnv) notificationsListHandler(simulation bool) any {
+	return handlers.BaseHandler[models.NotificationRequest, *models.NotificationResponse, notificationsHandler](env.Interface, notificationsHandler{Name: "notifications-list"}, simulation)
+}
+
+// notificationAttemptsHandler godoc
+// @Summary Get delivery attempts of a notification
+// @Description Get every delivery attempt of a notification with its error
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Notification ID"
+// @Router /notifications/:id/attempts [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.NotificationResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env notificationsEnv) notificationAttemptsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.NotificationRequest, *models.NotificationResponse, notificationsHandler](env.Interface, notificationsHandler{Name: "notifications-attempts"}, simulation)
+}
+
+// notificationRetryHandler godoc
+// @Summary Retry a failed notification
+// @Description Give a failed notification another round of attempts, e.g. after fixing the channel configuration
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Notification ID"
+// @Router /notifications/:id/retry [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.NotificationResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env notificationsEnv) notificationRetryHandler(simulation bool) any {
+	return handlers.BaseHandler[models.NotificationRequest, *models.NotificationResponse, notificationsHandler](env.Interface,
+		notificationsHandler{Name: "notifications-retry", MaxAttempts: env.Scheduler.MaxAttempts}, simulation)
+}
@@ -0,0 +1,327 @@
+package notifications
+
+import "healthcare/utils/dialect"
+
+var (
+	// columns of the patient and doctor of a reminder, the source table is aliased v
+	reminderColumns = dialect.Query{
+		Postgres: `
+			v.patient_id,
+			COALESCE(p.full_name, '') AS patient_name,
+			COALESCE(p.phone, '') AS phone,
+			COALESCE(p.email, '') AS email,
+			COALESCE(p.preferred_language, '') AS language,
+			COALESCE(pr.full_name, '') AS doctor_name
+		`,
+		Oracle: `
+			v.patient_id,
+			p.full_name AS patient_name,
+			p.phone,
+			p.email,
+			p.preferred_language AS language,
+			pr.full_name AS doctor_name
+		`,
+	}
+	upcomingAppointments = dialect.Query{
+		Postgres: `--sql
+			SELECT 'appointment' AS kind, v.id AS ref_id, v.visit_date AS due_at,` + reminderColumns.Postgres + `, '' AS therapy
+			  FROM public.visits v
+			  JOIN public.patients p ON p.id = v.patient_id
+			  LEFT JOIN public.doctors d ON d.id = v.doctor_id
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE v.status = 'scheduled'
+			   AND v.visit_date > :1
+			   AND v.visit_date <= :2
+		`,
+		Oracle: `--sql
+			SELECT 'appointment' AS kind, v.id AS ref_id, v.visit_date AS due_at,` + reminderColumns.Oracle + `, CAST(NULL AS VARCHAR2(64)) AS therapy
+			  FROM visits v
+			  JOIN patients p ON p.id = v.patient_id
+			  LEFT JOIN doctors d ON d.id = v.doctor_id
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE v.status = 'scheduled'
+			   AND v.visit_date > :1
+			   AND v.visit_date <= :2
+		`,
+	}
+	// follow-ups are due at the start of their day in the clinic time zone
+	upcomingFollowUps = dialect.Query{
+		Postgres: `--sql
+			SELECT 'follow_up' AS kind, v.id AS ref_id, (v.follow_up_date::TIMESTAMP AT TIME ZONE :2) AS due_at,` + reminderColumns.Postgres + `, '' AS therapy
+			  FROM public.visits v
+			  JOIN public.patients p ON p.id = v.patient_id
+			  LEFT JOIN public.doctors d ON d.id = v.doctor_id
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE v.follow_up_date = :1::DATE
+			   AND v.status NOT IN ('cancelled', 'no_show')
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT TO_DATE(:1, 'YYYY-MM-DD') AS on_day, :2 AS tz FROM dual)
+			SELECT 'follow_up' AS kind, v.id AS ref_id, FROM_TZ(CAST(v.follow_up_date AS TIMESTAMP), a.tz) AS due_at,` + reminderColumns.Oracle + `, CAST(NULL AS VARCHAR2(64)) AS therapy
+			  FROM a
+			  JOIN visits v ON v.follow_up_date = a.on_day
+			  JOIN patients p ON p.id = v.patient_id
+			  LEFT JOIN doctors d ON d.id = v.doctor_id
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE v.status NOT IN ('cancelled', 'no_show')
+		`,
+	}
+	// upcomingTherapies lists the active schedules running on the day, the ones with a session on it are
+	// picked by expanding their recurrence
+	upcomingTherapies = dialect.Query{
+		Postgres: `--sql
+			SELECT 'therapy' AS kind, v.id AS ref_id, (:1::DATE::TIMESTAMP AT TIME ZONE :2) AS due_at,` + reminderColumns.Postgres + `, v.therapy_type AS therapy
+			  FROM public.therapy_schedules v
+			  JOIN public.patients p ON p.id = v.patient_id
+			  LEFT JOIN public.doctors d ON d.id = v.doctor_id
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE v.status = 'active'
+			   AND v.start_date <= :1::DATE
+			   AND (v.end_date IS NULL OR v.end_date >= :1::DATE)
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT TO_DATE(:1, 'YYYY-MM-DD') AS on_day, :2 AS tz FROM dual)
+			SELECT 'therapy' AS kind, v.id AS ref_id, FROM_TZ(CAST(a.on_day AS TIMESTAMP), a.tz) AS due_at,` + reminderColumns.Oracle + `, v.therapy_type AS therapy
+			  FROM a
+			  JOIN therapy_schedules v ON v.start_date <= a.on_day AND (v.end_date IS NULL OR v.end_date >= a.on_day)
+			  JOIN patients p ON p.id = v.patient_id
+			  LEFT JOIN doctors d ON d.id = v.doctor_id
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE v.status = 'active'
+		`,
+	}
+	// a reminder is created once per channel, running the scheduler again is harmless
+	insertNotification = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.notifications
+				(kind, ref_id, due_at, patient_id, channel, recipient, language, subject, body, max_attempts)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, NULLIF(:8, ''), :9, :10)
+			ON CONFLICT (kind, ref_id, due_at, channel) DO NOTHING
+		`,
+		Oracle: `--sql
+			MERGE INTO notifications n
+			USING (
+				SELECT :1 AS kind, :2 AS ref_id, :3 AS due_at, :4 AS patient_id, :5 AS channel, :6 AS recipient,
+				       :7 AS language, :8 AS subject, :9 AS body, :10 AS max_attempts
+				  FROM dual
+			) a
+			   ON (n.kind = a.kind AND n.ref_id = a.ref_id AND n.due_at = a.due_at AND n.channel = a.channel)
+			 WHEN NOT MATCHED THEN INSERT
+				(kind, ref_id, due_at, patient_id, channel, recipient, language, subject, body, max_attempts)
+			VALUES (a.kind, a.ref_id, a.due_at, a.patient_id, a.channel, a.recipient, a.language, a.subject, a.body, a.max_attempts)
+		`,
+	}
+	// claimDue hands due notifications to one dispatcher, a claim older than the stale interval
+	// belongs to a dispatcher that stopped and is taken over. Oracle cannot skip locked rows of a
+	// limited subquery, the claim is checked again so a dispatcher that waited on a row claimed
+	// meanwhile leaves it
+	claimDue = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.notifications SET status = 'sending', claim_id = :1, claimed_at = NOW(), updated_at = NOW()
+			 WHERE id IN (
+				SELECT n.id
+				  FROM public.notifications n
+				 WHERE (n.status = 'pending' AND n.next_attempt_at <= NOW())
+				    OR (n.status = 'sending' AND n.claimed_at < NOW() - INTERVAL '15 minutes')
+				 ORDER BY n.next_attempt_at
+				 LIMIT :2
+				   FOR UPDATE SKIP LOCKED
+			 )
+		`,
+		Oracle: `--sql
+			UPDATE notifications SET status = 'sending', claim_id = :1, claimed_at = SYSTIMESTAMP, updated_at = SYSTIMESTAMP
+			 WHERE id IN (
+				SELECT n.id
+				  FROM notifications n
+				 WHERE (n.status = 'pending' AND n.next_attempt_at <= SYSTIMESTAMP)
+				    OR (n.status = 'sending' AND n.claimed_at < SYSTIMESTAMP - INTERVAL '15' MINUTE)
+				 ORDER BY n.next_attempt_at
+				 FETCH FIRST :2 ROWS ONLY
+			 )
+			   AND ((status = 'pending' AND next_attempt_at <= SYSTIMESTAMP)
+			    OR (status = 'sending' AND claimed_at < SYSTIMESTAMP - INTERVAL '15' MINUTE))
+		`,
+	}
+	notificationColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				n.id,
+				n.kind,
+				n.ref_id,
+				n.due_at,
+				n.patient_id,
+				n.channel,
+				n.recipient,
+				n.language,
+				COALESCE(n.subject, '') AS subject,
+				n.body,
+				n.status,
+				n.attempts,
+				n.max_attempts,
+				n.next_attempt_at,
+				COALESCE(n.last_error, '') AS last_error,
+				COALESCE(n.sent_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS sent_at,
+				n.created_at,
+				CASE n.kind
+					WHEN 'appointment' THEN NOT EXISTS (
+						SELECT 1 FROM public.visits v WHERE v.id = n.ref_id AND v.status = 'scheduled' AND v.visit_date = n.due_at)
+					WHEN 'therapy' THEN NOT EXISTS (
+						SELECT 1 FROM public.therapy_schedules t WHERE t.id = n.ref_id AND COALESCE(t.is_active, TRUE))
+					ELSE FALSE
+				END AS obsolete
+			  FROM public.notifications n
+		`,
+		Oracle: `--sql
+			SELECT
+				n.id,
+				n.kind,
+				n.ref_id,
+				n.due_at,
+				n.patient_id,
+				n.channel,
+				n.recipient,
+				n.language,
+				n.subject,
+				n.body,
+				n.status,
+				n.attempts,
+				n.max_attempts,
+				n.next_attempt_at,
+				n.last_error,
+				COALESCE(n.sent_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS sent_at,
+				n.created_at,
+				CASE
+					WHEN n.kind = 'appointment' AND NOT EXISTS (
+						SELECT 1 FROM visits v WHERE v.id = n.ref_id AND v.status = 'scheduled' AND v.visit_date = n.due_at) THEN 1
+					WHEN n.kind = 'therapy' AND NOT EXISTS (
+						SELECT 1 FROM therapy_schedules t WHERE t.id = n.ref_id AND COALESCE(t.is_active, TRUE)) THEN 1
+					ELSE 0
+				END AS obsolete
+			  FROM notifications n
+		`,
+	}
+	claimedNotifications = notificationColumns.Append(`
+		 WHERE n.claim_id = :1 AND n.status = 'sending'
+	`)
+	notificationList = dialect.Query{
+		Postgres: notificationColumns.Postgres + `
+			 WHERE (:1 = '' OR n.status = :1)
+			   AND (:2 = '' OR n.kind = :2)
+			   AND (:3 = '' OR n.patient_id::TEXT = :3)
+			 ORDER BY n.created_at DESC
+			 LIMIT 200
+		`,
+		Oracle: `
+			WITH a AS (SELECT :1 AS status, :2 AS kind, :3 AS patient_id FROM dual)` +
+			notificationColumns.Oracle + `
+			 CROSS JOIN a
+			 WHERE (a.status IS NULL OR n.status = a.status)
+			   AND (a.kind IS NULL OR n.kind = a.kind)
+			   AND (a.patient_id IS NULL OR n.patient_id = a.patient_id)
+			 ORDER BY n.created_at DESC
+			 FETCH FIRST 200 ROWS ONLY
+		`,
+	}
+	notificationAttempts = dialect.Query{
+		Postgres: `--sql
+			SELECT a.id, a.notification_id, a.attempt, a.channel, a.succeeded, COALESCE(a.error, '') AS error, a.attempted_at
+			  FROM public.notification_attempts a
+			 WHERE a.notification_id = :1
+			 ORDER BY a.attempt
+		`,
+		Oracle: `--sql
+			SELECT a.id, a.notification_id, a.attempt, a.channel, a.succeeded, a.error, a.attempted_at
+			  FROM notification_attempts a
+			 WHERE a.notification_id = :1
+			 ORDER BY a.attempt
+		`,
+	}
+	insertAttempt = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.notification_attempts (notification_id, attempt, channel, succeeded, error)
+			VALUES (:1, :2, :3, :4, NULLIF(:5, ''))
+		`,
+		Oracle: `--sql
+			INSERT INTO notification_attempts (notification_id, attempt, channel, succeeded, error)
+			VALUES (:1, :2, :3, :4, :5)
+		`,
+	}
+	// finishAttempt only applies while the dispatcher still holds the claim
+	finishAttempt = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.notifications SET
+				status = :3,
+				attempts = attempts + 1,
+				last_error = NULLIF(:4, ''),
+				next_attempt_at = :5,
+				sent_at = CASE WHEN :3 = 'sent' THEN NOW() ELSE sent_at END,
+				claim_id = NULL,
+				claimed_at = NULL,
+				updated_at = NOW()
+			 WHERE id = :1 AND claim_id = :2
+		`,
+		Oracle: `--sql
+			MERGE INTO notifications n
+			USING (SELECT :1 AS id, :2 AS claim_id, :3 AS status, :4 AS last_error, :5 AS next_attempt_at FROM dual) a
+			   ON (n.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				n.status = a.status,
+				n.attempts = n.attempts + 1,
+				n.last_error = a.last_error,
+				n.next_attempt_at = a.next_attempt_at,
+				n.sent_at = CASE WHEN a.status = 'sent' THEN SYSTIMESTAMP ELSE n.sent_at END,
+				n.claim_id = NULL,
+				n.claimed_at = NULL,
+				n.updated_at = SYSTIMESTAMP
+				WHERE n.claim_id = a.claim_id
+		`,
+	}
+	cancelNotification = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.notifications SET status = 'cancelled', claim_id = NULL, claimed_at = NULL, updated_at = NOW()
+			 WHERE id = :1 AND claim_id = :2
+		`,
+		Oracle: `--sql
+			UPDATE notifications SET status = 'cancelled', claim_id = NULL, claimed_at = NULL, updated_at = SYSTIMESTAMP
+			 WHERE id = :1 AND claim_id = :2
+		`,
+	}
+	retryNotification = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.notifications SET
+				status = 'pending',
+				max_attempts = attempts + :2,
+				next_attempt_at = NOW(),
+				updated_at = NOW()
+			 WHERE id = :1 AND status = 'failed'
+		`,
+		Oracle: `--sql
+			MERGE INTO notifications n
+			USING (SELECT :1 AS id, :2 AS retries FROM dual) a
+			   ON (n.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				n.status = 'pending',
+				n.max_attempts = n.attempts + a.retries,
+				n.next_attempt_at = SYSTIMESTAMP,
+				n.updated_at = SYSTIMESTAMP
+				WHERE n.status = 'failed'
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"cancelNotification":   cancelNotification,
+	"claimDue":             claimDue,
+	"claimedNotifications": claimedNotifications,
+	"finishAttempt":        finishAttempt,
+	"insertAttempt":        insertAttempt,
+	"insertNotification":   insertNotification,
+	"notificationAttempts": notificationAttempts,
+	"notificationList":     notificationList,
+	"retryNotification":    retryNotification,
+	"upcomingAppointments": upcomingAppointments,
+	"upcomingFollowUps":    upcomingFollowUps,
+	"upcomingTherapies":    upcomingTherapies,
+}
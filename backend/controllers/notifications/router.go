@@ -0,0 +1,28 @@
+package notifications
+
+import (
+	"healthcare/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func AddNotificationsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	scheduler *Scheduler,
+	simulation bool,
+) {
+	env := &notificationsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Scheduler: scheduler,
+	}
+	rg.GET("/notifications", libGin.Gin(env.notificationsListHandler(simulation)))
+	rg.GET("/notifications/:id/attempts", libGin.Gin(env.notificationAttemptsHandler(simulation)))
+	rg.POST("/notifications/:id/retry", libGin.Gin(env.notificationRetryHandler(simulation)))
+}
@@ -0,0 +1,246 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/notify"
+	"healthcare/utils/storage"
+	"log"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Defaults of the scheduler
+const (
+	DefaultInterval  = 5 * time.Minute
+	DefaultLead      = 24 * time.Hour
+	DefaultDaysAhead = 1
+	DefaultBatchSize = 50
+)
+
+// TherapySessions finds the therapy schedules with a session on a day
+type TherapySessions interface {
+	SessionsOn(day time.Time) (map[string]bool, error)
+}
+
+// Scheduler creates reminders of upcoming appointments, follow-ups and therapy sessions and
+// delivers them through the configured channels, retrying failed deliveries with back-off
+type Scheduler struct {
+	Core        requestCore.RequestCoreInterface
+	Channels    map[string]notify.Channel
+	Templates   *notify.Templates
+	Location    *time.Location
+	Language    string
+	Clinic      string
+	Interval    time.Duration
+	Lead        time.Duration
+	DaysAhead   int
+	MaxAttempts int
+	BatchSize   int
+	// Therapies picks the therapy schedules with a session on a day, without it no therapy reminders are sent
+	Therapies TherapySessions
+}
+
+// LoadScheduler creates the scheduler configured in params
+func LoadScheduler(core requestCore.RequestCoreInterface, wsParams *libParams.ApplicationParams[models.ApplicationParams]) *Scheduler {
+	params := wsParams.Specific.Notifications
+	templates, err := notify.LoadTemplates(params.Templates, params.DefaultLanguage)
+	if err != nil {
+		log.Fatalln("error loading notification templates", err)
+	}
+	s := &Scheduler{
+		Core:        core,
+		Channels:    map[string]notify.Channel{},
+		Templates:   templates,
+		Location:    appointments.LoadLocation(wsParams),
+		Language:    params.DefaultLanguage,
+		Clinic:      params.ClinicName,
+		Interval:    time.Duration(params.IntervalMinutes) * time.Minute,
+		Lead:        time.Duration(params.LeadHours) * time.Hour,
+		DaysAhead:   params.DaysAhead,
+		MaxAttempts: params.MaxAttempts,
+		BatchSize:   params.BatchSize,
+	}
+	for name, config := range map[string]notify.Config{notify.SMS: params.SMS, notify.Email: params.Email} {
+		channel, err := notify.New(name, config)
+		if err != nil {
+			log.Fatalln("error loading notification channel", err)
+		}
+		if channel != nil {
+			s.Channels[name] = channel
+		}
+	}
+	if s.Language == "" {
+		s.Language = notify.Persian
+	}
+	if s.Interval <= 0 {
+		s.Interval = DefaultInterval
+	}
+	if s.Lead <= 0 {
+		s.Lead = DefaultLead
+	}
+	if s.DaysAhead <= 0 {
+		s.DaysAhead = DefaultDaysAhead
+	}
+	if s.MaxAttempts <= 0 {
+		s.MaxAttempts = notify.DefaultMaxAttempts
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = DefaultBatchSize
+	}
+	return s
+}
+
+// recipients returns the address of the patient on each configured channel
+func (s *Scheduler) recipients(row models.ReminderRow) map[string]string {
+	addresses := map[string]string{}
+	if _, ok := s.Channels[notify.SMS]; ok && row.Phone != "" {
+		addresses[notify.SMS] = string(row.Phone)
+	}
+	if _, ok := s.Channels[notify.Email]; ok && row.Email != "" {
+		addresses[notify.Email] = string(row.Email)
+	}
+	return addresses
+}
+
+// Schedule creates the reminders due from now on, reminders created before are left alone
+func (s *Scheduler) Schedule(now time.Time) (int, error) {
+	target := now.In(s.Location).AddDate(0, 0, s.DaysAhead)
+	day := target.Format(time.DateOnly)
+	var reminders []models.ReminderRow
+	for _, source := range []struct {
+		query string
+		args  []any
+	}{
+		{upcomingAppointments.SQL(), []any{now, now.Add(s.Lead)}},
+		{upcomingFollowUps.SQL(), []any{day, s.Location.String()}},
+	} {
+		rows, err := libQuery.GetQuery[models.ReminderRow](source.query, s.Core.GetDB(), source.args...)
+		if err != nil {
+			return 0, err
+		}
+		reminders = append(reminders, rows...)
+	}
+	if s.Therapies != nil {
+		sessions, err := s.Therapies.SessionsOn(target)
+		if err != nil {
+			return 0, err
+		}
+		rows, err := libQuery.GetQuery[models.ReminderRow](upcomingTherapies.SQL(), s.Core.GetDB(), day, s.Location.String())
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			if sessions[row.RefID] {
+				reminders = append(reminders, row)
+			}
+		}
+	}
+
+	created := 0
+	for _, row := range reminders {
+		language := string(row.Language)
+		if language == "" {
+			language = s.Language
+		}
+		data := notify.Data{
+			Patient: string(row.PatientName),
+			Doctor:  string(row.DoctorName),
+			Therapy: row.Therapy,
+			Clinic:  s.Clinic,
+			Due:     row.DueAt.In(s.Location),
+		}
+		for channel, to := range s.recipients(row) {
+			msg, err := s.Templates.Render(row.Kind, language, to, data)
+			if err != nil {
+				return created, err
+			}
+			result, err := s.Core.GetDB().InsertRow(insertNotification.SQL(), row.Kind, row.RefID, row.DueAt, row.PatientID,
+				channel, to, language, msg.Subject, msg.Body, s.MaxAttempts)
+			if err != nil {
+				return created, err
+			}
+			if rows, err := result.RowsAffected(); err == nil {
+				created += int(rows)
+			}
+		}
+	}
+	return created, nil
+}
+
+// Dispatch delivers the due notifications and returns how many were sent
+func (s *Scheduler) Dispatch(ctx context.Context) (int, error) {
+	claim := storage.NewID()
+	if _, err := s.Core.GetDB().InsertRow(claimDue.SQL(), claim, s.BatchSize); err != nil {
+		return 0, err
+	}
+	rows, err := libQuery.GetQuery[models.NotificationRow](claimedNotifications.SQL(), s.Core.GetDB(), claim)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, row := range rows {
+		if row.Obsolete {
+			if _, err = s.Core.GetDB().InsertRow(cancelNotification.SQL(), row.ID, claim); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		err = s.send(ctx, row)
+		if err == nil {
+			sent++
+		}
+		if err = s.finish(row, claim, err); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (s *Scheduler) send(ctx context.Context, row models.NotificationRow) error {
+	channel, ok := s.Channels[row.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", row.Channel)
+	}
+	return channel.Send(ctx, notify.Message{To: row.Recipient, Subject: string(row.Subject), Body: row.Body})
+}
+
+// finish records the attempt, a failed notification is retried after a back-off until it runs out of attempts
+func (s *Scheduler) finish(row models.NotificationRow, claim string, sendErr error) error {
+	attempt := row.Attempts + 1
+	status, message, next := notify.Sent, "", time.Now()
+	if sendErr != nil {
+		message = sendErr.Error()
+		status, next = notify.Pending, time.Now().Add(notify.Backoff(attempt))
+		if attempt >= row.MaxAttempts {
+			status = notify.Failed
+		}
+	}
+	_, err := s.Core.GetDB().InsertRow(insertAttempt.SQL(), row.ID, attempt, row.Channel, sendErr == nil, message)
+	if err != nil {
+		return err
+	}
+	_, err = s.Core.GetDB().InsertRow(finishAttempt.SQL(), row.ID, claim, status, message, next)
+	return err
+}
+
+// RunOnce schedules and dispatches reminders once
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	created, err := s.Schedule(time.Now())
+	if err != nil {
+		return fmt.Errorf("scheduling reminders: %w", err)
+	}
+	sent, err := s.Dispatch(ctx)
+	if err != nil {
+		return fmt.Errorf("dispatching reminders: %w", err)
+	}
+	if created > 0 || sent > 0 {
+		log.Printf("notifications: %d reminders created, %d sent", created, sent)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package paging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"healthcare/controllers/locale"
+	"healthcare/utils/i18n"
+	"healthcare/utils/paging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware pages the lists of every GET handler in it, the requestCore ones included: handlers
+// respond with the whole list as the result of the envelope, it is sorted and cut to the _start,
+// _end, _sort and _order query parameters and its length is sent in the X-Total-Count header. Lists
+// that grow with the clinic are paged in their query instead and respond with a paging.Page
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		window, err := paging.Parse(c.Request.URL.Query())
+		if err != nil {
+			locale.Error(c, http.StatusBadRequest, i18n.InvalidPage, err.Error())
+			c.Abort()
+			return
+		}
+		w := &writer{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		w.flush(window)
+	}
+}
+
+// writer holds back the body of successful JSON responses so their lists can be paged, other
+// responses such as errors, event streams and downloads pass straight through
+type writer struct {
+	gin.ResponseWriter
+	status  int
+	decided bool
+	hold    bool
+	body    bytes.Buffer
+}
+
+// decide holds the response when it is a successful JSON one, the content type is known once the
+// handler writes the body
+func (w *writer) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	status := w.Status()
+	w.hold = status >= http.StatusOK && status < http.StatusMultipleChoices &&
+		strings.Contains(w.Header().Get("Content-Type"), "json")
+	if !w.hold && w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *writer) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+		return
+	}
+	if !w.hold {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *writer) WriteHeaderNow() {
+	w.decide()
+	if !w.hold {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	w.decide()
+	if w.hold {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	w.decide()
+	if w.hold {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *writer) Flush() {
+	w.decide()
+	if !w.hold {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *writer) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *writer) Size() int {
+	if w.hold {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *writer) Written() bool {
+	return w.hold || w.ResponseWriter.Written()
+}
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush writes the held response with its list paged
+func (w *writer) flush(window paging.Window) {
+	if !w.decided && w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if !w.hold {
+		return
+	}
+	body := page(w.body.Bytes(), window, w.Header())
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.Status())
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// page cuts the result of an envelope to the window when it is a list and unwraps it when it is a
+// Page, other bodies are kept as they are
+func page(body []byte, window paging.Window, header http.Header) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var envelope map[string]any
+	if err := decoder.Decode(&envelope); err != nil {
+		return body
+	}
+	if items, total, ok := paging.Paged(envelope["result"]); ok {
+		header.Set(paging.TotalHeader, total.String())
+		envelope["result"] = items
+	} else if items, ok := envelope["result"].([]any); ok {
+		header.Set(paging.TotalHeader, strconv.Itoa(len(items)))
+		envelope["result"] = window.Apply(items)
+	} else {
+		return body
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(envelope); err != nil {
+		return body
+	}
+	return out.Bytes()
+}
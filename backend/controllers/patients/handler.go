@@ -2,6 +2,8 @@ package patients
 
 import (
 	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/validation"
 	"net/http"
 	"time"
 
@@ -48,20 +50,17 @@ func (h patientsHandler) Parameters() handlers.HandlerParameters {
 
 // runs after validating request
 func (h patientsHandler) Initializer(req handlers.HandlerRequest[models.PatientRequest, *models.PatientResponse]) error {
-	return nil
+	if h.Name == "patients-delete" {
+		return nil
+	}
+	return validation.Error(req.Request)
 }
 
 // Handler is the main method that handles request and returns the response
 func (h patientsHandler) Handler(req handlers.HandlerRequest[models.PatientRequest, *models.PatientResponse]) (*models.PatientResponse, error) {
 	switch h.Name {
 	case "patients-post":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			INSERT INTO public.patients (
-				profile_id, patient_id, emergency_contact_name, emergency_contact_phone,
-				allergies, current_medications, insurance_info, medical_history,
-				blood_type, height, weight, date_of_birth, gender, address, phone, email, full_name
-			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16, :17)
-		`, req.Request.ProfileID, req.Request.PatientID, req.Request.EmergencyContactName,
+		result, err := req.Core.GetDB().InsertRow(insertPatient.SQL(), req.Request.ProfileID, req.Request.PatientID, req.Request.EmergencyContactName,
 			req.Request.EmergencyContactPhone, req.Request.Allergies, req.Request.CurrentMedications,
 			req.Request.InsuranceInfo, req.Request.MedicalHistory, req.Request.BloodType,
 			req.Request.Height, req.Request.Weight, req.Request.DateOfBirth, req.Request.Gender,
@@ -76,26 +75,7 @@ func (h patientsHandler) Handler(req handlers.HandlerRequest[models.PatientReque
 		return req.Response, nil
 
 	case "patients-put":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			UPDATE public.patients SET
-				emergency_contact_name = :1,
-				emergency_contact_phone = :2,
-				allergies = :3,
-				current_medications = :4,
-				insurance_info = :5,
-				medical_history = :6,
-				blood_type = :7,
-				height = :8,
-				weight = :9,
-				date_of_birth = :10,
-				gender = :11,
-				address = :12,
-				phone = :13,
-				email = :14,
-				full_name = :15,
-				updated_at = NOW()
-			WHERE id = :16
-		`, req.Request.EmergencyContactName, req.Request.EmergencyContactPhone,
+		result, err := req.Core.GetDB().InsertRow(updatePatient.SQL(), req.Request.EmergencyContactName, req.Request.EmergencyContactPhone,
 			req.Request.Allergies, req.Request.CurrentMedications, req.Request.InsuranceInfo,
 			req.Request.MedicalHistory, req.Request.BloodType, req.Request.Height,
 			req.Request.Weight, req.Request.DateOfBirth, req.Request.Gender,
@@ -111,9 +91,7 @@ func (h patientsHandler) Handler(req handlers.HandlerRequest[models.PatientReque
 		return req.Response, nil
 
 	case "patients-delete":
-		result, err := req.Core.GetDB().InsertRow(`--sql
-			DELETE FROM public.patients WHERE id = :1
-		`, req.Request.ID)
+		result, err := req.Core.GetDB().InsertRow(deletePatient.SQL(), req.Request.ID)
 		if err != nil {
 			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
 		}
@@ -207,7 +185,7 @@ func (env patientsEnv) patientsDeleteHandler(simulation bool) any {
 func (env patientsEnv) patientsGetHandler(simulation bool) any {
 	return handlers.QueryHandlerWithCaching[models.PatientRow](
 		"patients-get", models.QuerySingle, "/patients/:id",
-		QueryMap, env.Interface, libRequest.URI,
+		QueryMaps[dialect.Current()], env.Interface, libRequest.URI,
 		true, simulation, nil,
 		&handlers.CachingArgs{
 			Cache:       true,
@@ -230,5 +208,5 @@ func (env patientsEnv) patientsGetHandler(simulation bool) any {
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 func (env patientsEnv) patientsGetAllHandler(simulation bool) any {
-	return handlers.QueryHandler[models.PatientRow]("patients-get-all", models.QueryAll, "/patients/all", QueryMap, env.Interface, libRequest.Query, true, simulation, nil)
+	return handlers.QueryHandler[models.PatientRow]("patients-get-all", models.QueryAll, "/patients/all", QueryMaps[dialect.Current()], env.Interface, libRequest.Query, true, simulation, nil)
 }
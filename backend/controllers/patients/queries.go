@@ -2,64 +2,204 @@ package patients
 
 import (
 	"healthcare/models"
+	"healthcare/utils/dialect"
+
 	"github.com/hmmftg/requestCore/libQuery"
 )
 
-var QueryMap = map[string]libQuery.QueryConfig[models.PatientRow]{
-	models.QuerySingle: {
-		Query: `--sql
-			SELECT 
-				p.id,
-				p.profile_id,
-				p.patient_id,
-				p.emergency_contact_name,
-				p.emergency_contact_phone,
-				p.allergies,
-				p.current_medications,
-				p.insurance_info,
-				p.medical_history,
-				p.blood_type,
-				p.height,
-				p.weight,
-				p.date_of_birth,
-				p.gender,
-				p.address,
-				p.phone,
-				p.email,
-				p.full_name,
-				p.created_at,
-				p.updated_at
-			FROM public.patients p
-			WHERE p.id = :1
+var (
+	// QueryMaps holds the query map of each dialect, handlers are registered with the one of the
+	// current dialect
+	QueryMaps = map[dialect.Dialect]map[string]libQuery.QueryConfig[models.PatientRow]{
+		dialect.Postgres: {
+			models.QuerySingle: {
+				Query: `--sql
+					SELECT
+						p.id,
+						p.profile_id,
+						p.patient_id,
+						p.emergency_contact_name,
+						p.emergency_contact_phone,
+						p.allergies,
+						p.current_medications,
+						p.insurance_info,
+						p.medical_history,
+						p.blood_type,
+						p.height,
+						p.weight,
+						p.date_of_birth,
+						p.gender,
+						p.address,
+						p.phone,
+						p.email,
+						p.full_name,
+						p.created_at,
+						p.updated_at
+					FROM public.patients p
+					WHERE p.id = :1
+				`,
+				Params: []string{"id"},
+			},
+			models.QueryAll: {
+				Query: `--sql
+					SELECT
+						p.id,
+						p.profile_id,
+						p.patient_id,
+						p.emergency_contact_name,
+						p.emergency_contact_phone,
+						p.allergies,
+						p.current_medications,
+						p.insurance_info,
+						p.medical_history,
+						p.blood_type,
+						p.height,
+						p.weight,
+						p.date_of_birth,
+						p.gender,
+						p.address,
+						p.phone,
+						p.email,
+						p.full_name,
+						p.created_at,
+						p.updated_at
+					FROM public.patients p
+					ORDER BY p.created_at DESC
+				`,
+				Params: []string{},
+			},
+		},
+		dialect.Oracle: {
+			models.QuerySingle: {
+				Query: `--sql
+					SELECT
+						p.id,
+						p.profile_id,
+						p.patient_id,
+						p.emergency_contact_name,
+						p.emergency_contact_phone,
+						p.allergies,
+						p.current_medications,
+						p.insurance_info,
+						p.medical_history,
+						p.blood_type,
+						p.height,
+						p.weight,
+						p.date_of_birth,
+						p.gender,
+						p.address,
+						p.phone,
+						p.email,
+						p.full_name,
+						p.created_at,
+						p.updated_at
+					FROM patients p
+					WHERE p.id = :1
+				`,
+				Params: []string{"id"},
+			},
+			models.QueryAll: {
+				Query: `--sql
+					SELECT
+						p.id,
+						p.profile_id,
+						p.patient_id,
+						p.emergency_contact_name,
+						p.emergency_contact_phone,
+						p.allergies,
+						p.current_medications,
+						p.insurance_info,
+						p.medical_history,
+						p.blood_type,
+						p.height,
+						p.weight,
+						p.date_of_birth,
+						p.gender,
+						p.address,
+						p.phone,
+						p.email,
+						p.full_name,
+						p.created_at,
+						p.updated_at
+					FROM patients p
+					ORDER BY p.created_at DESC
+				`,
+				Params: []string{},
+			},
+		},
+	}
+	insertPatient = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.patients (
+				profile_id, patient_id, emergency_contact_name, emergency_contact_phone,
+				allergies, current_medications, insurance_info, medical_history,
+				blood_type, height, weight, date_of_birth, gender, address, phone, email, full_name
+			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16, :17)
+		`,
+		Oracle: `--sql
+			INSERT INTO patients (
+				profile_id, patient_id, emergency_contact_name, emergency_contact_phone,
+				allergies, current_medications, insurance_info, medical_history,
+				blood_type, height, weight, date_of_birth, gender, address, phone, email, full_name
+			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16, :17)
+		`,
+	}
+	updatePatient = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.patients SET
+				emergency_contact_name = :1,
+				emergency_contact_phone = :2,
+				allergies = :3,
+				current_medications = :4,
+				insurance_info = :5,
+				medical_history = :6,
+				blood_type = :7,
+				height = :8,
+				weight = :9,
+				date_of_birth = :10,
+				gender = :11,
+				address = :12,
+				phone = :13,
+				email = :14,
+				full_name = :15,
+				updated_at = NOW()
+			WHERE id = :16
+		`,
+		Oracle: `--sql
+			UPDATE patients SET
+				emergency_contact_name = :1,
+				emergency_contact_phone = :2,
+				allergies = :3,
+				current_medications = :4,
+				insurance_info = :5,
+				medical_history = :6,
+				blood_type = :7,
+				height = :8,
+				weight = :9,
+				date_of_birth = :10,
+				gender = :11,
+				address = :12,
+				phone = :13,
+				email = :14,
+				full_name = :15,
+				updated_at = SYSTIMESTAMP
+			WHERE id = :16
 		`,
-		Params: []string{"id"},
-	},
-	models.QueryAll: {
-		Query: `--sql
-			SELECT 
-				p.id,
-				p.profile_id,
-				p.patient_id,
-				p.emergency_contact_name,
-				p.emergency_contact_phone,
-				p.allergies,
-				p.current_medications,
-				p.insurance_info,
-				p.medical_history,
-				p.blood_type,
-				p.height,
-				p.weight,
-				p.date_of_birth,
-				p.gender,
-				p.address,
-				p.phone,
-				p.email,
-				p.full_name,
-				p.created_at,
-				p.updated_at
-			FROM public.patients p
-			ORDER BY p.created_at DESC
+	}
+	deletePatient = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.patients WHERE id = :1
 		`,
-		Params: []string{},
-	},
+		Oracle: `--sql
+			DELETE FROM patients WHERE id = :1
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"deletePatient": deletePatient,
+	"insertPatient": insertPatient,
+	"updatePatient": updatePatient,
 }
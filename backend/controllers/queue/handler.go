@@ -0,0 +1,609 @@
+package queue
+
+import (
+	"fmt"
+	"healthcare/controllers/ums"
+	"healthcare/controllers/visits"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/events"
+	"healthcare/utils/jalali"
+	"healthcare/utils/queue"
+	"healthcare/utils/storage"
+	"healthcare/utils/validation"
+	"healthcare/utils/visitflow"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type queueEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Location  *time.Location
+	Events    *events.Broker
+}
+
+// publish sends the change of an entry to its queue, the doctor who called it and the whole clinic
+func publish(broker *events.Broker, eventType string, entry *models.QueueEntryRow) {
+	rooms := []string{entry.QueueKey, events.Clinic}
+	if entry.CalledBy != "" {
+		if room, _ := queue.Key(string(entry.CalledBy), ""); room != entry.QueueKey {
+			rooms = append(rooms, room)
+		}
+	}
+	broker.Publish(eventType, models.QueueEvent{
+		EntryID:    entry.ID,
+		QueueKey:   entry.QueueKey,
+		Number:     entry.Number,
+		Status:     entry.Status,
+		DoctorID:   string(entry.CalledBy),
+		DoctorName: string(entry.DoctorName),
+		Department: string(entry.Department),
+	}, rooms...)
+}
+
+// publishVisit sends a status transition of the visit of an entry to the room of its doctor and the whole clinic
+func publishVisit(broker *events.Broker, visitID, doctorID, from, to string) {
+	room, _ := queue.Key(doctorID, "")
+	broker.Publish(models.EventVisitStatus, models.VisitStatusEvent{
+		VisitID:    visitID,
+		DoctorID:   doctorID,
+		FromStatus: from,
+		ToStatus:   to,
+	}, room, events.Clinic)
+}
+
+// isAlreadyQueued reports whether err is a violation of the index allowing one active entry per patient and day
+func isAlreadyQueued(err error) bool {
+	return dialect.Is(err, dialect.UniqueViolation, "queue_entries_active_patient_idx")
+}
+
+func getDoctor(core requestCore.RequestCoreInterface, doctorID string) (*models.QueueDoctorRow, error) {
+	rows, err := libQuery.GetQuery[models.QueueDoctorRow](doctorByID.SQL(), core.GetDB(), doctorID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_DOCTOR", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "DOCTOR_NOT_FOUND", "doctor %s not found", doctorID)
+	}
+	return &rows[0], nil
+}
+
+func getEntry(core requestCore.RequestCoreInterface, query, id string) (*models.QueueEntryRow, error) {
+	rows, err := libQuery.GetQuery[models.QueueEntryRow](query, core.GetDB(), id)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_QUEUE_ENTRY", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "QUEUE_ENTRY_NOT_FOUND", "queue entry %s not found", id)
+	}
+	return &rows[0], nil
+}
+
+// annotate sets the position and estimated wait of the waiting entries, entries of a queue must
+// be ordered by registration time
+func annotate(core requestCore.RequestCoreInterface, consultMinutes int, entries []models.QueueEntryRow) error {
+	ahead := map[string]int{}
+	minutes := map[string]float64{}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status != queue.Waiting {
+			continue
+		}
+		if _, ok := minutes[entry.QueueKey]; !ok {
+			calls, err := libQuery.GetQuery[models.QueueCallRow](callTimes.SQL(), core.GetDB(),
+				entry.QueueDate.Format(time.DateOnly), entry.QueueKey)
+			if err != nil {
+				return libError.New(http.StatusInternalServerError, "ERROR_GET_QUEUE", err.Error())
+			}
+			times := make([]time.Time, 0, len(calls))
+			for _, call := range calls {
+				times = append(times, call.CalledAt)
+			}
+			minutes[entry.QueueKey] = queue.ConsultMinutes(times, consultMinutes)
+		}
+		entry.Position = ahead[entry.QueueKey] + 1
+		entry.EstimatedWait = queue.EstimatedWait(ahead[entry.QueueKey], minutes[entry.QueueKey])
+		ahead[entry.QueueKey]++
+	}
+	return nil
+}
+
+// queueOf loads the entries of the queue of an entry with its position
+func queueOf(core requestCore.RequestCoreInterface, consultMinutes int, entry *models.QueueEntryRow) error {
+	if entry.Status != queue.Waiting {
+		return nil
+	}
+	entries, err := libQuery.GetQuery[models.QueueEntryRow](entriesOfDay.SQL(), core.GetDB(),
+		entry.QueueDate.Format(time.DateOnly), entry.QueueKey, queue.Waiting)
+	if err != nil {
+		return libError.New(http.StatusInternalServerError, "ERROR_GET_QUEUE", err.Error())
+	}
+	if err = annotate(core, consultMinutes, entries); err != nil {
+		return err
+	}
+	for _, other := range entries {
+		if other.ID == entry.ID {
+			entry.Position, entry.EstimatedWait = other.Position, other.EstimatedWait
+		}
+	}
+	return nil
+}
+
+type queueHandler struct {
+	Name           string
+	Location       *time.Location
+	ConsultMinutes int
+	Events         *events.Broker
+}
+
+// returns handler title
+func (h queueHandler) Parameters() handlers.HandlerParameters {
+	body, path := libRequest.JSON, "/queue/:id"
+	switch h.Name {
+	case "queue-post":
+		path = "/queue"
+	case "queue-get":
+		body = libRequest.NoBinding
+	}
+	return handlers.HandlerParameters{
+		Title:          "queue",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           path,
+	}
+}
+
+// runs after validating request
+func (h queueHandler) Initializer(req handlers.HandlerRequest[models.QueueRequest, *models.QueueResponse]) error {
+	if h.Name != "queue-post" {
+		req.Request.EntryID = req.W.Parser.GetUrlParam("id")
+		if req.Request.EntryID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, "INVALID_QUEUE_ENTRY_ID", "queue entry id is required")
+		}
+		return nil
+	}
+	req.Request.Department = strings.TrimSpace(req.Request.Department)
+	if req.Request.VisitType == "" {
+		req.Request.VisitType = "general"
+	}
+	return validation.Error(req.Request)
+}
+
+// Handler is the main method that handles request and returns the response
+func (h queueHandler) Handler(req handlers.HandlerRequest[models.QueueRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	req.Response = &models.QueueResponse{Result: libQuery.DmlResult{Success: true}}
+	event := ""
+	switch h.Name {
+	case "queue-post":
+		patients, err := libQuery.GetQuery[models.PatientIdentityRow](patientByID.SQL(), req.Core.GetDB(), req.Request.PatientID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_PATIENT", err.Error())
+		}
+		if len(patients) == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "PATIENT_NOT_FOUND", "patient %s not found", req.Request.PatientID)
+		}
+		department := strings.ToLower(strings.TrimSpace(req.Request.Department))
+		if req.Request.DoctorID != "" {
+			if _, err = getDoctor(req.Core, req.Request.DoctorID); err != nil {
+				return nil, err
+			}
+		} else {
+			doctors, err := libQuery.GetQuery[models.QueueDoctorRow](departmentDoctors.SQL(), req.Core.GetDB(), department)
+			if err != nil {
+				return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_DOCTOR", err.Error())
+			}
+			if len(doctors) == 0 {
+				return nil, libError.NewWithDescription(http.StatusNotFound, "DEPARTMENT_NOT_FOUND", "no doctor serves department %s", department)
+			}
+		}
+		user, err := ums.CurrentUser(req.W, req.Core)
+		if err != nil {
+			return nil, err
+		}
+		key, _ := queue.Key(req.Request.DoctorID, department)
+		id := storage.NewID()
+		result, err := req.Core.GetDB().InsertRow(insertEntry.SQL(), id, queue.Day(time.Now(), h.Location), key,
+			req.Request.PatientID, req.Request.DoctorID, department, req.Request.VisitType,
+			strings.TrimSpace(req.Request.ChiefComplaint), strings.TrimSpace(req.Request.Notes), user.UserId)
+		if err != nil {
+			if isAlreadyQueued(err) {
+				return nil, libError.NewWithDescription(http.StatusConflict, "ALREADY_QUEUED", "patient %s is already waiting today", req.Request.PatientID)
+			}
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+		req.Request.EntryID = id
+		event = models.EventQueueRegistered
+
+	case "queue-get":
+
+	case "queue-skip":
+		entry, err := getEntry(req.Core, entryByID.SQL(), req.Request.EntryID)
+		if err != nil {
+			return nil, err
+		}
+		if !queue.CanSkip(entry.Status) {
+			return nil, libError.NewWithDescription(http.StatusConflict, "INVALID_QUEUE_STATUS", "a %s queue entry cannot be skipped", entry.Status)
+		}
+		user, err := ums.CurrentUser(req.W, req.Core)
+		if err != nil {
+			return nil, err
+		}
+		reason := strings.TrimSpace(req.Request.Reason)
+		if entry.VisitID == "" {
+			result, err := req.Core.GetDB().InsertRow(skipEntry.SQL(), entry.ID, reason, entry.Status)
+			if err != nil {
+				return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+			}
+			if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+				return nil, libError.NewWithDescription(http.StatusConflict, "QUEUE_ENTRY_CHANGED", "queue entry %s is no longer %s", entry.ID, entry.Status)
+			}
+			req.Response.Result = libQuery.GetDmlResult(result, nil)
+		} else {
+			// the patient did not come in, so the visit opened on calling them is cancelled with the skip
+			state, err := visits.GetState(req.Core, string(entry.VisitID))
+			if err != nil {
+				return nil, err
+			}
+			if state.Status != visitflow.InProgress || state.IsLocked {
+				return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_STATUS_CHANGED", "visit %s of the queue entry is %s", state.ID, state.Status)
+			}
+			result, err := req.Core.GetDB().InsertRow(skipCalledEntry.SQL(), entry.ID, entry.Status, reason,
+				user.UserId, user.UserName, fmt.Sprintf("skipped in queue, number %d", entry.Number))
+			if err == nil && skipCalledEntry.Unchanged(result) || dialect.Is(err, dialect.NothingChanged, "") {
+				return nil, libError.NewWithDescription(http.StatusConflict, "QUEUE_ENTRY_CHANGED", "queue entry %s or its visit %s changed", entry.ID, entry.VisitID)
+			}
+			if err != nil {
+				return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+			}
+			publishVisit(h.Events, string(entry.VisitID), state.DoctorID, visitflow.InProgress, visitflow.Cancelled)
+			req.Response.Result = libQuery.GetDmlResult(result, nil)
+		}
+		event = models.EventQueueSkipped
+
+	case "queue-requeue":
+		entry, err := getEntry(req.Core, entryByID.SQL(), req.Request.EntryID)
+		if err != nil {
+			return nil, err
+		}
+		if !queue.CanRequeue(entry.Status) {
+			return nil, libError.NewWithDescription(http.StatusConflict, "INVALID_QUEUE_STATUS", "a %s queue entry cannot be requeued", entry.Status)
+		}
+		if queue.Day(time.Now(), h.Location) != entry.QueueDate.Format(time.DateOnly) {
+			return nil, libError.NewWithDescription(http.StatusConflict, "QUEUE_CLOSED", "the queue of %s is closed", entry.QueueDate.Format(time.DateOnly))
+		}
+		result, err := req.Core.GetDB().InsertRow(requeueEntry.SQL(), entry.ID)
+		if err != nil {
+			if isAlreadyQueued(err) {
+				return nil, libError.NewWithDescription(http.StatusConflict, "ALREADY_QUEUED", "patient %s is already waiting today", entry.PatientID)
+			}
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, "QUEUE_ENTRY_CHANGED", "queue entry %s is no longer skipped", entry.ID)
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+		event = models.EventQueueRequeued
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+
+	entry, err := getEntry(req.Core, entryByID.SQL(), req.Request.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	if event != "" {
+		publish(h.Events, event, entry)
+	}
+	if err = queueOf(req.Core, h.ConsultMinutes, entry); err != nil {
+		return nil, err
+	}
+	req.Response.Entry = entry
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h queueHandler) Simulation(req handlers.HandlerRequest[models.QueueRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h queueHandler) Finalizer(req handlers.HandlerRequest[models.QueueRequest, *models.QueueResponse]) {
+}
+
+type queueListHandler struct {
+	Location       *time.Location
+	ConsultMinutes int
+}
+
+// returns handler title
+func (h queueListHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "queue",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/queue",
+	}
+}
+
+// runs after validating request
+func (h queueListHandler) Initializer(req handlers.HandlerRequest[models.QueueListRequest, *models.QueueResponse]) error {
+	if req.Request.Status != "" && req.Request.Status != queue.Waiting && req.Request.Status != queue.Called &&
+		req.Request.Status != queue.Skipped && req.Request.Status != queue.Cancelled {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_QUEUE_STATUS", "unknown queue status %s", req.Request.Status)
+	}
+	if req.Request.DoctorID != "" && req.Request.Department != "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_QUEUE", "a queue is of either a doctor or a department")
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h queueListHandler) Handler(req handlers.HandlerRequest[models.QueueListRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	calendar, err := jalali.FromRequest(req.W, req.Request.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	date, err := jalali.ParseDay(calendar, req.Request.Date, "date", h.Location)
+	if err != nil {
+		return nil, err
+	}
+	day := queue.Day(time.Now(), h.Location)
+	if !date.IsZero() {
+		day = date.Format(time.DateOnly)
+	}
+	key := ""
+	if req.Request.DoctorID != "" || req.Request.Department != "" {
+		key, _ = queue.Key(req.Request.DoctorID, req.Request.Department)
+	}
+	rows, err := libQuery.GetQuery[models.QueueEntryRow](entriesOfDay.SQL(), req.Core.GetDB(), day, key, req.Request.Status)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_QUEUE", err.Error())
+	}
+	if req.Request.Status == "" || req.Request.Status == queue.Waiting {
+		if err = annotate(req.Core, h.ConsultMinutes, rows); err != nil {
+			return nil, err
+		}
+	}
+	req.Response = &models.QueueResponse{
+		Result:  libQuery.DmlResult{Success: true},
+		Entries: rows,
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h queueListHandler) Simulation(req handlers.HandlerRequest[models.QueueListRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h queueListHandler) Finalizer(req handlers.HandlerRequest[models.QueueListRequest, *models.QueueResponse]) {
+}
+
+type callNextHandler struct {
+	Location *time.Location
+	Events   *events.Broker
+}
+
+// returns handler title
+func (h callNextHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "queue",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/queue/call-next",
+	}
+}
+
+// runs after validating request
+func (h callNextHandler) Initializer(req handlers.HandlerRequest[models.CallNextRequest, *models.QueueResponse]) error {
+	return validation.Error(req.Request)
+}
+
+// Handler calls the next walk-in of the doctor and their department and converts it into an in-progress visit
+func (h callNextHandler) Handler(req handlers.HandlerRequest[models.CallNextRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	doctor, err := getDoctor(req.Core, req.Request.DoctorID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := ums.CurrentUser(req.W, req.Core)
+	if err != nil {
+		return nil, err
+	}
+	own, _ := queue.Key(doctor.ID, "")
+	department, _ := queue.Key("", doctor.Specialization)
+	callID := storage.NewID()
+	result, err := req.Core.GetDB().InsertRow(callNext.SQL(), doctor.ID, callID, queue.Day(time.Now(), h.Location), own, department)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "QUEUE_EMPTY", "no walk-in is waiting for doctor %s", doctor.ID)
+	}
+	entry, err := getEntry(req.Core, entryByCall.SQL(), callID)
+	if err != nil {
+		return nil, err
+	}
+
+	visitID := storage.NewID()
+	result, err = req.Core.GetDB().InsertRow(convertEntry.SQL(), visitID, entry.PatientID, doctor.ID, entry.VisitType,
+		entry.ChiefComplaint, entry.Notes, user.UserId, user.UserName,
+		fmt.Sprintf("walk-in queue number %d", entry.Number), entry.ID)
+	if err == nil && convertEntry.Unchanged(result) || dialect.Is(err, dialect.NothingChanged, "") {
+		// the entry was cancelled, skipped or converted meanwhile, it is no longer ours to release
+		return nil, libError.NewWithDescription(http.StatusConflict, "QUEUE_ENTRY_CHANGED", "queue entry %s changed while starting its visit", entry.ID)
+	}
+	if err != nil {
+		// give the walk-in back its place so the next call picks it up again
+		released, releaseErr := req.Core.GetDB().InsertRow(releaseCall.SQL(), entry.ID)
+		if releaseErr == nil && releaseCall.Unchanged(released) {
+			releaseErr = fmt.Errorf("queue entry %s is no longer called", entry.ID)
+		}
+		if releaseErr != nil {
+			log.Println("queue: releasing the call of entry", entry.ID, releaseErr)
+			return nil, libError.NewWithDescription(http.StatusInternalServerError, "ERROR_INSERT",
+				"%s, releasing the call of queue entry %s failed too: %s", err.Error(), entry.ID, releaseErr.Error())
+		}
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+	}
+
+	entry, err = getEntry(req.Core, entryByID.SQL(), entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	publish(h.Events, models.EventQueueCalled, entry)
+	publishVisit(h.Events, visitID, doctor.ID, visitflow.CheckedIn, visitflow.InProgress)
+	req.Response = &models.QueueResponse{
+		Result: libQuery.DmlResult{Success: true, Message: fmt.Sprintf("number %d is called", entry.Number)},
+		Entry:  entry,
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h callNextHandler) Simulation(req handlers.HandlerRequest[models.CallNextRequest, *models.QueueResponse]) (*models.QueueResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h callNextHandler) Finalizer(req handlers.HandlerRequest[models.CallNextRequest, *models.QueueResponse]) {
+}
+
+// queuePostHandler godoc
+// @Summary Register a walk-in
+// @Description Register a walk-in patient to today's queue of a doctor or a department and assign the next queue number
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param entry body models.QueueRequest true "Walk-in"
+// @Router /queue [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queuePostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.QueueRequest, *models.QueueResponse, queueHandler](env.Interface, env.queueHandler("queue-post"), simulation)
+}
+
+// queueListHandler godoc
+// @Summary Get the queue of a day
+// @Description Get the walk-ins of a day with the position and estimated wait of waiting patients, defaults to today and all queues
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of date" Enums(gregorian, jalali)
+// @Param date query string false "Date (YYYY-MM-DD)"
+// @Param calendar query string false "Calendar of date, wins over the header" Enums(gregorian, jalali)
+// @Param doctor_id query string false "Doctor ID"
+// @Param department query string false "Department"
+// @Param status query string false "Status" Enums(waiting, called, skipped, cancelled)
+// @Router /queue [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queueListHandler(simulation bool) any {
+	return handlers.BaseHandler[models.QueueListRequest, *models.QueueResponse, queueListHandler](env.Interface,
+		queueListHandler{Location: env.Location, ConsultMinutes: env.Params.Specific.Queue.ConsultMinutes}, simulation)
+}
+
+// queueGetHandler godoc
+// @Summary Get a queue entry
+// @Description Get a walk-in with its current position and estimated wait
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Queue entry ID"
+// @Router /queue/:id [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queueGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.QueueRequest, *models.QueueResponse, queueHandler](env.Interface, env.queueHandler("queue-get"), simulation)
+}
+
+// queueCallNextHandler godoc
+// @Summary Call the next walk-in
+// @Description Call the first waiting walk-in of a doctor or their department and start an in-progress visit for them
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param call body models.CallNextRequest true "Doctor"
+// @Router /queue/call-next [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queueCallNextHandler(simulation bool) any {
+	return handlers.BaseHandler[models.CallNextRequest, *models.QueueResponse, callNextHandler](env.Interface, callNextHandler{Location: env.Location, Events: env.Events}, simulation)
+}
+
+// queueSkipHandler godoc
+// @Summary Skip a queue entry
+// @Description Skip a waiting or called walk-in, the visit of a called walk-in is cancelled
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Queue entry ID"
+// @Param entry body models.QueueRequest true "Reason"
+// @Router /queue/:id/skip [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queueSkipHandler(simulation bool) any {
+	return handlers.BaseHandler[models.QueueRequest, *models.QueueResponse, queueHandler](env.Interface, env.queueHandler("queue-skip"), simulation)
+}
+
+// queueRequeueHandler godoc
+// @Summary Requeue a skipped walk-in
+// @Description Put a skipped walk-in back in today's queue with its number and place
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Queue entry ID"
+// @Router /queue/:id/requeue [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.QueueResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env queueEnv) queueRequeueHandler(simulation bool) any {
+	return handlers.BaseHandler[models.QueueRequest, *models.QueueResponse, queueHandler](env.Interface, env.queueHandler("queue-requeue"), simulation)
+}
+
+func (env queueEnv) queueHandler(name string) queueHandler {
+	return queueHandler{Name: name, Location: env.Location, ConsultMinutes: env.Params.Specific.Queue.ConsultMinutes, Events: env.Events}
+}
@@ -0,0 +1,376 @@
+package queue
+
+import "healthcare/utils/dialect"
+
+var (
+	patientByID = dialect.Query{
+		Postgres: `--sql
+			SELECT p.id, p.patient_id AS patient_number, COALESCE(p.full_name, '') AS full_name
+			  FROM public.patients p
+			 WHERE p.id = :1
+		`,
+		Oracle: `--sql
+			SELECT p.id, p.patient_id AS patient_number, p.full_name
+			  FROM patients p
+			 WHERE p.id = :1
+		`,
+	}
+	doctorByID = dialect.Query{
+		Postgres: `--sql
+			SELECT d.id, COALESCE(pr.full_name, '') AS name, d.specialization
+			  FROM public.doctors d
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE d.id = :1
+		`,
+		Oracle: `--sql
+			SELECT d.id, pr.full_name AS name, d.specialization
+			  FROM doctors d
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE d.id = :1
+		`,
+	}
+	departmentDoctors = dialect.Query{
+		Postgres: `--sql
+			SELECT d.id, COALESCE(pr.full_name, '') AS name, d.specialization
+			  FROM public.doctors d
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE lower(d.specialization) = :1
+		`,
+		Oracle: `--sql
+			SELECT d.id, pr.full_name AS name, d.specialization
+			  FROM doctors d
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE LOWER(d.specialization) = :1
+		`,
+	}
+	// the number is drawn from the counter of the queue in the same statement, so two walk-ins
+	// registered at once never get the same number and a rejected walk-in does not use one up. The
+	// Oracle block is rolled back as a whole when the entry is rejected
+	insertEntry = dialect.Query{
+		Postgres: `--sql
+			WITH counter AS (
+				INSERT INTO public.queue_counters (queue_date, queue_key, last_number)
+				VALUES (:2::DATE, :3, 1)
+				ON CONFLICT (queue_date, queue_key) DO UPDATE SET last_number = public.queue_counters.last_number + 1
+				RETURNING last_number
+			)
+			INSERT INTO public.queue_entries
+				(id, queue_date, queue_key, number, patient_id, doctor_id, department, visit_type, chief_complaint, notes, registered_by)
+			SELECT :1, :2::DATE, :3, counter.last_number, :4, NULLIF(:5, '')::UUID, NULLIF(:6, ''), :7, NULLIF(:8, ''), NULLIF(:9, ''), :10
+			  FROM counter
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_id queue_entries.id%TYPE := :1;
+				v_date DATE := TO_DATE(:2, 'YYYY-MM-DD');
+				v_key queue_entries.queue_key%TYPE := :3;
+				v_number queue_entries."NUMBER"%TYPE;
+			BEGIN
+				UPDATE queue_counters SET last_number = last_number + 1
+				 WHERE queue_date = v_date AND queue_key = v_key
+				RETURNING last_number INTO v_number;
+				IF SQL%ROWCOUNT = 0 THEN
+					BEGIN
+						INSERT INTO queue_counters (queue_date, queue_key, last_number) VALUES (v_date, v_key, 1);
+						v_number := 1;
+					EXCEPTION WHEN DUP_VAL_ON_INDEX THEN
+						UPDATE queue_counters SET last_number = last_number + 1
+						 WHERE queue_date = v_date AND queue_key = v_key
+						RETURNING last_number INTO v_number;
+					END;
+				END IF;
+				INSERT INTO queue_entries
+					(id, queue_date, queue_key, "NUMBER", patient_id, doctor_id, department, visit_type, chief_complaint, notes, registered_by)
+				VALUES (v_id, v_date, v_key, v_number, :4, :5, :6, :7, :8, :9, :10);
+			END;
+		`,
+	}
+	entryColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				e.id,
+				e.queue_date,
+				e.queue_key,
+				e.number,
+				e.patient_id,
+				p.patient_id AS patient_number,
+				COALESCE(p.full_name, '') AS patient_name,
+				COALESCE(e.doctor_id::TEXT, '') AS doctor_id,
+				COALESCE(pr.full_name, '') AS doctor_name,
+				COALESCE(e.department, '') AS department,
+				e.status,
+				e.visit_type,
+				COALESCE(e.chief_complaint, '') AS chief_complaint,
+				COALESCE(e.notes, '') AS notes,
+				COALESCE(e.reason, '') AS reason,
+				e.registered_by,
+				e.registered_at,
+				COALESCE(e.called_by::TEXT, '') AS called_by,
+				COALESCE(e.called_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS called_at,
+				COALESCE(e.visit_id::TEXT, '') AS visit_id
+			  FROM public.queue_entries e
+			  JOIN public.patients p ON p.id = e.patient_id
+			  LEFT JOIN public.doctors d ON d.id = COALESCE(e.doctor_id, e.called_by)
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+		`,
+		Oracle: `--sql
+			SELECT
+				e.id,
+				e.queue_date,
+				e.queue_key,
+				e."NUMBER",
+				e.patient_id,
+				p.patient_id AS patient_number,
+				p.full_name AS patient_name,
+				e.doctor_id,
+				pr.full_name AS doctor_name,
+				e.department,
+				e.status,
+				e.visit_type,
+				e.chief_complaint,
+				e.notes,
+				e.reason,
+				e.registered_by,
+				e.registered_at,
+				e.called_by,
+				COALESCE(e.called_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS called_at,
+				e.visit_id
+			  FROM queue_entries e
+			  JOIN patients p ON p.id = e.patient_id
+			  LEFT JOIN doctors d ON d.id = COALESCE(e.doctor_id, e.called_by)
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+		`,
+	}
+	entryByID = entryColumns.Append(`
+		 WHERE e.id = :1
+	`)
+	entryByCall = entryColumns.Append(`
+		 WHERE e.call_id = :1
+	`)
+	entriesOfDay = dialect.Query{
+		Postgres: entryColumns.Postgres + `
+			 WHERE e.queue_date = :1::DATE
+			   AND (:2 = '' OR e.queue_key = :2)
+			   AND (:3 = '' OR e.status = :3)
+			 ORDER BY e.queue_key, e.registered_at, e.number
+		`,
+		Oracle: `
+			WITH a AS (SELECT TO_DATE(:1, 'YYYY-MM-DD') AS queue_date, :2 AS queue_key, :3 AS status FROM dual)` +
+			entryColumns.Oracle + `
+			  JOIN a ON a.queue_date = e.queue_date
+			 WHERE (a.queue_key IS NULL OR e.queue_key = a.queue_key)
+			   AND (a.status IS NULL OR e.status = a.status)
+			 ORDER BY e.queue_key, e.registered_at, e."NUMBER"
+		`,
+	}
+	callTimes = dialect.Query{
+		Postgres: `--sql
+			SELECT e.called_at
+			  FROM public.queue_entries e
+			 WHERE e.queue_date = :1::DATE
+			   AND e.queue_key = :2
+			   AND e.called_at IS NOT NULL
+		`,
+		Oracle: `--sql
+			SELECT e.called_at
+			  FROM queue_entries e
+			 WHERE e.queue_date = TO_DATE(:1, 'YYYY-MM-DD')
+			   AND e.queue_key = :2
+			   AND e.called_at IS NOT NULL
+		`,
+	}
+	// callNext claims the first waiting walk-in of either queue, SKIP LOCKED lets doctors of a
+	// department call at the same time without being handed the same patient. Oracle cannot skip
+	// locked rows of a limited subquery, a doctor calling at the same time waits and the update
+	// restarts on the next walk-in
+	callNext = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.queue_entries SET status = 'called', called_by = :1, called_at = NOW(), call_id = :2, updated_at = NOW()
+			 WHERE id = (
+				SELECT e.id
+				  FROM public.queue_entries e
+				 WHERE e.queue_date = :3::DATE
+				   AND e.queue_key IN (:4, :5)
+				   AND e.status = 'waiting'
+				 ORDER BY e.registered_at, e.number
+				 LIMIT 1
+				   FOR UPDATE SKIP LOCKED
+			 )
+			   AND status = 'waiting'
+		`,
+		Oracle: `--sql
+			UPDATE queue_entries SET status = 'called', called_by = :1, called_at = SYSTIMESTAMP, call_id = :2, updated_at = SYSTIMESTAMP
+			 WHERE id = (
+				SELECT e.id
+				  FROM queue_entries e
+				 WHERE e.queue_date = TO_DATE(:3, 'YYYY-MM-DD')
+				   AND e.queue_key IN (:4, :5)
+				   AND e.status = 'waiting'
+				 ORDER BY e.registered_at, e."NUMBER"
+				 FETCH FIRST 1 ROWS ONLY
+			 )
+			   AND status = 'waiting'
+		`,
+	}
+	// convertEntry opens the in-progress visit of a called walk-in and records that it was
+	// checked in and started. The visit is only inserted for the locked entry while it is still called
+	// without a visit, so a changed entry changes no rows and leaves no visit behind. The Oracle block
+	// raises NothingChanged instead and rolls back the visit
+	convertEntry = dialect.Query{
+		Postgres: `--sql
+			WITH target AS (
+				SELECT e.id
+				  FROM public.queue_entries e
+				 WHERE e.id = :10 AND e.status = 'called' AND e.visit_id IS NULL
+				   FOR UPDATE
+			), visit AS (
+				INSERT INTO public.visits (id, patient_id, doctor_id, visit_type, visit_date, status, chief_complaint, notes)
+				SELECT :1::UUID, :2::UUID, :3::UUID, :4::visit_type, NOW(), 'in_progress', NULLIF(:5, ''), NULLIF(:6, '')
+				  FROM target
+				RETURNING id
+			), transitions AS (
+				INSERT INTO public.visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				SELECT visit.id, s.from_status::visit_status, s.to_status::visit_status, :7, :8, :9
+				  FROM visit, (VALUES (NULL, 'checked_in'), ('checked_in', 'in_progress')) AS s(from_status, to_status)
+			)
+			UPDATE public.queue_entries e SET visit_id = visit.id, updated_at = NOW()
+			  FROM target, visit
+			 WHERE e.id = target.id
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_visit visits.id%TYPE := :1;
+				v_actor_id visit_status_transitions.actor_id%TYPE := :7;
+				v_actor_name visit_status_transitions.actor_name%TYPE := :8;
+				v_reason visit_status_transitions.reason%TYPE := :9;
+				v_entry queue_entries.id%TYPE := :10;
+			BEGIN
+				INSERT INTO visits (id, patient_id, doctor_id, visit_type, visit_date, status, chief_complaint, notes)
+				VALUES (v_visit, :2, :3, :4, SYSTIMESTAMP, 'in_progress', :5, :6);
+				INSERT INTO visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				VALUES (v_visit, NULL, 'checked_in', v_actor_id, v_actor_name, v_reason);
+				INSERT INTO visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				VALUES (v_visit, 'checked_in', 'in_progress', v_actor_id, v_actor_name, v_reason);
+				UPDATE queue_entries SET visit_id = v_visit, updated_at = SYSTIMESTAMP
+				 WHERE id = v_entry AND status = 'called' AND visit_id IS NULL;
+				IF SQL%ROWCOUNT = 0 THEN
+					RAISE_APPLICATION_ERROR(-20000, 'queue entry changed while starting its visit');
+				END IF;
+			END;
+		`,
+	}
+	releaseCall = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.queue_entries SET status = 'waiting', called_by = NULL, called_at = NULL, call_id = NULL, updated_at = NOW()
+			 WHERE id = :1 AND status = 'called' AND visit_id IS NULL
+		`,
+		Oracle: `--sql
+			UPDATE queue_entries SET status = 'waiting', called_by = NULL, called_at = NULL, call_id = NULL, updated_at = SYSTIMESTAMP
+			 WHERE id = :1 AND status = 'called' AND visit_id IS NULL
+		`,
+	}
+	// skipCalledEntry skips a called walk-in and cancels the visit opened on calling them in one
+	// statement. Both rows are locked first and must still be in the status they were checked in,
+	// otherwise nothing changes and the Oracle block raises NothingChanged
+	skipCalledEntry = dialect.Query{
+		Postgres: `--sql
+			WITH target AS (
+				SELECT e.id AS entry_id, v.id AS visit_id
+				  FROM public.queue_entries e
+				  JOIN public.visits v ON v.id = e.visit_id
+				 WHERE e.id = :1 AND e.status = :2 AND v.status = 'in_progress' AND v.locked_at IS NULL
+				   FOR UPDATE OF e, v
+			), visit AS (
+				UPDATE public.visits SET status = 'cancelled', updated_at = NOW()
+				 WHERE id IN (SELECT visit_id FROM target)
+				RETURNING id
+			), transition AS (
+				INSERT INTO public.visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				SELECT visit.id, 'in_progress', 'cancelled', :4, :5, :6
+				  FROM visit
+			)
+			UPDATE public.queue_entries SET status = 'skipped', reason = NULLIF(:3, ''), updated_at = NOW()
+			 WHERE id IN (SELECT entry_id FROM target)
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_entry queue_entries.id%TYPE := :1;
+				v_status queue_entries.status%TYPE := :2;
+				v_reason queue_entries.reason%TYPE := :3;
+				v_visit visits.id%TYPE;
+			BEGIN
+				SELECT v.id INTO v_visit
+				  FROM queue_entries e
+				  JOIN visits v ON v.id = e.visit_id
+				 WHERE e.id = v_entry AND e.status = v_status AND v.status = 'in_progress' AND v.locked_at IS NULL
+				   FOR UPDATE;
+				UPDATE visits SET status = 'cancelled', updated_at = SYSTIMESTAMP
+				 WHERE id = v_visit;
+				INSERT INTO visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				VALUES (v_visit, 'in_progress', 'cancelled', :4, :5, :6);
+				UPDATE queue_entries SET status = 'skipped', reason = v_reason, updated_at = SYSTIMESTAMP
+				 WHERE id = v_entry;
+			EXCEPTION
+				WHEN NO_DATA_FOUND THEN
+					RAISE_APPLICATION_ERROR(-20000, 'queue entry or its visit changed while skipping');
+			END;
+		`,
+	}
+	skipEntry = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.queue_entries SET status = 'skipped', reason = NULLIF(:2, ''), updated_at = NOW()
+			 WHERE id = :1 AND status = :3
+		`,
+		Oracle: `--sql
+			MERGE INTO queue_entries e
+			USING (SELECT :1 AS id, :2 AS reason, :3 AS status FROM dual) a
+			   ON (e.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET e.status = 'skipped', e.reason = a.reason, e.updated_at = SYSTIMESTAMP
+				WHERE e.status = a.status
+		`,
+	}
+	// a requeued walk-in keeps its number and its place by registration time
+	requeueEntry = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.queue_entries SET
+				status = 'waiting',
+				called_by = NULL,
+				called_at = NULL,
+				call_id = NULL,
+				visit_id = NULL,
+				reason = NULL,
+				updated_at = NOW()
+			 WHERE id = :1 AND status = 'skipped'
+		`,
+		Oracle: `--sql
+			UPDATE queue_entries SET
+				status = 'waiting',
+				called_by = NULL,
+				called_at = NULL,
+				call_id = NULL,
+				visit_id = NULL,
+				reason = NULL,
+				updated_at = SYSTIMESTAMP
+			 WHERE id = :1 AND status = 'skipped'
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"callNext":          callNext,
+	"callTimes":         callTimes,
+	"convertEntry":      convertEntry,
+	"departmentDoctors": departmentDoctors,
+	"doctorByID":        doctorByID,
+	"entriesOfDay":      entriesOfDay,
+	"entryByCall":       entryByCall,
+	"entryByID":         entryByID,
+	"insertEntry":       insertEntry,
+	"patientByID":       patientByID,
+	"releaseCall":       releaseCall,
+	"requeueEntry":      requeueEntry,
+	"skipCalledEntry":   skipCalledEntry,
+	"skipEntry":         skipEntry,
+}
@@ -0,0 +1,34 @@
+package queue
+
+import (
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/events"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func AddQueueRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	broker *events.Broker,
+	simulation bool,
+) {
+	env := &queueEnv{
+		Interface: model,
+		Params:    wsParams,
+		Location:  appointments.LoadLocation(wsParams),
+		Events:    broker,
+	}
+	rg.POST("/queue", libGin.Gin(env.queuePostHandler(simulation)))
+	rg.GET("/queue", libGin.Gin(env.queueListHandler(simulation)))
+	rg.POST("/queue/call-next", libGin.Gin(env.queueCallNextHandler(simulation)))
+	rg.GET("/queue/:id", libGin.Gin(env.queueGetHandler(simulation)))
+	rg.POST("/queue/:id/skip", libGin.Gin(env.queueSkipHandler(simulation)))
+	rg.POST("/queue/:id/requeue", libGin.Gin(env.queueRequeueHandler(simulation)))
+}
@@ -1,262 +1,717 @@
 package therapyschedules
 
 import (
+	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
-	"charity-log/backend/models"
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/i18n"
+	"healthcare/utils/jalali"
+	"healthcare/utils/paging"
+	"healthcare/utils/storage"
+	"healthcare/utils/therapy"
+	"healthcare/utils/validation"
 
-	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
 	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
 )
 
-// TherapySchedulePostHandler handles POST requests for creating new therapy schedules
-func TherapySchedulePostHandler(c *gin.Context) {
-	var schedule models.TherapyScheduleRequest
-	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// MaxOccurrenceDays limits the range of expanded sessions
+const MaxOccurrenceDays = 366
+
+type therapyEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Tracker   *Tracker
+}
 
-	// Validate required fields
-	if schedule.PatientID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "patient_id is required"})
-		return
+// recurrence validates the recurrence rule, the excluded dates and the holiday policy of a schedule
+// and returns the excluded dates as stored
+func recurrence(schedule *models.TherapyScheduleRequest) (string, error) {
+	if schedule.RRule != "" {
+		if _, err := therapy.ParseRule(schedule.RRule); err != nil {
+			return "", fmt.Errorf("invalid rrule: %w", err)
+		}
+	}
+	exdates, err := therapy.ParseDates(schedule.ExDates)
+	if err != nil {
+		return "", fmt.Errorf("invalid exdates: %w", err)
 	}
-	if schedule.DoctorID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "doctor_id is required"})
-		return
+	if schedule.HolidayPolicy == "" {
+		schedule.HolidayPolicy = therapy.Skip
 	}
-	if schedule.TherapyType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "therapy_type is required"})
-		return
+	if !therapy.ValidPolicy(schedule.HolidayPolicy) {
+		return "", fmt.Errorf("holiday_policy must be skip, next or previous")
 	}
+	return therapy.FormatDates(exdates), nil
+}
 
-	// Set default values
-	if schedule.StartDate.IsZero() {
-		schedule.StartDate = time.Now()
+// day formats the date part of t, the zero time is stored as no date
+func day(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
-	if schedule.EndDate.IsZero() {
-		schedule.EndDate = schedule.StartDate.Add(30 * 24 * time.Hour) // Default 30 days
+	return t.Format("2006-01-02")
+}
+
+// load returns the schedule with its progress
+func (t *Tracker) load(id string) (*models.TherapyScheduleRow, error) {
+	row, err := t.Schedule(id)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_THERAPY_SCHEDULE", err.Error())
 	}
-	if schedule.Frequency == "" {
-		schedule.Frequency = "weekly"
+	if row == nil {
+		return nil, libError.NewWithDescription(http.StatusNotFound, i18n.ScheduleNotFound, "therapy schedule %s not found", id)
 	}
-	if schedule.Duration == 0 {
-		schedule.Duration = 30 // Default 30 minutes
+	return row, nil
+}
+
+// reload returns the schedule after a change, ending it when it is completed or expired by now
+func (t *Tracker) reload(id string) (*models.TherapyScheduleRow, error) {
+	row, err := t.load(id)
+	if err != nil {
+		return nil, err
 	}
-	if schedule.SessionCount == 0 {
-		schedule.SessionCount = 4 // Default 4 sessions
+	if err = t.Refresh(row); err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
 	}
+	return row, nil
+}
 
-	// TODO: Implement database insertion
-	// For now, return a mock response
-	response := models.TherapyScheduleResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Therapy schedule created successfully",
-		},
-	}
+type schedulesHandler struct {
+	Name    string
+	Tracker *Tracker
+}
 
-	c.JSON(http.StatusCreated, response)
+// returns handler title
+func (h schedulesHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name == "therapy-schedules-delete" {
+		body = libRequest.NoBinding
+	}
+	return handlers.HandlerParameters{
+		Title:          "therapy-schedules",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/therapy-schedules",
+	}
 }
 
-// TherapySchedulePutHandler handles PUT requests for updating therapy schedules
-func TherapySchedulePutHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid therapy schedule ID"})
-		return
+// runs after validating request
+func (h schedulesHandler) Initializer(req handlers.HandlerRequest[models.TherapyScheduleRequest, *models.TherapyScheduleResponse]) error {
+	schedule := req.Request
+	if h.Name != "therapy-schedules-post" {
+		schedule.ID = req.W.Parser.GetUrlParam("id")
+		if schedule.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidScheduleID, "therapy schedule id is required")
+		}
+	}
+	var skip []string
+	switch h.Name {
+	case "therapy-schedules-post":
+	case "therapy-schedules-put":
+		// the patient and doctor of a schedule do not change
+		skip = []string{"patient_id", "doctor_id"}
+	default:
+		return nil
+	}
+	calendar, err := jalali.FromRequest(req.W, schedule.Calendar)
+	if err != nil {
+		return err
 	}
-
-	var schedule models.TherapyScheduleRequest
-	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// TODO: Implement database update
-	// For now, return a mock response
-	response := models.TherapyScheduleResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Therapy schedule updated successfully",
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// TherapyScheduleDeleteHandler handles DELETE requests for therapy schedules
-func TherapyScheduleDeleteHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid therapy schedule ID"})
-		return
-	}
-
-	// TODO: Implement database deletion
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Therapy schedule deleted successfully",
-		"id":      idStr,
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// TherapyScheduleGetHandler handles GET requests for a specific therapy schedule
-func TherapyScheduleGetHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid therapy schedule ID"})
-		return
-	}
-
-	// TODO: Implement database retrieval
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Therapy schedule retrieved successfully",
-		"data": gin.H{
-			"id":            idStr,
-			"patient_id":    "1",
-			"doctor_id":     "1",
-			"therapy_type":  "Physical Therapy",
-			"description":   "Post-surgery rehabilitation",
-			"start_date":    time.Now(),
-			"end_date":      time.Now().Add(30 * 24 * time.Hour),
-			"frequency":     "weekly",
-			"instructions":  "Perform exercises as directed",
-			"is_active":     true,
-			"duration":      30,
-			"session_count": 4,
-			"created_at":    time.Now().Add(-24 * time.Hour),
-			"updated_at":    time.Now(),
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// TherapyScheduleGetAllHandler handles GET requests for all therapy schedules
-func TherapyScheduleGetAllHandler(c *gin.Context) {
-	// Get query parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-	patientID := c.Query("patient_id")
-	doctorID := c.Query("doctor_id")
-	therapyType := c.Query("therapy_type")
-	isActive := c.Query("is_active")
-
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	// TODO: Implement database retrieval with filters
-	// For now, return mock data
-	mockSchedules := []gin.H{
-		{
-			"id":            "1",
-			"patient_id":    "1",
-			"doctor_id":     "1",
-			"therapy_type":  "Physical Therapy",
-			"description":   "Post-surgery rehabilitation",
-			"start_date":    time.Now(),
-			"end_date":      time.Now().Add(30 * 24 * time.Hour),
-			"frequency":     "weekly",
-			"instructions":  "Perform exercises as directed",
-			"is_active":     true,
-			"duration":      30,
-			"session_count": 4,
-			"created_at":    time.Now().Add(-24 * time.Hour),
-			"updated_at":    time.Now(),
-		},
-		{
-			"id":            "2",
-			"patient_id":    "2",
-			"doctor_id":     "1",
-			"therapy_type":  "Speech Therapy",
-			"description":   "Language development",
-			"start_date":    time.Now().Add(-7 * 24 * time.Hour),
-			"end_date":      time.Now().Add(23 * 24 * time.Hour),
-			"frequency":     "twice weekly",
-			"instructions":  "Practice pronunciation exercises",
-			"is_active":     true,
-			"duration":      45,
-			"session_count": 8,
-			"created_at":    time.Now().Add(-7 * 24 * time.Hour),
-			"updated_at":    time.Now().Add(-1 * time.Hour),
-		},
-	}
-
-	// Apply basic filtering
-	filteredSchedules := mockSchedules
-	if patientID != "" {
-		var filtered []gin.H
-		for _, schedule := range filteredSchedules {
-			if schedule["patient_id"] == patientID {
-				filtered = append(filtered, schedule)
+	start, err := jalali.ParseDay(calendar, schedule.StartDate, "start_date", time.UTC)
+	if err != nil {
+		return err
+	}
+	end, err := jalali.ParseDay(calendar, schedule.EndDate, "end_date", time.UTC)
+	if err != nil {
+		return err
+	}
+	if h.Name == "therapy-schedules-post" {
+		// Set default values, a recurrence rule sets its own end
+		if start.IsZero() {
+			start = h.Tracker.today()
+		}
+		if schedule.RRule == "" {
+			if end.IsZero() {
+				end = start.Add(30 * 24 * time.Hour) // Default 30 days
+			}
+			if schedule.Frequency == "" {
+				schedule.Frequency = "weekly"
+			}
+			if schedule.SessionCount == 0 {
+				schedule.SessionCount = 4 // Default 4 sessions
 			}
 		}
-		filteredSchedules = filtered
+		if schedule.Duration == 0 {
+			schedule.Duration = 30 // Default 30 minutes
+		}
+	}
+	// the days are stored in the gregorian calendar
+	schedule.StartDate, schedule.EndDate = day(start), day(end)
+
+	fields, err := validation.Check(schedule, skip...)
+	if !end.IsZero() && end.Before(start) {
+		fields = append(fields, validation.FieldError{
+			Field:       "end_date",
+			Code:        i18n.ValidationNotBefore,
+			Rule:        "gtefield",
+			Param:       "start_date",
+			Description: "end_date must not be before start_date",
+		})
+	}
+	if err := validation.Fail(fields, err); err != nil {
+		return err
 	}
+	if _, err := recurrence(schedule); err != nil {
+		return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidRequest, "%s", err.Error())
+	}
+	return nil
+}
 
-	if doctorID != "" {
-		var filtered []gin.H
-		for _, schedule := range filteredSchedules {
-			if schedule["doctor_id"] == doctorID {
-				filtered = append(filtered, schedule)
+// Handler stores the schedule and returns it with its progress, a schedule whose new plan is already
+// completed or over ends right away and a deleted one is cancelled and kept with its sessions
+func (h schedulesHandler) Handler(req handlers.HandlerRequest[models.TherapyScheduleRequest, *models.TherapyScheduleResponse]) (*models.TherapyScheduleResponse, error) {
+	schedule := req.Request
+	switch h.Name {
+	case "therapy-schedules-post":
+		exdates, _ := recurrence(schedule)
+		id := storage.NewID()
+		result, err := req.Core.GetDB().InsertRow(insertSchedule.SQL(), id, schedule.PatientID, schedule.DoctorID,
+			schedule.TherapyType, schedule.Description, schedule.StartDate, schedule.EndDate, schedule.Frequency,
+			schedule.Instructions, schedule.Duration, schedule.SessionCount, schedule.RRule, exdates, schedule.HolidayPolicy)
+		if err != nil {
+			if dialect.Is(err, dialect.ForeignKeyViolation, "") {
+				return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.PatientOrDoctorMissing, "patient or doctor not found")
 			}
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		row, err := h.Tracker.load(id)
+		if err != nil {
+			return nil, err
+		}
+		req.Response = &models.TherapyScheduleResponse{
+			Result:   libQuery.GetDmlResult(result, nil),
+			Schedule: row,
+		}
+		req.Response.Result.Message = "Therapy schedule created successfully"
+		return req.Response, nil
+
+	case "therapy-schedules-put":
+		exdates, _ := recurrence(schedule)
+		result, err := req.Core.GetDB().InsertRow(updateSchedule.SQL(), schedule.ID, schedule.TherapyType, schedule.Description,
+			schedule.StartDate, schedule.EndDate, schedule.Frequency, schedule.Instructions, schedule.Duration,
+			schedule.SessionCount, schedule.RRule, exdates, schedule.HolidayPolicy)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, i18n.ScheduleNotFound, "therapy schedule %s not found", schedule.ID)
+		}
+		row, err := h.Tracker.reload(schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		req.Response = &models.TherapyScheduleResponse{
+			Result:   libQuery.GetDmlResult(result, nil),
+			Schedule: row,
 		}
-		filteredSchedules = filtered
+		req.Response.Result.Message = "Therapy schedule updated successfully"
+		return req.Response, nil
+
+	case "therapy-schedules-delete":
+		result, err := req.Core.GetDB().InsertRow(cancelSchedule.SQL(), schedule.ID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		row, err := h.Tracker.load(schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, i18n.ScheduleEnded, "therapy schedule is already %s", row.Status)
+		}
+		req.Response = &models.TherapyScheduleResponse{
+			Result:   libQuery.GetDmlResult(result, nil),
+			Schedule: row,
+		}
+		req.Response.Result.Message = "Therapy schedule cancelled successfully"
+		return req.Response, nil
 	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
 
-	if therapyType != "" {
-		var filtered []gin.H
-		for _, schedule := range filteredSchedules {
-			if schedule["therapy_type"] == therapyType {
-				filtered = append(filtered, schedule)
-			}
+// Simulation returns a simulated response
+func (h schedulesHandler) Simulation(req handlers.HandlerRequest[models.TherapyScheduleRequest, *models.TherapyScheduleResponse]) (*models.TherapyScheduleResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h schedulesHandler) Finalizer(req handlers.HandlerRequest[models.TherapyScheduleRequest, *models.TherapyScheduleResponse]) {
+}
+
+type schedulesQueryHandler struct {
+	Name    string
+	Tracker *Tracker
+}
+
+// returns handler title
+func (h schedulesQueryHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "therapy-schedules",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/therapy-schedules",
+	}
+}
+
+// runs after validating request
+func (h schedulesQueryHandler) Initializer(req handlers.HandlerRequest[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow]]) error {
+	if h.Name == "therapy-schedules-get" {
+		req.Request.ID = req.W.Parser.GetUrlParam("id")
+		if req.Request.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidScheduleID, "therapy schedule id is required")
 		}
-		filteredSchedules = filtered
 	}
+	return validation.Error(req.Request)
+}
 
-	if isActive != "" {
-		var filtered []gin.H
-		for _, schedule := range filteredSchedules {
-			if schedule["is_active"] == (isActive == "true") {
-				filtered = append(filtered, schedule)
-			}
+// scheduleSort maps the fields the schedule list is sorted by to their columns
+var scheduleSort = map[string]string{
+	"id":            "t.id",
+	"patient_id":    "t.patient_id",
+	"doctor_id":     "t.doctor_id",
+	"therapy_type":  "t.therapy_type",
+	"start_date":    "t.start_date",
+	"end_date":      "t.end_date",
+	"duration":      "t.duration",
+	"session_count": "t.session_count",
+	"status":        "t.status",
+	"created_at":    "t.created_at",
+	"updated_at":    "t.updated_at",
+	"patient_name":  "patient_name",
+	"doctor_name":   "doctor_name",
+}
+
+// Handler returns the schedule, or the page of the schedules matching the filters, with their
+// progress, the list is sorted and cut to the page in its query
+func (h schedulesQueryHandler) Handler(req handlers.HandlerRequest[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow]]) (*paging.Page[models.TherapyScheduleRow], error) {
+	switch h.Name {
+	case "therapy-schedules-get":
+		row, err := h.Tracker.reload(req.Request.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &paging.Page[models.TherapyScheduleRow]{Items: []models.TherapyScheduleRow{*row}, Total: 1}, nil
+
+	case "therapy-schedules-get-all":
+		filter := req.Request
+		clause, err := filter.Clause(scheduleSort, "t.start_date DESC, t.id")
+		if err != nil {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidPage, "%s", err.Error())
+		}
+		args := []any{filter.PatientID, filter.DoctorID, filter.TherapyType, filter.IsActive, filter.Status}
+		rows, err := libQuery.GetQuery[models.TherapyScheduleRow](scheduleList.Append(clause).SQL(), req.Core.GetDB(), args...)
+		if err == nil {
+			err = h.Tracker.annotate(rows)
+		}
+		var count []models.TherapyCountRow
+		if err == nil {
+			count, err = libQuery.GetQuery[models.TherapyCountRow](scheduleCount.SQL(), req.Core.GetDB(), args...)
+		}
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_THERAPY_SCHEDULE", err.Error())
+		}
+		page := &paging.Page[models.TherapyScheduleRow]{Items: rows}
+		if page.Items == nil {
+			page.Items = []models.TherapyScheduleRow{}
+		}
+		if len(count) > 0 {
+			page.Total = count[0].Count
 		}
-		filteredSchedules = filtered
+		return page, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h schedulesQueryHandler) Simulation(req handlers.HandlerRequest[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow]]) (*paging.Page[models.TherapyScheduleRow], error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h schedulesQueryHandler) Finalizer(req handlers.HandlerRequest[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow]]) {
+}
+
+type sessionPostHandler struct {
+	Tracker *Tracker
+}
+
+// returns handler title
+func (h sessionPostHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "therapy-sessions",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/therapy-schedules/:id/sessions",
 	}
+}
+
+// runs after validating request
+func (h sessionPostHandler) Initializer(req handlers.HandlerRequest[models.TherapySessionRequest, *models.TherapySessionResponse]) error {
+	return validation.Error(req.Request)
+}
+
+// Handler records an attended or missed session, a schedule whose sessions are all attended is
+// completed right away
+func (h sessionPostHandler) Handler(req handlers.HandlerRequest[models.TherapySessionRequest, *models.TherapySessionResponse]) (*models.TherapySessionResponse, error) {
+	user, err := ums.CurrentUser(req.W, req.Core)
+	if err != nil {
+		return nil, err
+	}
+	session := req.Request
+	calendar, err := jalali.FromRequest(req.W, session.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	if session.SessionDate == "" {
+		session.SessionDate = jalali.FormatDate(calendar, h.Tracker.today())
+	}
+	date, err := jalali.ParseDay(calendar, session.SessionDate, "session_date", time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := h.Tracker.load(req.W.Parser.GetUrlParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != therapy.Active {
+		return nil, libError.NewWithDescription(http.StatusConflict, i18n.ScheduleEnded, "therapy schedule is %s", schedule.Status)
+	}
+	if date.Before(schedule.StartDate) || (!schedule.EndDate.IsZero() && date.After(schedule.EndDate)) {
+		return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.SessionOutside, "session_date is outside the therapy schedule")
+	}
+	if date.After(h.Tracker.today()) {
+		return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.SessionInFuture, "session_date is in the future")
+	}
+
+	result, err := req.Core.GetDB().InsertRow(upsertSession.SQL(), schedule.ID, day(date), session.Status, session.Notes, user.UserId)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+	}
+	if schedule, err = h.Tracker.reload(schedule.ID); err != nil {
+		return nil, err
+	}
+	req.Response = &models.TherapySessionResponse{
+		Result:   libQuery.GetDmlResult(result, nil),
+		Schedule: schedule,
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h sessionPostHandler) Simulation(req handlers.HandlerRequest[models.TherapySessionRequest, *models.TherapySessionResponse]) (*models.TherapySessionResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h sessionPostHandler) Finalizer(req handlers.HandlerRequest[models.TherapySessionRequest, *models.TherapySessionResponse]) {
+}
+
+type sessionsGetHandler struct {
+	Tracker *Tracker
+}
+
+// returns handler title
+func (h sessionsGetHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "therapy-sessions",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/therapy-schedules/:id/sessions",
+	}
+}
+
+// runs after validating request
+func (h sessionsGetHandler) Initializer(req handlers.HandlerRequest[models.TherapySessionRequest, *[]models.TherapySessionRow]) error {
+	return nil
+}
+
+// Handler returns the recorded sessions of the schedule, the list is paged by the paging middleware
+func (h sessionsGetHandler) Handler(req handlers.HandlerRequest[models.TherapySessionRequest, *[]models.TherapySessionRow]) (*[]models.TherapySessionRow, error) {
+	schedule, err := h.Tracker.load(req.W.Parser.GetUrlParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := libQuery.GetQuery[models.TherapySessionRow](sessionList.SQL(), req.Core.GetDB(), schedule.ID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_THERAPY_SESSIONS", err.Error())
+	}
+	if rows == nil {
+		rows = []models.TherapySessionRow{}
+	}
+	return &rows, nil
+}
+
+// Simulation returns a simulated response
+func (h sessionsGetHandler) Simulation(req handlers.HandlerRequest[models.TherapySessionRequest, *[]models.TherapySessionRow]) (*[]models.TherapySessionRow, error) {
+	return req.Response, nil
+}
 
-	// Calculate pagination
-	total := len(filteredSchedules)
-	offset := (page - 1) * limit
-	end := offset + limit
+// runs after sending back response
+func (h sessionsGetHandler) Finalizer(req handlers.HandlerRequest[models.TherapySessionRequest, *[]models.TherapySessionRow]) {
+}
+
+type occurrencesHandler struct {
+	Tracker *Tracker
+}
 
-	if offset >= total {
-		filteredSchedules = []gin.H{}
-	} else if end > total {
-		filteredSchedules = filteredSchedules[offset:]
-	} else {
-		filteredSchedules = filteredSchedules[offset:end]
+// returns handler title
+func (h occurrencesHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "therapy-occurrences",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/therapy-schedules/:id/occurrences",
+	}
+}
+
+// runs after validating request
+func (h occurrencesHandler) Initializer(req handlers.HandlerRequest[models.TherapyOccurrencesRequest, *models.TherapyOccurrencesResponse]) error {
+	return nil
+}
+
+// Handler expands the sessions of the schedule between from and to, sessions moved off a clinic
+// holiday carry their original date
+func (h occurrencesHandler) Handler(req handlers.HandlerRequest[models.TherapyOccurrencesRequest, *models.TherapyOccurrencesResponse]) (*models.TherapyOccurrencesResponse, error) {
+	schedule, err := h.Tracker.load(req.W.Parser.GetUrlParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := jalali.FromRequest(req.W, req.Request.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	from, to := schedule.StartDate, schedule.StartDate.AddDate(0, 0, MaxOccurrenceDays-1)
+	if req.Request.From != "" {
+		if from, err = jalali.ParseDay(calendar, req.Request.From, "from", time.UTC); err != nil {
+			return nil, err
+		}
+		to = from.AddDate(0, 0, MaxOccurrenceDays-1)
+	}
+	if req.Request.To != "" {
+		if to, err = jalali.ParseDay(calendar, req.Request.To, "to", time.UTC); err != nil {
+			return nil, err
+		}
+	}
+	if to.Before(from) || to.Sub(from) >= MaxOccurrenceDays*24*time.Hour {
+		return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidDateRange, "from and to must span 1 to %d days", MaxOccurrenceDays)
 	}
 
-	response := gin.H{
-		"success": true,
-		"message": "Therapy schedules retrieved successfully",
-		"data":    filteredSchedules,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
+	sessions, err := h.Tracker.Sessions(schedule, from, to)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_THERAPY_SESSIONS", err.Error())
 	}
+	req.Response = &models.TherapyOccurrencesResponse{
+		Result:   libQuery.DmlResult{Success: true},
+		Calendar: calendar,
+		Sessions: sessions,
+	}
+	if calendar != jalali.Gregorian {
+		req.Response.Dates = make([]string, len(sessions))
+		for i, session := range sessions {
+			req.Response.Dates[i] = jalali.FormatDate(calendar, session.Date)
+		}
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h occurrencesHandler) Simulation(req handlers.HandlerRequest[models.TherapyOccurrencesRequest, *models.TherapyOccurrencesResponse]) (*models.TherapyOccurrencesResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h occurrencesHandler) Finalizer(req handlers.HandlerRequest[models.TherapyOccurrencesRequest, *models.TherapyOccurrencesResponse]) {
+}
+
+// therapySchedulePostHandler godoc
+// @Summary Create a new therapy schedule
+// @Description Create a therapy schedule, a recurrence rule sets its own end and the others default to 4 weekly sessions in 30 days
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of start_date and end_date" Enums(gregorian, jalali)
+// @Param schedule body models.TherapyScheduleRequest true "Therapy schedule"
+// @Router /therapy-schedules [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.TherapyScheduleResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapySchedulePostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyScheduleRequest, *models.TherapyScheduleResponse, schedulesHandler](env.Interface, schedulesHandler{Name: "therapy-schedules-post", Tracker: env.Tracker}, simulation)
+}
+
+// therapySchedulePutHandler godoc
+// @Summary Update a therapy schedule
+// @Description Update a therapy schedule, its patient and doctor do not change and a schedule whose new plan is already completed or over ends right away
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Therapy schedule ID"
+// @Param X-Calendar header string false "Calendar of start_date and end_date" Enums(gregorian, jalali)
+// @Param schedule body models.TherapyScheduleRequest true "Therapy schedule"
+// @Router /therapy-schedules/:id [put]
+// @Security OAuth2Password
+// @Success 200 {object} models.TherapyScheduleResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapySchedulePutHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyScheduleRequest, *models.TherapyScheduleResponse, schedulesHandler](env.Interface, schedulesHandler{Name: "therapy-schedules-put", Tracker: env.Tracker}, simulation)
+}
+
+// therapyScheduleDeleteHandler godoc
+// @Summary Cancel a therapy schedule
+// @Description Cancel an active therapy schedule, it is kept with its sessions
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Therapy schedule ID"
+// @Router /therapy-schedules/:id [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.TherapyScheduleResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapyScheduleDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyScheduleRequest, *models.TherapyScheduleResponse, schedulesHandler](env.Interface, schedulesHandler{Name: "therapy-schedules-delete", Tracker: env.Tracker}, simulation)
+}
+
+// therapyScheduleGetHandler godoc
+// @Summary Get a therapy schedule by ID
+// @Description Get a therapy schedule with its progress
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Therapy schedule ID"
+// @Router /therapy-schedules/:id [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.TherapyScheduleRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapyScheduleGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow], schedulesQueryHandler](env.Interface, schedulesQueryHandler{Name: "therapy-schedules-get", Tracker: env.Tracker}, simulation)
+}
+
+// therapyScheduleGetAllHandler godoc
+// @Summary Get all therapy schedules
+// @Description Get the therapy schedules matching the filters with their progress, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param patient_id query string false "Patient ID"
+// @Param doctor_id query string false "Doctor ID"
+// @Param therapy_type query string false "Therapy type"
+// @Param is_active query bool false "Active schedules only"
+// @Param status query string false "Status" Enums(active, completed, expired, cancelled)
+// @Param _start query int false "First item of the page"
+// @Param _end query int false "Item after the page"
+// @Param _sort query string false "Field to sort by"
+// @Param _order query string false "asc or desc"
+// @Router /therapy-schedules/all [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.TherapyScheduleRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapyScheduleGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyScheduleFilter, *paging.Page[models.TherapyScheduleRow], schedulesQueryHandler](env.Interface, schedulesQueryHandler{Name: "therapy-schedules-get-all", Tracker: env.Tracker}, simulation)
+}
+
+// therapySessionPostHandler godoc
+// @Summary Record a therapy session
+// @Description Record an attended or missed session of an active schedule, recording the same day again replaces its outcome. session_date is in the calendar of the request and defaults to today
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of session_date" Enums(gregorian, jalali)
+// @Param id path string true "Therapy schedule ID"
+// @Param session body models.TherapySessionRequest true "Session"
+// @Router /therapy-schedules/:id/sessions [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.TherapySessionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapySessionPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapySessionRequest, *models.TherapySessionResponse, sessionPostHandler](env.Interface, sessionPostHandler{Tracker: env.Tracker}, simulation)
+}
+
+// therapySessionGetAllHandler godoc
+// @Summary Get the sessions of a therapy schedule
+// @Description Get the recorded sessions of a therapy schedule, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Therapy schedule ID"
+// @Router /therapy-schedules/:id/sessions [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.TherapySessionRow
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapySessionGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapySessionRequest, *[]models.TherapySessionRow, sessionsGetHandler](env.Interface, sessionsGetHandler{Tracker: env.Tracker}, simulation)
+}
 
-	c.JSON(http.StatusOK, response)
+// therapyOccurrencesHandler godoc
+// @Summary Expand the sessions of a therapy schedule
+// @Description Expand the sessions of a therapy schedule between from and to, at most 366 days, sessions moved off a clinic holiday carry their original date
+// @Tags therapy-schedules
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param id path string true "Therapy schedule ID"
+// @Param from query string false "First day, defaults to the start of the schedule"
+// @Param to query string false "Last day"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /therapy-schedules/:id/occurrences [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.TherapyOccurrencesResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) therapyOccurrencesHandler(simulation bool) any {
+	return handlers.BaseHandler[models.TherapyOccurrencesRequest, *models.TherapyOccurrencesResponse, occurrencesHandler](env.Interface, occurrencesHandler{Tracker: env.Tracker}, simulation)
 }
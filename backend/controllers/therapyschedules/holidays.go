@@ -0,0 +1,199 @@
+package therapyschedules
+
+import (
+	"net/http"
+	"time"
+
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/jalali"
+	"healthcare/utils/validation"
+
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type holidaysHandler struct {
+	Name    string
+	Tracker *Tracker
+}
+
+// returns handler title
+func (h holidaysHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name == "clinic-holidays-delete" {
+		body = libRequest.Query
+	}
+	return handlers.HandlerParameters{
+		Title:          "clinic-holidays",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/clinic-holidays",
+	}
+}
+
+// runs after validating request
+func (h holidaysHandler) Initializer(req handlers.HandlerRequest[models.ClinicHolidayRequest, *models.ClinicHolidayResponse]) error {
+	if h.Name == "clinic-holidays-delete" {
+		// only the day is needed to delete a holiday
+		req.Request.Day = req.W.Parser.GetUrlParam("day")
+		return validation.Error(req.Request, "name")
+	}
+	return validation.Error(req.Request)
+}
+
+// Handler adds, renames or deletes a clinic holiday, sessions on it are skipped or moved according
+// to the policy of their schedule
+func (h holidaysHandler) Handler(req handlers.HandlerRequest[models.ClinicHolidayRequest, *models.ClinicHolidayResponse]) (*models.ClinicHolidayResponse, error) {
+	holiday := req.Request
+	calendar, err := jalali.FromRequest(req.W, holiday.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	date, err := jalali.ParseDay(calendar, holiday.Day, "day", time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	switch h.Name {
+	case "clinic-holidays-post":
+		result, err := req.Core.GetDB().InsertRow(insertHoliday.SQL(), day(date), holiday.Name)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response = &models.ClinicHolidayResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+			Day:    day(date),
+		}
+		return req.Response, nil
+
+	case "clinic-holidays-delete":
+		result, err := req.Core.GetDB().InsertRow(deleteHoliday.SQL(), day(date))
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_DELETE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, i18n.HolidayNotFound, "clinic holiday %s not found", day(date))
+		}
+		req.Response = &models.ClinicHolidayResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+			Day:    day(date),
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h holidaysHandler) Simulation(req handlers.HandlerRequest[models.ClinicHolidayRequest, *models.ClinicHolidayResponse]) (*models.ClinicHolidayResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h holidaysHandler) Finalizer(req handlers.HandlerRequest[models.ClinicHolidayRequest, *models.ClinicHolidayResponse]) {
+}
+
+type holidaysQueryHandler struct {
+	Tracker *Tracker
+}
+
+// returns handler title
+func (h holidaysQueryHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "clinic-holidays",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/clinic-holidays",
+	}
+}
+
+// runs after validating request
+func (h holidaysQueryHandler) Initializer(req handlers.HandlerRequest[models.ClinicHolidayFilter, *[]models.ClinicHolidayRow]) error {
+	return validation.Error(req.Request)
+}
+
+// Handler returns the clinic holidays, optionally of one year, the list is paged by the paging middleware
+func (h holidaysQueryHandler) Handler(req handlers.HandlerRequest[models.ClinicHolidayFilter, *[]models.ClinicHolidayRow]) (*[]models.ClinicHolidayRow, error) {
+	rows, err := libQuery.GetQuery[models.ClinicHolidayRow](holidayList.SQL(), req.Core.GetDB(), req.Request.Year)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_CLINIC_HOLIDAYS", err.Error())
+	}
+	if rows == nil {
+		rows = []models.ClinicHolidayRow{}
+	}
+	return &rows, nil
+}
+
+// Simulation returns a simulated response
+func (h holidaysQueryHandler) Simulation(req handlers.HandlerRequest[models.ClinicHolidayFilter, *[]models.ClinicHolidayRow]) (*[]models.ClinicHolidayRow, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h holidaysQueryHandler) Finalizer(req handlers.HandlerRequest[models.ClinicHolidayFilter, *[]models.ClinicHolidayRow]) {
+}
+
+// clinicHolidayGetAllHandler godoc
+// @Summary Get clinic holidays
+// @Description Get the days the clinic is closed, optionally of one year, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags clinic-holidays
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param year query int false "Gregorian year"
+// @Param _start query int false "First item of the page"
+// @Param _end query int false "Item after the page"
+// @Param _sort query string false "Field to sort by"
+// @Param _order query string false "asc or desc"
+// @Router /clinic-holidays/all [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.ClinicHolidayRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) clinicHolidayGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[models.ClinicHolidayFilter, *[]models.ClinicHolidayRow, holidaysQueryHandler](env.Interface, holidaysQueryHandler{Tracker: env.Tracker}, simulation)
+}
+
+// clinicHolidayPostHandler godoc
+// @Summary Add a clinic holiday
+// @Description Add or rename a day the clinic is closed, sessions on it are skipped or moved according to the policy of their schedule. day is in the calendar of the request
+// @Tags clinic-holidays
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of day" Enums(gregorian, jalali)
+// @Param holiday body models.ClinicHolidayRequest true "Clinic holiday"
+// @Router /clinic-holidays [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.ClinicHolidayResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) clinicHolidayPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.ClinicHolidayRequest, *models.ClinicHolidayResponse, holidaysHandler](env.Interface, holidaysHandler{Name: "clinic-holidays-post", Tracker: env.Tracker}, simulation)
+}
+
+// clinicHolidayDeleteHandler godoc
+// @Summary Delete a clinic holiday
+// @Description Delete a day the clinic is closed, day is in the calendar of the request
+// @Tags clinic-holidays
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param X-Calendar header string false "Calendar of day" Enums(gregorian, jalali)
+// @Param day path string true "Day"
+// @Param calendar query string false "Calendar of day, wins over the header" Enums(gregorian, jalali)
+// @Router /clinic-holidays/:day [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.ClinicHolidayResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env therapyEnv) clinicHolidayDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.ClinicHolidayRequest, *models.ClinicHolidayResponse, holidaysHandler](env.Interface, holidaysHandler{Name: "clinic-holidays-delete", Tracker: env.Tracker}, simulation)
+}
@@ -0,0 +1,217 @@
+package therapyschedules
+
+import (
+	"context"
+	"fmt"
+	"healthcare/models"
+	"healthcare/utils/therapy"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Tracker computes the progress of therapy schedules and ends the ones that are completed or expired
+type Tracker struct {
+	Core     requestCore.RequestCoreInterface
+	Location *time.Location
+}
+
+// today returns the current day in the clinic time zone, at midnight UTC like the dates of schedules
+func (t *Tracker) today() time.Time {
+	now := time.Now().In(t.Location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// holidays returns the clinic holidays as dates
+func (t *Tracker) holidays() (map[time.Time]bool, error) {
+	rows, err := libQuery.GetQuery[models.ClinicHolidayRow](holidayList.SQL(), t.Core.GetDB(), 0)
+	if err != nil {
+		return nil, err
+	}
+	days := map[time.Time]bool{}
+	for _, row := range rows {
+		days[time.Date(row.Day.Year(), row.Day.Month(), row.Day.Day(), 0, 0, 0, 0, time.UTC)] = true
+	}
+	return days, nil
+}
+
+// plan returns what the progress of the schedule is measured against, rules and dates are validated
+// when a schedule is stored so a broken one falls back to the frequency
+func plan(row *models.TherapyScheduleRow, holidays map[time.Time]bool) therapy.Plan {
+	p := therapy.Plan{
+		Frequency:     string(row.Frequency),
+		HolidayPolicy: row.HolidayPolicy,
+		Holidays:      holidays,
+		Start:         row.StartDate,
+		End:           row.EndDate,
+		SessionCount:  row.SessionCount,
+	}
+	if row.RRule != "" {
+		p.Rule, _ = therapy.ParseRule(string(row.RRule))
+	}
+	if row.ExDates != "" {
+		p.ExDates, _ = therapy.ParseDates([]string{string(row.ExDates)})
+	}
+	return p
+}
+
+// annotate sets the progress of the schedules on today
+func (t *Tracker) annotate(rows []models.TherapyScheduleRow) error {
+	holidays, err := t.holidays()
+	if err != nil {
+		return err
+	}
+	today := t.today()
+	for i := range rows {
+		rows[i].Progress = therapy.Compute(plan(&rows[i], holidays), rows[i].Attended, rows[i].Missed, today)
+	}
+	return nil
+}
+
+// Schedule returns the schedule with its progress, nil when it does not exist
+func (t *Tracker) Schedule(id string) (*models.TherapyScheduleRow, error) {
+	rows, err := libQuery.GetQuery[models.TherapyScheduleRow](scheduleByID.SQL(), t.Core.GetDB(), id)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	if err = t.annotate(rows); err != nil {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// end moves an active schedule to the status it has on today and reports whether it changed
+func (t *Tracker) end(row *models.TherapyScheduleRow, holidays map[time.Time]bool, today time.Time) (bool, error) {
+	if row.Status != therapy.Active {
+		return false, nil
+	}
+	status := therapy.Status(plan(row, holidays), row.Attended, today)
+	if status == therapy.Active {
+		return false, nil
+	}
+	result, err := t.Core.GetDB().InsertRow(endSchedule.SQL(), row.ID, status)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return false, err
+	}
+	row.Status, row.IsActive = status, false
+	return true, nil
+}
+
+// Refresh ends the schedule when it is completed or expired, e.g. right after a session was recorded
+func (t *Tracker) Refresh(row *models.TherapyScheduleRow) error {
+	holidays, err := t.holidays()
+	if err != nil {
+		return err
+	}
+	_, err = t.end(row, holidays, t.today())
+	return err
+}
+
+// UpdateStatuses ends every active schedule that is completed or expired and returns how many were ended,
+// it is meant to run periodically
+func (t *Tracker) UpdateStatuses(ctx context.Context) (int, error) {
+	rows, err := libQuery.GetQuery[models.TherapyScheduleRow](activeSchedules.SQL(), t.Core.GetDB())
+	if err != nil {
+		return 0, err
+	}
+	holidays, err := t.holidays()
+	if err != nil {
+		return 0, err
+	}
+	today := t.today()
+	ended := 0
+	for i := range rows {
+		if ctx.Err() != nil {
+			return ended, ctx.Err()
+		}
+		changed, err := t.end(&rows[i], holidays, today)
+		if err != nil {
+			return ended, fmt.Errorf("ending therapy schedule %s: %w", rows[i].ID, err)
+		}
+		if changed {
+			ended++
+		}
+	}
+	return ended, nil
+}
+
+// ActiveTherapies returns the number of active schedules, doctorID is optional
+func (t *Tracker) ActiveTherapies(doctorID string) (int, error) {
+	rows, err := libQuery.GetQuery[models.TherapyCountRow](activeCount.SQL(), t.Core.GetDB(), doctorID)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	return rows[0].Count, nil
+}
+
+// Sessions returns the sessions of the schedule from from up to and including to
+func (t *Tracker) Sessions(row *models.TherapyScheduleRow, from, to time.Time) ([]therapy.Session, error) {
+	holidays, err := t.holidays()
+	if err != nil {
+		return nil, err
+	}
+	sessions := []therapy.Session{}
+	for _, session := range plan(row, holidays).Sessions(to) {
+		if !session.Date.Before(from) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// SessionsOn returns the ids of the active schedules with a session on day
+func (t *Tracker) SessionsOn(day time.Time) (map[string]bool, error) {
+	rows, err := libQuery.GetQuery[models.TherapyScheduleRow](activeSchedules.SQL(), t.Core.GetDB())
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := t.holidays()
+	if err != nil {
+		return nil, err
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	ids := map[string]bool{}
+	for i := range rows {
+		sessions := plan(&rows[i], holidays).Sessions(day)
+		if n := len(sessions); n > 0 && sessions[n-1].Date.Equal(day) {
+			ids[rows[i].ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// CalendarTherapy is a therapy schedule with its sessions in a date range
+type CalendarTherapy struct {
+	Schedule models.TherapyScheduleRow
+	Sessions []therapy.Session
+}
+
+// CalendarSessions returns the schedules of a doctor or a patient with their sessions in [from, to) for
+// calendar feeds
+func (t *Tracker) CalendarSessions(doctorID, patientID string, from, to time.Time) ([]CalendarTherapy, error) {
+	rows, err := libQuery.GetQuery[models.TherapyScheduleRow](calendarSchedules.SQL(), t.Core.GetDB(), doctorID, patientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := t.holidays()
+	if err != nil {
+		return nil, err
+	}
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	therapies := make([]CalendarTherapy, 0, len(rows))
+	for i := range rows {
+		entry := CalendarTherapy{Schedule: rows[i]}
+		for _, session := range plan(&rows[i], holidays).Sessions(last) {
+			if !session.Date.Before(first) {
+				entry.Sessions = append(entry.Sessions, session)
+			}
+		}
+		therapies = append(therapies, entry)
+	}
+	return therapies, nil
+}
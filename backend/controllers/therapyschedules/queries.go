@@ -0,0 +1,361 @@
+package therapyschedules
+
+import "healthcare/utils/dialect"
+
+var (
+	scheduleColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				t.id,
+				t.patient_id,
+				t.doctor_id,
+				t.therapy_type,
+				COALESCE(t.description, '') AS description,
+				t.start_date,
+				COALESCE(t.end_date, DATE '0001-01-01') AS end_date,
+				COALESCE(t.frequency, '') AS frequency,
+				COALESCE(t.instructions, '') AS instructions,
+				t.status = 'active' AS is_active,
+				COALESCE(t.duration, 0) AS duration,
+				COALESCE(t.session_count, 0) AS session_count,
+				COALESCE(t.rrule, '') AS rrule,
+				COALESCE(t.exdates, '') AS exdates,
+				t.holiday_policy,
+				t.status,
+				COALESCE(t.ended_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS ended_at,
+				(SELECT COUNT(*) FROM public.therapy_sessions s WHERE s.schedule_id = t.id AND s.status = 'attended') AS attended,
+				(SELECT COUNT(*) FROM public.therapy_sessions s WHERE s.schedule_id = t.id AND s.status = 'missed') AS missed,
+				t.created_at,
+				t.updated_at,
+				COALESCE(p.full_name, '') AS patient_name,
+				COALESCE(pr.full_name, '') AS doctor_name
+			  FROM public.therapy_schedules t
+			  LEFT JOIN public.patients p ON p.id = t.patient_id
+			  LEFT JOIN public.doctors d ON d.id = t.doctor_id
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+		`,
+		Oracle: `--sql
+			SELECT
+				t.id,
+				t.patient_id,
+				t.doctor_id,
+				t.therapy_type,
+				t.description,
+				t.start_date,
+				COALESCE(t.end_date, DATE '0001-01-01') AS end_date,
+				t.frequency,
+				t.instructions,
+				CASE WHEN t.status = 'active' THEN 1 ELSE 0 END AS is_active,
+				COALESCE(t.duration, 0) AS duration,
+				COALESCE(t.session_count, 0) AS session_count,
+				t.rrule,
+				t.exdates,
+				t.holiday_policy,
+				t.status,
+				COALESCE(t.ended_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS ended_at,
+				(SELECT COUNT(*) FROM therapy_sessions s WHERE s.schedule_id = t.id AND s.status = 'attended') AS attended,
+				(SELECT COUNT(*) FROM therapy_sessions s WHERE s.schedule_id = t.id AND s.status = 'missed') AS missed,
+				t.created_at,
+				t.updated_at,
+				p.full_name AS patient_name,
+				pr.full_name AS doctor_name
+			  FROM therapy_schedules t
+			  LEFT JOIN patients p ON p.id = t.patient_id
+			  LEFT JOIN doctors d ON d.id = t.doctor_id
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+		`,
+	}
+	scheduleByID = dialect.Query{
+		Postgres: scheduleColumns.Postgres + `
+			 WHERE t.id::TEXT = :1
+		`,
+		Oracle: scheduleColumns.Oracle + `
+			 WHERE t.id = :1
+		`,
+	}
+	// scheduleList filters by patient, doctor, therapy type, activity and status, each filter is
+	// optional, the handler appends the order and page of the request
+	scheduleList = dialect.Query{
+		Postgres: scheduleColumns.Postgres + `
+			 WHERE (:1 = '' OR t.patient_id::TEXT = :1)
+			   AND (:2 = '' OR t.doctor_id::TEXT = :2)
+			   AND (:3 = '' OR t.therapy_type = :3)
+			   AND (:4 = '' OR (t.status = 'active') = (:4 = 'true'))
+			   AND (:5 = '' OR t.status = :5)
+		`,
+		Oracle: `
+			WITH a AS (SELECT :1 AS patient_id, :2 AS doctor_id, :3 AS therapy_type, :4 AS is_active, :5 AS status FROM dual)` +
+			scheduleColumns.Oracle + `
+			 CROSS JOIN a
+			 WHERE (a.patient_id IS NULL OR t.patient_id = a.patient_id)
+			   AND (a.doctor_id IS NULL OR t.doctor_id = a.doctor_id)
+			   AND (a.therapy_type IS NULL OR t.therapy_type = a.therapy_type)
+			   AND (a.is_active IS NULL
+			    OR (t.status = 'active' AND a.is_active = 'true')
+			    OR (t.status <> 'active' AND a.is_active <> 'true'))
+			   AND (a.status IS NULL OR t.status = a.status)
+		`,
+	}
+	// scheduleCount counts the schedules of scheduleList before they are cut to the page
+	scheduleCount = dialect.Query{
+		Postgres: `--sql
+			SELECT COUNT(*) AS count
+			  FROM public.therapy_schedules t
+			 WHERE (:1 = '' OR t.patient_id::TEXT = :1)
+			   AND (:2 = '' OR t.doctor_id::TEXT = :2)
+			   AND (:3 = '' OR t.therapy_type = :3)
+			   AND (:4 = '' OR (t.status = 'active') = (:4 = 'true'))
+			   AND (:5 = '' OR t.status = :5)
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS patient_id, :2 AS doctor_id, :3 AS therapy_type, :4 AS is_active, :5 AS status FROM dual)
+			SELECT COUNT(*) AS count
+			  FROM therapy_schedules t
+			 CROSS JOIN a
+			 WHERE (a.patient_id IS NULL OR t.patient_id = a.patient_id)
+			   AND (a.doctor_id IS NULL OR t.doctor_id = a.doctor_id)
+			   AND (a.therapy_type IS NULL OR t.therapy_type = a.therapy_type)
+			   AND (a.is_active IS NULL
+			    OR (t.status = 'active' AND a.is_active = 'true')
+			    OR (t.status <> 'active' AND a.is_active <> 'true'))
+			   AND (a.status IS NULL OR t.status = a.status)
+		`,
+	}
+	activeSchedules = scheduleColumns.Append(`
+		 WHERE t.status = 'active'
+	`)
+	// calendarSchedules lists the schedules of a doctor or a patient running in a date range, ended
+	// schedules are included while they ended within it so their sessions show as cancelled
+	calendarSchedules = dialect.Query{
+		Postgres: scheduleColumns.Postgres + `
+			 WHERE (t.doctor_id::TEXT = :1 OR t.patient_id::TEXT = :2)
+			   AND t.start_date < :4::DATE
+			   AND (t.end_date IS NULL OR t.end_date >= :3::DATE)
+			   AND (t.status = 'active' OR t.ended_at >= :3)
+			 ORDER BY t.start_date
+		`,
+		Oracle: `
+			WITH a AS (SELECT :1 AS doctor_id, :2 AS patient_id, :3 AS date_from, :4 AS date_to FROM dual)` +
+			scheduleColumns.Oracle + `
+			  JOIN a ON t.doctor_id = a.doctor_id OR t.patient_id = a.patient_id
+			 WHERE t.start_date < TRUNC(CAST(a.date_to AS DATE))
+			   AND (t.end_date IS NULL OR t.end_date >= TRUNC(CAST(a.date_from AS DATE)))
+			   AND (t.status = 'active' OR t.ended_at >= a.date_from)
+			 ORDER BY t.start_date
+		`,
+	}
+	activeCount = dialect.Query{
+		Postgres: `--sql
+			SELECT COUNT(*) AS count
+			  FROM public.therapy_schedules t
+			 WHERE t.status = 'active'
+			   AND (:1 = '' OR t.doctor_id::TEXT = :1)
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS doctor_id FROM dual)
+			SELECT COUNT(*) AS count
+			  FROM therapy_schedules t
+			 CROSS JOIN a
+			 WHERE t.status = 'active'
+			   AND (a.doctor_id IS NULL OR t.doctor_id = a.doctor_id)
+		`,
+	}
+	// endSchedule only moves an active schedule, a schedule cancelled meanwhile is left alone
+	endSchedule = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.therapy_schedules SET status = :2, is_active = FALSE, ended_at = NOW(), updated_at = NOW()
+			 WHERE id = :1 AND status = 'active'
+		`,
+		Oracle: `--sql
+			MERGE INTO therapy_schedules t
+			USING (SELECT :1 AS id, :2 AS status FROM dual) a
+			   ON (t.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				t.status = a.status, t.is_active = FALSE, t.ended_at = SYSTIMESTAMP, t.updated_at = SYSTIMESTAMP
+				WHERE t.status = 'active'
+		`,
+	}
+	insertSchedule = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.therapy_schedules
+				(id, patient_id, doctor_id, therapy_type, description, start_date, end_date, frequency, instructions,
+				 duration, session_count, rrule, exdates, holiday_policy)
+			VALUES (:1, :2, :3, :4, NULLIF(:5, ''), :6::DATE, NULLIF(:7, '')::DATE, NULLIF(:8, ''), NULLIF(:9, ''),
+				NULLIF(:10, 0), NULLIF(:11, 0), NULLIF(:12, ''), NULLIF(:13, ''), :14)
+		`,
+		Oracle: `--sql
+			INSERT INTO therapy_schedules
+				(id, patient_id, doctor_id, therapy_type, description, start_date, end_date, frequency, instructions,
+				 duration, session_count, rrule, exdates, holiday_policy)
+			VALUES (:1, :2, :3, :4, :5, TO_DATE(:6, 'YYYY-MM-DD'), TO_DATE(:7, 'YYYY-MM-DD'), :8, :9,
+				NULLIF(:10, 0), NULLIF(:11, 0), :12, :13, :14)
+		`,
+	}
+	// updateSchedule leaves the patient, the doctor and the status alone
+	updateSchedule = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.therapy_schedules SET
+				therapy_type = :2,
+				description = NULLIF(:3, ''),
+				start_date = :4::DATE,
+				end_date = NULLIF(:5, '')::DATE,
+				frequency = NULLIF(:6, ''),
+				instructions = NULLIF(:7, ''),
+				duration = NULLIF(:8, 0),
+				session_count = NULLIF(:9, 0),
+				rrule = NULLIF(:10, ''),
+				exdates = NULLIF(:11, ''),
+				holiday_policy = :12,
+				updated_at = NOW()
+			 WHERE id::TEXT = :1
+		`,
+		Oracle: `--sql
+			MERGE INTO therapy_schedules t
+			USING (
+				SELECT :1 AS id, :2 AS therapy_type, :3 AS description, TO_DATE(:4, 'YYYY-MM-DD') AS start_date,
+				       TO_DATE(:5, 'YYYY-MM-DD') AS end_date, :6 AS frequency, :7 AS instructions,
+				       NULLIF(:8, 0) AS duration, NULLIF(:9, 0) AS session_count, :10 AS rrule, :11 AS exdates,
+				       :12 AS holiday_policy
+				  FROM dual
+			) a
+			   ON (t.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				t.therapy_type = a.therapy_type,
+				t.description = a.description,
+				t.start_date = a.start_date,
+				t.end_date = a.end_date,
+				t.frequency = a.frequency,
+				t.instructions = a.instructions,
+				t.duration = a.duration,
+				t.session_count = a.session_count,
+				t.rrule = a.rrule,
+				t.exdates = a.exdates,
+				t.holiday_policy = a.holiday_policy,
+				t.updated_at = SYSTIMESTAMP
+		`,
+	}
+	cancelSchedule = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.therapy_schedules SET status = 'cancelled', is_active = FALSE, ended_at = NOW(), updated_at = NOW()
+			 WHERE id::TEXT = :1 AND status = 'active'
+		`,
+		Oracle: `--sql
+			UPDATE therapy_schedules SET status = 'cancelled', is_active = FALSE, ended_at = SYSTIMESTAMP, updated_at = SYSTIMESTAMP
+			 WHERE id = :1 AND status = 'active'
+		`,
+	}
+	holidayList = dialect.Query{
+		Postgres: `--sql
+			SELECT h.day, h.name, h.created_at
+			  FROM public.clinic_holidays h
+			 WHERE (:1 = 0 OR EXTRACT(YEAR FROM h.day) = :1)
+			 ORDER BY h.day
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS in_year FROM dual)
+			SELECT h.day, h.name, h.created_at
+			  FROM clinic_holidays h
+			 CROSS JOIN a
+			 WHERE (a.in_year = 0 OR EXTRACT(YEAR FROM h.day) = a.in_year)
+			 ORDER BY h.day
+		`,
+	}
+	insertHoliday = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.clinic_holidays (day, name)
+			VALUES (:1::DATE, :2)
+			ON CONFLICT (day) DO UPDATE SET name = EXCLUDED.name
+		`,
+		Oracle: `--sql
+			MERGE INTO clinic_holidays h
+			USING (SELECT TO_DATE(:1, 'YYYY-MM-DD') AS day, :2 AS name FROM dual) a
+			   ON (h.day = a.day)
+			 WHEN MATCHED THEN UPDATE SET h.name = a.name
+			 WHEN NOT MATCHED THEN INSERT (day, name) VALUES (a.day, a.name)
+		`,
+	}
+	deleteHoliday = dialect.Query{
+		Postgres: `--sql
+			DELETE FROM public.clinic_holidays WHERE day = :1::DATE
+		`,
+		Oracle: `--sql
+			DELETE FROM clinic_holidays WHERE day = TO_DATE(:1, 'YYYY-MM-DD')
+		`,
+	}
+	// recording a day again replaces its outcome
+	upsertSession = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.therapy_sessions (schedule_id, session_date, status, notes, recorded_by)
+			VALUES (:1, :2::DATE, :3, NULLIF(:4, ''), NULLIF(:5, ''))
+			ON CONFLICT (schedule_id, session_date) DO UPDATE SET
+				status = EXCLUDED.status,
+				notes = EXCLUDED.notes,
+				recorded_by = EXCLUDED.recorded_by,
+				updated_at = NOW()
+		`,
+		Oracle: `--sql
+			MERGE INTO therapy_sessions s
+			USING (
+				SELECT :1 AS schedule_id, TO_DATE(:2, 'YYYY-MM-DD') AS session_date, :3 AS status, :4 AS notes,
+				       :5 AS recorded_by
+				  FROM dual
+			) a
+			   ON (s.schedule_id = a.schedule_id AND s.session_date = a.session_date)
+			 WHEN MATCHED THEN UPDATE SET
+				s.status = a.status,
+				s.notes = a.notes,
+				s.recorded_by = a.recorded_by,
+				s.updated_at = SYSTIMESTAMP
+			 WHEN NOT MATCHED THEN INSERT (schedule_id, session_date, status, notes, recorded_by)
+			VALUES (a.schedule_id, a.session_date, a.status, a.notes, a.recorded_by)
+		`,
+	}
+	sessionList = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				s.id,
+				s.schedule_id,
+				s.session_date,
+				s.status,
+				COALESCE(s.notes, '') AS notes,
+				COALESCE(s.recorded_by, '') AS recorded_by,
+				s.created_at
+			  FROM public.therapy_sessions s
+			 WHERE s.schedule_id = :1
+			 ORDER BY s.session_date
+		`,
+		Oracle: `--sql
+			SELECT
+				s.id,
+				s.schedule_id,
+				s.session_date,
+				s.status,
+				s.notes,
+				s.recorded_by,
+				s.created_at
+			  FROM therapy_sessions s
+			 WHERE s.schedule_id = :1
+			 ORDER BY s.session_date
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"activeCount":       activeCount,
+	"activeSchedules":   activeSchedules,
+	"calendarSchedules": calendarSchedules,
+	"cancelSchedule":    cancelSchedule,
+	"deleteHoliday":     deleteHoliday,
+	"endSchedule":       endSchedule,
+	"holidayList":       holidayList,
+	"insertHoliday":     insertHoliday,
+	"insertSchedule":    insertSchedule,
+	"scheduleByID":      scheduleByID,
+	"scheduleCount":     scheduleCount,
+	"scheduleList":      scheduleList,
+	"sessionList":       sessionList,
+	"updateSchedule":    updateSchedule,
+	"upsertSession":     upsertSession,
+}
@@ -1,17 +1,44 @@
 package therapyschedules
 
 import (
+	"healthcare/models"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
 )
 
 // SetupRoutes sets up all therapy schedule-related routes
-func SetupRoutes(r *gin.RouterGroup) {
+func SetupRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	r *gin.RouterGroup,
+	tracker *Tracker,
+	simulation bool,
+) {
+	env := &therapyEnv{
+		Interface: model,
+		Params:    wsParams,
+		Tracker:   tracker,
+	}
 	schedules := r.Group("/therapy-schedules")
 	{
-		schedules.POST("", TherapySchedulePostHandler)         // Create new therapy schedule
-		schedules.GET("", TherapyScheduleGetAllHandler)        // Get all therapy schedules with filters
-		schedules.GET("/:id", TherapyScheduleGetHandler)       // Get specific therapy schedule
-		schedules.PUT("/:id", TherapySchedulePutHandler)       // Update therapy schedule
-		schedules.DELETE("/:id", TherapyScheduleDeleteHandler) // Delete therapy schedule
+		schedules.POST("", libGin.Gin(env.therapySchedulePostHandler(simulation)))               // Create new therapy schedule
+		schedules.GET("/all", libGin.Gin(env.therapyScheduleGetAllHandler(simulation)))          // Get all therapy schedules with filters
+		schedules.GET("", libGin.Gin(env.therapyScheduleGetAllHandler(simulation)))              // Get all therapy schedules, kept for older clients
+		schedules.GET("/:id", libGin.Gin(env.therapyScheduleGetHandler(simulation)))             // Get specific therapy schedule
+		schedules.PUT("/:id", libGin.Gin(env.therapySchedulePutHandler(simulation)))             // Update therapy schedule
+		schedules.DELETE("/:id", libGin.Gin(env.therapyScheduleDeleteHandler(simulation)))       // Cancel therapy schedule
+		schedules.GET("/:id/occurrences", libGin.Gin(env.therapyOccurrencesHandler(simulation))) // Expand sessions of therapy schedule
+		schedules.POST("/:id/sessions", libGin.Gin(env.therapySessionPostHandler(simulation)))   // Record attended or missed session
+		schedules.GET("/:id/sessions", libGin.Gin(env.therapySessionGetAllHandler(simulation)))  // Get sessions of therapy schedule
+	}
+	holidays := r.Group("/clinic-holidays")
+	{
+		holidays.GET("/all", libGin.Gin(env.clinicHolidayGetAllHandler(simulation)))     // Get clinic holidays
+		holidays.GET("", libGin.Gin(env.clinicHolidayGetAllHandler(simulation)))         // Get clinic holidays, kept for older clients
+		holidays.POST("", libGin.Gin(env.clinicHolidayPostHandler(simulation)))          // Add clinic holiday
+		holidays.DELETE("/:day", libGin.Gin(env.clinicHolidayDeleteHandler(simulation))) // Delete clinic holiday
 	}
 }
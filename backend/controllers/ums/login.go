@@ -85,11 +85,7 @@ func (h LoginHandler) Initializer(req handlers.HandlerRequest[LoginRequest, *Log
 }
 
 func GetUserData(userName string, core requestCore.RequestCoreInterface) (*UserData, error) {
-	result, err := libQuery.GetQuery[UserData](`--sql
-		select * 
-		  from SIMULATOR.USERS 
-		 where id = :1
-	`, core.GetDB(), userName)
+	result, err := libQuery.GetQuery[UserData](selectUser.SQL(), core.GetDB(), userName)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,26 @@
+package ums
+
+import "healthcare/utils/dialect"
+
+var (
+	selectUser = dialect.Query{
+		Postgres: `--sql
+			select *
+			  from simulator.users
+			 where id = :1
+		`,
+		Oracle: `--sql
+			select *
+			  from SIMULATOR.USERS
+			 where id = :1
+		`,
+	}
+	insertUser = dialect.Query{
+		Postgres: `--sql
+			insert into simulator.users values(:1, :2, NULL, '000014', NULL, NULL, :3)
+		`,
+		Oracle: `--sql
+			insert into SIMULATOR.USERS values(:1, :2, NULL, '000014', NULL, NULL, :3)
+		`,
+	}
+)
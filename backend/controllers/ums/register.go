@@ -51,9 +51,7 @@ func (h RegisterHandler) Initializer(req handlers.HandlerRequest[RegisterRequest
 }
 
 func InsertUserData(userID, userName, pass string, core requestCore.RequestCoreInterface) (sql.Result, error) {
-	result, err := core.GetDB().InsertRow(`--sql
-		insert into simulator.USERS values(:1, :2, NULL, '000014', NULL, NULL, :3)
-	`, userID, userName, pass)
+	result, err := core.GetDB().InsertRow(insertUser.SQL(), userID, userName, pass)
 	if err != nil {
 		return nil, err
 	}
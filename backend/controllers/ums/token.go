@@ -37,7 +37,10 @@ func GenerateOtp(key string) string {
 }
 
 func GetToken(w webFramework.WebFramework) (string, error) {
-	authHeader := w.Parser.GetHeaderValue("Authorization")
+	return tokenFromHeader(w.Parser.GetHeaderValue("Authorization"))
+}
+
+func tokenFromHeader(authHeader string) (string, error) {
 	if len(authHeader) == 0 {
 		return "", libError.New(http.StatusBadRequest,
 			"AUTH_HEADER_ABSENT_OR_INVALID", "auth header does not exists")
@@ -51,6 +54,25 @@ func GetToken(w webFramework.WebFramework) (string, error) {
 
 }
 
+// CurrentUser returns the user of the bearer token sent with the request
+func CurrentUser(w webFramework.WebFramework, core requestCore.RequestCoreInterface) (*UserData, error) {
+	token, err := GetToken(w)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateJwtToken(core, token)
+}
+
+// HeaderUser returns the user of the bearer token in an Authorization header,
+// for handlers that are not built on requestCore
+func HeaderUser(core requestCore.RequestCoreInterface, authHeader string) (*UserData, error) {
+	token, err := tokenFromHeader(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateJwtToken(core, token)
+}
+
 func GenJwtToken(payload jwt.RegisteredClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 
@@ -0,0 +1,15 @@
+package visits
+
+import (
+	"healthcare/models"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// CalendarVisits returns the visits of a doctor or a patient in [from, to) for calendar feeds, cancelled
+// visits are included so subscribed calendars mark them cancelled
+func CalendarVisits(core requestCore.RequestCoreInterface, doctorID, patientID string, from, to time.Time) ([]models.CalendarVisitRow, error) {
+	return libQuery.GetQuery[models.CalendarVisitRow](calendarVisits.SQL(), core.GetDB(), doctorID, patientID, from, to)
+}
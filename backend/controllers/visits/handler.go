@@ -2,247 +2,267 @@ package visits
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
-	"charity-log/backend/models"
+	"healthcare/models"
+	"healthcare/utils/i18n"
+	"healthcare/utils/paging"
+	"healthcare/utils/validation"
+	"healthcare/utils/visitflow"
 
-	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
 	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
 )
 
-// VisitPostHandler handles POST requests for creating new visits
-func VisitPostHandler(c *gin.Context) {
-	var visit models.VisitRequest
-	if err := c.ShouldBindJSON(&visit); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate required fields
-	if visit.PatientID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "patient_id is required"})
-		return
-	}
-	if visit.DoctorID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "doctor_id is required"})
-		return
-	}
-	if visit.VisitType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "visit_type is required"})
-		return
-	}
+type visitsHandler struct {
+	Name string
+}
 
-	// Set default values
-	if visit.VisitDate.IsZero() {
-		visit.VisitDate = time.Now()
+// returns handler title
+func (h visitsHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name != "visits-post" {
+		body = libRequest.NoBinding
 	}
-	if visit.Status == "" {
-		visit.Status = "scheduled"
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits",
 	}
-
-	// TODO: Implement database insertion
-	// For now, return a mock response
-	response := models.VisitResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Visit created successfully",
-		},
-	}
-
-	c.JSON(http.StatusCreated, response)
 }
 
-// VisitPutHandler handles PUT requests for updating visits
-func VisitPutHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visit ID"})
-		return
+// runs after validating request
+func (h visitsHandler) Initializer(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) error {
+	switch h.Name {
+	case "visits-post":
+		// Set default values
+		if req.Request.VisitDate.IsZero() {
+			req.Request.VisitDate = time.Now()
+		}
+		if err := validation.Error(req.Request); err != nil {
+			return err
+		}
+		if req.Request.Status == "" {
+			req.Request.Status = visitflow.Scheduled
+		}
+		if !visitflow.Initial(req.Request.Status) {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidVisitStatus, "a new visit must be scheduled or checked_in")
+		}
+	case "visits-delete":
+		req.Request.ID = req.W.Parser.GetUrlParam("id")
+		if req.Request.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidVisitID, "visit id is required")
+		}
 	}
+	return nil
+}
 
-	var visit models.VisitRequest
-	if err := c.ShouldBindJSON(&visit); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// Handler is the main method that handles request and returns the response
+func (h visitsHandler) Handler(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) (*models.VisitResponse, error) {
+	switch h.Name {
+	case "visits-post":
+		// TODO: Implement database insertion
+		// For now, return a mock response
+		req.Response = &models.VisitResponse{
+			Result: libQuery.DmlResult{
+				Success: true,
+				Message: "Visit created successfully",
+			},
+		}
+		return req.Response, nil
 
-	// TODO: Implement database update
-	// For now, return a mock response
-	response := models.VisitResponse{
-		Result: libQuery.DmlResult{
-			Success: true,
-			Message: "Visit updated successfully",
-		},
+	case "visits-delete":
+		// TODO: Implement database deletion
+		// For now, return a mock response
+		req.Response = &models.VisitResponse{
+			Result: libQuery.DmlResult{
+				Success: true,
+				Message: "Visit deleted successfully",
+			},
+		}
+		return req.Response, nil
 	}
-
-	c.JSON(http.StatusOK, response)
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
 }
 
-// VisitDeleteHandler handles DELETE requests for visits
-func VisitDeleteHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visit ID"})
-		return
-	}
+// Simulation returns a simulated response
+func (h visitsHandler) Simulation(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) (*models.VisitResponse, error) {
+	return req.Response, nil
+}
 
-	// TODO: Implement database deletion
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Visit deleted successfully",
-		"id":      idStr,
-	}
+// runs after sending back response
+func (h visitsHandler) Finalizer(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) {
+}
 
-	c.JSON(http.StatusOK, response)
+type visitsQueryHandler struct {
+	Name string
 }
 
-// VisitGetHandler handles GET requests for a specific visit
-func VisitGetHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visit ID"})
-		return
+// returns handler title
+func (h visitsQueryHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits",
 	}
+}
 
-	// TODO: Implement database retrieval
-	// For now, return a mock response
-	response := gin.H{
-		"success": true,
-		"message": "Visit retrieved successfully",
-		"data": gin.H{
-			"id":         idStr,
-			"patient_id": "1",
-			"doctor_id":  "1",
-			"visit_type": "general",
-			"visit_date": time.Now(),
-			"status":     "completed",
-			"diagnosis":  "Regular checkup",
-			"treatment":  "Prescribed medication",
-			"notes":      "Patient is in good health",
-			"created_at": time.Now().Add(-24 * time.Hour),
-			"updated_at": time.Now(),
-		},
+// runs after validating request
+func (h visitsQueryHandler) Initializer(req handlers.HandlerRequest[models.VisitFilter, *paging.Page[models.VisitRow]]) error {
+	if h.Name == "visits-get" {
+		req.Request.ID = req.W.Parser.GetUrlParam("id")
+		if req.Request.ID == "" {
+			return libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidVisitID, "visit id is required")
+		}
 	}
+	return nil
+}
 
-	c.JSON(http.StatusOK, response)
-}
-
-// VisitGetAllHandler handles GET requests for all visits
-func VisitGetAllHandler(c *gin.Context) {
-	// Get query parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-	patientID := c.Query("patient_id")
-	doctorID := c.Query("doctor_id")
-	status := c.Query("status")
-	visitType := c.Query("visit_type")
-
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	// TODO: Implement database retrieval with filters
-	// For now, return mock data
-	mockVisits := []gin.H{
-		{
-			"id":         "1",
-			"patient_id": "1",
-			"doctor_id":  "1",
-			"visit_type": "general",
-			"visit_date": time.Now(),
-			"status":     "completed",
-			"diagnosis":  "Regular checkup",
-			"treatment":  "Prescribed medication",
-			"notes":      "Patient is in good health",
-			"created_at": time.Now().Add(-24 * time.Hour),
-			"updated_at": time.Now(),
-		},
-		{
-			"id":         "2",
-			"patient_id": "2",
-			"doctor_id":  "1",
-			"visit_type": "dental",
-			"visit_date": time.Now().Add(-2 * time.Hour),
-			"status":     "in_progress",
-			"diagnosis":  "Cavity detected",
-			"treatment":  "Dental filling",
-			"notes":      "Patient needs follow-up",
-			"created_at": time.Now().Add(-2 * time.Hour),
-			"updated_at": time.Now().Add(-1 * time.Hour),
-		},
-	}
+// visitSort maps the fields the visit list is sorted by to their columns
+var visitSort = map[string]string{
+	"id":             "v.id",
+	"patient_id":     "v.patient_id",
+	"doctor_id":      "v.doctor_id",
+	"visit_type":     "v.visit_type",
+	"visit_date":     "v.visit_date",
+	"status":         "v.status",
+	"follow_up_date": "v.follow_up_date",
+	"created_at":     "v.created_at",
+	"updated_at":     "v.updated_at",
+}
 
-	// Apply basic filtering (simplified for demo)
-	filteredVisits := mockVisits
-	if patientID != "" {
-		// Filter by patient ID
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["patient_id"] == patientID {
-				filtered = append(filtered, visit)
-			}
+// Handler returns the visit, or the page of the visits matching the filters, the list is sorted and
+// cut to the page in its query
+func (h visitsQueryHandler) Handler(req handlers.HandlerRequest[models.VisitFilter, *paging.Page[models.VisitRow]]) (*paging.Page[models.VisitRow], error) {
+	switch h.Name {
+	case "visits-get":
+		rows, err := libQuery.GetQuery[models.VisitRow](visitByID.SQL(), req.Core.GetDB(), req.Request.ID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
 		}
-		filteredVisits = filtered
-	}
-
-	if doctorID != "" {
-		// Filter by doctor ID
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["doctor_id"] == doctorID {
-				filtered = append(filtered, visit)
-			}
+		if len(rows) == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, i18n.VisitNotFound, "visit %s not found", req.Request.ID)
 		}
-		filteredVisits = filtered
-	}
+		return &paging.Page[models.VisitRow]{Items: rows, Total: len(rows)}, nil
 
-	if status != "" {
-		// Filter by status
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["status"] == status {
-				filtered = append(filtered, visit)
-			}
+	case "visits-get-all":
+		filter := req.Request
+		clause, err := filter.Clause(visitSort, "v.visit_date DESC, v.id")
+		if err != nil {
+			return nil, libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidPage, "%s", err.Error())
 		}
-		filteredVisits = filtered
-	}
-
-	if visitType != "" {
-		// Filter by visit type
-		var filtered []gin.H
-		for _, visit := range filteredVisits {
-			if visit["visit_type"] == visitType {
-				filtered = append(filtered, visit)
-			}
+		args := []any{filter.PatientID, filter.DoctorID, filter.Status, filter.VisitType}
+		rows, err := libQuery.GetQuery[models.VisitRow](visitList.Append(clause).SQL(), req.Core.GetDB(), args...)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
+		}
+		count, err := libQuery.GetQuery[models.VisitCountRow](visitCount.SQL(), req.Core.GetDB(), args...)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
+		}
+		page := &paging.Page[models.VisitRow]{Items: rows}
+		if page.Items == nil {
+			page.Items = []models.VisitRow{}
+		}
+		if len(count) > 0 {
+			page.Total = count[0].Count
 		}
-		filteredVisits = filtered
+		return page, nil
 	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
 
-	// Calculate pagination
-	total := len(filteredVisits)
-	offset := (page - 1) * limit
-	end := offset + limit
-
-	if offset >= total {
-		filteredVisits = []gin.H{}
-	} else if end > total {
-		filteredVisits = filteredVisits[offset:]
-	} else {
-		filteredVisits = filteredVisits[offset:end]
-	}
+// Simulation returns a simulated response
+func (h visitsQueryHandler) Simulation(req handlers.HandlerRequest[models.VisitFilter, *paging.Page[models.VisitRow]]) (*paging.Page[models.VisitRow], error) {
+	return req.Response, nil
+}
 
-	response := gin.H{
-		"success": true,
-		"message": "Visits retrieved successfully",
-		"data":    filteredVisits,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
-	}
+// runs after sending back response
+func (h visitsQueryHandler) Finalizer(req handlers.HandlerRequest[models.VisitFilter, *paging.Page[models.VisitRow]]) {
+}
+
+// visitPostHandler godoc
+// @Summary Create a new visit
+// @Description Create a new visit, it starts scheduled unless it is created checked in
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param visit body models.VisitRequest true "Visit"
+// @Router /visits [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitRequest, *models.VisitResponse, visitsHandler](env.Interface, visitsHandler{Name: "visits-post"}, simulation)
+}
+
+// visitDeleteHandler godoc
+// @Summary Delete a visit
+// @Description Delete a visit record
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id [delete]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitDeleteHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitRequest, *models.VisitResponse, visitsHandler](env.Interface, visitsHandler{Name: "visits-delete"}, simulation)
+}
+
+// visitGetHandler godoc
+// @Summary Get a visit by ID
+// @Description Get a single visit record by ID
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.VisitRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitFilter, *paging.Page[models.VisitRow], visitsQueryHandler](env.Interface, visitsQueryHandler{Name: "visits-get"}, simulation)
+}
 
-	c.JSON(http.StatusOK, response)
+// visitGetAllHandler godoc
+// @Summary Get all visits
+// @Description Get the visits matching the filters, sorted and paged by _sort, _order, _start and _end with the total in the X-Total-Count header
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param patient_id query string false "Patient ID"
+// @Param doctor_id query string false "Doctor ID"
+// @Param status query string false "Visit status"
+// @Param visit_type query string false "Visit type"
+// @Param _start query int false "First item of the page"
+// @Param _end query int false "Item after the page"
+// @Param _sort query string false "Field to sort by"
+// @Param _order query string false "asc or desc"
+// @Router /visits/all [get]
+// @Security OAuth2Password
+// @Success 200 {object} []models.VisitRow
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitGetAllHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitFilter, *paging.Page[models.VisitRow], visitsQueryHandler](env.Interface, visitsQueryHandler{Name: "visits-get-all"}, simulation)
 }
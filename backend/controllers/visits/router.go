@@ -1,17 +1,45 @@
 package visits
 
 import (
+	"healthcare/models"
+	"healthcare/utils/events"
+	"healthcare/utils/visitflow"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
 )
 
 // SetupRoutes sets up all visit-related routes
-func SetupRoutes(r *gin.RouterGroup) {
+func SetupRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	r *gin.RouterGroup,
+	broker *events.Broker,
+	simulation bool,
+) {
+	env := &visitsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Events:    broker,
+	}
 	visits := r.Group("/visits")
 	{
-		visits.POST("", VisitPostHandler)         // Create new visit
-		visits.GET("", VisitGetAllHandler)        // Get all visits with filters
-		visits.GET("/:id", VisitGetHandler)       // Get specific visit
-		visits.PUT("/:id", VisitPutHandler)       // Update visit
-		visits.DELETE("/:id", VisitDeleteHandler) // Delete visit
+		visits.POST("", libGin.Gin(env.visitPostHandler(simulation)))                       // Create new visit
+		visits.GET("/all", libGin.Gin(env.visitGetAllHandler(simulation)))                  // Get all visits with filters
+		visits.GET("", libGin.Gin(env.visitGetAllHandler(simulation)))                      // Get all visits, kept for older clients
+		visits.GET("/:id", libGin.Gin(env.visitGetHandler(simulation)))                     // Get specific visit
+		visits.PUT("/:id", libGin.Gin(env.visitPutHandler(simulation)))                     // Update visit
+		visits.DELETE("/:id", libGin.Gin(env.visitDeleteHandler(simulation)))               // Delete visit
+		visits.GET("/:id/transitions", libGin.Gin(env.visitTransitionsHandler(simulation))) // Status history
+		visits.POST("/:id/lock", libGin.Gin(env.visitLockHandler(simulation)))              // Lock completed visit
+		visits.POST("/:id/sign", libGin.Gin(env.visitSignHandler(simulation)))              // Sign completed visit
+		visits.GET("/:id/sign", libGin.Gin(env.visitSignatureHandler(simulation)))          // Signature of visit
+		visits.GET("/:id/addenda", libGin.Gin(env.addendaGetHandler(simulation)))           // Addenda of visit
+		visits.POST("/:id/addenda", libGin.Gin(env.addendaPostHandler(simulation)))         // Append addendum
+		for action, status := range visitflow.Actions {
+			visits.POST("/:id/"+action, libGin.Gin(env.visitTransitionHandler(status, simulation))) // Status transition
+		}
 	}
 }
@@ -0,0 +1,306 @@
+package visits
+
+import (
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/signoff"
+	"healthcare/utils/validation"
+	"healthcare/utils/visitflow"
+	"net/http"
+	"strings"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+func getContent(core requestCore.RequestCoreInterface, visitID string) (*models.VisitContentRow, error) {
+	rows, err := libQuery.GetQuery[models.VisitContentRow](visitContent.SQL(), core.GetDB(), visitID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "VISIT_NOT_FOUND", "visit %s not found", visitID)
+	}
+	return &rows[0], nil
+}
+
+// note returns the content of a visit that signing freezes
+func note(row *models.VisitContentRow) signoff.Content {
+	return signoff.Content{
+		ChiefComplaint:        string(row.ChiefComplaint),
+		Symptoms:              string(row.Symptoms),
+		Diagnosis:             string(row.Diagnosis),
+		TreatmentPlan:         string(row.TreatmentPlan),
+		MedicationsPrescribed: string(row.MedicationsPrescribed),
+		Notes:                 string(row.Notes),
+		FollowUpDate:          row.FollowUpDate,
+	}
+}
+
+func contentHash(row *models.VisitContentRow) string {
+	return note(row).Hash()
+}
+
+func signature(row *models.VisitContentRow) *models.VisitSignatureResponse {
+	resp := &models.VisitSignatureResponse{
+		Result:  libQuery.DmlResult{Success: true},
+		VisitID: row.ID,
+		Signed:  row.IsSigned,
+	}
+	if row.IsSigned {
+		signedAt := row.SignedAt
+		resp.SignedBy = string(row.SignedBy)
+		resp.SignedByName = string(row.SignedByName)
+		resp.SignedAt = &signedAt
+		resp.ContentHash = string(row.ContentHash)
+		resp.Verified = note(row).Verify(string(row.ContentHash))
+	}
+	return resp
+}
+
+type signHandler struct {
+	Name string
+}
+
+// returns handler title
+func (h signHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           libRequest.NoBinding,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id/sign",
+	}
+}
+
+// runs after validating request
+func (h signHandler) Initializer(req handlers.HandlerRequest[models.VisitSignRequest, *models.VisitSignatureResponse]) error {
+	req.Request.VisitID = req.W.Parser.GetUrlParam("id")
+	if req.Request.VisitID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h signHandler) Handler(req handlers.HandlerRequest[models.VisitSignRequest, *models.VisitSignatureResponse]) (*models.VisitSignatureResponse, error) {
+	// the signer is known before the state of the visit is revealed
+	var user *ums.UserData
+	if h.Name == "visits-sign" {
+		var err error
+		if user, err = ums.CurrentUser(req.W, req.Core); err != nil {
+			return nil, err
+		}
+	}
+	content, err := getContent(req.Core, req.Request.VisitID)
+	if err != nil {
+		return nil, err
+	}
+	switch h.Name {
+	case "visits-signature":
+		req.Response = signature(content)
+		return req.Response, nil
+
+	case "visits-sign":
+		if content.Status != visitflow.Completed {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_NOT_COMPLETED", "only completed visits can be signed, visit %s is %s", content.ID, content.Status)
+		}
+		if content.IsSigned {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_ALREADY_SIGNED", "visit %s was signed by %s", content.ID, content.SignedByName)
+		}
+		result, err := req.Core.GetDB().InsertRow(signVisit.SQL(), user.UserId, user.UserName, contentHash(content), content.ID, content.UpdatedAt)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_CHANGED", "visit %s changed while signing, review and sign again", content.ID)
+		}
+		content, err = getContent(req.Core, content.ID)
+		if err != nil {
+			return nil, err
+		}
+		req.Response = signature(content)
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h signHandler) Simulation(req handlers.HandlerRequest[models.VisitSignRequest, *models.VisitSignatureResponse]) (*models.VisitSignatureResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h signHandler) Finalizer(req handlers.HandlerRequest[models.VisitSignRequest, *models.VisitSignatureResponse]) {
+}
+
+type addendaHandler struct {
+	Name string
+}
+
+// returns handler title
+func (h addendaHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.NoBinding
+	if h.Name == "addenda-post" {
+		body = libRequest.JSON
+	}
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id/addenda",
+	}
+}
+
+// runs after validating request
+func (h addendaHandler) Initializer(req handlers.HandlerRequest[models.VisitAddendumRequest, *models.VisitAddendumResponse]) error {
+	req.Request.VisitID = req.W.Parser.GetUrlParam("id")
+	if req.Request.VisitID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	if h.Name == "addenda-post" {
+		req.Request.Text = strings.TrimSpace(req.Request.Text)
+		return validation.Error(req.Request)
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h addendaHandler) Handler(req handlers.HandlerRequest[models.VisitAddendumRequest, *models.VisitAddendumResponse]) (*models.VisitAddendumResponse, error) {
+	switch h.Name {
+	case "addenda-get":
+		if _, err := GetState(req.Core, req.Request.VisitID); err != nil {
+			return nil, err
+		}
+		rows, err := libQuery.GetQuery[models.VisitAddendumRow](visitAddenda.SQL(), req.Core.GetDB(), req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_ADDENDA", err.Error())
+		}
+		req.Response = &models.VisitAddendumResponse{
+			Result:  libQuery.DmlResult{Success: true},
+			Addenda: rows,
+		}
+		return req.Response, nil
+
+	case "addenda-post":
+		user, err := ums.CurrentUser(req.W, req.Core)
+		if err != nil {
+			return nil, err
+		}
+		state, err := GetState(req.Core, req.Request.VisitID)
+		if err != nil {
+			return nil, err
+		}
+		if !state.IsLocked {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_NOT_LOCKED", "visit %s is not signed or locked, edit it directly", state.ID)
+		}
+		content, err := getContent(req.Core, state.ID)
+		if err != nil {
+			return nil, err
+		}
+		signed := string(content.ContentHash)
+		if !content.IsSigned {
+			signed = contentHash(content)
+		}
+		result, err := req.Core.GetDB().InsertRow(insertAddendum.SQL(), state.ID, user.UserId, user.UserName,
+			req.Request.Text, signoff.AddendumHash(string(signed), user.UserId, req.Request.Text))
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response = &models.VisitAddendumResponse{
+			Result: libQuery.GetDmlResult(result, nil),
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h addendaHandler) Simulation(req handlers.HandlerRequest[models.VisitAddendumRequest, *models.VisitAddendumResponse]) (*models.VisitAddendumResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h addendaHandler) Finalizer(req handlers.HandlerRequest[models.VisitAddendumRequest, *models.VisitAddendumResponse]) {
+}
+
+// visitSignHandler godoc
+// @Summary Sign a completed visit note
+// @Description Freeze the clinical content of a completed visit, store its SHA-256 hash and the signer of the bearer token, the visit is locked afterwards
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/sign [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitSignatureResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitSignHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitSignRequest, *models.VisitSignatureResponse, signHandler](env.Interface, signHandler{Name: "visits-sign"}, simulation)
+}
+
+// visitSignatureHandler godoc
+// @Summary Get signature of a visit note
+// @Description Get the signer and content hash of a visit, verified is false when the stored note no longer matches the hash
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/sign [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitSignatureResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitSignatureHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitSignRequest, *models.VisitSignatureResponse, signHandler](env.Interface, signHandler{Name: "visits-signature"}, simulation)
+}
+
+// addendaGetHandler godoc
+// @Summary Get addenda of a visit
+// @Description Get the addenda appended to a signed or locked visit in order
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/addenda [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitAddendumResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) addendaGetHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitAddendumRequest, *models.VisitAddendumResponse, addendaHandler](env.Interface, addendaHandler{Name: "addenda-get"}, simulation)
+}
+
+// addendaPostHandler godoc
+// @Summary Append an addendum to a visit
+// @Description Append a correction to a signed or locked visit, the author is taken from the bearer token
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param addendum body models.VisitAddendumRequest true "Addendum"
+// @Router /visits/:id/addenda [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitAddendumResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) addendaPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitAddendumRequest, *models.VisitAddendumResponse, addendaHandler](env.Interface, addendaHandler{Name: "addenda-post"}, simulation)
+}
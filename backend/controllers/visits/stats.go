@@ -0,0 +1,21 @@
+package visits
+
+import (
+	"healthcare/models"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// Stats counts visits for the dashboard
+type Stats struct {
+	Core     requestCore.RequestCoreInterface
+	Location *time.Location
+}
+
+// VisitsPerDay returns the number of visits of each day in [start, end) in the clinic time zone, cancelled
+// visits and no-shows are not counted and doctorID is optional
+func (s Stats) VisitsPerDay(start, end time.Time, doctorID string) ([]models.DailyVisitCount, error) {
+	return libQuery.GetQuery[models.DailyVisitCount](visitsPerDay.SQL(), s.Core.GetDB(), start, end, doctorID, s.Location.String())
+}
@@ -0,0 +1,357 @@
+package visits
+
+import (
+	"healthcare/controllers/ums"
+	"healthcare/models"
+	"healthcare/utils/dialect"
+	"healthcare/utils/events"
+	"healthcare/utils/queue"
+	"healthcare/utils/validation"
+	"healthcare/utils/visitflow"
+	"net/http"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+)
+
+type visitsEnv struct {
+	Params    *libParams.ApplicationParams[models.ApplicationParams]
+	Interface requestCore.RequestCoreInterface
+	Events    *events.Broker
+}
+
+// GetState loads the workflow state of a visit
+func GetState(core requestCore.RequestCoreInterface, visitID string) (*models.VisitStateRow, error) {
+	rows, err := libQuery.GetQuery[models.VisitStateRow](visitState.SQL(), core.GetDB(), visitID)
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, libError.NewWithDescription(http.StatusNotFound, "VISIT_NOT_FOUND", "visit %s not found", visitID)
+	}
+	return &rows[0], nil
+}
+
+// EnsureEditable rejects clinical edits of a locked visit, which only accepts addenda, callers
+// authenticate the user first so the lock is not revealed to anonymous requests
+func EnsureEditable(core requestCore.RequestCoreInterface, visitID string) error {
+	state, err := GetState(core, visitID)
+	if err != nil {
+		return err
+	}
+	if state.IsLocked {
+		return libError.NewWithDescription(http.StatusConflict, "VISIT_LOCKED", "visit %s is locked, use an addendum instead", visitID)
+	}
+	return nil
+}
+
+// transition moves the visit to status, records who moved it and publishes the change to the room of the doctor
+func transition(core requestCore.RequestCoreInterface, broker *events.Broker, state *models.VisitStateRow, status string, actor *ums.UserData, reason string) error {
+	if err := checkTransition(state, status); err != nil {
+		return err
+	}
+	result, err := core.GetDB().InsertRow(updateStatus.SQL(), status, state.ID, state.Status, actor.UserId, actor.UserName, reason)
+	if err == nil && updateStatus.Unchanged(result) || dialect.Is(err, dialect.NothingChanged, "") {
+		return libError.NewWithDescription(http.StatusConflict, "VISIT_STATUS_CHANGED", "visit %s is no longer %s", state.ID, state.Status)
+	}
+	if err != nil {
+		return libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+	}
+	announce(broker, state, status)
+	return nil
+}
+
+// checkTransition rejects a status the workflow does not allow after the status of the visit
+func checkTransition(state *models.VisitStateRow, status string) error {
+	if err := visitflow.Check(state.Status, status); err != nil {
+		return libError.NewWithDescription(http.StatusConflict, "INVALID_STATUS_TRANSITION", "%s", err.Error())
+	}
+	return nil
+}
+
+// announce publishes a status change of the visit to the room of its doctor and records it in state
+func announce(broker *events.Broker, state *models.VisitStateRow, status string) {
+	room, _ := queue.Key(state.DoctorID, "")
+	broker.Publish(models.EventVisitStatus, models.VisitStatusEvent{
+		VisitID:    state.ID,
+		DoctorID:   state.DoctorID,
+		FromStatus: state.Status,
+		ToStatus:   status,
+	}, room, events.Clinic)
+	state.Status = status
+}
+
+// nullTime maps the zero time to NULL
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+type workflowHandler struct {
+	Name   string
+	Status string
+	Events *events.Broker
+}
+
+// returns handler title
+func (h workflowHandler) Parameters() handlers.HandlerParameters {
+	body := libRequest.JSON
+	if h.Name == "visits-transitions" {
+		body = libRequest.NoBinding
+	}
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           body,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id",
+	}
+}
+
+// runs after validating request
+func (h workflowHandler) Initializer(req handlers.HandlerRequest[models.VisitTransitionRequest, *models.VisitTransitionResponse]) error {
+	req.Request.VisitID = req.W.Parser.GetUrlParam("id")
+	if req.Request.VisitID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	return nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h workflowHandler) Handler(req handlers.HandlerRequest[models.VisitTransitionRequest, *models.VisitTransitionResponse]) (*models.VisitTransitionResponse, error) {
+	// the caller of a change is known before the state of the visit is revealed
+	var user *ums.UserData
+	if h.Name != "visits-transitions" {
+		var err error
+		if user, err = ums.CurrentUser(req.W, req.Core); err != nil {
+			return nil, err
+		}
+	}
+	state, err := GetState(req.Core, req.Request.VisitID)
+	if err != nil {
+		return nil, err
+	}
+	req.Response = &models.VisitTransitionResponse{
+		Result:  libQuery.DmlResult{Success: true},
+		VisitID: state.ID,
+	}
+	switch h.Name {
+	case "visits-transitions":
+		rows, err := libQuery.GetQuery[models.VisitTransitionRow](visitTransitions.SQL(), req.Core.GetDB(), state.ID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_TRANSITIONS", err.Error())
+		}
+		req.Response.Transitions = rows
+
+	case "visits-transition":
+		if state.IsLocked {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_LOCKED", "visit %s is locked", state.ID)
+		}
+		if err = transition(req.Core, h.Events, state, h.Status, user, req.Request.Reason); err != nil {
+			return nil, err
+		}
+		req.Response.Result.Message = "visit is " + state.Status
+
+	case "visits-lock":
+		if state.IsLocked {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_LOCKED", "visit %s is already locked", state.ID)
+		}
+		if !visitflow.Lockable(state.Status) {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_NOT_LOCKABLE", "only completed visits can be locked, visit %s is %s", state.ID, state.Status)
+		}
+		result, err := req.Core.GetDB().InsertRow(lockVisit.SQL(), user.UserId, state.ID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_STATUS_CHANGED", "visit %s changed while locking", state.ID)
+		}
+		req.Response.Result = libQuery.GetDmlResult(result, nil)
+		state.IsLocked = true
+
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	req.Response.Status = state.Status
+	req.Response.IsLocked = state.IsLocked
+	req.Response.NextStatus = visitflow.Next(state.Status)
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h workflowHandler) Simulation(req handlers.HandlerRequest[models.VisitTransitionRequest, *models.VisitTransitionResponse]) (*models.VisitTransitionResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h workflowHandler) Finalizer(req handlers.HandlerRequest[models.VisitTransitionRequest, *models.VisitTransitionResponse]) {
+}
+
+type visitUpdateHandler struct {
+	Events *events.Broker
+}
+
+// returns handler title
+func (h visitUpdateHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "visits",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id",
+	}
+}
+
+// runs after validating request
+func (h visitUpdateHandler) Initializer(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) error {
+	req.Request.ID = req.W.Parser.GetUrlParam("id")
+	if req.Request.ID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	if req.Request.Status != "" && !visitflow.Valid(req.Request.Status) {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_STATUS", "unknown visit status %s", req.Request.Status)
+	}
+	// the patient and doctor of a visit do not change, the fields left out keep their values
+	skip := []string{"patient_id", "doctor_id"}
+	if req.Request.VisitType == "" {
+		skip = append(skip, "visit_type")
+	}
+	return validation.Error(req.Request, skip...)
+}
+
+// Handler updates the fields sent for an unlocked visit, a status change is checked against the
+// workflow and applied in the same statement
+func (h visitUpdateHandler) Handler(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) (*models.VisitResponse, error) {
+	user, err := ums.CurrentUser(req.W, req.Core)
+	if err != nil {
+		return nil, err
+	}
+	state, err := GetState(req.Core, req.Request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if state.IsLocked {
+		return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_LOCKED", "visit %s is locked, use an addendum instead", state.ID)
+	}
+	status := state.Status
+	if req.Request.Status != "" && req.Request.Status != state.Status {
+		if err := checkTransition(state, req.Request.Status); err != nil {
+			return nil, err
+		}
+		status = req.Request.Status
+	}
+	result, err := req.Core.GetDB().InsertRow(updateVisit.SQL(),
+		req.Request.VisitType, nullTime(req.Request.VisitDate), req.Request.ChiefComplaint, req.Request.Symptoms,
+		req.Request.Diagnosis, req.Request.TreatmentPlan, req.Request.MedicationsPrescribed, req.Request.Notes,
+		nullTime(req.Request.FollowUpDate), state.ID, status, state.Status, user.UserId, user.UserName)
+	if err == nil && updateVisit.Unchanged(result) || dialect.Is(err, dialect.NothingChanged, "") {
+		return nil, libError.NewWithDescription(http.StatusConflict, "VISIT_STATUS_CHANGED", "visit %s changed while updating", state.ID)
+	}
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_UPDATE", err.Error())
+	}
+	if status != state.Status {
+		announce(h.Events, state, status)
+	}
+	req.Response = &models.VisitResponse{
+		Result: libQuery.GetDmlResult(result, nil),
+	}
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h visitUpdateHandler) Simulation(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) (*models.VisitResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h visitUpdateHandler) Finalizer(req handlers.HandlerRequest[models.VisitRequest, *models.VisitResponse]) {
+}
+
+// visitPutHandler godoc
+// @Summary Update a visit
+// @Description Update the clinical fields of a visit that are sent, fields left out keep their values. A status change must be a valid workflow transition and is applied with the update, locked visits are rejected
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param visit body models.VisitRequest true "Visit"
+// @Router /visits/:id [put]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitPutHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitRequest, *models.VisitResponse, visitUpdateHandler](env.Interface, visitUpdateHandler{Events: env.Events}, simulation)
+}
+
+// visitTransitionHandler godoc
+// @Summary Move a visit to another status
+// @Description Actions are check-in, start, complete, cancel and no-show, each one is recorded with its actor and time
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param action path string true "Action" Enums(check-in, start, complete, cancel, no-show)
+// @Param transition body models.VisitTransitionRequest true "Reason of the transition"
+// @Router /visits/:id/{action} [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitTransitionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitTransitionHandler(status string, simulation bool) any {
+	return handlers.BaseHandler[models.VisitTransitionRequest, *models.VisitTransitionResponse, workflowHandler](env.Interface, workflowHandler{Name: "visits-transition", Status: status, Events: env.Events}, simulation)
+}
+
+// visitTransitionsHandler godoc
+// @Summary Get status history of a visit
+// @Description Get the recorded status transitions of a visit and the statuses it can move to
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/transitions [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitTransitionResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitTransitionsHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitTransitionRequest, *models.VisitTransitionResponse, workflowHandler](env.Interface, workflowHandler{Name: "visits-transitions"}, simulation)
+}
+
+// visitLockHandler godoc
+// @Summary Lock a completed visit
+// @Description Lock a completed visit against further clinical edits, later changes are only possible through addenda
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param transition body models.VisitTransitionRequest true "Reason"
+// @Router /visits/:id/lock [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitTransitionResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env visitsEnv) visitLockHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitTransitionRequest, *models.VisitTransitionResponse, workflowHandler](env.Interface, workflowHandler{Name: "visits-lock"}, simulation)
+}
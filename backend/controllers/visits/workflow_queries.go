@@ -0,0 +1,445 @@
+package visits
+
+import "healthcare/utils/dialect"
+
+var (
+	// visitColumns reads a visit, its vital signs, examination and lab result fields are recorded by
+	// their own endpoints and read empty
+	visitColumns = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				v.id,
+				v.patient_id,
+				v.doctor_id,
+				v.visit_type,
+				v.visit_date,
+				v.status,
+				COALESCE(v.chief_complaint, '') AS chief_complaint,
+				COALESCE(v.symptoms, '') AS symptoms,
+				COALESCE(v.diagnosis, '') AS diagnosis,
+				COALESCE(v.treatment_plan, '') AS treatment_plan,
+				COALESCE(v.medications_prescribed, '') AS medications_prescribed,
+				COALESCE(v.notes, '') AS notes,
+				COALESCE(v.follow_up_date, DATE '0001-01-01') AS follow_up_date,
+				'' AS vital_signs,
+				'' AS examination_notes,
+				'' AS lab_results,
+				v.created_at,
+				v.updated_at
+			  FROM public.visits v
+		`,
+		Oracle: `--sql
+			SELECT
+				v.id,
+				v.patient_id,
+				v.doctor_id,
+				v.visit_type,
+				v.visit_date,
+				v.status,
+				v.chief_complaint,
+				v.symptoms,
+				v.diagnosis,
+				v.treatment_plan,
+				v.medications_prescribed,
+				v.notes,
+				COALESCE(v.follow_up_date, DATE '0001-01-01') AS follow_up_date,
+				NULL AS vital_signs,
+				NULL AS examination_notes,
+				NULL AS lab_results,
+				v.created_at,
+				v.updated_at
+			  FROM visits v
+		`,
+	}
+	visitByID = dialect.Query{
+		Postgres: visitColumns.Postgres + `
+			 WHERE v.id::TEXT = :1
+		`,
+		Oracle: visitColumns.Oracle + `
+			 WHERE v.id = :1
+		`,
+	}
+	// visitList filters by patient, doctor, status and visit type, each filter is optional, the
+	// handler appends the order and page of the request
+	visitList = dialect.Query{
+		Postgres: visitColumns.Postgres + `
+			 WHERE (:1 = '' OR v.patient_id::TEXT = :1)
+			   AND (:2 = '' OR v.doctor_id::TEXT = :2)
+			   AND (:3 = '' OR v.status::TEXT = :3)
+			   AND (:4 = '' OR v.visit_type::TEXT = :4)
+		`,
+		Oracle: `
+			WITH a AS (SELECT :1 AS patient_id, :2 AS doctor_id, :3 AS status, :4 AS visit_type FROM dual)` +
+			visitColumns.Oracle + `
+			 CROSS JOIN a
+			 WHERE (a.patient_id IS NULL OR v.patient_id = a.patient_id)
+			   AND (a.doctor_id IS NULL OR v.doctor_id = a.doctor_id)
+			   AND (a.status IS NULL OR v.status = a.status)
+			   AND (a.visit_type IS NULL OR v.visit_type = a.visit_type)
+		`,
+	}
+	// visitCount counts the visits of visitList before they are cut to the page
+	visitCount = dialect.Query{
+		Postgres: `--sql
+			SELECT COUNT(*) AS count
+			  FROM public.visits v
+			 WHERE (:1 = '' OR v.patient_id::TEXT = :1)
+			   AND (:2 = '' OR v.doctor_id::TEXT = :2)
+			   AND (:3 = '' OR v.status::TEXT = :3)
+			   AND (:4 = '' OR v.visit_type::TEXT = :4)
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS patient_id, :2 AS doctor_id, :3 AS status, :4 AS visit_type FROM dual)
+			SELECT COUNT(*) AS count
+			  FROM visits v
+			 CROSS JOIN a
+			 WHERE (a.patient_id IS NULL OR v.patient_id = a.patient_id)
+			   AND (a.doctor_id IS NULL OR v.doctor_id = a.doctor_id)
+			   AND (a.status IS NULL OR v.status = a.status)
+			   AND (a.visit_type IS NULL OR v.visit_type = a.visit_type)
+		`,
+	}
+	visitState = dialect.Query{
+		Postgres: `--sql
+			SELECT v.id, v.patient_id, v.doctor_id, v.status, v.locked_at IS NOT NULL AS is_locked
+			  FROM public.visits v
+			 WHERE v.id = :1
+		`,
+		Oracle: `--sql
+			SELECT v.id, v.patient_id, v.doctor_id, v.status, CASE WHEN v.locked_at IS NOT NULL THEN 1 ELSE 0 END AS is_locked
+			  FROM visits v
+			 WHERE v.id = :1
+		`,
+	}
+	// updateStatus moves a visit that is still in the status the transition was checked against and
+	// records the transition in the same statement, the Oracle block raises NothingChanged when the
+	// status has changed
+	updateStatus = dialect.Query{
+		Postgres: `--sql
+			WITH visit AS (
+				UPDATE public.visits SET status = :1, updated_at = NOW()
+				 WHERE id = :2 AND status = :3
+				RETURNING id
+			)
+			INSERT INTO public.visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+			SELECT visit.id, :3, :1, :4, :5, :6
+			  FROM visit
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_to visits.status%TYPE := :1;
+				v_visit visits.id%TYPE := :2;
+				v_from visits.status%TYPE := :3;
+			BEGIN
+				UPDATE visits SET status = v_to, updated_at = SYSTIMESTAMP
+				 WHERE id = v_visit AND status = v_from;
+				IF SQL%ROWCOUNT = 0 THEN
+					RAISE_APPLICATION_ERROR(-20000, 'visit status changed');
+				END IF;
+				INSERT INTO visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name, reason)
+				VALUES (v_visit, v_from, v_to, :4, :5, :6);
+			END;
+		`,
+	}
+	visitTransitions = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				t.id,
+				t.visit_id,
+				COALESCE(t.from_status, '') AS from_status,
+				t.to_status,
+				t.actor_id,
+				COALESCE(t.actor_name, '') AS actor_name,
+				COALESCE(t.reason, '') AS reason,
+				t.transitioned_at
+			  FROM public.visit_status_transitions t
+			 WHERE t.visit_id = :1
+			 ORDER BY t.transitioned_at
+		`,
+		Oracle: `--sql
+			SELECT
+				t.id,
+				t.visit_id,
+				t.from_status,
+				t.to_status,
+				t.actor_id,
+				t.actor_name,
+				t.reason,
+				t.transitioned_at
+			  FROM visit_status_transitions t
+			 WHERE t.visit_id = :1
+			 ORDER BY t.transitioned_at
+		`,
+	}
+	lockVisit = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.visits SET locked_at = NOW(), locked_by = :1, updated_at = NOW()
+			 WHERE id = :2 AND status = 'completed' AND locked_at IS NULL
+		`,
+		Oracle: `--sql
+			UPDATE visits SET locked_at = SYSTIMESTAMP, locked_by = :1, updated_at = SYSTIMESTAMP
+			 WHERE id = :2 AND status = 'completed' AND locked_at IS NULL
+		`,
+	}
+	// updateVisit changes the fields of an unlocked visit that were sent, empty text and a NULL date
+	// keep the stored value. It also moves the visit from status :12 to :11 and records the move when
+	// they differ, the visit must still be in :12. The PostgreSQL variant returns the updated visit,
+	// the Oracle block raises NothingChanged when no visit matched
+	updateVisit = dialect.Query{
+		Postgres: `--sql
+			WITH visit AS (
+				UPDATE public.visits SET
+					status = :11,
+					visit_type = COALESCE(CAST(NULLIF(:1, '') AS visit_type), visit_type),
+					visit_date = COALESCE(:2, visit_date),
+					chief_complaint = COALESCE(NULLIF(:3, ''), chief_complaint),
+					symptoms = COALESCE(NULLIF(:4, ''), symptoms),
+					diagnosis = COALESCE(NULLIF(:5, ''), diagnosis),
+					treatment_plan = COALESCE(NULLIF(:6, ''), treatment_plan),
+					medications_prescribed = COALESCE(NULLIF(:7, ''), medications_prescribed),
+					notes = COALESCE(NULLIF(:8, ''), notes),
+					follow_up_date = COALESCE(:9, follow_up_date),
+					updated_at = NOW()
+				 WHERE id = :10 AND status = :12 AND locked_at IS NULL
+				RETURNING id
+			), transition AS (
+				INSERT INTO public.visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name)
+				SELECT visit.id, :12, :11, :13, :14
+				  FROM visit
+				 WHERE :11 <> :12
+			)
+			SELECT visit.id FROM visit
+		`,
+		Oracle: `--sql
+			DECLARE
+				v_type visits.visit_type%TYPE := :1;
+				v_date visits.visit_date%TYPE := :2;
+				v_chief_complaint visits.chief_complaint%TYPE := :3;
+				v_symptoms visits.symptoms%TYPE := :4;
+				v_diagnosis visits.diagnosis%TYPE := :5;
+				v_treatment_plan visits.treatment_plan%TYPE := :6;
+				v_medications visits.medications_prescribed%TYPE := :7;
+				v_notes visits.notes%TYPE := :8;
+				v_follow_up visits.follow_up_date%TYPE := :9;
+				v_visit visits.id%TYPE := :10;
+				v_to visits.status%TYPE := :11;
+				v_from visits.status%TYPE := :12;
+			BEGIN
+				UPDATE visits SET
+					status = v_to,
+					visit_type = COALESCE(v_type, visit_type),
+					visit_date = COALESCE(v_date, visit_date),
+					chief_complaint = COALESCE(v_chief_complaint, chief_complaint),
+					symptoms = COALESCE(v_symptoms, symptoms),
+					diagnosis = COALESCE(v_diagnosis, diagnosis),
+					treatment_plan = COALESCE(v_treatment_plan, treatment_plan),
+					medications_prescribed = COALESCE(v_medications, medications_prescribed),
+					notes = COALESCE(v_notes, notes),
+					follow_up_date = COALESCE(v_follow_up, follow_up_date),
+					updated_at = SYSTIMESTAMP
+				 WHERE id = v_visit AND status = v_from AND locked_at IS NULL;
+				IF SQL%ROWCOUNT = 0 THEN
+					RAISE_APPLICATION_ERROR(-20000, 'visit changed while updating');
+				END IF;
+				IF v_to <> v_from THEN
+					INSERT INTO visit_status_transitions (visit_id, from_status, to_status, actor_id, actor_name)
+					VALUES (v_visit, v_from, v_to, :13, :14);
+				END IF;
+			END;
+		`,
+	}
+	visitContent = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				v.id,
+				v.status,
+				COALESCE(v.chief_complaint, '') AS chief_complaint,
+				COALESCE(v.symptoms, '') AS symptoms,
+				COALESCE(v.diagnosis, '') AS diagnosis,
+				COALESCE(v.treatment_plan, '') AS treatment_plan,
+				COALESCE(v.medications_prescribed, '') AS medications_prescribed,
+				COALESCE(v.notes, '') AS notes,
+				COALESCE(v.follow_up_date, DATE '0001-01-01') AS follow_up_date,
+				v.updated_at,
+				v.signed_at IS NOT NULL AS is_signed,
+				COALESCE(v.signed_by, '') AS signed_by,
+				COALESCE(v.signed_by_name, '') AS signed_by_name,
+				COALESCE(v.signed_at, TIMESTAMPTZ '0001-01-01 00:00:00Z') AS signed_at,
+				COALESCE(v.content_hash, '') AS content_hash
+			  FROM public.visits v
+			 WHERE v.id = :1
+		`,
+		Oracle: `--sql
+			SELECT
+				v.id,
+				v.status,
+				v.chief_complaint,
+				v.symptoms,
+				v.diagnosis,
+				v.treatment_plan,
+				v.medications_prescribed,
+				v.notes,
+				COALESCE(v.follow_up_date, DATE '0001-01-01') AS follow_up_date,
+				v.updated_at,
+				CASE WHEN v.signed_at IS NOT NULL THEN 1 ELSE 0 END AS is_signed,
+				v.signed_by,
+				v.signed_by_name,
+				COALESCE(v.signed_at, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS signed_at,
+				v.content_hash
+			  FROM visits v
+			 WHERE v.id = :1
+		`,
+	}
+	// signVisit also locks the visit and fails when the note changed after it was hashed
+	signVisit = dialect.Query{
+		Postgres: `--sql
+			UPDATE public.visits SET
+				signed_at = NOW(),
+				signed_by = :1,
+				signed_by_name = :2,
+				content_hash = :3,
+				locked_at = COALESCE(locked_at, NOW()),
+				locked_by = COALESCE(locked_by, :1)
+			 WHERE id = :4 AND status = 'completed' AND signed_at IS NULL AND updated_at = :5
+		`,
+		Oracle: `--sql
+			MERGE INTO visits v
+			USING (SELECT :1 AS signed_by, :2 AS signed_by_name, :3 AS content_hash, :4 AS id, :5 AS updated_at FROM dual) a
+			   ON (v.id = a.id)
+			 WHEN MATCHED THEN UPDATE SET
+				v.signed_at = SYSTIMESTAMP,
+				v.signed_by = a.signed_by,
+				v.signed_by_name = a.signed_by_name,
+				v.content_hash = a.content_hash,
+				v.locked_at = COALESCE(v.locked_at, SYSTIMESTAMP),
+				v.locked_by = COALESCE(v.locked_by, a.signed_by)
+				WHERE v.status = 'completed' AND v.signed_at IS NULL AND v.updated_at = a.updated_at
+		`,
+	}
+	insertAddendum = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.visit_addenda (visit_id, author_id, author_name, text, content_hash)
+			VALUES (:1, :2, :3, :4, :5)
+		`,
+		Oracle: `--sql
+			INSERT INTO visit_addenda (visit_id, author_id, author_name, text, content_hash)
+			VALUES (:1, :2, :3, :4, :5)
+		`,
+	}
+	visitAddenda = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				a.id,
+				a.visit_id,
+				a.author_id,
+				COALESCE(a.author_name, '') AS author_name,
+				a.text,
+				a.content_hash,
+				a.created_at
+			  FROM public.visit_addenda a
+			 WHERE a.visit_id = :1
+			 ORDER BY a.created_at
+		`,
+		Oracle: `--sql
+			SELECT
+				a.id,
+				a.visit_id,
+				a.author_id,
+				a.author_name,
+				a.text,
+				a.content_hash,
+				a.created_at
+			  FROM visit_addenda a
+			 WHERE a.visit_id = :1
+			 ORDER BY a.created_at
+		`,
+	}
+	// calendarVisits lists the visits of a doctor or a patient in a date range, the one of the two
+	// that is empty is ignored
+	calendarVisits = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				v.id,
+				v.doctor_id::TEXT AS doctor_id,
+				v.visit_date,
+				COALESCE(upper(v.slot), TIMESTAMPTZ '0001-01-01 00:00:00Z') AS slot_end,
+				v.visit_type::TEXT AS visit_type,
+				v.status::TEXT AS status,
+				COALESCE(p.full_name, '') AS patient_name,
+				COALESCE(pr.full_name, '') AS doctor_name,
+				COALESCE(v.updated_at, v.created_at, v.visit_date) AS updated_at
+			  FROM public.visits v
+			  JOIN public.patients p ON p.id = v.patient_id
+			  LEFT JOIN public.doctors d ON d.id = v.doctor_id
+			  LEFT JOIN public.profiles pr ON pr.id = d.profile_id
+			 WHERE (v.doctor_id::TEXT = :1 OR v.patient_id::TEXT = :2)
+			   AND v.visit_date >= :3
+			   AND v.visit_date < :4
+			 ORDER BY v.visit_date
+		`,
+		Oracle: `--sql
+			SELECT
+				v.id,
+				v.doctor_id,
+				v.visit_date,
+				COALESCE(v.slot_end, TIMESTAMP '0001-01-01 00:00:00 +00:00') AS slot_end,
+				v.visit_type,
+				v.status,
+				p.full_name AS patient_name,
+				pr.full_name AS doctor_name,
+				COALESCE(v.updated_at, v.created_at, v.visit_date) AS updated_at
+			  FROM visits v
+			  JOIN patients p ON p.id = v.patient_id
+			  LEFT JOIN doctors d ON d.id = v.doctor_id
+			  LEFT JOIN profiles pr ON pr.id = d.profile_id
+			 WHERE (v.doctor_id = :1 OR v.patient_id = :2)
+			   AND v.visit_date >= :3
+			   AND v.visit_date < :4
+			 ORDER BY v.visit_date
+		`,
+	}
+	// visitsPerDay counts the visits of each day in the clinic time zone :4, optionally for one doctor
+	visitsPerDay = dialect.Query{
+		Postgres: `--sql
+			SELECT (v.visit_date AT TIME ZONE :4)::DATE AS day, COUNT(*) AS count
+			  FROM public.visits v
+			 WHERE v.visit_date >= :1
+			   AND v.visit_date < :2
+			   AND (:3 = '' OR v.doctor_id::TEXT = :3)
+			   AND v.status::TEXT NOT IN ('cancelled', 'no_show')
+			 GROUP BY 1
+			 ORDER BY 1
+		`,
+		Oracle: `--sql
+			WITH a AS (SELECT :1 AS date_from, :2 AS date_to, :3 AS doctor_id, :4 AS tz FROM dual)
+			SELECT TRUNC(CAST(v.visit_date AT TIME ZONE a.tz AS DATE)) AS day, COUNT(*) AS count
+			  FROM visits v
+			 CROSS JOIN a
+			 WHERE v.visit_date >= a.date_from
+			   AND v.visit_date < a.date_to
+			   AND (a.doctor_id IS NULL OR v.doctor_id = a.doctor_id)
+			   AND v.status NOT IN ('cancelled', 'no_show')
+			 GROUP BY TRUNC(CAST(v.visit_date AT TIME ZONE a.tz AS DATE))
+			 ORDER BY 1
+		`,
+	}
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"calendarVisits":   calendarVisits,
+	"insertAddendum":   insertAddendum,
+	"lockVisit":        lockVisit,
+	"signVisit":        signVisit,
+	"updateStatus":     updateStatus,
+	"updateVisit":      updateVisit,
+	"visitAddenda":     visitAddenda,
+	"visitByID":        visitByID,
+	"visitContent":     visitContent,
+	"visitCount":       visitCount,
+	"visitList":        visitList,
+	"visitState":       visitState,
+	"visitTransitions": visitTransitions,
+	"visitsPerDay":     visitsPerDay,
+}
@@ -0,0 +1,323 @@
+package vitals
+
+import (
+	"healthcare/controllers/ums"
+	"healthcare/controllers/visits"
+	"healthcare/models"
+	"healthcare/utils/jalali"
+	"healthcare/utils/validation"
+	"healthcare/utils/vitals"
+	"net/http"
+	"time"
+
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/handlers"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libParams"
+	"github.com/hmmftg/requestCore/libQuery"
+	"github.com/hmmftg/requestCore/libRequest"
+	"github.com/hmmftg/requestCore/webFramework"
+)
+
+type vitalsEnv struct {
+	Params    libParams.ParamInterface
+	Interface requestCore.RequestCoreInterface
+	Evaluator vitals.Evaluator
+	Location  *time.Location
+}
+
+func (env *vitalsEnv) GetInterface() requestCore.RequestCoreInterface {
+	return env.Interface
+}
+func (env *vitalsEnv) GetParams() libParams.ParamInterface {
+	return env.Params
+}
+func (env *vitalsEnv) SetInterface(core requestCore.RequestCoreInterface) {
+	env.Interface = core
+}
+func (env *vitalsEnv) SetParams(parameters libParams.ParamInterface) {
+	env.Params = parameters
+}
+
+type vitalsHandler struct {
+	Name      string
+	Evaluator vitals.Evaluator
+}
+
+// returns handler title
+func (h vitalsHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "vitals",
+		Body:           libRequest.JSON,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/visits/:id/vitals",
+	}
+}
+
+func measurements(req *models.VisitVitalsRequest) vitals.Measurements {
+	return vitals.Measurements{
+		BPSystolic:      req.BPSystolic,
+		BPDiastolic:     req.BPDiastolic,
+		Pulse:           req.Pulse,
+		Temperature:     req.Temperature,
+		SpO2:            req.SpO2,
+		RespiratoryRate: req.RespiratoryRate,
+		Weight:          req.Weight,
+		Height:          req.Height,
+	}
+}
+
+// runs after validating request
+func (h vitalsHandler) Initializer(req handlers.HandlerRequest[models.VisitVitalsRequest, *models.VisitVitalsResponse]) error {
+	req.Request.VisitID = req.W.Parser.GetUrlParam("id")
+	if req.Request.VisitID == "" {
+		return libError.NewWithDescription(http.StatusBadRequest, "INVALID_VISIT_ID", "visit id is required")
+	}
+	if req.Request.RecordedAt.IsZero() {
+		req.Request.RecordedAt = time.Now()
+	}
+	fields, err := validation.Check(req.Request)
+	return validation.Fail(append(fields, measurements(req.Request).Validate()...), err)
+}
+
+// Handler is the main method that handles request and returns the response
+func (h vitalsHandler) Handler(req handlers.HandlerRequest[models.VisitVitalsRequest, *models.VisitVitalsResponse]) (*models.VisitVitalsResponse, error) {
+	switch h.Name {
+	case "vitals-post":
+		user, err := ums.CurrentUser(req.W, req.Core)
+		if err != nil {
+			return nil, err
+		}
+		visit, err := libQuery.GetQuery[models.VisitPatientRow](visitPatient.SQL(), req.Core.GetDB(), req.Request.VisitID)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VISIT", err.Error())
+		}
+		if len(visit) == 0 {
+			return nil, libError.NewWithDescription(http.StatusNotFound, "VISIT_NOT_FOUND", "visit %s not found", req.Request.VisitID)
+		}
+		if err = visits.EnsureEditable(req.Core, req.Request.VisitID); err != nil {
+			return nil, err
+		}
+
+		m := measurements(req.Request)
+		age := vitals.Age(visit[0].DateOfBirth, req.Request.RecordedAt)
+		flags := h.Evaluator.Flags(m, age)
+		group, _ := h.Evaluator.Group(age)
+		var bmi *float64
+		if value, ok := m.BMI(); ok {
+			bmi = &value
+		}
+
+		result, err := req.Core.GetDB().InsertRow(insertVitals.SQL(),
+			req.Request.VisitID, visit[0].PatientID, req.Request.RecordedAt,
+			req.Request.BPSystolic, req.Request.BPDiastolic, req.Request.Pulse, req.Request.Temperature,
+			req.Request.SpO2, req.Request.RespiratoryRate, req.Request.Weight, req.Request.Height,
+			bmi, vitals.FormatFlags(flags), user.UserId)
+		if err != nil {
+			return nil, libError.New(http.StatusInternalServerError, "ERROR_INSERT", err.Error())
+		}
+		req.Response = &models.VisitVitalsResponse{
+			Result:        libQuery.GetDmlResult(result, nil),
+			AgeGroup:      group.Name,
+			AbnormalFlags: flags,
+		}
+		if bmi != nil {
+			req.Response.BMI = *bmi
+		}
+		return req.Response, nil
+	}
+	return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+}
+
+// Simulation returns a simulated response
+func (h vitalsHandler) Simulation(req handlers.HandlerRequest[models.VisitVitalsRequest, *models.VisitVitalsResponse]) (*models.VisitVitalsResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h vitalsHandler) Finalizer(req handlers.HandlerRequest[models.VisitVitalsRequest, *models.VisitVitalsResponse]) {
+}
+
+type vitalsSeriesHandler struct {
+	Name     string
+	Location *time.Location
+}
+
+// returns handler title
+func (h vitalsSeriesHandler) Parameters() handlers.HandlerParameters {
+	return handlers.HandlerParameters{
+		Title:          "vitals",
+		Body:           libRequest.Query,
+		ValidateHeader: true,
+		SaveToRequest:  false,
+		Path:           "/vitals",
+	}
+}
+
+// runs after validating request
+func (h vitalsSeriesHandler) Initializer(req handlers.HandlerRequest[models.VitalsSeriesRequest, *models.VitalsSeriesResponse]) error {
+	return nil
+}
+
+// seriesRange returns the instants [from, to) of the measurements of a request, its days are in the
+// calendar it selects and in the clinic time zone loc, to is inclusive and defaults to now and from
+// defaults to a year before to
+func seriesRange(w webFramework.WebFramework, request *models.VitalsSeriesRequest, loc *time.Location) (time.Time, time.Time, error) {
+	calendar, err := jalali.FromRequest(w, request.Calendar)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := jalali.ParseDay(calendar, request.From, "from", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := jalali.ParseDay(calendar, request.To, "to", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	} else {
+		// the end date is inclusive
+		to = to.AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(-1, 0, 0)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, libError.NewWithDescription(http.StatusBadRequest, "INVALID_DATE_RANGE", "from must be before to")
+	}
+	return from, to, nil
+}
+
+// Handler is the main method that handles request and returns the response
+func (h vitalsSeriesHandler) Handler(req handlers.HandlerRequest[models.VitalsSeriesRequest, *models.VitalsSeriesResponse]) (*models.VitalsSeriesResponse, error) {
+	id := req.W.Parser.GetUrlParam("id")
+	var rows []models.VisitVitalsRow
+	var err error
+	switch h.Name {
+	case "vitals-visit":
+		rows, err = libQuery.GetQuery[models.VisitVitalsRow](vitalsByVisit.SQL(), req.Core.GetDB(), id)
+		req.Response = &models.VitalsSeriesResponse{VisitID: id}
+	case "vitals-patient":
+		var from, to time.Time
+		if from, to, err = seriesRange(req.W, req.Request, h.Location); err != nil {
+			return nil, err
+		}
+		rows, err = libQuery.GetQuery[models.VisitVitalsRow](vitalsByPatient.SQL(), req.Core.GetDB(), id, from, to)
+		req.Response = &models.VitalsSeriesResponse{PatientID: id}
+	default:
+		return nil, libError.NewWithDescription(http.StatusInternalServerError, "UNKNOWN_METHOD", "method not defined: %s", h.Name)
+	}
+	if err != nil {
+		return nil, libError.New(http.StatusInternalServerError, "ERROR_GET_VITALS", err.Error())
+	}
+	req.Response.Measurements = rows
+	req.Response.Series = buildSeries(rows)
+	return req.Response, nil
+}
+
+// Simulation returns a simulated response
+func (h vitalsSeriesHandler) Simulation(req handlers.HandlerRequest[models.VitalsSeriesRequest, *models.VitalsSeriesResponse]) (*models.VitalsSeriesResponse, error) {
+	return req.Response, nil
+}
+
+// runs after sending back response
+func (h vitalsSeriesHandler) Finalizer(req handlers.HandlerRequest[models.VitalsSeriesRequest, *models.VitalsSeriesResponse]) {
+}
+
+// buildSeries groups rows per measurement in the order of vitals.Names, measurements that were not
+// taken are skipped
+func buildSeries(rows []models.VisitVitalsRow) []models.VitalsSeries {
+	series := make([]models.VitalsSeries, len(vitals.Names))
+	for i, name := range vitals.Names {
+		series[i] = models.VitalsSeries{Name: name, Unit: vitals.Units[name], Points: []models.VitalsPoint{}}
+	}
+	for _, row := range rows {
+		flags := vitals.ParseFlags(string(row.AbnormalFlags))
+		values := map[string]*float64{
+			vitals.BPSystolic:      row.BPSystolic,
+			vitals.BPDiastolic:     row.BPDiastolic,
+			vitals.Pulse:           row.Pulse,
+			vitals.Temperature:     row.Temperature,
+			vitals.SpO2:            row.SpO2,
+			vitals.RespiratoryRate: row.RespiratoryRate,
+			vitals.Weight:          row.Weight,
+			vitals.Height:          row.Height,
+			vitals.BMI:             row.BMI,
+		}
+		for i, name := range vitals.Names {
+			value := values[name]
+			if value == nil {
+				continue
+			}
+			series[i].Points = append(series[i].Points, models.VitalsPoint{
+				VisitID:    row.VisitID,
+				RecordedAt: row.RecordedAt,
+				Value:      *value,
+				Flag:       flags[name],
+			})
+		}
+	}
+	return series
+}
+
+// vitalsPostHandler godoc
+// @Summary Record vital signs of a visit
+// @Description Record typed vital signs, BMI is computed and values outside the normal range of the patient's age group are flagged
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Param vitals body models.VisitVitalsRequest true "Vital signs"
+// @Router /visits/:id/vitals [post]
+// @Security OAuth2Password
+// @Success 200 {object} models.VisitVitalsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env vitalsEnv) vitalsPostHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VisitVitalsRequest, *models.VisitVitalsResponse, vitalsHandler](env.Interface, vitalsHandler{Name: "vitals-post", Evaluator: env.Evaluator}, simulation)
+}
+
+// vitalsVisitHandler godoc
+// @Summary Get vital signs of a visit
+// @Description Get all vital signs recorded during a visit
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Visit ID"
+// @Router /visits/:id/vitals [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VitalsSeriesResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env vitalsEnv) vitalsVisitHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VitalsSeriesRequest, *models.VitalsSeriesResponse, vitalsSeriesHandler](env.Interface, vitalsSeriesHandler{Name: "vitals-visit", Location: env.Location}, simulation)
+}
+
+// vitalsPatientHandler godoc
+// @Summary Get vital signs time-series of a patient
+// @Description Get vital signs of a patient across visits grouped per measurement, defaults to the last year
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Param Request-Id header string true "Request ID"
+// @Param id path string true "Patient ID"
+// @Param X-Calendar header string false "Calendar of the dates" Enums(gregorian, jalali)
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param calendar query string false "Calendar of the dates, wins over the header" Enums(gregorian, jalali)
+// @Router /patients/:id/vitals [get]
+// @Security OAuth2Password
+// @Success 200 {object} models.VitalsSeriesResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+func (env vitalsEnv) vitalsPatientHandler(simulation bool) any {
+	return handlers.BaseHandler[models.VitalsSeriesRequest, *models.VitalsSeriesResponse, vitalsSeriesHandler](env.Interface, vitalsSeriesHandler{Name: "vitals-patient", Location: env.Location}, simulation)
+}
@@ -0,0 +1,95 @@
+package vitals
+
+import "healthcare/utils/dialect"
+
+var (
+	visitPatient = dialect.Query{
+		Postgres: `--sql
+			SELECT v.id AS visit_id, v.patient_id, COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM public.visits v
+			  JOIN public.patients p ON p.id = v.patient_id
+			 WHERE v.id = :1
+		`,
+		Oracle: `--sql
+			SELECT v.id AS visit_id, v.patient_id, COALESCE(p.date_of_birth, DATE '0001-01-01') AS date_of_birth
+			  FROM visits v
+			  JOIN patients p ON p.id = v.patient_id
+			 WHERE v.id = :1
+		`,
+	}
+	insertVitals = dialect.Query{
+		Postgres: `--sql
+			INSERT INTO public.visit_vitals (
+				visit_id, patient_id, recorded_at, bp_systolic, bp_diastolic, pulse, temperature,
+				spo2, respiratory_rate, weight, height, bmi, abnormal_flags, recorded_by
+			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14)
+		`,
+		Oracle: `--sql
+			INSERT INTO visit_vitals (
+				visit_id, patient_id, recorded_at, bp_systolic, bp_diastolic, pulse, temperature,
+				spo2, respiratory_rate, weight, height, bmi, abnormal_flags, recorded_by
+			) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14)
+		`,
+	}
+	selectVitals = dialect.Query{
+		Postgres: `--sql
+			SELECT
+				vv.id,
+				vv.visit_id,
+				vv.patient_id,
+				vv.recorded_at,
+				vv.bp_systolic,
+				vv.bp_diastolic,
+				vv.pulse,
+				vv.temperature,
+				vv.spo2,
+				vv.respiratory_rate,
+				vv.weight,
+				vv.height,
+				vv.bmi,
+				COALESCE(vv.abnormal_flags, '') AS abnormal_flags,
+				COALESCE(vv.recorded_by, '') AS recorded_by,
+				vv.created_at
+			  FROM public.visit_vitals vv
+		`,
+		Oracle: `--sql
+			SELECT
+				vv.id,
+				vv.visit_id,
+				vv.patient_id,
+				vv.recorded_at,
+				vv.bp_systolic,
+				vv.bp_diastolic,
+				vv.pulse,
+				vv.temperature,
+				vv.spo2,
+				vv.respiratory_rate,
+				vv.weight,
+				vv.height,
+				vv.bmi,
+				vv.abnormal_flags,
+				vv.recorded_by,
+				vv.created_at
+			  FROM visit_vitals vv
+		`,
+	}
+	vitalsByVisit = selectVitals.Append(`
+		 WHERE vv.visit_id = :1
+		 ORDER BY vv.recorded_at
+	`)
+	vitalsByPatient = selectVitals.Append(`
+		 WHERE vv.patient_id = :1
+		   AND vv.recorded_at >= :2
+		   AND vv.recorded_at < :3
+		 ORDER BY vv.recorded_at
+	`)
+)
+
+// Statements are the statements of the package by name, cmd/querycheck prepares each variant against
+// a migrated database of its dialect
+var Statements = map[string]dialect.Query{
+	"insertVitals":    insertVitals,
+	"visitPatient":    visitPatient,
+	"vitalsByPatient": vitalsByPatient,
+	"vitalsByVisit":   vitalsByVisit,
+}
@@ -0,0 +1,30 @@
+package vitals
+
+import (
+	"healthcare/controllers/appointments"
+	"healthcare/models"
+	"healthcare/utils/vitals"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hmmftg/requestCore"
+	"github.com/hmmftg/requestCore/libGin"
+	"github.com/hmmftg/requestCore/libParams"
+)
+
+func AddVitalsRoutes(
+	model *requestCore.RequestCoreModel,
+	wsParams *libParams.ApplicationParams[models.ApplicationParams],
+	_ map[string]string,
+	rg *gin.RouterGroup,
+	simulation bool,
+) {
+	env := &vitalsEnv{
+		Interface: model,
+		Params:    wsParams,
+		Evaluator: vitals.NewEvaluator(wsParams.Specific.VitalRanges),
+		Location:  appointments.LoadLocation(wsParams),
+	}
+	rg.POST("/visits/:id/vitals", libGin.Gin(env.vitalsPostHandler(simulation)))
+	rg.GET("/visits/:id/vitals", libGin.Gin(env.vitalsVisitHandler(simulation)))
+	rg.GET("/patients/:id/vitals", libGin.Gin(env.vitalsPatientHandler(simulation)))
+}
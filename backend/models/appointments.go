@@ -0,0 +1,118 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// WorkingHoursRequest represents the request structure for replacing the weekly working hours of a doctor
+type WorkingHoursRequest struct {
+	DoctorID string              `json:"doctor_id"`
+	Hours    []WorkingHoursEntry `json:"hours" validate:"dive"`
+}
+
+// WorkingHoursEntry represents the working hours of one weekday, 0 is Sunday, times are HH:MM in the clinic time zone
+type WorkingHoursEntry struct {
+	Weekday     int    `json:"weekday" validate:"gte=0,lte=6"`
+	StartTime   string `json:"start_time" validate:"required"`
+	EndTime     string `json:"end_time" validate:"required"`
+	SlotMinutes int    `json:"slot_minutes" validate:"gte=5,lte=480"`
+}
+
+// WorkingHoursResponse represents the response structure for working hours operations
+type WorkingHoursResponse struct {
+	Result libQuery.DmlResult `json:"result"`
+	Hours  []WorkingHoursRow  `json:"hours"`
+}
+
+// WorkingHoursRow represents a single working hours record
+type WorkingHoursRow struct {
+	ID          string       `json:"id" db:"ID"`
+	DoctorID    string       `json:"doctor_id" db:"DOCTOR_ID"`
+	Weekday     int          `json:"weekday" db:"WEEKDAY"`
+	StartTime   dialect.Text `json:"start_time" db:"START_TIME"`
+	EndTime     dialect.Text `json:"end_time" db:"END_TIME"`
+	SlotMinutes int          `json:"slot_minutes" db:"SLOT_MINUTES"`
+}
+
+// DoctorExceptionRequest represents the request structure for schedule exceptions of a doctor,
+// leave and holidays without times cover the whole day, extra hours need times and a slot length.
+// The rules apply to adding an exception
+type DoctorExceptionRequest struct {
+	DoctorID    string `json:"doctor_id"`
+	ExceptionID string `json:"exception_id"`
+	Date        string `json:"date" validate:"required,datetime=2006-01-02"`
+	StartTime   string `json:"start_time" validate:"required_if=Kind extra,required_with=EndTime"`
+	EndTime     string `json:"end_time" validate:"required_if=Kind extra,required_with=StartTime"`
+	SlotMinutes int    `json:"slot_minutes" validate:"required_if=Kind extra,omitempty,gte=5,lte=480"`
+	Kind        string `json:"kind" validate:"oneof=leave holiday extra"`
+	Reason      string `json:"reason"`
+	From        string `form:"from"`
+	To          string `form:"to"`
+	// Calendar of Date, From and To, it wins over the X-Calendar header
+	Calendar string `json:"calendar" form:"calendar"`
+}
+
+// DoctorExceptionResponse represents the response structure for schedule exception operations
+type DoctorExceptionResponse struct {
+	Result     libQuery.DmlResult   `json:"result"`
+	Exceptions []DoctorExceptionRow `json:"exceptions,omitempty"`
+}
+
+// DoctorExceptionRow represents a single schedule exception, times are empty for whole days
+type DoctorExceptionRow struct {
+	ID          string       `json:"id" db:"ID"`
+	DoctorID    string       `json:"doctor_id" db:"DOCTOR_ID"`
+	Date        time.Time    `json:"date" db:"EXCEPTION_DATE"`
+	StartTime   dialect.Text `json:"start_time" db:"START_TIME"`
+	EndTime     dialect.Text `json:"end_time" db:"END_TIME"`
+	SlotMinutes int          `json:"slot_minutes" db:"SLOT_MINUTES"`
+	Kind        string       `json:"kind" db:"KIND"`
+	Reason      dialect.Text `json:"reason" db:"REASON"`
+}
+
+// SlotsRequest represents the request structure for the free slots of a doctor, both days are inclusive
+// and in Calendar or else in the calendar of the X-Calendar header
+type SlotsRequest struct {
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Calendar string `form:"calendar"`
+}
+
+// SlotsResponse represents the free slots of a doctor
+type SlotsResponse struct {
+	DoctorID string `json:"doctor_id"`
+	Timezone string `json:"timezone"`
+	Slots    []Slot `json:"slots"`
+}
+
+// Slot represents a bookable time range [start, end)
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// BusyRow represents the time range of a booked visit
+type BusyRow struct {
+	Start time.Time `json:"start" db:"SLOT_START"`
+	End   time.Time `json:"end" db:"SLOT_END"`
+}
+
+// BookingRequest represents the request structure for booking a slot, Start must be the start of a free slot
+type BookingRequest struct {
+	DoctorID       string    `json:"doctor_id"`
+	PatientID      string    `json:"patient_id" validate:"required"`
+	Start          time.Time `json:"start" validate:"required"`
+	VisitType      string    `json:"visit_type" validate:"omitempty,oneof=general dentistry specialist"`
+	ChiefComplaint string    `json:"chief_complaint"`
+	Notes          string    `json:"notes"`
+}
+
+// BookingResponse represents the scheduled visit of a booking
+type BookingResponse struct {
+	Result  libQuery.DmlResult `json:"result"`
+	VisitID string             `json:"visit_id"`
+	Slot    Slot               `json:"slot"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// CalendarTokenRequest represents the request structure for managing the calendar feed of the current user
+type CalendarTokenRequest struct {
+	UserID string `json:"-"`
+}
+
+// CalendarTokenResponse represents the response structure for calendar feed tokens, Token and URL are only
+// returned when the token is generated since only its hash is kept
+type CalendarTokenResponse struct {
+	Result        libQuery.DmlResult `json:"result"`
+	Token         string             `json:"token,omitempty"`
+	URL           string             `json:"url,omitempty"`
+	Exists        bool               `json:"exists"`
+	CreatedAt     time.Time          `json:"created_at,omitempty"`
+	LastFetchedAt time.Time          `json:"last_fetched_at,omitempty"`
+}
+
+// CalendarFeedRow represents the calendar feed of a user
+type CalendarFeedRow struct {
+	UserID        string    `json:"user_id" db:"USER_ID"`
+	CreatedAt     time.Time `json:"created_at" db:"CREATED_AT"`
+	LastFetchedAt time.Time `json:"last_fetched_at" db:"LAST_FETCHED_AT"`
+}
+
+// CalendarOwnerRow represents the doctor and patient records of a user, either may be empty
+type CalendarOwnerRow struct {
+	DoctorID  dialect.Text `db:"DOCTOR_ID"`
+	PatientID dialect.Text `db:"PATIENT_ID"`
+	Name      dialect.Text `db:"NAME"`
+}
+
+// CalendarVisitRow represents a visit in a calendar feed, SlotEnd is zero for visits booked without a slot
+type CalendarVisitRow struct {
+	ID          string       `db:"ID"`
+	DoctorID    string       `db:"DOCTOR_ID"`
+	VisitDate   time.Time    `db:"VISIT_DATE"`
+	SlotEnd     time.Time    `db:"SLOT_END"`
+	VisitType   string       `db:"VISIT_TYPE"`
+	Status      string       `db:"STATUS"`
+	PatientName dialect.Text `db:"PATIENT_NAME"`
+	DoctorName  dialect.Text `db:"DOCTOR_NAME"`
+	UpdatedAt   time.Time    `db:"UPDATED_AT"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// ICD10Row represents a single code of the ICD-10 code table
+type ICD10Row struct {
+	Code          string       `json:"code" db:"CODE"`
+	DescriptionEn string       `json:"description_en" db:"DESCRIPTION_EN"`
+	DescriptionFa dialect.Text `json:"description_fa" db:"DESCRIPTION_FA"`
+}
+
+// ICD10SearchRequest represents the request structure for ICD-10 autocomplete
+type ICD10SearchRequest struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+// ICD10ImportRequest represents the request structure for importing the ICD-10 code table
+// from the configured local file
+type ICD10ImportRequest struct {
+}
+
+// ICD10ImportResponse represents the response structure for importing the ICD-10 code table
+type ICD10ImportResponse struct {
+	Path     string `json:"path"`
+	Imported int    `json:"imported"`
+}
+
+// VisitDiagnosisRequest represents the request structure for coding a diagnosis of a visit
+type VisitDiagnosisRequest struct {
+	ID        string `json:"id"`
+	VisitID   string `json:"visit_id"`
+	ICD10Code string `json:"icd10_code" validate:"required"`
+	IsPrimary bool   `json:"is_primary"`
+	Notes     string `json:"notes"`
+}
+
+// VisitDiagnosisResponse represents the response structure for visit diagnosis operations
+type VisitDiagnosisResponse struct {
+	Result    libQuery.DmlResult  `json:"result"`
+	Diagnoses []VisitDiagnosisRow `json:"diagnoses,omitempty"`
+}
+
+// VisitDiagnosisRow represents a single coded diagnosis of a visit
+type VisitDiagnosisRow struct {
+	ID            string       `json:"id" db:"ID"`
+	VisitID       string       `json:"visit_id" db:"VISIT_ID"`
+	ICD10Code     string       `json:"icd10_code" db:"ICD10_CODE"`
+	DescriptionEn string       `json:"description_en" db:"DESCRIPTION_EN"`
+	DescriptionFa dialect.Text `json:"description_fa" db:"DESCRIPTION_FA"`
+	IsPrimary     bool         `json:"is_primary" db:"IS_PRIMARY"`
+	Notes         dialect.Text `json:"notes" db:"NOTES"`
+	CreatedAt     time.Time    `json:"created_at" db:"CREATED_AT"`
+}
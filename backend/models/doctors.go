@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/hmmftg/requestCore/libQuery"
+	"healthcare/utils/dialect"
 )
 
 type DoctorsRequest struct {
@@ -14,8 +15,8 @@ type DoctorsResponse struct {
 }
 
 type DoctorsRow struct {
-	ID   string `form:"id" uri:"id" json:"id" db:"ID"`
-	Name string `json:"name" db:"NAME"`
+	ID   string       `form:"id" uri:"id" json:"id" db:"ID"`
+	Name dialect.Text `json:"name" db:"NAME"`
 }
 
 const (
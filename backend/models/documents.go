@@ -0,0 +1,47 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// PatientDocumentRequest represents the request structure for patient document uploads,
+// the fields are sent as multipart form fields next to the file
+type PatientDocumentRequest struct {
+	Category     string `form:"category" json:"category"`
+	Title        string `form:"title" json:"title"`
+	Description  string `form:"description" json:"description"`
+	DocumentDate string `form:"document_date" json:"document_date"`
+	RetainUntil  string `form:"retain_until" json:"retain_until"`
+}
+
+// PatientDocumentResponse represents the response structure for patient document operations,
+// Duplicate is set when an upload matched the checksum of a stored document of the patient
+type PatientDocumentResponse struct {
+	Result    libQuery.DmlResult  `json:"result"`
+	Document  *PatientDocumentRow `json:"document,omitempty"`
+	Duplicate bool                `json:"duplicate,omitempty"`
+}
+
+// PatientDocumentRow represents a single patient document record
+type PatientDocumentRow struct {
+	ID            string       `json:"id" db:"ID"`
+	PatientID     string       `json:"patient_id" db:"PATIENT_ID"`
+	Category      string       `json:"category" db:"CATEGORY"`
+	Title         dialect.Text `json:"title" db:"TITLE"`
+	Description   dialect.Text `json:"description" db:"DESCRIPTION"`
+	FileName      string       `json:"file_name" db:"FILE_NAME"`
+	FileSize      int64        `json:"file_size" db:"FILE_SIZE"`
+	MimeType      string       `json:"mime_type" db:"MIME_TYPE"`
+	Checksum      string       `json:"checksum" db:"CHECKSUM"`
+	StorageKey    string       `json:"-" db:"STORAGE_KEY"`
+	DownloadURL   string       `json:"download_url,omitempty"`
+	ScanStatus    string       `json:"scan_status" db:"SCAN_STATUS"`
+	ScanSignature dialect.Text `json:"scan_signature,omitempty" db:"SCAN_SIGNATURE"`
+	DocumentDate  time.Time    `json:"document_date" db:"DOCUMENT_DATE"`
+	RetainUntil   time.Time    `json:"retain_until" db:"RETAIN_UNTIL"`
+	UploadedBy    dialect.Text `json:"uploaded_by" db:"UPLOADED_BY"`
+	CreatedAt     time.Time    `json:"created_at" db:"CREATED_AT"`
+}
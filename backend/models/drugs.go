@@ -0,0 +1,41 @@
+package models
+
+// DrugRow represents a single drug of the reference dataset
+type DrugRow struct {
+	GenericName string   `json:"generic_name"`
+	Class       string   `json:"class"`
+	Aliases     []string `json:"aliases"`
+}
+
+// DrugInteractionRequest represents the request structure for checking a proposed drug
+// against the active medications of a patient, either PatientID or VisitID is required
+type DrugInteractionRequest struct {
+	PatientID      string `json:"patient_id" validate:"required_without=VisitID"`
+	VisitID        string `json:"visit_id"`
+	MedicationName string `json:"medication_name" validate:"required"`
+}
+
+// DrugInteraction represents an interaction found between the proposed drug and an active medication
+type DrugInteraction struct {
+	ProposedDrug   string `json:"proposed_drug"`
+	ExistingDrug   string `json:"existing_drug"`
+	MedicationID   string `json:"medication_id"`
+	VisitID        string `json:"visit_id"`
+	Severity       string `json:"severity"`
+	Description    string `json:"description"`
+	RequiresReview bool   `json:"requires_review"`
+}
+
+// DrugInteractionResponse represents the response structure for drug interaction checks
+type DrugInteractionResponse struct {
+	MedicationName string            `json:"medication_name"`
+	KnownDrug      bool              `json:"known_drug"`
+	Interactions   []DrugInteraction `json:"interactions"`
+}
+
+// ActiveMedicationRow represents an active medication of a patient across all visits
+type ActiveMedicationRow struct {
+	ID             string `json:"id" db:"ID"`
+	VisitID        string `json:"visit_id" db:"VISIT_ID"`
+	MedicationName string `json:"medication_name" db:"MEDICATION_NAME"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Event types pushed to waiting-room displays
+const (
+	EventQueueRegistered = "queue.registered"
+	EventQueueCalled     = "queue.called"
+	EventQueueSkipped    = "queue.skipped"
+	EventQueueRequeued   = "queue.requeued"
+	EventVisitStatus     = "visit.status"
+)
+
+// QueueEvent represents a change of a queue entry, it carries no patient details since it is shown on public screens
+type QueueEvent struct {
+	EntryID    string `json:"entry_id"`
+	QueueKey   string `json:"queue_key"`
+	Number     int    `json:"number"`
+	Status     string `json:"status"`
+	DoctorID   string `json:"doctor_id,omitempty"`
+	DoctorName string `json:"doctor_name,omitempty"`
+	Department string `json:"department,omitempty"`
+}
+
+// VisitStatusEvent represents a status transition of a visit
+type VisitStatusEvent struct {
+	VisitID    string `json:"visit_id"`
+	DoctorID   string `json:"doctor_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// JobRequest represents the request structure for inspecting and triggering background jobs
+type JobRequest struct {
+	Name string `json:"name"`
+}
+
+// JobResponse represents the response structure for background job operations
+type JobResponse struct {
+	Result libQuery.DmlResult `json:"result"`
+	Jobs   []JobRow           `json:"jobs,omitempty"`
+	Runs   []JobRunRow        `json:"runs,omitempty"`
+}
+
+// JobRow represents the persisted state of a background job, a replica holds the lease while it runs the job
+type JobRow struct {
+	Name       string       `json:"name" db:"NAME"`
+	Schedule   string       `json:"schedule" db:"SCHEDULE"`
+	Enabled    bool         `json:"enabled" db:"ENABLED"`
+	NextRunAt  time.Time    `json:"next_run_at" db:"NEXT_RUN_AT"`
+	Attempt    int          `json:"attempt" db:"ATTEMPT"`
+	Triggered  bool         `json:"triggered" db:"TRIGGERED"`
+	LeaseOwner dialect.Text `json:"lease_owner" db:"LEASE_OWNER"`
+	LeaseUntil time.Time    `json:"lease_until" db:"LEASE_UNTIL"`
+	LastRunAt  time.Time    `json:"last_run_at" db:"LAST_RUN_AT"`
+	LastStatus dialect.Text `json:"last_status" db:"LAST_STATUS"`
+	LastError  dialect.Text `json:"last_error" db:"LAST_ERROR"`
+}
+
+// JobRunRow represents a single run of a background job
+type JobRunRow struct {
+	ID         string       `json:"id" db:"ID"`
+	JobName    string       `json:"job_name" db:"JOB_NAME"`
+	Attempt    int          `json:"attempt" db:"ATTEMPT"`
+	Trigger    string       `json:"trigger" db:"TRIGGER"`
+	Owner      string       `json:"owner" db:"OWNER"`
+	Status     string       `json:"status" db:"STATUS"`
+	Error      dialect.Text `json:"error" db:"ERROR"`
+	StartedAt  time.Time    `json:"started_at" db:"STARTED_AT"`
+	FinishedAt time.Time    `json:"finished_at" db:"FINISHED_AT"`
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"healthcare/utils/labs"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// LabPanelsResponse represents the orderable lab panels
+type LabPanelsResponse struct {
+	Panels []labs.Panel `json:"panels"`
+}
+
+// LabOrderRequest represents the request structure for ordering a lab panel for a visit
+type LabOrderRequest struct {
+	VisitID   string `json:"visit_id"`
+	OrderID   string `json:"order_id"`
+	PanelCode string `json:"panel_code" validate:"required"`
+	Notes     string `json:"notes"`
+}
+
+// LabOrderResponse represents the response structure for lab order operations
+type LabOrderResponse struct {
+	Result libQuery.DmlResult `json:"result"`
+	Order  *LabOrderRow       `json:"order,omitempty"`
+	Orders []LabOrderRow      `json:"orders,omitempty"`
+}
+
+// LabOrderRow represents a single lab order with its results
+type LabOrderRow struct {
+	ID            string         `json:"id" db:"ID"`
+	VisitID       string         `json:"visit_id" db:"VISIT_ID"`
+	PatientID     string         `json:"patient_id" db:"PATIENT_ID"`
+	PanelCode     string         `json:"panel_code" db:"PANEL_CODE"`
+	PanelName     string         `json:"panel_name" db:"PANEL_NAME"`
+	Status        string         `json:"status" db:"STATUS"`
+	Notes         dialect.Text   `json:"notes" db:"NOTES"`
+	OrderedBy     string         `json:"ordered_by" db:"ORDERED_BY"`
+	OrderedByName dialect.Text   `json:"ordered_by_name" db:"ORDERED_BY_NAME"`
+	OrderedAt     time.Time      `json:"ordered_at" db:"ORDERED_AT"`
+	CollectedAt   time.Time      `json:"collected_at" db:"COLLECTED_AT"`
+	ResultedAt    time.Time      `json:"resulted_at" db:"RESULTED_AT"`
+	Results       []LabResultRow `json:"results"`
+}
+
+// LabResultsRequest represents the request structure for entering results of a lab order,
+// entering a result again for an analyte amends it
+type LabResultsRequest struct {
+	OrderID     string           `json:"order_id"`
+	CollectedAt time.Time        `json:"collected_at"`
+	Results     []LabResultEntry `json:"results" validate:"required,min=1,dive"`
+}
+
+// LabResultEntry represents the value of a single analyte, Unit is checked against the panel when sent
+type LabResultEntry struct {
+	AnalyteCode string `json:"analyte_code" validate:"required"`
+	Value       string `json:"value" validate:"required"`
+	Unit        string `json:"unit"`
+	Comment     string `json:"comment"`
+}
+
+// LabResultsResponse represents the response structure for entering lab results
+type LabResultsResponse struct {
+	Result        libQuery.DmlResult `json:"result"`
+	Status        string             `json:"status"`
+	AbnormalFlags map[string]string  `json:"abnormal_flags"`
+	Critical      []string           `json:"critical,omitempty"`
+}
+
+// LabResultRow represents a single analyte result, NumericValue is zero for text analytes
+type LabResultRow struct {
+	ID           string       `json:"id" db:"ID"`
+	OrderID      string       `json:"order_id" db:"ORDER_ID"`
+	VisitID      string       `json:"visit_id" db:"VISIT_ID"`
+	PatientID    string       `json:"patient_id" db:"PATIENT_ID"`
+	AnalyteCode  string       `json:"analyte_code" db:"ANALYTE_CODE"`
+	AnalyteName  string       `json:"analyte_name" db:"ANALYTE_NAME"`
+	ValueType    string       `json:"value_type" db:"VALUE_TYPE"`
+	Value        string       `json:"value" db:"VALUE"`
+	NumericValue float64      `json:"numeric_value" db:"NUMERIC_VALUE"`
+	Unit         dialect.Text `json:"unit" db:"UNIT"`
+	RefLow       float64      `json:"ref_low" db:"REF_LOW"`
+	RefHigh      float64      `json:"ref_high" db:"REF_HIGH"`
+	RefText      dialect.Text `json:"ref_text" db:"REF_TEXT"`
+	Flag         dialect.Text `json:"flag" db:"FLAG"`
+	Comment      dialect.Text `json:"comment" db:"COMMENT"`
+	Amended      bool         `json:"amended" db:"AMENDED"`
+	EnteredBy    dialect.Text `json:"entered_by" db:"ENTERED_BY"`
+	ResultedAt   time.Time    `json:"resulted_at" db:"RESULTED_AT"`
+}
+
+// LabPatientRow represents the patient of a lab order or visit, used to pick reference ranges,
+// the order fields are empty when it is loaded for a visit
+type LabPatientRow struct {
+	OrderID     string       `json:"order_id" db:"ORDER_ID"`
+	PanelCode   string       `json:"panel_code" db:"PANEL_CODE"`
+	OrderStatus string       `json:"order_status" db:"ORDER_STATUS"`
+	VisitID     string       `json:"visit_id" db:"VISIT_ID"`
+	PatientID   string       `json:"patient_id" db:"PATIENT_ID"`
+	VisitStatus string       `json:"visit_status" db:"VISIT_STATUS"`
+	Gender      dialect.Text `json:"gender" db:"GENDER"`
+	DateOfBirth time.Time    `json:"date_of_birth" db:"DATE_OF_BIRTH"`
+}
+
+// LabCumulativeRequest represents the request structure for the cumulative lab view of a patient, the
+// days are in Calendar or else in the calendar of the X-Calendar header
+type LabCumulativeRequest struct {
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Analyte  string `form:"analyte"`
+	Calendar string `form:"calendar"`
+}
+
+// LabCumulativeResponse represents results of a patient across visits grouped per analyte
+type LabCumulativeResponse struct {
+	PatientID string      `json:"patient_id"`
+	Analytes  []LabSeries `json:"analytes"`
+}
+
+// LabSeries represents the values of a single analyte over time, oldest first
+type LabSeries struct {
+	Code    string     `json:"code"`
+	Name    string     `json:"name"`
+	Unit    string     `json:"unit"`
+	RefText string     `json:"ref_text"`
+	Points  []LabPoint `json:"points"`
+}
+
+// LabPoint represents a single result of an analyte
+type LabPoint struct {
+	OrderID    string    `json:"order_id"`
+	VisitID    string    `json:"visit_id"`
+	ResultedAt time.Time `json:"resulted_at"`
+	Value      string    `json:"value"`
+	Numeric    *float64  `json:"numeric,omitempty"`
+	Flag       string    `json:"flag,omitempty"`
+}
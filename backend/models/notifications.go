@@ -0,0 +1,70 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// ReminderRow represents an upcoming appointment, follow-up or therapy session a patient is reminded of
+type ReminderRow struct {
+	Kind        string       `json:"kind" db:"KIND"`
+	RefID       string       `json:"ref_id" db:"REF_ID"`
+	DueAt       time.Time    `json:"due_at" db:"DUE_AT"`
+	PatientID   string       `json:"patient_id" db:"PATIENT_ID"`
+	PatientName dialect.Text `json:"patient_name" db:"PATIENT_NAME"`
+	Phone       dialect.Text `json:"phone" db:"PHONE"`
+	Email       dialect.Text `json:"email" db:"EMAIL"`
+	Language    dialect.Text `json:"language" db:"LANGUAGE"`
+	DoctorName  dialect.Text `json:"doctor_name" db:"DOCTOR_NAME"`
+	Therapy     string       `json:"therapy" db:"THERAPY"`
+}
+
+// NotificationRequest represents the request structure for listing and retrying notifications
+type NotificationRequest struct {
+	NotificationID string `json:"notification_id"`
+	Status         string `form:"status"`
+	Kind           string `form:"kind"`
+	PatientID      string `form:"patient_id"`
+}
+
+// NotificationResponse represents the response structure for notification operations
+type NotificationResponse struct {
+	Result        libQuery.DmlResult       `json:"result"`
+	Notifications []NotificationRow        `json:"notifications,omitempty"`
+	Attempts      []NotificationAttemptRow `json:"attempts,omitempty"`
+}
+
+// NotificationRow represents a single reminder to one recipient on one channel
+type NotificationRow struct {
+	ID            string       `json:"id" db:"ID"`
+	Kind          string       `json:"kind" db:"KIND"`
+	RefID         string       `json:"ref_id" db:"REF_ID"`
+	DueAt         time.Time    `json:"due_at" db:"DUE_AT"`
+	PatientID     string       `json:"patient_id" db:"PATIENT_ID"`
+	Channel       string       `json:"channel" db:"CHANNEL"`
+	Recipient     string       `json:"recipient" db:"RECIPIENT"`
+	Language      dialect.Text `json:"language" db:"LANGUAGE"`
+	Subject       dialect.Text `json:"subject" db:"SUBJECT"`
+	Body          string       `json:"body" db:"BODY"`
+	Status        string       `json:"status" db:"STATUS"`
+	Attempts      int          `json:"attempts" db:"ATTEMPTS"`
+	MaxAttempts   int          `json:"max_attempts" db:"MAX_ATTEMPTS"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" db:"NEXT_ATTEMPT_AT"`
+	LastError     dialect.Text `json:"last_error" db:"LAST_ERROR"`
+	SentAt        time.Time    `json:"sent_at" db:"SENT_AT"`
+	CreatedAt     time.Time    `json:"created_at" db:"CREATED_AT"`
+	Obsolete      bool         `json:"-" db:"OBSOLETE"`
+}
+
+// NotificationAttemptRow represents a single delivery attempt of a notification
+type NotificationAttemptRow struct {
+	ID             string       `json:"id" db:"ID"`
+	NotificationID string       `json:"notification_id" db:"NOTIFICATION_ID"`
+	Attempt        int          `json:"attempt" db:"ATTEMPT"`
+	Channel        string       `json:"channel" db:"CHANNEL"`
+	Succeeded      bool         `json:"succeeded" db:"SUCCEEDED"`
+	Error          dialect.Text `json:"error" db:"ERROR"`
+	AttemptedAt    time.Time    `json:"attempted_at" db:"ATTEMPTED_AT"`
+}
@@ -1,5 +1,117 @@
 package models
 
+import (
+	"os"
+
+	"healthcare/utils/notify"
+	"healthcare/utils/scan"
+	"healthcare/utils/storage"
+	"healthcare/utils/vitals"
+)
+
 type ApplicationParams struct {
-	StaticBaseUrl string `yaml:"staticBaseUrl"`
+	StaticBaseUrl     string            `yaml:"staticBaseUrl"`
+	Timezone          string            `yaml:"timezone"`
+	DrugReferencePath string            `yaml:"drugReferencePath"`
+	VitalRanges       []vitals.AgeGroup `yaml:"vitalRanges"`
+	ICD10Path         string            `yaml:"icd10Path"`
+	LabCatalogPath    string            `yaml:"labCatalogPath"`
+	Storage           storage.Config    `yaml:"storage"`
+	Scanner           scan.Config       `yaml:"scanner"`
+	Images            UploadParams      `yaml:"images"`
+	Documents         DocumentParams    `yaml:"documents"`
+	Queue             QueueParams       `yaml:"queue"`
+	Events            EventParams       `yaml:"events"`
+	Notifications     NotifyParams      `yaml:"notifications"`
+	Jobs              JobParams         `yaml:"jobs"`
+	Calendar          CalendarParams    `yaml:"calendar"`
+	Locale            LocaleParams      `yaml:"locale"`
+	Migrations        MigrationParams   `yaml:"migrations"`
+}
+
+// UploadParams limits uploaded files, zero values fall back to the defaults of each subsystem
+type UploadParams struct {
+	MaxSizeMB    int      `yaml:"maxSizeMB"`
+	AllowedTypes []string `yaml:"allowedTypes"`
+}
+
+// DocumentParams configures patient documents, RetentionYears maps a category to the
+// number of years its documents are kept after the document date
+type DocumentParams struct {
+	MaxSizeMB      int            `yaml:"maxSizeMB"`
+	AllowedTypes   []string       `yaml:"allowedTypes"`
+	RetentionYears map[string]int `yaml:"retentionYears"`
+}
+
+// QueueParams configures the walk-in queue, ConsultMinutes estimates waits until enough patients were called
+type QueueParams struct {
+	ConsultMinutes int `yaml:"consultMinutes"`
+}
+
+// EventParams configures live events, Buffer is how many events a client may fall behind before it is
+// dropped and History how many events of a room are kept for reconnecting clients
+type EventParams struct {
+	Buffer           int `yaml:"buffer"`
+	History          int `yaml:"history"`
+	HeartbeatSeconds int `yaml:"heartbeatSeconds"`
+}
+
+// NotifyParams configures reminders: appointments are reminded LeadHours ahead, follow-ups and
+// therapy sessions DaysAhead days ahead. Templates is a JSON file overriding the built-in texts
+type NotifyParams struct {
+	Enabled         bool          `yaml:"enabled"`
+	IntervalMinutes int           `yaml:"intervalMinutes"`
+	LeadHours       int           `yaml:"leadHours"`
+	DaysAhead       int           `yaml:"daysAhead"`
+	MaxAttempts     int           `yaml:"maxAttempts"`
+	BatchSize       int           `yaml:"batchSize"`
+	DefaultLanguage string        `yaml:"defaultLanguage"`
+	ClinicName      string        `yaml:"clinicName"`
+	Templates       string        `yaml:"templates"`
+	SMS             notify.Config `yaml:"sms"`
+	Email           notify.Config `yaml:"email"`
+}
+
+// JobParams configures the background job runner: a replica holds the lease of a job for LeaseSeconds
+// and renews it while the job runs, a failed job is retried after RetryBaseSeconds doubling per attempt
+// up to MaxAttempts. Schedules overrides the built-in schedule of a job by its name
+type JobParams struct {
+	Enabled          bool              `yaml:"enabled"`
+	TickSeconds      int               `yaml:"tickSeconds"`
+	LeaseSeconds     int               `yaml:"leaseSeconds"`
+	MaxAttempts      int               `yaml:"maxAttempts"`
+	RetryBaseSeconds int               `yaml:"retryBaseSeconds"`
+	Schedules        map[string]string `yaml:"schedules"`
+}
+
+// CalendarParams configures iCalendar feeds: PublicURL is prepended to feed urls handed to users and
+// a feed covers PastDays before and FutureDays after today
+type CalendarParams struct {
+	PublicURL      string `yaml:"publicUrl"`
+	RefreshMinutes int    `yaml:"refreshMinutes"`
+	PastDays       int    `yaml:"pastDays"`
+	FutureDays     int    `yaml:"futureDays"`
+}
+
+// LocaleParams configures the languages of error and message descriptions, CatalogPath is a JSON file
+// overriding the built-in texts and DefaultLanguage is used when Accept-Language names none of them
+type LocaleParams struct {
+	DefaultLanguage string `yaml:"defaultLanguage"`
+	CatalogPath     string `yaml:"catalogPath"`
+}
+
+// MigrationParams configures schema migrations, DSN is the connection string of a role allowed to change
+// the schema and falls back to the DATABASE_URL environment variable. RunOnStartup applies pending
+// migrations before the api starts serving
+type MigrationParams struct {
+	RunOnStartup bool   `yaml:"runOnStartup"`
+	DSN          string `yaml:"dsn"`
+}
+
+// Address returns the connection string migrations run with
+func (p MigrationParams) Address() string {
+	if len(p.DSN) > 0 {
+		return p.DSN
+	}
+	return os.Getenv("DATABASE_URL")
 }
@@ -1,30 +1,34 @@
 package models
 
 import (
+	"healthcare/utils/dialect"
+	"healthcare/utils/paging"
+	"healthcare/utils/therapy"
 	"time"
+
 	"github.com/hmmftg/requestCore/libQuery"
 )
 
 // PatientRequest represents the request structure for patient operations
 type PatientRequest struct {
-	ID                      string    `json:"id"`
-	ProfileID               string    `json:"profile_id"`
-	PatientID               string    `json:"patient_id"`
-	EmergencyContactName    string    `json:"emergency_contact_name"`
-	EmergencyContactPhone   string    `json:"emergency_contact_phone"`
-	Allergies               string    `json:"allergies"`
-	CurrentMedications      string    `json:"current_medications"`
-	InsuranceInfo           string    `json:"insurance_info"`
-	MedicalHistory          string    `json:"medical_history"`
-	BloodType               string    `json:"blood_type"`
-	Height                  float64   `json:"height"`
-	Weight                  float64   `json:"weight"`
-	DateOfBirth             time.Time `json:"date_of_birth"`
-	Gender                  string    `json:"gender"`
-	Address                 string    `json:"address"`
-	Phone                   string    `json:"phone"`
-	Email                   string    `json:"email"`
-	FullName                string    `json:"full_name"`
+	ID                    string    `json:"id"`
+	ProfileID             string    `json:"profile_id"`
+	PatientID             string    `json:"patient_id"`
+	EmergencyContactName  string    `json:"emergency_contact_name"`
+	EmergencyContactPhone string    `json:"emergency_contact_phone" validate:"omitempty,e164"`
+	Allergies             string    `json:"allergies"`
+	CurrentMedications    string    `json:"current_medications"`
+	InsuranceInfo         string    `json:"insurance_info"`
+	MedicalHistory        string    `json:"medical_history"`
+	BloodType             string    `json:"blood_type" validate:"omitempty,oneof=A+ A- B+ B- AB+ AB- O+ O-"`
+	Height                float64   `json:"height" validate:"omitempty,gte=20,lte=260"`
+	Weight                float64   `json:"weight" validate:"omitempty,gte=0.3,lte=400"`
+	DateOfBirth           time.Time `json:"date_of_birth" validate:"omitempty,lte"`
+	Gender                string    `json:"gender"`
+	Address               string    `json:"address"`
+	Phone                 string    `json:"phone" validate:"omitempty,e164"`
+	Email                 string    `json:"email" validate:"omitempty,email"`
+	FullName              string    `json:"full_name" validate:"required"`
 }
 
 // PatientResponse represents the response structure for patient operations
@@ -34,46 +38,63 @@ type PatientResponse struct {
 
 // PatientRow represents a single patient record
 type PatientRow struct {
-	ID                      string    `form:"id" uri:"id" json:"id" db:"ID"`
-	ProfileID               string    `json:"profile_id" db:"PROFILE_ID"`
-	PatientID               string    `json:"patient_id" db:"PATIENT_ID"`
-	EmergencyContactName    string    `json:"emergency_contact_name" db:"EMERGENCY_CONTACT_NAME"`
-	EmergencyContactPhone   string    `json:"emergency_contact_phone" db:"EMERGENCY_CONTACT_PHONE"`
-	Allergies               string    `json:"allergies" db:"ALLERGIES"`
-	CurrentMedications      string    `json:"current_medications" db:"CURRENT_MEDICATIONS"`
-	InsuranceInfo           string    `json:"insurance_info" db:"INSURANCE_INFO"`
-	MedicalHistory          string    `json:"medical_history" db:"MEDICAL_HISTORY"`
-	BloodType               string    `json:"blood_type" db:"BLOOD_TYPE"`
-	Height                  float64   `json:"height" db:"HEIGHT"`
-	Weight                  float64   `json:"weight" db:"WEIGHT"`
-	DateOfBirth             time.Time `json:"date_of_birth" db:"DATE_OF_BIRTH"`
-	Gender                  string    `json:"gender" db:"GENDER"`
-	Address                 string    `json:"address" db:"ADDRESS"`
-	Phone                   string    `json:"phone" db:"PHONE"`
-	Email                   string    `json:"email" db:"EMAIL"`
-	FullName                string    `json:"full_name" db:"FULL_NAME"`
-	CreatedAt               time.Time `json:"created_at" db:"CREATED_AT"`
-	UpdatedAt               time.Time `json:"updated_at" db:"UPDATED_AT"`
+	ID                    string    `form:"id" uri:"id" json:"id" db:"ID"`
+	ProfileID             string    `json:"profile_id" db:"PROFILE_ID"`
+	PatientID             string    `json:"patient_id" db:"PATIENT_ID"`
+	EmergencyContactName  string    `json:"emergency_contact_name" db:"EMERGENCY_CONTACT_NAME"`
+	EmergencyContactPhone string    `json:"emergency_contact_phone" db:"EMERGENCY_CONTACT_PHONE"`
+	Allergies             string    `json:"allergies" db:"ALLERGIES"`
+	CurrentMedications    string    `json:"current_medications" db:"CURRENT_MEDICATIONS"`
+	InsuranceInfo         string    `json:"insurance_info" db:"INSURANCE_INFO"`
+	MedicalHistory        string    `json:"medical_history" db:"MEDICAL_HISTORY"`
+	BloodType             string    `json:"blood_type" db:"BLOOD_TYPE"`
+	Height                float64   `json:"height" db:"HEIGHT"`
+	Weight                float64   `json:"weight" db:"WEIGHT"`
+	DateOfBirth           time.Time `json:"date_of_birth" db:"DATE_OF_BIRTH"`
+	Gender                string    `json:"gender" db:"GENDER"`
+	Address               string    `json:"address" db:"ADDRESS"`
+	Phone                 string    `json:"phone" db:"PHONE"`
+	Email                 string    `json:"email" db:"EMAIL"`
+	FullName              string    `json:"full_name" db:"FULL_NAME"`
+	CreatedAt             time.Time `json:"created_at" db:"CREATED_AT"`
+	UpdatedAt             time.Time `json:"updated_at" db:"UPDATED_AT"`
+}
+
+// PatientIdentityRow represents the identity of a patient that uploaded files are checked against
+type PatientIdentityRow struct {
+	ID            string       `json:"id" db:"ID"`
+	PatientNumber string       `json:"patient_number" db:"PATIENT_NUMBER"`
+	FullName      dialect.Text `json:"full_name" db:"FULL_NAME"`
 }
 
 // VisitRequest represents the request structure for visit operations
 type VisitRequest struct {
-	ID                  string    `json:"id"`
-	PatientID           string    `json:"patient_id"`
-	DoctorID            string    `json:"doctor_id"`
-	VisitType           string    `json:"visit_type"`
-	VisitDate           time.Time `json:"visit_date"`
-	Status              string    `json:"status"`
-	ChiefComplaint      string    `json:"chief_complaint"`
-	Symptoms            string    `json:"symptoms"`
-	Diagnosis           string    `json:"diagnosis"`
-	TreatmentPlan        string    `json:"treatment_plan"`
-	MedicationsPrescribed string  `json:"medications_prescribed"`
-	Notes               string    `json:"notes"`
-	FollowUpDate        time.Time `json:"follow_up_date"`
-	VitalSigns          string    `json:"vital_signs"`
-	ExaminationNotes    string    `json:"examination_notes"`
-	LabResults          string    `json:"lab_results"`
+	ID                    string    `json:"id"`
+	PatientID             string    `json:"patient_id" validate:"required"`
+	DoctorID              string    `json:"doctor_id" validate:"required"`
+	VisitType             string    `json:"visit_type" validate:"required,oneof=general dentistry specialist"`
+	VisitDate             time.Time `json:"visit_date"`
+	Status                string    `json:"status"`
+	ChiefComplaint        string    `json:"chief_complaint"`
+	Symptoms              string    `json:"symptoms"`
+	Diagnosis             string    `json:"diagnosis"`
+	TreatmentPlan         string    `json:"treatment_plan"`
+	MedicationsPrescribed string    `json:"medications_prescribed"`
+	Notes                 string    `json:"notes"`
+	FollowUpDate          time.Time `json:"follow_up_date" validate:"omitempty,gtfield=VisitDate"`
+	VitalSigns            string    `json:"vital_signs"`
+	ExaminationNotes      string    `json:"examination_notes"`
+	LabResults            string    `json:"lab_results"`
+}
+
+// VisitFilter represents the filters of the visit list, each one is optional, and its page
+type VisitFilter struct {
+	ID        string `form:"id" uri:"id" json:"id"`
+	PatientID string `form:"patient_id" json:"patient_id"`
+	DoctorID  string `form:"doctor_id" json:"doctor_id"`
+	Status    string `form:"status" json:"status"`
+	VisitType string `form:"visit_type" json:"visit_type"`
+	paging.Params
 }
 
 // VisitResponse represents the response structure for visit operations
@@ -81,35 +102,42 @@ type VisitResponse struct {
 	Result libQuery.DmlResult `json:"result"`
 }
 
-// VisitRow represents a single visit record
+// VisitRow represents a single visit record, vital signs and lab results are recorded by their own
+// endpoints and the free text fields kept for them stay empty
 type VisitRow struct {
-	ID                  string    `form:"id" uri:"id" json:"id" db:"ID"`
-	PatientID           string    `json:"patient_id" db:"PATIENT_ID"`
-	DoctorID            string    `json:"doctor_id" db:"DOCTOR_ID"`
-	VisitType           string    `json:"visit_type" db:"VISIT_TYPE"`
-	VisitDate           time.Time `json:"visit_date" db:"VISIT_DATE"`
-	Status              string    `json:"status" db:"STATUS"`
-	ChiefComplaint      string    `json:"chief_complaint" db:"CHIEF_COMPLAINT"`
-	Symptoms            string    `json:"symptoms" db:"SYMPTOMS"`
-	Diagnosis           string    `json:"diagnosis" db:"DIAGNOSIS"`
-	TreatmentPlan        string    `json:"treatment_plan" db:"TREATMENT_PLAN"`
-	MedicationsPrescribed string  `json:"medications_prescribed" db:"MEDICATIONS_PRESCRIBED"`
-	Notes               string    `json:"notes" db:"NOTES"`
-	FollowUpDate        time.Time `json:"follow_up_date" db:"FOLLOW_UP_DATE"`
-	VitalSigns          string    `json:"vital_signs" db:"VITAL_SIGNS"`
-	ExaminationNotes    string    `json:"examination_notes" db:"EXAMINATION_NOTES"`
-	LabResults          string    `json:"lab_results" db:"LAB_RESULTS"`
-	CreatedAt           time.Time `json:"created_at" db:"CREATED_AT"`
-	UpdatedAt           time.Time `json:"updated_at" db:"UPDATED_AT"`
-}
-
-// VisitImageRequest represents the request structure for visit image operations
+	ID                    string       `form:"id" uri:"id" json:"id" db:"ID"`
+	PatientID             string       `json:"patient_id" db:"PATIENT_ID"`
+	DoctorID              string       `json:"doctor_id" db:"DOCTOR_ID"`
+	VisitType             string       `json:"visit_type" db:"VISIT_TYPE"`
+	VisitDate             time.Time    `json:"visit_date" db:"VISIT_DATE"`
+	Status                string       `json:"status" db:"STATUS"`
+	ChiefComplaint        dialect.Text `json:"chief_complaint" db:"CHIEF_COMPLAINT"`
+	Symptoms              dialect.Text `json:"symptoms" db:"SYMPTOMS"`
+	Diagnosis             dialect.Text `json:"diagnosis" db:"DIAGNOSIS"`
+	TreatmentPlan         dialect.Text `json:"treatment_plan" db:"TREATMENT_PLAN"`
+	MedicationsPrescribed dialect.Text `json:"medications_prescribed" db:"MEDICATIONS_PRESCRIBED"`
+	Notes                 dialect.Text `json:"notes" db:"NOTES"`
+	FollowUpDate          time.Time    `json:"follow_up_date" db:"FOLLOW_UP_DATE"`
+	VitalSigns            dialect.Text `json:"vital_signs" db:"VITAL_SIGNS"`
+	ExaminationNotes      dialect.Text `json:"examination_notes" db:"EXAMINATION_NOTES"`
+	LabResults            dialect.Text `json:"lab_results" db:"LAB_RESULTS"`
+	CreatedAt             time.Time    `json:"created_at" db:"CREATED_AT"`
+	UpdatedAt             time.Time    `json:"updated_at" db:"UPDATED_AT"`
+}
+
+// VisitCountRow represents the number of visits matching the filters of the visit list
+type VisitCountRow struct {
+	Count int `json:"count" db:"COUNT"`
+}
+
+// VisitImageRequest represents the request structure for visit image operations,
+// uploads send Description and ImageType as multipart form fields next to the file
 type VisitImageRequest struct {
 	ID          string `json:"id"`
 	VisitID     string `json:"visit_id"`
 	ImageURL    string `json:"image_url"`
-	Description string `json:"description"`
-	ImageType   string `json:"image_type"`
+	Description string `form:"description" json:"description"`
+	ImageType   string `form:"image_type" json:"image_type"`
 	FileName    string `json:"file_name"`
 	FileSize    int64  `json:"file_size"`
 	MimeType    string `json:"mime_type"`
@@ -118,121 +146,241 @@ type VisitImageRequest struct {
 // VisitImageResponse represents the response structure for visit image operations
 type VisitImageResponse struct {
 	Result libQuery.DmlResult `json:"result"`
+	Image  *VisitImageRow     `json:"image,omitempty"`
 }
 
 // VisitImageRow represents a single visit image record
+
 type VisitImageRow struct {
-	ID          string    `form:"id" uri:"id" json:"id" db:"ID"`
-	VisitID     string    `json:"visit_id" db:"VISIT_ID"`
-	ImageURL    string    `json:"image_url" db:"IMAGE_URL"`
-	Description string    `json:"description" db:"DESCRIPTION"`
-	ImageType   string    `json:"image_type" db:"IMAGE_TYPE"`
-	FileName    string    `json:"file_name" db:"FILE_NAME"`
-	FileSize    int64     `json:"file_size" db:"FILE_SIZE"`
-	MimeType    string    `json:"mime_type" db:"MIME_TYPE"`
-	CreatedAt   time.Time `json:"created_at" db:"CREATED_AT"`
-}
-
-// TherapyScheduleRequest represents the request structure for therapy schedule operations
+	ID            string       `form:"id" uri:"id" json:"id" db:"ID"`
+	VisitID       string       `json:"visit_id" db:"VISIT_ID"`
+	ImageURL      dialect.Text `json:"image_url" db:"IMAGE_URL"`
+	Description   dialect.Text `json:"description" db:"DESCRIPTION"`
+	ImageType     dialect.Text `json:"image_type" db:"IMAGE_TYPE"`
+	FileName      dialect.Text `json:"file_name" db:"FILE_NAME"`
+	FileSize      int64        `json:"file_size" db:"FILE_SIZE"`
+	MimeType      dialect.Text `json:"mime_type" db:"MIME_TYPE"`
+	StorageKey    dialect.Text `json:"-" db:"STORAGE_KEY"`
+	ThumbnailURL  string       `json:"thumbnail_url,omitempty"`
+	ThumbnailKey  dialect.Text `json:"-" db:"THUMBNAIL_KEY"`
+	PreviewURL    string       `json:"preview_url,omitempty"`
+	PreviewKey    dialect.Text `json:"-" db:"PREVIEW_KEY"`
+	Modality      dialect.Text `json:"modality,omitempty" db:"MODALITY"`
+	StudyUID      dialect.Text `json:"study_uid,omitempty" db:"STUDY_UID"`
+	ScanStatus    string       `json:"scan_status" db:"SCAN_STATUS"`
+	ScanSignature dialect.Text `json:"scan_signature,omitempty" db:"SCAN_SIGNATURE"`
+	UploadedBy    dialect.Text `json:"uploaded_by" db:"UPLOADED_BY"`
+	CreatedAt     time.Time    `json:"created_at" db:"CREATED_AT"`
+}
+
+// TherapyScheduleRequest represents the request structure for therapy schedule operations, StartDate
+// and EndDate are days in Calendar or else in the calendar of the X-Calendar header, EndDate must not
+// be before StartDate
 type TherapyScheduleRequest struct {
-	ID           string    `json:"id"`
-	PatientID    string    `json:"patient_id"`
-	DoctorID     string    `json:"doctor_id"`
-	TherapyType  string    `json:"therapy_type"`
-	Description  string    `json:"description"`
-	StartDate    time.Time `json:"start_date"`
-	EndDate      time.Time `json:"end_date"`
-	Frequency    string    `json:"frequency"`
-	Instructions string    `json:"instructions"`
-	IsActive     bool      `json:"is_active"`
-	Duration     int       `json:"duration"`
-	SessionCount int       `json:"session_count"`
+	ID           string `json:"id"`
+	PatientID    string `json:"patient_id" validate:"required"`
+	DoctorID     string `json:"doctor_id" validate:"required"`
+	TherapyType  string `json:"therapy_type" validate:"required"`
+	Description  string `json:"description"`
+	StartDate    string `json:"start_date" validate:"required"`
+	EndDate      string `json:"end_date"`
+	Calendar     string `json:"calendar" form:"calendar"`
+	Frequency    string `json:"frequency"`
+	Instructions string `json:"instructions"`
+	IsActive     bool   `json:"is_active"`
+	Duration     int    `json:"duration" validate:"omitempty,gte=5,lte=480"`
+	SessionCount int    `json:"session_count" validate:"omitempty,gte=1,lte=365"`
+	// RRule is an RFC 5545 recurrence rule such as FREQ=WEEKLY;BYDAY=SA,MO, Frequency is used when it is empty
+	RRule         string   `json:"rrule"`
+	ExDates       []string `json:"exdates"`
+	HolidayPolicy string   `json:"holiday_policy" validate:"omitempty,oneof=skip next previous"`
+}
+
+// TherapyScheduleFilter represents the filters of the therapy schedule list, each one is optional, and its page
+type TherapyScheduleFilter struct {
+	ID          string `form:"id" uri:"id" json:"id"`
+	PatientID   string `form:"patient_id" json:"patient_id"`
+	DoctorID    string `form:"doctor_id" json:"doctor_id"`
+	TherapyType string `form:"therapy_type" json:"therapy_type"`
+	IsActive    string `form:"is_active" json:"is_active" validate:"omitempty,oneof=true false"`
+	Status      string `form:"status" json:"status" validate:"omitempty,oneof=active completed expired cancelled"`
+	paging.Params
 }
 
 // TherapyScheduleResponse represents the response structure for therapy schedule operations
 type TherapyScheduleResponse struct {
-	Result libQuery.DmlResult `json:"result"`
+	Result   libQuery.DmlResult  `json:"result"`
+	Schedule *TherapyScheduleRow `json:"schedule,omitempty"`
 }
 
 // TherapyScheduleRow represents a single therapy schedule record
 type TherapyScheduleRow struct {
-	ID           string    `form:"id" uri:"id" json:"id" db:"ID"`
-	PatientID    string    `json:"patient_id" db:"PATIENT_ID"`
-	DoctorID     string    `json:"doctor_id" db:"DOCTOR_ID"`
-	TherapyType  string    `json:"therapy_type" db:"THERAPY_TYPE"`
-	Description  string    `json:"description" db:"DESCRIPTION"`
-	StartDate    time.Time `json:"start_date" db:"START_DATE"`
-	EndDate      time.Time `json:"end_date" db:"END_DATE"`
-	Frequency    string    `json:"frequency" db:"FREQUENCY"`
-	Instructions string    `json:"instructions" db:"INSTRUCTIONS"`
-	IsActive     bool      `json:"is_active" db:"IS_ACTIVE"`
-	Duration     int       `json:"duration" db:"DURATION"`
-	SessionCount int       `json:"session_count" db:"SESSION_COUNT"`
-	CreatedAt    time.Time `json:"created_at" db:"CREATED_AT"`
-	UpdatedAt    time.Time `json:"updated_at" db:"UPDATED_AT"`
+	ID            string       `form:"id" uri:"id" json:"id" db:"ID"`
+	PatientID     string       `json:"patient_id" db:"PATIENT_ID"`
+	DoctorID      string       `json:"doctor_id" db:"DOCTOR_ID"`
+	TherapyType   string       `json:"therapy_type" db:"THERAPY_TYPE"`
+	Description   dialect.Text `json:"description" db:"DESCRIPTION"`
+	StartDate     time.Time    `json:"start_date" db:"START_DATE"`
+	EndDate       time.Time    `json:"end_date" db:"END_DATE"`
+	Frequency     dialect.Text `json:"frequency" db:"FREQUENCY"`
+	Instructions  dialect.Text `json:"instructions" db:"INSTRUCTIONS"`
+	IsActive      bool         `json:"is_active" db:"IS_ACTIVE"`
+	Duration      int          `json:"duration" db:"DURATION"`
+	SessionCount  int          `json:"session_count" db:"SESSION_COUNT"`
+	RRule         dialect.Text `json:"rrule" db:"RRULE"`
+	ExDates       dialect.Text `json:"exdates" db:"EXDATES"`
+	HolidayPolicy string       `json:"holiday_policy" db:"HOLIDAY_POLICY"`
+	Status        string       `json:"status" db:"STATUS"`
+	EndedAt       time.Time    `json:"ended_at" db:"ENDED_AT"`
+	Attended      int          `json:"-" db:"ATTENDED"`
+	Missed        int          `json:"-" db:"MISSED"`
+	CreatedAt     time.Time    `json:"created_at" db:"CREATED_AT"`
+	UpdatedAt     time.Time    `json:"updated_at" db:"UPDATED_AT"`
+	PatientName   dialect.Text `json:"patient_name" db:"PATIENT_NAME"`
+	DoctorName    dialect.Text `json:"doctor_name" db:"DOCTOR_NAME"`
+	// Progress is computed from the attended and missed sessions
+	Progress therapy.Progress `json:"progress"`
 }
 
 // MedicationRequest represents the request structure for medication operations
 type MedicationRequest struct {
-	ID              string    `json:"id"`
-	VisitID         string    `json:"visit_id"`
-	MedicationName  string    `json:"medication_name"`
-	Dosage          string    `json:"dosage"`
-	Frequency       string    `json:"frequency"`
-	Duration        string    `json:"duration"`
-	Instructions    string    `json:"instructions"`
-	StartDate       time.Time `json:"start_date"`
-	EndDate         time.Time `json:"end_date"`
-	IsActive        bool      `json:"is_active"`
-	SideEffects     string    `json:"side_effects"`
-	Contraindications string  `json:"contraindications"`
+	ID                string    `json:"id"`
+	VisitID           string    `json:"visit_id" validate:"required"`
+	MedicationName    string    `json:"medication_name" validate:"required"`
+	Dosage            string    `json:"dosage" validate:"required"`
+	Frequency         string    `json:"frequency"`
+	Duration          string    `json:"duration"`
+	Instructions      string    `json:"instructions"`
+	StartDate         time.Time `json:"start_date"`
+	EndDate           time.Time `json:"end_date" validate:"omitempty,gtfield=StartDate"`
+	IsActive          bool      `json:"is_active"`
+	SideEffects       string    `json:"side_effects"`
+	Contraindications string    `json:"contraindications"`
+}
+
+// MedicationFilter represents the filters of the medication list, each one is optional
+type MedicationFilter struct {
+	ID       string `form:"id" uri:"id" json:"id"`
+	VisitID  string `form:"visit_id" json:"visit_id"`
+	IsActive string `form:"is_active" json:"is_active" validate:"omitempty,oneof=true false"`
 }
 
 // MedicationResponse represents the response structure for medication operations
 type MedicationResponse struct {
-	Result libQuery.DmlResult `json:"result"`
+	Result       libQuery.DmlResult `json:"result"`
+	Interactions []DrugInteraction  `json:"interactions,omitempty"`
 }
 
 // MedicationRow represents a single medication record
 type MedicationRow struct {
-	ID              string    `form:"id" uri:"id" json:"id" db:"ID"`
-	VisitID         string    `json:"visit_id" db:"VISIT_ID"`
-	MedicationName  string    `json:"medication_name" db:"MEDICATION_NAME"`
-	Dosage          string    `json:"dosage" db:"DOSAGE"`
-	Frequency       string    `json:"frequency" db:"FREQUENCY"`
-	Duration        string    `json:"duration" db:"DURATION"`
-	Instructions    string    `json:"instructions" db:"INSTRUCTIONS"`
-	StartDate       time.Time `json:"start_date" db:"START_DATE"`
-	EndDate         time.Time `json:"end_date" db:"END_DATE"`
-	IsActive        bool      `json:"is_active" db:"IS_ACTIVE"`
-	SideEffects     string    `json:"side_effects" db:"SIDE_EFFECTS"`
-	Contraindications string  `json:"contraindications" db:"CONTRAINDICATIONS"`
-	CreatedAt       time.Time `json:"created_at" db:"CREATED_AT"`
-	UpdatedAt       time.Time `json:"updated_at" db:"UPDATED_AT"`
-}
-
-// DashboardStatsRequest represents the request structure for dashboard statistics
+	ID                string    `form:"id" uri:"id" json:"id" db:"ID"`
+	VisitID           string    `json:"visit_id" db:"VISIT_ID"`
+	MedicationName    string    `json:"medication_name" db:"MEDICATION_NAME"`
+	Dosage            string    `json:"dosage" db:"DOSAGE"`
+	Frequency         string    `json:"frequency" db:"FREQUENCY"`
+	Duration          string    `json:"duration" db:"DURATION"`
+	Instructions      string    `json:"instructions" db:"INSTRUCTIONS"`
+	StartDate         time.Time `json:"start_date" db:"START_DATE"`
+	EndDate           time.Time `json:"end_date" db:"END_DATE"`
+	IsActive          bool      `json:"is_active" db:"IS_ACTIVE"`
+	SideEffects       string    `json:"side_effects" db:"SIDE_EFFECTS"`
+	Contraindications string    `json:"contraindications" db:"CONTRAINDICATIONS"`
+	CreatedAt         time.Time `json:"created_at" db:"CREATED_AT"`
+	UpdatedAt         time.Time `json:"updated_at" db:"UPDATED_AT"`
+}
+
+// DashboardStatsRequest represents the request structure for dashboard statistics, the dates are in
+// Calendar or else in the calendar of the X-Calendar header and default to the last month
 type DashboardStatsRequest struct {
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
-	DoctorID  string    `json:"doctor_id"`
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+	DoctorID  string `json:"doctor_id" form:"doctor_id"`
+	VisitType string `json:"visit_type" form:"visit_type"`
+	Status    string `json:"status" form:"status"`
+	Calendar  string `json:"calendar" form:"calendar"`
+}
+
+// DashboardFilters represents the filters statistics were computed with, the dates are in Calendar
+type DashboardFilters struct {
+	Calendar  string `json:"calendar"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	DoctorID  string `json:"doctor_id,omitempty"`
+	VisitType string `json:"visit_type,omitempty"`
+	Status    string `json:"status,omitempty"`
 }
 
 // DashboardStatsResponse represents the response structure for dashboard statistics
 type DashboardStatsResponse struct {
-	TotalPatients     int `json:"total_patients"`
-	TotalVisits       int `json:"total_visits"`
-	ActiveTherapies   int `json:"active_therapies"`
-	PendingFollowUps  int `json:"pending_follow_ups"`
-	MonthlyVisits     []MonthlyVisitStats `json:"monthly_visits"`
-	VisitTypes        []VisitTypeStats `json:"visit_types"`
-	TopDiagnoses      []DiagnosisStats `json:"top_diagnoses"`
+	TotalPatients    int                 `json:"total_patients"`
+	TotalVisits      int                 `json:"total_visits"`
+	ActiveTherapies  int                 `json:"active_therapies"`
+	PendingFollowUps int                 `json:"pending_follow_ups"`
+	MonthlyVisits    []MonthlyVisitStats `json:"monthly_visits"`
+	VisitTypes       []VisitTypeStats    `json:"visit_types"`
+	TopDiagnoses     []DiagnosisStats    `json:"top_diagnoses"`
+	Filters          DashboardFilters    `json:"filters"`
 }
 
-// MonthlyVisitStats represents monthly visit statistics
+// PatientStatsRequest represents the filters of the patient statistics
+type PatientStatsRequest struct {
+	Status   string `json:"status" form:"status"`
+	AgeGroup string `json:"age_group" form:"age_group" validate:"omitempty,oneof=0-18 19-35 36-50 50+"`
+}
+
+// PatientStatsRow represents a patient with their visit statistics
+type PatientStatsRow struct {
+	ID         string    `json:"id"`
+	FullName   string    `json:"full_name"`
+	Age        int       `json:"age"`
+	Gender     string    `json:"gender"`
+	LastVisit  time.Time `json:"last_visit"`
+	VisitCount int       `json:"visit_count"`
+	Status     string    `json:"status"`
+	BloodType  string    `json:"blood_type"`
+	Phone      string    `json:"phone"`
+	Email      string    `json:"email"`
+}
+
+// VisitStatsRow represents a visit in the visit statistics
+type VisitStatsRow struct {
+	ID          string    `json:"id"`
+	PatientName string    `json:"patient_name"`
+	DoctorName  string    `json:"doctor_name"`
+	VisitType   string    `json:"visit_type"`
+	VisitDate   time.Time `json:"visit_date"`
+	Status      string    `json:"status"`
+	Diagnosis   string    `json:"diagnosis"`
+	Duration    int       `json:"duration"`
+}
+
+// VisitStatsSummary represents the totals of the visit statistics
+type VisitStatsSummary struct {
+	TotalVisits      int    `json:"total_visits"`
+	CompletedVisits  int    `json:"completed_visits"`
+	InProgressVisits int    `json:"in_progress_visits"`
+	AverageDuration  int    `json:"average_duration"`
+	MostCommonType   string `json:"most_common_type"`
+}
+
+// VisitStatsResponse represents the response structure for visit statistics
+type VisitStatsResponse struct {
+	Visits  []VisitStatsRow   `json:"visits"`
+	Summary VisitStatsSummary `json:"summary"`
+	Filters DashboardFilters  `json:"filters"`
+}
+
+// MonthlyVisitStats represents monthly visit statistics, Period is the month as YYYY-MM in the requested calendar
 type MonthlyVisitStats struct {
-	Month string `json:"month"`
-	Count int    `json:"count"`
+	Month  string `json:"month"`
+	Period string `json:"period"`
+	Count  int    `json:"count"`
+}
+
+// DailyVisitCount represents the number of visits of a day in the clinic time zone
+type DailyVisitCount struct {
+	Day   time.Time `json:"day" db:"DAY"`
+	Count int       `json:"count" db:"COUNT"`
 }
 
 // VisitTypeStats represents visit type statistics
@@ -241,10 +389,12 @@ type VisitTypeStats struct {
 	Count int    `json:"count"`
 }
 
-// DiagnosisStats represents diagnosis statistics
+// DiagnosisStats represents diagnosis statistics aggregated by ICD-10 code
 type DiagnosisStats struct {
-	Diagnosis string `json:"diagnosis"`
-	Count     int    `json:"count"`
+	Code        string       `json:"code" db:"CODE"`
+	Diagnosis   string       `json:"diagnosis" db:"DIAGNOSIS"`
+	DiagnosisFa dialect.Text `json:"diagnosis_fa" db:"DIAGNOSIS_FA"`
+	Count       int          `json:"count" db:"COUNT"`
 }
 
 // Query constants
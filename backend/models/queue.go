@@ -0,0 +1,85 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// QueueRequest represents the request structure for registering a walk-in patient to the daily queue
+// of a doctor or a department, and for skipping or requeueing an entry. The rules apply to registering
+type QueueRequest struct {
+	EntryID        string `json:"entry_id"`
+	PatientID      string `json:"patient_id" validate:"required"`
+	DoctorID       string `json:"doctor_id" validate:"required_without=Department,excluded_with=Department"`
+	Department     string `json:"department"`
+	VisitType      string `json:"visit_type" validate:"omitempty,oneof=general dentistry specialist"`
+	ChiefComplaint string `json:"chief_complaint"`
+	Notes          string `json:"notes"`
+	Reason         string `json:"reason"`
+}
+
+// QueueListRequest represents the request structure for the queue of a day, all queues are listed
+// when neither a doctor nor a department is given
+type QueueListRequest struct {
+	Date       string `form:"date"`
+	DoctorID   string `form:"doctor_id"`
+	Department string `form:"department"`
+	Status     string `form:"status"`
+	// Calendar of Date, it wins over the X-Calendar header
+	Calendar string `form:"calendar"`
+}
+
+// CallNextRequest represents the request structure for calling the next walk-in, the doctor serves
+// their own queue and the queue of their department, whoever registered first is called first
+type CallNextRequest struct {
+	DoctorID string `json:"doctor_id" validate:"required"`
+	Reason   string `json:"reason"`
+}
+
+// QueueResponse represents the response structure for queue operations
+type QueueResponse struct {
+	Result  libQuery.DmlResult `json:"result"`
+	Entry   *QueueEntryRow     `json:"entry,omitempty"`
+	Entries []QueueEntryRow    `json:"entries,omitempty"`
+}
+
+// QueueEntryRow represents a single walk-in of the daily queue, Position and EstimatedWait are only
+// set for waiting entries, a called entry has the in-progress visit it was converted into
+type QueueEntryRow struct {
+	ID             string       `json:"id" db:"ID"`
+	QueueDate      time.Time    `json:"queue_date" db:"QUEUE_DATE"`
+	QueueKey       string       `json:"queue_key" db:"QUEUE_KEY"`
+	Number         int          `json:"number" db:"NUMBER"`
+	PatientID      string       `json:"patient_id" db:"PATIENT_ID"`
+	PatientNumber  string       `json:"patient_number" db:"PATIENT_NUMBER"`
+	PatientName    dialect.Text `json:"patient_name" db:"PATIENT_NAME"`
+	DoctorID       dialect.Text `json:"doctor_id" db:"DOCTOR_ID"`
+	DoctorName     dialect.Text `json:"doctor_name" db:"DOCTOR_NAME"`
+	Department     dialect.Text `json:"department" db:"DEPARTMENT"`
+	Status         string       `json:"status" db:"STATUS"`
+	VisitType      string       `json:"visit_type" db:"VISIT_TYPE"`
+	ChiefComplaint dialect.Text `json:"chief_complaint" db:"CHIEF_COMPLAINT"`
+	Notes          dialect.Text `json:"notes" db:"NOTES"`
+	Reason         dialect.Text `json:"reason" db:"REASON"`
+	RegisteredBy   string       `json:"registered_by" db:"REGISTERED_BY"`
+	RegisteredAt   time.Time    `json:"registered_at" db:"REGISTERED_AT"`
+	CalledBy       dialect.Text `json:"called_by" db:"CALLED_BY"`
+	CalledAt       time.Time    `json:"called_at" db:"CALLED_AT"`
+	VisitID        dialect.Text `json:"visit_id" db:"VISIT_ID"`
+	Position       int          `json:"position,omitempty"`
+	EstimatedWait  int          `json:"estimated_wait_minutes,omitempty"`
+}
+
+// QueueCallRow represents the time a patient of a queue was called
+type QueueCallRow struct {
+	CalledAt time.Time `json:"called_at" db:"CALLED_AT"`
+}
+
+// QueueDoctorRow represents a doctor serving the queue of their specialization
+type QueueDoctorRow struct {
+	ID             string       `json:"id" db:"ID"`
+	Name           dialect.Text `json:"name" db:"NAME"`
+	Specialization string       `json:"specialization" db:"SPECIALIZATION"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"healthcare/utils/therapy"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// TherapySessionRequest represents the request structure for recording a session of a therapy schedule,
+// recording the same day again replaces its outcome
+type TherapySessionRequest struct {
+	SessionDate string `json:"session_date"`
+	Status      string `json:"status" validate:"required,oneof=attended missed"`
+	Notes       string `json:"notes"`
+	// Calendar of SessionDate, it wins over the X-Calendar header
+	Calendar string `json:"calendar" form:"calendar"`
+}
+
+// TherapySessionResponse represents the response structure for therapy session operations
+type TherapySessionResponse struct {
+	Result   libQuery.DmlResult  `json:"result"`
+	Schedule *TherapyScheduleRow `json:"schedule,omitempty"`
+	Sessions []TherapySessionRow `json:"sessions,omitempty"`
+}
+
+// TherapySessionRow represents a single attended or missed session of a therapy schedule
+type TherapySessionRow struct {
+	ID          string       `json:"id" db:"ID"`
+	ScheduleID  string       `json:"schedule_id" db:"SCHEDULE_ID"`
+	SessionDate time.Time    `json:"session_date" db:"SESSION_DATE"`
+	Status      string       `json:"status" db:"STATUS"`
+	Notes       dialect.Text `json:"notes" db:"NOTES"`
+	RecordedBy  dialect.Text `json:"recorded_by" db:"RECORDED_BY"`
+	CreatedAt   time.Time    `json:"created_at" db:"CREATED_AT"`
+}
+
+// TherapyCountRow represents the number of therapy schedules matching a filter
+type TherapyCountRow struct {
+	Count int `json:"count" db:"COUNT"`
+}
+
+// TherapyOccurrencesRequest represents the date range to expand the sessions of a therapy schedule in,
+// the dates are in Calendar or else in the calendar of the X-Calendar header
+type TherapyOccurrencesRequest struct {
+	From     string `json:"from" form:"from"`
+	To       string `json:"to" form:"to"`
+	Calendar string `json:"calendar" form:"calendar"`
+}
+
+// TherapyOccurrencesResponse represents the expanded sessions of a therapy schedule in a date range
+// Dates are the days of the sessions in the requested calendar, they are left out for the gregorian calendar
+type TherapyOccurrencesResponse struct {
+	Result   libQuery.DmlResult `json:"result"`
+	Calendar string             `json:"calendar"`
+	Sessions []therapy.Session  `json:"sessions"`
+	Dates    []string           `json:"dates,omitempty"`
+}
+
+// ClinicHolidayRequest represents the request structure for adding a clinic holiday
+type ClinicHolidayRequest struct {
+	Day  string `json:"day" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	// Calendar of Day, it wins over the X-Calendar header
+	Calendar string `json:"calendar" form:"calendar"`
+}
+
+// ClinicHolidayFilter represents the filters of the clinic holiday list
+type ClinicHolidayFilter struct {
+	Year int `json:"year" form:"year" validate:"omitempty,gte=1"`
+}
+
+// ClinicHolidayResponse represents the response structure for clinic holiday operations, Day is
+// the gregorian day stored
+type ClinicHolidayResponse struct {
+	Result libQuery.DmlResult `json:"result"`
+	Day    string             `json:"day"`
+}
+
+// ClinicHolidayRow represents a day the clinic is closed
+type ClinicHolidayRow struct {
+	Day       time.Time `json:"day" db:"DAY"`
+	Name      string    `json:"name" db:"NAME"`
+	CreatedAt time.Time `json:"created_at" db:"CREATED_AT"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// VisitTransitionRequest represents the request structure for moving a visit to another status
+type VisitTransitionRequest struct {
+	VisitID string `json:"visit_id"`
+	Reason  string `json:"reason"`
+}
+
+// VisitTransitionResponse represents the response structure for visit status transitions
+type VisitTransitionResponse struct {
+	Result      libQuery.DmlResult   `json:"result"`
+	VisitID     string               `json:"visit_id"`
+	Status      string               `json:"status"`
+	IsLocked    bool                 `json:"is_locked"`
+	NextStatus  []string             `json:"next_status"`
+	Transitions []VisitTransitionRow `json:"transitions,omitempty"`
+}
+
+// VisitTransitionRow represents a single recorded status transition of a visit
+type VisitTransitionRow struct {
+	ID             string       `json:"id" db:"ID"`
+	VisitID        string       `json:"visit_id" db:"VISIT_ID"`
+	FromStatus     dialect.Text `json:"from_status" db:"FROM_STATUS"`
+	ToStatus       string       `json:"to_status" db:"TO_STATUS"`
+	ActorID        string       `json:"actor_id" db:"ACTOR_ID"`
+	ActorName      dialect.Text `json:"actor_name" db:"ACTOR_NAME"`
+	Reason         dialect.Text `json:"reason" db:"REASON"`
+	TransitionedAt time.Time    `json:"transitioned_at" db:"TRANSITIONED_AT"`
+}
+
+// VisitStateRow represents the workflow state of a visit
+type VisitStateRow struct {
+	ID        string `json:"id" db:"ID"`
+	PatientID string `json:"patient_id" db:"PATIENT_ID"`
+	DoctorID  string `json:"doctor_id" db:"DOCTOR_ID"`
+	Status    string `json:"status" db:"STATUS"`
+	IsLocked  bool   `json:"is_locked" db:"IS_LOCKED"`
+}
+
+// VisitSignRequest represents the request structure for signing a visit note
+type VisitSignRequest struct {
+	VisitID string `json:"visit_id"`
+}
+
+// VisitSignatureResponse represents the signature of a visit note,
+// Verified is false when the stored content no longer matches the signed hash
+type VisitSignatureResponse struct {
+	Result       libQuery.DmlResult `json:"result"`
+	VisitID      string             `json:"visit_id"`
+	Signed       bool               `json:"signed"`
+	SignedBy     string             `json:"signed_by,omitempty"`
+	SignedByName string             `json:"signed_by_name,omitempty"`
+	SignedAt     *time.Time         `json:"signed_at,omitempty"`
+	ContentHash  string             `json:"content_hash,omitempty"`
+	Verified     bool               `json:"verified"`
+}
+
+// VisitContentRow represents the clinical content of a visit note and its signature
+type VisitContentRow struct {
+	ID                    string       `json:"id" db:"ID"`
+	Status                string       `json:"status" db:"STATUS"`
+	ChiefComplaint        dialect.Text `json:"chief_complaint" db:"CHIEF_COMPLAINT"`
+	Symptoms              dialect.Text `json:"symptoms" db:"SYMPTOMS"`
+	Diagnosis             dialect.Text `json:"diagnosis" db:"DIAGNOSIS"`
+	TreatmentPlan         dialect.Text `json:"treatment_plan" db:"TREATMENT_PLAN"`
+	MedicationsPrescribed dialect.Text `json:"medications_prescribed" db:"MEDICATIONS_PRESCRIBED"`
+	Notes                 dialect.Text `json:"notes" db:"NOTES"`
+	FollowUpDate          time.Time    `json:"follow_up_date" db:"FOLLOW_UP_DATE"`
+	UpdatedAt             time.Time    `json:"updated_at" db:"UPDATED_AT"`
+	IsSigned              bool         `json:"is_signed" db:"IS_SIGNED"`
+	SignedBy              dialect.Text `json:"signed_by" db:"SIGNED_BY"`
+	SignedByName          dialect.Text `json:"signed_by_name" db:"SIGNED_BY_NAME"`
+	SignedAt              time.Time    `json:"signed_at" db:"SIGNED_AT"`
+	ContentHash           dialect.Text `json:"content_hash" db:"CONTENT_HASH"`
+}
+
+// VisitAddendumRequest represents the request structure for appending an addendum to a signed or locked visit
+type VisitAddendumRequest struct {
+	VisitID string `json:"visit_id"`
+	Text    string `json:"text" validate:"required"`
+}
+
+// VisitAddendumResponse represents the response structure for visit addenda
+type VisitAddendumResponse struct {
+	Result  libQuery.DmlResult `json:"result"`
+	Addenda []VisitAddendumRow `json:"addenda,omitempty"`
+}
+
+// VisitAddendumRow represents a single addendum of a visit note
+type VisitAddendumRow struct {
+	ID          string       `json:"id" db:"ID"`
+	VisitID     string       `json:"visit_id" db:"VISIT_ID"`
+	AuthorID    string       `json:"author_id" db:"AUTHOR_ID"`
+	AuthorName  dialect.Text `json:"author_name" db:"AUTHOR_NAME"`
+	Text        string       `json:"text" db:"TEXT"`
+	ContentHash dialect.Text `json:"content_hash" db:"CONTENT_HASH"`
+	CreatedAt   time.Time    `json:"created_at" db:"CREATED_AT"`
+}
+
+// ImagingStudyRow represents a DICOM study indexed for a patient across visits
+type ImagingStudyRow struct {
+	ID            string          `json:"id" db:"ID"`
+	PatientID     string          `json:"patient_id" db:"PATIENT_ID"`
+	StudyUID      dialect.Text    `json:"study_uid" db:"STUDY_UID"`
+	Modality      dialect.Text    `json:"modality" db:"MODALITY"`
+	StudyDate     time.Time       `json:"study_date" db:"STUDY_DATE"`
+	Description   dialect.Text    `json:"description" db:"DESCRIPTION"`
+	InstanceCount int             `json:"instance_count" db:"INSTANCE_COUNT"`
+	LastVisitID   dialect.Text    `json:"last_visit_id" db:"LAST_VISIT_ID"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"UPDATED_AT"`
+	Images        []VisitImageRow `json:"images"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"healthcare/utils/dialect"
+	"time"
+
+	"github.com/hmmftg/requestCore/libQuery"
+)
+
+// VisitVitalsRequest represents the request structure for recording vital signs of a visit,
+// measurements that were not taken are left out. The bounds reject physically impossible values,
+// which are most likely typing mistakes
+type VisitVitalsRequest struct {
+	VisitID         string    `json:"visit_id"`
+	RecordedAt      time.Time `json:"recorded_at"`
+	BPSystolic      *float64  `json:"bp_systolic" validate:"required_without_all=BPDiastolic Pulse Temperature SpO2 RespiratoryRate Weight Height,omitempty,gte=40,lte=300"`
+	BPDiastolic     *float64  `json:"bp_diastolic" validate:"omitempty,gte=20,lte=200"`
+	Pulse           *float64  `json:"pulse" validate:"omitempty,gte=20,lte=300"`
+	Temperature     *float64  `json:"temperature" validate:"omitempty,gte=25,lte=45"`
+	SpO2            *float64  `json:"spo2" validate:"omitempty,gte=30,lte=100"`
+	RespiratoryRate *float64  `json:"respiratory_rate" validate:"omitempty,gte=2,lte=80"`
+	Weight          *float64  `json:"weight" validate:"omitempty,gte=0.3,lte=400"`
+	Height          *float64  `json:"height" validate:"omitempty,gte=20,lte=260"`
+}
+
+// VisitVitalsResponse represents the response structure for recording vital signs
+type VisitVitalsResponse struct {
+	Result        libQuery.DmlResult `json:"result"`
+	BMI           float64            `json:"bmi,omitempty"`
+	AgeGroup      string             `json:"age_group"`
+	AbnormalFlags map[string]string  `json:"abnormal_flags"`
+}
+
+// VisitVitalsRow represents a single vital signs record, measurements that were not taken are null
+type VisitVitalsRow struct {
+	ID              string       `json:"id" db:"ID"`
+	VisitID         string       `json:"visit_id" db:"VISIT_ID"`
+	PatientID       string       `json:"patient_id" db:"PATIENT_ID"`
+	RecordedAt      time.Time    `json:"recorded_at" db:"RECORDED_AT"`
+	BPSystolic      *float64     `json:"bp_systolic" db:"BP_SYSTOLIC"`
+	BPDiastolic     *float64     `json:"bp_diastolic" db:"BP_DIASTOLIC"`
+	Pulse           *float64     `json:"pulse" db:"PULSE"`
+	Temperature     *float64     `json:"temperature" db:"TEMPERATURE"`
+	SpO2            *float64     `json:"spo2" db:"SPO2"`
+	RespiratoryRate *float64     `json:"respiratory_rate" db:"RESPIRATORY_RATE"`
+	Weight          *float64     `json:"weight" db:"WEIGHT"`
+	Height          *float64     `json:"height" db:"HEIGHT"`
+	BMI             *float64     `json:"bmi" db:"BMI"`
+	AbnormalFlags   dialect.Text `json:"abnormal_flags" db:"ABNORMAL_FLAGS"`
+	RecordedBy      dialect.Text `json:"recorded_by" db:"RECORDED_BY"`
+	CreatedAt       time.Time    `json:"created_at" db:"CREATED_AT"`
+}
+
+// VisitPatientRow represents the patient a visit belongs to, an unknown date of birth is 0001-01-01
+type VisitPatientRow struct {
+	VisitID     string    `json:"visit_id" db:"VISIT_ID"`
+	PatientID   string    `json:"patient_id" db:"PATIENT_ID"`
+	DateOfBirth time.Time `json:"date_of_birth" db:"DATE_OF_BIRTH"`
+}
+
+// VitalsSeriesRequest represents the request structure for the vital signs time-series, the days are
+// in Calendar or else in the calendar of the X-Calendar header
+type VitalsSeriesRequest struct {
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Calendar string `form:"calendar"`
+}
+
+// VitalsSeriesResponse represents vital signs of a patient or visit grouped per measurement for charting
+type VitalsSeriesResponse struct {
+	PatientID    string           `json:"patient_id,omitempty"`
+	VisitID      string           `json:"visit_id,omitempty"`
+	Measurements []VisitVitalsRow `json:"measurements"`
+	Series       []VitalsSeries   `json:"series"`
+}
+
+// VitalsSeries represents the values of a single measurement over time
+type VitalsSeries struct {
+	Name   string        `json:"name"`
+	Unit   string        `json:"unit"`
+	Points []VitalsPoint `json:"points"`
+}
+
+// VitalsPoint represents a single value of a measurement
+type VitalsPoint struct {
+	VisitID    string    `json:"visit_id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Value      float64   `json:"value"`
+	Flag       string    `json:"flag,omitempty"`
+}
@@ -0,0 +1,134 @@
+// Package dialect picks the SQL of the database the healthcare api runs on. Every statement is kept
+// in a PostgreSQL and an Oracle variant and the dialect is chosen once at startup from
+// db.healthcare.dbType, before any handler is registered.
+//
+// Both variants take the same arguments in the same order with :1, :2 binds. Oracle binds by
+// position, so a variant that refers to an argument twice or out of order selects its binds once from
+// dual in a WITH clause or the USING of a MERGE and refers to them there. Oracle stores an empty
+// string as NULL, its variants test IS NULL where PostgreSQL compares with an empty string. Text
+// that PostgreSQL coalesces to an empty string is still NULL in Oracle, rows read such columns into
+// Text. Flags computed by a statement are 1 or 0 in Oracle, which scan into a bool
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// Dialect is the SQL flavour of a database
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	Oracle   Dialect = "oracle"
+)
+
+var current atomic.Value
+
+// Parse returns the dialect of a dbType of the params, e.g. postgres or oracle
+func Parse(dbType string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(dbType)) {
+	case "postgres", "postgresql", "pg":
+		return Postgres, nil
+	case "oracle", "ora":
+		return Oracle, nil
+	}
+	return "", fmt.Errorf("unknown database type %q, expected postgres or oracle", dbType)
+}
+
+// FromDSN returns the dialect of a connection string, oracle:// urls are Oracle and everything else
+// is left to lib/pq
+func FromDSN(dsn string) Dialect {
+	if u, err := url.Parse(dsn); err == nil && strings.EqualFold(u.Scheme, "oracle") {
+		return Oracle
+	}
+	return Postgres
+}
+
+// Driver is the database/sql driver name of the dialect
+func (d Dialect) Driver() string {
+	if d == Oracle {
+		return "oracle"
+	}
+	return "postgres"
+}
+
+// Use makes d the dialect of the statements of the process
+func Use(d Dialect) {
+	current.Store(d)
+}
+
+// Current returns the dialect set by Use, PostgreSQL until it is called
+func Current() Dialect {
+	if d, ok := current.Load().(Dialect); ok {
+		return d
+	}
+	return Postgres
+}
+
+// Query is a statement in both dialects
+type Query struct {
+	Postgres string
+	Oracle   string
+}
+
+// In returns the variant of the statement for d
+func (q Query) In(d Dialect) string {
+	if d == Oracle {
+		return q.Oracle
+	}
+	return q.Postgres
+}
+
+// SQL returns the variant of the statement for the current dialect
+func (q Query) SQL() string {
+	return q.In(Current())
+}
+
+// Append adds a clause written alike in both dialects to the end of both variants
+func (q Query) Append(tail string) Query {
+	return Query{Postgres: q.Postgres + tail, Oracle: q.Oracle + tail}
+}
+
+// Unchanged reports whether the statement changed no rows. Oracle variants written as a PL/SQL block
+// have no row count, they raise NothingChanged instead and never report unchanged here
+func (q Query) Unchanged(result sql.Result) bool {
+	if Current() == Oracle && block(q.Oracle) {
+		return false
+	}
+	rows, err := result.RowsAffected()
+	return err == nil && rows == 0
+}
+
+// block reports whether a statement is a PL/SQL block, leading comments are skipped
+func block(query string) bool {
+	query = strings.TrimSpace(query)
+	for strings.HasPrefix(query, "--") {
+		_, query, _ = strings.Cut(query, "\n")
+		query = strings.TrimSpace(query)
+	}
+	word, _, _ := strings.Cut(query, " ")
+	word, _, _ = strings.Cut(strings.ToUpper(word), "\n")
+	return word == "DECLARE" || word == "BEGIN"
+}
+
+// Text is a string read from a column that may be NULL, which it scans as an empty string
+type Text string
+
+// Scan implements sql.Scanner
+func (t *Text) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*t = ""
+	case string:
+		*t = Text(value)
+	case []byte:
+		*t = Text(value)
+	default:
+		*t = Text(fmt.Sprint(value))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package dialect
+
+import "strings"
+
+// Code is the code of a database error in both dialects, an empty code never matches
+type Code struct {
+	Postgres string
+	Oracle   string
+}
+
+var (
+	// UniqueViolation is a duplicate key of a unique constraint or index
+	UniqueViolation = Code{Postgres: "23505", Oracle: "ORA-00001"}
+	// ForeignKeyViolation is a reference to a missing row
+	ForeignKeyViolation = Code{Postgres: "23503", Oracle: "ORA-02291"}
+	// ExclusionViolation is a conflict with an exclusion constraint. Oracle has none, its variants
+	// enforce them with a unique index, a trigger or a check in the statement that name the constraint
+	ExclusionViolation = Code{Postgres: "23P01"}
+	// NothingChanged is raised by Oracle variants that run as a PL/SQL block when the row they guard
+	// on has changed, the PostgreSQL variants report 0 rows affected instead
+	NothingChanged = Code{Oracle: "ORA-20000"}
+)
+
+// In returns the code in d
+func (c Code) In(d Dialect) string {
+	if d == Oracle {
+		return c.Oracle
+	}
+	return c.Postgres
+}
+
+// Is reports whether err carries code in the current dialect or names constraint. Oracle reports
+// constraint names in upper case, so they are compared ignoring case
+func Is(err error, code Code, constraint string) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	if c := code.In(Current()); len(c) > 0 && strings.Contains(msg, c) {
+		return true
+	}
+	return len(constraint) > 0 && strings.Contains(strings.ToLower(msg), strings.ToLower(constraint))
+}
@@ -0,0 +1,55 @@
+package dialect
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FromParams returns the dialect of the dbType of database name under the db section of the
+// application params, e.g. db.healthcare.dbType. The section is looked up by its yaml keys, so any
+// params struct of requestCore works. PostgreSQL is returned when the params have no dbType
+func FromParams(params any, name string) (Dialect, error) {
+	db := field(reflect.ValueOf(params), "db")
+	if !db.IsValid() {
+		return Postgres, nil
+	}
+	dbType := field(entry(db, name), "dbType")
+	if !dbType.IsValid() || dbType.Kind() != reflect.String || len(dbType.String()) == 0 {
+		return Postgres, nil
+	}
+	return Parse(dbType.String())
+}
+
+// field returns the field of struct v with the yaml key, following pointers and interfaces
+func field(v reflect.Value, key string) reflect.Value {
+	v = indirect(v)
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == key || (len(tag) == 0 && strings.EqualFold(t.Field(i).Name, key)) {
+			return indirect(v.Field(i))
+		}
+	}
+	return reflect.Value{}
+}
+
+// entry returns the value of map v under key
+func entry(v reflect.Value, key string) reflect.Value {
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}
+	}
+	return indirect(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,403 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transfer syntaxes that can be parsed, big endian and deflated datasets are rejected
+const (
+	ImplicitVRLittleEndian = "1.2.840.10008.1.2"
+	ExplicitVRLittleEndian = "1.2.840.10008.1.2.1"
+	JPEGBaseline           = "1.2.840.10008.1.2.4.50"
+	JPEGExtended           = "1.2.840.10008.1.2.4.51"
+	deflatedLittleEndian   = "1.2.840.10008.1.2.1.99"
+	explicitVRBigEndian    = "1.2.840.10008.1.2.2"
+)
+
+// MimeType of DICOM Part 10 files
+const MimeType = "application/dicom"
+
+// Tag is a (group, element) pair
+type Tag struct {
+	Group   uint16
+	Element uint16
+}
+
+func (t Tag) String() string {
+	return fmt.Sprintf("(%04X,%04X)", t.Group, t.Element)
+}
+
+// Tags read from the dataset
+var (
+	tagTransferSyntax       = Tag{0x0002, 0x0010}
+	tagSOPInstanceUID       = Tag{0x0008, 0x0018}
+	tagStudyDate            = Tag{0x0008, 0x0020}
+	tagModality             = Tag{0x0008, 0x0060}
+	tagStudyDescription     = Tag{0x0008, 0x1030}
+	tagPatientName          = Tag{0x0010, 0x0010}
+	tagPatientID            = Tag{0x0010, 0x0020}
+	tagStudyInstanceUID     = Tag{0x0020, 0x000D}
+	tagSeriesInstanceUID    = Tag{0x0020, 0x000E}
+	tagSamplesPerPixel      = Tag{0x0028, 0x0002}
+	tagPhotometric          = Tag{0x0028, 0x0004}
+	tagNumberOfFrames       = Tag{0x0028, 0x0008}
+	tagRows                 = Tag{0x0028, 0x0010}
+	tagColumns              = Tag{0x0028, 0x0011}
+	tagBitsAllocated        = Tag{0x0028, 0x0100}
+	tagBitsStored           = Tag{0x0028, 0x0101}
+	tagPixelRepresentation  = Tag{0x0028, 0x0103}
+	tagPlanarConfiguration  = Tag{0x0028, 0x0006}
+	tagWindowCenter         = Tag{0x0028, 0x1050}
+	tagWindowWidth          = Tag{0x0028, 0x1051}
+	tagRescaleIntercept     = Tag{0x0028, 0x1052}
+	tagRescaleSlope         = Tag{0x0028, 0x1053}
+	tagPixelData            = Tag{0x7FE0, 0x0010}
+	tagItem                 = Tag{0xFFFE, 0xE000}
+	tagItemDelimitation     = Tag{0xFFFE, 0xE00D}
+	tagSequenceDelimitation = Tag{0xFFFE, 0xE0DD}
+)
+
+// implicitVR gives the VR of the tags that are read when the dataset does not carry VRs
+var implicitVR = map[Tag]string{
+	tagSOPInstanceUID: "UI", tagStudyDate: "DA", tagModality: "CS", tagStudyDescription: "LO",
+	tagPatientName: "PN", tagPatientID: "LO", tagStudyInstanceUID: "UI", tagSeriesInstanceUID: "UI",
+	tagSamplesPerPixel: "US", tagPhotometric: "CS", tagNumberOfFrames: "IS", tagRows: "US",
+	tagColumns: "US", tagBitsAllocated: "US", tagBitsStored: "US", tagPixelRepresentation: "US",
+	tagPlanarConfiguration: "US", tagWindowCenter: "DS", tagWindowWidth: "DS",
+	tagRescaleIntercept: "DS", tagRescaleSlope: "DS", tagPixelData: "OW",
+}
+
+// VRs whose explicit length is 4 bytes after 2 reserved bytes
+var longVR = map[string]bool{
+	"OB": true, "OD": true, "OF": true, "OL": true, "OV": true, "OW": true,
+	"SQ": true, "SV": true, "UC": true, "UN": true, "UR": true, "UT": true, "UV": true,
+}
+
+const undefinedLength = 0xFFFFFFFF
+
+// File holds the attributes of a DICOM file that are needed to index and preview it
+type File struct {
+	TransferSyntax    string
+	PatientName       string
+	PatientID         string
+	Modality          string
+	StudyDate         time.Time
+	StudyDescription  string
+	StudyInstanceUID  string
+	SeriesInstanceUID string
+	SOPInstanceUID    string
+
+	Rows                int
+	Columns             int
+	SamplesPerPixel     int
+	BitsAllocated       int
+	BitsStored          int
+	PixelRepresentation int
+	PlanarConfiguration int
+	Photometric         string
+	NumberOfFrames      int
+	WindowCenter        float64
+	WindowWidth         float64
+	RescaleIntercept    float64
+	RescaleSlope        float64
+
+	// PixelData is the raw native pixel data, or the fragments of encapsulated pixel data
+	PixelData    []byte
+	Fragments    [][]byte
+	Encapsulated bool
+}
+
+// IsDICOM reports whether data starts with the Part 10 preamble and prefix
+func IsDICOM(data []byte) bool {
+	return len(data) >= 132 && string(data[128:132]) == "DICM"
+}
+
+// Parse reads a DICOM Part 10 file
+func Parse(data []byte) (*File, error) {
+	if !IsDICOM(data) {
+		return nil, errors.New("not a DICOM part 10 file")
+	}
+	f := &File{SamplesPerPixel: 1, NumberOfFrames: 1, RescaleSlope: 1}
+	// the file meta information is always explicit VR little endian
+	meta := &reader{data: data, pos: 132, explicit: true}
+	for meta.pos+4 <= len(data) && binary.LittleEndian.Uint16(data[meta.pos:]) == 0x0002 {
+		tag, vr, value, err := meta.element()
+		if err != nil {
+			return nil, err
+		}
+		if tag == tagTransferSyntax {
+			f.TransferSyntax = text(vr, value)
+		}
+	}
+	switch f.TransferSyntax {
+	case deflatedLittleEndian, explicitVRBigEndian:
+		return nil, fmt.Errorf("unsupported transfer syntax %s", f.TransferSyntax)
+	}
+	body := &reader{data: data, pos: meta.pos, explicit: f.TransferSyntax != ImplicitVRLittleEndian}
+	if err := body.dataset(f); err != nil {
+		return nil, err
+	}
+	if f.StudyInstanceUID == "" {
+		return nil, errors.New("study instance uid is missing")
+	}
+	return f, nil
+}
+
+type reader struct {
+	data     []byte
+	pos      int
+	explicit bool
+}
+
+func (r *reader) u16() uint16 {
+	v := binary.LittleEndian.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v
+}
+
+func (r *reader) u32() uint32 {
+	v := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v
+}
+
+func (r *reader) need(n int) error {
+	if n < 0 || r.pos+n > len(r.data) {
+		return errors.New("truncated DICOM file")
+	}
+	return nil
+}
+
+// header reads a tag with its VR and value length
+func (r *reader) header() (Tag, string, uint32, error) {
+	if err := r.need(8); err != nil {
+		return Tag{}, "", 0, err
+	}
+	tag := Tag{r.u16(), r.u16()}
+	if tag.Group == 0xFFFE {
+		// items and delimiters never carry a VR
+		return tag, "", r.u32(), nil
+	}
+	if !r.explicit {
+		vr, ok := implicitVR[tag]
+		if !ok {
+			vr = "UN"
+		}
+		return tag, vr, r.u32(), nil
+	}
+	vr := string(r.data[r.pos : r.pos+2])
+	r.pos += 2
+	if longVR[vr] {
+		if err := r.need(6); err != nil {
+			return Tag{}, "", 0, err
+		}
+		r.pos += 2
+		return tag, vr, r.u32(), nil
+	}
+	return tag, vr, uint32(r.u16()), nil
+}
+
+// element reads a complete element with a defined length
+func (r *reader) element() (Tag, string, []byte, error) {
+	tag, vr, length, err := r.header()
+	if err != nil {
+		return tag, vr, nil, err
+	}
+	if length == undefinedLength {
+		return tag, vr, nil, fmt.Errorf("undefined length in file meta element %s", tag)
+	}
+	if err = r.need(int(length)); err != nil {
+		return tag, vr, nil, err
+	}
+	value := r.data[r.pos : r.pos+int(length)]
+	r.pos += int(length)
+	return tag, vr, value, nil
+}
+
+// dataset reads elements into f until the end of data or the delimiter of an item of undefined length,
+// nested sequences are skipped and f is nil while reading their items
+func (r *reader) dataset(f *File) error {
+	for r.pos < len(r.data) {
+		tag, vr, length, err := r.header()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == tagItemDelimitation:
+			return nil
+		case tag == tagPixelData && length == undefinedLength:
+			if f != nil {
+				f.Encapsulated = true
+			}
+			fragments, err := r.fragments()
+			if err != nil {
+				return err
+			}
+			if f != nil {
+				f.Fragments = fragments
+			}
+		case length == undefinedLength:
+			// a sequence, or an element of unknown VR holding an implicit VR encoded sequence
+			explicit := r.explicit
+			r.explicit = explicit && vr != "UN"
+			err = r.skipSequence()
+			r.explicit = explicit
+			if err != nil {
+				return err
+			}
+		default:
+			if err = r.need(int(length)); err != nil {
+				return err
+			}
+			value := r.data[r.pos : r.pos+int(length)]
+			r.pos += int(length)
+			if f != nil && vr != "SQ" {
+				f.set(tag, vr, value)
+			}
+		}
+	}
+	return nil
+}
+
+// skipSequence skips the items of a sequence of undefined length
+func (r *reader) skipSequence() error {
+	for {
+		tag, _, length, err := r.header()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == tagSequenceDelimitation:
+			return nil
+		case tag != tagItem:
+			return fmt.Errorf("unexpected tag %s in sequence", tag)
+		case length == undefinedLength:
+			if err = r.dataset(nil); err != nil {
+				return err
+			}
+		default:
+			if err = r.need(int(length)); err != nil {
+				return err
+			}
+			r.pos += int(length)
+		}
+	}
+}
+
+// fragments reads encapsulated pixel data, the offset table item is dropped
+func (r *reader) fragments() ([][]byte, error) {
+	var fragments [][]byte
+	for first := true; ; first = false {
+		tag, _, length, err := r.header()
+		if err != nil {
+			return nil, err
+		}
+		if tag == tagSequenceDelimitation {
+			return fragments, nil
+		}
+		if tag != tagItem || length == undefinedLength {
+			return nil, fmt.Errorf("unexpected tag %s in pixel data", tag)
+		}
+		if err = r.need(int(length)); err != nil {
+			return nil, err
+		}
+		if !first {
+			fragments = append(fragments, r.data[r.pos:r.pos+int(length)])
+		}
+		r.pos += int(length)
+	}
+}
+
+func text(vr string, value []byte) string {
+	s := string(bytes.TrimRight(value, "\x00 "))
+	if vr != "UI" {
+		s = strings.TrimSpace(s)
+	}
+	return s
+}
+
+func (f *File) set(tag Tag, vr string, value []byte) {
+	number := func() int {
+		if vr == "US" && len(value) >= 2 {
+			return int(binary.LittleEndian.Uint16(value))
+		}
+		n, _ := strconv.Atoi(text(vr, value))
+		return n
+	}
+	decimal := func() float64 {
+		// multi-valued strings keep their first value
+		first, _, _ := strings.Cut(text(vr, value), "\\")
+		d, _ := strconv.ParseFloat(strings.TrimSpace(first), 64)
+		return d
+	}
+	switch tag {
+	case tagPatientName:
+		f.PatientName = text(vr, value)
+	case tagPatientID:
+		f.PatientID = text(vr, value)
+	case tagModality:
+		f.Modality = text(vr, value)
+	case tagStudyDate:
+		f.StudyDate, _ = time.Parse("20060102", text(vr, value))
+	case tagStudyDescription:
+		f.StudyDescription = text(vr, value)
+	case tagStudyInstanceUID:
+		f.StudyInstanceUID = text(vr, value)
+	case tagSeriesInstanceUID:
+		f.SeriesInstanceUID = text(vr, value)
+	case tagSOPInstanceUID:
+		f.SOPInstanceUID = text(vr, value)
+	case tagRows:
+		f.Rows = number()
+	case tagColumns:
+		f.Columns = number()
+	case tagSamplesPerPixel:
+		f.SamplesPerPixel = number()
+	case tagBitsAllocated:
+		f.BitsAllocated = number()
+	case tagBitsStored:
+		f.BitsStored = number()
+	case tagPixelRepresentation:
+		f.PixelRepresentation = number()
+	case tagPlanarConfiguration:
+		f.PlanarConfiguration = number()
+	case tagPhotometric:
+		f.Photometric = text(vr, value)
+	case tagNumberOfFrames:
+		if n := number(); n > 0 {
+			f.NumberOfFrames = n
+		}
+	case tagWindowCenter:
+		f.WindowCenter = decimal()
+	case tagWindowWidth:
+		f.WindowWidth = decimal()
+	case tagRescaleIntercept:
+		f.RescaleIntercept = decimal()
+	case tagRescaleSlope:
+		if slope := decimal(); slope != 0 {
+			f.RescaleSlope = slope
+		}
+	case tagPixelData:
+		f.PixelData = value
+	}
+}
+
+// PersonName formats a PN value "Family^Given^Middle^Prefix^Suffix" as "Given Middle Family"
+func PersonName(pn string) string {
+	// only the alphabetic representation is used
+	alphabetic, _, _ := strings.Cut(pn, "=")
+	parts := strings.Split(alphabetic, "^")
+	ordered := make([]string, 0, len(parts))
+	for _, i := range []int{3, 1, 2, 0, 4} {
+		if i < len(parts) && strings.TrimSpace(parts[i]) != "" {
+			ordered = append(ordered, strings.TrimSpace(parts[i]))
+		}
+	}
+	return strings.Join(ordered, " ")
+}
@@ -0,0 +1,253 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// encoder writes elements of a dataset in explicit or implicit VR little endian
+type encoder struct {
+	explicit bool
+	buf      bytes.Buffer
+}
+
+func (e *encoder) header(tag Tag, vr string, length uint32) *encoder {
+	binary.Write(&e.buf, binary.LittleEndian, tag)
+	switch {
+	case tag.Group == 0xFFFE || !e.explicit:
+		binary.Write(&e.buf, binary.LittleEndian, length)
+	case longVR[vr]:
+		e.buf.WriteString(vr + "\x00\x00")
+		binary.Write(&e.buf, binary.LittleEndian, length)
+	default:
+		e.buf.WriteString(vr)
+		binary.Write(&e.buf, binary.LittleEndian, uint16(length))
+	}
+	return e
+}
+
+// add writes an element, values of odd length are padded as DICOM requires
+func (e *encoder) add(tag Tag, vr string, value []byte) *encoder {
+	if len(value)%2 == 1 {
+		pad := byte(' ')
+		if vr == "UI" || vr == "OB" {
+			pad = 0
+		}
+		value = append(append([]byte{}, value...), pad)
+	}
+	e.header(tag, vr, uint32(len(value)))
+	e.buf.Write(value)
+	return e
+}
+
+func (e *encoder) text(tag Tag, vr, value string) *encoder {
+	return e.add(tag, vr, []byte(value))
+}
+
+func (e *encoder) us(tag Tag, value uint16) *encoder {
+	return e.add(tag, "US", binary.LittleEndian.AppendUint16(nil, value))
+}
+
+// part10 wraps a dataset in the preamble and the file meta information of syntax
+func part10(syntax string, dataset []byte) []byte {
+	meta := &encoder{explicit: true}
+	meta.text(tagTransferSyntax, "UI", syntax)
+	return append(append(append(make([]byte, 128), "DICM"...), meta.buf.Bytes()...), dataset...)
+}
+
+// study writes the identifying attributes of a CT study
+func study(e *encoder) *encoder {
+	return e.
+		text(tagSOPInstanceUID, "UI", "1.2.3.4.5.6").
+		text(tagStudyDate, "DA", "20240501").
+		text(tagModality, "CS", "CT").
+		text(tagStudyDescription, "LO", "Chest").
+		text(tagPatientName, "PN", "Doe^John").
+		text(tagPatientID, "LO", "P-1234").
+		text(tagStudyInstanceUID, "UI", "1.2.3.4").
+		text(tagSeriesInstanceUID, "UI", "1.2.3.4.5")
+}
+
+func TestParse(t *testing.T) {
+	for _, explicit := range []bool{true, false} {
+		syntax := ImplicitVRLittleEndian
+		if explicit {
+			syntax = ExplicitVRLittleEndian
+		}
+		t.Run(syntax, func(t *testing.T) {
+			e := study(&encoder{explicit: explicit})
+			// a nested sequence of undefined length, its attributes are not the ones of the file
+			e.header(Tag{0x0008, 0x1140}, "SQ", undefinedLength).
+				header(tagItem, "", undefinedLength).
+				text(tagPatientName, "PN", "Other^Patient").
+				header(tagItemDelimitation, "", 0).
+				header(tagSequenceDelimitation, "", 0)
+			e.us(tagRows, 1).us(tagColumns, 2).us(tagBitsAllocated, 8).us(tagBitsStored, 8).
+				text(tagPhotometric, "CS", "MONOCHROME2").
+				text(tagWindowCenter, "DS", "40\\400").
+				text(tagWindowWidth, "DS", "80\\1500").
+				text(tagRescaleIntercept, "DS", "-1024").
+				add(tagPixelData, "OW", []byte{10, 20})
+
+			f, err := Parse(part10(syntax, e.buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := File{
+				TransferSyntax: syntax, PatientName: "Doe^John", PatientID: "P-1234", Modality: "CT",
+				StudyDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), StudyDescription: "Chest",
+				StudyInstanceUID: "1.2.3.4", SeriesInstanceUID: "1.2.3.4.5", SOPInstanceUID: "1.2.3.4.5.6",
+				Rows: 1, Columns: 2, SamplesPerPixel: 1, BitsAllocated: 8, BitsStored: 8, Photometric: "MONOCHROME2",
+				NumberOfFrames: 1, WindowCenter: 40, WindowWidth: 80, RescaleIntercept: -1024, RescaleSlope: 1,
+			}
+			got := *f
+			if !bytes.Equal(got.PixelData, []byte{10, 20}) {
+				t.Errorf("PixelData = %v, want [10 20]", got.PixelData)
+			}
+			got.PixelData = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid := study(&encoder{explicit: true}).buf.Bytes()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no preamble", []byte("DICM")},
+		{"not dicom", append(make([]byte, 128), "JPEG"...)},
+		{"big endian", part10(explicitVRBigEndian, valid)},
+		{"deflated", part10(deflatedLittleEndian, valid)},
+		{"no study", part10(ExplicitVRLittleEndian, (&encoder{explicit: true}).text(tagModality, "CS", "CT").buf.Bytes())},
+		{"truncated", part10(ExplicitVRLittleEndian, valid[:len(valid)-3])},
+		{"unterminated sequence", part10(ExplicitVRLittleEndian, append(append([]byte{}, valid...),
+			(&encoder{explicit: true}).header(Tag{0x0008, 0x1140}, "SQ", undefinedLength).header(tagItem, "", 0).buf.Bytes()...))},
+		{"bad item", part10(ExplicitVRLittleEndian, append(append([]byte{}, valid...),
+			(&encoder{explicit: true}).header(Tag{0x0008, 0x1140}, "SQ", undefinedLength).text(tagModality, "CS", "CT").buf.Bytes()...))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); err == nil {
+				t.Error("Parse() succeeded")
+			}
+		})
+	}
+}
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		file File
+		want []uint8
+	}{
+		{"8 bit by value range", File{Rows: 1, Columns: 3, SamplesPerPixel: 1, BitsAllocated: 8, RescaleSlope: 1,
+			PixelData: []byte{10, 20, 30}}, []uint8{0, 128, 255}},
+		{"8 bit windowed", File{Rows: 1, Columns: 3, SamplesPerPixel: 1, BitsAllocated: 8, RescaleSlope: 1,
+			WindowCenter: 20, WindowWidth: 10, PixelData: []byte{10, 20, 30}}, []uint8{0, 128, 255}},
+		{"monochrome1", File{Rows: 1, Columns: 2, SamplesPerPixel: 1, BitsAllocated: 8, RescaleSlope: 1,
+			Photometric: "MONOCHROME1", PixelData: []byte{0, 255}}, []uint8{255, 0}},
+		{"12 bit signed", File{Rows: 2, Columns: 2, SamplesPerPixel: 1, BitsAllocated: 16, BitsStored: 12, PixelRepresentation: 1,
+			RescaleSlope: 1, PixelData: []byte{0xFF, 0x0F, 0x01, 0x00, 0xFF, 0x07, 0x00, 0x08}}, []uint8{127, 128, 255, 0}},
+		{"rescaled", File{Rows: 1, Columns: 2, SamplesPerPixel: 1, BitsAllocated: 16, BitsStored: 16, RescaleSlope: 2,
+			RescaleIntercept: -1024, WindowCenter: -1000, WindowWidth: 102, PixelData: []byte{0, 0, 0x0C, 0x00}}, []uint8{68, 128}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := tt.file.Frame()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.(*image.Gray).Pix; !bytes.Equal(got, tt.want) {
+				t.Errorf("Frame() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rgb := File{Rows: 1, Columns: 2, SamplesPerPixel: 3, BitsAllocated: 8, Photometric: "RGB", PlanarConfiguration: 1,
+		PixelData: []byte{255, 0, 0, 255, 0, 0}}
+	img, err := rgb.Frame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.At(1, 0); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("planar RGB pixel = %v, want green", got)
+	}
+
+	for name, f := range map[string]File{
+		"no size":       {SamplesPerPixel: 1, BitsAllocated: 8},
+		"short data":    {Rows: 2, Columns: 2, SamplesPerPixel: 1, BitsAllocated: 8, PixelData: []byte{1}},
+		"32 bit":        {Rows: 1, Columns: 1, SamplesPerPixel: 1, BitsAllocated: 32, PixelData: make([]byte, 4)},
+		"ybr":           {Rows: 1, Columns: 1, SamplesPerPixel: 3, BitsAllocated: 8, Photometric: "YBR_FULL", PixelData: make([]byte, 3)},
+		"jpeg lossless": {Encapsulated: true, TransferSyntax: "1.2.840.10008.1.2.4.70", Fragments: [][]byte{{0}}},
+		"no fragments":  {Encapsulated: true, TransferSyntax: JPEGBaseline},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := f.Frame(); err == nil {
+				t.Error("Frame() succeeded")
+			}
+		})
+	}
+}
+
+// TestEncapsulated parses a JPEG baseline file whose single frame is split into two fragments
+func TestEncapsulated(t *testing.T) {
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, image.NewGray(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	frame := out.Bytes()
+	if len(frame)%2 == 1 {
+		frame = append(frame, 0)
+	}
+	half := len(frame) / 2 &^ 1
+	e := study(&encoder{explicit: true})
+	e.header(tagPixelData, "OB", undefinedLength).
+		header(tagItem, "", 0).
+		add(tagItem, "", frame[:half]).
+		add(tagItem, "", frame[half:]).
+		header(tagSequenceDelimitation, "", 0)
+
+	f, err := Parse(part10(JPEGBaseline, e.buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Encapsulated || len(f.Fragments) != 2 {
+		t.Fatalf("Parse() = %d fragments, encapsulated %v, want 2 encapsulated fragments", len(f.Fragments), f.Encapsulated)
+	}
+	img, err := f.Frame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(8, 4) {
+		t.Errorf("Frame() = %v, want 8x4", size)
+	}
+}
+
+func TestPersonName(t *testing.T) {
+	tests := []struct {
+		pn   string
+		want string
+	}{
+		{"Doe^John", "John Doe"},
+		{"Doe^John^Q^Dr^Jr", "Dr John Q Doe Jr"},
+		{"Doe^John=山田^太郎", "John Doe"},
+		{"Doe", "Doe"},
+		{"^John^^^", "John"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := PersonName(tt.pn); got != tt.want {
+			t.Errorf("PersonName(%q) = %q, want %q", tt.pn, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+)
+
+// maxPixels rejects frames whose decoded size would exhaust memory
+const maxPixels = 100_000_000
+
+// Frame renders the first frame as an 8 bit image, grayscale frames are windowed with the
+// window of the file or, when absent, with the value range of the frame
+func (f *File) Frame() (image.Image, error) {
+	if f.Encapsulated {
+		return f.encapsulatedFrame()
+	}
+	if f.Rows <= 0 || f.Columns <= 0 || f.Rows*f.Columns > maxPixels {
+		return nil, fmt.Errorf("invalid frame size %dx%d", f.Columns, f.Rows)
+	}
+	bytesPerSample := f.BitsAllocated / 8
+	frameSize := f.Rows * f.Columns * f.SamplesPerPixel * bytesPerSample
+	if bytesPerSample == 0 || len(f.PixelData) < frameSize {
+		return nil, fmt.Errorf("pixel data is missing or shorter than a %dx%d frame", f.Columns, f.Rows)
+	}
+	switch {
+	case f.SamplesPerPixel == 1 && (f.BitsAllocated == 8 || f.BitsAllocated == 16):
+		return f.grayFrame(), nil
+	case f.SamplesPerPixel == 3 && f.BitsAllocated == 8 && f.Photometric == "RGB":
+		return f.rgbFrame(), nil
+	}
+	return nil, fmt.Errorf("unsupported pixel format: %d samples of %d bits, %s", f.SamplesPerPixel, f.BitsAllocated, f.Photometric)
+}
+
+func (f *File) encapsulatedFrame() (image.Image, error) {
+	if f.TransferSyntax != JPEGBaseline && f.TransferSyntax != JPEGExtended {
+		return nil, fmt.Errorf("preview of transfer syntax %s is not supported", f.TransferSyntax)
+	}
+	if len(f.Fragments) == 0 {
+		return nil, fmt.Errorf("pixel data has no fragments")
+	}
+	frame := f.Fragments[0]
+	if f.NumberOfFrames == 1 {
+		// a single frame may still be split into several fragments
+		frame = bytes.Join(f.Fragments, nil)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("invalid frame size %dx%d", config.Width, config.Height)
+	}
+	return jpeg.Decode(bytes.NewReader(frame))
+}
+
+// sample returns the modality value of the i-th grayscale sample
+func (f *File) sample(i int) float64 {
+	var raw float64
+	if f.BitsAllocated == 8 {
+		raw = float64(f.PixelData[i])
+	} else {
+		v := binary.LittleEndian.Uint16(f.PixelData[2*i:])
+		stored := f.BitsStored
+		if stored <= 0 || stored > 16 {
+			stored = 16
+		}
+		v &= uint16(1<<stored - 1)
+		if f.PixelRepresentation == 1 && v&(1<<(stored-1)) != 0 {
+			raw = float64(int32(v) - 1<<stored)
+		} else {
+			raw = float64(v)
+		}
+	}
+	return raw*f.RescaleSlope + f.RescaleIntercept
+}
+
+func (f *File) grayFrame() image.Image {
+	count := f.Rows * f.Columns
+	low, high := f.WindowCenter-f.WindowWidth/2, f.WindowCenter+f.WindowWidth/2
+	if f.WindowWidth <= 1 {
+		low, high = math.Inf(1), math.Inf(-1)
+		for i := 0; i < count; i++ {
+			v := f.sample(i)
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	scale := 255 / math.Max(high-low, 1)
+	img := image.NewGray(image.Rect(0, 0, f.Columns, f.Rows))
+	for i := 0; i < count; i++ {
+		v := math.Round((f.sample(i) - low) * scale)
+		v = math.Max(0, math.Min(255, v))
+		if f.Photometric == "MONOCHROME1" {
+			// MONOCHROME1 shows low values as white
+			v = 255 - v
+		}
+		img.Pix[i] = uint8(v)
+	}
+	return img
+}
+
+func (f *File) rgbFrame() image.Image {
+	count := f.Rows * f.Columns
+	img := image.NewRGBA(image.Rect(0, 0, f.Columns, f.Rows))
+	for i := 0; i < count; i++ {
+		var c color.RGBA
+		if f.PlanarConfiguration == 1 {
+			c = color.RGBA{f.PixelData[i], f.PixelData[count+i], f.PixelData[2*count+i], 255}
+		} else {
+			c = color.RGBA{f.PixelData[3*i], f.PixelData[3*i+1], f.PixelData[3*i+2], 255}
+		}
+		img.SetRGBA(i%f.Columns, i/f.Columns, c)
+	}
+	return img
+}
@@ -0,0 +1,234 @@
+package drugref
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity levels of a drug-drug interaction, ordered from least to most serious
+const (
+	SeverityMinor           = "minor"
+	SeverityModerate        = "moderate"
+	SeverityMajor           = "major"
+	SeverityContraindicated = "contraindicated"
+)
+
+var severityRank = map[string]int{
+	SeverityMinor:           1,
+	SeverityModerate:        2,
+	SeverityMajor:           3,
+	SeverityContraindicated: 4,
+}
+
+// Drug is a single entry of the reference dataset
+type Drug struct {
+	GenericName string   `json:"generic_name"`
+	Class       string   `json:"class"`
+	Aliases     []string `json:"aliases"`
+}
+
+// Interaction is a known interaction between two drugs or drug classes
+type Interaction struct {
+	DrugA       string `json:"drug_a"`
+	DrugB       string `json:"drug_b"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// Dataset is the on-disk layout of a JSON reference file
+type Dataset struct {
+	Drugs        []Drug        `json:"drugs"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Reference is an indexed, read-only drug reference
+type Reference struct {
+	drugs        []Drug
+	byName       map[string]*Drug
+	interactions []Interaction
+}
+
+// New builds a reference from an in-memory dataset, severities are matched regardless of case so
+// JSON and CSV files may write Major as well as major
+func New(data Dataset) (*Reference, error) {
+	ref := &Reference{
+		drugs:  data.Drugs,
+		byName: make(map[string]*Drug),
+	}
+	for i := range ref.drugs {
+		drug := &ref.drugs[i]
+		if drug.GenericName == "" {
+			return nil, fmt.Errorf("drug #%d has no generic name", i+1)
+		}
+		ref.byName[normalize(drug.GenericName)] = drug
+		for _, alias := range drug.Aliases {
+			ref.byName[normalize(alias)] = drug
+		}
+	}
+	for i, interaction := range data.Interactions {
+		if interaction.DrugA == "" || interaction.DrugB == "" {
+			return nil, fmt.Errorf("interaction #%d is missing a drug", i+1)
+		}
+		interaction.Severity = normalize(interaction.Severity)
+		if _, ok := severityRank[interaction.Severity]; !ok {
+			return nil, fmt.Errorf("interaction #%d has unknown severity %q", i+1, interaction.Severity)
+		}
+		ref.interactions = append(ref.interactions, interaction)
+	}
+	return ref, nil
+}
+
+// Load reads a reference file, the format is chosen by extension (.json or .csv)
+func Load(path string) (*Reference, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var data Dataset
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&data)
+	case ".csv":
+		data, err = readCSV(file)
+	default:
+		err = fmt.Errorf("unsupported drug reference format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read drug reference %s: %w", path, err)
+	}
+	return New(data)
+}
+
+// readCSV parses the csv layout, each row starts with its record type:
+//
+//	drug,<generic name>,<class>,<alias|alias...>
+//	interaction,<drug or class>,<drug or class>,<severity>,<description>
+func readCSV(r io.Reader) (Dataset, error) {
+	var data Dataset
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+		if err != nil {
+			return data, err
+		}
+		line++
+		switch strings.TrimSpace(record[0]) {
+		case "drug":
+			if len(record) < 3 {
+				return data, fmt.Errorf("line %d: drug record needs at least 3 fields", line)
+			}
+			drug := Drug{
+				GenericName: strings.TrimSpace(record[1]),
+				Class:       strings.TrimSpace(record[2]),
+			}
+			if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+				for _, alias := range strings.Split(record[3], "|") {
+					drug.Aliases = append(drug.Aliases, strings.TrimSpace(alias))
+				}
+			}
+			data.Drugs = append(data.Drugs, drug)
+		case "interaction":
+			if len(record) < 4 {
+				return data, fmt.Errorf("line %d: interaction record needs at least 4 fields", line)
+			}
+			interaction := Interaction{
+				DrugA:    strings.TrimSpace(record[1]),
+				DrugB:    strings.TrimSpace(record[2]),
+				Severity: strings.TrimSpace(record[3]),
+			}
+			if len(record) > 4 {
+				interaction.Description = strings.TrimSpace(record[4])
+			}
+			data.Interactions = append(data.Interactions, interaction)
+		case "record_type":
+			// header row
+		default:
+			return data, fmt.Errorf("line %d: unknown record type %q", line, record[0])
+		}
+	}
+}
+
+// Drugs returns all drugs of the reference
+func (r *Reference) Drugs() []Drug {
+	return r.drugs
+}
+
+// Lookup finds a drug by generic name or alias, ignoring case
+func (r *Reference) Lookup(name string) (Drug, bool) {
+	drug, ok := r.byName[normalize(name)]
+	if !ok {
+		return Drug{}, false
+	}
+	return *drug, true
+}
+
+// Match is an interaction found between a proposed drug and an existing one
+type Match struct {
+	Interaction
+	ProposedDrug string `json:"proposed_drug"`
+	ExistingDrug string `json:"existing_drug"`
+}
+
+// Check lists interactions between the proposed drug and each of the existing drugs,
+// the most severe ones come first
+func (r *Reference) Check(proposed string, existing []string) []Match {
+	var matches []Match
+	for _, other := range existing {
+		for _, interaction := range r.interactions {
+			if (r.matches(interaction.DrugA, proposed) && r.matches(interaction.DrugB, other)) ||
+				(r.matches(interaction.DrugB, proposed) && r.matches(interaction.DrugA, other)) {
+				matches = append(matches, Match{
+					Interaction:  interaction,
+					ProposedDrug: proposed,
+					ExistingDrug: other,
+				})
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return severityRank[matches[i].Severity] > severityRank[matches[j].Severity]
+	})
+	return matches
+}
+
+// matches reports whether an interaction side (generic name or class) covers the given drug name
+func (r *Reference) matches(side, name string) bool {
+	side = normalize(side)
+	if side == normalize(name) {
+		return true
+	}
+	drug, ok := r.byName[normalize(name)]
+	if !ok {
+		return false
+	}
+	return side == normalize(drug.GenericName) || side == normalize(drug.Class)
+}
+
+// SeverityRank orders severities, unknown ones rank lowest
+func SeverityRank(severity string) int {
+	return severityRank[severity]
+}
+
+// IsSevere reports whether the severity should block prescribing without review
+func IsSevere(severity string) bool {
+	return severityRank[severity] >= severityRank[SeverityMajor]
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,172 @@
+package drugref
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testReference(t *testing.T) *Reference {
+	t.Helper()
+	ref, err := Load(filepath.Join("..", "..", "config", "drug-reference.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestLookup(t *testing.T) {
+	ref := testReference(t)
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"warfarin", "warfarin", true},
+		{" Coumadin ", "warfarin", true},
+		{"وارفارین", "warfarin", true},
+		{"ASA", "aspirin", true},
+		{"nsaid", "", false},
+		{"unobtainium", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drug, ok := ref.Lookup(tt.name)
+			if ok != tt.wantOK || drug.GenericName != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.name, drug.GenericName, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ref := testReference(t)
+	tests := []struct {
+		name     string
+		proposed string
+		existing []string
+		want     []string
+	}{
+		{"by class", "ibuprofen", []string{"warfarin"}, []string{"major warfarin"}},
+		{"by alias", "advil", []string{"coumadin"}, []string{"major coumadin"}},
+		{"both directions", "warfarin", []string{"naproxen"}, []string{"major naproxen"}},
+		{"same class", "ibuprofen", []string{"aspirin"}, []string{"moderate aspirin"}},
+		{"most severe first", "ibuprofen", []string{"lisinopril", "warfarin"}, []string{"major warfarin", "moderate lisinopril"}},
+		{"no interaction", "omeprazole", []string{"metformin"}, nil},
+		{"unknown drug", "unobtainium", []string{"warfarin"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range ref.Check(tt.proposed, tt.existing) {
+				if m.ProposedDrug != tt.proposed {
+					t.Errorf("ProposedDrug = %q, want %q", m.ProposedDrug, tt.proposed)
+				}
+				got = append(got, m.Severity+" "+m.ExistingDrug)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q, %v) = %v, want %v", tt.proposed, tt.existing, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		data Dataset
+	}{
+		{"no generic name", Dataset{Drugs: []Drug{{Class: "nsaid"}}}},
+		{"missing drug", Dataset{Interactions: []Interaction{{DrugA: "warfarin", Severity: SeverityMajor}}}},
+		{"unknown severity", Dataset{Interactions: []Interaction{{DrugA: "warfarin", DrugB: "nsaid", Severity: "severe"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.data); err == nil {
+				t.Error("New() succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reference.json")
+	data := `{"drugs": [{"generic_name": "warfarin", "class": "anticoagulant"}, {"generic_name": "ibuprofen", "class": "nsaid"}],
+		"interactions": [{"drug_a": "warfarin", "drug_b": "nsaid", "severity": " Major ", "description": "Bleeding"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := ref.Check("warfarin", []string{"ibuprofen"})
+	if len(matches) != 1 || matches[0].Severity != SeverityMajor || !IsSevere(matches[0].Severity) {
+		t.Errorf("Check() = %+v, want the major interaction with its severity lower cased", matches)
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ref, err := Load(write("reference.csv", `record_type,name,class,aliases
+# a comment
+drug,warfarin,anticoagulant,coumadin | jantoven
+drug,ibuprofen,nsaid
+interaction,warfarin,nsaid,Major,"Bleeding, increased risk"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drug{
+		{GenericName: "warfarin", Class: "anticoagulant", Aliases: []string{"coumadin", "jantoven"}},
+		{GenericName: "ibuprofen", Class: "nsaid"},
+	}
+	if !reflect.DeepEqual(ref.Drugs(), want) {
+		t.Errorf("Drugs() = %+v, want %+v", ref.Drugs(), want)
+	}
+	matches := ref.Check("jantoven", []string{"ibuprofen"})
+	if len(matches) != 1 || matches[0].Severity != SeverityMajor || matches[0].Description != "Bleeding, increased risk" {
+		t.Errorf("Check() = %+v, want the major bleeding interaction", matches)
+	}
+
+	for name, content := range map[string]string{
+		"short-drug.csv":        "drug,warfarin\n",
+		"short-interaction.csv": "interaction,warfarin,nsaid\n",
+		"unknown.csv":           "food,grapefruit,citrus\n",
+		"reference.xml":         "<drugs/>",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(write(name, content)); err == nil {
+				t.Errorf("Load(%s) succeeded", name)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		severity string
+		rank     int
+		severe   bool
+	}{
+		{SeverityMinor, 1, false},
+		{SeverityModerate, 2, false},
+		{SeverityMajor, 3, true},
+		{SeverityContraindicated, 4, true},
+		{"unknown", 0, false},
+	}
+	for _, tt := range tests {
+		if rank, severe := SeverityRank(tt.severity), IsSevere(tt.severity); rank != tt.rank || severe != tt.severe {
+			t.Errorf("%s: rank %d severe %v, want %d %v", tt.severity, rank, severe, tt.rank, tt.severe)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clinic is the room receiving every event of the clinic
+const Clinic = "clinic"
+
+// Defaults of the broker
+const (
+	DefaultBuffer  = 64
+	DefaultHistory = 256
+)
+
+// Event is a change published to rooms, IDs increase for the life of the process
+type Event struct {
+	ID    uint64    `json:"id"`
+	Type  string    `json:"type"`
+	Rooms []string  `json:"rooms"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// Subscription receives the events of its rooms on C until it is closed. A subscriber that
+// does not keep up is dropped instead of blocking publishers, C is closed and Lagged is set
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	rooms  []string
+	lagged bool
+	broker *Broker
+}
+
+// Lagged reports whether the subscription was dropped for falling behind
+func (s *Subscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.lagged
+}
+
+// Close unsubscribes, it is safe to close a dropped subscription
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// Broker is an in-process pub/sub of events per room, it keeps the recent events of each room
+// so reconnecting subscribers can catch up
+type Broker struct {
+	mu      sync.Mutex
+	last    uint64
+	buffer  int
+	history int
+	subs    map[string]map[*Subscription]struct{}
+	recent  map[string][]Event
+}
+
+// NewBroker creates a broker, buffer is the number of undelivered events a subscriber may fall
+// behind and history the number of events kept per room
+func NewBroker(buffer, history int) *Broker {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	return &Broker{
+		buffer:  buffer,
+		history: history,
+		subs:    map[string]map[*Subscription]struct{}{},
+		recent:  map[string][]Event{},
+	}
+}
+
+// Publish sends an event to the subscribers of the rooms, each subscriber receives it once.
+// Publishing never blocks and a nil broker drops the event
+func (b *Broker) Publish(eventType string, data any, rooms ...string) {
+	if b == nil || len(rooms) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.last++
+	event := Event{ID: b.last, Type: eventType, Rooms: rooms, Time: time.Now(), Data: data}
+	delivered := map[*Subscription]bool{}
+	for _, room := range rooms {
+		recent := append(b.recent[room], event)
+		if len(recent) > b.history {
+			recent = recent[len(recent)-b.history:]
+		}
+		b.recent[room] = recent
+		for sub := range b.subs[room] {
+			if delivered[sub] {
+				continue
+			}
+			delivered[sub] = true
+			select {
+			case sub.c <- event:
+			default:
+				sub.lagged = true
+				b.remove(sub)
+			}
+		}
+	}
+}
+
+// Subscribe subscribes to the rooms and returns the kept events after lastID, which are not
+// sent on C. An unknown lastID, e.g. from before a restart, replays nothing
+func (b *Broker) Subscribe(rooms []string, lastID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := make(chan Event, b.buffer)
+	sub := &Subscription{C: c, c: c, rooms: rooms, broker: b}
+	for _, room := range rooms {
+		if b.subs[room] == nil {
+			b.subs[room] = map[*Subscription]struct{}{}
+		}
+		b.subs[room][sub] = struct{}{}
+	}
+	if lastID == 0 || lastID > b.last {
+		return sub, nil
+	}
+	seen := map[uint64]bool{}
+	var replay []Event
+	for _, room := range rooms {
+		for _, event := range b.recent[room] {
+			if event.ID > lastID && !seen[event.ID] {
+				seen[event.ID] = true
+				replay = append(replay, event)
+			}
+		}
+	}
+	sort.Slice(replay, func(i, j int) bool { return replay[i].ID < replay[j].ID })
+	return sub, replay
+}
+
+// Subscribers returns the number of open subscriptions of a room
+func (b *Broker) Subscribers(room string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[room])
+}
+
+// remove unsubscribes and closes the channel once, b.mu must be held
+func (b *Broker) remove(sub *Subscription) {
+	open := false
+	for _, room := range sub.rooms {
+		if _, ok := b.subs[room][sub]; ok {
+			open = true
+			delete(b.subs[room], sub)
+			if len(b.subs[room]) == 0 {
+				delete(b.subs, room)
+			}
+		}
+	}
+	if open {
+		close(sub.c)
+	}
+}
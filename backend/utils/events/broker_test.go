@@ -0,0 +1,152 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ids drains the events waiting on c and returns their IDs
+func ids(c <-chan Event) []uint64 {
+	var got []uint64
+	for {
+		select {
+		case event, ok := <-c:
+			if !ok {
+				return got
+			}
+			got = append(got, event.ID)
+		default:
+			return got
+		}
+	}
+}
+
+func eventIDs(events []Event) []uint64 {
+	var got []uint64
+	for _, event := range events {
+		got = append(got, event.ID)
+	}
+	return got
+}
+
+func TestPublishFanOut(t *testing.T) {
+	b := NewBroker(8, 8)
+	clinic, _ := b.Subscribe([]string{Clinic}, 0)
+	doctor, _ := b.Subscribe([]string{"doctor:d-1"}, 0)
+	both, _ := b.Subscribe([]string{Clinic, "doctor:d-1"}, 0)
+
+	b.Publish("queue.called", nil, Clinic, "doctor:d-1")
+	b.Publish("queue.joined", nil, Clinic)
+	b.Publish("queue.left", nil, "doctor:d-2")
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []uint64
+	}{
+		{"clinic", clinic, []uint64{1, 2}},
+		{"doctor", doctor, []uint64{1}},
+		{"both rooms receive each event once", both, []uint64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.sub.C); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("received %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(2, 8)
+	slow, _ := b.Subscribe([]string{Clinic}, 0)
+	fast, _ := b.Subscribe([]string{Clinic}, 0)
+
+	for i := 0; i < 3; i++ {
+		b.Publish("queue.joined", i, Clinic)
+		ids(fast.C)
+	}
+
+	if !slow.Lagged() {
+		t.Errorf("Lagged() = false for a subscriber behind by more than its buffer, want true")
+	}
+	if got := ids(slow.C); !reflect.DeepEqual(got, []uint64{1, 2}) {
+		t.Errorf("dropped subscriber received %v, want the buffered [1 2] before C is closed", got)
+	}
+	if _, ok := <-slow.C; ok {
+		t.Errorf("C of a dropped subscriber is open, want closed")
+	}
+	if fast.Lagged() {
+		t.Errorf("Lagged() = true for a subscriber keeping up, want false")
+	}
+	if got := b.Subscribers(Clinic); got != 1 {
+		t.Errorf("Subscribers() = %d after dropping, want 1", got)
+	}
+	slow.Close()
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	b := NewBroker(8, 3)
+	b.Publish("queue.joined", nil, Clinic)               // 1
+	b.Publish("queue.called", nil, Clinic, "doctor:d-1") // 2
+	b.Publish("queue.called", nil, "doctor:d-1")         // 3
+	b.Publish("queue.joined", nil, Clinic)               // 4
+	b.Publish("queue.joined", nil, Clinic)               // 5
+
+	tests := []struct {
+		name   string
+		rooms  []string
+		lastID uint64
+		want   []uint64
+	}{
+		{"no last id", []string{Clinic}, 0, nil},
+		{"after the last id", []string{Clinic}, 3, []uint64{4, 5}},
+		{"history keeps the last events of a room", []string{Clinic}, 1, []uint64{2, 4, 5}},
+		{"events of several rooms once and in order", []string{"doctor:d-1", Clinic}, 1, []uint64{2, 3, 4, 5}},
+		{"up to date", []string{Clinic}, 5, nil},
+		{"unknown last id", []string{Clinic}, 42, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay := b.Subscribe(tt.rooms, tt.lastID)
+			defer sub.Close()
+			if got := eventIDs(replay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Subscribe(%v, %d) replayed %v, want %v", tt.rooms, tt.lastID, got, tt.want)
+			}
+			if got := ids(sub.C); got != nil {
+				t.Errorf("replayed events were also sent on C: %v", got)
+			}
+		})
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	b := NewBroker(8, 8)
+	sub, _ := b.Subscribe([]string{Clinic, "doctor:d-1"}, 0)
+	other, _ := b.Subscribe([]string{Clinic}, 0)
+
+	sub.Close()
+	sub.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Errorf("C is open after Close(), want closed")
+	}
+	if got := b.Subscribers("doctor:d-1"); got != 0 {
+		t.Errorf("Subscribers(doctor:d-1) = %d after Close(), want 0", got)
+	}
+	if _, ok := b.subs["doctor:d-1"]; ok {
+		t.Errorf("room without subscribers is kept, want it removed")
+	}
+	b.Publish("queue.joined", nil, Clinic, "doctor:d-1")
+	if got := ids(other.C); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Errorf("remaining subscriber received %v, want [1]", got)
+	}
+	if sub.Lagged() {
+		t.Errorf("Lagged() = true for a closed subscription, want false")
+	}
+}
+
+func TestPublishNilBroker(t *testing.T) {
+	var b *Broker
+	b.Publish("queue.joined", nil, Clinic)
+}
@@ -0,0 +1,234 @@
+package i18n
+
+// Codes of errors returned by the handlers
+const (
+	InvalidRequest         = "INVALID_REQUEST"
+	InternalError          = "INTERNAL_ERROR"
+	Unauthorized           = "UNAUTHORIZED"
+	PageNotFound           = "PAGE_NOT_FOUND"
+	InvalidDate            = "INVALID_DATE"
+	InvalidDateRange       = "INVALID_DATE_RANGE"
+	InvalidPage            = "INVALID_PAGE"
+	PatientNotFound        = "PATIENT_NOT_FOUND"
+	PatientOrDoctorMissing = "PATIENT_OR_DOCTOR_NOT_FOUND"
+	InvalidVisitID         = "INVALID_VISIT_ID"
+	InvalidVisitStatus     = "INVALID_VISIT_STATUS"
+	VisitNotFound          = "VISIT_NOT_FOUND"
+	VisitLocked            = "VISIT_LOCKED"
+	InvalidMedicationID    = "INVALID_MEDICATION_ID"
+	InvalidScheduleID      = "INVALID_THERAPY_SCHEDULE_ID"
+	ScheduleNotFound       = "THERAPY_SCHEDULE_NOT_FOUND"
+	ScheduleEnded          = "THERAPY_SCHEDULE_ENDED"
+	SessionOutside         = "SESSION_OUTSIDE_SCHEDULE"
+	SessionInFuture        = "SESSION_IN_FUTURE"
+	HolidayNotFound        = "CLINIC_HOLIDAY_NOT_FOUND"
+	FileRequired           = "FILE_REQUIRED"
+	FileTooLarge           = "FILE_TOO_LARGE"
+	FileTypeNotAllowed     = "FILE_TYPE_NOT_ALLOWED"
+	FileInfected           = "FILE_INFECTED"
+	FileNotScanned         = "FILE_NOT_SCANNED"
+	FileQuarantined        = "FILE_QUARANTINED"
+	FileNotFound           = "FILE_NOT_FOUND"
+	InvalidLink            = "INVALID_DOWNLOAD_LINK"
+	InvalidImage           = "INVALID_IMAGE"
+	ImageNotFound          = "IMAGE_NOT_FOUND"
+	PatientMismatch        = "DICOM_PATIENT_MISMATCH"
+	InvalidCategory        = "INVALID_CATEGORY"
+	DocumentNotFound       = "DOCUMENT_NOT_FOUND"
+	FeedNotFound           = "CALENDAR_FEED_NOT_FOUND"
+)
+
+// Codes of the rules a field of a request breaks, each field error names its field
+const (
+	ValidationRequired  = "VALIDATION_REQUIRED"
+	ValidationEmail     = "VALIDATION_EMAIL"
+	ValidationPhone     = "VALIDATION_PHONE"
+	ValidationOneOf     = "VALIDATION_ONE_OF"
+	ValidationRange     = "VALIDATION_RANGE"
+	ValidationAfter     = "VALIDATION_AFTER"
+	ValidationNotBefore = "VALIDATION_NOT_BEFORE"
+	ValidationBelow     = "VALIDATION_BELOW"
+	ValidationExcluded  = "VALIDATION_EXCLUDED"
+	ValidationFuture    = "VALIDATION_FUTURE"
+	ValidationInvalid   = "VALIDATION_INVALID"
+)
+
+// DefaultErrors are the built-in descriptions of error codes
+var DefaultErrors = Texts{
+	// generic errors
+	"SYSTEM_FAULT":   {Persian: "خطای سامانه، لطفا دوباره تلاش کنید", English: "System fault, please try again"},
+	InvalidRequest:   {Persian: "درخواست نامعتبر است", English: "The request is invalid"},
+	InternalError:    {Persian: "خطای داخلی سامانه", English: "Internal server error"},
+	Unauthorized:     {Persian: "کاربر احراز هویت نشده است", English: "The user is not authenticated"},
+	"UNKNOWN_METHOD": {Persian: "عملیات تعریف نشده است", English: "The operation is not defined"},
+	"ERROR_INSERT":   {Persian: "خطا در ثبت اطلاعات", English: "Error saving the data"},
+	"ERROR_UPDATE":   {Persian: "خطا در ویرایش اطلاعات", English: "Error updating the data"},
+	"ERROR_DELETE":   {Persian: "خطا در حذف اطلاعات", English: "Error deleting the data"},
+	InvalidDate:      {Persian: "تاریخ نامعتبر است", English: "The date is invalid"},
+	InvalidDateRange: {Persian: "بازه تاریخ نامعتبر است", English: "The date range is invalid"},
+	InvalidPage:      {Persian: "صفحه یا مرتب‌سازی نامعتبر است", English: "The page or sort order is invalid"},
+	"INVALID_STATUS": {Persian: "وضعیت نامعتبر است", English: "The status is invalid"},
+	PageNotFound:     {Persian: "صفحه یافت نشد", English: "404 page not found"},
+
+	// users and tokens
+	"AUTH_BAD_METHOD":       {Persian: "نوع سرآیند احراز هویت معتبر نیست", English: "The auth header type is not valid"},
+	"INVALID_AUTH_HEADER":   {Persian: "سرآیند احراز هویت نامعتبر است", English: "The auth header is invalid"},
+	"INVALID_TOKEN":         {Persian: "توکن نامعتبر است", English: "Invalid token"},
+	"EXPIRED_TOKEN":         {Persian: "توکن منقضی شده است", English: "Expired token"},
+	"ERROR_PARSE_TOKEN":     {Persian: "خطا در خواندن توکن", English: "Error reading the token"},
+	"ERROR_GENERATE_TOKEN":  {Persian: "خطا در ایجاد توکن", English: "Error generating the token"},
+	"INVALID_PASSWORD":      {Persian: "رمز عبور نادرست است", English: "The password is incorrect"},
+	"USER_NOT_FOUND":        {Persian: "کاربر یافت نشد", English: "User not found"},
+	"USER_EXISTS":           {Persian: "کاربر از قبل وجود دارد", English: "The user already exists"},
+	"ERROR_INSERT_NEW_USER": {Persian: "خطا در ثبت کاربر جدید", English: "Error registering the new user"},
+	"INVALID_VALIDATION":    {Persian: "خطا در اعتبارسنجی درخواست", English: "Error validating the request"},
+
+	// fields of requests
+	ValidationRequired:  {Persian: "مقدار این فیلد الزامی است", English: "The field is required"},
+	ValidationEmail:     {Persian: "نشانی ایمیل نامعتبر است", English: "The field must be an email address"},
+	ValidationPhone:     {Persian: "شماره تلفن باید با کد کشور باشد، مانند +989121234567", English: "The field must be a phone number with its country code such as +989121234567"},
+	ValidationOneOf:     {Persian: "مقدار این فیلد مجاز نیست", English: "The value of the field is not allowed"},
+	ValidationRange:     {Persian: "مقدار این فیلد خارج از بازه مجاز است", English: "The value of the field is out of range"},
+	ValidationAfter:     {Persian: "تاریخ پایان باید بعد از تاریخ شروع باشد", English: "The end must be after the start"},
+	ValidationNotBefore: {Persian: "تاریخ پایان نمی‌تواند پیش از تاریخ شروع باشد", English: "The end cannot be before the start"},
+	ValidationBelow:     {Persian: "مقدار این فیلد باید کمتر از فیلد دیگر باشد", English: "The field must be lower than the other field"},
+	ValidationExcluded:  {Persian: "این فیلد همراه با فیلد دیگر پذیرفته نیست", English: "The field cannot be sent along with the other field"},
+	ValidationFuture:    {Persian: "تاریخ نمی‌تواند در آینده باشد", English: "The date cannot be in the future"},
+	ValidationInvalid:   {Persian: "مقدار این فیلد نامعتبر است", English: "The value of the field is invalid"},
+
+	// patients and doctors
+	"INVALID_PATIENT_ID":   {Persian: "شناسه بیمار الزامی است", English: "patient_id is required"},
+	PatientNotFound:        {Persian: "بیمار یافت نشد", English: "patient not found"},
+	"ERROR_GET_PATIENT":    {Persian: "خطا در دریافت اطلاعات بیمار", English: "Error loading the patient"},
+	"INVALID_DOCTOR_ID":    {Persian: "شناسه پزشک الزامی است", English: "doctor_id is required"},
+	"DOCTOR_NOT_FOUND":     {Persian: "پزشک یافت نشد", English: "Doctor not found"},
+	"ERROR_GET_DOCTOR":     {Persian: "خطا در دریافت اطلاعات پزشک", English: "Error loading the doctor"},
+	"DEPARTMENT_NOT_FOUND": {Persian: "پزشکی در این بخش خدمت نمی‌کند", English: "No doctor serves the department"},
+	PatientOrDoctorMissing: {Persian: "بیمار یا پزشک یافت نشد", English: "patient or doctor not found"},
+
+	// visits
+	InvalidVisitID:              {Persian: "شناسه ویزیت نامعتبر است", English: "Invalid visit ID"},
+	InvalidVisitStatus:          {Persian: "ویزیت جدید باید در وضعیت نوبت‌دهی یا پذیرش باشد", English: "a new visit must be scheduled or checked_in"},
+	VisitNotFound:               {Persian: "ویزیت یافت نشد", English: "visit not found"},
+	"ERROR_GET_VISIT":           {Persian: "خطا در دریافت اطلاعات ویزیت", English: "Error loading the visit"},
+	"ERROR_GET_TRANSITIONS":     {Persian: "خطا در دریافت تاریخچه وضعیت ویزیت", English: "Error loading the status history of the visit"},
+	"INVALID_STATUS_TRANSITION": {Persian: "تغییر وضعیت ویزیت مجاز نیست", English: "The visit cannot move to this status"},
+	"VISIT_STATUS_CHANGED":      {Persian: "وضعیت ویزیت در این فاصله تغییر کرده است", English: "The visit status changed in the meantime"},
+	VisitLocked:                 {Persian: "ویزیت قفل شده است، از الحاقیه استفاده کنید", English: "visit is locked, use an addendum instead"},
+	"VISIT_NOT_LOCKED":          {Persian: "ویزیت امضا یا قفل نشده است، آن را مستقیما ویرایش کنید", English: "The visit is not signed or locked, edit it directly"},
+	"VISIT_NOT_LOCKABLE":        {Persian: "فقط ویزیت‌های تکمیل شده قفل می‌شوند", English: "Only completed visits can be locked"},
+	"VISIT_NOT_COMPLETED":       {Persian: "فقط ویزیت‌های تکمیل شده امضا می‌شوند", English: "Only completed visits can be signed"},
+	"VISIT_ALREADY_SIGNED":      {Persian: "ویزیت قبلا امضا شده است", English: "The visit is already signed"},
+	"VISIT_CHANGED":             {Persian: "ویزیت هنگام امضا تغییر کرد، آن را بازبینی و دوباره امضا کنید", English: "The visit changed while signing, review and sign again"},
+	"VISIT_NOT_ACTIVE":          {Persian: "برای این ویزیت نمی‌توان آزمایش درخواست کرد", English: "Labs cannot be ordered for this visit"},
+	"ERROR_GET_ADDENDA":         {Persian: "خطا در دریافت الحاقیه‌ها", English: "Error loading the addenda"},
+
+	// appointments and queues
+	"ERROR_GET_WORKING_HOURS": {Persian: "خطا در دریافت ساعات کاری", English: "Error loading the working hours"},
+	"ERROR_GET_EXCEPTIONS":    {Persian: "خطا در دریافت استثناهای برنامه", English: "Error loading the schedule exceptions"},
+	"INVALID_EXCEPTION":       {Persian: "استثنای برنامه نامعتبر است", English: "The schedule exception is invalid"},
+	"INVALID_EXCEPTION_ID":    {Persian: "شناسه استثنا الزامی است", English: "exception id is required"},
+	"EXCEPTION_NOT_FOUND":     {Persian: "استثنای برنامه یافت نشد", English: "Schedule exception not found"},
+	"ERROR_GET_BOOKINGS":      {Persian: "خطا در دریافت نوبت‌ها", English: "Error loading the bookings"},
+	"SLOT_NOT_AVAILABLE":      {Persian: "این زمان نوبت آزاد نیست", English: "The slot is not free"},
+	"SLOT_TAKEN":              {Persian: "این نوبت توسط فرد دیگری رزرو شد", English: "The slot was booked by someone else"},
+	"ERROR_GET_QUEUE":         {Persian: "خطا در دریافت صف", English: "Error loading the queue"},
+	"ERROR_GET_QUEUE_ENTRY":   {Persian: "خطا در دریافت نوبت صف", English: "Error loading the queue entry"},
+	"INVALID_QUEUE":           {Persian: "صف نامعتبر است", English: "The queue is invalid"},
+	"INVALID_QUEUE_ENTRY_ID":  {Persian: "شناسه نوبت صف الزامی است", English: "queue entry id is required"},
+	"INVALID_QUEUE_STATUS":    {Persian: "وضعیت نوبت صف نامعتبر است", English: "The queue entry status is invalid"},
+	"QUEUE_ENTRY_NOT_FOUND":   {Persian: "نوبت صف یافت نشد", English: "Queue entry not found"},
+	"QUEUE_ENTRY_CHANGED":     {Persian: "نوبت صف در این فاصله تغییر کرده است", English: "The queue entry changed in the meantime"},
+	"QUEUE_CLOSED":            {Persian: "صف این روز بسته شده است", English: "The queue of the day is closed"},
+	"QUEUE_EMPTY":             {Persian: "بیماری در صف انتظار نیست", English: "No walk-in is waiting"},
+	"ALREADY_QUEUED":          {Persian: "بیمار امروز در صف انتظار است", English: "The patient is already waiting today"},
+
+	// diagnoses, labs, vitals and medications
+	"ERROR_GET_DIAGNOSES":       {Persian: "خطا در دریافت تشخیص‌ها", English: "Error loading the diagnoses"},
+	"ERROR_GET_ICD10":           {Persian: "خطا در دریافت کد ICD-10", English: "Error loading the ICD-10 code"},
+	"ERROR_SEARCH_ICD10":        {Persian: "خطا در جستجوی کدهای ICD-10", English: "Error searching the ICD-10 codes"},
+	"ERROR_READ_ICD10":          {Persian: "خطا در خواندن فایل ICD-10", English: "Error reading the ICD-10 file"},
+	"ERROR_IMPORT_ICD10":        {Persian: "خطا در بارگذاری کدهای ICD-10", English: "Error importing the ICD-10 codes"},
+	"ICD10_PATH_NOT_CONFIGURED": {Persian: "مسیر فایل ICD-10 تنظیم نشده است", English: "icd10Path is not configured"},
+	"UNKNOWN_ICD10_CODE":        {Persian: "کد ICD-10 در جدول کدها نیست", English: "The ICD-10 code is not in the code table"},
+	"QUERY_TOO_SHORT":           {Persian: "عبارت جستجو باید حداقل ۲ حرف باشد", English: "q must have at least 2 characters"},
+	"ERROR_GET_LAB_ORDER":       {Persian: "خطا در دریافت درخواست آزمایش", English: "Error loading the lab order"},
+	"ERROR_GET_LAB_ORDERS":      {Persian: "خطا در دریافت درخواست‌های آزمایش", English: "Error loading the lab orders"},
+	"ERROR_GET_LAB_RESULTS":     {Persian: "خطا در دریافت نتایج آزمایش", English: "Error loading the lab results"},
+	"INVALID_LAB_ORDER_ID":      {Persian: "شناسه درخواست آزمایش الزامی است", English: "lab order id is required"},
+	"INVALID_LAB_PANEL":         {Persian: "پنل آزمایش نامعتبر است", English: "The lab panel is invalid"},
+	"INVALID_LAB_RESULTS":       {Persian: "نتایج آزمایش نامعتبر است", English: "The lab results are invalid"},
+	"INVALID_LAB_VALUE":         {Persian: "مقدار آزمایش نامعتبر است", English: "The lab value is invalid"},
+	"INVALID_ANALYTE":           {Persian: "این آنالیت جزو پنل آزمایش نیست", English: "The analyte is not part of the panel"},
+	"INVALID_UNIT":              {Persian: "واحد نتیجه آزمایش نادرست است", English: "The result is reported in the wrong unit"},
+	"LAB_ORDER_NOT_FOUND":       {Persian: "درخواست آزمایش یافت نشد", English: "Lab order not found"},
+	"LAB_ORDER_CANCELLED":       {Persian: "درخواست آزمایش لغو شده است", English: "The lab order is cancelled"},
+	"LAB_ORDER_CHANGED":         {Persian: "درخواست آزمایش در این فاصله تغییر کرده است", English: "The lab order changed in the meantime"},
+	"LAB_ORDER_NOT_CANCELLABLE": {Persian: "فقط درخواست‌های بدون نتیجه لغو می‌شوند", English: "Only lab orders without results can be cancelled"},
+	"ERROR_GET_VITALS":          {Persian: "خطا در دریافت علائم حیاتی", English: "Error loading the vital signs"},
+	"ERROR_ACTIVE_MEDICATIONS":  {Persian: "خطا در دریافت داروهای فعال", English: "Error loading the active medications"},
+	InvalidMedicationID:         {Persian: "شناسه دارو نامعتبر است", English: "Invalid medication ID"},
+
+	// therapy schedules
+	InvalidScheduleID:            {Persian: "شناسه برنامه درمانی نامعتبر است", English: "Invalid therapy schedule ID"},
+	ScheduleNotFound:             {Persian: "برنامه درمانی یافت نشد", English: "Therapy schedule not found"},
+	ScheduleEnded:                {Persian: "برنامه درمانی پایان یافته است", English: "The therapy schedule has ended"},
+	SessionOutside:               {Persian: "تاریخ جلسه خارج از برنامه درمانی است", English: "session_date is outside the therapy schedule"},
+	SessionInFuture:              {Persian: "تاریخ جلسه در آینده است", English: "session_date is in the future"},
+	HolidayNotFound:              {Persian: "تعطیلی درمانگاه یافت نشد", English: "Clinic holiday not found"},
+	"ERROR_GET_THERAPY_SCHEDULE": {Persian: "خطا در دریافت برنامه درمانی", English: "Error loading the therapy schedule"},
+	"ERROR_GET_THERAPY_SESSIONS": {Persian: "خطا در دریافت جلسات درمانی", English: "Error loading the therapy sessions"},
+	"ERROR_GET_CLINIC_HOLIDAYS":  {Persian: "خطا در دریافت تعطیلات درمانگاه", English: "Error loading the clinic holidays"},
+
+	// images and documents
+	FileRequired:       {Persian: "فایل الزامی است", English: "file is required"},
+	FileTooLarge:       {Persian: "حجم فایل بیش از حد مجاز است", English: "The file is too large"},
+	FileTypeNotAllowed: {Persian: "نوع فایل مجاز نیست", English: "The file type is not allowed"},
+	FileInfected:       {Persian: "فایل آلوده است", English: "The file is infected"},
+	FileNotScanned:     {Persian: "فایل قابل بررسی نبود و قرنطینه شد", English: "file could not be scanned and was quarantined"},
+	FileQuarantined:    {Persian: "فایل قرنطینه شده است", English: "The file is quarantined"},
+	FileNotFound:       {Persian: "فایل یافت نشد", English: "The file was not found"},
+	InvalidLink:        {Persian: "پیوند دانلود نامعتبر یا منقضی است", English: "download link is invalid or expired"},
+	InvalidImage:       {Persian: "تصویر نامعتبر است", English: "The image is invalid"},
+	ImageNotFound:      {Persian: "تصویر یافت نشد", English: "image not found"},
+	PatientMismatch:    {Persian: "بیمار فایل DICOM با بیمار ویزیت یکسان نیست", English: "The patient of the DICOM file does not match the visit"},
+	InvalidCategory:    {Persian: "دسته مدرک نامعتبر است", English: "The document category is invalid"},
+	DocumentNotFound:   {Persian: "مدرک یافت نشد", English: "document not found"},
+
+	// notifications, jobs and calendar feeds
+	"ERROR_GET_NOTIFICATIONS":  {Persian: "خطا در دریافت اعلان‌ها", English: "Error loading the notifications"},
+	"ERROR_GET_ATTEMPTS":       {Persian: "خطا در دریافت تلاش‌های ارسال", English: "Error loading the delivery attempts"},
+	"INVALID_NOTIFICATION_ID":  {Persian: "شناسه اعلان الزامی است", English: "notification id is required"},
+	"NOTIFICATION_NOT_FAILED":  {Persian: "فقط اعلان‌های ناموفق دوباره ارسال می‌شوند", English: "Only failed notifications can be retried"},
+	"ERROR_GET_JOBS":           {Persian: "خطا در دریافت کارهای زمان‌بندی شده", English: "Error loading the jobs"},
+	"ERROR_GET_JOB_RUNS":       {Persian: "خطا در دریافت اجراهای کار", English: "Error loading the job runs"},
+	"JOB_NOT_FOUND":            {Persian: "کار زمان‌بندی شده یافت نشد", English: "Job not found"},
+	FeedNotFound:               {Persian: "تقویم یافت نشد", English: "calendar feed not found"},
+	"ERROR_GET_CALENDAR_FEED":  {Persian: "خطا در دریافت تقویم", English: "Error loading the calendar feed"},
+	"ERROR_GET_CALENDAR_OWNER": {Persian: "خطا در دریافت صاحب تقویم", English: "Error loading the owner of the calendar"},
+	"NO_CALENDAR":              {Persian: "کاربر پزشک یا بیمار نیست", English: "The user is neither a doctor nor a patient"},
+}
+
+// Codes of the messages of successful responses
+const (
+	ImagesRetrieved    = "IMAGES_RETRIEVED"
+	ImageSaved         = "IMAGE_SAVED"
+	ImageDeleted       = "IMAGE_DELETED"
+	StudiesRetrieved   = "IMAGING_STUDIES_RETRIEVED"
+	DocumentsRetrieved = "DOCUMENTS_RETRIEVED"
+	DocumentSaved      = "DOCUMENT_SAVED"
+	DocumentExists     = "DOCUMENT_EXISTS"
+	DocumentDeleted    = "DOCUMENT_DELETED"
+)
+
+// DefaultMessages are the built-in descriptions of message codes
+var DefaultMessages = Texts{
+	ImagesRetrieved:    {Persian: "تصاویر ویزیت دریافت شد", English: "Visit images retrieved successfully"},
+	ImageSaved:         {Persian: "تصویر ویزیت ثبت شد", English: "Visit image saved successfully"},
+	ImageDeleted:       {Persian: "تصویر ویزیت حذف شد", English: "Visit image deleted successfully"},
+	StudiesRetrieved:   {Persian: "مطالعات تصویربرداری دریافت شد", English: "Imaging studies retrieved successfully"},
+	DocumentsRetrieved: {Persian: "مدارک بیمار دریافت شد", English: "Patient documents retrieved successfully"},
+	DocumentSaved:      {Persian: "مدرک بیمار ثبت شد", English: "Patient document saved successfully"},
+	DocumentExists:     {Persian: "این مدرک پیش‌تر ثبت شده است", English: "The document is already stored"},
+	DocumentDeleted:    {Persian: "مدرک بیمار حذف شد", English: "Patient document deleted successfully"},
+}
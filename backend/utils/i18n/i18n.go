@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Languages of the catalogue
+const (
+	Persian = "fa"
+	English = "en"
+)
+
+// Texts are descriptions per code and language
+type Texts map[string]map[string]string
+
+// File is the layout of a catalogue file, errors are described by libError codes and messages by
+// the codes of successful responses
+type File struct {
+	Errors   Texts `json:"errors"`
+	Messages Texts `json:"messages"`
+}
+
+// Catalogue describes error and message codes, a missing language falls back to the default language
+type Catalogue struct {
+	fallback  string
+	errors    Texts
+	messages  Texts
+	languages map[string]bool
+}
+
+func merge(sets ...Texts) Texts {
+	merged := Texts{}
+	for _, set := range sets {
+		for code, languages := range set {
+			if merged[code] == nil {
+				merged[code] = map[string]string{}
+			}
+			for language, text := range languages {
+				merged[code][strings.ToLower(language)] = text
+			}
+		}
+	}
+	return merged
+}
+
+// New creates a catalogue of file over the defaults, fallback is the language used when a request
+// asks for none or one without a text
+func New(file File, fallback string) (*Catalogue, error) {
+	if fallback == "" {
+		fallback = Persian
+	}
+	c := &Catalogue{
+		fallback: fallback,
+		errors:   merge(DefaultErrors, file.Errors),
+		messages: merge(DefaultMessages, file.Messages),
+	}
+	c.languages = map[string]bool{fallback: true}
+	for kind, texts := range map[string]Texts{"error": c.errors, "message": c.messages} {
+		for code, languages := range texts {
+			if _, ok := languages[fallback]; !ok {
+				return nil, fmt.Errorf("%s %s has no %s text", kind, code, fallback)
+			}
+			for language := range languages {
+				c.languages[language] = true
+			}
+		}
+	}
+	return c, nil
+}
+
+// Load reads a catalogue file in JSON, codes and languages missing from it keep their defaults
+func Load(path, fallback string) (*Catalogue, error) {
+	if path == "" {
+		return New(File{}, fallback)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file File
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return New(file, fallback)
+}
+
+func lookup(texts Texts, fallback, code, language string) (string, bool) {
+	languages, ok := texts[code]
+	if !ok {
+		return "", false
+	}
+	if text, ok := languages[language]; ok {
+		return text, true
+	}
+	return languages[fallback], true
+}
+
+// Error describes the error code in language
+func (c *Catalogue) Error(code, language string) (string, bool) {
+	return lookup(c.errors, c.fallback, code, language)
+}
+
+// Message describes the message code in language
+func (c *Catalogue) Message(code, language string) (string, bool) {
+	return lookup(c.messages, c.fallback, code, language)
+}
+
+// Fallback is the default language of the catalogue
+func (c *Catalogue) Fallback() string {
+	return c.fallback
+}
+
+// Language picks the first language of an Accept-Language header the catalogue supports, quality
+// values are assumed to be in descending order as browsers send them
+func (c *Catalogue) Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag, _, _ = strings.Cut(strings.ToLower(tag), "-")
+		if c.languages[tag] {
+			return tag
+		}
+	}
+	return c.fallback
+}
@@ -0,0 +1,122 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func catalogue(t *testing.T, file File, fallback string) *Catalogue {
+	t.Helper()
+	c, err := New(file, fallback)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestLookup(t *testing.T) {
+	c := catalogue(t, File{
+		Errors: Texts{
+			"CUSTOM":      {Persian: "سفارشی", "DE": "Benutzerdefiniert"},
+			VisitNotFound: {English: "No such visit", Persian: "ویزیت پیدا نشد"},
+		},
+		Messages: Texts{"CUSTOM_DONE": {Persian: "انجام شد"}},
+	}, "")
+	tests := []struct {
+		name, code, language string
+		want                 string
+		ok                   bool
+	}{
+		{"default text", InvalidRequest, English, "The request is invalid", true},
+		{"default language", InvalidRequest, Persian, "درخواست نامعتبر است", true},
+		{"file overrides default", VisitNotFound, English, "No such visit", true},
+		{"file language is lower cased", "CUSTOM", "de", "Benutzerdefiniert", true},
+		{"missing language falls back", "CUSTOM", English, "سفارشی", true},
+		{"unknown language falls back", InvalidRequest, "fr", "درخواست نامعتبر است", true},
+		{"unknown code", "NO_SUCH_CODE", English, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Error(tt.code, tt.language)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Error(%s, %s) = %q, %v, want %q, %v", tt.code, tt.language, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if got, ok := c.Message("CUSTOM_DONE", English); got != "انجام شد" || !ok {
+		t.Errorf("Message(CUSTOM_DONE, en) = %q, %v, want the persian text", got, ok)
+	}
+	if _, ok := c.Message(InvalidRequest, English); ok {
+		t.Errorf("Message() found an error code, want messages and errors kept apart")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if got := catalogue(t, File{}, "").Fallback(); got != Persian {
+		t.Errorf("Fallback() = %s, want %s when none is given", got, Persian)
+	}
+	c := catalogue(t, File{}, English)
+	if got, _ := c.Error("NO_SUCH_CODE", "fr"); got != "" {
+		t.Errorf("Error() of an unknown code = %q, want empty", got)
+	}
+	if got, _ := c.Error(InvalidRequest, "fr"); got != "The request is invalid" {
+		t.Errorf("Error() falls back to %q, want the english text", got)
+	}
+	if _, err := New(File{Errors: Texts{"CUSTOM": {English: "custom"}}}, Persian); err == nil {
+		t.Errorf("New() of a code without a text in the fallback language error = nil, want an error")
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	c := catalogue(t, File{Errors: Texts{"CUSTOM": {Persian: "سفارشی", "de": "Benutzerdefiniert"}}}, "")
+	tests := []struct {
+		header, want string
+	}{
+		{"", Persian},
+		{"en", English},
+		{"en-US,en;q=0.9", English},
+		{"fr-FR, de;q=0.8, en;q=0.5", "de"},
+		{"FA-IR", Persian},
+		{"fr, ja", Persian},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := c.Language(tt.header); got != tt.want {
+				t.Errorf("Language(%q) = %s, want %s", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalogue.json")
+	data := `{"errors": {"INVALID_REQUEST": {"en": "Bad request"}}, "messages": {"CUSTOM_DONE": {"fa": "انجام شد"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := c.Error(InvalidRequest, English); got != "Bad request" {
+		t.Errorf("Error() = %q, want the text of the file", got)
+	}
+	if got, _ := c.Error(InvalidRequest, Persian); got != "درخواست نامعتبر است" {
+		t.Errorf("Error() = %q, want the default kept for a language missing from the file", got)
+	}
+
+	if _, err = Load(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Errorf("Load() of a missing file error = nil, want an error")
+	}
+	if err = os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(path, ""); err == nil {
+		t.Errorf("Load() of invalid JSON error = nil, want an error")
+	}
+	if c, err = Load("", English); err != nil || c.Fallback() != English {
+		t.Errorf("Load(\"\") = %v, want the defaults", err)
+	}
+}
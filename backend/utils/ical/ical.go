@@ -0,0 +1,124 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Statuses of an event
+const (
+	Confirmed = "CONFIRMED"
+	Tentative = "TENTATIVE"
+	Cancelled = "CANCELLED"
+)
+
+// Calendar is a published calendar, Refresh tells clients how often to fetch it again
+type Calendar struct {
+	ProductID string
+	Name      string
+	TimeZone  string
+	Refresh   time.Duration
+}
+
+// Event is a VEVENT, an all-day event only uses the date of Start and End. Clients match updates by UID
+// and take the one with the higher Sequence
+type Event struct {
+	UID          string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Summary      string
+	Description  string
+	Location     string
+	Status       string
+	Sequence     int64
+	LastModified time.Time
+}
+
+// Escape escapes a TEXT value
+func Escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// writer folds content lines at 75 octets without splitting UTF-8 sequences and ends them with CRLF
+type writer struct {
+	w   *bufio.Writer
+	now string
+}
+
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	// continuation lines start with a space, which counts towards their 75 octets
+	for limit := 75; len(line) > limit; limit = 74 {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	w.w.WriteString(line + "\r\n")
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Write writes the calendar with its events as an iCalendar stream
+func Write(out io.Writer, cal Calendar, events []Event) error {
+	w := &writer{w: bufio.NewWriter(out), now: utc(time.Now())}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", cal.ProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME", Escape(cal.Name))
+	}
+	if cal.TimeZone != "" {
+		w.line("X-WR-TIMEZONE", cal.TimeZone)
+	}
+	if cal.Refresh > 0 {
+		minutes := int(cal.Refresh / time.Minute)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", fmt.Sprintf("PT%dM", minutes))
+		w.line("X-PUBLISHED-TTL", fmt.Sprintf("PT%dM", minutes))
+	}
+	for _, event := range events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", event.UID)
+		w.line("DTSTAMP", w.now)
+		if event.AllDay {
+			end := event.End
+			if !end.After(event.Start) {
+				end = event.Start.AddDate(0, 0, 1)
+			}
+			w.line("DTSTART;VALUE=DATE", event.Start.Format("20060102"))
+			w.line("DTEND;VALUE=DATE", end.Format("20060102"))
+		} else {
+			w.line("DTSTART", utc(event.Start))
+			if event.End.After(event.Start) {
+				w.line("DTEND", utc(event.End))
+			}
+		}
+		w.line("SUMMARY", Escape(event.Summary))
+		if event.Description != "" {
+			w.line("DESCRIPTION", Escape(event.Description))
+		}
+		if event.Location != "" {
+			w.line("LOCATION", Escape(event.Location))
+		}
+		if event.Status != "" {
+			w.line("STATUS", event.Status)
+		}
+		w.line("SEQUENCE", fmt.Sprint(event.Sequence))
+		if !event.LastModified.IsZero() {
+			w.line("LAST-MODIFIED", utc(event.LastModified))
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.w.Flush()
+}
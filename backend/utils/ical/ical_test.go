@@ -0,0 +1,129 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Dental check-up", "Dental check-up"},
+		{"Dr. Doe; room 2, floor 1", `Dr. Doe\; room 2\, floor 1`},
+		{`C:\notes`, `C:\\notes`},
+		{"line one\r\nline two\nline three\r", `line one\nline two\nline three\n`},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.text); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// write returns the lines of the stream of events without the DTSTAMP of the time of writing
+func write(t *testing.T, cal Calendar, events []Event) []string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Write(&out, cal, events); err != nil {
+		t.Fatal(err)
+	}
+	stream := out.String()
+	if !strings.HasSuffix(stream, "\r\n") || strings.Contains(strings.ReplaceAll(stream, "\r\n", ""), "\n") {
+		t.Errorf("Write() lines do not all end with CRLF: %q", stream)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(stream, "\r\n"), "\r\n") {
+		if !strings.HasPrefix(line, "DTSTAMP:") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestWrite(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	cal := Calendar{ProductID: "-//Healthcare//Visits//EN", Name: "Dr. Doe, visits", TimeZone: "Asia/Tehran", Refresh: time.Hour}
+	events := []Event{
+		{
+			UID: "v-1@healthcare", Start: time.Date(2024, 5, 1, 9, 0, 0, 0, tehran), End: time.Date(2024, 5, 1, 9, 30, 0, 0, tehran),
+			Summary: "General visit", Description: "Headache; fever", Location: "Room 2", Status: Confirmed, Sequence: 3,
+			LastModified: time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC),
+		},
+		{UID: "h-1@healthcare", Start: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), AllDay: true, Summary: "Holiday", Status: Cancelled},
+		{UID: "v-2@healthcare", Start: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC), Summary: "Walk-in"},
+	}
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Healthcare//Visits//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		`X-WR-CALNAME:Dr. Doe\, visits`,
+		"X-WR-TIMEZONE:Asia/Tehran",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT60M",
+		"X-PUBLISHED-TTL:PT60M",
+		"BEGIN:VEVENT",
+		"UID:v-1@healthcare",
+		"DTSTART:20240501T053000Z",
+		"DTEND:20240501T060000Z",
+		"SUMMARY:General visit",
+		`DESCRIPTION:Headache\; fever`,
+		"LOCATION:Room 2",
+		"STATUS:CONFIRMED",
+		"SEQUENCE:3",
+		"LAST-MODIFIED:20240430T120000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:h-1@healthcare",
+		"DTSTART;VALUE=DATE:20240502",
+		"DTEND;VALUE=DATE:20240503",
+		"SUMMARY:Holiday",
+		"STATUS:CANCELLED",
+		"SEQUENCE:0",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:v-2@healthcare",
+		"DTSTART:20240503T080000Z",
+		"SUMMARY:Walk-in",
+		"SEQUENCE:0",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	got := write(t, cal, events)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Write() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFolding(t *testing.T) {
+	summary := strings.Repeat("ویزیت دندانپزشکی ", 12)
+	lines := write(t, Calendar{ProductID: "-//Healthcare//EN"}, []Event{{UID: "1", Start: time.Now(), Summary: summary}})
+	var unfolded []string
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a character: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	found := false
+	for _, line := range unfolded {
+		if line == "SUMMARY:"+summary {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("unfolded lines %q do not hold the summary", unfolded)
+	}
+}
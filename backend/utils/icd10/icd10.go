@@ -0,0 +1,105 @@
+package icd10
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Code is a single entry of the ICD-10 code table
+type Code struct {
+	Code          string
+	DescriptionEn string
+	DescriptionFa string
+}
+
+var codePattern = regexp.MustCompile(`^[A-Z][0-9][0-9A-Z](\.[0-9A-Z]{1,4})?$`)
+
+// NormalizeCode upper-cases a code and inserts the dot after the category, "j069" becomes "J06.9"
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, ".", "")
+	if len(code) > 3 {
+		code = code[:3] + "." + code[3:]
+	}
+	return code
+}
+
+// ValidCode reports whether the normalized code has the ICD-10 shape
+func ValidCode(code string) bool {
+	return codePattern.MatchString(code)
+}
+
+// persianReplacer unifies Arabic and Persian forms of letters and digits so searches match either keyboard layout
+var persianReplacer = strings.NewReplacer(
+	"ي", "ی", "ى", "ی", "ك", "ک", "ة", "ه",
+	"۰", "0", "۱", "1", "۲", "2", "۳", "3", "۴", "4",
+	"۵", "5", "۶", "6", "۷", "7", "۸", "8", "۹", "9",
+	"‌", " ",
+)
+
+// NormalizeText prepares free text for storing or searching descriptions
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(persianReplacer.Replace(text)), " ")
+}
+
+// SearchKey returns the code prefix to search for, dots are removed so "J06" and "J06." behave alike
+func SearchKey(query string) string {
+	return strings.ReplaceAll(strings.ToUpper(NormalizeText(query)), ".", "")
+}
+
+// Load reads a code table from a .csv or .tsv file with the columns code, description_en, description_fa,
+// a first row starting with "code" is treated as a header
+func Load(path string) ([]Code, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+	case ".tsv", ".txt":
+		reader.Comma = '\t'
+	default:
+		return nil, fmt.Errorf("unsupported icd-10 file format: %s", path)
+	}
+
+	var codes []Code
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return codes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read icd-10 file %s: %w", path, err)
+		}
+		line++
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "code") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: code and english description are required", line)
+		}
+		code := Code{
+			Code:          NormalizeCode(record[0]),
+			DescriptionEn: NormalizeText(record[1]),
+		}
+		if len(record) > 2 {
+			code.DescriptionFa = NormalizeText(record[2])
+		}
+		if !ValidCode(code.Code) {
+			return nil, fmt.Errorf("line %d: invalid icd-10 code %q", line, record[0])
+		}
+		codes = append(codes, code)
+	}
+}
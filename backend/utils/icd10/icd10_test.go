@@ -0,0 +1,113 @@
+package icd10
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		code  string
+		want  string
+		valid bool
+	}{
+		{"j069", "J06.9", true},
+		{" J06.9 ", "J06.9", true},
+		{"a09", "A09", true},
+		{"S72.0012", "S72.0012", true},
+		{"c4a.1", "C4A.1", true},
+		{"J06.", "J06", true},
+		{"J0", "J0", false},
+		{"106.9", "106.9", false},
+		{"J06.12345", "J06.12345", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got := NormalizeCode(tt.code)
+			if got != tt.want {
+				t.Errorf("NormalizeCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+			if valid := ValidCode(got); valid != tt.valid {
+				t.Errorf("ValidCode(%q) = %v, want %v", got, valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"  Acute   upper\trespiratory infection ", "Acute upper respiratory infection"},
+		{"ديابت نوع ۲", "دیابت نوع 2"},
+		{"كولیت", "کولیت"},
+		{"نیم‌فاصله", "نیم فاصله"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.text); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"j06.", "J06"},
+		{" J06.9", "J069"},
+		{"e۱۱", "E11"},
+	}
+	for _, tt := range tests {
+		if got := SearchKey(tt.query); got != tt.want {
+			t.Errorf("SearchKey(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	codes, err := Load(filepath.Join("..", "..", "config", "icd10-sample.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) == 0 || codes[0].Code != "A09" || codes[0].DescriptionFa == "" {
+		t.Errorf("Load() of the sample = %+v, want its codes starting with A09", codes)
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	codes, err = Load(write("codes.tsv", "j069\tAcute upper respiratory infection\tعفونت حاد\nk021\tCaries of dentine\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Code{
+		{Code: "J06.9", DescriptionEn: "Acute upper respiratory infection", DescriptionFa: "عفونت حاد"},
+		{Code: "K02.1", DescriptionEn: "Caries of dentine"},
+	}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("Load() = %+v, want %+v", codes, want)
+	}
+
+	for name, content := range map[string]string{
+		"invalid.csv": "code,description_en\nJ06.9X12345,Too long\n",
+		"short.csv":   "J06.9\n",
+		"codes.json":  "[]",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(write(name, content)); err == nil {
+				t.Errorf("Load(%s) succeeded", name)
+			}
+		})
+	}
+}
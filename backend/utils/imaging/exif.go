@@ -0,0 +1,224 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// JPEG markers
+const (
+	markerSOI   = 0xD8
+	markerSOS   = 0xDA
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP13 = 0xED
+	markerCOM   = 0xFE
+)
+
+const tagOrientation = 0x0112
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// Orientation returns the EXIF orientation (1-8) of a JPEG, 1 when absent or unreadable
+func Orientation(jpegData []byte) int {
+	segments, _ := jpegSegments(jpegData)
+	for _, segment := range segments {
+		if segment.marker != markerAPP1 || !bytes.HasPrefix(segment.payload, exifHeader) {
+			continue
+		}
+		if o := exifOrientation(segment.payload[len(exifHeader):]); o >= 1 && o <= 8 {
+			return o
+		}
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == tagOrientation {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+type segment struct {
+	marker  byte
+	payload []byte
+	raw     []byte
+}
+
+// jpegSegments splits the header of a JPEG and returns the offset of the start of scan,
+// the offset is 0 when the header is malformed
+func jpegSegments(data []byte) ([]segment, int) {
+	var segments []segment
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, 0
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return segments, 0
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// fill byte
+			i++
+			continue
+		}
+		if marker == markerSOS {
+			return segments, i
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return segments, 0
+		}
+		segments = append(segments, segment{marker: marker, payload: data[i+4 : i+2+length], raw: data[i : i+2+length]})
+		i += 2 + length
+	}
+	return segments, 0
+}
+
+// StripJPEG removes EXIF, XMP, IPTC and comment segments without re-encoding the image,
+// an orientation other than 1 is kept in a minimal EXIF segment so the original still displays upright
+func StripJPEG(data []byte) ([]byte, error) {
+	segments, scan := jpegSegments(data)
+	if scan == 0 {
+		return nil, errors.New("invalid jpeg")
+	}
+	orientation := Orientation(data)
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write([]byte{0xFF, markerSOI})
+	for i, s := range segments {
+		// APP0 (JFIF) must stay the first segment
+		if orientation != 1 && !(i == 0 && s.marker == markerAPP0) {
+			out.Write(orientationSegment(orientation))
+			orientation = 1
+		}
+		if s.marker == markerAPP1 || s.marker == markerAPP13 || s.marker == markerCOM {
+			continue
+		}
+		out.Write(s.raw)
+	}
+	if orientation != 1 {
+		out.Write(orientationSegment(orientation))
+	}
+	out.Write(data[scan:])
+	return out.Bytes(), nil
+}
+
+// orientationSegment builds an APP1 segment with a single IFD holding the orientation tag
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	tiff = binary.BigEndian.AppendUint16(tiff, tagOrientation)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding and no next IFD
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, markerAPP1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// strippedChunks carry metadata, location or free text
+var strippedChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// StripPNG removes metadata chunks, chunks are independently checksummed so the rest stays valid
+func StripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("invalid png")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, errors.New("truncated png chunk")
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errors.New("truncated png chunk")
+		}
+		if !strippedChunks[string(data[i+4:i+8])] {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// webpHeaderSize is the length of the RIFF header of a WebP file
+const webpHeaderSize = 12
+
+// VP8X flags of the metadata chunks
+const (
+	vp8xFlagXMP  = 0x04
+	vp8xFlagEXIF = 0x08
+)
+
+// StripWebP removes the EXIF and XMP chunks of an extended WebP and clears their flags in the VP8X
+// chunk, the image chunks are copied unchanged
+func StripWebP(data []byte) ([]byte, error) {
+	if len(data) < webpHeaderSize || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("invalid webp")
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:8]))
+	if size < 4 || 8+size > len(data) {
+		return nil, errors.New("truncated webp")
+	}
+	data = data[:8+size]
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:webpHeaderSize])
+	for i := webpHeaderSize; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errors.New("truncated webp chunk")
+		}
+		fourCC := string(data[i : i+4])
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		// chunks are padded to an even size
+		end := i + 8 + length + length%2
+		if length < 0 || end > len(data) {
+			return nil, errors.New("truncated webp chunk")
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[i:end]...)
+			if length > 0 {
+				chunk[8] &^= vp8xFlagEXIF | vp8xFlagXMP
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:8], uint32(len(stripped)-8))
+	return stripped, nil
+}
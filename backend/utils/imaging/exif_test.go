@@ -0,0 +1,215 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testJPEG encodes a small JPEG and inserts extra segments after its SOI marker
+func testJPEG(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, image.NewGray(image.Rect(0, 0, 4, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := out.Bytes()
+	return append(append(data[:2:2], bytes.Join(segments, nil)...), data[2:]...)
+}
+
+// jpegSegment builds a segment with marker and payload
+func jpegSegment(marker byte, payload string) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngChunk builds a chunk of kind with data and its checksum
+func pngChunk(kind, data string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind+data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(kind+data)))
+}
+
+// webpChunk builds a RIFF chunk padded to an even size
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// webpFile wraps chunks in the RIFF header of a WebP
+func webpFile(chunks ...[]byte) []byte {
+	body := append([]byte("WEBP"), bytes.Join(chunks, nil)...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", testJPEG(t), 1},
+		{"rotated", testJPEG(t, orientationSegment(6)), 6},
+		{"mirrored", testJPEG(t, jpegSegment(markerAPP0, "JFIF\x00"), orientationSegment(2)), 2},
+		{"out of range", testJPEG(t, orientationSegment(9)), 1},
+		{"not a jpeg", []byte("GIF89a"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Orientation(tt.data); got != tt.want {
+				t.Errorf("Orientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	gps := jpegSegment(markerAPP1, "Exif\x00\x00GPS 35.6892N 51.3890E")
+	xmp := jpegSegment(markerAPP1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")
+	iptc := jpegSegment(markerAPP13, "Photoshop 3.0\x00caption")
+	comment := jpegSegment(markerCOM, "patient 1234")
+	tests := []struct {
+		name            string
+		data            []byte
+		wantOrientation int
+	}{
+		{"metadata", testJPEG(t, gps, xmp, iptc, comment), 1},
+		{"rotated with metadata", testJPEG(t, orientationSegment(8), gps, comment), 8},
+		{"jfif first", testJPEG(t, jpegSegment(markerAPP0, "JFIF\x00"), orientationSegment(3), xmp), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StripJPEG(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, leaked := range []string{"GPS", "xmpmeta", "caption", "patient"} {
+				if bytes.Contains(got, []byte(leaked)) {
+					t.Errorf("StripJPEG() kept %q", leaked)
+				}
+			}
+			if o := Orientation(got); o != tt.wantOrientation {
+				t.Errorf("Orientation() after StripJPEG() = %d, want %d", o, tt.wantOrientation)
+			}
+			if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+				t.Errorf("stripped jpeg does not decode: %v", err)
+			}
+		})
+	}
+	if _, err := StripJPEG([]byte{0xFF, markerSOI, 0xFF, markerAPP1, 0xFF}); err == nil {
+		t.Error("StripJPEG() of a truncated jpeg succeeded")
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	var out bytes.Buffer
+	if err := png.Encode(&out, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	encoded := out.Bytes()
+	// metadata chunks go right after IHDR, which is 25 bytes long
+	ihdr := len(pngSignature) + 25
+	data := append(append(encoded[:ihdr:ihdr],
+		bytes.Join([][]byte{pngChunk("tEXt", "Author\x00Dr. Example"), pngChunk("eXIf", "MM\x00*GPS"), pngChunk("tIME", "\x07\xe8\x05\x01\x0c\x00\x00")}, nil)...),
+		encoded[ihdr:]...)
+
+	got, err := StripPNG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, encoded) {
+		t.Errorf("StripPNG() = %d bytes, want the %d bytes of the encoded png", len(got), len(encoded))
+	}
+	if _, err := StripPNG(data[:len(data)-3]); err == nil {
+		t.Error("StripPNG() of a truncated png succeeded")
+	}
+	if _, err := StripPNG([]byte("not a png")); err == nil {
+		t.Error("StripPNG() of another format succeeded")
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X with the ICC, EXIF and XMP flags and a 1x1 canvas
+	vp8x := []byte{0x20 | vp8xFlagEXIF | vp8xFlagXMP, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	lossless := webpChunk("VP8L", []byte{0x2f, 0, 0, 0, 0x10, 0x07, 0x10, 0x11, 0x11, 0x88, 0x88, 0xfe, 0x07, 0})
+	icc := webpChunk("ICCP", []byte("profile"))
+	exif := webpChunk("EXIF", []byte("MM\x00*GPS 35.6892N"))
+	xmp := webpChunk("XMP ", []byte("<x:xmpmeta/>"))
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{
+			"extended",
+			webpFile(webpChunk("VP8X", vp8x), icc, lossless, exif, xmp),
+			webpFile(webpChunk("VP8X", []byte{0x20, 0, 0, 0, 0, 0, 0, 0, 0, 0}), icc, lossless),
+		},
+		{"simple", webpFile(lossless), webpFile(lossless)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StripWebP(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("StripWebP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		data []byte
+	}{
+		{"not riff", []byte("RIFX\x04\x00\x00\x00WEBP")},
+		{"not webp", append([]byte("RIFF\x04\x00\x00\x00"), "WAVE"...)},
+		{"size past the end", webpFile(lossless)[:20]},
+		{"chunk past the end", append([]byte("RIFF\x10\x00\x00\x00WEBP"), "VP8L\xff\x00\x00\x00"...)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := StripWebP(tt.data); err == nil {
+				t.Error("StripWebP() succeeded")
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	webp := webpFile(webpChunk("VP8X", []byte{vp8xFlagEXIF, 0, 0, 0, 0, 0, 0, 0, 0, 0}), webpChunk("EXIF", []byte("GPS")))
+	tests := []struct {
+		mimeType string
+		data     []byte
+	}{
+		{"image/jpeg", testJPEG(t, jpegSegment(markerCOM, "GPS"))},
+		{"image/png", append(append([]byte{}, pngSignature...), pngChunk("tEXt", "GPS")...)},
+		{"image/webp", webp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			got, err := Strip(tt.mimeType, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(got, []byte("GPS")) {
+				t.Errorf("Strip(%s) kept the metadata", tt.mimeType)
+			}
+		})
+	}
+	dicom := []byte("DICM GPS")
+	if got, err := Strip("application/dicom", dicom); err != nil || !bytes.Equal(got, dicom) {
+		t.Errorf("Strip() of another type = %q, %v", got, err)
+	}
+}
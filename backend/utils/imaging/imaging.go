@@ -0,0 +1,156 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels rejects images whose decoded size would exhaust memory
+const MaxPixels = 100_000_000
+
+// Rendition is a downscaled JPEG copy of an image
+type Rendition struct {
+	Name    string
+	MaxSide int
+	Quality int
+}
+
+// Default renditions, thumbnails for listings and web-sized previews for viewing
+var (
+	Thumbnail = Rendition{Name: "thumbnail", MaxSide: 256, Quality: 80}
+	Preview   = Rendition{Name: "preview", MaxSide: 1280, Quality: 85}
+)
+
+// Supported reports whether renditions can be generated for the MIME type
+func Supported(mimeType string) bool {
+	return mimeType == "image/jpeg" || mimeType == "image/png"
+}
+
+// Strip removes privacy sensitive metadata of JPEG, PNG and WebP images, other types are returned
+// unchanged
+func Strip(mimeType string, data []byte) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return StripJPEG(data)
+	case "image/png":
+		return StripPNG(data)
+	case "image/webp":
+		return StripWebP(data)
+	}
+	return data, nil
+}
+
+// Decode decodes a JPEG or PNG and applies its EXIF orientation
+func Decode(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	}
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err == nil {
+			img = Orient(img, Orientation(data))
+		}
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	default:
+		err = fmt.Errorf("unsupported image format %s", format)
+	}
+	return img, err
+}
+
+// Orient transforms an image stored with the EXIF orientation into its upright form
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// orientations 5 to 8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the main diagonal
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise to display
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the anti-diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise to display
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Resize scales the image down so its longer side is at most maxSide, averaging the source pixels
+// covered by each target pixel, smaller images are returned unchanged
+func Resize(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+// Render produces the JPEG rendition of a decoded image, transparency is flattened on white
+func Render(img image.Image, rendition Rendition) ([]byte, error) {
+	scaled := Resize(img, rendition.MaxSide)
+	flat := image.NewRGBA(scaled.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), scaled, scaled.Bounds().Min, draw.Over)
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, flat, &jpeg.Options{Quality: rendition.Quality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
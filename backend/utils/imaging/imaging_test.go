@@ -0,0 +1,71 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	// a 2x1 image with a red left and a blue right pixel
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+	tests := []struct {
+		orientation int
+		size        image.Point
+		redAt       image.Point
+	}{
+		{1, image.Pt(2, 1), image.Pt(0, 0)},
+		{2, image.Pt(2, 1), image.Pt(1, 0)},
+		{3, image.Pt(2, 1), image.Pt(1, 0)},
+		{6, image.Pt(1, 2), image.Pt(0, 0)},
+		{8, image.Pt(1, 2), image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		got := Orient(src, tt.orientation)
+		if size := got.Bounds().Size(); size != tt.size {
+			t.Errorf("Orient(%d) size = %v, want %v", tt.orientation, size, tt.size)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(got.At(tt.redAt.X, tt.redAt.Y)); c != red {
+			t.Errorf("Orient(%d) at %v = %v, want red", tt.orientation, tt.redAt, c)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    image.Point
+		maxSide int
+		want    image.Point
+	}{
+		{"smaller", image.Pt(100, 50), 256, image.Pt(100, 50)},
+		{"landscape", image.Pt(1000, 500), 256, image.Pt(256, 128)},
+		{"portrait", image.Pt(300, 1200), 256, image.Pt(64, 256)},
+		{"thin", image.Pt(4000, 2), 256, image.Pt(256, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resize(image.NewGray(image.Rectangle{Max: tt.size}), tt.maxSide).Bounds().Size()
+			if got != tt.want {
+				t.Errorf("Resize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	img, err := Decode(testJPEG(t, orientationSegment(6)))
+	if err != nil {
+		t.Fatalf("Decode() of a jpeg error = %v", err)
+	}
+	if img.Bounds().Size() != image.Pt(2, 4) {
+		t.Errorf("Decode() of a rotated jpeg size = %v, want 2x4", img.Bounds().Size())
+	}
+	if _, err := Decode([]byte("not an image")); err == nil {
+		t.Error("Decode() of another format succeeded")
+	}
+}
@@ -0,0 +1,153 @@
+package jalali
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendars dates of requests and responses are written in
+const (
+	Gregorian = "gregorian"
+	Jalali    = "jalali"
+)
+
+// Header and QueryParam select the calendar of a request, the query parameter wins over the header
+const (
+	Header     = "X-Calendar"
+	QueryParam = "calendar"
+)
+
+// aliases are the other accepted names of the calendars
+var aliases = map[string]string{
+	"":        Gregorian,
+	Gregorian: Gregorian,
+	"miladi":  Gregorian,
+	Jalali:    Jalali,
+	"persian": Jalali,
+	"shamsi":  Jalali,
+	"solar":   Jalali,
+}
+
+// ParseCalendar returns the calendar named name, an empty name is the gregorian calendar
+func ParseCalendar(name string) (string, error) {
+	if calendar, ok := aliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return calendar, nil
+	}
+	return "", fmt.Errorf("unknown calendar %q, use %s or %s", name, Gregorian, Jalali)
+}
+
+// Select returns the calendar named by the query parameter of a request or else by its header
+func Select(query, header string) (string, error) {
+	if query == "" {
+		query = header
+	}
+	return ParseCalendar(query)
+}
+
+// digits maps Persian and Arabic-Indic digits to ASCII ones
+var digits = strings.NewReplacer(
+	"۰", "0", "۱", "1", "۲", "2", "۳", "3", "۴", "4", "۵", "5", "۶", "6", "۷", "7", "۸", "8", "۹", "9",
+	"٠", "0", "١", "1", "٢", "2", "٣", "3", "٤", "4", "٥", "5", "٦", "6", "٧", "7", "٨", "8", "٩", "9",
+	"/", "-",
+)
+
+// ParseDate reads a day written as YYYY-MM-DD in calendar and returns its start in loc, Jalali days
+// may also use / and Persian digits such as ۱۴۰۳/۰۱/۱۵
+func ParseDate(calendar, value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if calendar != Jalali {
+		return time.ParseInLocation(time.DateOnly, value, loc)
+	}
+	parts := strings.Split(digits.Replace(strings.TrimSpace(value)), "-")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("%q is not a Jalali date in YYYY-MM-DD format", value)
+	}
+	var fields [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not a Jalali date in YYYY-MM-DD format", value)
+		}
+		fields[i] = n
+	}
+	d := Date{Year: fields[0], Month: fields[1], Day: fields[2]}
+	if !d.Valid() {
+		return time.Time{}, fmt.Errorf("%q is not a day of the Jalali calendar", value)
+	}
+	return d.Time(loc), nil
+}
+
+// FormatDate writes the day of t as YYYY-MM-DD in calendar
+func FormatDate(calendar string, t time.Time) string {
+	if calendar == Jalali {
+		return FromTime(t).String()
+	}
+	return t.Format(time.DateOnly)
+}
+
+// Month is a month of a calendar
+type Month struct {
+	Year  int
+	Month int
+}
+
+// MonthOf returns the month of t in calendar
+func MonthOf(calendar string, t time.Time) Month {
+	if calendar == Jalali {
+		d := FromTime(t)
+		return Month{Year: d.Year, Month: d.Month}
+	}
+	return Month{Year: t.Year(), Month: int(t.Month())}
+}
+
+// Next returns the month after m
+func (m Month) Next() Month {
+	if m.Month == 12 {
+		return Month{Year: m.Year + 1, Month: 1}
+	}
+	return Month{Year: m.Year, Month: m.Month + 1}
+}
+
+// After reports whether m is a later month than o
+func (m Month) After(o Month) bool {
+	return m.Year > o.Year || (m.Year == o.Year && m.Month > o.Month)
+}
+
+// String formats m as YYYY-MM
+func (m Month) String() string {
+	return fmt.Sprintf("%04d-%02d", m.Year, m.Month)
+}
+
+var monthNames = map[string]map[string][12]string{
+	Jalali: {
+		"fa": {"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور", "مهر", "آبان", "آذر", "دی", "بهمن", "اسفند"},
+		"en": {"Farvardin", "Ordibehesht", "Khordad", "Tir", "Mordad", "Shahrivar", "Mehr", "Aban", "Azar", "Dey", "Bahman", "Esfand"},
+	},
+	Gregorian: {
+		"fa": {"ژانویه", "فوریه", "مارس", "آوریل", "مه", "ژوئن", "ژوئیه", "اوت", "سپتامبر", "اکتبر", "نوامبر", "دسامبر"},
+		"en": {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	},
+}
+
+// MonthName returns the name of month in calendar in language, fa or en, other languages fall back
+// to Persian for the Jalali calendar and to English for the gregorian one
+func MonthName(calendar string, month int, language string) string {
+	if month < 1 || month > 12 {
+		return ""
+	}
+	if calendar != Jalali {
+		calendar = Gregorian
+	}
+	names, ok := monthNames[calendar][language]
+	if !ok {
+		names = monthNames[calendar]["en"]
+		if calendar == Jalali {
+			names = monthNames[calendar]["fa"]
+		}
+	}
+	return names[month-1]
+}
@@ -0,0 +1,107 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		query, header string
+		want          string
+		wantErr       bool
+	}{
+		{"", "", Gregorian, false},
+		{"", "Shamsi", Jalali, false},
+		{" persian ", "", Jalali, false},
+		{"gregorian", "jalali", Gregorian, false},
+		{"hijri", "jalali", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Select(tt.query, tt.header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Select(%q, %q) = %q, %v, want %q", tt.query, tt.header, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	tests := []struct {
+		calendar string
+		value    string
+		want     time.Time
+		wantErr  bool
+	}{
+		{Jalali, "1403-01-01", time.Date(2024, 3, 20, 0, 0, 0, 0, tehran), false},
+		{Jalali, "۱۴۰۳/۰۷/۲۸", time.Date(2024, 10, 19, 0, 0, 0, 0, tehran), false},
+		{Jalali, "١٤٠٣-١٢-٣٠", time.Date(2025, 3, 20, 0, 0, 0, 0, tehran), false},
+		{Jalali, "1402-12-30", time.Time{}, true},
+		{Jalali, "1403-13-01", time.Time{}, true},
+		{Jalali, "1403-01", time.Time{}, true},
+		{Jalali, "1403-x-01", time.Time{}, true},
+		{Gregorian, "2024-03-20", time.Date(2024, 3, 20, 0, 0, 0, 0, tehran), false},
+		{Gregorian, "1403-01-01", time.Date(1403, 1, 1, 0, 0, 0, 0, tehran), false},
+		{Gregorian, "2024/03/20", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.calendar+" "+tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.calendar, tt.value, tehran)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	day := time.Date(2024, 10, 19, 23, 0, 0, 0, time.UTC)
+	if got := FormatDate(Jalali, day); got != "1403-07-28" {
+		t.Errorf("FormatDate(jalali) = %s, want 1403-07-28", got)
+	}
+	if got := FormatDate(Gregorian, day); got != "2024-10-19" {
+		t.Errorf("FormatDate(gregorian) = %s, want 2024-10-19", got)
+	}
+}
+
+func TestMonth(t *testing.T) {
+	day := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	if got := MonthOf(Jalali, day); got != (Month{1403, 12}) {
+		t.Errorf("MonthOf(jalali) = %s, want 1403-12", got)
+	}
+	if got := MonthOf(Gregorian, day); got != (Month{2025, 3}) {
+		t.Errorf("MonthOf(gregorian) = %s, want 2025-03", got)
+	}
+	if got := (Month{1403, 12}).Next(); got != (Month{1404, 1}) {
+		t.Errorf("Next() = %s, want 1404-01", got)
+	}
+	if !(Month{1404, 1}).After(Month{1403, 12}) || (Month{1403, 12}).After(Month{1403, 12}) {
+		t.Error("After() does not order months")
+	}
+}
+
+func TestMonthName(t *testing.T) {
+	tests := []struct {
+		calendar string
+		month    int
+		language string
+		want     string
+	}{
+		{Jalali, 1, "fa", "فروردین"},
+		{Jalali, 12, "en", "Esfand"},
+		{Jalali, 1, "de", "فروردین"},
+		{Gregorian, 5, "en", "May"},
+		{Gregorian, 5, "de", "May"},
+		{"", 3, "fa", "مارس"},
+		{Jalali, 13, "fa", ""},
+		{Jalali, 0, "fa", ""},
+	}
+	for _, tt := range tests {
+		if got := MonthName(tt.calendar, tt.month, tt.language); got != tt.want {
+			t.Errorf("MonthName(%q, %d, %q) = %q, want %q", tt.calendar, tt.month, tt.language, got, tt.want)
+		}
+	}
+}
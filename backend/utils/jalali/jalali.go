@@ -0,0 +1,119 @@
+package jalali
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date is a day of the Jalali (Solar Hijri) calendar
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// breaks are the Jalali years starting a new run of the 33 year leap cycle
+var breaks = []int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210, 1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+// Supported range of Jalali years
+const (
+	MinYear = -60
+	MaxYear = 3177
+)
+
+// unixJDN is the julian day number of 1970-01-01
+const unixJDN = 2440588
+
+// jdn returns the julian day number of a gregorian day
+func jdn(year int, month time.Month, day int) int {
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()/86400) + unixJDN
+}
+
+// cycle returns the position of year in its leap cycle, 0 for leap years, the gregorian year it starts
+// in and the day of March it starts on
+func cycle(year int) (leap, gy, march int) {
+	gy = year + 621
+	leapJ, jp, jump := -14, breaks[0], 0
+	for _, jm := range breaks[1:] {
+		jump = jm - jp
+		if year < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := year - jp
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return leap, gy, march
+}
+
+// IsLeap reports whether year has 366 days
+func IsLeap(year int) bool {
+	leap, _, _ := cycle(year)
+	return leap == 0
+}
+
+// DaysIn returns the number of days of month in year
+func DaysIn(year, month int) int {
+	switch {
+	case month <= 6:
+		return 31
+	case month <= 11:
+		return 30
+	case IsLeap(year):
+		return 30
+	}
+	return 29
+}
+
+// Valid reports whether d is a day of the calendar
+func (d Date) Valid() bool {
+	return d.Year >= MinYear && d.Year <= MaxYear && d.Month >= 1 && d.Month <= 12 && d.Day >= 1 && d.Day <= DaysIn(d.Year, d.Month)
+}
+
+// FromTime returns the Jalali day of t in its location
+func FromTime(t time.Time) Date {
+	day := jdn(t.Year(), t.Month(), t.Day())
+	year := t.Year() - 621
+	leap, gy, march := cycle(year)
+	k := day - jdn(gy, time.March, march)
+	if k >= 0 {
+		if k <= 185 {
+			return Date{Year: year, Month: 1 + k/31, Day: k%31 + 1}
+		}
+		k -= 186
+	} else {
+		year--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+	return Date{Year: year, Month: 7 + k/30, Day: k%30 + 1}
+}
+
+// Time returns the start of d in loc
+func (d Date) Time(loc *time.Location) time.Time {
+	_, gy, march := cycle(d.Year)
+	days := (d.Month-1)*31 - d.Month/7*(d.Month-7) + d.Day - 1
+	return time.Date(gy, time.March, march+days, 0, 0, 0, 0, loc)
+}
+
+// String formats d as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
@@ -0,0 +1,104 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromTime(t *testing.T) {
+	tests := []struct {
+		gregorian string
+		want      Date
+	}{
+		{"2024-03-20", Date{1403, 1, 1}},
+		{"2024-03-19", Date{1402, 12, 29}},
+		{"2025-03-20", Date{1403, 12, 30}},
+		{"2025-03-21", Date{1404, 1, 1}},
+		{"2023-03-21", Date{1402, 1, 1}},
+		{"2024-09-21", Date{1403, 6, 31}},
+		{"2024-09-22", Date{1403, 7, 1}},
+		{"2024-10-19", Date{1403, 7, 28}},
+		{"2000-01-01", Date{1378, 10, 11}},
+		{"1979-02-11", Date{1357, 11, 22}},
+		{"1970-01-01", Date{1348, 10, 11}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gregorian, func(t *testing.T) {
+			day, err := time.Parse(time.DateOnly, tt.gregorian)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FromTime(day); got != tt.want {
+				t.Errorf("FromTime(%s) = %s, want %s", tt.gregorian, got, tt.want)
+			}
+			if got := tt.want.Time(time.UTC); !got.Equal(day) {
+				t.Errorf("%s.Time() = %s, want %s", tt.want, got.Format(time.DateOnly), tt.gregorian)
+			}
+		})
+	}
+}
+
+// TestRoundTrip converts every day of two centuries there and back
+func TestRoundTrip(t *testing.T) {
+	previous := FromTime(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC))
+	for day := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC); day.Year() < 2100; day = day.AddDate(0, 0, 1) {
+		d := FromTime(day)
+		if !d.Valid() {
+			t.Fatalf("FromTime(%s) = %s, not a valid day", day.Format(time.DateOnly), d)
+		}
+		if back := d.Time(time.UTC); !back.Equal(day) {
+			t.Fatalf("FromTime(%s) = %s, which is %s", day.Format(time.DateOnly), d, back.Format(time.DateOnly))
+		}
+		next := Date{previous.Year, previous.Month, previous.Day + 1}
+		if previous.Day == DaysIn(previous.Year, previous.Month) {
+			next = Date{previous.Year, previous.Month + 1, 1}
+			if previous.Month == 12 {
+				next = Date{previous.Year + 1, 1, 1}
+			}
+		}
+		if d != next {
+			t.Fatalf("FromTime(%s) = %s, want %s after %s", day.Format(time.DateOnly), d, next, previous)
+		}
+		previous = d
+	}
+}
+
+func TestIsLeap(t *testing.T) {
+	tests := []struct {
+		year int
+		want bool
+	}{
+		{1375, true},
+		{1399, true},
+		{1400, false},
+		{1402, false},
+		{1403, true},
+		{1404, false},
+		{1408, true},
+	}
+	for _, tt := range tests {
+		if got := IsLeap(tt.year); got != tt.want {
+			t.Errorf("IsLeap(%d) = %v, want %v", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		date Date
+		want bool
+	}{
+		{Date{1403, 6, 31}, true},
+		{Date{1403, 7, 31}, false},
+		{Date{1403, 12, 30}, true},
+		{Date{1402, 12, 30}, false},
+		{Date{1403, 13, 1}, false},
+		{Date{1403, 1, 0}, false},
+		{Date{MaxYear + 1, 1, 1}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.date.Valid(); got != tt.want {
+			t.Errorf("%s.Valid() = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
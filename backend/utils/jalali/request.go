@@ -0,0 +1,35 @@
+package jalali
+
+import (
+	"net/http"
+	"time"
+
+	"healthcare/utils/i18n"
+
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/webFramework"
+)
+
+// FromRequest returns the calendar named by the calendar parameter or field of a request, or else by
+// its X-Calendar header. The calendar applies to days written as YYYY-MM-DD, instants such as the
+// start of a booking or the time vitals were recorded are RFC 3339 in the gregorian calendar
+func FromRequest(w webFramework.WebFramework, name string) (string, error) {
+	calendar, err := Select(name, w.Parser.GetHeaderValue(Header))
+	if err != nil {
+		return "", libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidRequest, "%s", err.Error())
+	}
+	return calendar, nil
+}
+
+// ParseDay reads the day in field of a request written in calendar and returns its start in loc, an
+// empty day is the zero time
+func ParseDay(calendar, value, field string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := ParseDate(calendar, value, loc)
+	if err != nil {
+		return time.Time{}, libError.NewWithDescription(http.StatusBadRequest, i18n.InvalidDate, "invalid %s format, use YYYY-MM-DD in the %s calendar", field, calendar)
+	}
+	return date, nil
+}
@@ -0,0 +1,33 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDay(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	tests := []struct {
+		calendar string
+		value    string
+		want     time.Time
+		wantErr  bool
+	}{
+		{Jalali, "", time.Time{}, false},
+		{Jalali, "1403-02-31", time.Date(2024, 5, 20, 0, 0, 0, 0, tehran), false},
+		{Gregorian, "2024-05-20", time.Date(2024, 5, 20, 0, 0, 0, 0, tehran), false},
+		{Gregorian, "1403-02-31", time.Time{}, true},
+		{Jalali, "20-05-2024", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.calendar+" "+tt.value, func(t *testing.T) {
+			got, err := ParseDay(tt.calendar, tt.value, "from", tehran)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDay() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
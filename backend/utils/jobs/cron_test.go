@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	after := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tehran := time.FixedZone("Tehran", 12600)
+	tests := []struct {
+		spec string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"*/15 * * * *", time.UTC, time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.UTC, time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC)},
+		{"30 12 * * *", time.UTC, time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.UTC, time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.UTC, time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.UTC, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * *", time.UTC, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{"0,30 6,18 * 6 *", time.UTC, time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)},
+		{"@hourly", time.UTC, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.UTC, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.UTC, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", tehran, time.Date(2024, 5, 2, 4, 30, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.UTC, time.Time{}},
+		{"@every 90s", time.UTC, time.Date(2024, 5, 1, 12, 31, 30, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@every 500ms",
+		"@every soon",
+		"@fortnightly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := Parse(spec, time.UTC); err == nil {
+				t.Errorf("Parse(%q) succeeded", spec)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		base     time.Duration
+		want     time.Duration
+	}{
+		{0, 0, time.Minute},
+		{1, time.Minute, time.Minute},
+		{2, time.Minute, 2 * time.Minute},
+		{3, 30 * time.Second, 2 * time.Minute},
+		{6, time.Minute, 32 * time.Minute},
+		{7, time.Minute, time.Hour},
+		{50, time.Minute, time.Hour},
+		{2, 45 * time.Minute, time.Hour},
+		{1, 2 * time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, tt.base); got != tt.want {
+			t.Errorf("Backoff(%d, %v) = %v, want %v", tt.attempts, tt.base, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package labs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Statuses of a lab order
+const (
+	Ordered   = "ordered"
+	Partial   = "partial"
+	Resulted  = "resulted"
+	Cancelled = "cancelled"
+)
+
+// Types of analyte values
+const (
+	Numeric = "numeric"
+	Text    = "text"
+)
+
+// Abnormal flags of a result, a normal result has no flag
+const (
+	FlagLow          = "low"
+	FlagHigh         = "high"
+	FlagCriticalLow  = "critical_low"
+	FlagCriticalHigh = "critical_high"
+	FlagAbnormal     = "abnormal"
+)
+
+// Range is the reference range of an analyte for patients of a sex aged MinAge up to, but not
+// including, MaxAge years. An empty Sex matches both and a zero bound is not checked
+type Range struct {
+	Sex          string  `json:"sex,omitempty"`
+	MinAge       int     `json:"min_age,omitempty"`
+	MaxAge       int     `json:"max_age,omitempty"`
+	Low          float64 `json:"low,omitempty"`
+	High         float64 `json:"high,omitempty"`
+	CriticalLow  float64 `json:"critical_low,omitempty"`
+	CriticalHigh float64 `json:"critical_high,omitempty"`
+}
+
+// matches reports whether the range applies to the patient, an unknown age (-1) or sex matches any range
+func (r Range) matches(sex string, age int) bool {
+	if r.Sex != "" && sex != "" && !strings.EqualFold(r.Sex, sex) {
+		return false
+	}
+	if age < 0 {
+		return true
+	}
+	return age >= r.MinAge && (r.MaxAge == 0 || age < r.MaxAge)
+}
+
+// Text formats the range for display, e.g. "3.5-5.1", "< 200" or "> 40"
+func (r Range) Text() string {
+	switch {
+	case r.Low != 0 && r.High != 0:
+		return format(r.Low) + "-" + format(r.High)
+	case r.High != 0:
+		return "< " + format(r.High)
+	case r.Low != 0:
+		return "> " + format(r.Low)
+	}
+	return ""
+}
+
+// Analyte is a single measured value of a panel, text analytes list their normal values
+type Analyte struct {
+	Code   string   `json:"code"`
+	Name   string   `json:"name"`
+	Unit   string   `json:"unit,omitempty"`
+	Type   string   `json:"type"`
+	Normal []string `json:"normal,omitempty"`
+	Ranges []Range  `json:"ranges,omitempty"`
+}
+
+// Reference returns the first range of the analyte that applies to the patient
+func (a Analyte) Reference(sex string, age int) (Range, bool) {
+	for _, r := range a.Ranges {
+		if r.matches(sex, age) {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+// Panel is an orderable set of analytes
+type Panel struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Analytes []Analyte `json:"analytes"`
+}
+
+// Analyte returns the analyte of the panel with the code
+func (p Panel) Analyte(code string) (Analyte, bool) {
+	for _, a := range p.Analytes {
+		if a.Code == code {
+			return a, true
+		}
+	}
+	return Analyte{}, false
+}
+
+// Result is an evaluated value, Numeric is set for numeric analytes
+type Result struct {
+	Value     string
+	Numeric   *float64
+	Flag      string
+	Reference Range
+	RefText   string
+}
+
+// digits maps Persian and Arabic-Indic digits and decimal separators to ASCII
+var digits = strings.NewReplacer(
+	"۰", "0", "۱", "1", "۲", "2", "۳", "3", "۴", "4", "۵", "5", "۶", "6", "۷", "7", "۸", "8", "۹", "9",
+	"٠", "0", "١", "1", "٢", "2", "٣", "3", "٤", "4", "٥", "5", "٦", "6", "٧", "7", "٨", "8", "٩", "9",
+	"٫", ".",
+)
+
+// ParseNumber reads a numeric value typed with ASCII, Persian or Arabic digits
+func ParseNumber(value string) (float64, error) {
+	number, err := strconv.ParseFloat(digits.Replace(strings.TrimSpace(value)), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return number, nil
+}
+
+// Evaluate checks a value against the reference range of the patient and flags it
+func (a Analyte) Evaluate(value, sex string, age int) (Result, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Result{}, fmt.Errorf("%s: value is required", a.Code)
+	}
+	if a.Type == Text {
+		result := Result{Value: value, RefText: strings.Join(a.Normal, ", ")}
+		if len(a.Normal) > 0 {
+			result.Flag = FlagAbnormal
+			for _, normal := range a.Normal {
+				if strings.EqualFold(normal, value) {
+					result.Flag = ""
+				}
+			}
+		}
+		return result, nil
+	}
+
+	number, err := ParseNumber(value)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %s", a.Code, err.Error())
+	}
+	result := Result{Value: format(number), Numeric: &number}
+	ref, ok := a.Reference(sex, age)
+	if !ok {
+		return result, nil
+	}
+	result.Reference, result.RefText = ref, ref.Text()
+	switch {
+	case ref.CriticalLow != 0 && number < ref.CriticalLow:
+		result.Flag = FlagCriticalLow
+	case ref.CriticalHigh != 0 && number > ref.CriticalHigh:
+		result.Flag = FlagCriticalHigh
+	case ref.Low != 0 && number < ref.Low:
+		result.Flag = FlagLow
+	case ref.High != 0 && number > ref.High:
+		result.Flag = FlagHigh
+	}
+	return result, nil
+}
+
+// IsCritical reports whether the flag needs immediate attention
+func IsCritical(flag string) bool {
+	return flag == FlagCriticalLow || flag == FlagCriticalHigh
+}
+
+func format(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// Catalog is an indexed, read-only set of orderable panels
+type Catalog struct {
+	panels []Panel
+	byCode map[string]*Panel
+}
+
+// New builds a catalog, DefaultPanels are used when panels is empty. Codes are lower cased, codes of panels must be
+// unique and an analyte code shared by panels must have the same unit, results are compared
+// across panels by analyte code
+func New(panels []Panel) (*Catalog, error) {
+	if len(panels) == 0 {
+		panels = DefaultPanels
+	}
+	c := &Catalog{panels: panels, byCode: map[string]*Panel{}}
+	units := map[string]string{}
+	for i := range panels {
+		panel := &panels[i]
+		panel.Code = strings.ToLower(strings.TrimSpace(panel.Code))
+		if panel.Code == "" || len(panel.Analytes) == 0 {
+			return nil, fmt.Errorf("panel %q must have a code and analytes", panel.Name)
+		}
+		if _, ok := c.byCode[panel.Code]; ok {
+			return nil, fmt.Errorf("duplicate panel code %s", panel.Code)
+		}
+		c.byCode[panel.Code] = panel
+		for j := range panel.Analytes {
+			a := &panel.Analytes[j]
+			a.Code = strings.ToLower(strings.TrimSpace(a.Code))
+			if a.Code == "" {
+				return nil, fmt.Errorf("panel %s has an analyte without code", panel.Code)
+			}
+			if a.Type != Numeric && a.Type != Text {
+				return nil, fmt.Errorf("analyte %s of panel %s has unknown type %q", a.Code, panel.Code, a.Type)
+			}
+			if unit, ok := units[a.Code]; ok && unit != a.Unit {
+				return nil, fmt.Errorf("analyte %s has units %q and %q", a.Code, unit, a.Unit)
+			}
+			units[a.Code] = a.Unit
+		}
+	}
+	return c, nil
+}
+
+// Load reads a catalog from a JSON file with a "panels" array
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Panels []Panel `json:"panels"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(file.Panels) == 0 {
+		return nil, fmt.Errorf("%s: no panels", path)
+	}
+	return New(file.Panels)
+}
+
+// Panels returns the panels sorted by name
+func (c *Catalog) Panels() []Panel {
+	panels := append([]Panel(nil), c.panels...)
+	sort.Slice(panels, func(i, j int) bool { return panels[i].Name < panels[j].Name })
+	return panels
+}
+
+// Panel returns the panel with the code
+func (c *Catalog) Panel(code string) (Panel, bool) {
+	panel, ok := c.byCode[strings.ToLower(strings.TrimSpace(code))]
+	if !ok {
+		return Panel{}, false
+	}
+	return *panel, true
+}
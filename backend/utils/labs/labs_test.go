@@ -0,0 +1,142 @@
+package labs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	catalog, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyte := func(panel, code string) Analyte {
+		p, ok := catalog.Panel(panel)
+		if !ok {
+			t.Fatalf("no panel %s", panel)
+		}
+		a, ok := p.Analyte(code)
+		if !ok {
+			t.Fatalf("no analyte %s in %s", code, panel)
+		}
+		return a
+	}
+	alp := Analyte{Code: "alp", Type: Numeric, Ranges: []Range{{MaxAge: 18, Low: 100, High: 400}, {MinAge: 18, Low: 40, High: 130}}}
+	urine := Analyte{Code: "protein", Type: Text, Normal: []string{"negative", "trace"}}
+	tests := []struct {
+		name        string
+		analyte     Analyte
+		value, sex  string
+		age         int
+		wantValue   string
+		wantFlag    string
+		wantRefText string
+	}{
+		{"normal", analyte("cbc", "hgb"), "12.50", "female", 40, "12.5", "", "12-15.5"},
+		{"low for the sex", analyte("cbc", "hgb"), "12.5", "male", 40, "12.5", FlagLow, "13.5-17.5"},
+		{"unknown sex takes the first range", analyte("cbc", "hgb"), "12.5", "", -1, "12.5", FlagLow, "13.5-17.5"},
+		{"critical low", analyte("cbc", "hgb"), "6", "female", 40, "6", FlagCriticalLow, "12-15.5"},
+		{"critical high", analyte("cbc", "hgb"), "21", "Female", 40, "21", FlagCriticalHigh, "12-15.5"},
+		{"persian digits", analyte("bmp", "potassium"), " ۵٫۸ ", "male", 40, "5.8", FlagHigh, "3.5-5.1"},
+		{"upper bound only", analyte("lipid", "cholesterol"), "210", "male", 40, "210", FlagHigh, "< 200"},
+		{"lower bound only", analyte("lipid", "hdl"), "45", "female", 40, "45", FlagLow, "> 50"},
+		{"child", alp, "300", "", 10, "300", "", "100-400"},
+		{"adult", alp, "300", "", 30, "300", FlagHigh, "40-130"},
+		{"no range", Analyte{Code: "x", Type: Numeric}, "1", "", 30, "1", "", ""},
+		{"normal text", urine, "NEGATIVE", "", 30, "NEGATIVE", "", "negative, trace"},
+		{"abnormal text", urine, "positive", "", 30, "positive", FlagAbnormal, "negative, trace"},
+		{"free text", Analyte{Code: "note", Type: Text}, "turbid", "", 30, "turbid", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.analyte.Evaluate(tt.value, tt.sex, tt.age)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.wantValue || got.Flag != tt.wantFlag || got.RefText != tt.wantRefText {
+				t.Errorf("Evaluate(%q) = %q %q %q, want %q %q %q", tt.value, got.Value, got.Flag, got.RefText, tt.wantValue, tt.wantFlag, tt.wantRefText)
+			}
+			if (got.Numeric != nil) != (tt.analyte.Type == Numeric) {
+				t.Errorf("Evaluate(%q) Numeric = %v", tt.value, got.Numeric)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "  ", "high", "NaN", "Inf", "1,5"} {
+		if _, err := analyte("cbc", "hgb").Evaluate(value, "male", 40); err == nil {
+			t.Errorf("Evaluate(%q) succeeded", value)
+		}
+	}
+}
+
+func TestIsCritical(t *testing.T) {
+	for flag, want := range map[string]bool{FlagCriticalLow: true, FlagCriticalHigh: true, FlagLow: false, FlagAbnormal: false, "": false} {
+		if got := IsCritical(flag); got != want {
+			t.Errorf("IsCritical(%q) = %v, want %v", flag, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	numeric := func(code, unit string) Analyte { return Analyte{Code: code, Unit: unit, Type: Numeric} }
+	tests := []struct {
+		name   string
+		panels []Panel
+	}{
+		{"no code", []Panel{{Name: "Panel", Analytes: []Analyte{numeric("a", "")}}}},
+		{"no analytes", []Panel{{Code: "p"}}},
+		{"duplicate code", []Panel{{Code: "P", Analytes: []Analyte{numeric("a", "")}}, {Code: " p ", Analytes: []Analyte{numeric("b", "")}}}},
+		{"analyte without code", []Panel{{Code: "p", Analytes: []Analyte{numeric(" ", "")}}}},
+		{"unknown type", []Panel{{Code: "p", Analytes: []Analyte{{Code: "a", Type: "image"}}}}},
+		{"units differ", []Panel{{Code: "p", Analytes: []Analyte{numeric("glucose", "mg/dL")}}, {Code: "q", Analytes: []Analyte{numeric("Glucose", "mmol/L")}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.panels); err == nil {
+				t.Error("New() succeeded")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	catalog, err := Load(write("panels.json", `{"panels": [
+		{"code": "UA", "name": "Urinalysis", "analytes": [{"code": "Protein", "name": "Protein", "type": "text", "normal": ["negative"]}]},
+		{"code": "tsh", "name": "Thyroid", "analytes": [{"code": "tsh", "name": "TSH", "unit": "mIU/L", "type": "numeric", "ranges": [{"low": 0.4, "high": 4}]}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	panels := catalog.Panels()
+	if len(panels) != 2 || panels[0].Name != "Thyroid" || panels[1].Name != "Urinalysis" {
+		t.Errorf("Panels() = %+v, want Thyroid and Urinalysis", panels)
+	}
+	panel, ok := catalog.Panel(" ua ")
+	if !ok {
+		t.Fatal("Panel(ua) not found")
+	}
+	if _, ok := panel.Analyte("protein"); !ok {
+		t.Error("analyte codes are not lower cased")
+	}
+
+	for name, content := range map[string]string{
+		"empty.json":   `{"panels": []}`,
+		"invalid.json": `{"panels": `,
+	} {
+		if _, err := Load(write(name, content)); err == nil {
+			t.Errorf("Load(%s) succeeded", name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
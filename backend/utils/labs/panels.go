@@ -0,0 +1,99 @@
+package labs
+
+// DefaultPanels are orderable when no catalog is configured, ranges are common adult
+// reference intervals and should be replaced by those of the laboratory in use
+var DefaultPanels = []Panel{
+	{
+		Code: "cbc", Name: "Complete Blood Count",
+		Analytes: []Analyte{
+			{Code: "wbc", Name: "White Blood Cells", Unit: "10^9/L", Type: Numeric,
+				Ranges: []Range{{Low: 4.0, High: 11.0, CriticalLow: 2.0, CriticalHigh: 30.0}}},
+			{Code: "rbc", Name: "Red Blood Cells", Unit: "10^12/L", Type: Numeric,
+				Ranges: []Range{{Sex: "male", Low: 4.5, High: 5.9}, {Sex: "female", Low: 4.1, High: 5.1}}},
+			{Code: "hgb", Name: "Hemoglobin", Unit: "g/dL", Type: Numeric,
+				Ranges: []Range{
+					{Sex: "male", Low: 13.5, High: 17.5, CriticalLow: 7.0, CriticalHigh: 20.0},
+					{Sex: "female", Low: 12.0, High: 15.5, CriticalLow: 7.0, CriticalHigh: 20.0},
+				}},
+			{Code: "hct", Name: "Hematocrit", Unit: "%", Type: Numeric,
+				Ranges: []Range{{Sex: "male", Low: 41, High: 53}, {Sex: "female", Low: 36, High: 46}}},
+			{Code: "mcv", Name: "Mean Corpuscular Volume", Unit: "fL", Type: Numeric,
+				Ranges: []Range{{Low: 80, High: 100}}},
+			{Code: "plt", Name: "Platelets", Unit: "10^9/L", Type: Numeric,
+				Ranges: []Range{{Low: 150, High: 450, CriticalLow: 50, CriticalHigh: 1000}}},
+		},
+	},
+	{
+		Code: "bmp", Name: "Basic Metabolic Panel",
+		Analytes: []Analyte{
+			{Code: "glucose", Name: "Glucose, fasting", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Low: 70, High: 99, CriticalLow: 50, CriticalHigh: 400}}},
+			{Code: "bun", Name: "Blood Urea Nitrogen", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Low: 7, High: 20}}},
+			{Code: "creatinine", Name: "Creatinine", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Sex: "male", Low: 0.74, High: 1.35}, {Sex: "female", Low: 0.59, High: 1.04}}},
+			{Code: "sodium", Name: "Sodium", Unit: "mmol/L", Type: Numeric,
+				Ranges: []Range{{Low: 135, High: 145, CriticalLow: 120, CriticalHigh: 160}}},
+			{Code: "potassium", Name: "Potassium", Unit: "mmol/L", Type: Numeric,
+				Ranges: []Range{{Low: 3.5, High: 5.1, CriticalLow: 2.5, CriticalHigh: 6.5}}},
+			{Code: "chloride", Name: "Chloride", Unit: "mmol/L", Type: Numeric,
+				Ranges: []Range{{Low: 98, High: 107}}},
+			{Code: "calcium", Name: "Calcium", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Low: 8.6, High: 10.3, CriticalLow: 6.0, CriticalHigh: 13.0}}},
+		},
+	},
+	{
+		Code: "lipid", Name: "Lipid Panel",
+		Analytes: []Analyte{
+			{Code: "cholesterol", Name: "Total Cholesterol", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{High: 200}}},
+			{Code: "ldl", Name: "LDL Cholesterol", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{High: 130}}},
+			{Code: "hdl", Name: "HDL Cholesterol", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Sex: "male", Low: 40}, {Sex: "female", Low: 50}}},
+			{Code: "triglycerides", Name: "Triglycerides", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{High: 150}}},
+		},
+	},
+	{
+		Code: "hba1c", Name: "Hemoglobin A1c",
+		Analytes: []Analyte{
+			{Code: "hba1c", Name: "Hemoglobin A1c", Unit: "%", Type: Numeric,
+				Ranges: []Range{{High: 5.7}}},
+		},
+	},
+	{
+		Code: "tsh", Name: "Thyroid Stimulating Hormone",
+		Analytes: []Analyte{
+			{Code: "tsh", Name: "TSH", Unit: "mIU/L", Type: Numeric,
+				Ranges: []Range{{Low: 0.4, High: 4.0}}},
+		},
+	},
+	{
+		Code: "lft", Name: "Liver Function Tests",
+		Analytes: []Analyte{
+			{Code: "alt", Name: "Alanine Aminotransferase", Unit: "U/L", Type: Numeric,
+				Ranges: []Range{{Low: 7, High: 56}}},
+			{Code: "ast", Name: "Aspartate Aminotransferase", Unit: "U/L", Type: Numeric,
+				Ranges: []Range{{Low: 10, High: 40}}},
+			{Code: "alp", Name: "Alkaline Phosphatase", Unit: "U/L", Type: Numeric,
+				Ranges: []Range{{Low: 44, High: 147}}},
+			{Code: "bilirubin", Name: "Total Bilirubin", Unit: "mg/dL", Type: Numeric,
+				Ranges: []Range{{Low: 0.1, High: 1.2}}},
+			{Code: "albumin", Name: "Albumin", Unit: "g/dL", Type: Numeric,
+				Ranges: []Range{{Low: 3.4, High: 5.4}}},
+		},
+	},
+	{
+		Code: "ua", Name: "Urinalysis",
+		Analytes: []Analyte{
+			{Code: "urine_ph", Name: "pH", Type: Numeric, Ranges: []Range{{Low: 4.5, High: 8.0}}},
+			{Code: "urine_sg", Name: "Specific Gravity", Type: Numeric, Ranges: []Range{{Low: 1.005, High: 1.030}}},
+			{Code: "urine_protein", Name: "Protein", Type: Text, Normal: []string{"negative"}},
+			{Code: "urine_glucose", Name: "Glucose", Type: Text, Normal: []string{"negative"}},
+			{Code: "urine_nitrite", Name: "Nitrite", Type: Text, Normal: []string{"negative"}},
+			{Code: "urine_leukocytes", Name: "Leukocyte Esterase", Type: Text, Normal: []string{"negative"}},
+			{Code: "urine_blood", Name: "Blood", Type: Text, Normal: []string{"negative"}},
+		},
+	},
+}
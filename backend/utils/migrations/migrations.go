@@ -0,0 +1,142 @@
+// Package migrations owns the healthcare schema on plain PostgreSQL 13 or later and on Oracle
+// Database 23ai or later. Migrations are embedded in the binary per dialect as
+// sql/<dialect>/NNNN_name.up.sql with an optional NNNN_name.down.sql, both dialects use the same
+// versions and each migration is recorded in schema_migrations with the checksum of its up script.
+//
+// A PostgreSQL migration runs in its own transaction. Oracle commits every DDL statement, so an
+// Oracle script is a list of statements each ended by a line holding a single slash, as in SQL*Plus,
+// and a failed Oracle migration leaves the statements before the failing one applied
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"healthcare/utils/dialect"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/postgres/*.sql sql/oracle/*.sql
+var files embed.FS
+
+// Migration is one version of the schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum is the SHA-256 of the up script, hex encoded. An applied migration whose script was edited
+// afterwards no longer matches the checksum recorded when it ran
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Embedded returns the migrations of d built into the binary
+func Embedded(d dialect.Dialect) ([]Migration, error) {
+	sub, err := fs.Sub(files, path.Join("sql", string(d)))
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Load reads NNNN_name.up.sql and NNNN_name.down.sql files of fsys sorted by version, a version needs
+// an up script and must not be used by two names
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		version, name, direction, err := parseName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if len(strings.TrimSpace(m.Up)) == 0 {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Statements splits a script into the statements the driver runs one at a time. A PostgreSQL script
+// runs whole, an Oracle script is split on the lines holding a single slash and parts holding only
+// comments are dropped
+func Statements(d dialect.Dialect, script string) []string {
+	if d != dialect.Oracle {
+		return []string{script}
+	}
+	var list []string
+	var part []string
+	flush := func() {
+		statement := strings.TrimSpace(strings.Join(part, "\n"))
+		part = part[:0]
+		for _, line := range strings.Split(statement, "\n") {
+			if line = strings.TrimSpace(line); len(line) > 0 && !strings.HasPrefix(line, "--") {
+				list = append(list, statement)
+				return
+			}
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "/" {
+			flush()
+			continue
+		}
+		part = append(part, line)
+	}
+	flush()
+	return list
+}
+
+// parseName splits 0001_core_schema.up.sql into its version, name and direction
+func parseName(file string) (int64, string, string, error) {
+	base := strings.TrimSuffix(file, ".sql")
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("%s: migrations end in .up.sql or .down.sql", file)
+	}
+	base = strings.TrimSuffix(base, direction)
+	number, name, ok := strings.Cut(base, "_")
+	if !ok || len(name) == 0 {
+		return 0, "", "", fmt.Errorf("%s: migrations are named NNNN_name", file)
+	}
+	version, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("%s: version must be a positive number", file)
+	}
+	return version, name, direction[1:], nil
+}
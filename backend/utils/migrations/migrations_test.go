@@ -0,0 +1,113 @@
+package migrations
+
+import (
+	"healthcare/utils/dialect"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_visits.up.sql":         {Data: []byte("CREATE TABLE visits ();")},
+		"0001_core.down.sql":         {Data: []byte("DROP TABLE core;")},
+		"0001_core.up.sql":           {Data: []byte("CREATE TABLE core ();")},
+		"0010_indexes.up.sql":        {Data: []byte("CREATE INDEX i ON visits (id);")},
+		"README.md":                  {Data: []byte("notes")},
+		"archive/0003_old.up.sql":    {Data: []byte("SELECT 1;")},
+		"0011_no_down_needed.up.sql": {Data: []byte("SELECT 1;")},
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "core", Up: "CREATE TABLE core ();", Down: "DROP TABLE core;"},
+		{Version: 2, Name: "visits", Up: "CREATE TABLE visits ();"},
+		{Version: 10, Name: "indexes", Up: "CREATE INDEX i ON visits (id);"},
+		{Version: 11, Name: "no_down_needed", Up: "SELECT 1;"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+	if sum := got[0].Checksum(); len(sum) != 64 || sum == got[1].Checksum() {
+		t.Errorf("Checksum() = %q", sum)
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"no up script":    {"0001_core.down.sql": {Data: []byte("DROP TABLE core;")}},
+		"empty up":        {"0001_core.up.sql": {Data: []byte(" \n")}},
+		"two names":       {"0001_core.up.sql": {Data: []byte("SELECT 1;")}, "0001_other.up.sql": {Data: []byte("SELECT 1;")}},
+		"no direction":    {"0001_core.sql": {Data: []byte("SELECT 1;")}},
+		"no name":         {"0001.up.sql": {Data: []byte("SELECT 1;")}},
+		"zero version":    {"0000_core.up.sql": {Data: []byte("SELECT 1;")}},
+		"not a version":   {"v1_core.up.sql": {Data: []byte("SELECT 1;")}},
+		"wrong direction": {"0001_core.sideways.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for name, fsys := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(fsys); err == nil {
+				t.Error("Load() succeeded")
+			}
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	script := "-- the visits table\r\nCREATE TABLE visits (id NUMBER)\r\n/\r\n" +
+		"-- only a comment\n/\n" +
+		"CREATE OR REPLACE TRIGGER visits_trg BEFORE UPDATE ON visits FOR EACH ROW\nBEGIN\n  :NEW.id := :OLD.id / 1;\nEND;\n  /  \n" +
+		"COMMENT ON TABLE visits IS 'a / b'"
+	want := []string{
+		"-- the visits table\nCREATE TABLE visits (id NUMBER)",
+		"CREATE OR REPLACE TRIGGER visits_trg BEFORE UPDATE ON visits FOR EACH ROW\nBEGIN\n  :NEW.id := :OLD.id / 1;\nEND;",
+		"COMMENT ON TABLE visits IS 'a / b'",
+	}
+	if got := Statements(dialect.Oracle, script); !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements(oracle) = %q, want %q", got, want)
+	}
+	if got := Statements(dialect.Postgres, script); len(got) != 1 || got[0] != script {
+		t.Errorf("Statements(postgres) = %q, want the whole script", got)
+	}
+	if got := Statements(dialect.Oracle, "-- nothing\n/\n"); len(got) != 0 {
+		t.Errorf("Statements() of comments = %q, want none", got)
+	}
+}
+
+// plsql matches the statements that are PL/SQL units and end with a semicolon
+var plsql = regexp.MustCompile(`(?is)^(--[^\n]*\n\s*)*(DECLARE|BEGIN|CREATE\s+(OR\s+REPLACE\s+)?(TRIGGER|PROCEDURE|FUNCTION|PACKAGE))\b`)
+
+// TestEmbedded checks the migrations built into the binary: both dialects have the same versions and
+// names, and the Oracle statements only end with a semicolon when they are PL/SQL, as the driver
+// rejects one after a SQL statement
+func TestEmbedded(t *testing.T) {
+	postgres, err := Embedded(dialect.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle, err := Embedded(dialect.Oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(postgres) != len(oracle) {
+		t.Fatalf("%d PostgreSQL and %d Oracle migrations", len(postgres), len(oracle))
+	}
+	for i := range postgres {
+		p, o := postgres[i], oracle[i]
+		if p.Version != o.Version || p.Name != o.Name {
+			t.Errorf("migration %d_%s of PostgreSQL is %d_%s on Oracle", p.Version, p.Name, o.Version, o.Name)
+		}
+		if (p.Down == "") != (o.Down == "") {
+			t.Errorf("migration %d_%s has a down script in one dialect only", p.Version, p.Name)
+		}
+		for _, script := range []string{o.Up, o.Down} {
+			for _, statement := range Statements(dialect.Oracle, script) {
+				if strings.HasSuffix(statement, ";") && !plsql.MatchString(statement) {
+					t.Errorf("migration %d_%s: statement ends with a semicolon: %.80q", o.Version, o.Name, statement)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"healthcare/utils/dialect"
+	"log"
+	"sort"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/sijms/go-ora/v2"
+)
+
+// lockKey is the advisory lock held while migrating, replicas starting together wait for each other
+// and find the schema migrated. It spells "health" in ASCII
+const lockKey int64 = 0x6865616c7468
+
+// oracleLockID is lockKey folded into the range of Oracle user locks, which are numbered below 2^30
+const oracleLockID = lockKey & (1<<30 - 1)
+
+var (
+	createTable = dialect.Query{
+		Postgres: `
+			CREATE TABLE IF NOT EXISTS public.schema_migrations (
+			  version BIGINT PRIMARY KEY,
+			  name TEXT NOT NULL,
+			  checksum TEXT NOT NULL,
+			  applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+			)`,
+		Oracle: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+			  version NUMBER(19) PRIMARY KEY,
+			  name VARCHAR2(255) NOT NULL,
+			  checksum VARCHAR2(64) NOT NULL,
+			  applied_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+			)`,
+	}
+	tableExists = dialect.Query{
+		Postgres: `SELECT to_regclass('public.schema_migrations') IS NOT NULL`,
+		Oracle:   `SELECT COUNT(*) > 0 FROM user_tables WHERE table_name = 'SCHEMA_MIGRATIONS'`,
+	}
+	listApplied = dialect.Query{
+		Postgres: `SELECT version, name, checksum, applied_at FROM public.schema_migrations ORDER BY version`,
+		Oracle:   `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`,
+	}
+	insertApplied = dialect.Query{
+		Postgres: `INSERT INTO public.schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+		Oracle:   `INSERT INTO schema_migrations (version, name, checksum) VALUES (:1, :2, :3)`,
+	}
+	deleteApplied = dialect.Query{
+		Postgres: `DELETE FROM public.schema_migrations WHERE version = $1`,
+		Oracle:   `DELETE FROM schema_migrations WHERE version = :1`,
+	}
+	// the Oracle lock is a user lock of DBMS_LOCK, which the role needs EXECUTE on, and is not
+	// released by the commits of DDL statements
+	tryLock = dialect.Query{
+		Postgres: `SELECT pg_try_advisory_lock($1)`,
+		Oracle:   `BEGIN :1 := DBMS_LOCK.REQUEST(:2, DBMS_LOCK.X_MODE, 0, FALSE); END;`,
+	}
+	waitLock = dialect.Query{
+		Postgres: `SELECT TRUE FROM pg_advisory_lock($1)`,
+		Oracle:   `BEGIN :1 := DBMS_LOCK.REQUEST(:2, DBMS_LOCK.X_MODE, DBMS_LOCK.MAXWAIT, FALSE); END;`,
+	}
+	unlock = dialect.Query{
+		Postgres: `SELECT pg_advisory_unlock($1)`,
+		Oracle:   `BEGIN :1 := DBMS_LOCK.RELEASE(:2); END;`,
+	}
+)
+
+// Runner applies and reverts migrations on a PostgreSQL or an Oracle database
+type Runner struct {
+	DB         *sql.DB
+	Dialect    dialect.Dialect
+	Migrations []Migration
+}
+
+// State is a migration with its record in the database, Unknown marks an applied version the binary
+// has no script for and Modified an applied script that changed since it ran
+type State struct {
+	Migration
+	AppliedAt *time.Time
+	Unknown   bool
+	Modified  bool
+}
+
+type record struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Open connects to the database of dsn, oracle:// urls are opened with go-ora and the others with
+// lib/pq
+func Open(ctx context.Context, dsn string) (*sql.DB, error) {
+	if len(dsn) == 0 {
+		return nil, errors.New("no database to migrate, set specific.migrations.dsn or DATABASE_URL")
+	}
+	db, err := sql.Open(dialect.FromDSN(dsn).Driver(), dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// New returns a runner of the embedded migrations of d
+func New(db *sql.DB, d dialect.Dialect) (*Runner, error) {
+	list, err := Embedded(d)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{DB: db, Dialect: d, Migrations: list}, nil
+}
+
+// Up applies the pending migrations up to and including target, all of them when target is 0, and
+// returns how many were applied. Nothing is applied while an applied migration is unknown or modified
+func (r *Runner) Up(ctx context.Context, target int64) (int, error) {
+	count := 0
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := r.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.Migrations {
+			if target > 0 && m.Version > target {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if err := r.exec(ctx, tx, m.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, insertApplied.In(r.Dialect), m.Version, m.Name, m.Checksum())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			log.Printf("migrations: applied %d_%s", m.Version, m.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns how many were reverted
+func (r *Runner) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := r.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(r.Migrations) - 1; i >= 0 && count < steps; i-- {
+			m := r.Migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if len(m.Down) == 0 {
+				return fmt.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if err := r.exec(ctx, tx, m.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, deleteApplied.In(r.Dialect), m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			log.Printf("migrations: reverted %d_%s", m.Version, m.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Baseline records the migrations up to and including version as applied without running them, for
+// databases whose schema was created by the former scripts
+func (r *Runner) Baseline(ctx context.Context, version int64) (int, error) {
+	count := 0
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := r.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.Migrations {
+			if m.Version > version {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, insertApplied.In(r.Dialect), m.Version, m.Name, m.Checksum()); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status lists the migrations of the binary and the applied versions unknown to it by version, it
+// takes no lock and creates nothing
+func (r *Runner) Status(ctx context.Context) ([]State, error) {
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, tableExists.In(r.Dialect)).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int64]record{}
+	if exists {
+		var err error
+		if applied, err = r.readApplied(ctx, r.DB); err != nil {
+			return nil, err
+		}
+	}
+	states := make([]State, 0, len(r.Migrations))
+	for _, m := range r.Migrations {
+		state := State{Migration: m}
+		if rec, ok := applied[m.Version]; ok {
+			state.AppliedAt = &rec.AppliedAt
+			state.Modified = rec.Checksum != m.Checksum()
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for _, rec := range applied {
+		states = append(states, State{
+			Migration: Migration{Version: rec.Version, Name: rec.Name},
+			AppliedAt: &rec.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// verify returns the applied migrations after checking each one is known and unchanged
+func (r *Runner) verify(ctx context.Context, conn *sql.Conn) (map[int64]record, error) {
+	applied, err := r.readApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]Migration, len(r.Migrations))
+	for _, m := range r.Migrations {
+		known[m.Version] = m
+	}
+	for _, rec := range applied {
+		m, ok := known[rec.Version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d_%s is unknown to this binary", rec.Version, rec.Name)
+		}
+		if rec.Checksum != m.Checksum() {
+			return nil, fmt.Errorf("migration %d_%s was modified after it was applied", m.Version, m.Name)
+		}
+	}
+	return applied, nil
+}
+
+// locked runs f on one connection holding the migration lock, the table of applied migrations is
+// created under the lock
+func (r *Runner) locked(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	acquired, err := r.request(ctx, conn, tryLock)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		log.Printf("migrations: waiting for another migration to finish")
+		if acquired, err = r.request(ctx, conn, waitLock); err != nil {
+			return err
+		}
+		if !acquired {
+			return errors.New("the migration lock was not granted")
+		}
+	}
+	defer func() {
+		// the lock belongs to the session, a connection that kept it must not go back to the pool
+		if err := r.release(conn); err != nil {
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+	if _, err := conn.ExecContext(ctx, createTable.In(r.Dialect)); err != nil {
+		return err
+	}
+	return f(conn)
+}
+
+// request runs a lock statement and reports whether the lock was taken, PostgreSQL returns it and
+// DBMS_LOCK.REQUEST returns 0 for a granted lock, 1 for a timeout and 4 for a lock the session holds
+func (r *Runner) request(ctx context.Context, conn *sql.Conn, statement dialect.Query) (bool, error) {
+	if r.Dialect == dialect.Oracle {
+		var status int64
+		if _, err := conn.ExecContext(ctx, statement.In(r.Dialect), sql.Out{Dest: &status}, oracleLockID); err != nil {
+			return false, err
+		}
+		switch status {
+		case 0, 4:
+			return true, nil
+		case 1:
+			return false, nil
+		}
+		return false, fmt.Errorf("DBMS_LOCK.REQUEST returned %d", status)
+	}
+	var acquired bool
+	err := conn.QueryRowContext(ctx, statement.In(r.Dialect), lockKey).Scan(&acquired)
+	return acquired, err
+}
+
+func (r *Runner) release(conn *sql.Conn) error {
+	args := []any{lockKey}
+	if r.Dialect == dialect.Oracle {
+		var status int64
+		args = []any{sql.Out{Dest: &status}, oracleLockID}
+	}
+	_, err := conn.ExecContext(context.Background(), unlock.In(r.Dialect), args...)
+	return err
+}
+
+// exec runs a script statement by statement
+func (r *Runner) exec(ctx context.Context, tx *sql.Tx, script string) error {
+	for _, statement := range Statements(r.Dialect, script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, f func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (r *Runner) readApplied(ctx context.Context, db queryer) (map[int64]record, error) {
+	rows, err := db.QueryContext(ctx, listApplied.In(r.Dialect))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int64]record{}
+	for rows.Next() {
+		var rec record
+		if err := rows.Scan(&rec.Version, &rec.Name, &rec.Checksum, &rec.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[rec.Version] = rec
+	}
+	return applied, rows.Err()
+}
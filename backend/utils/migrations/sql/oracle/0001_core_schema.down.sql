@@ -0,0 +1,13 @@
+-- the triggers are dropped with their tables
+DROP TABLE therapy_schedules
+/
+DROP TABLE visit_images
+/
+DROP TABLE visits
+/
+DROP TABLE doctors
+/
+DROP TABLE patients
+/
+DROP TABLE profiles
+/
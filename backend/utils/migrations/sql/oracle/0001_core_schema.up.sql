@@ -0,0 +1,112 @@
+-- Profiles of users with their role, patients and doctors extend a profile. Users sign in through
+-- the ums endpoints of the backend, so a profile is not tied to an external auth schema.
+-- Ids are UUIDs kept as text, the default formats SYS_GUID as one. Enums of the PostgreSQL schema
+-- are text columns with a check and TIME columns are HH24:MI text
+CREATE TABLE profiles (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  email VARCHAR2(320) UNIQUE NOT NULL,
+  full_name VARCHAR2(255) NOT NULL,
+  role VARCHAR2(16) DEFAULT 'patient' NOT NULL CONSTRAINT profiles_role_check CHECK (role IN ('admin', 'doctor', 'patient')),
+  phone VARCHAR2(64),
+  date_of_birth DATE,
+  address VARCHAR2(1000),
+  medical_history VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE TABLE patients (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  profile_id VARCHAR2(36) REFERENCES profiles(id),
+  patient_id VARCHAR2(64) UNIQUE NOT NULL, -- Human readable ID
+  emergency_contact_name VARCHAR2(255),
+  emergency_contact_phone VARCHAR2(64),
+  allergies VARCHAR2(4000),
+  current_medications VARCHAR2(4000),
+  insurance_info VARCHAR2(1000),
+  -- demographics of patients registered at the front desk without a profile of their own
+  full_name VARCHAR2(255),
+  date_of_birth DATE,
+  gender VARCHAR2(16),
+  phone VARCHAR2(64),
+  email VARCHAR2(320),
+  address VARCHAR2(1000),
+  medical_history VARCHAR2(4000),
+  blood_type VARCHAR2(8),
+  height NUMBER(5,1), -- cm
+  weight NUMBER(5,1), -- kg
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE TABLE doctors (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  profile_id VARCHAR2(36) REFERENCES profiles(id),
+  license_number VARCHAR2(64) UNIQUE NOT NULL,
+  specialization VARCHAR2(255) NOT NULL,
+  years_experience NUMBER(10),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE TABLE visits (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  doctor_id VARCHAR2(36) REFERENCES doctors(id) NOT NULL,
+  visit_type VARCHAR2(16) NOT NULL CONSTRAINT visits_visit_type_check CHECK (visit_type IN ('general', 'dentistry', 'specialist')),
+  visit_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR2(16) DEFAULT 'scheduled' NOT NULL CONSTRAINT visits_status_check CHECK (status IN ('scheduled', 'completed', 'cancelled')),
+  chief_complaint VARCHAR2(4000),
+  symptoms VARCHAR2(4000),
+  diagnosis VARCHAR2(4000),
+  treatment_plan VARCHAR2(4000),
+  medications_prescribed VARCHAR2(4000),
+  notes VARCHAR2(4000),
+  follow_up_date DATE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE TABLE visit_images (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE,
+  image_url VARCHAR2(2000) NOT NULL,
+  description VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE TABLE therapy_schedules (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  doctor_id VARCHAR2(36) REFERENCES doctors(id) NOT NULL,
+  therapy_type VARCHAR2(255) NOT NULL,
+  description VARCHAR2(4000),
+  start_date DATE NOT NULL,
+  end_date DATE,
+  frequency VARCHAR2(255), -- e.g., "daily", "weekly", "twice a week"
+  instructions VARCHAR2(4000),
+  is_active BOOLEAN DEFAULT TRUE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE OR REPLACE TRIGGER update_profiles_updated_at BEFORE UPDATE ON profiles FOR EACH ROW
+BEGIN
+  :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+CREATE OR REPLACE TRIGGER update_patients_updated_at BEFORE UPDATE ON patients FOR EACH ROW
+BEGIN
+  :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+CREATE OR REPLACE TRIGGER update_visits_updated_at BEFORE UPDATE ON visits FOR EACH ROW
+BEGIN
+  :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+CREATE OR REPLACE TRIGGER update_therapy_schedules_updated_at BEFORE UPDATE ON therapy_schedules FOR EACH ROW
+BEGIN
+  :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
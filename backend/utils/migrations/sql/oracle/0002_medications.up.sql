@@ -0,0 +1,27 @@
+-- Medications prescribed in a visit, running medications of a patient are checked for interactions
+-- with a new prescription
+CREATE TABLE medications (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  medication_name VARCHAR2(255) NOT NULL,
+  dosage VARCHAR2(255),
+  frequency VARCHAR2(255),
+  duration VARCHAR2(255),
+  instructions VARCHAR2(4000),
+  start_date DATE,
+  end_date DATE,
+  is_active BOOLEAN DEFAULT TRUE NOT NULL,
+  side_effects VARCHAR2(4000),
+  contraindications VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+-- Oracle has no partial indexes, the index covers inactive medications too
+CREATE INDEX medications_visit_idx ON medications (visit_id)
+/
+CREATE OR REPLACE TRIGGER update_medications_updated_at BEFORE UPDATE ON medications FOR EACH ROW
+BEGIN
+  :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
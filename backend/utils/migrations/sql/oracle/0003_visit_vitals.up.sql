@@ -0,0 +1,24 @@
+-- Structured vital signs recorded during visits
+CREATE TABLE visit_vitals (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  recorded_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  bp_systolic NUMBER(5,1),
+  bp_diastolic NUMBER(5,1),
+  pulse NUMBER(5,1),
+  temperature NUMBER(4,1),
+  spo2 NUMBER(4,1),
+  respiratory_rate NUMBER(4,1),
+  weight NUMBER(5,1), -- kg
+  height NUMBER(5,1), -- cm
+  bmi NUMBER(4,1),
+  abnormal_flags VARCHAR2(1000), -- e.g. "bp_systolic:high,spo2:low"
+  recorded_by VARCHAR2(255),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE INDEX visit_vitals_patient_idx ON visit_vitals (patient_id, recorded_at)
+/
+CREATE INDEX visit_vitals_visit_idx ON visit_vitals (visit_id)
+/
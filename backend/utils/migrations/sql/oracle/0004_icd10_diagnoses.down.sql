@@ -0,0 +1,4 @@
+DROP TABLE visit_diagnoses
+/
+DROP TABLE icd10_codes
+/
@@ -0,0 +1,26 @@
+-- ICD-10 code table, imported from a local file through POST /api/v1/icd10/import
+CREATE TABLE icd10_codes (
+  code VARCHAR2(16) PRIMARY KEY, -- e.g. "J06.9"
+  description_en VARCHAR2(1000) NOT NULL,
+  description_fa VARCHAR2(1000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE INDEX icd10_codes_description_en_idx ON icd10_codes (LOWER(description_en))
+/
+-- Coded diagnoses of visits, a visit has at most one primary diagnosis
+CREATE TABLE visit_diagnoses (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  icd10_code VARCHAR2(16) REFERENCES icd10_codes(code) NOT NULL,
+  is_primary BOOLEAN DEFAULT FALSE NOT NULL,
+  notes VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  UNIQUE (visit_id, icd10_code)
+)
+/
+-- only primary diagnoses have a key, rows with a null key are not indexed
+CREATE UNIQUE INDEX visit_diagnoses_primary_idx ON visit_diagnoses (CASE WHEN is_primary THEN visit_id END)
+/
+CREATE INDEX visit_diagnoses_code_idx ON visit_diagnoses (icd10_code)
+/
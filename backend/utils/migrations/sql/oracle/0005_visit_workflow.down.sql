@@ -0,0 +1,10 @@
+-- visits in the workflow statuses are kept, the narrowed check only applies to new rows
+DROP TABLE visit_status_transitions
+/
+ALTER TABLE visits DROP (locked_by, locked_at)
+/
+ALTER TABLE visits DROP CONSTRAINT visits_status_check
+/
+ALTER TABLE visits ADD CONSTRAINT visits_status_check
+  CHECK (status IN ('scheduled', 'completed', 'cancelled')) ENABLE NOVALIDATE
+/
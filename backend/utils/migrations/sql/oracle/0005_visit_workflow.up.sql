@@ -0,0 +1,28 @@
+-- Visit workflow: scheduled -> checked_in -> in_progress -> completed, plus cancelled and no_show
+ALTER TABLE visits DROP CONSTRAINT visits_status_check
+/
+ALTER TABLE visits ADD CONSTRAINT visits_status_check
+  CHECK (status IN ('scheduled', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show'))
+/
+-- A completed visit may be locked against clinical edits, later changes go to addenda
+ALTER TABLE visits ADD (
+  locked_at TIMESTAMP WITH TIME ZONE,
+  locked_by VARCHAR2(255)
+)
+/
+-- Every status change of a visit with its actor
+CREATE TABLE visit_status_transitions (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  from_status VARCHAR2(16) CONSTRAINT visit_status_transitions_from_check
+    CHECK (from_status IN ('scheduled', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show')),
+  to_status VARCHAR2(16) NOT NULL CONSTRAINT visit_status_transitions_to_check
+    CHECK (to_status IN ('scheduled', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show')),
+  actor_id VARCHAR2(255) NOT NULL,
+  actor_name VARCHAR2(255),
+  reason VARCHAR2(4000),
+  transitioned_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE INDEX visit_status_transitions_visit_id_idx ON visit_status_transitions (visit_id, transitioned_at)
+/
@@ -0,0 +1,4 @@
+DROP TABLE visit_addenda
+/
+ALTER TABLE visits DROP (content_hash, signed_by_name, signed_by, signed_at)
+/
@@ -0,0 +1,21 @@
+-- Electronic sign-off of visit notes, signing also locks the visit
+ALTER TABLE visits ADD (
+  signed_at TIMESTAMP WITH TIME ZONE,
+  signed_by VARCHAR2(255),
+  signed_by_name VARCHAR2(255),
+  content_hash VARCHAR2(64) -- SHA-256 of the signed clinical content
+)
+/
+-- Corrections of signed or locked visits, append only
+CREATE TABLE visit_addenda (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  author_id VARCHAR2(255) NOT NULL,
+  author_name VARCHAR2(255),
+  text VARCHAR2(4000) NOT NULL,
+  content_hash VARCHAR2(64) NOT NULL, -- binds the addendum to the signed content and its author
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE INDEX visit_addenda_visit_id_idx ON visit_addenda (visit_id, created_at)
+/
@@ -0,0 +1,9 @@
+-- uploaded images have no url, they are removed before the column is required again
+DROP INDEX visit_images_visit_id_idx
+/
+DELETE FROM visit_images WHERE image_url IS NULL
+/
+ALTER TABLE visit_images DROP (uploaded_by, storage_key, mime_type, file_size, file_name, image_type)
+/
+ALTER TABLE visit_images MODIFY image_url NOT NULL
+/
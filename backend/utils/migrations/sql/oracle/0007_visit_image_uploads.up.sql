@@ -0,0 +1,15 @@
+-- Uploaded visit images, files are kept in the configured storage under storage_key
+-- and served through signed download urls
+ALTER TABLE visit_images MODIFY image_url NULL
+/
+ALTER TABLE visit_images ADD (
+  image_type VARCHAR2(64),
+  file_name VARCHAR2(255),
+  file_size NUMBER(19),
+  mime_type VARCHAR2(255),
+  storage_key VARCHAR2(1024) UNIQUE,
+  uploaded_by VARCHAR2(255)
+)
+/
+CREATE INDEX visit_images_visit_id_idx ON visit_images (visit_id, created_at)
+/
@@ -0,0 +1,2 @@
+ALTER TABLE visit_images DROP (preview_key, thumbnail_key)
+/
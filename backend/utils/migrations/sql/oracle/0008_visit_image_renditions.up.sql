@@ -0,0 +1,6 @@
+-- Thumbnail and preview renditions of uploaded JPEG and PNG visit images
+ALTER TABLE visit_images ADD (
+  thumbnail_key VARCHAR2(1024),
+  preview_key VARCHAR2(1024)
+)
+/
@@ -0,0 +1,6 @@
+DROP TABLE imaging_studies
+/
+DROP INDEX visit_images_study_uid_idx
+/
+ALTER TABLE visit_images DROP (study_uid, modality)
+/
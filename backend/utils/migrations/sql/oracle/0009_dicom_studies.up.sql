@@ -0,0 +1,23 @@
+-- DICOM visit images and the per patient index of imaging studies
+ALTER TABLE visit_images ADD (
+  modality VARCHAR2(16),
+  study_uid VARCHAR2(64)
+)
+/
+CREATE INDEX visit_images_study_uid_idx ON visit_images (study_uid)
+/
+CREATE TABLE imaging_studies (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  patient_id VARCHAR2(36) REFERENCES patients(id) ON DELETE CASCADE NOT NULL,
+  study_uid VARCHAR2(64) NOT NULL,
+  modality VARCHAR2(16),
+  study_date DATE,
+  description VARCHAR2(4000),
+  instance_count NUMBER(10) DEFAULT 0 NOT NULL,
+  first_visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE SET NULL,
+  last_visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  UNIQUE (patient_id, study_uid)
+)
+/
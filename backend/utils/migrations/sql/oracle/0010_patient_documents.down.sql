@@ -0,0 +1,2 @@
+DROP TABLE patient_documents
+/
@@ -0,0 +1,33 @@
+-- Documents kept at the patient level, e.g. consent forms, referral letters, external lab
+-- reports and ID scans. Files are kept in the configured storage under storage_key, deleted
+-- documents keep their file until retain_until and are marked purged once it is removed
+CREATE TABLE patient_documents (
+  id VARCHAR2(36) PRIMARY KEY,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  category VARCHAR2(32) NOT NULL,
+  title VARCHAR2(255),
+  description VARCHAR2(4000),
+  file_name VARCHAR2(255) NOT NULL,
+  file_size NUMBER(19) NOT NULL,
+  mime_type VARCHAR2(255) NOT NULL,
+  checksum VARCHAR2(64) NOT NULL, -- SHA-256 of the content, hex encoded
+  storage_key VARCHAR2(1024) UNIQUE NOT NULL,
+  document_date DATE NOT NULL,
+  retain_until DATE NOT NULL,
+  uploaded_by VARCHAR2(255),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE,
+  deleted_by VARCHAR2(255),
+  purged_at TIMESTAMP WITH TIME ZONE
+)
+/
+-- deleted documents have a null key and are not indexed
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON patient_documents (
+  CASE WHEN deleted_at IS NULL THEN patient_id END,
+  CASE WHEN deleted_at IS NULL THEN checksum END
+)
+/
+CREATE INDEX patient_documents_patient_idx ON patient_documents (patient_id, category, document_date DESC)
+/
+CREATE INDEX patient_documents_retention_idx ON patient_documents (retain_until)
+/
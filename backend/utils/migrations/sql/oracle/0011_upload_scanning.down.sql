@@ -0,0 +1,20 @@
+-- quarantined documents are dropped with their scan status, the checksum index would count them again
+DROP INDEX patient_documents_quarantine_idx
+/
+DROP INDEX visit_images_quarantine_idx
+/
+DROP INDEX patient_documents_checksum_idx
+/
+DELETE FROM patient_documents WHERE scan_status IN ('infected', 'failed')
+/
+DELETE FROM visit_images WHERE scan_status IN ('infected', 'failed')
+/
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON patient_documents (
+  CASE WHEN deleted_at IS NULL THEN patient_id END,
+  CASE WHEN deleted_at IS NULL THEN checksum END
+)
+/
+ALTER TABLE patient_documents DROP (scanned_at, scan_signature, scan_status)
+/
+ALTER TABLE visit_images DROP (scanned_at, scan_signature, scan_status)
+/
@@ -0,0 +1,33 @@
+-- Scan status of uploaded files. Files that are infected or could not be scanned are kept under
+-- the quarantine/ prefix of the storage and are not served, files stored before scanning was
+-- added are recorded as skipped
+ALTER TABLE visit_images ADD (
+  scan_status VARCHAR2(16) DEFAULT 'skipped' NOT NULL CONSTRAINT visit_images_scan_status_check
+    CHECK (scan_status IN ('clean', 'infected', 'failed', 'skipped')),
+  scan_signature VARCHAR2(255),
+  scanned_at TIMESTAMP WITH TIME ZONE
+)
+/
+ALTER TABLE visit_images MODIFY scan_status DEFAULT NULL
+/
+ALTER TABLE patient_documents ADD (
+  scan_status VARCHAR2(16) DEFAULT 'skipped' NOT NULL CONSTRAINT patient_documents_scan_status_check
+    CHECK (scan_status IN ('clean', 'infected', 'failed', 'skipped')),
+  scan_signature VARCHAR2(255),
+  scanned_at TIMESTAMP WITH TIME ZONE
+)
+/
+ALTER TABLE patient_documents MODIFY scan_status DEFAULT NULL
+/
+-- quarantined copies of a document must not block a clean upload of the same content
+DROP INDEX patient_documents_checksum_idx
+/
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON patient_documents (
+  CASE WHEN deleted_at IS NULL AND scan_status IN ('clean', 'skipped') THEN patient_id END,
+  CASE WHEN deleted_at IS NULL AND scan_status IN ('clean', 'skipped') THEN checksum END
+)
+/
+CREATE INDEX visit_images_quarantine_idx ON visit_images (scan_status, created_at)
+/
+CREATE INDEX patient_documents_quarantine_idx ON patient_documents (scan_status, created_at)
+/
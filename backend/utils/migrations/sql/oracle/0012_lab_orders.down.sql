@@ -0,0 +1,4 @@
+DROP TABLE lab_results
+/
+DROP TABLE lab_orders
+/
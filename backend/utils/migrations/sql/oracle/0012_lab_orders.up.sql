@@ -0,0 +1,48 @@
+-- Lab orders of a visit and their structured results, panels and reference ranges come from the
+-- lab catalog of the backend and are copied to each result so old results keep their ranges
+CREATE TABLE lab_orders (
+  id VARCHAR2(36) PRIMARY KEY,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  panel_code VARCHAR2(64) NOT NULL,
+  panel_name VARCHAR2(255) NOT NULL,
+  status VARCHAR2(16) DEFAULT 'ordered' NOT NULL CONSTRAINT lab_orders_status_check
+    CHECK (status IN ('ordered', 'partial', 'resulted', 'cancelled')),
+  notes VARCHAR2(4000),
+  ordered_by VARCHAR2(255) NOT NULL,
+  ordered_by_name VARCHAR2(255),
+  ordered_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  collected_at TIMESTAMP WITH TIME ZONE,
+  resulted_at TIMESTAMP WITH TIME ZONE,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+)
+/
+CREATE INDEX lab_orders_visit_idx ON lab_orders (visit_id, ordered_at)
+/
+-- a result without a flag stores NULL, COMMENT is a reserved word and is quoted
+CREATE TABLE lab_results (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  order_id VARCHAR2(36) REFERENCES lab_orders(id) ON DELETE CASCADE NOT NULL,
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  analyte_code VARCHAR2(64) NOT NULL,
+  analyte_name VARCHAR2(255) NOT NULL,
+  value_type VARCHAR2(16) NOT NULL CHECK (value_type IN ('numeric', 'text')),
+  value_text VARCHAR2(1000) NOT NULL,
+  numeric_value NUMBER,
+  unit VARCHAR2(64),
+  ref_low NUMBER,
+  ref_high NUMBER,
+  ref_text VARCHAR2(1000),
+  flag VARCHAR2(16) CHECK (flag IN ('low', 'high', 'critical_low', 'critical_high', 'abnormal')),
+  "COMMENT" VARCHAR2(4000),
+  amended BOOLEAN DEFAULT FALSE NOT NULL,
+  entered_by VARCHAR2(255),
+  resulted_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  UNIQUE (order_id, analyte_code)
+)
+/
+CREATE INDEX lab_results_visit_idx ON lab_results (visit_id)
+/
+CREATE INDEX lab_results_patient_idx ON lab_results (patient_id, analyte_code, resulted_at)
+/
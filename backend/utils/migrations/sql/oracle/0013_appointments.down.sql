@@ -0,0 +1,12 @@
+DROP INDEX visits_slot_idx
+/
+DROP INDEX visits_no_double_booking
+/
+ALTER TABLE visits DROP CONSTRAINT visits_slot_check
+/
+ALTER TABLE visits DROP (slot_end, slot_start)
+/
+DROP TABLE doctor_exceptions
+/
+DROP TABLE doctor_working_hours
+/
@@ -0,0 +1,48 @@
+-- Weekly working hours and schedule exceptions of doctors, free slots are generated from them by
+-- the backend and a booked slot is stored on its scheduled visit. Times of day are HH24:MI text,
+-- the backend writes them zero padded so they compare as text
+CREATE TABLE doctor_working_hours (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  doctor_id VARCHAR2(36) REFERENCES doctors(id) ON DELETE CASCADE NOT NULL,
+  weekday NUMBER(1) NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  start_time VARCHAR2(5) NOT NULL,
+  end_time VARCHAR2(5) NOT NULL,
+  slot_minutes NUMBER(10) NOT NULL CHECK (slot_minutes BETWEEN 5 AND 480),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  CHECK (start_time < end_time OR end_time IN ('00:00', '24:00'))
+)
+/
+CREATE INDEX doctor_working_hours_doctor_idx ON doctor_working_hours (doctor_id, weekday)
+/
+-- Leave and holidays without times cover the whole day, extra hours add slots on a date
+CREATE TABLE doctor_exceptions (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  doctor_id VARCHAR2(36) REFERENCES doctors(id) ON DELETE CASCADE NOT NULL,
+  exception_date DATE NOT NULL,
+  start_time VARCHAR2(5),
+  end_time VARCHAR2(5),
+  slot_minutes NUMBER(10) CHECK (slot_minutes BETWEEN 5 AND 480),
+  kind VARCHAR2(16) NOT NULL CHECK (kind IN ('leave', 'holiday', 'extra')),
+  reason VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  CHECK ((start_time IS NULL AND end_time IS NULL) OR (start_time IS NOT NULL AND end_time IS NOT NULL)),
+  CHECK (kind <> 'extra' OR (start_time IS NOT NULL AND slot_minutes IS NOT NULL))
+)
+/
+CREATE INDEX doctor_exceptions_doctor_idx ON doctor_exceptions (doctor_id, exception_date)
+/
+-- The booked slot of a visit. Oracle has no exclusion constraints, bookings lock the doctor row
+-- and check for overlaps, the index below still rejects two active visits starting together
+ALTER TABLE visits ADD (
+  slot_start TIMESTAMP WITH TIME ZONE,
+  slot_end TIMESTAMP WITH TIME ZONE,
+  CONSTRAINT visits_slot_check CHECK (slot_end > slot_start)
+)
+/
+CREATE UNIQUE INDEX visits_no_double_booking ON visits (
+  CASE WHEN slot_start IS NOT NULL AND status NOT IN ('cancelled', 'no_show') THEN doctor_id END,
+  CASE WHEN slot_start IS NOT NULL AND status NOT IN ('cancelled', 'no_show') THEN SYS_EXTRACT_UTC(slot_start) END
+)
+/
+CREATE INDEX visits_slot_idx ON visits (doctor_id, slot_start)
+/
@@ -0,0 +1,4 @@
+DROP TABLE queue_entries
+/
+DROP TABLE queue_counters
+/
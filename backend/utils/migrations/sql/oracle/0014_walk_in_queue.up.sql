@@ -0,0 +1,43 @@
+-- Daily walk-in queues of doctors and departments, a department queue is served by all doctors
+-- of that specialization and numbers restart every day per queue
+CREATE TABLE queue_counters (
+  queue_date DATE NOT NULL,
+  queue_key VARCHAR2(255) NOT NULL,
+  last_number NUMBER(10) NOT NULL,
+  PRIMARY KEY (queue_date, queue_key)
+)
+/
+-- NUMBER is a reserved word and is quoted
+CREATE TABLE queue_entries (
+  id VARCHAR2(36) PRIMARY KEY,
+  queue_date DATE NOT NULL,
+  queue_key VARCHAR2(255) NOT NULL,
+  "NUMBER" NUMBER(10) NOT NULL,
+  patient_id VARCHAR2(36) REFERENCES patients(id) NOT NULL,
+  doctor_id VARCHAR2(36) REFERENCES doctors(id),
+  department VARCHAR2(255),
+  status VARCHAR2(16) DEFAULT 'waiting' NOT NULL CHECK (status IN ('waiting', 'called', 'skipped', 'cancelled')),
+  visit_type VARCHAR2(16) DEFAULT 'general' NOT NULL CHECK (visit_type IN ('general', 'dentistry', 'specialist')),
+  chief_complaint VARCHAR2(4000),
+  notes VARCHAR2(4000),
+  reason VARCHAR2(4000),
+  registered_by VARCHAR2(255) NOT NULL,
+  registered_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  called_by VARCHAR2(36) REFERENCES doctors(id),
+  called_at TIMESTAMP WITH TIME ZONE,
+  call_id VARCHAR2(36) UNIQUE,
+  -- the in-progress visit a called walk-in was converted into
+  visit_id VARCHAR2(36) REFERENCES visits(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  UNIQUE (queue_date, queue_key, "NUMBER"),
+  CHECK ((doctor_id IS NULL AND department IS NOT NULL) OR (doctor_id IS NOT NULL AND department IS NULL))
+)
+/
+CREATE INDEX queue_entries_waiting_idx ON queue_entries (queue_date, queue_key, status, registered_at)
+/
+-- a patient waits in one queue at a time
+CREATE UNIQUE INDEX queue_entries_active_patient_idx ON queue_entries (
+  CASE WHEN status = 'waiting' THEN patient_id END,
+  CASE WHEN status = 'waiting' THEN queue_date END
+)
+/
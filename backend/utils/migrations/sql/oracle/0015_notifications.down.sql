@@ -0,0 +1,6 @@
+DROP TABLE notification_attempts
+/
+DROP TABLE notifications
+/
+ALTER TABLE patients DROP COLUMN preferred_language
+/
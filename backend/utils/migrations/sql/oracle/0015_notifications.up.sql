@@ -0,0 +1,46 @@
+-- Reminders of appointments, follow-ups and therapy sessions with their delivery attempts,
+-- one notification is kept per reminder and channel
+ALTER TABLE patients ADD preferred_language VARCHAR2(16)
+/
+CREATE TABLE notifications (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  kind VARCHAR2(16) NOT NULL CHECK (kind IN ('appointment', 'follow_up', 'therapy')),
+  ref_id VARCHAR2(36) NOT NULL, -- visit or therapy schedule
+  due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  patient_id VARCHAR2(36) REFERENCES patients(id) ON DELETE CASCADE NOT NULL,
+  channel VARCHAR2(8) NOT NULL CHECK (channel IN ('sms', 'email')),
+  recipient VARCHAR2(320) NOT NULL,
+  language VARCHAR2(16) NOT NULL,
+  subject VARCHAR2(1000),
+  body VARCHAR2(4000) NOT NULL,
+  status VARCHAR2(16) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'cancelled')),
+  attempts NUMBER(10) DEFAULT 0 NOT NULL,
+  max_attempts NUMBER(10) NOT NULL,
+  next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  last_error VARCHAR2(4000),
+  claim_id VARCHAR2(36),
+  claimed_at TIMESTAMP WITH TIME ZONE,
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+  UNIQUE (kind, ref_id, due_at, channel)
+)
+/
+CREATE INDEX notifications_due_idx ON notifications (status, next_attempt_at)
+/
+CREATE INDEX notifications_claim_idx ON notifications (claim_id)
+/
+CREATE INDEX notifications_patient_idx ON notifications (patient_id, created_at)
+/
+CREATE TABLE notification_attempts (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  notification_id VARCHAR2(36) REFERENCES notifications(id) ON DELETE CASCADE NOT NULL,
+  attempt NUMBER(10) NOT NULL,
+  channel VARCHAR2(8) NOT NULL,
+  succeeded BOOLEAN NOT NULL,
+  error VARCHAR2(4000),
+  attempted_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+)
+/
+CREATE INDEX notification_attempts_notification_idx ON notification_attempts (notification_id, attempt)
+/
@@ -0,0 +1,4 @@
+DROP TABLE job_runs
+/
+DROP TABLE jobs
+/
@@ -0,0 +1,34 @@
+-- Background jobs with their runs, every replica runs the job runner and the lease of a job
+-- makes one of them run it at a time
+CREATE TABLE jobs (
+  name VARCHAR2(64) PRIMARY KEY,
+  schedule VARCHAR2(255) NOT NULL,
+  enabled BOOLEAN DEFAULT TRUE NOT NULL,
+  next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  -- failed runs since the last success, reset when the job succeeds or runs out of attempts
+  attempt NUMBER(10) DEFAULT 0 NOT NULL,
+  triggered BOOLEAN DEFAULT FALSE NOT NULL,
+  lease_owner VARCHAR2(255),
+  lease_until TIMESTAMP WITH TIME ZONE,
+  last_run_at TIMESTAMP WITH TIME ZONE,
+  last_status VARCHAR2(16) CHECK (last_status IN ('succeeded', 'failed')),
+  last_error VARCHAR2(4000),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+)
+/
+-- TRIGGER is a reserved word and is quoted
+CREATE TABLE job_runs (
+  id VARCHAR2(36) PRIMARY KEY,
+  job_name VARCHAR2(64) REFERENCES jobs(name) ON DELETE CASCADE NOT NULL,
+  attempt NUMBER(10) NOT NULL,
+  "TRIGGER" VARCHAR2(16) NOT NULL CHECK ("TRIGGER" IN ('schedule', 'manual', 'retry')),
+  owner VARCHAR2(255) NOT NULL,
+  status VARCHAR2(16) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+  error VARCHAR2(4000),
+  started_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  finished_at TIMESTAMP WITH TIME ZONE
+)
+/
+CREATE INDEX job_runs_job_idx ON job_runs (job_name, started_at DESC)
+/
@@ -0,0 +1,6 @@
+DROP TABLE therapy_sessions
+/
+DROP INDEX therapy_schedules_status_idx
+/
+ALTER TABLE therapy_schedules DROP (ended_at, status, session_count, duration)
+/
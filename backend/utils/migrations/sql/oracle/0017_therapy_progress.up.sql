@@ -0,0 +1,26 @@
+-- Progress of therapy schedules: the sessions a patient attended or missed and the status of the
+-- schedule, which the system moves to completed or expired. is_active mirrors the active status
+ALTER TABLE therapy_schedules ADD (
+  duration NUMBER(10), -- minutes of a session
+  session_count NUMBER(10),
+  status VARCHAR2(16) DEFAULT 'active' NOT NULL CONSTRAINT therapy_schedules_status_check
+    CHECK (status IN ('active', 'completed', 'expired', 'cancelled')),
+  ended_at TIMESTAMP WITH TIME ZONE
+)
+/
+UPDATE therapy_schedules SET status = 'cancelled', ended_at = updated_at WHERE is_active = FALSE
+/
+CREATE INDEX therapy_schedules_status_idx ON therapy_schedules (status)
+/
+CREATE TABLE therapy_sessions (
+  id VARCHAR2(36) DEFAULT LOWER(REGEXP_REPLACE(RAWTOHEX(SYS_GUID()), '(.{8})(.{4})(.{4})(.{4})(.{12})', '\1-\2-\3-\4-\5')) PRIMARY KEY,
+  schedule_id VARCHAR2(36) REFERENCES therapy_schedules(id) ON DELETE CASCADE NOT NULL,
+  session_date DATE NOT NULL,
+  status VARCHAR2(16) NOT NULL CHECK (status IN ('attended', 'missed')),
+  notes VARCHAR2(4000),
+  recorded_by VARCHAR2(255),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  UNIQUE (schedule_id, session_date)
+)
+/
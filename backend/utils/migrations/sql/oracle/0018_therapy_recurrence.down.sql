@@ -0,0 +1,4 @@
+DROP TABLE clinic_holidays
+/
+ALTER TABLE therapy_schedules DROP (holiday_policy, exdates, rrule)
+/
@@ -0,0 +1,16 @@
+-- Recurrence of therapy schedules: an RFC 5545 RRULE with EXDATE exceptions replaces the loose
+-- frequency, which is kept as a label. Sessions on a clinic holiday are skipped or moved to the next
+-- or previous working day according to the policy of the schedule
+ALTER TABLE therapy_schedules ADD (
+  rrule VARCHAR2(1000),
+  exdates VARCHAR2(4000), -- comma separated YYYY-MM-DD
+  holiday_policy VARCHAR2(16) DEFAULT 'skip' NOT NULL CONSTRAINT therapy_schedules_holiday_policy_check
+    CHECK (holiday_policy IN ('skip', 'next', 'previous'))
+)
+/
+CREATE TABLE clinic_holidays (
+  day DATE PRIMARY KEY,
+  name VARCHAR2(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL
+)
+/
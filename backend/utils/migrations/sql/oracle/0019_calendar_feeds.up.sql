@@ -0,0 +1,9 @@
+-- Secret-token iCalendar feeds of doctors and patients, only a SHA-256 hash of the token is kept
+-- and regenerating it replaces the hash so the old feed url stops working
+CREATE TABLE calendar_feeds (
+  user_id VARCHAR2(255) PRIMARY KEY,
+  token_hash VARCHAR2(64) UNIQUE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  last_fetched_at TIMESTAMP WITH TIME ZONE
+)
+/
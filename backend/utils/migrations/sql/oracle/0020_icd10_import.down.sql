@@ -0,0 +1,2 @@
+DROP TABLE icd10_import_codes
+/
@@ -0,0 +1,11 @@
+-- Codes of an ICD-10 import are staged under the id of the import and merged into icd10_codes in one
+-- statement, so a failed import leaves the code table as it was
+CREATE TABLE icd10_import_codes (
+  import_id VARCHAR2(36) NOT NULL,
+  code VARCHAR2(16) NOT NULL,
+  description_en VARCHAR2(1000) NOT NULL,
+  description_fa VARCHAR2(1000),
+  staged_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+  PRIMARY KEY (import_id, code)
+)
+/
@@ -0,0 +1,2 @@
+DROP TRIGGER visits_no_double_booking_trg
+/
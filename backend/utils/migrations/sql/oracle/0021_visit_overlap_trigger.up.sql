@@ -0,0 +1,43 @@
+-- Oracle has no exclusion constraints, this trigger keeps two active visits of a doctor from
+-- overlapping like visits_no_double_booking does on PostgreSQL. It checks the visits a statement
+-- booked, moved or made active again after locking the row of their doctor, so bookings of a doctor
+-- in concurrent transactions are checked one after the other and see each other once committed
+CREATE OR REPLACE TRIGGER visits_no_double_booking_trg
+FOR INSERT OR UPDATE OF doctor_id, slot_start, slot_end, status ON visits
+COMPOUND TRIGGER
+  TYPE id_list IS TABLE OF visits.id%TYPE;
+  changed id_list := id_list();
+  doctors_of id_list := id_list();
+
+  AFTER EACH ROW IS
+  BEGIN
+    IF :NEW.slot_start IS NOT NULL AND :NEW.doctor_id IS NOT NULL
+       AND :NEW.status NOT IN ('cancelled', 'no_show') THEN
+      changed.EXTEND;
+      changed(changed.LAST) := :NEW.id;
+      doctors_of.EXTEND;
+      doctors_of(doctors_of.LAST) := :NEW.doctor_id;
+    END IF;
+  END AFTER EACH ROW;
+
+  AFTER STATEMENT IS
+    v_locked doctors.id%TYPE;
+    v_overlaps NUMBER;
+  BEGIN
+    FOR i IN 1 .. changed.COUNT LOOP
+      SELECT id INTO v_locked FROM doctors WHERE id = doctors_of(i) FOR UPDATE;
+      SELECT COUNT(*) INTO v_overlaps
+        FROM visits a
+        JOIN visits b ON b.doctor_id = a.doctor_id AND b.id <> a.id
+       WHERE a.id = changed(i)
+         AND b.slot_start < a.slot_end
+         AND b.slot_end > a.slot_start
+         AND a.status NOT IN ('cancelled', 'no_show')
+         AND b.status NOT IN ('cancelled', 'no_show');
+      IF v_overlaps > 0 THEN
+        RAISE_APPLICATION_ERROR(-20001, 'visits_no_double_booking: the slot overlaps another visit');
+      END IF;
+    END LOOP;
+  END AFTER STATEMENT;
+END visits_no_double_booking_trg;
+/
@@ -0,0 +1,12 @@
+DROP TABLE public.therapy_schedules;
+DROP TABLE public.visit_images;
+DROP TABLE public.visits;
+DROP TABLE public.doctors;
+DROP TABLE public.patients;
+DROP TABLE public.profiles;
+
+DROP FUNCTION public.update_updated_at_column();
+
+DROP TYPE visit_status;
+DROP TYPE visit_type;
+DROP TYPE user_role;
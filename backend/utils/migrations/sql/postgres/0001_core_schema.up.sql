@@ -0,0 +1,113 @@
+-- Profiles of users with their role, patients and doctors extend a profile. Users sign in through
+-- the ums endpoints of the backend, so a profile is not tied to an external auth schema
+CREATE TYPE user_role AS ENUM ('admin', 'doctor', 'patient');
+CREATE TYPE visit_type AS ENUM ('general', 'dentistry', 'specialist');
+CREATE TYPE visit_status AS ENUM ('scheduled', 'completed', 'cancelled');
+
+CREATE TABLE public.profiles (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  email TEXT UNIQUE NOT NULL,
+  full_name TEXT NOT NULL,
+  role user_role NOT NULL DEFAULT 'patient',
+  phone TEXT,
+  date_of_birth DATE,
+  address TEXT,
+  medical_history TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE public.patients (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  profile_id UUID REFERENCES public.profiles(id),
+  patient_id TEXT UNIQUE NOT NULL, -- Human readable ID
+  emergency_contact_name TEXT,
+  emergency_contact_phone TEXT,
+  allergies TEXT,
+  current_medications TEXT,
+  insurance_info TEXT,
+  -- demographics of patients registered at the front desk without a profile of their own
+  full_name TEXT,
+  date_of_birth DATE,
+  gender TEXT,
+  phone TEXT,
+  email TEXT,
+  address TEXT,
+  medical_history TEXT,
+  blood_type TEXT,
+  height NUMERIC(5,1), -- cm
+  weight NUMERIC(5,1), -- kg
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE public.doctors (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  profile_id UUID REFERENCES public.profiles(id),
+  license_number TEXT UNIQUE NOT NULL,
+  specialization TEXT NOT NULL,
+  years_experience INTEGER,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE public.visits (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  doctor_id UUID REFERENCES public.doctors(id) NOT NULL,
+  visit_type visit_type NOT NULL,
+  visit_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  status visit_status NOT NULL DEFAULT 'scheduled',
+  chief_complaint TEXT,
+  symptoms TEXT,
+  diagnosis TEXT,
+  treatment_plan TEXT,
+  medications_prescribed TEXT,
+  notes TEXT,
+  follow_up_date DATE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE public.visit_images (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE,
+  image_url TEXT NOT NULL,
+  description TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE public.therapy_schedules (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  doctor_id UUID REFERENCES public.doctors(id) NOT NULL,
+  therapy_type TEXT NOT NULL,
+  description TEXT,
+  start_date DATE NOT NULL,
+  end_date DATE,
+  frequency TEXT, -- e.g., "daily", "weekly", "twice a week"
+  instructions TEXT,
+  is_active BOOLEAN DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION public.update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = NOW();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_profiles_updated_at BEFORE UPDATE ON public.profiles
+  FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_patients_updated_at BEFORE UPDATE ON public.patients
+  FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_visits_updated_at BEFORE UPDATE ON public.visits
+  FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+CREATE TRIGGER update_therapy_schedules_updated_at BEFORE UPDATE ON public.therapy_schedules
+  FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
@@ -0,0 +1,23 @@
+-- Medications prescribed in a visit, running medications of a patient are checked for interactions
+-- with a new prescription
+CREATE TABLE public.medications (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  medication_name TEXT NOT NULL,
+  dosage TEXT,
+  frequency TEXT,
+  duration TEXT,
+  instructions TEXT,
+  start_date DATE,
+  end_date DATE,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  side_effects TEXT,
+  contraindications TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX medications_visit_idx ON public.medications (visit_id) WHERE is_active;
+
+CREATE TRIGGER update_medications_updated_at BEFORE UPDATE ON public.medications
+  FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
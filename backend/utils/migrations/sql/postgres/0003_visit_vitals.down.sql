@@ -0,0 +1 @@
+DROP TABLE public.visit_vitals;
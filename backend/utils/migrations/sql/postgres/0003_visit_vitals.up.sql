@@ -0,0 +1,22 @@
+-- Structured vital signs recorded during visits
+CREATE TABLE public.visit_vitals (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  bp_systolic NUMERIC(5,1),
+  bp_diastolic NUMERIC(5,1),
+  pulse NUMERIC(5,1),
+  temperature NUMERIC(4,1),
+  spo2 NUMERIC(4,1),
+  respiratory_rate NUMERIC(4,1),
+  weight NUMERIC(5,1), -- kg
+  height NUMERIC(5,1), -- cm
+  bmi NUMERIC(4,1),
+  abnormal_flags TEXT, -- e.g. "bp_systolic:high,spo2:low"
+  recorded_by TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX visit_vitals_patient_idx ON public.visit_vitals (patient_id, recorded_at);
+CREATE INDEX visit_vitals_visit_idx ON public.visit_vitals (visit_id);
@@ -0,0 +1,2 @@
+DROP TABLE public.visit_diagnoses;
+DROP TABLE public.icd10_codes;
@@ -0,0 +1,23 @@
+-- ICD-10 code table, imported from a local file through POST /api/v1/icd10/import
+CREATE TABLE public.icd10_codes (
+  code TEXT PRIMARY KEY, -- e.g. "J06.9"
+  description_en TEXT NOT NULL,
+  description_fa TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX icd10_codes_description_en_idx ON public.icd10_codes (lower(description_en));
+
+-- Coded diagnoses of visits, a visit has at most one primary diagnosis
+CREATE TABLE public.visit_diagnoses (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  icd10_code TEXT REFERENCES public.icd10_codes(code) NOT NULL,
+  is_primary BOOLEAN NOT NULL DEFAULT false,
+  notes TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (visit_id, icd10_code)
+);
+
+CREATE UNIQUE INDEX visit_diagnoses_primary_idx ON public.visit_diagnoses (visit_id) WHERE is_primary;
+CREATE INDEX visit_diagnoses_code_idx ON public.visit_diagnoses (icd10_code);
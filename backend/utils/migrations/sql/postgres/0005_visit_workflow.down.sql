@@ -0,0 +1,5 @@
+-- PostgreSQL can not drop values of an enum, checked_in, in_progress and no_show stay in visit_status
+DROP TABLE public.visit_status_transitions;
+
+ALTER TABLE public.visits DROP COLUMN locked_by;
+ALTER TABLE public.visits DROP COLUMN locked_at;
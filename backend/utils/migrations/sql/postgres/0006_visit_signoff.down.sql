@@ -0,0 +1,6 @@
+DROP TABLE public.visit_addenda;
+
+ALTER TABLE public.visits DROP COLUMN content_hash;
+ALTER TABLE public.visits DROP COLUMN signed_by_name;
+ALTER TABLE public.visits DROP COLUMN signed_by;
+ALTER TABLE public.visits DROP COLUMN signed_at;
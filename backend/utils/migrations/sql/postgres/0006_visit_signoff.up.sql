@@ -0,0 +1,18 @@
+-- Electronic sign-off of visit notes, signing also locks the visit
+ALTER TABLE public.visits ADD COLUMN signed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE public.visits ADD COLUMN signed_by TEXT;
+ALTER TABLE public.visits ADD COLUMN signed_by_name TEXT;
+ALTER TABLE public.visits ADD COLUMN content_hash TEXT; -- SHA-256 of the signed clinical content
+
+-- Corrections of signed or locked visits, append only
+CREATE TABLE public.visit_addenda (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  author_id TEXT NOT NULL,
+  author_name TEXT,
+  text TEXT NOT NULL,
+  content_hash TEXT NOT NULL, -- binds the addendum to the signed content and its author
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX visit_addenda_visit_id_idx ON public.visit_addenda (visit_id, created_at);
@@ -0,0 +1,12 @@
+-- uploaded images have no url, they are removed before the column is required again
+DROP INDEX public.visit_images_visit_id_idx;
+
+DELETE FROM public.visit_images WHERE image_url IS NULL;
+
+ALTER TABLE public.visit_images DROP COLUMN uploaded_by;
+ALTER TABLE public.visit_images DROP COLUMN storage_key;
+ALTER TABLE public.visit_images DROP COLUMN mime_type;
+ALTER TABLE public.visit_images DROP COLUMN file_size;
+ALTER TABLE public.visit_images DROP COLUMN file_name;
+ALTER TABLE public.visit_images DROP COLUMN image_type;
+ALTER TABLE public.visit_images ALTER COLUMN image_url SET NOT NULL;
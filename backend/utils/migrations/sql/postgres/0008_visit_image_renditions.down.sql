@@ -0,0 +1,2 @@
+ALTER TABLE public.visit_images DROP COLUMN preview_key;
+ALTER TABLE public.visit_images DROP COLUMN thumbnail_key;
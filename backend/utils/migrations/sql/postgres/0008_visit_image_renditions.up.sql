@@ -0,0 +1,3 @@
+-- Thumbnail and preview renditions of uploaded JPEG and PNG visit images
+ALTER TABLE public.visit_images ADD COLUMN thumbnail_key TEXT;
+ALTER TABLE public.visit_images ADD COLUMN preview_key TEXT;
@@ -0,0 +1,6 @@
+DROP TABLE public.imaging_studies;
+
+DROP INDEX public.visit_images_study_uid_idx;
+
+ALTER TABLE public.visit_images DROP COLUMN study_uid;
+ALTER TABLE public.visit_images DROP COLUMN modality;
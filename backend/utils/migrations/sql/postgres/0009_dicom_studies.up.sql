@@ -0,0 +1,20 @@
+-- DICOM visit images and the per patient index of imaging studies
+ALTER TABLE public.visit_images ADD COLUMN modality TEXT;
+ALTER TABLE public.visit_images ADD COLUMN study_uid TEXT;
+
+CREATE INDEX visit_images_study_uid_idx ON public.visit_images (study_uid) WHERE study_uid IS NOT NULL;
+
+CREATE TABLE public.imaging_studies (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  patient_id UUID REFERENCES public.patients(id) ON DELETE CASCADE NOT NULL,
+  study_uid TEXT NOT NULL,
+  modality TEXT,
+  study_date DATE,
+  description TEXT,
+  instance_count INTEGER NOT NULL DEFAULT 0,
+  first_visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  last_visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (patient_id, study_uid)
+);
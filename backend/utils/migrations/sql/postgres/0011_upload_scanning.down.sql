@@ -0,0 +1,18 @@
+-- quarantined documents are dropped with their scan status, the checksum index would count them again
+DROP INDEX public.patient_documents_quarantine_idx;
+DROP INDEX public.visit_images_quarantine_idx;
+DROP INDEX public.patient_documents_checksum_idx;
+
+DELETE FROM public.patient_documents WHERE scan_status IN ('infected', 'failed');
+DELETE FROM public.visit_images WHERE scan_status IN ('infected', 'failed');
+
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON public.patient_documents (patient_id, checksum)
+  WHERE deleted_at IS NULL;
+
+ALTER TABLE public.patient_documents DROP COLUMN scanned_at;
+ALTER TABLE public.patient_documents DROP COLUMN scan_signature;
+ALTER TABLE public.patient_documents DROP COLUMN scan_status;
+
+ALTER TABLE public.visit_images DROP COLUMN scanned_at;
+ALTER TABLE public.visit_images DROP COLUMN scan_signature;
+ALTER TABLE public.visit_images DROP COLUMN scan_status;
@@ -0,0 +1,24 @@
+-- Scan status of uploaded files. Files that are infected or could not be scanned are kept under
+-- the quarantine/ prefix of the storage and are not served, files stored before scanning was
+-- added are recorded as skipped
+ALTER TABLE public.visit_images ADD COLUMN scan_status TEXT NOT NULL DEFAULT 'skipped'
+  CHECK (scan_status IN ('clean', 'infected', 'failed', 'skipped'));
+ALTER TABLE public.visit_images ADD COLUMN scan_signature TEXT;
+ALTER TABLE public.visit_images ADD COLUMN scanned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE public.visit_images ALTER COLUMN scan_status DROP DEFAULT;
+
+ALTER TABLE public.patient_documents ADD COLUMN scan_status TEXT NOT NULL DEFAULT 'skipped'
+  CHECK (scan_status IN ('clean', 'infected', 'failed', 'skipped'));
+ALTER TABLE public.patient_documents ADD COLUMN scan_signature TEXT;
+ALTER TABLE public.patient_documents ADD COLUMN scanned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE public.patient_documents ALTER COLUMN scan_status DROP DEFAULT;
+
+-- quarantined copies of a document must not block a clean upload of the same content
+DROP INDEX public.patient_documents_checksum_idx;
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON public.patient_documents (patient_id, checksum)
+  WHERE deleted_at IS NULL AND scan_status IN ('clean', 'skipped');
+
+CREATE INDEX visit_images_quarantine_idx ON public.visit_images (created_at)
+  WHERE scan_status IN ('infected', 'failed');
+CREATE INDEX patient_documents_quarantine_idx ON public.patient_documents (created_at)
+  WHERE scan_status IN ('infected', 'failed');
@@ -0,0 +1,4 @@
+DROP TABLE public.lab_results;
+DROP TABLE public.lab_orders;
+
+DROP TYPE lab_order_status;
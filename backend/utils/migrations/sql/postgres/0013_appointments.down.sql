@@ -0,0 +1,6 @@
+-- btree_gist is left installed, other schemas of the database may use it
+ALTER TABLE public.visits DROP CONSTRAINT visits_no_double_booking;
+ALTER TABLE public.visits DROP COLUMN slot;
+
+DROP TABLE public.doctor_exceptions;
+DROP TABLE public.doctor_working_hours;
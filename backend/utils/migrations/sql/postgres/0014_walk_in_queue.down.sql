@@ -0,0 +1,2 @@
+DROP TABLE public.queue_entries;
+DROP TABLE public.queue_counters;
@@ -0,0 +1,40 @@
+-- Daily walk-in queues of doctors and departments, a department queue is served by all doctors
+-- of that specialization and numbers restart every day per queue
+CREATE TABLE public.queue_counters (
+  queue_date DATE NOT NULL,
+  queue_key TEXT NOT NULL,
+  last_number INTEGER NOT NULL,
+  PRIMARY KEY (queue_date, queue_key)
+);
+
+CREATE TABLE public.queue_entries (
+  id UUID PRIMARY KEY,
+  queue_date DATE NOT NULL,
+  queue_key TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  doctor_id UUID REFERENCES public.doctors(id),
+  department TEXT,
+  status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'called', 'skipped', 'cancelled')),
+  visit_type visit_type NOT NULL DEFAULT 'general',
+  chief_complaint TEXT,
+  notes TEXT,
+  reason TEXT,
+  registered_by TEXT NOT NULL,
+  registered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  called_by UUID REFERENCES public.doctors(id),
+  called_at TIMESTAMP WITH TIME ZONE,
+  call_id UUID UNIQUE,
+  -- the in-progress visit a called walk-in was converted into
+  visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (queue_date, queue_key, number),
+  CHECK ((doctor_id IS NULL) <> (department IS NULL))
+);
+
+CREATE INDEX queue_entries_waiting_idx ON public.queue_entries (queue_date, queue_key, registered_at)
+  WHERE status = 'waiting';
+
+-- a patient waits in one queue at a time
+CREATE UNIQUE INDEX queue_entries_active_patient_idx ON public.queue_entries (patient_id, queue_date)
+  WHERE status = 'waiting';
@@ -0,0 +1,4 @@
+DROP TABLE public.notification_attempts;
+DROP TABLE public.notifications;
+
+ALTER TABLE public.patients DROP COLUMN preferred_language;
@@ -0,0 +1,2 @@
+DROP TABLE public.job_runs;
+DROP TABLE public.jobs;
@@ -0,0 +1,8 @@
+DROP TABLE public.therapy_sessions;
+
+DROP INDEX public.therapy_schedules_status_idx;
+
+ALTER TABLE public.therapy_schedules DROP COLUMN ended_at;
+ALTER TABLE public.therapy_schedules DROP COLUMN status;
+ALTER TABLE public.therapy_schedules DROP COLUMN session_count;
+ALTER TABLE public.therapy_schedules DROP COLUMN duration;
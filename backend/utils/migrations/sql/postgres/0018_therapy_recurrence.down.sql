@@ -0,0 +1,5 @@
+DROP TABLE public.clinic_holidays;
+
+ALTER TABLE public.therapy_schedules DROP COLUMN holiday_policy;
+ALTER TABLE public.therapy_schedules DROP COLUMN exdates;
+ALTER TABLE public.therapy_schedules DROP COLUMN rrule;
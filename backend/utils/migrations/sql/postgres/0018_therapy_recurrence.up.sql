@@ -0,0 +1,13 @@
+-- Recurrence of therapy schedules: an RFC 5545 RRULE with EXDATE exceptions replaces the loose
+-- frequency, which is kept as a label. Sessions on a clinic holiday are skipped or moved to the next
+-- or previous working day according to the policy of the schedule
+ALTER TABLE public.therapy_schedules ADD COLUMN rrule TEXT;
+ALTER TABLE public.therapy_schedules ADD COLUMN exdates TEXT; -- comma separated YYYY-MM-DD
+ALTER TABLE public.therapy_schedules ADD COLUMN holiday_policy TEXT DEFAULT 'skip' NOT NULL
+  CHECK (holiday_policy IN ('skip', 'next', 'previous'));
+
+CREATE TABLE public.clinic_holidays (
+  day DATE PRIMARY KEY,
+  name TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Secret-token iCalendar feeds of doctors and patients, only a SHA-256 hash of the token is kept
+-- and regenerating it replaces the hash so the old feed url stops working
+CREATE TABLE public.calendar_feeds (
+  user_id TEXT PRIMARY KEY,
+  token_hash TEXT UNIQUE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  last_fetched_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1 @@
+DROP TABLE public.icd10_import_codes;
@@ -0,0 +1,10 @@
+-- Codes of an ICD-10 import are staged under the id of the import and merged into icd10_codes in one
+-- statement, so a failed import leaves the code table as it was
+CREATE TABLE public.icd10_import_codes (
+  import_id UUID NOT NULL,
+  code TEXT NOT NULL,
+  description_en TEXT NOT NULL,
+  description_fa TEXT,
+  staged_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  PRIMARY KEY (import_id, code)
+);
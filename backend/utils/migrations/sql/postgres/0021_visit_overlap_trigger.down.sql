@@ -0,0 +1 @@
+COMMENT ON CONSTRAINT visits_no_double_booking ON public.visits IS NULL;
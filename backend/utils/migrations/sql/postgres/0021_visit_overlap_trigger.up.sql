@@ -0,0 +1,3 @@
+-- Oracle checks overlapping visits of a doctor with a trigger from this version on, the exclusion
+-- constraint of 0013 already does so here and is only described
+COMMENT ON CONSTRAINT visits_no_double_booking ON public.visits IS 'two active visits of a doctor never overlap';
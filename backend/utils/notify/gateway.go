@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Gateway delivers SMS through a generic HTTP gateway. The recipient and text are sent in the
+// fields named by the config together with the static params, as JSON for POST and as query
+// parameters for GET. Any 2xx status is a successful delivery
+type Gateway struct {
+	url         string
+	method      string
+	headers     map[string]string
+	toField     string
+	textField   string
+	senderField string
+	sender      string
+	params      map[string]string
+	client      *http.Client
+}
+
+// NewGateway creates an SMS channel, the fields default to "to" and "text"
+func NewGateway(config Config) (*Gateway, error) {
+	if _, err := url.ParseRequestURI(config.URL); err != nil {
+		return nil, fmt.Errorf("sms gateway url: %w", err)
+	}
+	g := &Gateway{
+		url:         config.URL,
+		method:      strings.ToUpper(config.Method),
+		headers:     config.Headers,
+		toField:     config.ToField,
+		textField:   config.TextField,
+		senderField: config.SenderField,
+		sender:      config.From,
+		params:      config.Params,
+		client:      &http.Client{Timeout: config.timeout()},
+	}
+	if g.method == "" {
+		g.method = http.MethodPost
+	}
+	if g.method != http.MethodPost && g.method != http.MethodGet {
+		return nil, fmt.Errorf("sms gateway method must be GET or POST")
+	}
+	if g.toField == "" {
+		g.toField = "to"
+	}
+	if g.textField == "" {
+		g.textField = "text"
+	}
+	return g, nil
+}
+
+// Name returns the name of the channel
+func (g *Gateway) Name() string {
+	return SMS
+}
+
+func (g *Gateway) fields(msg Message) map[string]string {
+	fields := map[string]string{}
+	for k, v := range g.params {
+		fields[k] = v
+	}
+	if g.senderField != "" && g.sender != "" {
+		fields[g.senderField] = g.sender
+	}
+	fields[g.toField] = msg.To
+	fields[g.textField] = msg.Body
+	return fields
+}
+
+// Send posts the message to the gateway
+func (g *Gateway) Send(ctx context.Context, msg Message) error {
+	fields := g.fields(msg)
+	var req *http.Request
+	var err error
+	if g.method == http.MethodGet {
+		query := url.Values{}
+		for k, v := range fields {
+			query.Set(k, v)
+		}
+		target := g.url
+		if strings.Contains(target, "?") {
+			target += "&" + query.Encode()
+		} else {
+			target += "?" + query.Encode()
+		}
+		req, err = http.NewRequestWithContext(ctx, g.method, target, nil)
+	} else {
+		body, _ := json.Marshal(fields)
+		req, err = http.NewRequestWithContext(ctx, g.method, g.url, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for k, v := range g.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sms gateway returned %s: %s", resp.Status, strings.TrimSpace(string(reply)))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recorded is a request the test gateway received
+type recorded struct {
+	method string
+	header http.Header
+	query  map[string]string
+	body   map[string]string
+}
+
+// gateway starts a test SMS gateway answering with status and reply, requests go to got
+func gateway(t *testing.T, status int, reply string, got *recorded) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.header = r.Method, r.Header.Clone()
+		got.query = map[string]string{}
+		for k := range r.URL.Query() {
+			got.query[k] = r.URL.Query().Get(k)
+		}
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&got.body); err != nil {
+				t.Errorf("gateway body is not JSON: %v", err)
+			}
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGatewaySend(t *testing.T) {
+	msg := Message{To: "+989121234567", Body: "یادآوری نوبت"}
+	t.Run("post", func(t *testing.T) {
+		var got recorded
+		server := gateway(t, http.StatusAccepted, "queued", &got)
+		g, err := NewGateway(Config{
+			URL:         server.URL + "/send",
+			Headers:     map[string]string{"Authorization": "Bearer key"},
+			SenderField: "from",
+			From:        "3000",
+			Params:      map[string]string{"type": "text"},
+		})
+		if err != nil {
+			t.Fatalf("NewGateway() error = %v", err)
+		}
+		if err := g.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		want := map[string]string{"to": msg.To, "text": msg.Body, "from": "3000", "type": "text"}
+		if got.method != http.MethodPost || !reflect.DeepEqual(got.body, want) {
+			t.Errorf("gateway received %s %v, want POST %v", got.method, got.body, want)
+		}
+		if got.header.Get("Authorization") != "Bearer key" || got.header.Get("Content-Type") != "application/json" {
+			t.Errorf("gateway headers = %v, want the configured header and JSON", got.header)
+		}
+	})
+	t.Run("get", func(t *testing.T) {
+		var got recorded
+		server := gateway(t, http.StatusOK, "", &got)
+		g, err := NewGateway(Config{URL: server.URL + "/send?key=k", Method: "get", ToField: "receptor", TextField: "message"})
+		if err != nil {
+			t.Fatalf("NewGateway() error = %v", err)
+		}
+		if err := g.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		want := map[string]string{"key": "k", "receptor": msg.To, "message": msg.Body}
+		if got.method != http.MethodGet || !reflect.DeepEqual(got.query, want) {
+			t.Errorf("gateway received %s %v, want GET %v", got.method, got.query, want)
+		}
+	})
+	t.Run("error status", func(t *testing.T) {
+		var got recorded
+		server := gateway(t, http.StatusUnauthorized, " invalid api key\n", &got)
+		g, err := NewGateway(Config{URL: server.URL})
+		if err != nil {
+			t.Fatalf("NewGateway() error = %v", err)
+		}
+		err = g.Send(context.Background(), msg)
+		if err == nil || !strings.Contains(err.Error(), "401") || !strings.HasSuffix(err.Error(), ": invalid api key") {
+			t.Errorf("Send() error = %v, want the status and the trimmed reply", err)
+		}
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		g, err := NewGateway(Config{URL: server.URL})
+		if err != nil {
+			t.Fatalf("NewGateway() error = %v", err)
+		}
+		if err := g.Send(context.Background(), msg); err == nil {
+			t.Errorf("Send() to a closed gateway succeeded, want an error")
+		}
+	})
+}
+
+func TestNewGatewayErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"no url", Config{}},
+		{"relative url", Config{URL: "sms/send"}},
+		{"method", Config{URL: "https://sms.example.com/send", Method: "PUT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGateway(tt.config); err == nil {
+				t.Errorf("NewGateway() succeeded, want an error")
+			}
+		})
+	}
+}
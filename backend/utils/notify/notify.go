@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Kinds of reminders
+const (
+	Appointment = "appointment"
+	FollowUp    = "follow_up"
+	Therapy     = "therapy"
+)
+
+// Delivery statuses of a notification, sending is held by one dispatcher at a time and
+// cancelled reminders were for an appointment or therapy that no longer takes place
+const (
+	Pending   = "pending"
+	Sending   = "sending"
+	Sent      = "sent"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+)
+
+// Channel names
+const (
+	SMS   = "sms"
+	Email = "email"
+)
+
+// Languages of the templates
+const (
+	Persian = "fa"
+	English = "en"
+)
+
+// DefaultMaxAttempts is the number of times a notification is tried before it fails for good
+const DefaultMaxAttempts = 5
+
+// Message is a rendered notification for one recipient, Subject is only used by email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Channel delivers messages, Send must return when ctx is done
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Backoff is the wait before the next attempt after attempt failed ones: 1, 4, 16 and 64 minutes, at most 6 hours
+func Backoff(attempts int) time.Duration {
+	wait := time.Minute
+	for i := 1; i < attempts && wait < 6*time.Hour; i++ {
+		wait *= 4
+	}
+	if wait > 6*time.Hour {
+		wait = 6 * time.Hour
+	}
+	return wait
+}
+
+// Log is a channel that only logs its messages, for development and tests
+type Log struct {
+	Channel string
+}
+
+// Name returns the channel the sink stands in for
+func (l Log) Name() string {
+	return l.Channel
+}
+
+// Send logs the message
+func (l Log) Send(_ context.Context, msg Message) error {
+	log.Printf("notify %s to %s: %s %s", l.Channel, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// Config selects and configures the driver of a channel, none disables it
+type Config struct {
+	Driver         string            `yaml:"driver"`
+	TimeoutSeconds int               `yaml:"timeoutSeconds"`
+	Host           string            `yaml:"host"`
+	Port           int               `yaml:"port"`
+	Username       string            `yaml:"username"`
+	Password       string            `yaml:"password"`
+	From           string            `yaml:"from"`
+	URL            string            `yaml:"url"`
+	Method         string            `yaml:"method"`
+	Headers        map[string]string `yaml:"headers"`
+	ToField        string            `yaml:"toField"`
+	TextField      string            `yaml:"textField"`
+	SenderField    string            `yaml:"senderField"`
+	Params         map[string]string `yaml:"params"`
+}
+
+func (c Config) timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// New creates the channel name from its config, a nil channel is returned for the none driver
+func New(name string, config Config) (Channel, error) {
+	switch config.Driver {
+	case "", "none":
+		return nil, nil
+	case "log":
+		return Log{Channel: name}, nil
+	case "smtp":
+		if name != Email {
+			return nil, fmt.Errorf("smtp can only deliver %s", Email)
+		}
+		return NewSMTP(config)
+	case "http":
+		if name != SMS {
+			return nil, fmt.Errorf("the http gateway can only deliver %s", SMS)
+		}
+		return NewGateway(config)
+	}
+	return nil, fmt.Errorf("unknown %s driver %q", name, config.Driver)
+}
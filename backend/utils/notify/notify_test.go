@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 4 * time.Minute},
+		{3, 16 * time.Minute},
+		{4, 64 * time.Minute},
+		{5, 256 * time.Minute},
+		{6, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		config  Config
+		want    string
+		wantErr bool
+	}{
+		{"none", SMS, Config{Driver: "none"}, "", false},
+		{"empty driver", Email, Config{}, "", false},
+		{"log", SMS, Config{Driver: "log"}, SMS, false},
+		{"smtp", Email, Config{Driver: "smtp", Host: "mail.example.com", From: "clinic@example.com"}, Email, false},
+		{"smtp for sms", SMS, Config{Driver: "smtp", Host: "mail.example.com", From: "clinic@example.com"}, "", true},
+		{"http", SMS, Config{Driver: "http", URL: "https://sms.example.com/send"}, SMS, false},
+		{"http for email", Email, Config{Driver: "http", URL: "https://sms.example.com/send"}, "", true},
+		{"unknown", SMS, Config{Driver: "pigeon"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.channel, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, want error %v", err, tt.wantErr)
+			}
+			name := ""
+			if got != nil {
+				name = got.Name()
+			}
+			if name != tt.want {
+				t.Errorf("New() channel = %q, want %q", name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestNewSMTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		wantAddress string
+		wantErr     bool
+	}{
+		{"default port", Config{Host: "mail.example.com", From: "Clinic <clinic@example.com>"}, "mail.example.com:587", false},
+		{"port", Config{Host: "mail.example.com", Port: 25, From: "clinic@example.com"}, "mail.example.com:25", false},
+		{"no host", Config{From: "clinic@example.com"}, "", true},
+		{"bad from", Config{Host: "mail.example.com", From: "clinic"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSMTP(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSMTP() error = %v, want error %v", err, tt.wantErr)
+			}
+			if s != nil && s.address != tt.wantAddress {
+				t.Errorf("NewSMTP() address = %q, want %q", s.address, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	s, err := NewSMTP(Config{Host: "mail.example.com", From: "Clinic <clinic@example.com>"})
+	if err != nil {
+		t.Fatalf("NewSMTP() error = %v", err)
+	}
+	to := &mail.Address{Name: "Sara", Address: "sara@example.com"}
+	body := strings.Repeat("سارا عزیز، نوبت شما فردا است. ", 5)
+	raw := string(s.compose(to, Message{To: to.Address, Subject: "یادآوری نوبت", Body: body}))
+
+	header, encoded, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("compose() has no blank line between header and body:\n%s", raw)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(header + "\r\n\r\n"))
+	if err != nil {
+		t.Fatalf("compose() header does not parse: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("Subject %q does not decode: %v", msg.Header.Get("Subject"), err)
+	}
+	wantHeaders := map[string]string{
+		"From":                      `"Clinic" <clinic@example.com>`,
+		"To":                        `"Sara" <sara@example.com>`,
+		"Mime-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "base64",
+	}
+	for name, want := range wantHeaders {
+		if got := msg.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if subject != "یادآوری نوبت" {
+		t.Errorf("Subject = %q, want the persian subject", subject)
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date does not parse: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(encoded, "\r\n"), "\r\n")
+	for _, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("body line of %d characters, want at most 76", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatalf("body does not decode: %v", err)
+	}
+	if string(decoded) != body {
+		t.Errorf("body = %q, want %q", decoded, body)
+	}
+}
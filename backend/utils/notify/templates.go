@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"healthcare/utils/jalali"
+)
+
+// Data fills the templates of a reminder, Date and Time are formatted in the clinic time zone. When Due
+// is set Render writes them from it, Persian texts get the Jalali date
+type Data struct {
+	Patient string
+	Doctor  string
+	Date    string
+	Time    string
+	Therapy string
+	Clinic  string
+	Due     time.Time
+}
+
+// Template is the text of a reminder in one language
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// DefaultTemplates are the built-in reminder texts per kind and language
+var DefaultTemplates = map[string]map[string]Template{
+	Appointment: {
+		Persian: {
+			Subject: "یادآوری نوبت",
+			Body:    "{{.Patient}} عزیز، نوبت شما نزد {{.Doctor}} در تاریخ {{.Date}} ساعت {{.Time}} است.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+		English: {
+			Subject: "Appointment reminder",
+			Body:    "Dear {{.Patient}}, your appointment with {{.Doctor}} is on {{.Date}} at {{.Time}}.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+	},
+	FollowUp: {
+		Persian: {
+			Subject: "یادآوری مراجعه پیگیری",
+			Body:    "{{.Patient}} عزیز، زمان مراجعه پیگیری شما نزد {{.Doctor}} در تاریخ {{.Date}} است.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+		English: {
+			Subject: "Follow-up reminder",
+			Body:    "Dear {{.Patient}}, your follow-up visit with {{.Doctor}} is due on {{.Date}}.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+	},
+	Therapy: {
+		Persian: {
+			Subject: "یادآوری جلسه درمان",
+			Body:    "{{.Patient}} عزیز، جلسه {{.Therapy}} شما در تاریخ {{.Date}} است.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+		English: {
+			Subject: "Therapy session reminder",
+			Body:    "Dear {{.Patient}}, your {{.Therapy}} session is on {{.Date}}.{{if .Clinic}} {{.Clinic}}{{end}}",
+		},
+	},
+}
+
+type parsed struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// Templates renders reminders, a missing language falls back to the default language
+type Templates struct {
+	fallback string
+	texts    map[string]map[string]parsed
+}
+
+// NewTemplates parses the texts over the defaults, fallback is the language used when a
+// recipient has none or one without a text
+func NewTemplates(texts map[string]map[string]Template, fallback string) (*Templates, error) {
+	if fallback == "" {
+		fallback = Persian
+	}
+	t := &Templates{fallback: fallback, texts: map[string]map[string]parsed{}}
+	for _, set := range []map[string]map[string]Template{DefaultTemplates, texts} {
+		for kind, languages := range set {
+			if t.texts[kind] == nil {
+				t.texts[kind] = map[string]parsed{}
+			}
+			for language, text := range languages {
+				name := kind + "." + language
+				subject, err := template.New(name + ".subject").Parse(text.Subject)
+				if err != nil {
+					return nil, err
+				}
+				body, err := template.New(name + ".body").Parse(text.Body)
+				if err != nil {
+					return nil, err
+				}
+				t.texts[kind][language] = parsed{subject: subject, body: body}
+			}
+		}
+	}
+	for kind := range t.texts {
+		if _, ok := t.texts[kind][fallback]; !ok {
+			return nil, fmt.Errorf("reminder %s has no %s text", kind, fallback)
+		}
+	}
+	return t, nil
+}
+
+// LoadTemplates reads texts from a JSON file mapping kind and language to a subject and body,
+// kinds and languages missing from the file keep their defaults
+func LoadTemplates(path, fallback string) (*Templates, error) {
+	if path == "" {
+		return NewTemplates(nil, fallback)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var texts map[string]map[string]Template
+	if err = json.Unmarshal(data, &texts); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewTemplates(texts, fallback)
+}
+
+// Render renders the reminder of kind in language for the recipient
+func (t *Templates) Render(kind, language, to string, data Data) (Message, error) {
+	languages, ok := t.texts[kind]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown reminder %q", kind)
+	}
+	language = strings.ToLower(language)
+	text, ok := languages[language]
+	if !ok {
+		language, text = t.fallback, languages[t.fallback]
+	}
+	if !data.Due.IsZero() {
+		calendar := jalali.Gregorian
+		if language == Persian {
+			calendar = jalali.Jalali
+		}
+		data.Date, data.Time = jalali.FormatDate(calendar, data.Due), data.Due.Format("15:04")
+	}
+	var subject, body bytes.Buffer
+	if err := text.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := text.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
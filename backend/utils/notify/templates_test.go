@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	templates, err := NewTemplates(map[string]map[string]Template{
+		FollowUp: {English: {Subject: "Follow-up", Body: "{{.Patient}} on {{.Date}}"}},
+	}, Persian)
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+	due := time.Date(2024, 10, 19, 9, 30, 0, 0, tehran)
+	data := Data{Patient: "Sara", Doctor: "Dr. Ahmadi", Therapy: "physiotherapy", Clinic: "Charity Clinic", Due: due}
+	tests := []struct {
+		name        string
+		kind        string
+		language    string
+		wantSubject string
+		wantBody    string
+	}{
+		{"english appointment", Appointment, "EN", "Appointment reminder",
+			"Dear Sara, your appointment with Dr. Ahmadi is on 2024-10-19 at 09:30. Charity Clinic"},
+		{"persian appointment has the jalali date", Appointment, Persian, "یادآوری نوبت",
+			"Sara عزیز، نوبت شما نزد Dr. Ahmadi در تاریخ 1403-07-28 ساعت 09:30 است. Charity Clinic"},
+		{"unknown language falls back to persian", Therapy, "de", "یادآوری جلسه درمان",
+			"Sara عزیز، جلسه physiotherapy شما در تاریخ 1403-07-28 است. Charity Clinic"},
+		{"configured text replaces the default", FollowUp, English, "Follow-up", "Sara on 2024-10-19"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := templates.Render(tt.kind, tt.language, "+989121234567", data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if msg.To != "+989121234567" || msg.Subject != tt.wantSubject || msg.Body != tt.wantBody {
+				t.Errorf("Render() = %+v, want subject %q and body %q", msg, tt.wantSubject, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRenderWithoutDue(t *testing.T) {
+	templates, err := NewTemplates(nil, English)
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+	msg, err := templates.Render(Therapy, English, "sara@example.com", Data{Patient: "Sara", Therapy: "physiotherapy", Date: "2024-10-19"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "Dear Sara, your physiotherapy session is on 2024-10-19."; msg.Body != want {
+		t.Errorf("Render() body = %q, want %q", msg.Body, want)
+	}
+	if _, err := templates.Render("birthday", English, "sara@example.com", Data{}); err == nil {
+		t.Errorf("Render() of an unknown kind succeeded, want an error")
+	}
+}
+
+func TestNewTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		texts    map[string]map[string]Template
+		fallback string
+	}{
+		{"bad template", map[string]map[string]Template{Appointment: {English: {Body: "{{.Patient"}}}, Persian},
+		{"kind without the fallback language", map[string]map[string]Template{"birthday": {English: {Body: "Happy birthday"}}}, Persian},
+		{"fallback language without texts", nil, "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTemplates(tt.texts, tt.fallback); err == nil {
+				t.Errorf("NewTemplates() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package paging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query parameters of the page of a list, they are the ones the data provider of the frontend sends,
+// _start is inclusive and _end exclusive
+const (
+	StartParam = "_start"
+	EndParam   = "_end"
+	SortParam  = "_sort"
+	OrderParam = "_order"
+)
+
+// TotalHeader carries the number of items of a list before it is cut to the page
+const TotalHeader = "X-Total-Count"
+
+// Key is a field a list is sorted by
+type Key struct {
+	Field string
+	Desc  bool
+}
+
+// Window is the page of a list a request asks for, End is -1 when the list is not cut
+type Window struct {
+	Start int
+	End   int
+	Sort  []Key
+}
+
+// Parse reads the window of a request, a request without the parameters gets the whole list, each
+// _sort may be followed by its _order, asc or desc
+func Parse(query url.Values) (Window, error) {
+	w := Window{End: -1}
+	var err error
+	if value := query.Get(StartParam); value != "" {
+		if w.Start, err = strconv.Atoi(value); err != nil || w.Start < 0 {
+			return w, fmt.Errorf("%s must be a number from 0", StartParam)
+		}
+	}
+	if value := query.Get(EndParam); value != "" {
+		if w.End, err = strconv.Atoi(value); err != nil || w.End < w.Start {
+			return w, fmt.Errorf("%s must be a number from %s", EndParam, StartParam)
+		}
+	}
+	orders := query[OrderParam]
+	for i, field := range query[SortParam] {
+		if field == "" {
+			continue
+		}
+		key := Key{Field: field}
+		if i < len(orders) {
+			switch strings.ToLower(orders[i]) {
+			case "", "asc":
+			case "desc":
+				key.Desc = true
+			default:
+				return w, fmt.Errorf("%s must be asc or desc", OrderParam)
+			}
+		}
+		w.Sort = append(w.Sort, key)
+	}
+	return w, nil
+}
+
+// Params are the window parameters bound from the query of a request, handlers that page in their
+// query embed them in their request
+type Params struct {
+	Start string   `json:"-" form:"_start"`
+	End   string   `json:"-" form:"_end"`
+	Sort  []string `json:"-" form:"_sort"`
+	Order []string `json:"-" form:"_order"`
+}
+
+// Clause parses the window of the params and returns its clause, see Window.Clause
+func (p Params) Clause(columns map[string]string, order string) (string, error) {
+	w, err := Parse(url.Values{StartParam: {p.Start}, EndParam: {p.End}, SortParam: p.Sort, OrderParam: p.Order})
+	if err != nil {
+		return "", err
+	}
+	return w.Clause(columns, order)
+}
+
+// Clause returns the ORDER BY and OFFSET FETCH clause of the window, written alike for PostgreSQL
+// and Oracle. columns maps the fields a list may be sorted by to the columns of its query and order
+// follows the requested keys, it ends with a unique column so pages do not overlap
+func (w Window) Clause(columns map[string]string, order string) (string, error) {
+	keys := make([]string, 0, len(w.Sort)+1)
+	for _, key := range w.Sort {
+		column, ok := columns[key.Field]
+		if !ok {
+			return "", fmt.Errorf("the list can not be sorted by %s", key.Field)
+		}
+		if key.Desc {
+			column += " DESC"
+		}
+		keys = append(keys, column)
+	}
+	clause := "\n ORDER BY " + strings.Join(append(keys, order), ", ")
+	if w.Start > 0 || w.End >= 0 {
+		clause += fmt.Sprintf("\n OFFSET %d ROWS", w.Start)
+	}
+	if w.End >= 0 {
+		clause += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", w.End-w.Start)
+	}
+	return clause, nil
+}
+
+// Page is a list a handler has sorted and cut to its window in the query, the paging middleware
+// sends Items as the result and Total in the X-Total-Count header instead of paging it again
+type Page[T any] struct {
+	Items []T `json:"_items"`
+	Total int `json:"_total"`
+}
+
+// Paged returns the items and total of a decoded Page, ok is false for any other value
+func Paged(result any) (items []any, total json.Number, ok bool) {
+	page, isObject := result.(map[string]any)
+	if !isObject || len(page) != 2 {
+		return nil, "", false
+	}
+	total, ok = page["_total"].(json.Number)
+	list, found := page["_items"]
+	if !ok || !found {
+		return nil, "", false
+	}
+	switch list := list.(type) {
+	case []any:
+		return list, total, true
+	case nil:
+		return []any{}, total, true
+	}
+	return nil, "", false
+}
+
+// Apply sorts items, the objects of a decoded JSON array, and cuts them to the window
+func (w Window) Apply(items []any) []any {
+	if len(w.Sort) > 0 {
+		sort.SliceStable(items, func(i, j int) bool {
+			for _, key := range w.Sort {
+				c := compare(field(items[i], key.Field), field(items[j], key.Field))
+				if c == 0 {
+					continue
+				}
+				if key.Desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	start, end := w.Start, w.End
+	if start > len(items) {
+		start = len(items)
+	}
+	if end < 0 || end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// field returns the value of the field of item, dots reach into nested objects
+func field(item any, name string) any {
+	for _, part := range strings.Split(name, ".") {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		item = object[part]
+	}
+	return item
+}
+
+// rank orders values of different types: missing and null values first, then booleans, numbers and strings
+func rank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case json.Number, float64:
+		return 2
+	case string:
+		return 3
+	}
+	return 4
+}
+
+func number(v any) float64 {
+	switch n := v.(type) {
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// compare orders two JSON values, dates are written in RFC 3339 and sort as strings
+func compare(a, b any) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch ra {
+	case 1:
+		switch {
+		case a == b:
+			return 0
+		case a == false:
+			return -1
+		}
+		return 1
+	case 2:
+		x, y := number(a), number(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	}
+	return 0
+}
@@ -0,0 +1,191 @@
+package paging
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    Window
+		wantErr bool
+	}{
+		{"", Window{End: -1}, false},
+		{"_start=10&_end=20", Window{Start: 10, End: 20}, false},
+		{"_start=5", Window{Start: 5, End: -1}, false},
+		{"_sort=visit_date&_order=DESC&_sort=id", Window{End: -1, Sort: []Key{{"visit_date", true}, {"id", false}}}, false},
+		{"_sort=name&_order=asc&_sort=&_order=desc", Window{End: -1, Sort: []Key{{"name", false}}}, false},
+		{"_start=-1", Window{}, true},
+		{"_start=x", Window{}, true},
+		{"_start=10&_end=5", Window{}, true},
+		{"_sort=name&_order=up", Window{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// items decodes a JSON array the way the handlers do, numbers stay json.Number
+func items(t *testing.T, data string) []any {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var out []any
+	if err := decoder.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func ids(list []any) []string {
+	out := []string{}
+	for _, item := range list {
+		out = append(out, item.(map[string]any)["id"].(string))
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	const data = `[
+		{"id": "a", "name": "Sara", "age": 30, "active": true, "visit": {"date": "2024-05-02"}},
+		{"id": "b", "name": "Ali", "age": 9, "active": false, "visit": {"date": "2024-05-01"}},
+		{"id": "c", "name": "Reza", "age": 30, "visit": null},
+		{"id": "d", "name": "Ali", "age": 45.5, "active": true, "visit": {"date": "2024-05-03"}}
+	]`
+	tests := []struct {
+		name   string
+		window Window
+		want   []string
+	}{
+		{"whole list", Window{End: -1}, []string{"a", "b", "c", "d"}},
+		{"page", Window{Start: 1, End: 3}, []string{"b", "c"}},
+		{"page past the end", Window{Start: 3, End: 10}, []string{"d"}},
+		{"start past the end", Window{Start: 10, End: 20}, []string{}},
+		{"numbers", Window{End: -1, Sort: []Key{{Field: "age"}}}, []string{"b", "a", "c", "d"}},
+		{"descending then ascending", Window{End: -1, Sort: []Key{{"name", false}, {"age", true}}}, []string{"d", "b", "c", "a"}},
+		{"missing first", Window{End: -1, Sort: []Key{{Field: "active"}}}, []string{"c", "b", "a", "d"}},
+		{"nested", Window{End: -1, Sort: []Key{{"visit.date", true}}}, []string{"d", "a", "b", "c"}},
+		{"sorted page", Window{Start: 0, End: 2, Sort: []Key{{"age", true}}}, []string{"d", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.window.Apply(items(t, data))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClause(t *testing.T) {
+	columns := map[string]string{"visit_date": "v.visit_date", "status": "v.status"}
+	tests := []struct {
+		name    string
+		params  Params
+		want    string
+		wantErr bool
+	}{
+		{"whole list", Params{}, "\n ORDER BY v.id", false},
+		{"page", Params{Start: "10", End: "20"}, "\n ORDER BY v.id\n OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY", false},
+		{"from start", Params{Start: "5"}, "\n ORDER BY v.id\n OFFSET 5 ROWS", false},
+		{"sorted", Params{End: "10", Sort: []string{"status", "visit_date"}, Order: []string{"asc", "DESC"}},
+			"\n ORDER BY v.status, v.visit_date DESC, v.id\n OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", false},
+		{"unknown field", Params{Sort: []string{"v.id; DROP TABLE visits"}}, "", true},
+		{"invalid window", Params{Start: "10", End: "5"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.Clause(columns, "v.id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Clause() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Clause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaged(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   []string
+		total  json.Number
+		ok     bool
+	}{
+		{"page", `{"_items": [{"id": "a"}, {"id": "b"}], "_total": 12}`, []string{"a", "b"}, "12", true},
+		{"empty page", `{"_items": null, "_total": 0}`, []string{}, "0", true},
+		{"list", `[{"id": "a"}]`, nil, "", false},
+		{"object", `{"id": "a", "_total": 1}`, nil, "", false},
+		{"more fields", `{"_items": [], "_total": 0, "id": "a"}`, nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := json.NewDecoder(strings.NewReader(tt.result))
+			decoder.UseNumber()
+			var result any
+			if err := decoder.Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			items, total, ok := Paged(result)
+			if ok != tt.ok || total != tt.total {
+				t.Fatalf("Paged() = %v, %v, want %v, %v", total, ok, tt.total, tt.ok)
+			}
+			if got := ids(items); ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Paged() items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	body, err := json.Marshal(Page[map[string]string]{Items: []map[string]string{{"id": "a"}}, Total: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result any
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	if err = decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if items, total, ok := Paged(result); !ok || total != "3" || len(items) != 1 {
+		t.Errorf("Paged() of a marshalled Page = %v, %v, %v, want its item and total", items, total, ok)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b any
+		want int
+	}{
+		{nil, false, -1},
+		{false, true, -1},
+		{true, true, 0},
+		{true, json.Number("1"), -1},
+		{json.Number("10"), json.Number("9"), 1},
+		{json.Number("2.5"), 2.5, 0},
+		{json.Number("1"), "1", -1},
+		{"2024-05-01T10:00:00Z", "2024-05-01T09:00:00Z", 1},
+	}
+	for _, tt := range tests {
+		got := compare(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("compare(%v, %v) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
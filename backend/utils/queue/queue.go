@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Statuses of a queue entry: waiting entries are called in order, a called entry has an
+// in-progress visit, skipped entries can be requeued and cancelled entries left the queue
+const (
+	Waiting   = "waiting"
+	Called    = "called"
+	Skipped   = "skipped"
+	Cancelled = "cancelled"
+)
+
+// DefaultConsultMinutes is the estimated length of a consultation before enough patients were called
+const DefaultConsultMinutes = 10
+
+// maxSamples limits the estimate to the most recent consultations of the day
+const maxSamples = 10
+
+// Key identifies the queue of a doctor or a department, a department queue is served by all
+// doctors with that specialization
+func Key(doctorID, department string) (string, error) {
+	department = strings.ToLower(strings.TrimSpace(department))
+	switch {
+	case doctorID != "" && department != "":
+		return "", fmt.Errorf("a walk-in is queued for either a doctor or a department")
+	case doctorID != "":
+		return "doctor:" + doctorID, nil
+	case department != "":
+		return "department:" + department, nil
+	}
+	return "", fmt.Errorf("doctor_id or department is required")
+}
+
+// Day returns the clinic day of t, queue numbers restart every day
+func Day(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.DateOnly)
+}
+
+// CanSkip reports whether an entry in the status can be skipped
+func CanSkip(status string) bool {
+	return status == Waiting || status == Called
+}
+
+// CanRequeue reports whether an entry in the status can wait again
+func CanRequeue(status string) bool {
+	return status == Skipped
+}
+
+// ConsultMinutes estimates the length of a consultation from the times patients of a queue
+// were called, the gaps between consecutive calls of the day are averaged
+func ConsultMinutes(calls []time.Time, fallback int) float64 {
+	if fallback <= 0 {
+		fallback = DefaultConsultMinutes
+	}
+	if len(calls) < 2 {
+		return float64(fallback)
+	}
+	calls = append([]time.Time(nil), calls...)
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Before(calls[j]) })
+	if len(calls) > maxSamples+1 {
+		calls = calls[len(calls)-maxSamples-1:]
+	}
+	total := calls[len(calls)-1].Sub(calls[0])
+	return total.Minutes() / float64(len(calls)-1)
+}
+
+// EstimatedWait is the wait of a patient with ahead patients before them, rounded up to minutes
+func EstimatedWait(ahead int, consultMinutes float64) int {
+	if ahead <= 0 {
+		return 0
+	}
+	minutes := float64(ahead) * consultMinutes
+	wait := int(minutes)
+	if float64(wait) < minutes {
+		wait++
+	}
+	return wait
+}
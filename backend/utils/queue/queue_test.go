@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		doctorID, department string
+		want                 string
+		wantErr              bool
+	}{
+		{"d-1", "", "doctor:d-1", false},
+		{"", " Dentistry ", "department:dentistry", false},
+		{"d-1", "dentistry", "", true},
+		{"", "  ", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Key(tt.doctorID, tt.department)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Key(%q, %q) = %q, %v, want %q", tt.doctorID, tt.department, got, err, tt.want)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	// 22:00 UTC is already the next day in Tehran
+	if got := Day(time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC), tehran); got != "2024-05-02" {
+		t.Errorf("Day() = %s, want 2024-05-02", got)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	tests := []struct {
+		status        string
+		skip, requeue bool
+	}{
+		{Waiting, true, false},
+		{Called, true, false},
+		{Skipped, false, true},
+		{Cancelled, false, false},
+	}
+	for _, tt := range tests {
+		if skip, requeue := CanSkip(tt.status), CanRequeue(tt.status); skip != tt.skip || requeue != tt.requeue {
+			t.Errorf("%s: CanSkip() = %v, CanRequeue() = %v, want %v, %v", tt.status, skip, requeue, tt.skip, tt.requeue)
+		}
+	}
+}
+
+func TestConsultMinutes(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	minutes := func(offsets ...int) []time.Time {
+		calls := make([]time.Time, len(offsets))
+		for i, offset := range offsets {
+			calls[i] = start.Add(time.Duration(offset) * time.Minute)
+		}
+		return calls
+	}
+	tests := []struct {
+		name     string
+		calls    []time.Time
+		fallback int
+		want     float64
+	}{
+		{"no calls", nil, 15, 15},
+		{"one call", minutes(0), 0, DefaultConsultMinutes},
+		{"even gaps", minutes(0, 12, 24), 10, 12},
+		{"unordered", minutes(30, 0, 10), 10, 15},
+		{"recent calls only", minutes(0, 100, 105, 110, 115, 120, 125, 130, 135, 140, 145, 150), 10, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConsultMinutes(tt.calls, tt.fallback); got != tt.want {
+				t.Errorf("ConsultMinutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimatedWait(t *testing.T) {
+	tests := []struct {
+		ahead   int
+		consult float64
+		want    int
+	}{
+		{0, 10, 0},
+		{-1, 10, 0},
+		{3, 10, 30},
+		{3, 7.5, 23},
+		{2, 0.4, 1},
+	}
+	for _, tt := range tests {
+		if got := EstimatedWait(tt.ahead, tt.consult); got != tt.want {
+			t.Errorf("EstimatedWait(%d, %v) = %d, want %d", tt.ahead, tt.consult, got, tt.want)
+		}
+	}
+}
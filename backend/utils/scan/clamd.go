@@ -0,0 +1,142 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a whole clamd scan when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// chunkSize of INSTREAM, clamd rejects chunks larger than its StreamMaxLength
+const chunkSize = 64 << 10
+
+// Clamd streams files to a clamd daemon with the INSTREAM command
+type Clamd struct {
+	Network string
+	Address string
+	Timeout time.Duration
+}
+
+// NewClamd parses an address of the form tcp://host:port or unix:///path, a bare host:port is tcp
+func NewClamd(address string, timeout time.Duration) (*Clamd, error) {
+	if address == "" {
+		address = "tcp://localhost:3310"
+	}
+	network, addr, found := strings.Cut(address, "://")
+	if !found {
+		network, addr = "tcp", address
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("unsupported clamd address %q", address)
+	}
+	return &Clamd{Network: network, Address: addr, Timeout: timeout}, nil
+}
+
+func (c *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.Timeout}
+	conn, err := dialer.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(c.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// command sends a null terminated command and returns the reply without its terminator
+func (c *Clamd) command(ctx context.Context, command string, body func(io.Writer) error) (string, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("z" + command + "\x00")); err != nil {
+		return "", err
+	}
+	var writeErr error
+	if body != nil {
+		// clamd answers and closes the connection when the stream exceeds its limit,
+		// so the reply is read even when writing failed
+		writeErr = body(conn)
+	}
+	reply, err := io.ReadAll(conn)
+	if len(reply) == 0 {
+		if writeErr != nil {
+			return "", writeErr
+		}
+		if err != nil {
+			return "", err
+		}
+		return "", errors.New("empty reply from clamd")
+	}
+	return strings.TrimSpace(string(bytes.TrimRight(reply, "\x00"))), nil
+}
+
+// Ping checks that clamd is reachable
+func (c *Clamd) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected reply from clamd: %s", reply)
+	}
+	return nil
+}
+
+// Scan implements Scanner
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	reply, err := c.command(ctx, "INSTREAM", func(w io.Writer) error {
+		buf := make([]byte, 4+chunkSize)
+		for {
+			n, err := io.ReadFull(r, buf[4:])
+			if n > 0 {
+				binary.BigEndian.PutUint32(buf[:4], uint32(n))
+				if _, werr := w.Write(buf[:4+n]); werr != nil {
+					return werr
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		_, err := w.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return parseReply(reply)
+}
+
+// parseReply reads replies like "stream: OK" and "stream: Eicar-Test-Signature FOUND"
+func parseReply(reply string) (Result, error) {
+	_, verdict, found := strings.Cut(reply, ": ")
+	if !found {
+		verdict = reply
+	}
+	switch {
+	case verdict == "OK":
+		return Result{Status: Clean}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Status: Infected, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("clamd: %s", reply)
+}
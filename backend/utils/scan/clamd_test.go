@@ -0,0 +1,244 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClamd serves one connection at a time like clamd, reply decides the answer to a command
+// and its streamed data and chunks records the sizes of the INSTREAM chunks
+type fakeClamd struct {
+	listener net.Listener
+	reply    func(command string, data []byte) string
+	// limit rejects streams longer than so many bytes as clamd does past StreamMaxLength
+	limit int
+
+	mu     sync.Mutex
+	chunks []int
+	data   []byte
+}
+
+func startClamd(t *testing.T, reply func(command string, data []byte) string) *fakeClamd {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeClamd{listener: listener, reply: reply}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.handle(conn)
+	}
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	command, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+	command = strings.TrimSuffix(strings.TrimPrefix(command, "z"), "\x00")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.chunks, f.data = nil, nil
+	if command == "INSTREAM" {
+		for {
+			var size [4]byte
+			if _, err := io.ReadFull(r, size[:]); err != nil {
+				return
+			}
+			n := int(binary.BigEndian.Uint32(size[:]))
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return
+			}
+			f.chunks = append(f.chunks, n)
+			f.data = append(f.data, chunk...)
+			if f.limit > 0 && len(f.data) > f.limit {
+				// clamd answers at once, the rest of the stream is drained so the reply is not
+				// lost to a reset of the connection
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				conn.(*net.TCPConn).CloseWrite()
+				io.Copy(io.Discard, r)
+				return
+			}
+		}
+	}
+	if reply := f.reply(command, f.data); reply != "" {
+		conn.Write([]byte(reply + "\x00"))
+	}
+}
+
+func (f *fakeClamd) client(t *testing.T, timeout time.Duration) *Clamd {
+	t.Helper()
+	c, err := NewClamd("tcp://"+f.listener.Addr().String(), timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// clamdVerdict answers like clamd with its EICAR signature
+func clamdVerdict(command string, data []byte) string {
+	switch {
+	case command == "PING":
+		return "PONG"
+	case bytes.Contains(data, []byte(EICAR)):
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestNewClamd(t *testing.T) {
+	tests := []struct {
+		address     string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{"", "tcp", "localhost:3310", false},
+		{"tcp://clamav:3310", "tcp", "clamav:3310", false},
+		{"clamav:3310", "tcp", "clamav:3310", false},
+		{"unix:///run/clamav/clamd.ctl", "unix", "/run/clamav/clamd.ctl", false},
+		{"udp://clamav:3310", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			c, err := NewClamd(tt.address, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClamd(%q) error = %v, want error %v", tt.address, err, tt.wantErr)
+			}
+			if err == nil && (c.Network != tt.wantNetwork || c.Address != tt.wantAddress) {
+				t.Errorf("NewClamd(%q) = %s %s, want %s %s", tt.address, c.Network, c.Address, tt.wantNetwork, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		reply   string
+		want    Result
+		wantErr bool
+	}{
+		{"stream: OK", Result{Status: Clean}, false},
+		{"OK", Result{Status: Clean}, false},
+		{"stream: Eicar-Test-Signature FOUND", Result{Status: Infected, Signature: "Eicar-Test-Signature"}, false},
+		{"stream: Win.Test.EICAR_HDB-1 FOUND", Result{Status: Infected, Signature: "Win.Test.EICAR_HDB-1"}, false},
+		{"INSTREAM size limit exceeded. ERROR", Result{}, true},
+		{"stream: Can't allocate memory ERROR", Result{}, true},
+		{"", Result{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			got, err := parseReply(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseReply(%q) error = %v, want error %v", tt.reply, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseReply(%q) = %+v, want %+v", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClamdScan(t *testing.T) {
+	server := startClamd(t, clamdVerdict)
+	c := server.client(t, time.Second)
+	large := bytes.Repeat([]byte("a"), 2*chunkSize+10)
+	tests := []struct {
+		name       string
+		data       []byte
+		want       Result
+		wantChunks []int
+	}{
+		{"clean", []byte("hello"), Result{Status: Clean}, []int{5}},
+		{"eicar", []byte(EICAR), Result{Status: Infected, Signature: "Eicar-Test-Signature"}, []int{len(EICAR)}},
+		{"empty", nil, Result{Status: Clean}, nil},
+		{"chunked", large, Result{Status: Clean}, []int{chunkSize, chunkSize, 10}},
+		{"exactly one chunk", large[:chunkSize], Result{Status: Clean}, []int{chunkSize}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Scan(context.Background(), bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if !equalInts(server.chunks, tt.wantChunks) {
+				t.Errorf("INSTREAM chunks = %v, want %v", server.chunks, tt.wantChunks)
+			}
+			if !bytes.Equal(server.data, tt.data) {
+				t.Errorf("clamd received %d bytes, want %d", len(server.data), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestClamdErrors(t *testing.T) {
+	t.Run("stream limit", func(t *testing.T) {
+		server := startClamd(t, clamdVerdict)
+		server.limit = chunkSize
+		_, err := server.client(t, time.Second).Scan(context.Background(), bytes.NewReader(make([]byte, 8*chunkSize)))
+		if err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+			t.Errorf("Scan() error = %v, want the size limit reply", err)
+		}
+	})
+	t.Run("no reply", func(t *testing.T) {
+		server := startClamd(t, func(string, []byte) string { return "" })
+		if _, err := server.client(t, time.Second).Scan(context.Background(), strings.NewReader("x")); err == nil {
+			t.Error("Scan() without a reply succeeded")
+		}
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		server := startClamd(t, clamdVerdict)
+		c := server.client(t, time.Second)
+		server.listener.Close()
+		if err := c.Ping(context.Background()); err == nil {
+			t.Error("Ping() of a closed port succeeded")
+		}
+	})
+	t.Run("ping", func(t *testing.T) {
+		server := startClamd(t, clamdVerdict)
+		if err := server.client(t, time.Second).Ping(context.Background()); err != nil {
+			t.Errorf("Ping() error = %v", err)
+		}
+	})
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// EICAR is the standard anti-virus test file, every scanner reports it as infected
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// Fake is a scanner for development and tests, it reports files containing the EICAR
+// test string or one of Signatures as infected and returns Err when it is set
+type Fake struct {
+	// Signatures maps a content pattern to the name reported for it
+	Signatures map[string]string
+	Err        error
+}
+
+// Scan implements Scanner
+func (f Fake) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	if f.Err != nil {
+		return Result{}, f.Err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Result{}, err
+	}
+	if bytes.Contains(data, []byte(EICAR)) {
+		return Result{Status: Infected, Signature: "Eicar-Test-Signature"}, nil
+	}
+	for pattern, name := range f.Signatures {
+		if bytes.Contains(data, []byte(pattern)) {
+			return Result{Status: Infected, Signature: name}, nil
+		}
+	}
+	return Result{Status: Clean}, nil
+}
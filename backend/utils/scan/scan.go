@@ -0,0 +1,101 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+// Statuses recorded on uploaded files
+const (
+	Clean    = "clean"
+	Infected = "infected"
+	// Failed files could not be scanned and are quarantined until the rescan job of the upload
+	// handlers scans them again
+	Failed = "failed"
+	// Skipped files were stored while no scanner was configured
+	Skipped = "skipped"
+)
+
+// Downloadable reports whether files with the status may be served
+func Downloadable(status string) bool {
+	return status == Clean || status == Skipped
+}
+
+// Result is the verdict of a scanner, Signature names what was found in infected files
+type Result struct {
+	Status    string
+	Signature string
+}
+
+// Scanner screens the content of an uploaded file
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// Drivers
+const (
+	DriverNone  = "none"
+	DriverClamd = "clamd"
+	DriverFake  = "fake"
+)
+
+// Config selects and configures the scanner
+type Config struct {
+	Driver string `yaml:"driver"`
+	// Address of clamd, tcp://host:port or unix:///path/to/clamd.sock
+	Address        string `yaml:"address"`
+	TimeoutSeconds int    `yaml:"timeoutSeconds"`
+}
+
+// New builds the scanner of the configured driver, uploads are not scanned by default
+func New(config Config) (Scanner, error) {
+	switch config.Driver {
+	case "", DriverNone:
+		return None{}, nil
+	case DriverClamd:
+		timeout := time.Duration(config.TimeoutSeconds) * time.Second
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		return NewClamd(config.Address, timeout)
+	case DriverFake:
+		return Fake{}, nil
+	}
+	return nil, fmt.Errorf("unknown scanner driver %q", config.Driver)
+}
+
+// Check scans a file, errors of the scanner are logged and reported as Failed so the
+// file is quarantined instead of served unscanned
+func Check(ctx context.Context, scanner Scanner, r io.Reader) Result {
+	result, err := scanner.Scan(ctx, r)
+	if err != nil {
+		log.Println("error scanning upload", err)
+		return Result{Status: Failed}
+	}
+	return result
+}
+
+const quarantine = "quarantine/"
+
+// QuarantineKey is the storage key of a quarantined file, quarantined files are kept apart
+// from served files so a bug in a download path can not expose them
+func QuarantineKey(key string) string {
+	return quarantine + key
+}
+
+// ReleaseKey is the storage key a quarantined file is moved to once a rescan finds it clean
+func ReleaseKey(key string) string {
+	return strings.TrimPrefix(key, quarantine)
+}
+
+// None accepts every file without scanning it
+type None struct{}
+
+// Scan implements Scanner
+func (None) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{Status: Skipped}, nil
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCheck follows an upload through Check to where the handlers store it, files that are not
+// downloadable go under their quarantine key
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		scanner        Scanner
+		data           string
+		want           Result
+		wantQuarantine bool
+	}{
+		{"eicar", Fake{}, "prefix " + EICAR + " suffix", Result{Status: Infected, Signature: "Eicar-Test-Signature"}, true},
+		{"clean", Fake{}, "x-ray report", Result{Status: Clean}, false},
+		{"custom signature", Fake{Signatures: map[string]string{"MALWARE": "Test.Malware"}}, "MALWARE", Result{Status: Infected, Signature: "Test.Malware"}, true},
+		{"scanner down", Fake{Err: errors.New("connection refused")}, "x-ray report", Result{Status: Failed}, true},
+		{"no scanner", None{}, EICAR, Result{Status: Skipped}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Check(context.Background(), tt.scanner, strings.NewReader(tt.data))
+			if got != tt.want {
+				t.Fatalf("Check() = %+v, want %+v", got, tt.want)
+			}
+			key := "visits/1/2.png"
+			if !Downloadable(got.Status) {
+				key = QuarantineKey(key)
+			}
+			if quarantined := strings.HasPrefix(key, "quarantine/"); quarantined != tt.wantQuarantine {
+				t.Errorf("stored under %s, want quarantined %v", key, tt.wantQuarantine)
+			}
+			if released := ReleaseKey(key); released != "visits/1/2.png" {
+				t.Errorf("ReleaseKey(%s) = %s, want visits/1/2.png", key, released)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		driver  string
+		want    string
+		wantErr bool
+	}{
+		{"", "scan.None", false},
+		{DriverNone, "scan.None", false},
+		{DriverFake, "scan.Fake", false},
+		{DriverClamd, "*scan.Clamd", false},
+		{"virustotal", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			got, err := New(Config{Driver: tt.driver})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q) error = %v, want error %v", tt.driver, err, tt.wantErr)
+			}
+			if err == nil && typeName(got) != tt.want {
+				t.Errorf("New(%q) = %s, want %s", tt.driver, typeName(got), tt.want)
+			}
+		})
+	}
+	c, _ := New(Config{Driver: DriverClamd})
+	if c.(*Clamd).Timeout != DefaultTimeout {
+		t.Errorf("clamd timeout = %v, want %v", c.(*Clamd).Timeout, DefaultTimeout)
+	}
+}
+
+func typeName(s Scanner) string {
+	switch s.(type) {
+	case None:
+		return "scan.None"
+	case Fake:
+		return "scan.Fake"
+	case *Clamd:
+		return "*scan.Clamd"
+	}
+	return ""
+}
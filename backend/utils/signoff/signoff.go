@@ -0,0 +1,50 @@
+package signoff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Content is the clinical part of a visit note that is frozen by signing
+type Content struct {
+	ChiefComplaint        string
+	Symptoms              string
+	Diagnosis             string
+	TreatmentPlan         string
+	MedicationsPrescribed string
+	Notes                 string
+	FollowUpDate          time.Time
+}
+
+// Hash returns the hex encoded SHA-256 of the content,
+// fields are length prefixed so moving text between fields changes the hash
+func (c Content) Hash() string {
+	followUp := ""
+	if !c.FollowUpDate.IsZero() {
+		followUp = c.FollowUpDate.UTC().Format("2006-01-02")
+	}
+	return hash(c.ChiefComplaint, c.Symptoms, c.Diagnosis, c.TreatmentPlan,
+		c.MedicationsPrescribed, c.Notes, followUp)
+}
+
+// Verify reports whether hash is the hash of the content, it fails once the signed content is edited
+func (c Content) Verify(hash string) bool {
+	return hash != "" && c.Hash() == hash
+}
+
+// AddendumHash binds an addendum to the signed content it amends and to its author
+func AddendumHash(contentHash, authorID, text string) string {
+	return hash(contentHash, authorID, text)
+}
+
+func hash(fields ...string) string {
+	h := sha256.New()
+	for _, field := range fields {
+		h.Write([]byte(strconv.Itoa(len(field))))
+		h.Write([]byte{':'})
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
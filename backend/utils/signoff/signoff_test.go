@@ -0,0 +1,96 @@
+package signoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	tehran := time.FixedZone("Tehran", 12600)
+	tests := []struct {
+		name    string
+		content Content
+		want    string
+	}{
+		// SHA-256 of 5:cough0:7:J45.9090:0:0:10:2024-10-20
+		{"length prefixed fields", Content{ChiefComplaint: "cough", Diagnosis: "J45.909", FollowUpDate: time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)},
+			"6abad60f1cfc44dd9507bbed11e9cbbe9f2d0fdfbee800e6b0653981097bb338"},
+		// SHA-256 of 0:0:0:0:0:0:0:
+		{"empty", Content{}, "6db2d5a4b4209856d3249ada2dd5cebd32f82c1ad350481ed1e52524ec3efd80"},
+		{"follow up day in utc", Content{ChiefComplaint: "cough", Diagnosis: "J45.909", FollowUpDate: time.Date(2024, 10, 20, 3, 30, 0, 0, tehran)},
+			"6abad60f1cfc44dd9507bbed11e9cbbe9f2d0fdfbee800e6b0653981097bb338"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.content.Hash(); got != tt.want {
+				t.Errorf("Hash() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signed := Content{
+		ChiefComplaint:        "cough",
+		Symptoms:              "fever",
+		Diagnosis:             "J45.909",
+		TreatmentPlan:         "rest",
+		MedicationsPrescribed: "salbutamol",
+		Notes:                 "review in a week",
+		FollowUpDate:          time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC),
+	}
+	hash := signed.Hash()
+	if !signed.Verify(hash) {
+		t.Fatalf("Verify() = false for the signed content, want true")
+	}
+	if signed.Verify("") {
+		t.Errorf("Verify(\"\") = true, want false for content never signed")
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(c *Content)
+	}{
+		{"chief complaint", func(c *Content) { c.ChiefComplaint = "cough and wheeze" }},
+		{"symptoms", func(c *Content) { c.Symptoms = "" }},
+		{"diagnosis", func(c *Content) { c.Diagnosis = "J45.901" }},
+		{"treatment plan", func(c *Content) { c.TreatmentPlan += "." }},
+		{"medications", func(c *Content) { c.MedicationsPrescribed = "salbutamol 2 puffs" }},
+		{"notes", func(c *Content) { c.Notes = "Review in a week" }},
+		{"follow up date", func(c *Content) { c.FollowUpDate = c.FollowUpDate.AddDate(0, 0, 1) }},
+		{"follow up removed", func(c *Content) { c.FollowUpDate = time.Time{} }},
+		{"text moved between fields", func(c *Content) { c.ChiefComplaint, c.Symptoms = "coughfever", "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := signed
+			tt.tamper(&tampered)
+			if tampered.Verify(hash) {
+				t.Errorf("Verify() = true after editing the %s, want false", tt.name)
+			}
+		})
+	}
+}
+
+func TestAddendumHash(t *testing.T) {
+	hash := AddendumHash("content", "u-1", "corrected dose")
+	if hash != AddendumHash("content", "u-1", "corrected dose") {
+		t.Errorf("AddendumHash() is not deterministic")
+	}
+	tests := []struct {
+		name                 string
+		content, author, txt string
+	}{
+		{"other content", "content2", "u-1", "corrected dose"},
+		{"other author", "content", "u-2", "corrected dose"},
+		{"other text", "content", "u-1", "corrected dose."},
+		{"text moved into the author", "content", "u-1corrected", " dose"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if AddendumHash(tt.content, tt.author, tt.txt) == hash {
+				t.Errorf("AddendumHash() of the %s matches the original", tt.name)
+			}
+		})
+	}
+}
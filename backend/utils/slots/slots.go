@@ -0,0 +1,221 @@
+package slots
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of schedule exceptions, leave and holidays block time, extra hours add bookable time
+const (
+	Leave   = "leave"
+	Holiday = "holiday"
+	Extra   = "extra"
+)
+
+// ValidKind reports whether kind is a known exception kind
+func ValidKind(kind string) bool {
+	return kind == Leave || kind == Holiday || kind == Extra
+}
+
+// Limits of the length of a slot in minutes
+const (
+	MinSlotMinutes = 5
+	MaxSlotMinutes = 480
+)
+
+// Clock is a time of day in minutes after midnight
+type Clock int
+
+// ParseClock reads a time of day written as HH:MM, 24:00 is accepted as the end of the day
+func ParseClock(value string) (Clock, error) {
+	hour, minute, ok := strings.Cut(strings.TrimSpace(value), ":")
+	h, herr := strconv.Atoi(hour)
+	m, merr := strconv.Atoi(minute)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is not a time of day in HH:MM format", value)
+	}
+	return Clock(h*60 + m), nil
+}
+
+// String formats the clock as HH:MM
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+// on returns the instant of the clock on the day of date in loc
+func (c Clock) on(date time.Time, loc *time.Location) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, int(c)/60, int(c)%60, 0, 0, loc)
+}
+
+// Hours are the weekly working hours of a doctor on one weekday, split into slots of SlotMinutes
+type Hours struct {
+	Weekday     time.Weekday
+	Start       Clock
+	End         Clock
+	SlotMinutes int
+}
+
+// Exception changes the hours of a single date, a zero Start and End cover the whole day.
+// Extra exceptions need a range and SlotMinutes
+type Exception struct {
+	Date        time.Time
+	Start       Clock
+	End         Clock
+	Kind        string
+	SlotMinutes int
+}
+
+// wholeDay reports whether the exception has no time range
+func (e Exception) wholeDay() bool {
+	return e.Start == 0 && e.End == 0
+}
+
+// Interval is a half-open time range [Start, End)
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps reports whether the intervals share any instant
+func (i Interval) Overlaps(other Interval) bool {
+	return i.Start.Before(other.End) && other.Start.Before(i.End)
+}
+
+func checkRange(start, end Clock, slotMinutes int) error {
+	if start >= end {
+		return fmt.Errorf("start %s must be before end %s", start, end)
+	}
+	if slotMinutes < MinSlotMinutes || slotMinutes > MaxSlotMinutes {
+		return fmt.Errorf("slot length must be between %d and %d minutes", MinSlotMinutes, MaxSlotMinutes)
+	}
+	if int(end-start) < slotMinutes {
+		return fmt.Errorf("%s-%s is shorter than one slot of %d minutes", start, end, slotMinutes)
+	}
+	return nil
+}
+
+// ValidateHours rejects empty ranges and ranges that overlap on the same weekday
+func ValidateHours(hours []Hours) error {
+	sorted := append([]Hours(nil), hours...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weekday != sorted[j].Weekday {
+			return sorted[i].Weekday < sorted[j].Weekday
+		}
+		return sorted[i].Start < sorted[j].Start
+	})
+	for i, h := range sorted {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+			return fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if err := checkRange(h.Start, h.End, h.SlotMinutes); err != nil {
+			return fmt.Errorf("%s: %s", h.Weekday, err.Error())
+		}
+		if i > 0 && sorted[i-1].Weekday == h.Weekday && sorted[i-1].End > h.Start {
+			return fmt.Errorf("%s: %s-%s overlaps %s-%s", h.Weekday, sorted[i-1].Start, sorted[i-1].End, h.Start, h.End)
+		}
+	}
+	return nil
+}
+
+// ValidateException rejects unknown kinds and bad ranges
+func ValidateException(e Exception) error {
+	if !ValidKind(e.Kind) {
+		return fmt.Errorf("kind must be one of %s, %s, %s", Leave, Holiday, Extra)
+	}
+	if e.Kind == Extra {
+		return checkRange(e.Start, e.End, e.SlotMinutes)
+	}
+	if !e.wholeDay() && e.Start >= e.End {
+		return fmt.Errorf("start %s must be before end %s", e.Start, e.End)
+	}
+	return nil
+}
+
+// Generate returns the free slots of the days from up to, but not including, to in loc. Slots
+// come from the weekly hours and extra exceptions of each day, slots overlapping leave, holidays
+// or busy intervals and slots starting before notBefore are left out
+func Generate(loc *time.Location, from, to time.Time, hours []Hours, exceptions []Exception, busy []Interval, notBefore time.Time) []Interval {
+	free := []Interval{}
+	for day := dayOf(from, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		var blocked []Interval
+		type window struct {
+			start, end  Clock
+			slotMinutes int
+		}
+		var windows []window
+		for _, h := range hours {
+			if h.Weekday == day.Weekday() {
+				windows = append(windows, window{h.Start, h.End, h.SlotMinutes})
+			}
+		}
+		for _, e := range exceptions {
+			if !sameDay(e.Date, day) {
+				continue
+			}
+			switch {
+			case e.Kind == Extra:
+				windows = append(windows, window{e.Start, e.End, e.SlotMinutes})
+			case e.wholeDay():
+				blocked = append(blocked, Interval{day, day.AddDate(0, 0, 1)})
+			default:
+				blocked = append(blocked, Interval{e.Start.on(day, loc), e.End.on(day, loc)})
+			}
+		}
+
+		for _, w := range windows {
+			end := w.end.on(day, loc)
+			step := time.Duration(w.slotMinutes) * time.Minute
+			for start := w.start.on(day, loc); !start.Add(step).After(end); start = start.Add(step) {
+				slot := Interval{start, start.Add(step)}
+				if start.Before(notBefore) || overlapsAny(slot, blocked) || overlapsAny(slot, busy) {
+					continue
+				}
+				free = append(free, slot)
+			}
+		}
+	}
+	sort.Slice(free, func(i, j int) bool { return free[i].Start.Before(free[j].Start) })
+	// extra hours may repeat slots of the weekly hours
+	unique := free[:0]
+	for i, slot := range free {
+		if i == 0 || !slot.Start.Equal(free[i-1].Start) {
+			unique = append(unique, slot)
+		}
+	}
+	return unique
+}
+
+// Find returns the slot starting at start
+func Find(free []Interval, start time.Time) (Interval, bool) {
+	for _, slot := range free {
+		if slot.Start.Equal(start) {
+			return slot, true
+		}
+	}
+	return Interval{}, false
+}
+
+func overlapsAny(slot Interval, intervals []Interval) bool {
+	for _, interval := range intervals {
+		if slot.Overlaps(interval) {
+			return true
+		}
+	}
+	return false
+}
+
+// dayOf returns midnight of the day of t in loc
+func dayOf(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,168 @@
+package slots
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var tehran = time.FixedZone("Tehran", 12600)
+
+// monday is a Monday in tehran
+var monday = time.Date(2024, 5, 6, 0, 0, 0, 0, tehran)
+
+func at(day time.Time, clock string) time.Time {
+	c, err := ParseClock(clock)
+	if err != nil {
+		panic(err)
+	}
+	return c.on(day, tehran)
+}
+
+func starts(free []Interval) []string {
+	out := []string{}
+	for _, slot := range free {
+		out = append(out, slot.Start.In(tehran).Format("Mon 15:04")+"-"+slot.End.In(tehran).Format("15:04"))
+	}
+	return out
+}
+
+func TestGenerate(t *testing.T) {
+	morning := Hours{Weekday: time.Monday, Start: 9 * 60, End: 11 * 60, SlotMinutes: 30}
+	exception := func(kind, start, end string, slotMinutes int) Exception {
+		e := Exception{Date: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), Kind: kind, SlotMinutes: slotMinutes}
+		if start != "" {
+			e.Start, _ = ParseClock(start)
+			e.End, _ = ParseClock(end)
+		}
+		return e
+	}
+	tests := []struct {
+		name       string
+		to         time.Time
+		hours      []Hours
+		exceptions []Exception
+		busy       []Interval
+		notBefore  time.Time
+		want       []string
+	}{
+		{"weekly hours", monday.AddDate(0, 0, 1), []Hours{morning}, nil, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30", "Mon 10:30-11:00"}},
+		{"partial slot dropped", monday.AddDate(0, 0, 1), []Hours{{time.Monday, 9 * 60, 10*60 + 45, 30}}, nil, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30"}},
+		{"busy", monday.AddDate(0, 0, 1), []Hours{morning}, nil, []Interval{{at(monday, "09:15"), at(monday, "09:45")}}, time.Time{},
+			[]string{"Mon 10:00-10:30", "Mon 10:30-11:00"}},
+		{"busy until the start", monday.AddDate(0, 0, 1), []Hours{morning}, nil, []Interval{{at(monday, "08:00"), at(monday, "09:00")}}, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30", "Mon 10:30-11:00"}},
+		{"leave", monday.AddDate(0, 0, 1), []Hours{morning}, []Exception{exception(Leave, "10:00", "11:00", 0)}, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00"}},
+		{"holiday", monday.AddDate(0, 0, 1), []Hours{morning}, []Exception{exception(Holiday, "", "", 0)}, nil, time.Time{},
+			[]string{}},
+		{"extra hours", monday.AddDate(0, 0, 1), []Hours{morning}, []Exception{exception(Extra, "14:00", "15:00", 20)}, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30", "Mon 10:30-11:00", "Mon 14:00-14:20", "Mon 14:20-14:40", "Mon 14:40-15:00"}},
+		{"extra hours repeating weekly ones", monday.AddDate(0, 0, 1), []Hours{morning}, []Exception{exception(Extra, "09:00", "10:00", 30)}, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30", "Mon 10:30-11:00"}},
+		{"not before", monday.AddDate(0, 0, 1), []Hours{morning}, nil, nil, at(monday, "09:45"),
+			[]string{"Mon 10:00-10:30", "Mon 10:30-11:00"}},
+		{"several days", monday.AddDate(0, 0, 3),
+			[]Hours{{time.Thursday, 8 * 60, 9 * 60, 60}, {time.Wednesday, 8 * 60, 9 * 60, 60}, {time.Tuesday, 16 * 60, 17 * 60, 60}, {time.Monday, 12 * 60, 13 * 60, 60}}, nil, nil, time.Time{},
+			[]string{"Mon 12:00-13:00", "Tue 16:00-17:00", "Wed 08:00-09:00"}},
+		{"exception of another day", monday.AddDate(0, 0, 1), []Hours{morning},
+			[]Exception{{Date: time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC), Kind: Holiday}}, nil, time.Time{},
+			[]string{"Mon 09:00-09:30", "Mon 09:30-10:00", "Mon 10:00-10:30", "Mon 10:30-11:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := starts(Generate(tehran, monday, tt.to, tt.hours, tt.exceptions, tt.busy, tt.notBefore))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Generate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	free := Generate(tehran, monday, monday.AddDate(0, 0, 1), []Hours{{time.Monday, 9 * 60, 10 * 60, 30}}, nil, nil, time.Time{})
+	if slot, ok := Find(free, at(monday, "09:30").UTC()); !ok || !slot.End.Equal(at(monday, "10:00")) {
+		t.Errorf("Find(09:30) = %v, %v, want the 09:30 slot", slot, ok)
+	}
+	if _, ok := Find(free, at(monday, "09:15")); ok {
+		t.Error("Find(09:15) found a slot")
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Clock
+		wantErr bool
+	}{
+		{"09:30", 570, false},
+		{" 9:05 ", 545, false},
+		{"00:00", 0, false},
+		{"24:00", 1440, false},
+		{"24:30", 0, true},
+		{"25:00", 0, true},
+		{"12:60", 0, true},
+		{"-1:00", 0, true},
+		{"12", 0, true},
+		{"noon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseClock(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClock(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+	if got := Clock(545).String(); got != "09:05" {
+		t.Errorf("String() = %s, want 09:05", got)
+	}
+}
+
+func TestValidateHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   []Hours
+		wantErr bool
+	}{
+		{"adjacent", []Hours{{time.Monday, 12 * 60, 15 * 60, 30}, {time.Monday, 9 * 60, 12 * 60, 30}}, false},
+		{"other weekdays", []Hours{{time.Monday, 9 * 60, 12 * 60, 30}, {time.Tuesday, 10 * 60, 11 * 60, 15}}, false},
+		{"overlap", []Hours{{time.Monday, 11 * 60, 15 * 60, 30}, {time.Monday, 9 * 60, 12 * 60, 30}}, true},
+		{"empty range", []Hours{{time.Monday, 12 * 60, 12 * 60, 30}}, true},
+		{"shorter than a slot", []Hours{{time.Monday, 12 * 60, 12*60 + 20, 30}}, true},
+		{"short slots", []Hours{{time.Monday, 9 * 60, 12 * 60, 4}}, true},
+		{"long slots", []Hours{{time.Monday, 0, 24 * 60, 481}}, true},
+		{"weekday", []Hours{{7, 9 * 60, 12 * 60, 30}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHours(tt.hours); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHours() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateException(t *testing.T) {
+	tests := []struct {
+		name      string
+		exception Exception
+		wantErr   bool
+	}{
+		{"whole day leave", Exception{Kind: Leave}, false},
+		{"holiday", Exception{Kind: Holiday}, false},
+		{"leave range", Exception{Kind: Leave, Start: 9 * 60, End: 12 * 60}, false},
+		{"reversed leave", Exception{Kind: Leave, Start: 12 * 60, End: 9 * 60}, true},
+		{"extra hours", Exception{Kind: Extra, Start: 9 * 60, End: 12 * 60, SlotMinutes: 30}, false},
+		{"extra without slots", Exception{Kind: Extra, Start: 9 * 60, End: 12 * 60}, true},
+		{"extra whole day", Exception{Kind: Extra, SlotMinutes: 30}, true},
+		{"unknown kind", Exception{Kind: "sick"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateException(tt.exception); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateException() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"net/http"
+)
+
+// NewID returns a random UUID v4 for rows whose id is needed before insert, e.g. uploads
+// whose storage key contains it
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// DetectType returns the MIME type sniffed from the content instead of trusting the client
+func DetectType(data []byte) string {
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mimeType
+}
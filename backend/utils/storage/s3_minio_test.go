@@ -0,0 +1,86 @@
+//go:build minio
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMinIO stores, reads and deletes an object in the MinIO of docker-compose.yml, run it with
+//
+//	go test -tags minio ./utils/storage
+//
+// MINIO_ENDPOINT, MINIO_ACCESS_KEY and MINIO_SECRET_KEY point it to another server
+func TestMinIO(t *testing.T) {
+	s, err := NewS3(Config{
+		Endpoint:  env("MINIO_ENDPOINT", "http://localhost:9000"),
+		Bucket:    "healthcare-test",
+		AccessKey: env("MINIO_ACCESS_KEY", "minioadmin"),
+		SecretKey: env("MINIO_SECRET_KEY", "minioadmin"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	createBucket(t, s)
+
+	key := "visits/" + NewID() + "/a b+c.txt"
+	if err := s.Put(ctx, key, strings.NewReader("stored in minio"), 15, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "stored in minio" {
+		t.Errorf("Get() = %q", body)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a deleted object error = %v, want ErrNotFound", err)
+	}
+
+	wrong := *s
+	wrong.secretKey = "not-the-secret"
+	if err := wrong.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Error("Put() with a wrong secret succeeded")
+	}
+}
+
+// createBucket creates the bucket of s, a bucket left by an earlier run is reused
+func createBucket(t *testing.T, s *S3) {
+	t.Helper()
+	target := *s.endpoint
+	target.Path = "/" + s.bucket
+	req, err := http.NewRequest(http.MethodPut, target.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.sign(req, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		t.Fatalf("minio is not reachable: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		t.Fatalf("creating bucket %s: %s", s.bucket, resp.Status)
+	}
+}
+
+func env(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
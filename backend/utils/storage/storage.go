@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded files, keys are slash separated relative paths
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Drivers
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Config selects and configures the storage driver
+type Config struct {
+	Driver    string `yaml:"driver"`
+	LocalPath string `yaml:"localPath"`
+	// Endpoint of an S3 compatible service including the scheme, e.g. http://localhost:9000 for MinIO
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	// URLSecret signs download URLs and is required, see MinURLSecret
+	URLSecret     string `yaml:"urlSecret"`
+	URLTTLMinutes int    `yaml:"urlTTLMinutes"`
+}
+
+// New builds the storage of the configured driver, local storage is the default
+func New(config Config) (Storage, error) {
+	switch config.Driver {
+	case "", DriverLocal:
+		path := config.LocalPath
+		if path == "" {
+			path = "data/uploads"
+		}
+		return NewLocal(path)
+	case DriverS3:
+		return NewS3(config)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestNewURLSigner(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"empty", "", true},
+		{"short", "secret", true},
+		{"long enough", testSecret, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewURLSigner(tt.secret, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewURLSigner(%q) error = %v, want error %v", tt.secret, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	signer, err := NewURLSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewURLSigner(strings.ToUpper(testSecret), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	path := "/api/v1/visits/1/images/2/file"
+	signed, err := url.Parse(signer.Sign(path, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Path != path {
+		t.Fatalf("Sign() path = %q, want %q", signed.Path, path)
+	}
+	expires, signature := signed.Query().Get("expires"), signed.Query().Get("signature")
+
+	tests := []struct {
+		name      string
+		signer    URLSigner
+		path      string
+		expires   string
+		signature string
+		at        time.Time
+		want      bool
+	}{
+		{"valid", signer, path, expires, signature, now, true},
+		{"at expiry", signer, path, expires, signature, now.Add(time.Minute), true},
+		{"expired", signer, path, expires, signature, now.Add(time.Minute + time.Second), false},
+		{"other path", signer, "/api/v1/visits/1/images/3/file", expires, signature, now, false},
+		{"extended expiry", signer, path, "9999999999", signature, now, false},
+		{"bad expiry", signer, path, "soon", signature, now, false},
+		{"other secret", other, path, expires, signature, now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.signer.Verify(tt.path, tt.expires, tt.signature, tt.at); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
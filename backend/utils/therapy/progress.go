@@ -0,0 +1,233 @@
+package therapy
+
+import (
+	"sort"
+	"time"
+)
+
+// Statuses of a therapy schedule, a schedule is completed when all its sessions were attended and
+// expired when its last day passed before that
+const (
+	Active    = "active"
+	Completed = "completed"
+	Expired   = "expired"
+	Cancelled = "cancelled"
+)
+
+// Outcomes of a session
+const (
+	Attended = "attended"
+	Missed   = "missed"
+)
+
+// Policies for sessions falling on a clinic holiday: skip drops the session, next and previous move it
+// to the closest working day after or before it within the schedule
+const (
+	Skip     = "skip"
+	Next     = "next"
+	Previous = "previous"
+)
+
+// ValidPolicy reports whether policy is a known holiday policy
+func ValidPolicy(policy string) bool {
+	return policy == Skip || policy == Next || policy == Previous
+}
+
+// Plan is what progress is measured against. Rule defaults to the rule of Frequency, SessionCount and
+// End are optional and Holidays holds the clinic holidays as dates
+type Plan struct {
+	Frequency     string
+	Rule          *Rule
+	ExDates       []time.Time
+	HolidayPolicy string
+	Holidays      map[time.Time]bool
+	Start         time.Time
+	End           time.Time
+	SessionCount  int
+}
+
+// Session is a day of a schedule, Original is the day a session moved off a holiday came from
+type Session struct {
+	Date     time.Time  `json:"date"`
+	Original *time.Time `json:"original_date,omitempty"`
+}
+
+// Progress of a schedule on a day: Due sessions should have taken place by then, Percent is the share
+// of the planned sessions attended and Adherence the share of the due ones
+type Progress struct {
+	Planned   int     `json:"planned"`
+	Due       int     `json:"due"`
+	Completed int     `json:"completed"`
+	Missed    int     `json:"missed"`
+	Remaining int     `json:"remaining"`
+	Percent   float64 `json:"percent"`
+	Adherence float64 `json:"adherence"`
+}
+
+// date drops the time of t, schedules are kept by calendar day
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (p Plan) rule() *Rule {
+	if p.Rule != nil {
+		return p.Rule
+	}
+	return RuleFor(p.Frequency)
+}
+
+// Recurring reports whether the sessions of the plan follow a rule
+func (p Plan) Recurring() bool {
+	return p.rule() != nil
+}
+
+// Sessions returns the sessions of the plan up to and including to, after removing the excluded dates
+// and applying the holiday policy. A plan without a rule has no sessions
+func (p Plan) Sessions(to time.Time) []Session {
+	rule := p.rule()
+	if rule == nil {
+		return nil
+	}
+	start := date(p.Start)
+	last := date(to)
+	if !p.End.IsZero() && date(p.End).Before(last) {
+		last = date(p.End)
+	}
+	// a session moved back from a holiday after last may still fall before it
+	expandTo := last
+	if p.HolidayPolicy == Previous {
+		expandTo = last.AddDate(0, 0, 7)
+		if !p.End.IsZero() && date(p.End).Before(expandTo) {
+			expandTo = date(p.End)
+		}
+	}
+	excluded := map[time.Time]bool{}
+	for _, day := range p.ExDates {
+		excluded[date(day)] = true
+	}
+	days := rule.Expand(start, expandTo)
+	taken := map[time.Time]bool{}
+	for _, day := range days {
+		taken[day] = true
+	}
+	var sessions []Session
+	for _, day := range days {
+		if excluded[day] {
+			continue
+		}
+		if !p.Holidays[day] {
+			sessions = append(sessions, Session{Date: day})
+			continue
+		}
+		step := 0
+		switch p.HolidayPolicy {
+		case Next:
+			step = 1
+		case Previous:
+			step = -1
+		}
+		if step == 0 {
+			continue
+		}
+		moved := day.AddDate(0, 0, step)
+		for p.Holidays[moved] || excluded[moved] || taken[moved] {
+			moved = moved.AddDate(0, 0, step)
+		}
+		if moved.Before(start) || (!p.End.IsZero() && moved.After(date(p.End))) {
+			continue
+		}
+		original := day
+		taken[moved] = true
+		sessions = append(sessions, Session{Date: moved, Original: &original})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Date.Before(sessions[j].Date) })
+	n := len(sessions)
+	for n > 0 && sessions[n-1].Date.After(last) {
+		n--
+	}
+	return sessions[:n]
+}
+
+// horizon returns the day after which the plan has no sessions, the zero time when it never ends
+func (p Plan) horizon() time.Time {
+	if !p.End.IsZero() {
+		return p.End
+	}
+	if rule := p.rule(); rule != nil && rule.Finite() {
+		return date(p.Start).AddDate(MaxYears, 0, 0)
+	}
+	return time.Time{}
+}
+
+// Planned returns the number of sessions of the plan, from its session count or else its sessions
+func (p Plan) Planned() int {
+	if p.SessionCount > 0 {
+		return p.SessionCount
+	}
+	horizon := p.horizon()
+	if horizon.IsZero() {
+		return 0
+	}
+	return len(p.Sessions(horizon))
+}
+
+// LastDay returns the last day of the plan, its end date or else its last session, the zero time when
+// it never ends
+func (p Plan) LastDay() time.Time {
+	if !p.End.IsZero() {
+		return date(p.End)
+	}
+	horizon := p.horizon()
+	if horizon.IsZero() {
+		return time.Time{}
+	}
+	sessions := p.Sessions(horizon)
+	if len(sessions) == 0 {
+		return date(p.Start)
+	}
+	return sessions[len(sessions)-1].Date
+}
+
+// Compute returns the progress of the plan on today given the attended and missed sessions, the
+// sessions recorded stand in for the due ones when the plan follows no rule
+func Compute(p Plan, attended, missed int, today time.Time) Progress {
+	progress := Progress{Planned: p.Planned(), Completed: attended, Missed: missed}
+	due := attended + missed
+	if p.Recurring() {
+		due = len(p.Sessions(today))
+	}
+	if progress.Planned > 0 && due > progress.Planned {
+		due = progress.Planned
+	}
+	progress.Due = due
+	if progress.Planned > attended {
+		progress.Remaining = progress.Planned - attended
+	}
+	if progress.Planned > 0 {
+		progress.Percent = percent(attended, progress.Planned)
+	}
+	progress.Adherence = 100
+	if due > 0 {
+		progress.Adherence = percent(attended, due)
+	}
+	return progress
+}
+
+// percent returns part of whole in percent with one decimal, at most 100
+func percent(part, whole int) float64 {
+	if part >= whole {
+		return 100
+	}
+	return float64(part*1000/whole) / 10
+}
+
+// Status returns the status a schedule of the plan has on today, only active schedules change
+func Status(p Plan, attended int, today time.Time) string {
+	if planned := p.Planned(); planned > 0 && attended >= planned {
+		return Completed
+	}
+	if last := p.LastDay(); !last.IsZero() && last.Before(date(today)) {
+		return Expired
+	}
+	return Active
+}
@@ -0,0 +1,79 @@
+package therapy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name             string
+		plan             Plan
+		attended, missed int
+		today            string
+		want             Progress
+	}{
+		{"session count", Plan{Frequency: "weekly", Start: day("2024-05-01"), SessionCount: 10}, 2, 1, "2024-05-15",
+			Progress{Planned: 10, Due: 3, Completed: 2, Missed: 1, Remaining: 8, Percent: 20, Adherence: 66.6}},
+		{"end date", Plan{Frequency: "daily", Start: day("2024-05-01"), End: day("2024-05-10")}, 5, 0, "2024-05-05",
+			Progress{Planned: 10, Due: 5, Completed: 5, Remaining: 5, Percent: 50, Adherence: 100}},
+		{"due capped", Plan{Frequency: "weekly", Start: day("2024-05-01"), SessionCount: 2}, 1, 0, "2024-12-31",
+			Progress{Planned: 2, Due: 2, Completed: 1, Remaining: 1, Percent: 50, Adherence: 50}},
+		{"counted rule", Plan{Rule: &Rule{Freq: Weekly, Interval: 1, Count: 3, WeekStart: time.Monday}, Start: day("2024-05-01")}, 3, 0, "2024-06-30",
+			Progress{Planned: 3, Due: 3, Completed: 3, Percent: 100, Adherence: 100}},
+		{"no rhythm", Plan{Frequency: "as needed", Start: day("2024-05-01")}, 3, 1, "2024-05-31",
+			Progress{Due: 4, Completed: 3, Missed: 1, Adherence: 75}},
+		{"not started", Plan{Frequency: "weekly", Start: day("2024-05-01"), SessionCount: 4}, 0, 0, "2024-04-30",
+			Progress{Planned: 4, Remaining: 4, Adherence: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compute(tt.plan, tt.attended, tt.missed, day(tt.today)); got != tt.want {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	counted := &Rule{Freq: Weekly, Interval: 1, Count: 3, WeekStart: time.Monday}
+	tests := []struct {
+		name     string
+		plan     Plan
+		attended int
+		today    string
+		want     string
+	}{
+		{"all attended", Plan{Frequency: "weekly", Start: day("2024-05-01"), SessionCount: 10}, 10, "2024-05-02", Completed},
+		{"last day", Plan{Frequency: "daily", Start: day("2024-05-01"), End: day("2024-05-10")}, 3, "2024-05-10", Active},
+		{"past the end", Plan{Frequency: "daily", Start: day("2024-05-01"), End: day("2024-05-10")}, 3, "2024-05-11", Expired},
+		{"past the last session", Plan{Rule: counted, Start: day("2024-05-01")}, 2, "2024-05-16", Expired},
+		{"last session attended", Plan{Rule: counted, Start: day("2024-05-01")}, 3, "2024-05-16", Completed},
+		{"open ended", Plan{Frequency: "weekly", Start: day("2024-05-01")}, 40, "2030-01-01", Active},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.plan, tt.attended, day(tt.today)); got != tt.want {
+				t.Errorf("Status() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		part, whole int
+		want        float64
+	}{
+		{1, 3, 33.3},
+		{2, 3, 66.6},
+		{0, 7, 0},
+		{5, 4, 100},
+		{4, 4, 100},
+	}
+	for _, tt := range tests {
+		if got := percent(tt.part, tt.whole); got != tt.want {
+			t.Errorf("percent(%d, %d) = %v, want %v", tt.part, tt.whole, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package therapy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a recurrence rule
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// MaxYears bounds the expansion of a rule without an end
+const MaxYears = 5
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry, a non-zero Ordinal picks e.g. the first (1) or last (-1) weekday of the
+// month, or of the year for a yearly rule without BYMONTH
+type WeekdayNum struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// Rule is the date part of an RFC 5545 recurrence rule, sessions are whole days so time parts are ignored
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  time.Weekday
+}
+
+// legacy maps the loose frequencies schedules had before recurrence rules to a rule
+var legacy = map[string]string{
+	"daily":    "FREQ=DAILY",
+	"weekly":   "FREQ=WEEKLY",
+	"biweekly": "FREQ=WEEKLY;INTERVAL=2",
+	"monthly":  "FREQ=MONTHLY",
+}
+
+// RuleFor returns the rule of a loose frequency such as weekly, nil when it has no fixed rhythm
+func RuleFor(frequency string) *Rule {
+	spec, ok := legacy[strings.ToLower(strings.TrimSpace(frequency))]
+	if !ok {
+		return nil
+	}
+	rule, _ := ParseRule(spec)
+	return rule
+}
+
+// ParseRule reads an RRULE value such as FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10, with or without the
+// RRULE: prefix. BYSETPOS, BYYEARDAY, BYWEEKNO and the time parts are not supported
+func ParseRule(spec string) (*Rule, error) {
+	spec = strings.TrimSpace(spec)
+	spec = strings.TrimPrefix(strings.TrimPrefix(spec, "RRULE:"), "rrule:")
+	if spec == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%s is given twice", key)
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = value
+			default:
+				return nil, fmt.Errorf("FREQ %s is not supported, use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive number")
+			}
+		case "UNTIL":
+			if r.Until, err = parseUntil(value); err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			if r.ByDay, err = parseByDay(value); err != nil {
+				return nil, err
+			}
+		case "BYMONTHDAY":
+			if r.ByMonthDay, err = parseNumbers(key, value, -31, 31); err != nil {
+				return nil, err
+			}
+		case "BYMONTH":
+			if r.ByMonth, err = parseNumbers(key, value, 1, 12); err != nil {
+				return nil, err
+			}
+		case "WKST":
+			day, ok := weekdays[value]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST %s", value)
+			}
+			r.WeekStart = day
+		default:
+			return nil, fmt.Errorf("%s is not supported", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	for _, day := range r.ByDay {
+		if day.Ordinal != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("numbered BYDAY is only allowed in MONTHLY and YEARLY rules")
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return nil, fmt.Errorf("BYMONTHDAY is not allowed in WEEKLY rules")
+	}
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return date(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %s, use YYYYMMDD", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %s", item)
+		}
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY %s", item)
+		}
+		ordinal := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if ordinal, err = strconv.Atoi(prefix); err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+				return nil, fmt.Errorf("invalid BYDAY %s", item)
+			}
+		}
+		days = append(days, WeekdayNum{Ordinal: ordinal, Day: day})
+	}
+	return days, nil
+}
+
+func parseNumbers(key, value string, min, max int) ([]int, error) {
+	var numbers []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid %s %s", key, item)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// Finite reports whether the rule ends by itself
+func (r *Rule) Finite() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// period returns the first day of the k-th period of the rule from start
+func (r *Rule) period(start time.Time, k int) (first, next time.Time) {
+	step := k * r.Interval
+	switch r.Freq {
+	case Daily:
+		first = start.AddDate(0, 0, step)
+		return first, first.AddDate(0, 0, 1)
+	case Weekly:
+		week := start.AddDate(0, 0, -((int(start.Weekday()) - int(r.WeekStart) + 7) % 7))
+		first = week.AddDate(0, 0, 7*step)
+		return first, first.AddDate(0, 0, 7)
+	case Monthly:
+		first = time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 1, 0)
+	}
+	first = time.Date(start.Year()+step, 1, 1, 0, 0, 0, 0, time.UTC)
+	return first, first.AddDate(1, 0, 0)
+}
+
+// matches reports whether day is an occurrence of the rule started on start
+func (r *Rule) matches(day, start time.Time) bool {
+	if len(r.ByMonth) > 0 && !contains(r.ByMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		found := false
+		for _, n := range r.ByMonthDay {
+			if n == day.Day() || (n < 0 && last+n+1 == day.Day()) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.ByDay) > 0 {
+		found := false
+		for _, d := range r.ByDay {
+			if d.Day == day.Weekday() && (d.Ordinal == 0 || r.ordinalMatches(d.Ordinal, day)) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	// without a BY rule fixing the day the day of the start is repeated
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+	case Monthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+	case Yearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+				return false
+			}
+			return day.Day() == start.Day()
+		}
+	}
+	return true
+}
+
+// ordinalMatches reports whether day is the n-th of its weekday in its month, or its year for a
+// yearly rule without BYMONTH, counting from the end when n is negative
+func (r *Rule) ordinalMatches(n int, day time.Time) bool {
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	if r.Freq == Yearly && len(r.ByMonth) == 0 {
+		first = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		last = time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	if n > 0 {
+		return int(day.Sub(first).Hours()/24)/7+1 == n
+	}
+	return int(last.Sub(day).Hours()/24)/7+1 == -n
+}
+
+func contains(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand returns the occurrences of the rule started on start up to and including to, COUNT is
+// counted from start. A rule without an end stops MaxYears after start
+func (r *Rule) Expand(start, to time.Time) []time.Time {
+	start, to = date(start), date(to)
+	if limit := start.AddDate(MaxYears, 0, 0); to.After(limit) {
+		to = limit
+	}
+	if !r.Until.IsZero() && r.Until.Before(to) {
+		to = r.Until
+	}
+	var days []time.Time
+	for k := 0; ; k++ {
+		first, next := r.period(start, k)
+		if first.After(to) {
+			return days
+		}
+		for day := first; day.Before(next); day = day.AddDate(0, 0, 1) {
+			if day.Before(start) || day.After(to) || !r.matches(day, start) {
+				continue
+			}
+			days = append(days, day)
+			if r.Count > 0 && len(days) == r.Count {
+				return days
+			}
+		}
+	}
+}
+
+// ParseDates reads EXDATE values given as YYYY-MM-DD or YYYYMMDD, separated by commas
+func ParseDates(values []string) ([]time.Time, error) {
+	var days []time.Time
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			day, err := time.Parse("2006-01-02", item)
+			if err != nil {
+				if day, err = time.Parse("20060102", item); err != nil {
+					return nil, fmt.Errorf("invalid date %s, use YYYY-MM-DD", item)
+				}
+			}
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// FormatDates writes days as a comma separated list of YYYY-MM-DD, the inverse of ParseDates
+func FormatDates(days []time.Time) string {
+	items := make([]string, len(days))
+	for i, day := range days {
+		items[i] = day.Format("2006-01-02")
+	}
+	return strings.Join(items, ",")
+}
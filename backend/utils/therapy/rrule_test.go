@@ -0,0 +1,174 @@
+package therapy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func days(t []time.Time) []string {
+	out := make([]string, len(t))
+	for i, d := range t {
+		out[i] = d.Format("2006-01-02")
+	}
+	return out
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Rule
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", Rule{Freq: Weekly, Interval: 1, Count: 10,
+			ByDay: []WeekdayNum{{0, time.Monday}, {0, time.Wednesday}}, WeekStart: time.Monday}},
+		{"freq=monthly;byday=-1fr;interval=2", Rule{Freq: Monthly, Interval: 2,
+			ByDay: []WeekdayNum{{-1, time.Friday}}, WeekStart: time.Monday}},
+		{"FREQ=DAILY;UNTIL=20240531T235959Z", Rule{Freq: Daily, Interval: 1, Until: day("2024-05-31"), WeekStart: time.Monday}},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=21", Rule{Freq: Yearly, Interval: 1, ByMonth: []int{3}, ByMonthDay: []int{21}, WeekStart: time.Monday}},
+		{"FREQ=WEEKLY;WKST=SU", Rule{Freq: Weekly, Interval: 1, WeekStart: time.Sunday}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseRule(%q) = %+v, want %+v", tt.spec, *got, tt.want)
+			}
+		})
+	}
+
+	for _, spec := range []string{
+		"",
+		"FREQ",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=DAILY;UNTIL=2024-05-01",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=WEEKLY;WKST=XX",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseRule(spec); err == nil {
+				t.Errorf("ParseRule(%q) succeeded", spec)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		spec  string
+		start string
+		to    string
+		want  []string
+	}{
+		{"FREQ=DAILY;COUNT=3", "2024-05-01", "2024-12-31", []string{"2024-05-01", "2024-05-02", "2024-05-03"}},
+		{"FREQ=DAILY;INTERVAL=2;UNTIL=20240507", "2024-05-01", "2024-12-31", []string{"2024-05-01", "2024-05-03", "2024-05-05", "2024-05-07"}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", "2024-05-01", "2024-12-31", []string{"2024-05-01", "2024-05-06", "2024-05-08", "2024-05-13"}},
+		{"FREQ=WEEKLY;INTERVAL=2", "2024-05-01", "2024-05-31", []string{"2024-05-01", "2024-05-15", "2024-05-29"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=3", "2024-05-01", "2024-12-31", []string{"2024-05-03", "2024-05-13", "2024-05-17"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "2024-05-01", "2024-12-31", []string{"2024-05-31", "2024-06-28", "2024-07-26"}},
+		{"FREQ=MONTHLY;BYDAY=2TU;COUNT=2", "2024-05-01", "2024-12-31", []string{"2024-05-14", "2024-06-11"}},
+		{"FREQ=MONTHLY;COUNT=3", "2024-01-31", "2024-12-31", []string{"2024-01-31", "2024-03-31", "2024-05-31"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3", "2024-05-01", "2024-12-31", []string{"2024-05-31", "2024-06-30", "2024-07-31"}},
+		{"FREQ=YEARLY;COUNT=2", "2024-02-29", "2030-12-31", []string{"2024-02-29", "2028-02-29"}},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=21;COUNT=2", "2024-05-01", "2030-12-31", []string{"2025-03-21", "2026-03-21"}},
+		{"FREQ=YEARLY;BYDAY=1MO;COUNT=1", "2024-05-01", "2030-12-31", []string{"2025-01-06"}},
+		{"FREQ=YEARLY", "2024-05-01", "2100-01-01", []string{"2024-05-01", "2025-05-01", "2026-05-01", "2027-05-01", "2028-05-01", "2029-05-01"}},
+		{"FREQ=DAILY", "2024-05-01", "2024-04-30", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			rule, err := ParseRule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := days(rule.Expand(day(tt.start), day(tt.to))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand(%s, %s) = %v, want %v", tt.start, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	holidays := func(dates ...string) map[time.Time]bool {
+		m := map[time.Time]bool{}
+		for _, d := range dates {
+			m[day(d)] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name string
+		plan Plan
+		to   string
+		want []string
+	}{
+		{"weekly", Plan{Frequency: "weekly", Start: day("2024-05-01")}, "2024-05-29",
+			[]string{"2024-05-01", "2024-05-08", "2024-05-15", "2024-05-22", "2024-05-29"}},
+		{"excluded", Plan{Frequency: "weekly", Start: day("2024-05-01"), ExDates: []time.Time{day("2024-05-15")}}, "2024-05-29",
+			[]string{"2024-05-01", "2024-05-08", "2024-05-22", "2024-05-29"}},
+		{"holiday skipped", Plan{Frequency: "weekly", Start: day("2024-05-01"), Holidays: holidays("2024-05-08"), HolidayPolicy: Skip}, "2024-05-22",
+			[]string{"2024-05-01", "2024-05-15", "2024-05-22"}},
+		{"holiday moved to the next day", Plan{Frequency: "weekly", Start: day("2024-05-01"), Holidays: holidays("2024-05-08"), HolidayPolicy: Next}, "2024-05-15",
+			[]string{"2024-05-01", "2024-05-09<2024-05-08", "2024-05-15"}},
+		{"holidays moved back", Plan{Frequency: "weekly", Start: day("2024-05-01"), Holidays: holidays("2024-05-07", "2024-05-08"), HolidayPolicy: Previous}, "2024-05-15",
+			[]string{"2024-05-01", "2024-05-06<2024-05-08", "2024-05-15"}},
+		{"moved back before to", Plan{Frequency: "weekly", Start: day("2024-05-01"), Holidays: holidays("2024-05-29"), HolidayPolicy: Previous}, "2024-05-28",
+			[]string{"2024-05-01", "2024-05-08", "2024-05-15", "2024-05-22", "2024-05-28<2024-05-29"}},
+		{"moved past the end", Plan{Frequency: "weekly", Start: day("2024-05-01"), End: day("2024-05-29"), Holidays: holidays("2024-05-29"), HolidayPolicy: Next}, "2024-06-30",
+			[]string{"2024-05-01", "2024-05-08", "2024-05-15", "2024-05-22"}},
+		{"moved onto sessions", Plan{Frequency: "daily", Start: day("2024-05-01"), End: day("2024-05-05"), Holidays: holidays("2024-05-02"), HolidayPolicy: Next}, "2024-05-31",
+			[]string{"2024-05-01", "2024-05-03", "2024-05-04", "2024-05-05"}},
+		{"rule", Plan{Frequency: "weekly", Rule: &Rule{Freq: Daily, Interval: 3, Count: 3}, Start: day("2024-05-01")}, "2024-05-31",
+			[]string{"2024-05-01", "2024-05-04", "2024-05-07"}},
+		{"no rhythm", Plan{Frequency: "as needed", Start: day("2024-05-01")}, "2024-05-31", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, s := range tt.plan.Sessions(day(tt.to)) {
+				item := s.Date.Format("2006-01-02")
+				if s.Original != nil {
+					item += "<" + s.Original.Format("2006-01-02")
+				}
+				got = append(got, item)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sessions(%s) = %v, want %v", tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	got, err := ParseDates([]string{"2024-05-15, 20240501", "", "2024-05-08"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2024-05-01,2024-05-08,2024-05-15"; FormatDates(got) != want {
+		t.Errorf("ParseDates() = %s, want %s", FormatDates(got), want)
+	}
+	if _, err := ParseDates([]string{"15/05/2024"}); err == nil {
+		t.Error("ParseDates() of another layout succeeded")
+	}
+}
@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"healthcare/utils/i18n"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hmmftg/requestCore/libError"
+	"github.com/hmmftg/requestCore/libValidate"
+)
+
+// FieldError is a rule a field of a request broke, Field is its path in the JSON body such as
+// results[0].analyte_code and Description is in English, the locale middleware describes Code in the
+// language of the request
+type FieldError struct {
+	Field       string `json:"field"`
+	Code        string `json:"code"`
+	Rule        string `json:"rule"`
+	Param       string `json:"param,omitempty"`
+	Description string `json:"description"`
+}
+
+// Check runs the validate tags of v through libValidate and lists every field that broke a rule,
+// fields named in skip are not checked, updates skip the fields fixed when the record was created
+func Check(v any, skip ...string) ([]FieldError, error) {
+	err, errValidate := libValidate.ValidateStruct(v)
+	if err != nil {
+		return nil, err
+	}
+	if errValidate == nil {
+		return nil, nil
+	}
+	var list []validator.FieldError
+	var all validator.ValidationErrors
+	var one validator.FieldError
+	switch {
+	case errors.As(errValidate, &all):
+		list = all
+	case errors.As(errValidate, &one):
+		list = []validator.FieldError{one}
+	default:
+		return []FieldError{{Code: i18n.ValidationInvalid, Rule: "invalid", Description: errValidate.Error()}}, nil
+	}
+	fields := make([]FieldError, 0, len(list))
+	for _, fe := range list {
+		field, parent := path(reflect.TypeOf(v), fe.StructNamespace())
+		if skipped(field, skip) {
+			continue
+		}
+		fields = append(fields, describe(field, parent, fe))
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// Error checks v like Check and returns the libError of handlers on requestCore, the fields are
+// its data
+func Error(v any, skip ...string) error {
+	return Fail(Check(v, skip...))
+}
+
+// Fail returns the libError of Error for the results of Check, handlers add the fields of rules
+// tags can not express before failing. It is nil when no field broke a rule
+func Fail(fields []FieldError, err error) error {
+	if err != nil {
+		return libError.New(http.StatusInternalServerError, "INVALID_VALIDATION", err.Error())
+	}
+	if len(fields) > 0 {
+		return libError.New(http.StatusBadRequest, i18n.InvalidRequest, fields)
+	}
+	return nil
+}
+
+func skipped(field string, skip []string) bool {
+	for _, s := range skip {
+		if field == s || strings.HasPrefix(field, s+".") || strings.HasPrefix(field, s+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName returns the name of f in a JSON body
+func jsonName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// path turns the struct namespace of a field such as LabResultsRequest.Results[0].AnalyteCode into
+// its path in the JSON body, parent is the struct holding the field
+func path(t reflect.Type, namespace string) (field string, parent reflect.Type) {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	t = indirect(t)
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		parent = t
+		name, index, _ := strings.Cut(part, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		f, ok := reflect.StructField{}, false
+		if t != nil && t.Kind() == reflect.Struct {
+			f, ok = t.FieldByName(name)
+		}
+		if !ok {
+			names = append(names, name+index)
+			t = nil
+			continue
+		}
+		names = append(names, jsonName(f)+index)
+		t = indirect(f.Type)
+		if index != "" && t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = indirect(t.Elem())
+		}
+	}
+	return strings.Join(names, "."), parent
+}
+
+// sibling returns the JSON name of the field name of parent, the param of cross field rules
+func sibling(parent reflect.Type, name string) string {
+	if parent != nil && parent.Kind() == reflect.Struct {
+		if f, ok := parent.FieldByName(name); ok {
+			return jsonName(f)
+		}
+	}
+	return name
+}
+
+func isTime(fe validator.FieldError) bool {
+	return fe.Type() == reflect.TypeOf(time.Time{})
+}
+
+func isNumber(fe validator.FieldError) bool {
+	switch fe.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// describe returns the code and English description of the rule fe broke
+func describe(field string, parent reflect.Type, fe validator.FieldError) FieldError {
+	e := FieldError{Field: field, Rule: fe.Tag(), Param: fe.Param()}
+	switch fe.Tag() {
+	case "required":
+		e.Code, e.Description = i18n.ValidationRequired, fmt.Sprintf("%s is required", field)
+	case "required_without":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationRequired, fmt.Sprintf("%s is required when %s is empty", field, e.Param)
+	case "required_with":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationRequired, fmt.Sprintf("%s is required when %s is given", field, e.Param)
+	case "required_if":
+		name, value, _ := strings.Cut(fe.Param(), " ")
+		e.Param = sibling(parent, name) + " " + value
+		e.Code, e.Description = i18n.ValidationRequired, fmt.Sprintf("%s is required when %s is %s", field, sibling(parent, name), value)
+	case "required_without_all":
+		names := strings.Fields(fe.Param())
+		for i, name := range names {
+			names[i] = sibling(parent, name)
+		}
+		e.Param = strings.Join(names, " ")
+		e.Code, e.Description = i18n.ValidationRequired, fmt.Sprintf("one of %s is required", strings.Join(append([]string{field}, names...), ", "))
+	case "excluded_with":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationExcluded, fmt.Sprintf("%s must be left out when %s is given", field, e.Param)
+	case "email":
+		e.Code, e.Description = i18n.ValidationEmail, fmt.Sprintf("%s must be an email address", field)
+	case "e164":
+		e.Code, e.Description = i18n.ValidationPhone, fmt.Sprintf("%s must be a phone number in E.164 format such as +989121234567", field)
+	case "oneof":
+		e.Code, e.Description = i18n.ValidationOneOf, fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "datetime":
+		e.Code, e.Description = i18n.ValidationInvalid, fmt.Sprintf("%s must be in the %s format", field, layout(fe.Param()))
+	case "gt", "gte", "min", "lt", "lte", "max":
+		e.Code = i18n.ValidationRange
+		switch {
+		case isTime(fe) && fe.Param() == "" && (fe.Tag() == "lt" || fe.Tag() == "lte"):
+			e.Code, e.Description = i18n.ValidationFuture, fmt.Sprintf("%s must not be in the future", field)
+		case !isNumber(fe):
+			e.Description = fmt.Sprintf("%s must have %s %s items or characters", field, bound(fe.Tag()), fe.Param())
+		default:
+			e.Description = fmt.Sprintf("%s must be %s %s", field, bound(fe.Tag()), fe.Param())
+		}
+	case "gtfield":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationAfter, fmt.Sprintf("%s must be after %s", field, e.Param)
+	case "gtefield":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationNotBefore, fmt.Sprintf("%s must not be before %s", field, e.Param)
+	case "ltfield", "ltefield":
+		e.Param = sibling(parent, fe.Param())
+		e.Code, e.Description = i18n.ValidationBelow, fmt.Sprintf("%s must be %s %s", field, bound(strings.TrimSuffix(fe.Tag(), "field")), e.Param)
+	default:
+		e.Code, e.Description = i18n.ValidationInvalid, fmt.Sprintf("%s is invalid", field)
+		if fe.Param() != "" {
+			e.Description += " (" + fe.Tag() + "=" + fe.Param() + ")"
+		}
+	}
+	return e
+}
+
+func bound(tag string) string {
+	switch tag {
+	case "gt":
+		return "more than"
+	case "gte", "min":
+		return "at least"
+	case "lt":
+		return "less than"
+	}
+	return "at most"
+}
+
+// layout spells a time layout of Go such as 2006-01-02 the way clients write it
+func layout(value string) string {
+	return strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD", "15", "HH", "04", "mm").Replace(value)
+}
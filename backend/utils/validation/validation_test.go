@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"healthcare/utils/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type testResult struct {
+	AnalyteCode string  `json:"analyte_code" validate:"required"`
+	Value       float64 `json:"value" validate:"gte=0"`
+}
+
+type testRequest struct {
+	Email   string       `json:"email" validate:"omitempty,email"`
+	Phone   string       `json:"phone,omitempty" validate:"required_without=Email"`
+	Gender  string       `json:"gender" validate:"omitempty,oneof=male female"`
+	Born    time.Time    `json:"date_of_birth" validate:"lt"`
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end" validate:"gtfield=Start"`
+	Name    string       `form:"name" validate:"min=2"`
+	Results []testResult `json:"results" validate:"dive"`
+	Code    string       `json:"-" validate:"len=3"`
+}
+
+// fieldErrors validates v with the validator libValidate runs and describes the errors as Check does
+func fieldErrors(t *testing.T, v any) []FieldError {
+	t.Helper()
+	var all validator.ValidationErrors
+	if err := validator.New().Struct(v); !errors.As(err, &all) {
+		t.Fatalf("Struct() error = %v, want validation errors", err)
+	}
+	var fields []FieldError
+	for _, fe := range all {
+		field, parent := path(reflect.TypeOf(v), fe.StructNamespace())
+		fields = append(fields, describe(field, parent, fe))
+	}
+	return fields
+}
+
+func TestDescribe(t *testing.T) {
+	now := time.Now()
+	request := &testRequest{
+		Gender:  "other",
+		Born:    now.Add(time.Hour),
+		Start:   now,
+		End:     now,
+		Name:    "a",
+		Results: []testResult{{AnalyteCode: "GLU", Value: 1}, {Value: -1}},
+		Code:    "ab",
+	}
+	want := []FieldError{
+		{"phone", i18n.ValidationRequired, "required_without", "email", "phone is required when email is empty"},
+		{"gender", i18n.ValidationOneOf, "oneof", "male female", "gender must be one of male, female"},
+		{"date_of_birth", i18n.ValidationFuture, "lt", "", "date_of_birth must not be in the future"},
+		{"end", i18n.ValidationAfter, "gtfield", "start", "end must be after start"},
+		{"name", i18n.ValidationRange, "min", "2", "name must have at least 2 items or characters"},
+		{"results[1].analyte_code", i18n.ValidationRequired, "required", "", "results[1].analyte_code is required"},
+		{"results[1].value", i18n.ValidationRange, "gte", "0", "results[1].value must be at least 0"},
+		{"Code", i18n.ValidationInvalid, "len", "3", "Code is invalid (len=3)"},
+	}
+	got := fieldErrors(t, request)
+	if len(got) != len(want) {
+		t.Fatalf("%d errors %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("error %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+type testBetween struct {
+	Systolic   *float64  `json:"bp_systolic" validate:"required_without_all=Diastolic Pulse,omitempty,gte=40"`
+	Diastolic  *float64  `json:"bp_diastolic" validate:"omitempty,ltfield=Systolic"`
+	Pulse      *float64  `json:"pulse" validate:"omitempty,gte=20"`
+	DoctorID   string    `json:"doctor_id" validate:"required_without=Department,excluded_with=Department"`
+	Department string    `json:"department"`
+	Start      time.Time `json:"start_date"`
+	End        time.Time `json:"end_date" validate:"omitempty,gtefield=Start"`
+}
+
+// TestDescribeBetween covers the rules between fields
+func TestDescribeBetween(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	diastolic := 80.0
+	tests := []struct {
+		name    string
+		request testBetween
+		want    []FieldError
+	}{
+		{"none measured and both queues", testBetween{DoctorID: "d-1", Department: "cardiology", Start: day, End: day.AddDate(0, 0, -1)}, []FieldError{
+			{"bp_systolic", i18n.ValidationRequired, "required_without_all", "bp_diastolic pulse", "one of bp_systolic, bp_diastolic, pulse is required"},
+			{"doctor_id", i18n.ValidationExcluded, "excluded_with", "department", "doctor_id must be left out when department is given"},
+			{"end_date", i18n.ValidationNotBefore, "gtefield", "start_date", "end_date must not be before start_date"},
+		}},
+		{"diastolic alone", testBetween{Diastolic: &diastolic, Start: day, End: day}, []FieldError{
+			{"bp_diastolic", i18n.ValidationBelow, "ltfield", "bp_systolic", "bp_diastolic must be less than bp_systolic"},
+			{"doctor_id", i18n.ValidationRequired, "required_without", "department", "doctor_id is required when department is empty"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldErrors(t, tt.request)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testException struct {
+	Date      string `json:"date" validate:"required,datetime=2006-01-02"`
+	Kind      string `json:"kind"`
+	StartTime string `json:"start_time" validate:"required_if=Kind extra,required_with=EndTime"`
+	EndTime   string `json:"end_time" validate:"required_if=Kind extra,required_with=StartTime"`
+}
+
+func TestDescribeConditional(t *testing.T) {
+	tests := []struct {
+		name    string
+		request testException
+		want    []FieldError
+	}{
+		{"extra without times", testException{Date: "1404/12/10", Kind: "extra"}, []FieldError{
+			{"date", i18n.ValidationInvalid, "datetime", "2006-01-02", "date must be in the YYYY-MM-DD format"},
+			{"start_time", i18n.ValidationRequired, "required_if", "kind extra", "start_time is required when kind is extra"},
+			{"end_time", i18n.ValidationRequired, "required_if", "kind extra", "end_time is required when kind is extra"},
+		}},
+		{"leave with a start", testException{Date: "2026-03-01", Kind: "leave", StartTime: "10:00"}, []FieldError{
+			{"end_time", i18n.ValidationRequired, "required_with", "start_time", "end_time is required when start_time is given"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldErrors(t, tt.request)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFail(t *testing.T) {
+	if err := Fail(nil, nil); err != nil {
+		t.Errorf("Fail(nil, nil) = %v, want nil", err)
+	}
+	if err := Fail([]FieldError{{Field: "bmi", Code: i18n.ValidationRange}}, nil); err == nil {
+		t.Error("Fail(fields) = nil, want an error")
+	}
+	if err := Fail(nil, errors.New("no validator")); err == nil {
+		t.Error("Fail(err) = nil, want an error")
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		namespace  string
+		want       string
+		wantParent reflect.Type
+	}{
+		{"testRequest.Phone", "phone", reflect.TypeOf(testRequest{})},
+		{"testRequest.Results[2].AnalyteCode", "results[2].analyte_code", reflect.TypeOf(testResult{})},
+		{"testRequest.Name", "name", reflect.TypeOf(testRequest{})},
+		{"testRequest.Missing.Field", "Missing.Field", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			got, parent := path(reflect.TypeOf(&testRequest{}), tt.namespace)
+			if got != tt.want || parent != tt.wantParent {
+				t.Errorf("path(%q) = %q, %v, want %q, %v", tt.namespace, got, parent, tt.want, tt.wantParent)
+			}
+		})
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	tests := []struct {
+		field string
+		skip  []string
+		want  bool
+	}{
+		{"start", []string{"start"}, true},
+		{"results[1].value", []string{"results"}, true},
+		{"visit.date", []string{"patient", "visit"}, true},
+		{"results_count", []string{"results"}, false},
+		{"start", nil, false},
+	}
+	for _, tt := range tests {
+		if got := skipped(tt.field, tt.skip); got != tt.want {
+			t.Errorf("skipped(%q, %v) = %v, want %v", tt.field, tt.skip, got, tt.want)
+		}
+	}
+}
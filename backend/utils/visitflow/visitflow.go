@@ -0,0 +1,80 @@
+package visitflow
+
+import "fmt"
+
+// Visit statuses
+const (
+	Scheduled  = "scheduled"
+	CheckedIn  = "checked_in"
+	InProgress = "in_progress"
+	Completed  = "completed"
+	Cancelled  = "cancelled"
+	NoShow     = "no_show"
+)
+
+// visitTypes are the values of the visit_type enum
+var visitTypes = map[string]bool{"general": true, "dentistry": true, "specialist": true}
+
+// ValidType reports whether visitType is a known visit type
+func ValidType(visitType string) bool {
+	return visitTypes[visitType]
+}
+
+// Actions of the transition endpoints and the status each one leads to
+var Actions = map[string]string{
+	"check-in": CheckedIn,
+	"start":    InProgress,
+	"complete": Completed,
+	"cancel":   Cancelled,
+	"no-show":  NoShow,
+}
+
+// transitions lists the statuses reachable from each status, terminal statuses have none
+var transitions = map[string][]string{
+	Scheduled:  {CheckedIn, Cancelled, NoShow},
+	CheckedIn:  {InProgress, Cancelled, NoShow},
+	InProgress: {Completed, Cancelled},
+	Completed:  {},
+	Cancelled:  {},
+	NoShow:     {},
+}
+
+// Valid reports whether status is a known visit status
+func Valid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// Initial reports whether a visit may be created in the status,
+// walk-ins are created already checked in
+func Initial(status string) bool {
+	return status == Scheduled || status == CheckedIn
+}
+
+// Terminal reports whether no transition leaves the status
+func Terminal(status string) bool {
+	return Valid(status) && len(transitions[status]) == 0
+}
+
+// Lockable reports whether a visit in the status may be locked against clinical edits
+func Lockable(status string) bool {
+	return status == Completed
+}
+
+// Next lists the statuses reachable from status
+func Next(status string) []string {
+	return transitions[status]
+}
+
+// Check returns an error when moving from one status to another is not allowed
+func Check(from, to string) error {
+	if !Valid(to) {
+		return fmt.Errorf("unknown visit status %q", to)
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("visit status cannot change from %q to %q", from, to)
+}
@@ -0,0 +1,92 @@
+package visitflow
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{Scheduled, CheckedIn, false},
+		{Scheduled, Cancelled, false},
+		{Scheduled, NoShow, false},
+		{Scheduled, InProgress, true},
+		{Scheduled, Completed, true},
+		{CheckedIn, InProgress, false},
+		{CheckedIn, NoShow, false},
+		{CheckedIn, Scheduled, true},
+		{InProgress, Completed, false},
+		{InProgress, Cancelled, false},
+		{InProgress, NoShow, true},
+		{Completed, Cancelled, true},
+		{Cancelled, Scheduled, true},
+		{NoShow, CheckedIn, true},
+		{Scheduled, Scheduled, true},
+		{Scheduled, "archived", true},
+		{"archived", Cancelled, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if err := Check(tt.from, tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("Check(%q, %q) error = %v, want error %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestActions checks that every action leads to a status some other status can move to
+func TestActions(t *testing.T) {
+	for action, status := range Actions {
+		reachable := false
+		for from := range transitions {
+			if Check(from, status) == nil {
+				reachable = true
+			}
+		}
+		if !Valid(status) || !reachable {
+			t.Errorf("action %s leads to %s, which no status can move to", action, status)
+		}
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	tests := []struct {
+		status                             string
+		valid, initial, terminal, lockable bool
+	}{
+		{Scheduled, true, true, false, false},
+		{CheckedIn, true, true, false, false},
+		{InProgress, true, false, false, false},
+		{Completed, true, false, true, true},
+		{Cancelled, true, false, true, false},
+		{NoShow, true, false, true, false},
+		{"archived", false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := Valid(tt.status); got != tt.valid {
+				t.Errorf("Valid() = %v, want %v", got, tt.valid)
+			}
+			if got := Initial(tt.status); got != tt.initial {
+				t.Errorf("Initial() = %v, want %v", got, tt.initial)
+			}
+			if got := Terminal(tt.status); got != tt.terminal {
+				t.Errorf("Terminal() = %v, want %v", got, tt.terminal)
+			}
+			if got := Lockable(tt.status); got != tt.lockable {
+				t.Errorf("Lockable() = %v, want %v", got, tt.lockable)
+			}
+			if got := len(Next(tt.status)) == 0; got != (tt.terminal || !tt.valid) {
+				t.Errorf("Next() = %v", Next(tt.status))
+			}
+		})
+	}
+}
+
+func TestValidType(t *testing.T) {
+	for visitType, want := range map[string]bool{"general": true, "dentistry": true, "specialist": true, "": false, "General": false} {
+		if got := ValidType(visitType); got != want {
+			t.Errorf("ValidType(%q) = %v, want %v", visitType, got, want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package vitals
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"healthcare/utils/i18n"
+	"healthcare/utils/validation"
+)
+
+// Measurement names, also used as keys of normal ranges and abnormal flags
+const (
+	BPSystolic      = "bp_systolic"
+	BPDiastolic     = "bp_diastolic"
+	Pulse           = "pulse"
+	Temperature     = "temperature"
+	SpO2            = "spo2"
+	RespiratoryRate = "respiratory_rate"
+	Weight          = "weight"
+	Height          = "height"
+	BMI             = "bmi"
+)
+
+// Names lists the measurements in a fixed order
+var Names = []string{BPSystolic, BPDiastolic, Pulse, Temperature, SpO2, RespiratoryRate, Weight, Height, BMI}
+
+// Units of each measurement
+var Units = map[string]string{
+	BPSystolic:      "mmHg",
+	BPDiastolic:     "mmHg",
+	Pulse:           "bpm",
+	Temperature:     "°C",
+	SpO2:            "%",
+	RespiratoryRate: "breaths/min",
+	Weight:          "kg",
+	Height:          "cm",
+	BMI:             "kg/m²",
+}
+
+// Flags of an out of range measurement
+const (
+	FlagLow  = "low"
+	FlagHigh = "high"
+)
+
+// Measurements is a set of vital signs taken at once, absent values are nil
+type Measurements struct {
+	BPSystolic      *float64
+	BPDiastolic     *float64
+	Pulse           *float64
+	Temperature     *float64
+	SpO2            *float64
+	RespiratoryRate *float64
+	Weight          *float64
+	Height          *float64
+}
+
+// Values returns the present measurements keyed by name, including the computed BMI
+func (m Measurements) Values() map[string]float64 {
+	values := map[string]float64{}
+	for name, value := range map[string]*float64{
+		BPSystolic:      m.BPSystolic,
+		BPDiastolic:     m.BPDiastolic,
+		Pulse:           m.Pulse,
+		Temperature:     m.Temperature,
+		SpO2:            m.SpO2,
+		RespiratoryRate: m.RespiratoryRate,
+		Weight:          m.Weight,
+		Height:          m.Height,
+	} {
+		if value != nil {
+			values[name] = *value
+		}
+	}
+	if bmi, ok := m.BMI(); ok {
+		values[BMI] = bmi
+	}
+	return values
+}
+
+// BMI computes body mass index from weight in kg and height in cm, rounded to one decimal
+func (m Measurements) BMI() (float64, bool) {
+	if m.Weight == nil || m.Height == nil || *m.Weight <= 0 || *m.Height <= 0 {
+		return 0, false
+	}
+	meters := *m.Height / 100
+	return math.Round(*m.Weight/(meters*meters)*10) / 10, true
+}
+
+// BMILimits are the bounds of a plausible BMI, weight and height may each be plausible while their
+// BMI is not. It is stored as NUMERIC(4,1)
+var BMILimits = Range{Min: 2, Max: 250}
+
+// Validate checks the rules between measurements, the request bounds each measurement on its own
+func (m Measurements) Validate() []validation.FieldError {
+	var fields []validation.FieldError
+	if m.BPSystolic != nil && m.BPDiastolic != nil && *m.BPDiastolic >= *m.BPSystolic {
+		fields = append(fields, validation.FieldError{
+			Field: BPDiastolic, Code: i18n.ValidationBelow, Rule: "ltfield", Param: BPSystolic,
+			Description: fmt.Sprintf("%s must be less than %s", BPDiastolic, BPSystolic),
+		})
+	}
+	if bmi, ok := m.BMI(); ok && bmi < BMILimits.Min {
+		fields = append(fields, validation.FieldError{
+			Field: BMI, Code: i18n.ValidationRange, Rule: "gte", Param: fmt.Sprint(BMILimits.Min),
+			Description: fmt.Sprintf("%s must be at least %g", BMI, BMILimits.Min),
+		})
+	} else if ok && bmi > BMILimits.Max {
+		fields = append(fields, validation.FieldError{
+			Field: BMI, Code: i18n.ValidationRange, Rule: "lte", Param: fmt.Sprint(BMILimits.Max),
+			Description: fmt.Sprintf("%s must be at most %g", BMI, BMILimits.Max),
+		})
+	}
+	return fields
+}
+
+// Range is an inclusive normal range, a zero bound is not checked
+type Range struct {
+	Min float64 `yaml:"min" json:"min"`
+	Max float64 `yaml:"max" json:"max"`
+}
+
+// AgeGroup holds normal ranges of patients aged MinAge up to, but not including, MaxAge years
+type AgeGroup struct {
+	Name   string           `yaml:"name" json:"name"`
+	MinAge int              `yaml:"minAge" json:"min_age"`
+	MaxAge int              `yaml:"maxAge" json:"max_age"`
+	Ranges map[string]Range `yaml:"ranges" json:"ranges"`
+}
+
+// DefaultAgeGroups are used when no normal ranges are configured
+var DefaultAgeGroups = []AgeGroup{
+	{
+		Name: "infant", MinAge: 0, MaxAge: 1,
+		Ranges: map[string]Range{
+			BPSystolic: {Min: 70, Max: 100}, BPDiastolic: {Min: 50, Max: 65},
+			Pulse: {Min: 100, Max: 160}, Temperature: {Min: 36.5, Max: 37.5},
+			SpO2: {Min: 95}, RespiratoryRate: {Min: 30, Max: 60},
+		},
+	},
+	{
+		Name: "child", MinAge: 1, MaxAge: 12,
+		Ranges: map[string]Range{
+			BPSystolic: {Min: 80, Max: 120}, BPDiastolic: {Min: 50, Max: 80},
+			Pulse: {Min: 70, Max: 130}, Temperature: {Min: 36.1, Max: 37.5},
+			SpO2: {Min: 95}, RespiratoryRate: {Min: 18, Max: 30},
+		},
+	},
+	{
+		Name: "adolescent", MinAge: 12, MaxAge: 18,
+		Ranges: map[string]Range{
+			BPSystolic: {Min: 90, Max: 130}, BPDiastolic: {Min: 60, Max: 85},
+			Pulse: {Min: 60, Max: 110}, Temperature: {Min: 36.1, Max: 37.5},
+			SpO2: {Min: 95}, RespiratoryRate: {Min: 12, Max: 20},
+			BMI: {Min: 16, Max: 27},
+		},
+	},
+	{
+		Name: "adult", MinAge: 18, MaxAge: 65,
+		Ranges: map[string]Range{
+			BPSystolic: {Min: 90, Max: 130}, BPDiastolic: {Min: 60, Max: 85},
+			Pulse: {Min: 60, Max: 100}, Temperature: {Min: 36.1, Max: 37.5},
+			SpO2: {Min: 95}, RespiratoryRate: {Min: 12, Max: 20},
+			BMI: {Min: 18.5, Max: 25},
+		},
+	},
+	{
+		Name: "senior", MinAge: 65, MaxAge: 150,
+		Ranges: map[string]Range{
+			BPSystolic: {Min: 90, Max: 140}, BPDiastolic: {Min: 60, Max: 90},
+			Pulse: {Min: 60, Max: 100}, Temperature: {Min: 35.8, Max: 37.5},
+			SpO2: {Min: 94}, RespiratoryRate: {Min: 12, Max: 22},
+			BMI: {Min: 22, Max: 28},
+		},
+	},
+}
+
+// Evaluator flags measurements that are outside the normal range of the patient's age group
+type Evaluator struct {
+	groups []AgeGroup
+}
+
+// NewEvaluator builds an evaluator, DefaultAgeGroups are used when groups is empty
+func NewEvaluator(groups []AgeGroup) Evaluator {
+	if len(groups) == 0 {
+		groups = DefaultAgeGroups
+	}
+	return Evaluator{groups: groups}
+}
+
+// Group returns the age group matching the age in years
+func (e Evaluator) Group(age int) (AgeGroup, bool) {
+	for _, group := range e.groups {
+		if age >= group.MinAge && age < group.MaxAge {
+			return group, true
+		}
+	}
+	return AgeGroup{}, false
+}
+
+// Flags returns the abnormal flags of the measurements keyed by measurement name
+func (e Evaluator) Flags(m Measurements, age int) map[string]string {
+	flags := map[string]string{}
+	group, ok := e.Group(age)
+	if !ok {
+		return flags
+	}
+	for name, value := range m.Values() {
+		normal, ok := group.Ranges[name]
+		if !ok {
+			continue
+		}
+		switch {
+		case normal.Min != 0 && value < normal.Min:
+			flags[name] = FlagLow
+		case normal.Max != 0 && value > normal.Max:
+			flags[name] = FlagHigh
+		}
+	}
+	return flags
+}
+
+// Age returns the age in full years at the given time, or -1 when the date of birth is unknown: zero
+// or the 0001-01-01 the queries coalesce a missing one to
+func Age(dateOfBirth, at time.Time) int {
+	if dateOfBirth.Year() <= 1 {
+		return -1
+	}
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() || (at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
+
+// FormatFlags encodes flags for storage as "name:flag,name:flag" sorted by name
+func FormatFlags(flags map[string]string) string {
+	parts := make([]string, 0, len(flags))
+	for name, flag := range flags {
+		parts = append(parts, name+":"+flag)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// ParseFlags decodes flags stored by FormatFlags
+func ParseFlags(stored string) map[string]string {
+	flags := map[string]string{}
+	for _, part := range strings.Split(stored, ",") {
+		name, flag, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if ok {
+			flags[name] = flag
+		}
+	}
+	return flags
+}
@@ -0,0 +1,123 @@
+package vitals
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func value(v float64) *float64 {
+	return &v
+}
+
+func TestBMI(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      Measurements
+		want   float64
+		wantOK bool
+	}{
+		{"adult", Measurements{Weight: value(70), Height: value(175)}, 22.9, true},
+		{"no height", Measurements{Weight: value(70)}, 0, false},
+		{"zero height", Measurements{Weight: value(70), Height: value(0)}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.m.BMI()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("BMI() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Measurements
+		want []string
+	}{
+		{"valid", Measurements{BPSystolic: value(120), BPDiastolic: value(80), Pulse: value(72)}, nil},
+		{"diastolic alone", Measurements{BPDiastolic: value(80)}, nil},
+		{"implausible bmi", Measurements{Weight: value(400), Height: value(20)}, []string{"bmi must be at most 250"}},
+		{"too low bmi", Measurements{Weight: value(0.3), Height: value(260)}, []string{"bmi must be at least 2"}},
+		{"diastolic above systolic", Measurements{BPSystolic: value(90), BPDiastolic: value(95), Weight: value(70), Height: value(175)},
+			[]string{"bp_diastolic must be less than bp_systolic"}},
+		{"both", Measurements{BPSystolic: value(90), BPDiastolic: value(90), Weight: value(400), Height: value(20)},
+			[]string{"bp_diastolic must be less than bp_systolic", "bmi must be at most 250"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, field := range tt.m.Validate() {
+				got = append(got, field.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	e := NewEvaluator(nil)
+	tests := []struct {
+		name string
+		m    Measurements
+		age  int
+		want string
+	}{
+		{"normal adult", Measurements{Pulse: value(70), SpO2: value(98)}, 30, ""},
+		{"tachycardic adult", Measurements{Pulse: value(120), SpO2: value(90)}, 30, "pulse:high,spo2:low"},
+		{"infant pulse", Measurements{Pulse: value(120)}, 0, ""},
+		{"unknown age", Measurements{Pulse: value(200)}, -1, ""},
+		{"overweight senior", Measurements{Weight: value(90), Height: value(170)}, 70, "bmi:high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatFlags(e.Flags(tt.m, tt.age)); got != tt.want {
+				t.Errorf("Flags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	birth := time.Date(2000, 6, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{time.Date(2020, 6, 14, 0, 0, 0, 0, time.UTC), 19},
+		{time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC), 20},
+		{time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), 20},
+	}
+	for _, tt := range tests {
+		if got := Age(birth, tt.at); got != tt.want {
+			t.Errorf("Age(%s) = %d, want %d", tt.at.Format("2006-01-02"), got, tt.want)
+		}
+	}
+	if got := Age(time.Time{}, birth); got != -1 {
+		t.Errorf("Age(zero) = %d, want -1", got)
+	}
+	// a missing date of birth read through COALESCE, in the zone of the database session
+	unknown := time.Date(1, 1, 1, 0, 0, 0, 0, time.FixedZone("Tehran", 12600))
+	if got := Age(unknown, birth); got != -1 {
+		t.Errorf("Age(0001-01-01) = %d, want -1", got)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	flags := map[string]string{Pulse: FlagHigh, SpO2: FlagLow}
+	stored := FormatFlags(flags)
+	if stored != "pulse:high,spo2:low" {
+		t.Fatalf("FormatFlags() = %q", stored)
+	}
+	parsed := ParseFlags(stored)
+	if len(parsed) != 2 || parsed[Pulse] != FlagHigh || parsed[SpO2] != FlagLow {
+		t.Errorf("ParseFlags(%q) = %v", stored, parsed)
+	}
+	if got := ParseFlags(""); len(got) != 0 {
+		t.Errorf("ParseFlags(\"\") = %v", got)
+	}
+}
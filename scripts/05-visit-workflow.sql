@@ -0,0 +1,24 @@
+-- Visit workflow: scheduled -> checked_in -> in_progress -> completed, plus cancelled and no_show
+ALTER TYPE visit_status ADD VALUE IF NOT EXISTS 'checked_in';
+ALTER TYPE visit_status ADD VALUE IF NOT EXISTS 'in_progress';
+ALTER TYPE visit_status ADD VALUE IF NOT EXISTS 'no_show';
+
+-- A completed visit may be locked against clinical edits, later changes go to addenda
+ALTER TABLE public.visits ADD COLUMN locked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE public.visits ADD COLUMN locked_by TEXT;
+
+-- Every status change of a visit with its actor
+CREATE TABLE public.visit_status_transitions (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  from_status visit_status,
+  to_status visit_status NOT NULL,
+  actor_id TEXT NOT NULL,
+  actor_name TEXT,
+  reason TEXT,
+  transitioned_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX visit_status_transitions_visit_id_idx ON public.visit_status_transitions (visit_id, transitioned_at);
+
+ALTER TABLE public.visit_status_transitions ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,11 @@
+-- Uploaded visit images, files are kept in the configured storage under storage_key
+-- and served through signed download urls
+ALTER TABLE public.visit_images ALTER COLUMN image_url DROP NOT NULL;
+ALTER TABLE public.visit_images ADD COLUMN image_type TEXT;
+ALTER TABLE public.visit_images ADD COLUMN file_name TEXT;
+ALTER TABLE public.visit_images ADD COLUMN file_size BIGINT;
+ALTER TABLE public.visit_images ADD COLUMN mime_type TEXT;
+ALTER TABLE public.visit_images ADD COLUMN storage_key TEXT UNIQUE;
+ALTER TABLE public.visit_images ADD COLUMN uploaded_by TEXT;
+
+CREATE INDEX visit_images_visit_id_idx ON public.visit_images (visit_id, created_at);
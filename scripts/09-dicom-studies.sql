@@ -0,0 +1,38 @@
+-- DICOM visit images and the per patient index of imaging studies
+ALTER TABLE public.visit_images ADD COLUMN modality TEXT;
+ALTER TABLE public.visit_images ADD COLUMN study_uid TEXT;
+
+CREATE INDEX visit_images_study_uid_idx ON public.visit_images (study_uid) WHERE study_uid IS NOT NULL;
+
+CREATE TABLE public.imaging_studies (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  patient_id UUID REFERENCES public.patients(id) ON DELETE CASCADE NOT NULL,
+  study_uid TEXT NOT NULL,
+  modality TEXT,
+  study_date DATE,
+  description TEXT,
+  instance_count INTEGER NOT NULL DEFAULT 0,
+  first_visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  last_visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (patient_id, study_uid)
+);
+
+ALTER TABLE public.imaging_studies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Patients can view own imaging studies" ON public.imaging_studies
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.patients p
+      WHERE p.id = imaging_studies.patient_id AND p.profile_id = auth.uid()
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage imaging studies" ON public.imaging_studies
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
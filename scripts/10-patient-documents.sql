@@ -0,0 +1,47 @@
+-- Documents kept at the patient level, e.g. consent forms, referral letters, external lab
+-- reports and ID scans. Files are kept in the configured storage under storage_key, deleted
+-- documents keep their file until retain_until and are marked purged once it is removed
+CREATE TABLE public.patient_documents (
+  id UUID PRIMARY KEY,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  category TEXT NOT NULL,
+  title TEXT,
+  description TEXT,
+  file_name TEXT NOT NULL,
+  file_size BIGINT NOT NULL,
+  mime_type TEXT NOT NULL,
+  checksum TEXT NOT NULL, -- SHA-256 of the content, hex encoded
+  storage_key TEXT UNIQUE NOT NULL,
+  document_date DATE NOT NULL,
+  retain_until DATE NOT NULL,
+  uploaded_by TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  deleted_at TIMESTAMP WITH TIME ZONE,
+  deleted_by TEXT,
+  purged_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX patient_documents_checksum_idx ON public.patient_documents (patient_id, checksum)
+  WHERE deleted_at IS NULL;
+CREATE INDEX patient_documents_patient_idx ON public.patient_documents (patient_id, category, document_date DESC)
+  WHERE deleted_at IS NULL;
+CREATE INDEX patient_documents_retention_idx ON public.patient_documents (retain_until)
+  WHERE deleted_at IS NOT NULL AND purged_at IS NULL;
+
+ALTER TABLE public.patient_documents ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Patients can view own documents" ON public.patient_documents
+  FOR SELECT USING (
+    deleted_at IS NULL AND EXISTS (
+      SELECT 1 FROM public.patients p
+      WHERE p.id = patient_documents.patient_id AND p.profile_id = auth.uid()
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage documents" ON public.patient_documents
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
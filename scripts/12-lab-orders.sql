@@ -0,0 +1,81 @@
+-- Lab orders of a visit and their structured results, panels and reference ranges come from the
+-- lab catalog of the backend and are copied to each result so old results keep their ranges
+CREATE TYPE lab_order_status AS ENUM ('ordered', 'partial', 'resulted', 'cancelled');
+
+CREATE TABLE public.lab_orders (
+  id UUID PRIMARY KEY,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  panel_code TEXT NOT NULL,
+  panel_name TEXT NOT NULL,
+  status lab_order_status NOT NULL DEFAULT 'ordered',
+  notes TEXT,
+  ordered_by TEXT NOT NULL,
+  ordered_by_name TEXT,
+  ordered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  collected_at TIMESTAMP WITH TIME ZONE,
+  resulted_at TIMESTAMP WITH TIME ZONE,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX lab_orders_visit_idx ON public.lab_orders (visit_id, ordered_at);
+
+CREATE TABLE public.lab_results (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  order_id UUID REFERENCES public.lab_orders(id) ON DELETE CASCADE NOT NULL,
+  visit_id UUID REFERENCES public.visits(id) ON DELETE CASCADE NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  analyte_code TEXT NOT NULL,
+  analyte_name TEXT NOT NULL,
+  value_type TEXT NOT NULL CHECK (value_type IN ('numeric', 'text')),
+  value_text TEXT NOT NULL,
+  numeric_value NUMERIC,
+  unit TEXT,
+  ref_low NUMERIC,
+  ref_high NUMERIC,
+  ref_text TEXT,
+  flag TEXT CHECK (flag IN ('', 'low', 'high', 'critical_low', 'critical_high', 'abnormal')),
+  comment TEXT,
+  amended BOOLEAN NOT NULL DEFAULT FALSE,
+  entered_by TEXT,
+  resulted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  UNIQUE (order_id, analyte_code)
+);
+
+CREATE INDEX lab_results_visit_idx ON public.lab_results (visit_id);
+CREATE INDEX lab_results_patient_idx ON public.lab_results (patient_id, analyte_code, resulted_at);
+
+ALTER TABLE public.lab_orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.lab_results ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Patients can view own lab orders" ON public.lab_orders
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.patients p
+      WHERE p.id = lab_orders.patient_id AND p.profile_id = auth.uid()
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage lab orders" ON public.lab_orders
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
+
+CREATE POLICY "Patients can view own lab results" ON public.lab_results
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.patients p
+      WHERE p.id = lab_results.patient_id AND p.profile_id = auth.uid()
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage lab results" ON public.lab_results
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
@@ -0,0 +1,65 @@
+-- Weekly working hours and schedule exceptions of doctors, free slots are generated from them by
+-- the backend and a booked slot is stored on its scheduled visit
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE public.doctor_working_hours (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  doctor_id UUID REFERENCES public.doctors(id) ON DELETE CASCADE NOT NULL,
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  start_time TIME NOT NULL,
+  end_time TIME NOT NULL,
+  slot_minutes INTEGER NOT NULL CHECK (slot_minutes BETWEEN 5 AND 480),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  CHECK (start_time < end_time OR end_time = '00:00')
+);
+
+CREATE INDEX doctor_working_hours_doctor_idx ON public.doctor_working_hours (doctor_id, weekday);
+
+-- Leave and holidays without times cover the whole day, extra hours add slots on a date
+CREATE TABLE public.doctor_exceptions (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  doctor_id UUID REFERENCES public.doctors(id) ON DELETE CASCADE NOT NULL,
+  exception_date DATE NOT NULL,
+  start_time TIME,
+  end_time TIME,
+  slot_minutes INTEGER CHECK (slot_minutes BETWEEN 5 AND 480),
+  kind TEXT NOT NULL CHECK (kind IN ('leave', 'holiday', 'extra')),
+  reason TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  CHECK ((start_time IS NULL) = (end_time IS NULL)),
+  CHECK (kind <> 'extra' OR (start_time IS NOT NULL AND slot_minutes IS NOT NULL))
+);
+
+CREATE INDEX doctor_exceptions_doctor_idx ON public.doctor_exceptions (doctor_id, exception_date);
+
+-- The booked slot of a visit, two active visits of a doctor can never overlap
+ALTER TABLE public.visits ADD COLUMN IF NOT EXISTS slot TSTZRANGE;
+
+ALTER TABLE public.visits ADD CONSTRAINT visits_no_double_booking
+  EXCLUDE USING gist (doctor_id WITH =, slot WITH &&)
+  WHERE (slot IS NOT NULL AND status NOT IN ('cancelled', 'no_show'));
+
+ALTER TABLE public.doctor_working_hours ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.doctor_exceptions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Everyone can view working hours" ON public.doctor_working_hours
+  FOR SELECT USING (auth.uid() IS NOT NULL);
+
+CREATE POLICY "Doctors and admins can manage working hours" ON public.doctor_working_hours
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
+
+CREATE POLICY "Everyone can view schedule exceptions" ON public.doctor_exceptions
+  FOR SELECT USING (auth.uid() IS NOT NULL);
+
+CREATE POLICY "Doctors and admins can manage schedule exceptions" ON public.doctor_exceptions
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
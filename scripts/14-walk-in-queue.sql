@@ -0,0 +1,67 @@
+-- Daily walk-in queues of doctors and departments, a department queue is served by all doctors
+-- of that specialization and numbers restart every day per queue
+CREATE TABLE public.queue_counters (
+  queue_date DATE NOT NULL,
+  queue_key TEXT NOT NULL,
+  last_number INTEGER NOT NULL,
+  PRIMARY KEY (queue_date, queue_key)
+);
+
+CREATE TABLE public.queue_entries (
+  id UUID PRIMARY KEY,
+  queue_date DATE NOT NULL,
+  queue_key TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) NOT NULL,
+  doctor_id UUID REFERENCES public.doctors(id),
+  department TEXT,
+  status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'called', 'skipped', 'cancelled')),
+  visit_type visit_type NOT NULL DEFAULT 'general',
+  chief_complaint TEXT,
+  notes TEXT,
+  reason TEXT,
+  registered_by TEXT NOT NULL,
+  registered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  called_by UUID REFERENCES public.doctors(id),
+  called_at TIMESTAMP WITH TIME ZONE,
+  call_id UUID UNIQUE,
+  -- the in-progress visit a called walk-in was converted into
+  visit_id UUID REFERENCES public.visits(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (queue_date, queue_key, number),
+  CHECK ((doctor_id IS NULL) <> (department IS NULL))
+);
+
+CREATE INDEX queue_entries_waiting_idx ON public.queue_entries (queue_date, queue_key, registered_at)
+  WHERE status = 'waiting';
+
+-- a patient waits in one queue at a time
+CREATE UNIQUE INDEX queue_entries_active_patient_idx ON public.queue_entries (patient_id, queue_date)
+  WHERE status = 'waiting';
+
+ALTER TABLE public.queue_counters ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.queue_entries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Patients can view own queue entries" ON public.queue_entries
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.patients p
+      WHERE p.id = queue_entries.patient_id AND p.profile_id = auth.uid()
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage queue entries" ON public.queue_entries
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
+
+CREATE POLICY "Doctors and admins can manage queue counters" ON public.queue_counters
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
@@ -0,0 +1,63 @@
+-- Reminders of appointments, follow-ups and therapy sessions with their delivery attempts,
+-- one notification is kept per reminder and channel
+ALTER TABLE public.patients ADD COLUMN IF NOT EXISTS preferred_language TEXT;
+
+CREATE TABLE public.notifications (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  kind TEXT NOT NULL CHECK (kind IN ('appointment', 'follow_up', 'therapy')),
+  ref_id UUID NOT NULL, -- visit or therapy schedule
+  due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  patient_id UUID REFERENCES public.patients(id) ON DELETE CASCADE NOT NULL,
+  channel TEXT NOT NULL CHECK (channel IN ('sms', 'email')),
+  recipient TEXT NOT NULL,
+  language TEXT NOT NULL,
+  subject TEXT,
+  body TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'cancelled')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL,
+  next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  last_error TEXT,
+  claim_id UUID,
+  claimed_at TIMESTAMP WITH TIME ZONE,
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  UNIQUE (kind, ref_id, due_at, channel)
+);
+
+CREATE INDEX notifications_due_idx ON public.notifications (next_attempt_at)
+  WHERE status IN ('pending', 'sending');
+CREATE INDEX notifications_claim_idx ON public.notifications (claim_id) WHERE claim_id IS NOT NULL;
+CREATE INDEX notifications_patient_idx ON public.notifications (patient_id, created_at);
+
+CREATE TABLE public.notification_attempts (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  notification_id UUID REFERENCES public.notifications(id) ON DELETE CASCADE NOT NULL,
+  attempt INTEGER NOT NULL,
+  channel TEXT NOT NULL,
+  succeeded BOOLEAN NOT NULL,
+  error TEXT,
+  attempted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX notification_attempts_notification_idx ON public.notification_attempts (notification_id, attempt);
+
+ALTER TABLE public.notifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.notification_attempts ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Admins can manage notifications" ON public.notifications
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );
+
+CREATE POLICY "Admins can view notification attempts" ON public.notification_attempts
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );
@@ -0,0 +1,52 @@
+-- Background jobs with their runs, every replica runs the job runner and the lease of a job
+-- makes one of them run it at a time
+
+CREATE TABLE public.jobs (
+  name TEXT PRIMARY KEY,
+  schedule TEXT NOT NULL,
+  enabled BOOLEAN DEFAULT TRUE NOT NULL,
+  next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  -- failed runs since the last success, reset when the job succeeds or runs out of attempts
+  attempt INTEGER DEFAULT 0 NOT NULL,
+  triggered BOOLEAN DEFAULT FALSE NOT NULL,
+  lease_owner TEXT,
+  lease_until TIMESTAMP WITH TIME ZONE,
+  last_run_at TIMESTAMP WITH TIME ZONE,
+  last_status TEXT CHECK (last_status IN ('succeeded', 'failed')),
+  last_error TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE TABLE public.job_runs (
+  id UUID PRIMARY KEY,
+  job_name TEXT REFERENCES public.jobs(name) ON DELETE CASCADE NOT NULL,
+  attempt INTEGER NOT NULL,
+  trigger TEXT NOT NULL CHECK (trigger IN ('schedule', 'manual', 'retry')),
+  owner TEXT NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+  error TEXT,
+  started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX job_runs_job_idx ON public.job_runs (job_name, started_at DESC);
+
+ALTER TABLE public.jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.job_runs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Admins can manage jobs" ON public.jobs
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );
+
+CREATE POLICY "Admins can view job runs" ON public.job_runs
+  FOR SELECT USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );
@@ -0,0 +1,33 @@
+-- Progress of therapy schedules: the sessions a patient attended or missed and the status of the
+-- schedule, which the system moves to completed or expired. is_active mirrors the active status
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS duration INTEGER; -- minutes of a session
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS session_count INTEGER;
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS status TEXT DEFAULT 'active' NOT NULL
+  CHECK (status IN ('active', 'completed', 'expired', 'cancelled'));
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS ended_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE public.therapy_schedules SET status = 'cancelled', ended_at = updated_at WHERE is_active = false;
+
+CREATE INDEX IF NOT EXISTS therapy_schedules_status_idx ON public.therapy_schedules (status);
+
+CREATE TABLE public.therapy_sessions (
+  id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  schedule_id UUID REFERENCES public.therapy_schedules(id) ON DELETE CASCADE NOT NULL,
+  session_date DATE NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('attended', 'missed')),
+  notes TEXT,
+  recorded_by TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  UNIQUE (schedule_id, session_date)
+);
+
+ALTER TABLE public.therapy_sessions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Doctors and admins can manage therapy sessions" ON public.therapy_sessions
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role IN ('doctor', 'admin')
+    )
+  );
@@ -0,0 +1,26 @@
+-- Recurrence of therapy schedules: an RFC 5545 RRULE with EXDATE exceptions replaces the loose
+-- frequency, which is kept as a label. Sessions on a clinic holiday are skipped or moved to the next
+-- or previous working day according to the policy of the schedule
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS rrule TEXT;
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS exdates TEXT; -- comma separated YYYY-MM-DD
+ALTER TABLE public.therapy_schedules ADD COLUMN IF NOT EXISTS holiday_policy TEXT DEFAULT 'skip' NOT NULL
+  CHECK (holiday_policy IN ('skip', 'next', 'previous'));
+
+CREATE TABLE public.clinic_holidays (
+  day DATE PRIMARY KEY,
+  name TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+ALTER TABLE public.clinic_holidays ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Authenticated users can view clinic holidays" ON public.clinic_holidays
+  FOR SELECT USING (auth.uid() IS NOT NULL);
+
+CREATE POLICY "Admins can manage clinic holidays" ON public.clinic_holidays
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );
@@ -0,0 +1,18 @@
+-- Secret-token iCalendar feeds of doctors and patients, only a SHA-256 hash of the token is kept
+-- and regenerating it replaces the hash so the old feed url stops working
+CREATE TABLE public.calendar_feeds (
+  user_id TEXT PRIMARY KEY,
+  token_hash TEXT UNIQUE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+  last_fetched_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE public.calendar_feeds ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Admins can manage calendar feeds" ON public.calendar_feeds
+  FOR ALL USING (
+    EXISTS (
+      SELECT 1 FROM public.profiles
+      WHERE id = auth.uid() AND role = 'admin'
+    )
+  );